@@ -0,0 +1,112 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AccountNonce is the nonce state of an account, to select the nonce of a
+// new transaction before constructing it
+type AccountNonce struct {
+	Address string `json:"address"`
+
+	// Latest is the nonce of the account at the latest block
+	Latest uint64 `json:"latest"`
+
+	// Pending is the nonce of the account after its executable transactions in
+	// the transaction pool, the nonce of a new transaction
+	Pending uint64 `json:"pending"`
+
+	// InFlight is the number of executable transactions of the account waiting
+	// to be mined
+	InFlight uint64 `json:"in_flight"`
+
+	// QueuedNonces are the nonces of the transactions of the account that wait
+	// for transactions of the NonceGaps. Both are unset when the node doesn't
+	// expose its transaction pool.
+	QueuedNonces []uint64 `json:"queued_nonces,omitempty"`
+	NonceGaps    []uint64 `json:"nonce_gaps,omitempty"`
+}
+
+// AccountNonceProvider is an optional interface a client can implement to
+// return the nonces of an account for the account_nonce /call method. The
+// nonces of the other clients are fetched with FetchAccountNonce.
+type AccountNonceProvider interface {
+	GetAccountNonce(ctx context.Context, address common.Address) (*AccountNonce, error)
+}
+
+// GetAccountNonce returns the latest and pending nonces of address, see
+// FetchAccountNonce
+func (ec *SDKClient) GetAccountNonce(ctx context.Context, address common.Address) (*AccountNonce, error) {
+	return FetchAccountNonce(ctx, ec.batchCall, address)
+}
+
+// FetchAccountNonce returns the latest and pending nonces of address with the
+// batches of batchCall, with the nonce gaps of its transactions in the
+// transaction pool when the node supports txpool_contentFrom
+func FetchAccountNonce(
+	ctx context.Context,
+	batchCall func(context.Context, []rpc.BatchElem) error,
+	address common.Address,
+) (*AccountNonce, error) {
+	var (
+		latest  hexutil.Uint64
+		pending hexutil.Uint64
+		content poolContent
+	)
+	reqs := []rpc.BatchElem{
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, "latest"}, Result: &latest},
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, "pending"}, Result: &pending},
+		{Method: "txpool_contentFrom", Args: []interface{}{address}, Result: &content},
+	}
+	if err := batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	if reqs[0].Error != nil {
+		return nil, fmt.Errorf("latest eth_getTransactionCount failed: %w", reqs[0].Error)
+	}
+	if reqs[1].Error != nil {
+		return nil, fmt.Errorf("pending eth_getTransactionCount failed: %w", reqs[1].Error)
+	}
+
+	nonce := &AccountNonce{
+		Address: FormatAddress(address),
+		Latest:  uint64(latest),
+		Pending: uint64(pending),
+	}
+	// The pending nonce of a node behind the latest block can be lower
+	if nonce.Pending > nonce.Latest {
+		nonce.InFlight = nonce.Pending - nonce.Latest
+	}
+
+	// Providers commonly disable the txpool namespace, the nonces are still
+	// usable without the gap analysis
+	if reqs[2].Error == nil {
+		queued := sortedPoolTransactions(content.Queued)
+		nonce.QueuedNonces = make([]uint64, len(queued))
+		for i, tx := range queued {
+			nonce.QueuedNonces[i] = uint64(tx.Nonce)
+		}
+		nonce.NonceGaps = nonceGaps(nonce.Latest, sortedPoolTransactions(content.Pending), queued)
+	}
+
+	return nonce, nil
+}
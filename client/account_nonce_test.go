@@ -0,0 +1,118 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAccountNonce(t *testing.T) {
+	sender := common.HexToAddress("0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1")
+	content := `{
+		"pending": {
+			"5": {"hash": "` + common.HexToHash("0x05").Hex() + `", "nonce": "0x5", "gas": "0x5208", "value": "0x1"},
+			"6": {"hash": "` + common.HexToHash("0x06").Hex() + `", "nonce": "0x6", "gas": "0x5208", "value": "0x1"}
+		},
+		"queued": {
+			"10": {"hash": "` + common.HexToHash("0x0a").Hex() + `", "nonce": "0xa", "gas": "0x5208", "value": "0x1"},
+			"9": {"hash": "` + common.HexToHash("0x09").Hex() + `", "nonce": "0x9", "gas": "0x5208", "value": "0x1"}
+		}
+	}`
+
+	tests := map[string]struct {
+		pending       uint64
+		pendingErr    error
+		contentErr    error
+		expected      *AccountNonce
+		expectedError string
+	}{
+		"with transaction pool": {
+			pending: 7,
+			expected: &AccountNonce{
+				Address:      sender.Hex(),
+				Latest:       5,
+				Pending:      7,
+				InFlight:     2,
+				QueuedNonces: []uint64{9, 10},
+				NonceGaps:    []uint64{7, 8},
+			},
+		},
+		"without transaction pool": {
+			pending:    7,
+			contentErr: &rpcError{code: methodNotFoundCode},
+			expected: &AccountNonce{
+				Address:  sender.Hex(),
+				Latest:   5,
+				Pending:  7,
+				InFlight: 2,
+			},
+		},
+		"pending behind latest": {
+			pending:    4,
+			contentErr: errors.New("txpool disabled"),
+			expected: &AccountNonce{
+				Address: sender.Hex(),
+				Latest:  5,
+				Pending: 4,
+			},
+		},
+		"pending error": {
+			pendingErr:    errors.New("unavailable"),
+			expectedError: "pending eth_getTransactionCount failed: unavailable",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+			mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+				func(args mock.Arguments) {
+					reqs := args.Get(1).([]rpc.BatchElem)
+					assert.Equal(t, []interface{}{sender, "latest"}, reqs[0].Args)
+					assert.Equal(t, []interface{}{sender, "pending"}, reqs[1].Args)
+					assert.Equal(t, "txpool_contentFrom", reqs[2].Method)
+					*reqs[0].Result.(*hexutil.Uint64) = 5
+					*reqs[1].Result.(*hexutil.Uint64) = hexutil.Uint64(test.pending)
+					reqs[1].Error = test.pendingErr
+					reqs[2].Error = test.contentErr
+					if test.contentErr == nil {
+						assert.NoError(t, json.Unmarshal([]byte(content), reqs[2].Result))
+					}
+				},
+			).Once()
+
+			nonce, err := sdkClient.GetAccountNonce(context.Background(), sender)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, nonce)
+			}
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
@@ -15,22 +15,158 @@
 package client
 
 import (
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// ChecksumAddress ensures an address can be EIP55-compliant format
+// ErrInvalidAddress is returned by ChecksumAddress for malformed addresses
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ChecksumStrategy formats an address in the checksum format of the network
+type ChecksumStrategy func(common.Address) string
+
+// checksumStrategy is the ChecksumStrategy used by ChecksumAddress,
+// MustChecksum and FormatAddress
+var checksumStrategy atomic.Value
+
+// AddressNormalizer is an optional interface a client can implement for chains
+// with aliased or non-standard account encodings, like Hedera account aliases,
+// to map them to canonical 20-byte addresses. Once set with
+// SetAddressNormalizer, it is applied to every address checksummed by
+// ChecksumAddress, so the addresses of requests, operations and constructed
+// transactions are canonical.
+type AddressNormalizer interface {
+	NormalizeAddress(address string) (string, error)
+}
+
+// addressNormalizer holds the AddressNormalizer used by NormalizeAddress
+type addressNormalizer struct {
+	AddressNormalizer
+}
+
+// normalizer is the addressNormalizer used by NormalizeAddress
+var normalizer atomic.Value
+
+// EIP55Checksum formats an address with the EIP-55 mixed-case checksum
+func EIP55Checksum(addr common.Address) string {
+	return addr.Hex()
+}
+
+// LowercaseChecksum formats an address as lowercase hex without a checksum
+func LowercaseChecksum(addr common.Address) string {
+	return strings.ToLower(addr.Hex())
+}
+
+// EIP1191Checksum returns a ChecksumStrategy for the chain id aware
+// EIP-1191 checksum used by RSK-like networks.
+func EIP1191Checksum(chainID *big.Int) ChecksumStrategy {
+	prefix := chainID.String() + "0x"
+	return func(addr common.Address) string {
+		lower := hex.EncodeToString(addr.Bytes())
+		hash := crypto.Keccak256([]byte(prefix + lower))
+
+		result := []byte(lower)
+		for i, c := range result {
+			if c < 'a' {
+				continue
+			}
+			nibble := hash[i/2] & 0xf // nolint:gomnd
+			if i%2 == 0 {
+				nibble = hash[i/2] >> 4 // nolint:gomnd
+			}
+			if nibble >= 8 { // nolint:gomnd
+				result[i] = c - 'a' + 'A'
+			}
+		}
+
+		return "0x" + string(result)
+	}
+}
+
+// ChecksumStrategyFromConfig returns the ChecksumStrategy configured
+// by RosettaConfig.AddressChecksum. EIP-55 is used by default.
+func ChecksumStrategyFromConfig(cfg *configuration.Configuration) (ChecksumStrategy, error) {
+	switch cfg.RosettaCfg.AddressChecksum {
+	case "", configuration.EIP55AddressChecksum:
+		return EIP55Checksum, nil
+	case configuration.LowercaseAddressChecksum:
+		return LowercaseChecksum, nil
+	case configuration.EIP1191AddressChecksum:
+		if cfg.ChainConfig == nil || cfg.ChainConfig.ChainID == nil {
+			return nil, fmt.Errorf("%s address checksum requires a chain id", configuration.EIP1191AddressChecksum)
+		}
+		return EIP1191Checksum(cfg.ChainConfig.ChainID), nil
+	default:
+		return nil, fmt.Errorf("unknown address checksum %s", cfg.RosettaCfg.AddressChecksum)
+	}
+}
+
+// SetChecksumStrategy sets the ChecksumStrategy used to format addresses
+func SetChecksumStrategy(strategy ChecksumStrategy) {
+	checksumStrategy.Store(strategy)
+}
+
+// FormatAddress formats addr with the configured ChecksumStrategy
+func FormatAddress(addr common.Address) string {
+	if strategy, ok := checksumStrategy.Load().(ChecksumStrategy); ok {
+		return strategy(addr)
+	}
+
+	return EIP55Checksum(addr)
+}
+
+// SetAddressNormalizer sets the AddressNormalizer used by NormalizeAddress, or
+// removes it if n is nil
+func SetAddressNormalizer(n AddressNormalizer) {
+	normalizer.Store(addressNormalizer{n})
+}
+
+// NormalizeAddress maps address to its canonical address with the configured
+// AddressNormalizer. It is returned unchanged if there is none.
+func NormalizeAddress(address string) (string, error) {
+	n, ok := normalizer.Load().(addressNormalizer)
+	if !ok || n.AddressNormalizer == nil {
+		return address, nil
+	}
+
+	normalized, err := n.NormalizeAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	return normalized, nil
+}
+
+// ChecksumAddress ensures an address is in the checksum format of the network.
+// Malformed addresses fail with an error naming them and wrapping
+// ErrInvalidAddress.
 func ChecksumAddress(address string) (string, error) {
-	addr, err := common.NewMixedcaseAddressFromString(address)
+	normalized, err := NormalizeAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid address: %w", address, err)
+	}
+	addr, err := common.NewMixedcaseAddressFromString(normalized)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%s is not a valid address: %w", address, ErrInvalidAddress)
 	}
 
-	return addr.Address().Hex(), nil
+	return FormatAddress(addr.Address()), nil
 }
 
-// MustChecksum ensures an address can be EIP55-compliant format
+// MustChecksum ensures an address is in the checksum format of the network
+//
+// Deprecated: MustChecksum exits on malformed addresses. Use ChecksumAddress
+// for addresses from chain data, or FormatAddress for a common.Address.
 func MustChecksum(address string) string {
 	addr, err := ChecksumAddress(address)
 	if err != nil {
@@ -39,3 +175,13 @@ func MustChecksum(address string) string {
 
 	return addr
 }
+
+// checksumOrRaw checksums an address from chain data, returning it unchanged
+// when it is malformed so that only the operations using it fail to parse.
+func checksumOrRaw(address string) string {
+	if addr, err := ChecksumAddress(address); err == nil {
+		return addr
+	}
+
+	return address
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEIP1191Checksum(t *testing.T) {
+	// Test vectors from https://eips.ethereum.org/EIPS/eip-1191
+	tests := map[string]struct {
+		chainID  int64
+		expected string
+	}{
+		"rsk mainnet": {
+			chainID:  30,
+			expected: "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD",
+		},
+		"rsk testnet": {
+			chainID:  31,
+			expected: "0x5aAeb6053F3e94c9b9A09F33669435E7EF1BEaEd",
+		},
+	}
+
+	addr := common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, EIP1191Checksum(big.NewInt(test.chainID))(addr))
+		})
+	}
+}
+
+func TestChecksumAddress_Strategy(t *testing.T) {
+	t.Cleanup(func() { SetChecksumStrategy(EIP55Checksum) })
+
+	address := "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	tests := map[string]struct {
+		checksum string
+		expected string
+		err      bool
+	}{
+		"default": {
+			expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		"eip55": {
+			checksum: configuration.EIP55AddressChecksum,
+			expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		"eip1191": {
+			checksum: configuration.EIP1191AddressChecksum,
+			expected: "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD",
+		},
+		"lowercase": {
+			checksum: configuration.LowercaseAddressChecksum,
+			expected: address,
+		},
+		"unknown": {
+			checksum: "eip9999",
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := ChecksumStrategyFromConfig(&configuration.Configuration{
+				ChainConfig: &params.ChainConfig{ChainID: big.NewInt(30)},
+				RosettaCfg:  configuration.RosettaConfig{AddressChecksum: test.checksum},
+			})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			SetChecksumStrategy(strategy)
+			checksummed, err := ChecksumAddress(address)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, checksummed)
+			addr := common.HexToAddress(address)
+			assert.Equal(t, test.expected, Account(&addr).Address)
+		})
+	}
+}
+
+// hederaNormalizer maps Hedera account ids like 0.0.1234 to their long-zero
+// addresses
+type hederaNormalizer struct{}
+
+func (hederaNormalizer) NormalizeAddress(address string) (string, error) {
+	if !strings.HasPrefix(address, "0.0.") {
+		return address, nil
+	}
+	num, err := strconv.ParseUint(strings.TrimPrefix(address, "0.0."), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid account id %s", address)
+	}
+
+	return common.BigToAddress(new(big.Int).SetUint64(num)).Hex(), nil
+}
+
+func TestChecksumAddress_Normalizer(t *testing.T) {
+	t.Cleanup(func() { SetAddressNormalizer(nil) })
+
+	tests := map[string]struct {
+		address  string
+		expected string
+		err      bool
+	}{
+		"canonical address": {
+			address:  "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+			expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		"account id": {
+			address:  "0.0.1234",
+			expected: "0x00000000000000000000000000000000000004d2",
+		},
+		"invalid account id": {
+			address: "0.0.x",
+			err:     true,
+		},
+	}
+
+	SetAddressNormalizer(hederaNormalizer{})
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			checksummed, err := ChecksumAddress(test.address)
+			if test.err {
+				assert.ErrorIs(t, err, ErrInvalidAddress)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, checksummed)
+		})
+	}
+
+	// Without a normalizer, account ids are not addresses
+	SetAddressNormalizer(nil)
+	normalized, err := NormalizeAddress("0.0.1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.1234", normalized)
+	_, err = ChecksumAddress("0.0.1234")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	assert.EqualError(t, err, "0.0.1234 is not a valid address: invalid address")
+}
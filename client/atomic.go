@@ -0,0 +1,152 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// x2cRate is the number of wei in a nAVAX, the denomination of the amounts of
+// Avalanche atomic transactions
+var x2cRate = big.NewInt(1_000_000_000)
+
+// AtomicTransactionProvider is an optional interface a client can implement to
+// add the transactions of a block that are not in its transaction list, like the
+// atomic import and export transactions of the Avalanche C-chain, which move
+// AVAX between the C-chain and the X and P chains. AtomicTransactions is called
+// by the block service with the block and its JSON RPC encoding, and the
+// transactions it returns are appended to the block, so the balances they
+// change can be reconciled.
+type AtomicTransactionProvider interface {
+	AtomicTransactions(
+		ctx context.Context,
+		block *EthTypes.Block,
+		raw json.RawMessage,
+	) ([]*RosettaTypes.Transaction, error)
+}
+
+// AtomicTransfer is an amount of an asset, in nAVAX for AVAX, moved into or out
+// of a C-chain address by an atomic transaction
+type AtomicTransfer struct {
+	Address common.Address
+	Amount  uint64
+	AssetID string
+}
+
+// AtomicTransaction is an Avalanche C-chain atomic transaction. Imports credit
+// their outputs, and exports debit their inputs.
+type AtomicTransaction struct {
+	ID      string
+	Inputs  []AtomicTransfer
+	Outputs []AtomicTransfer
+}
+
+// AtomicTransactionDecoder decodes the atomic transactions encoded in the
+// blockExtraData field of a C-chain block, e.g. with the atomic transaction
+// codec of coreth
+type AtomicTransactionDecoder func(blockExtraData []byte) ([]*AtomicTransaction, error)
+
+// AvalancheAtomicTransactions is an example AtomicTransactionProvider for the
+// Avalanche C-chain. It decodes the blockExtraData of a block with Decode and
+// maps the AVAX transfers of the atomic transactions to AtomicImportOpType and
+// AtomicExportOpType operations in Currency. Transfers of other assets are
+// skipped, since they don't change the native balance of their address.
+// Clients embed it to implement AtomicTransactionProvider, and register the two
+// operation types with types.RegisterOpType.
+type AvalancheAtomicTransactions struct {
+	Decode      AtomicTransactionDecoder
+	AVAXAssetID string
+	Currency    *RosettaTypes.Currency
+}
+
+// AtomicTransactions implements AtomicTransactionProvider
+func (a *AvalancheAtomicTransactions) AtomicTransactions(
+	_ context.Context,
+	block *EthTypes.Block,
+	raw json.RawMessage,
+) ([]*RosettaTypes.Transaction, error) {
+	if a.Decode == nil {
+		return nil, errors.New("no atomic transaction decoder")
+	}
+
+	var fields struct {
+		BlockExtraData hexutil.Bytes `json:"blockExtraData"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("could not decode block extra data of block %d: %w", block.NumberU64(), err)
+	}
+	if len(fields.BlockExtraData) == 0 {
+		return nil, nil
+	}
+
+	atomicTxs, err := a.Decode(fields.BlockExtraData)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode atomic transactions of block %d: %w", block.NumberU64(), err)
+	}
+
+	transactions := make([]*RosettaTypes.Transaction, 0, len(atomicTxs))
+	for _, atomicTx := range atomicTxs {
+		var ops []*RosettaTypes.Operation
+		ops = a.appendOperations(ops, sdkTypes.AtomicExportOpType, atomicTx.Inputs, true)
+		ops = a.appendOperations(ops, sdkTypes.AtomicImportOpType, atomicTx.Outputs, false)
+
+		transactions = append(transactions, &RosettaTypes.Transaction{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: atomicTx.ID},
+			Operations:            ops,
+		})
+	}
+
+	return transactions, nil
+}
+
+// appendOperations appends an operation of opType to ops for each AVAX transfer
+// of transfers, debiting the address of the transfer when debit is set
+func (a *AvalancheAtomicTransactions) appendOperations(
+	ops []*RosettaTypes.Operation,
+	opType string,
+	transfers []AtomicTransfer,
+	debit bool,
+) []*RosettaTypes.Operation {
+	for _, transfer := range transfers {
+		if transfer.AssetID != a.AVAXAssetID {
+			continue
+		}
+
+		amount := new(big.Int).Mul(new(big.Int).SetUint64(transfer.Amount), x2cRate)
+		if debit {
+			amount.Neg(amount)
+		}
+		ops = append(ops, &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: int64(len(ops))},
+			Type:                opType,
+			Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: FormatAddress(transfer.Address)},
+			Amount:              Amount(amount, a.Currency),
+		})
+	}
+
+	return ops
+}
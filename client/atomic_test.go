@@ -0,0 +1,117 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvalancheAtomicTransactions(t *testing.T) {
+	avax := &RosettaTypes.Currency{Symbol: "AVAX", Decimals: 18}
+	exporter := common.HexToAddress("0x0a")
+	importer := common.HexToAddress("0x0b")
+	block := EthTypes.NewBlockWithHeader(&EthTypes.Header{Number: big.NewInt(10)})
+
+	tests := map[string]struct {
+		raw           string
+		decode        AtomicTransactionDecoder
+		expected      []*RosettaTypes.Transaction
+		expectedError string
+	}{
+		"import and export": {
+			raw: `{"blockExtraData":"0x0102"}`,
+			decode: func(blockExtraData []byte) ([]*AtomicTransaction, error) {
+				assert.Equal(t, []byte{0x01, 0x02}, blockExtraData)
+				return []*AtomicTransaction{
+					{
+						ID:     "export",
+						Inputs: []AtomicTransfer{{Address: exporter, Amount: 2, AssetID: "avax"}},
+					},
+					{
+						ID: "import",
+						Outputs: []AtomicTransfer{
+							{Address: importer, Amount: 1, AssetID: "ant"},
+							{Address: importer, Amount: 3, AssetID: "avax"},
+						},
+					},
+				}, nil
+			},
+			expected: []*RosettaTypes.Transaction{
+				{
+					TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "export"},
+					Operations: []*RosettaTypes.Operation{{
+						OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+						Type:                sdkTypes.AtomicExportOpType,
+						Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+						Account:             &RosettaTypes.AccountIdentifier{Address: exporter.Hex()},
+						Amount:              &RosettaTypes.Amount{Value: "-2000000000", Currency: avax},
+					}},
+				},
+				{
+					TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "import"},
+					Operations: []*RosettaTypes.Operation{{
+						OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+						Type:                sdkTypes.AtomicImportOpType,
+						Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+						Account:             &RosettaTypes.AccountIdentifier{Address: importer.Hex()},
+						Amount:              &RosettaTypes.Amount{Value: "3000000000", Currency: avax},
+					}},
+				},
+			},
+		},
+		"no block extra data": {
+			raw: `{"number":"0xa"}`,
+			decode: func([]byte) ([]*AtomicTransaction, error) {
+				t.Error("decoded a block without block extra data")
+				return nil, nil
+			},
+		},
+		"decode error": {
+			raw: `{"blockExtraData":"0x01"}`,
+			decode: func([]byte) ([]*AtomicTransaction, error) {
+				return nil, errors.New("unknown codec version")
+			},
+			expectedError: "could not decode atomic transactions of block 10: unknown codec version",
+		},
+		"no decoder": {
+			raw:           `{"blockExtraData":"0x01"}`,
+			expectedError: "no atomic transaction decoder",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := &AvalancheAtomicTransactions{Decode: test.decode, AVAXAssetID: "avax", Currency: avax}
+			txs, err := provider.AtomicTransactions(context.Background(), block, json.RawMessage(test.raw))
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, txs)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchCallContext sends all given requests to the node and waits for the
+// responses. When MaxBatchSize is configured, the requests are split into
+// several sequential batches that each stay within the limit.
+//
+// The results and errors are written back into b, just like
+// rpc.Client.BatchCallContext does. Every batch gets its own UpstreamCallTimeout,
+// and when ctx is done the remaining batches are not sent and their requests
+// fail with the context error. Batches beyond the slow call threshold of their
+// methods are logged.
+func (ec *SDKClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	batches := splitBatch(b, ec.maxBatchSize, ec.batchMethodWeights)
+	for i, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			for _, remaining := range batches[i:] {
+				for j := range remaining {
+					remaining[j].Error = err
+				}
+			}
+			return err
+		}
+
+		if err := ec.batchCall(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ec *SDKClient) batchCall(ctx context.Context, batch []rpc.BatchElem) error {
+	ctx, cancel := ec.upstreamContext(ctx)
+	defer cancel()
+
+	defer ec.slowCalls.observeBatch(batch, time.Now())
+	return ec.RPCClient.BatchCallContext(ctx, batch)
+}
+
+// splitBatch splits b into consecutive batches whose total weight does not exceed
+// maxBatchSize. A single request heavier than maxBatchSize is sent on its own.
+// The returned batches share the backing array of b.
+func splitBatch(b []rpc.BatchElem, maxBatchSize int, weights map[string]int) [][]rpc.BatchElem {
+	if maxBatchSize <= 0 || len(b) == 0 {
+		return [][]rpc.BatchElem{b}
+	}
+
+	var batches [][]rpc.BatchElem
+	start, weight := 0, 0
+	for i := range b {
+		w := batchMethodWeight(b[i].Method, weights)
+		if i > start && weight+w > maxBatchSize {
+			batches = append(batches, b[start:i])
+			start, weight = i, 0
+		}
+		weight += w
+	}
+
+	return append(batches, b[start:])
+}
+
+func batchMethodWeight(method string, weights map[string]int) int {
+	if w, ok := weights[method]; ok && w > 0 {
+		return w
+	}
+
+	return configuration.DefaultBatchMethodWeight
+}
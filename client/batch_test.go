@@ -0,0 +1,150 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBatchElems(methods ...string) []rpc.BatchElem {
+	reqs := make([]rpc.BatchElem, len(methods))
+	for i, method := range methods {
+		reqs[i] = rpc.BatchElem{Method: method}
+	}
+	return reqs
+}
+
+func batchLen(n int) interface{} {
+	return mock.MatchedBy(func(b []rpc.BatchElem) bool { return len(b) == n })
+}
+
+func TestSplitBatch(t *testing.T) {
+	tests := map[string]struct {
+		methods      []string
+		maxBatchSize int
+		weights      map[string]int
+		expected     []int
+	}{
+		"no limit": {
+			methods:  []string{"a", "a", "a"},
+			expected: []int{3},
+		},
+		"exact multiple": {
+			methods:      []string{"a", "a", "a", "a"},
+			maxBatchSize: 2,
+			expected:     []int{2, 2},
+		},
+		"remainder": {
+			methods:      []string{"a", "a", "a", "a", "a"},
+			maxBatchSize: 2,
+			expected:     []int{2, 2, 1},
+		},
+		"weighted methods": {
+			methods:      []string{"trace", "a", "a", "trace", "a"},
+			maxBatchSize: 4,
+			weights:      map[string]int{"trace": 3},
+			expected:     []int{2, 2, 1},
+		},
+		"method heavier than limit": {
+			methods:      []string{"a", "trace", "a"},
+			maxBatchSize: 2,
+			weights:      map[string]int{"trace": 5},
+			expected:     []int{1, 1, 1},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			batches := splitBatch(newBatchElems(test.methods...), test.maxBatchSize, test.weights)
+			sizes := make([]int, len(batches))
+			for i, batch := range batches {
+				sizes[i] = len(batch)
+			}
+			assert.Equal(t, test.expected, sizes)
+		})
+	}
+}
+
+func TestBatchCallContext_Split(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient:    &RPCClient{JSONRPC: mockJSONRPC},
+		maxBatchSize: 2,
+	}
+
+	mockJSONRPC.On("BatchCallContext", ctx, batchLen(2)).Return(nil).Run(
+		func(args mock.Arguments) {
+			b := args.Get(1).([]rpc.BatchElem)
+			b[1].Error = errors.New("boom")
+		},
+	).Once()
+	mockJSONRPC.On("BatchCallContext", ctx, batchLen(1)).Return(nil).Once()
+
+	reqs := newBatchElems("eth_getBalance", "eth_getBalance", "eth_getBalance")
+	assert.NoError(t, sdkClient.BatchCallContext(ctx, reqs))
+	assert.Nil(t, reqs[0].Error)
+	assert.EqualError(t, reqs[1].Error, "boom")
+	assert.Nil(t, reqs[2].Error)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBatchCallContext_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient:    &RPCClient{JSONRPC: mockJSONRPC},
+		maxBatchSize: 1,
+	}
+
+	mockJSONRPC.On("BatchCallContext", ctx, batchLen(1)).Return(errors.New("too many requests")).Once()
+
+	err := sdkClient.BatchCallContext(ctx, newBatchElems("eth_getBalance", "eth_getBalance"))
+	assert.EqualError(t, err, "too many requests")
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBatchCallContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient:    &RPCClient{JSONRPC: mockJSONRPC},
+		maxBatchSize: 1,
+	}
+
+	// The caller goes away while the first batch is in flight
+	mockJSONRPC.On("BatchCallContext", ctx, batchLen(1)).Return(nil).Run(
+		func(args mock.Arguments) { cancel() },
+	).Once()
+
+	reqs := newBatchElems("eth_getBalance", "eth_getBalance", "eth_getBalance")
+	err := sdkClient.BatchCallContext(ctx, reqs)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, reqs[0].Error)
+	assert.ErrorIs(t, reqs[1].Error, context.Canceled)
+	assert.ErrorIs(t, reqs[2].Error, context.Canceled)
+
+	mockJSONRPC.AssertExpectations(t)
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// MaxSafeJSONInteger is the largest integer that a JSON number decoded
+// into a float64 is guaranteed to hold exactly (2^53).
+const MaxSafeJSONInteger = 1 << 53
+
+// ErrPrecisionLoss is returned when a JSON number cannot be converted
+// into an integer without losing precision.
+var ErrPrecisionLoss = errors.New("number cannot be represented without losing precision")
+
+var maxSafeJSONInteger = big.NewInt(MaxSafeJSONInteger)
+
+// BigIntFromJSON converts a decoded JSON value into a *big.Int.
+//
+// Strings may be decimal or 0x prefixed hex. Numbers must be integers, and
+// numbers decoded as float64 must not be larger than MaxSafeJSONInteger since
+// they may already have been rounded. Pass large amounts as strings instead.
+func BigIntFromJSON(v interface{}) (*big.Int, error) {
+	switch t := v.(type) {
+	case string:
+		if has0xPrefix(t) {
+			if i, ok := new(big.Int).SetString(t[2:], 16); ok { // nolint:gomnd
+				return i, nil
+			}
+		} else if i, ok := new(big.Int).SetString(t, 10); ok { // nolint:gomnd
+			return i, nil
+		}
+		return nil, fmt.Errorf("%q is not a valid integer", t)
+	case json.Number:
+		i, ok := new(big.Int).SetString(t.String(), 10) // nolint:gomnd
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", t, ErrPrecisionLoss)
+		}
+		return i, nil
+	case float64:
+		if t != math.Trunc(t) || math.Abs(t) > MaxSafeJSONInteger {
+			return nil, fmt.Errorf("%v: %w", t, ErrPrecisionLoss)
+		}
+		return big.NewInt(int64(t)), nil
+	default:
+		return nil, fmt.Errorf("%v is not a valid integer", v)
+	}
+}
+
+// BigIntToJSON returns a JSON value for i that round trips without losing
+// precision. Integers up to MaxSafeJSONInteger are returned as float64, like
+// encoding/json decodes them, and larger integers as json.Number.
+func BigIntToJSON(i *big.Int) interface{} {
+	if i == nil {
+		return nil
+	}
+	if new(big.Int).Abs(i).Cmp(maxSafeJSONInteger) <= 0 {
+		return float64(i.Int64())
+	}
+
+	return json.Number(i.String())
+}
+
+// normalizeJSONNumbers replaces the json.Number values of a map decoded with
+// UseNumber, keeping large integers as json.Number so they are not rounded.
+func normalizeJSONNumbers(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			n, err := normalizeJSONNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = n
+		}
+	case []interface{}:
+		for k, e := range t {
+			n, err := normalizeJSONNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = n
+		}
+	case json.Number:
+		if i, ok := new(big.Int).SetString(t.String(), 10); ok { // nolint:gomnd
+			return BigIntToJSON(i), nil
+		}
+		return t.Float64()
+	}
+
+	return v, nil
+}
+
+// canonicalJSONNumbers replaces the json.Number values of a map decoded with
+// UseNumber like normalizeJSONNumbers, except that integers larger than
+// MaxSafeJSONInteger become decimal strings, which decoders parsing numbers
+// as float64 don't round.
+func canonicalJSONNumbers(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			n, err := canonicalJSONNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = n
+		}
+	case []interface{}:
+		for k, e := range t {
+			n, err := canonicalJSONNumbers(e)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = n
+		}
+	case json.Number:
+		if i, ok := new(big.Int).SetString(t.String(), 10); ok { // nolint:gomnd
+			if new(big.Int).Abs(i).Cmp(maxSafeJSONInteger) <= 0 {
+				return float64(i.Int64()), nil
+			}
+			return i.String(), nil
+		}
+		return t.Float64()
+	}
+
+	return v, nil
+}
+
+// checkJSONNumbers returns ErrPrecisionLoss if v contains a float64 integer
+// larger than MaxSafeJSONInteger, which may have been rounded when decoded.
+func checkJSONNumbers(v interface{}) error {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			if err := checkJSONNumbers(e); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			if err := checkJSONNumbers(e); err != nil {
+				return err
+			}
+		}
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) > MaxSafeJSONInteger {
+			return fmt.Errorf("%v: %w", t, ErrPrecisionLoss)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigIntFromJSON(t *testing.T) {
+	large, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	tests := map[string]struct {
+		value    interface{}
+		expected *big.Int
+		err      bool
+	}{
+		"decimal string": {
+			value:    "123456789012345678901234567890",
+			expected: large,
+		},
+		"hex string": {
+			value:    "0x2a",
+			expected: big.NewInt(42),
+		},
+		"json number": {
+			value:    json.Number("123456789012345678901234567890"),
+			expected: large,
+		},
+		"safe float": {
+			value:    float64(MaxSafeJSONInteger),
+			expected: big.NewInt(MaxSafeJSONInteger),
+		},
+		"unsafe float": {
+			value: float64(MaxSafeJSONInteger * 4),
+			err:   true,
+		},
+		"fractional float": {
+			value: 1.5,
+			err:   true,
+		},
+		"fractional json number": {
+			value: json.Number("1.5"),
+			err:   true,
+		},
+		"invalid string": {
+			value: "1e18",
+			err:   true,
+		},
+		"invalid type": {
+			value: true,
+			err:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			i, err := BigIntFromJSON(test.value)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 0, test.expected.Cmp(i))
+		})
+	}
+}
+
+func TestJSONMapPreservesBigInts(t *testing.T) {
+	type payload struct {
+		Small *big.Int `json:"small"`
+		Large *big.Int `json:"large"`
+		Rate  float64  `json:"rate"`
+	}
+
+	large, _ := new(big.Int).SetString("1000000000000000000001", 10)
+	in := &payload{
+		Small: big.NewInt(21000),
+		Large: large,
+		Rate:  1.5,
+	}
+
+	m, err := MarshalJSONMap(in)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(21000), m["small"])
+	assert.Equal(t, json.Number("1000000000000000000001"), m["large"])
+	assert.Equal(t, 1.5, m["rate"])
+
+	var out payload
+	assert.NoError(t, UnmarshalJSONMap(m, &out))
+	assert.Equal(t, in, &out)
+}
+
+func TestUnmarshalJSONMapRejectsPrecisionLoss(t *testing.T) {
+	var out struct {
+		GasPrice *big.Int `json:"gas_price"`
+	}
+
+	err := UnmarshalJSONMap(map[string]interface{}{"gas_price": float64(1e20)}, &out)
+	assert.ErrorIs(t, err, ErrPrecisionLoss)
+}
+
+func TestMarshalCanonicalJSONMap(t *testing.T) {
+	type payload struct {
+		Small *big.Int          `json:"small"`
+		Large *big.Int          `json:"large"`
+		Rate  float64           `json:"rate"`
+		Calls []*big.Int        `json:"calls"`
+		Extra map[string]uint64 `json:"extra"`
+	}
+
+	large, _ := new(big.Int).SetString("1000000000000000000001", 10)
+	m, err := MarshalCanonicalJSONMap(&payload{
+		Small: big.NewInt(21000),
+		Large: large,
+		Rate:  1.5,
+		Calls: []*big.Int{big.NewInt(-1), new(big.Int).Neg(large)},
+		Extra: map[string]uint64{"b": 1, "a": 1 << 60},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"small": float64(21000),
+		"large": "1000000000000000000001",
+		"rate":  1.5,
+		"calls": []interface{}{float64(-1), "-1000000000000000000001"},
+		"extra": map[string]interface{}{"a": "1152921504606846976", "b": float64(1)},
+	}, m)
+
+	// The map encodes to the same JSON after it is decoded as is
+	encoded, err := json.Marshal(m)
+	assert.NoError(t, err)
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	reencoded, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, string(encoded), string(reencoded))
+	assert.Equal(
+		t,
+		`{"calls":[-1,"-1000000000000000000001"],"extra":{"a":"1152921504606846976","b":1},`+
+			`"large":"1000000000000000000001","rate":1.5,"small":21000}`,
+		string(encoded),
+	)
+}
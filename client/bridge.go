@@ -0,0 +1,301 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// bridgeEvent is a configuration.BridgeEvent ready to match logs
+type bridgeEvent struct {
+	contract common.Address
+	event    abi.Event
+	indexed  abi.Arguments
+	deposit  bool
+	account  string
+	amount   string
+	opType   string
+	currency *RosettaTypes.Currency
+}
+
+// BridgeEventParser populates the cross-chain transactions of a block from the
+// events of bridge contracts, described by configuration.BridgeEvent. Each event
+// becomes a transaction with a single operation crediting or debiting the
+// account of the event, identified by the hash of the transaction emitting the
+// event and the index of the log.
+//
+// It is meant for bridges whose transfers are not visible in the traces of the
+// transaction, like mints of the native currency by a message service. Transfers
+// that are already calls of the transaction would be counted twice.
+type BridgeEventParser struct {
+	events []*bridgeEvent
+}
+
+// NewBridgeEventParser returns a parser of the events of descriptors. Transfers
+// are in currency unless the descriptor has its own currency.
+func NewBridgeEventParser(
+	descriptors []configuration.BridgeEvent,
+	currency *RosettaTypes.Currency,
+) (*BridgeEventParser, error) {
+	events := make([]*bridgeEvent, 0, len(descriptors))
+	for i, descriptor := range descriptors {
+		event, err := newBridgeEvent(descriptor, currency)
+		if err != nil {
+			return nil, fmt.Errorf("bridge event %d: %w", i, err)
+		}
+		events = append(events, event)
+	}
+
+	return &BridgeEventParser{events: events}, nil
+}
+
+// OperationTypes returns the operation types of the transactions of the parser
+func (p *BridgeEventParser) OperationTypes() []string {
+	var opTypes []string
+	seen := map[string]bool{}
+	for _, event := range p.events {
+		if !seen[event.opType] {
+			seen[event.opType] = true
+			opTypes = append(opTypes, event.opType)
+		}
+	}
+
+	return opTypes
+}
+
+func newBridgeEvent(descriptor configuration.BridgeEvent, currency *RosettaTypes.Currency) (*bridgeEvent, error) {
+	if !common.IsHexAddress(descriptor.Contract) {
+		return nil, fmt.Errorf("invalid contract %q", descriptor.Contract)
+	}
+	event, err := parseEventSignature(descriptor.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	bridge := &bridgeEvent{
+		contract: common.HexToAddress(descriptor.Contract),
+		event:    event,
+		account:  descriptor.Account,
+		amount:   descriptor.Amount,
+		opType:   descriptor.OperationType,
+		currency: currency,
+	}
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			bridge.indexed = append(bridge.indexed, input)
+		}
+	}
+
+	switch descriptor.Direction {
+	case configuration.DepositBridgeDirection:
+		bridge.deposit = true
+		if bridge.opType == "" {
+			bridge.opType = sdkTypes.BridgeDepositOpType
+		}
+	case configuration.WithdrawalBridgeDirection:
+		if bridge.opType == "" {
+			bridge.opType = sdkTypes.BridgeWithdrawalOpType
+		}
+	default:
+		return nil, fmt.Errorf("unsupported direction %q", descriptor.Direction)
+	}
+	if descriptor.Currency != nil {
+		bridge.currency = descriptor.Currency
+	}
+
+	if err := checkEventArgument(event, descriptor.Account, abi.AddressTy); err != nil {
+		return nil, fmt.Errorf("account: %w", err)
+	}
+	if err := checkEventArgument(event, descriptor.Amount, abi.UintTy); err != nil {
+		return nil, fmt.Errorf("amount: %w", err)
+	}
+
+	return bridge, nil
+}
+
+// parseEventSignature parses an event signature with named arguments, like
+// "Transfer(address indexed from, address indexed to, uint256 value)"
+func parseEventSignature(signature string) (abi.Event, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return abi.Event{}, fmt.Errorf("invalid event signature %q", signature)
+	}
+	name := strings.TrimSpace(signature[:open])
+	params := strings.TrimSpace(signature[open+1 : len(signature)-1])
+
+	var inputs abi.Arguments
+	if len(params) > 0 {
+		for i, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			indexed := len(fields) > 1 && fields[1] == "indexed"
+			if indexed {
+				fields = append(fields[:1], fields[2:]...)
+			}
+			if len(fields) == 0 || len(fields) > 2 {
+				return abi.Event{}, fmt.Errorf("invalid argument %d %q of event signature %q", i, param, signature)
+			}
+			typ, err := abi.NewType(fields[0], "", nil)
+			if err != nil {
+				return abi.Event{}, fmt.Errorf("invalid type of argument %d of event signature %q: %w", i, signature, err)
+			}
+			argument := abi.Argument{Name: fmt.Sprintf("arg%d", i), Type: typ, Indexed: indexed}
+			if len(fields) == 2 {
+				argument.Name = fields[1]
+			}
+			inputs = append(inputs, argument)
+		}
+	}
+
+	return abi.NewEvent(name, name, false, inputs), nil
+}
+
+// checkEventArgument checks that event has an argument name of type typ
+func checkEventArgument(event abi.Event, name string, typ byte) error {
+	for _, input := range event.Inputs {
+		if input.Name != name {
+			continue
+		}
+		if input.Type.T != typ {
+			return fmt.Errorf("argument %s of %s has type %s", name, event.Sig, input.Type)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%s has no argument %q", event.Sig, name)
+}
+
+// CrossChainTransactions returns the cross-chain transactions of the bridge
+// events emitted by the successful transactions of the block, in log order
+func (p *BridgeEventParser) CrossChainTransactions(
+	block *EthTypes.Block,
+	txs []*LoadedTransaction,
+) ([]*RosettaTypes.Transaction, error) {
+	var transactions []*RosettaTypes.Transaction
+	for _, tx := range txs {
+		if tx.Receipt == nil || tx.Receipt.Status != EthTypes.ReceiptStatusSuccessful {
+			continue
+		}
+		for _, log := range tx.Receipt.Logs {
+			event := p.match(log)
+			if event == nil {
+				continue
+			}
+			transaction, err := event.transaction(tx, log)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"could not parse %s of %s in block %d: %w",
+					event.event.Sig,
+					tx.TxHash,
+					block.NumberU64(),
+					err,
+				)
+			}
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	return transactions, nil
+}
+
+// match returns the bridge event of log, or nil if it is not a bridge event
+func (p *BridgeEventParser) match(log *EthTypes.Log) *bridgeEvent {
+	if log.Removed || len(log.Topics) == 0 {
+		return nil
+	}
+	for _, event := range p.events {
+		if log.Address == event.contract && log.Topics[0] == event.event.ID {
+			return event
+		}
+	}
+
+	return nil
+}
+
+// transaction returns the cross-chain transaction of log, emitted by tx
+func (e *bridgeEvent) transaction(tx *LoadedTransaction, log *EthTypes.Log) (*RosettaTypes.Transaction, error) {
+	if len(log.Topics) != len(e.indexed)+1 {
+		return nil, fmt.Errorf("log %d has %d topics, expected %d", log.Index, len(log.Topics), len(e.indexed)+1)
+	}
+	values := map[string]interface{}{}
+	if err := e.event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return nil, err
+	}
+	if err := abi.ParseTopicsIntoMap(values, e.indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	account, ok := values[e.account].(common.Address)
+	if !ok {
+		return nil, fmt.Errorf("account %s is not an address", e.account)
+	}
+	amount, err := bridgeAmount(values[e.amount])
+	if err != nil {
+		return nil, err
+	}
+	if !e.deposit {
+		amount = new(big.Int).Neg(amount)
+	}
+
+	txHash := tx.TxHash.Hex()
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: fmt.Sprintf("%s-%d", txHash, log.Index),
+		},
+		Operations: []*RosettaTypes.Operation{{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                e.opType,
+			Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: FormatAddress(account)},
+			Amount:              &RosettaTypes.Amount{Value: amount.String(), Currency: e.currency},
+		}},
+		RelatedTransactions: []*RosettaTypes.RelatedTransaction{{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: txHash},
+			Direction:             RosettaTypes.Backward,
+		}},
+		Metadata: map[string]interface{}{
+			"event":     e.event.Sig,
+			"contract":  e.contract.Hex(),
+			"log_index": log.Index,
+		},
+	}, nil
+}
+
+// bridgeAmount returns the unsigned integer value of an event argument
+func bridgeAmount(value interface{}) (*big.Int, error) {
+	switch amount := value.(type) {
+	case *big.Int:
+		return amount, nil
+	case uint8:
+		return new(big.Int).SetUint64(uint64(amount)), nil
+	case uint16:
+		return new(big.Int).SetUint64(uint64(amount)), nil
+	case uint32:
+		return new(big.Int).SetUint64(uint64(amount)), nil
+	case uint64:
+		return new(big.Int).SetUint64(amount), nil
+	default:
+		return nil, fmt.Errorf("amount %v is not an unsigned integer", value)
+	}
+}
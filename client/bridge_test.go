@@ -0,0 +1,212 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testMessageService = common.HexToAddress("0x508Ca82Df566dCD1B0DE8296e70a96332cD644ec")
+	testClaimedTopic   = crypto.Keccak256Hash([]byte("MessageClaimed(bytes32,address,uint256)"))
+	testSentTopic      = crypto.Keccak256Hash([]byte("MessageSent(address,uint256)"))
+)
+
+var testBridgeEvents = []configuration.BridgeEvent{
+	{
+		Contract:  testMessageService.Hex(),
+		Event:     "MessageClaimed(bytes32 indexed messageHash, address indexed to, uint256 value)",
+		Direction: configuration.DepositBridgeDirection,
+		Account:   "to",
+		Amount:    "value",
+	},
+	{
+		Contract:      testMessageService.Hex(),
+		Event:         "MessageSent(address indexed from, uint256 value)",
+		Direction:     configuration.WithdrawalBridgeDirection,
+		Account:       "from",
+		Amount:        "value",
+		OperationType: "MESSAGE_SENT",
+	},
+}
+
+func TestBridgeEventParser(t *testing.T) {
+	eth := &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18}
+	parser, err := NewBridgeEventParser(testBridgeEvents, eth)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sdkTypes.BridgeDepositOpType, "MESSAGE_SENT"}, parser.OperationTypes())
+
+	user := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	claimHash := common.HexToHash("0x01")
+	sendHash := common.HexToHash("0x02")
+	txs := []*LoadedTransaction{
+		{
+			TxHash: &claimHash,
+			Receipt: &RosettaTxReceipt{
+				Status: EthTypes.ReceiptStatusSuccessful,
+				Logs: []*EthTypes.Log{
+					{
+						Address: testMessageService,
+						Topics:  []common.Hash{testClaimedTopic, common.HexToHash("0xaa"), common.BytesToHash(user.Bytes())},
+						Data:    common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+						Index:   3,
+					},
+					{
+						// Same event from another contract
+						Address: user,
+						Topics:  []common.Hash{testClaimedTopic, common.HexToHash("0xaa"), common.BytesToHash(user.Bytes())},
+						Data:    common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+						Index:   4,
+					},
+				},
+			},
+		},
+		{
+			TxHash: &sendHash,
+			Receipt: &RosettaTxReceipt{
+				Status: EthTypes.ReceiptStatusSuccessful,
+				Logs: []*EthTypes.Log{{
+					Address: testMessageService,
+					Topics:  []common.Hash{testSentTopic, common.BytesToHash(user.Bytes())},
+					Data:    common.LeftPadBytes(big.NewInt(40).Bytes(), 32),
+					Index:   7,
+				}},
+			},
+		},
+		{
+			TxHash: &sendHash,
+			Receipt: &RosettaTxReceipt{
+				Status: EthTypes.ReceiptStatusFailed,
+				Logs: []*EthTypes.Log{{
+					Address: testMessageService,
+					Topics:  []common.Hash{testSentTopic, common.BytesToHash(user.Bytes())},
+					Data:    common.LeftPadBytes(big.NewInt(40).Bytes(), 32),
+				}},
+			},
+		},
+	}
+	block := EthTypes.NewBlockWithHeader(&EthTypes.Header{Number: big.NewInt(10)})
+
+	transactions, err := parser.CrossChainTransactions(block, txs)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.Transaction{
+		{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: claimHash.Hex() + "-3"},
+			Operations: []*RosettaTypes.Operation{{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+				Type:                sdkTypes.BridgeDepositOpType,
+				Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+				Account:             &RosettaTypes.AccountIdentifier{Address: user.Hex()},
+				Amount:              &RosettaTypes.Amount{Value: "100", Currency: eth},
+			}},
+			RelatedTransactions: []*RosettaTypes.RelatedTransaction{{
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: claimHash.Hex()},
+				Direction:             RosettaTypes.Backward,
+			}},
+			Metadata: map[string]interface{}{
+				"event":     "MessageClaimed(bytes32,address,uint256)",
+				"contract":  testMessageService.Hex(),
+				"log_index": uint(3),
+			},
+		},
+		{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: sendHash.Hex() + "-7"},
+			Operations: []*RosettaTypes.Operation{{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+				Type:                "MESSAGE_SENT",
+				Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+				Account:             &RosettaTypes.AccountIdentifier{Address: user.Hex()},
+				Amount:              &RosettaTypes.Amount{Value: "-40", Currency: eth},
+			}},
+			RelatedTransactions: []*RosettaTypes.RelatedTransaction{{
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: sendHash.Hex()},
+				Direction:             RosettaTypes.Backward,
+			}},
+			Metadata: map[string]interface{}{
+				"event":     "MessageSent(address,uint256)",
+				"contract":  testMessageService.Hex(),
+				"log_index": uint(7),
+			},
+		},
+	}, transactions)
+
+	// A log that doesn't match the signature of its topic fails
+	txs[0].Receipt.Logs[0].Topics = txs[0].Receipt.Logs[0].Topics[:2]
+	_, err = parser.CrossChainTransactions(block, txs)
+	assert.EqualError(
+		t,
+		err,
+		"could not parse MessageClaimed(bytes32,address,uint256) of "+claimHash.Hex()+
+			" in block 10: log 3 has 2 topics, expected 3",
+	)
+}
+
+func TestNewBridgeEventParser(t *testing.T) {
+	tests := map[string]struct {
+		update        func(event *configuration.BridgeEvent)
+		expectedError string
+	}{
+		"invalid signature": {
+			update: func(event *configuration.BridgeEvent) {
+				event.Event = "MessageClaimed"
+			},
+			expectedError: `bridge event 0: invalid event signature "MessageClaimed"`,
+		},
+		"invalid type": {
+			update: func(event *configuration.BridgeEvent) {
+				event.Event = "MessageClaimed(bytes32 indexed messageHash, account indexed to, uint256 value)"
+			},
+			expectedError: "bridge event 0: invalid type of argument 1 of event signature " +
+				`"MessageClaimed(bytes32 indexed messageHash, account indexed to, uint256 value)": unsupported arg type: account`,
+		},
+		"missing account": {
+			update: func(event *configuration.BridgeEvent) {
+				event.Account = "recipient"
+			},
+			expectedError: `bridge event 0: account: MessageClaimed(bytes32,address,uint256) has no argument "recipient"`,
+		},
+		"amount is not an integer": {
+			update: func(event *configuration.BridgeEvent) {
+				event.Amount = "messageHash"
+			},
+			expectedError: "bridge event 0: amount: argument messageHash of MessageClaimed(bytes32,address,uint256) has type bytes32",
+		},
+		"unsupported direction": {
+			update: func(event *configuration.BridgeEvent) {
+				event.Direction = "mint"
+			},
+			expectedError: `bridge event 0: unsupported direction "mint"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			event := testBridgeEvents[0]
+			test.update(&event)
+			_, err := NewBridgeEventParser([]configuration.BridgeEvent{event}, nil)
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BSCSystemTransactionExtension is the extension key holding the kind of a BNB
+// Smart Chain system transaction, see BSCTransactionFilter
+const BSCSystemTransactionExtension = "bsc_system_transaction"
+
+// Kinds of BNB Smart Chain system transactions
+const (
+	// BSCValidatorReward deposits the validator share of the block fees into
+	// the ValidatorSet contract
+	BSCValidatorReward = "validator_reward"
+
+	// BSCSystemReward sends the system share of the block fees to the
+	// SystemReward contract
+	BSCSystemReward = "system_reward"
+
+	// BSCValidatorSetUpdate updates the validator set, once a day
+	BSCValidatorSetUpdate = "validator_set_update"
+
+	// BSCSlash slashes a validator that missed its blocks
+	BSCSlash = "slash"
+
+	// BSCFinalityReward distributes the rewards of fast finality votes
+	BSCFinalityReward = "finality_reward"
+
+	// BSCSystemCall is any other call of a system contract by the validator
+	BSCSystemCall = "system_call"
+)
+
+// BNB Smart Chain system contracts
+var (
+	BSCValidatorSetContract = common.HexToAddress("0x0000000000000000000000000000000000001000")
+	BSCSlashContract        = common.HexToAddress("0x0000000000000000000000000000000000001001")
+	BSCSystemRewardContract = common.HexToAddress("0x0000000000000000000000000000000000001002")
+)
+
+var (
+	bscDepositSelector                  = crypto.Keccak256([]byte("deposit(address)"))[:4]
+	bscUpdateValidatorSetSelector       = crypto.Keccak256([]byte("updateValidatorSetV2(address[],uint64[],bytes[])"))[:4]
+	bscSlashSelector                    = crypto.Keccak256([]byte("slash(address)"))[:4]
+	bscDistributeFinalityRewardSelector = crypto.Keccak256([]byte("distributeFinalityReward(address[],uint256[])"))[:4]
+)
+
+// BSCTransactionFilter is the TransactionFilter of the BNB Smart Chain chain
+// profile. The Parlia consensus engine appends system transactions to each
+// block, sent by the validator to the system contracts without gas price. The
+// block fees are credited to the validator, which distributes them with the
+// system transactions, part to the SystemReward contract and the rest to the
+// ValidatorSet contract. The shares are taken from the distribution transactions
+// rather than assumed, since they changed with hard forks and the SystemReward
+// share is skipped once the contract holds enough.
+//
+// System transactions are classified with BSCSystemTransactionExtension. Those
+// that move no value, like the daily validator set updates, are dropped, since
+// they have no balance changes.
+type BSCTransactionFilter struct{}
+
+// FilterTransaction implements TransactionFilter
+func (BSCTransactionFilter) FilterTransaction(_ context.Context, tx *LoadedTransaction) (TransactionFilterAction, error) {
+	kind, ok := bscSystemTransaction(tx)
+	if !ok {
+		return KeepTransaction, nil
+	}
+
+	tx.SetExtension(BSCSystemTransactionExtension, kind)
+	if !movesValue(tx) {
+		return DropTransaction, nil
+	}
+
+	return KeepTransaction, nil
+}
+
+// bscSystemTransaction returns the kind of tx if it is a system transaction,
+// a call of a system contract by the validator of the block without gas price
+func bscSystemTransaction(tx *LoadedTransaction) (string, bool) {
+	to := tx.Transaction.To()
+	if to == nil || !isBSCSystemContract(*to) || tx.From == nil {
+		return "", false
+	}
+	if tx.Transaction.GasPrice().Sign() != 0 {
+		return "", false
+	}
+	validator := tx.Miner
+	if len(tx.Author) > 0 {
+		validator = tx.Author
+	}
+	if !common.IsHexAddress(validator) || common.HexToAddress(validator) != *tx.From {
+		return "", false
+	}
+
+	data := tx.Transaction.Data()
+	switch {
+	case *to == BSCValidatorSetContract && hasSelector(data, bscDepositSelector):
+		return BSCValidatorReward, true
+	case *to == BSCSystemRewardContract:
+		return BSCSystemReward, true
+	case *to == BSCValidatorSetContract && hasSelector(data, bscUpdateValidatorSetSelector):
+		return BSCValidatorSetUpdate, true
+	case *to == BSCSlashContract && hasSelector(data, bscSlashSelector):
+		return BSCSlash, true
+	case *to == BSCValidatorSetContract && hasSelector(data, bscDistributeFinalityRewardSelector):
+		return BSCFinalityReward, true
+	default:
+		return BSCSystemCall, true
+	}
+}
+
+// isBSCSystemContract returns whether address is one of the system contracts
+// deployed in genesis or by hard forks, from 0x...1000 to 0x...3000
+func isBSCSystemContract(address common.Address) bool {
+	for _, b := range address[:common.AddressLength-2] {
+		if b != 0 {
+			return false
+		}
+	}
+	suffix := uint16(address[common.AddressLength-2])<<8 | uint16(address[common.AddressLength-1])
+	return suffix >= 0x1000 && suffix <= 0x3000
+}
+
+// hasSelector returns whether the call data calls the method of selector
+func hasSelector(data []byte, selector []byte) bool {
+	return len(data) >= len(selector) && bytes.Equal(data[:len(selector)], selector)
+}
+
+// movesValue returns whether tx or any of its successful calls transfers value
+func movesValue(tx *LoadedTransaction) bool {
+	if tx.Transaction.Value().Sign() > 0 {
+		return true
+	}
+	for _, call := range tx.Trace {
+		if !call.Revert && call.Value != nil && call.Value.Sign() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBSCTransactionFilter(t *testing.T) {
+	validator := common.HexToAddress("0x72b61c6014342d914470ec7ac2975be345796c2b")
+	user := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	depositData := append(append([]byte{}, bscDepositSelector...), common.LeftPadBytes(validator.Bytes(), 32)...)
+
+	tests := map[string]struct {
+		from           common.Address
+		to             common.Address
+		value          int64
+		gasPrice       int64
+		data           []byte
+		trace          []*FlatCall
+		author         string
+		expectedAction TransactionFilterAction
+		expectedKind   string
+	}{
+		"validator reward": {
+			from:           validator,
+			to:             BSCValidatorSetContract,
+			value:          90,
+			data:           depositData,
+			expectedAction: KeepTransaction,
+			expectedKind:   BSCValidatorReward,
+		},
+		"system reward": {
+			from:           validator,
+			to:             BSCSystemRewardContract,
+			value:          10,
+			expectedAction: KeepTransaction,
+			expectedKind:   BSCSystemReward,
+		},
+		"validator set update": {
+			from:           validator,
+			to:             BSCValidatorSetContract,
+			data:           bscUpdateValidatorSetSelector,
+			expectedAction: DropTransaction,
+			expectedKind:   BSCValidatorSetUpdate,
+		},
+		"validator set update paying out": {
+			from:           validator,
+			to:             BSCValidatorSetContract,
+			data:           bscUpdateValidatorSetSelector,
+			trace:          []*FlatCall{{From: BSCValidatorSetContract, To: user, Value: big.NewInt(5)}},
+			expectedAction: KeepTransaction,
+			expectedKind:   BSCValidatorSetUpdate,
+		},
+		"reverted payout": {
+			from:           validator,
+			to:             BSCSlashContract,
+			data:           bscSlashSelector,
+			trace:          []*FlatCall{{From: BSCSlashContract, To: user, Value: big.NewInt(5), Revert: true}},
+			expectedAction: DropTransaction,
+			expectedKind:   BSCSlash,
+		},
+		"finality reward": {
+			from:           validator,
+			to:             BSCValidatorSetContract,
+			data:           bscDistributeFinalityRewardSelector,
+			expectedAction: DropTransaction,
+			expectedKind:   BSCFinalityReward,
+		},
+		"other system call": {
+			from:           validator,
+			to:             common.HexToAddress("0x0000000000000000000000000000000000002002"),
+			data:           []byte{0x01, 0x02, 0x03, 0x04},
+			expectedAction: DropTransaction,
+			expectedKind:   BSCSystemCall,
+		},
+		"block author": {
+			from:           validator,
+			to:             BSCSystemRewardContract,
+			value:          10,
+			author:         validator.Hex(),
+			expectedAction: KeepTransaction,
+			expectedKind:   BSCSystemReward,
+		},
+		"user call of a system contract": {
+			from:           user,
+			to:             BSCValidatorSetContract,
+			data:           bscUpdateValidatorSetSelector,
+			expectedAction: KeepTransaction,
+		},
+		"validator call with gas price": {
+			from:           validator,
+			to:             BSCValidatorSetContract,
+			gasPrice:       1,
+			data:           bscUpdateValidatorSetSelector,
+			expectedAction: KeepTransaction,
+		},
+		"not a system contract": {
+			from:           validator,
+			to:             common.HexToAddress("0x0000000000000000000000000000000000000fff"),
+			expectedAction: KeepTransaction,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := &LoadedTransaction{
+				Transaction: EthTypes.NewTransaction(
+					0, test.to, big.NewInt(test.value), 100000, big.NewInt(test.gasPrice), test.data,
+				),
+				From:  &test.from,
+				Trace: test.trace,
+			}
+			if test.author != "" {
+				tx.Author = test.author
+			} else {
+				tx.Miner = validator.Hex()
+			}
+
+			action, err := BSCTransactionFilter{}.FilterTransaction(context.Background(), tx)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedAction, action)
+			kind, ok := Extension[string](tx, BSCSystemTransactionExtension)
+			assert.Equal(t, test.expectedKind != "", ok)
+			assert.Equal(t, test.expectedKind, kind)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// FeeCurrencyExtension is the extension key holding the ERC20 token a
+	// transaction pays its fees in, on chains with fee currencies like Celo
+	FeeCurrencyExtension = "feeCurrency"
+
+	// CIP64TxType is the type of Celo dynamic fee transactions paying their
+	// fees in a fee currency, see CIP-64
+	CIP64TxType = 0x7b
+)
+
+// FeeCurrencyAddress returns the ERC20 token tx pays its fees in, if it doesn't
+// pay them in the native currency
+func FeeCurrencyAddress(tx *LoadedTransaction) (common.Address, bool) {
+	value, ok := Extension[string](tx, FeeCurrencyExtension)
+	if !ok || !common.IsHexAddress(value) {
+		return common.Address{}, false
+	}
+	address := common.HexToAddress(value)
+
+	return address, address != (common.Address{})
+}
+
+// decodeFeeCurrencyTransaction decodes a JSON RPC CIP-64 transaction, which
+// go-ethereum doesn't support, as the dynamic fee transaction it extends. The
+// fee currency is kept in the extensions and the hash in TxExtraInfo, since the
+// hash of the decoded transaction is the one of a dynamic fee transaction.
+func decodeFeeCurrencyTransaction(msg []byte) (*EthTypes.Transaction, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return nil, false, err
+	}
+	var txType hexutil.Uint64
+	if err := json.Unmarshal(fields["type"], &txType); err != nil || txType != CIP64TxType {
+		return nil, false, nil
+	}
+
+	fields["type"] = json.RawMessage(`"0x2"`)
+	dynamicFeeTx, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	var tx EthTypes.Transaction
+	if err := json.Unmarshal(dynamicFeeTx, &tx); err != nil {
+		return nil, false, err
+	}
+
+	return &tx, true, nil
+}
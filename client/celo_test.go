@@ -0,0 +1,95 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCIP64Transaction = `{
+	"blockHash": "0x64b4aa5e38bc5bd6ef85c2d2ba5a8b4ac0a2d1b5cc4aa4bb3e41b4d1bd1b2a2e",
+	"blockNumber": "0x1a",
+	"chainId": "0xa4ec",
+	"feeCurrency": "0x765de816845861e75a25fca122bb6898b8b1282a",
+	"from": "0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0",
+	"gas": "0xea60",
+	"gasPrice": "0x7d0",
+	"hash": "0x9a3bde2a4d2e2ccc0b0cd5cb7b6ec6e2cfb8a8f62d3dd4a7d4e0c1f0b9d2a3b4",
+	"input": "0x",
+	"maxFeePerGas": "0x7d0",
+	"maxPriorityFeePerGas": "0x3e8",
+	"nonce": "0x2",
+	"r": "0x1",
+	"s": "0x1",
+	"to": "0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d",
+	"transactionIndex": "0x0",
+	"type": "0x7b",
+	"v": "0x0",
+	"value": "0x1"
+}`
+
+func TestDecodeFeeCurrencyTransaction(t *testing.T) {
+	var tx RPCTransaction
+	assert.NoError(t, json.Unmarshal([]byte(testCIP64Transaction), &tx))
+	assert.Equal(t, uint8(EthTypes.DynamicFeeTxType), tx.Tx.Type())
+	assert.Equal(t, uint64(2), tx.Tx.Nonce())
+	assert.Equal(t, uint64(60000), tx.Tx.Gas())
+	assert.Equal(t, int64(2000), tx.Tx.GasFeeCap().Int64())
+	assert.Equal(t,
+		common.HexToHash("0x9a3bde2a4d2e2ccc0b0cd5cb7b6ec6e2cfb8a8f62d3dd4a7d4e0c1f0b9d2a3b4"),
+		*tx.TxExtraInfo.TxHash,
+	)
+
+	loaded := &LoadedTransaction{Extensions: tx.Extensions}
+	address, ok := FeeCurrencyAddress(loaded)
+	assert.True(t, ok)
+	assert.Equal(t, common.HexToAddress("0x765de816845861e75a25fca122bb6898b8b1282a"), address)
+
+	// Other unsupported types still fail
+	var unsupported RPCTransaction
+	err := json.Unmarshal([]byte(`{"type": "0x7c", "nonce": "0x0"}`), &unsupported)
+	assert.ErrorIs(t, err, EthTypes.ErrTxTypeNotSupported)
+}
+
+func TestFeeCurrencyAddress(t *testing.T) {
+	tests := map[string]struct {
+		extensions map[string]interface{}
+		expected   bool
+	}{
+		"no extensions": {},
+		"native currency": {
+			extensions: map[string]interface{}{FeeCurrencyExtension: "0x0000000000000000000000000000000000000000"},
+		},
+		"invalid address": {
+			extensions: map[string]interface{}{FeeCurrencyExtension: "cUSD"},
+		},
+		"fee currency": {
+			extensions: map[string]interface{}{FeeCurrencyExtension: "0x765de816845861e75a25fca122bb6898b8b1282a"},
+			expected:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, ok := FeeCurrencyAddress(&LoadedTransaction{Extensions: test.extensions})
+			assert.Equal(t, test.expected, ok)
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
@@ -41,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	lru "github.com/hashicorp/golang-lru"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -56,7 +58,22 @@ type SDKClient struct {
 
 	traceSemaphore *semaphore.Weighted
 
-	skipAdminCalls bool
+	maxBatchSize       int
+	batchMethodWeights map[string]int
+
+	oldestBlock *oldestBlockCache
+
+	consensusEngine string
+	cliqueSigners   *lru.Cache
+
+	bridgeEvents *BridgeEventParser
+
+	traceDecoder TraceDecoder
+
+	tokenWhiteListSource *TokenWhiteListSource
+
+	// slowCalls is nil when slow call logging is disabled
+	slowCalls *slowCallLog
 }
 
 type ReplaceableRPCClient interface {
@@ -65,6 +82,9 @@ type ReplaceableRPCClient interface {
 
 // NewClient creates a client that connects to the network.
 func NewClient(cfg *configuration.Configuration, rpcClient *RPCClient, transport http.RoundTripper) (*SDKClient, error) {
+	if cfg.RosettaCfg.CompressRPCRequests {
+		transport = NewGzipRequestTransport(transport)
+	}
 	c, err := NewRPCClient(cfg.GethURL, transport)
 	if err != nil {
 		return nil, err
@@ -79,8 +99,7 @@ func NewClient(cfg *configuration.Configuration, rpcClient *RPCClient, transport
 		return nil, err
 	}
 
-	enableNativeTracer := cfg.RosettaCfg.TraceType == configuration.GethNativeTrace
-	tc, err := GetTraceConfig(enableNativeTracer)
+	tc, err := LoadTraceConfig(cfg.RosettaCfg)
 	if err != nil {
 		return nil, fmt.Errorf("unable to load trace config: %w", err)
 	}
@@ -90,6 +109,55 @@ func NewClient(cfg *configuration.Configuration, rpcClient *RPCClient, transport
 		customizedTc = cfg.RosettaCfg.CustomizedTraceConfig
 	}
 
+	var cliqueSigners *lru.Cache
+	if cfg.Consensus() == configuration.CliqueConsensusEngine {
+		cliqueSigners, err = lru.New(cliqueSignerCacheSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var bridgeEvents *BridgeEventParser
+	if len(cfg.RosettaCfg.BridgeEvents) > 0 {
+		bridgeEvents, err = NewBridgeEventParser(cfg.RosettaCfg.BridgeEvents, cfg.RosettaCfg.Currency)
+		if err != nil {
+			return nil, err
+		}
+		for _, opType := range bridgeEvents.OperationTypes() {
+			if err := sdkTypes.RegisterOpType(opType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cfg.RosettaCfg.SupportsOpStack {
+		if err := sdkTypes.RegisterOpType(sdkTypes.BridgeDepositOpType); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.RosettaCfg.SupportsWithdrawals {
+		if err := sdkTypes.RegisterOpType(sdkTypes.WithdrawalOpType); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.RosettaCfg.DecodeUserOperations {
+		if err := sdkTypes.RegisterOpType(sdkTypes.UserOperationOpType); err != nil {
+			return nil, err
+		}
+	}
+
+	var tokenWhiteListSource *TokenWhiteListSource
+	if len(cfg.RosettaCfg.TokenWhiteListURL) > 0 {
+		tokenWhiteListSource = NewTokenWhiteListSource(
+			cfg.RosettaCfg.TokenWhiteListURL,
+			cfg.RosettaCfg.TokenWhiteListRefresh(),
+			cfg.ChainConfig.ChainID.Uint64(),
+			&http.Client{Transport: NewDefaultHTTPTransport()},
+		)
+	}
+
 	return &SDKClient{
 		P:              cfg.ChainConfig,
 		tc:             tc,
@@ -98,10 +166,27 @@ func NewClient(cfg *configuration.Configuration, rpcClient *RPCClient, transport
 		RPCClient:      c,
 		EthClient:      ec,
 		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
+
+		maxBatchSize:       cfg.RosettaCfg.MaxBatchSize,
+		batchMethodWeights: cfg.RosettaCfg.BatchMethodWeights,
+
+		oldestBlock: &oldestBlockCache{},
+
+		consensusEngine: cfg.Consensus(),
+		cliqueSigners:   cliqueSigners,
+
+		bridgeEvents: bridgeEvents,
+
+		tokenWhiteListSource: tokenWhiteListSource,
+
+		slowCalls: newSlowCallLog(cfg.RosettaCfg),
 	}, nil
 }
 
 func (ec *SDKClient) WithRPCTransport(endpoint string, transport http.RoundTripper) (ReplaceableRPCClient, error) {
+	if ec.rosettaConfig.CompressRPCRequests {
+		transport = NewGzipRequestTransport(transport)
+	}
 	newClient, err := NewRPCClient(endpoint, transport)
 	if err != nil {
 		return ec, err
@@ -111,11 +196,17 @@ func (ec *SDKClient) WithRPCTransport(endpoint string, transport http.RoundTripp
 	return ec, nil
 }
 
+// PopulateCrossChainTransactions populates the cross-chain transactions of the
+// bridge events of RosettaConfig.BridgeEvents, see BridgeEventParser
 func (ec *SDKClient) PopulateCrossChainTransactions(
-	*EthTypes.Block,
-	[]*LoadedTransaction,
+	block *EthTypes.Block,
+	txs []*LoadedTransaction,
 ) ([]*RosettaTypes.Transaction, error) {
-	return nil, nil
+	if ec.bridgeEvents == nil {
+		return nil, nil
+	}
+
+	return ec.bridgeEvents.CrossChainTransactions(block, txs)
 }
 
 func (ec *SDKClient) GetRosettaConfig() configuration.RosettaConfig {
@@ -150,6 +241,10 @@ func (ec *SDKClient) Balance(
 	if err != nil {
 		return nil, fmt.Errorf("failed to get block header: %w", err)
 	}
+	blockHash, err := ec.HashHeader(header, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	var (
 		nativeBalance hexutil.Big
@@ -222,7 +317,7 @@ func (ec *SDKClient) Balance(
 	return &RosettaTypes.AccountBalanceResponse{
 		Balances: balances,
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
-			Hash:  header.Hash().Hex(),
+			Hash:  blockHash.Hex(),
 			Index: header.Number.Int64(),
 		},
 		Metadata: map[string]interface{}{
@@ -244,33 +339,37 @@ func (ec *SDKClient) Status(ctx context.Context) (
 	if err != nil {
 		return nil, -1, nil, nil, err
 	}
+	blockHash, err := ec.HashHeader(header, nil)
+	if err != nil {
+		return nil, -1, nil, nil, err
+	}
 
-	// Get sync status
-	var syncStatus *RosettaTypes.SyncStatus
+	// Get sync status. Nodes claiming to be synced are checked against the
+	// age of their current block.
+	var syncProgress *goEthereum.SyncProgress
 	if ec.rosettaConfig.SupportsSyncing {
-		syncProgress, err := ec.SyncProgress(ctx)
+		syncProgress, err = ec.SyncProgress(ctx)
 		if err != nil {
 			return nil, -1, nil, nil, err
 		}
-		if syncProgress != nil {
-			currentIndex := int64(syncProgress.CurrentBlock)
-			targetIndex := int64(syncProgress.HighestBlock)
-
-			syncStatus = &RosettaTypes.SyncStatus{
-				CurrentIndex: &currentIndex,
-				TargetIndex:  &targetIndex,
-			}
-		}
-	} else {
+	}
+	syncStatus := nodeSyncStatus(
+		syncProgress,
+		header.Number.Int64(),
+		time.Unix(int64(header.Time), 0),
+		time.Now(),
+		ec.rosettaConfig.SyncStalenessThreshold,
+	)
+	if syncStatus == nil && !ec.rosettaConfig.SupportsSyncing {
 		syncStatus = &RosettaTypes.SyncStatus{
 			Synced: RosettaTypes.Bool(true),
-			Stage:  RosettaTypes.String("SYNCED"),
+			Stage:  RosettaTypes.String(SyncedStage),
 		}
 	}
 
 	// Get peers information
 	var peers []*RosettaTypes.Peer
-	if ec.rosettaConfig.SupportsPeering {
+	if ec.rosettaConfig.PeersEnabled() {
 		peers, err = ec.peers(ctx)
 		if err != nil {
 			return nil, -1, nil, nil, err
@@ -280,7 +379,7 @@ func (ec *SDKClient) Status(ctx context.Context) (
 	}
 
 	return &RosettaTypes.BlockIdentifier{
-			Hash:  header.Hash().Hex(),
+			Hash:  blockHash.Hex(),
 			Index: header.Number.Int64(),
 		},
 		convertTime(header.Time),
@@ -290,7 +389,7 @@ func (ec *SDKClient) Status(ctx context.Context) (
 }
 
 // blockHeader returns a block header from the current canonical chain.
-// If number is nil, the latest known header is returned.
+// If number is nil, the header of the default block is returned.
 func (ec *SDKClient) blockHeader(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
@@ -301,13 +400,8 @@ func (ec *SDKClient) blockHeader(
 	)
 
 	if blockIdentifier == nil || (blockIdentifier.Hash == nil && blockIdentifier.Index == nil) {
-		defaultBlockNumber := ec.rosettaConfig.DefaultBlockNumber
-		if len(defaultBlockNumber) != 0 {
-			// Handle reorg issues of Optimism and Base
-			err = ec.CallContext(ctx, &header, "eth_getBlockByNumber", defaultBlockNumber, false)
-		} else {
-			err = ec.CallContext(ctx, &header, "eth_getBlockByNumber", ToBlockNumArg(nil), false)
-		}
+		// Handle reorg issues of Optimism and Base
+		err = ec.CallContext(ctx, &header, "eth_getBlockByNumber", string(ec.rosettaConfig.DefaultBlock()), false)
 	} else {
 		if blockIdentifier.Index != nil {
 			err = ec.CallContext(ctx, &header, "eth_getBlockByNumber", ToBlockNumArg(big.NewInt(*blockIdentifier.Index)), false)
@@ -325,11 +419,6 @@ func (ec *SDKClient) blockHeader(
 // Peers retrieves all peers of the node.
 func (ec *SDKClient) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 	var info []*p2p.PeerInfo
-
-	if ec.skipAdminCalls {
-		return []*RosettaTypes.Peer{}, nil
-	}
-
 	if err := ec.CallContext(ctx, &info, "admin_peers"); err != nil {
 		return nil, err
 	}
@@ -416,6 +505,7 @@ func (tx *RPCTransaction) LoadedTransaction() *LoadedTransaction {
 		BlockHash:   tx.TxExtraInfo.BlockHash,
 		TxHash:      tx.TxExtraInfo.TxHash,
 		Mint:        tx.TxExtraInfo.Mint,
+		Extensions:  tx.TxExtraInfo.Extensions,
 	}
 	return ethTx
 }
@@ -431,7 +521,6 @@ func (ec *SDKClient) TraceBlockByHash(
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
 
-	var calls []*rpcCall
 	var raw json.RawMessage
 	var err error
 	if ec.rosettaConfig.SupportCustomizedTraceConfig {
@@ -442,10 +531,17 @@ func (ec *SDKClient) TraceBlockByHash(
 	if err != nil {
 		return nil, err
 	}
+	if ec.traceDecoder != nil {
+		return ec.decodeBlockTraces(raw, blockHash, txs)
+	}
+
+	buf := rpcCallPool.Get().(*[]*rpcCall)
+	calls := (*buf)[:0]
+	defer func() { releaseBuffer(&rpcCallPool, buf, calls) }()
 	if err := json.Unmarshal(raw, &calls); err != nil {
 		return nil, err
 	}
-	m := make(map[string][]*FlatCall)
+	m := make(map[string][]*FlatCall, len(calls))
 	for i, tx := range calls {
 		if tx.Result.Type == "" {
 			// ignore calls with an empty type
@@ -473,6 +569,13 @@ func (ec *SDKClient) TraceTransaction(
 	if err != nil {
 		return nil, nil, err
 	}
+	if ec.traceDecoder != nil {
+		flattened, err := ec.traceDecoder(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		return raw, flattened, nil
+	}
 	if err := json.Unmarshal(raw, &result); err != nil {
 		return nil, nil, err
 	}
@@ -518,6 +621,8 @@ func (ec *SDKClient) TraceReplayBlockTransactions(ctx context.Context, hsh strin
 				To:      action.To,
 				Value:   action.Value,
 				GasUsed: action.GasUsed,
+				Input:   action.Input,
+				Depth:   len(child.TraceAddress),
 				// Revert:       t.Revert,
 				// ErrorMessage: t.ErrorMessage,
 			}
@@ -557,11 +662,14 @@ func (ec *SDKClient) TraceReplayTransaction(
 // EffectiveGasPrice returns the price of gas charged to this Transaction to be included in the
 // block.
 func EffectiveGasPrice(tx *EthTypes.Transaction, baseFee *big.Int) (*big.Int, error) {
-	if tx.Type() != eip1559TxType {
+	if tx.Type() != eip1559TxType && tx.Type() != EthTypes.BlobTxType {
 		return tx.GasPrice(), nil
 	}
 	// For EIP-1559 the gas price is determined by the base fee & miner tip sinstead
 	// of the tx-specified gas price.
+	if baseFee == nil {
+		return nil, fmt.Errorf("no base fee to compute the gas price of %s", tx.Hash())
+	}
 	tip, err := tx.EffectiveGasTip(baseFee)
 	if err != nil {
 		return nil, err
@@ -569,13 +677,59 @@ func EffectiveGasPrice(tx *EthTypes.Transaction, baseFee *big.Int) (*big.Int, er
 	return new(big.Int).Add(tip, baseFee), nil
 }
 
-// flattenTraces recursively flattens all traces.
+// ReceiptGasPrice returns the effective gas price of the receipt of tx, and
+// the price computed by EffectiveGasPrice when the receipt has none, like the
+// receipts of some node providers, with the source of the price, see
+// RosettaTxReceipt.GasPriceSource.
+func ReceiptGasPrice(
+	receiptPrice *big.Int,
+	tx *EthTypes.Transaction,
+	baseFee *big.Int,
+) (*big.Int, string, error) {
+	if receiptPrice != nil {
+		return receiptPrice, ReceiptGasPriceSource, nil
+	}
+
+	gasPrice, err := EffectiveGasPrice(tx, baseFee)
+	if err != nil {
+		return nil, "", err
+	}
+	if tx.Type() == eip1559TxType || tx.Type() == EthTypes.BlobTxType {
+		return gasPrice, ComputedGasPriceSource, nil
+	}
+
+	return gasPrice, TransactionGasPriceSource, nil
+}
+
+// FlattenTraces appends data and all of its nested calls, depth first, to flattened.
 func FlattenTraces(data *Call, flattened []*FlatCall) []*FlatCall {
 	if data == nil {
 		return flattened
 	}
-	results := append(flattened, data.flatten()) //nolint
+
+	// Collect the calls in a pooled buffer so the result is allocated once
+	buf := flatCallPool.Get().(*[]*FlatCall)
+	calls := appendFlatCalls((*buf)[:0], data, 0)
+
+	results := make([]*FlatCall, len(flattened), len(flattened)+len(calls))
+	copy(results, flattened)
+	results = append(results, calls...)
+
+	releaseBuffer(&flatCallPool, buf, calls)
+	return results
+}
+
+// appendFlatCalls recursively appends data, at depth, and its nested calls to
+// flattened
+func appendFlatCalls(flattened []*FlatCall, data *Call, depth int) []*FlatCall {
+	call := data.flatten()
+	call.Depth = depth
+	flattened = append(flattened, call)
 	for _, child := range data.Calls {
+		if child == nil {
+			continue
+		}
+
 		// Ensure all children of a reverted call
 		// are also reverted!
 		if data.Revert {
@@ -588,10 +742,9 @@ func FlattenTraces(data *Call, flattened []*FlatCall) []*FlatCall {
 			}
 		}
 
-		children := FlattenTraces(child, flattened)
-		results = append(results, children...)
+		flattened = appendFlatCalls(flattened, child, depth+1)
 	}
-	return results
+	return flattened
 }
 
 // miningReward returns the mining reward
@@ -634,12 +787,8 @@ func (ec *SDKClient) BlockRewardTransaction(
 	// (uncle_block_index + 8 - current_block_index) * final_mining_reward / 8
 	minerReward := miningReward
 	numUncles := len(uncles)
-	if len(uncles) > 0 {
-		reward := new(big.Float)
-		uncleReward := float64(numUncles) / sdkTypes.UnclesRewardMultiplier
-		rewardFloat := reward.Mul(big.NewFloat(uncleReward), big.NewFloat(float64(miningReward)))
-		rewardInt, _ := rewardFloat.Int64()
-		minerReward += rewardInt
+	if numUncles > 0 {
+		minerReward += miningReward / sdkTypes.UnclesRewardMultiplier * int64(numUncles)
 	}
 
 	const base = 10
@@ -650,7 +799,7 @@ func (ec *SDKClient) BlockRewardTransaction(
 		Type:   sdkTypes.MinerRewardOpType,
 		Status: RosettaTypes.String(sdkTypes.SuccessStatus),
 		Account: &RosettaTypes.AccountIdentifier{
-			Address: MustChecksum(miner),
+			Address: checksumOrRaw(miner),
 		},
 		Amount: &RosettaTypes.Amount{
 			Value:    strconv.FormatInt(minerReward, base),
@@ -661,7 +810,7 @@ func (ec *SDKClient) BlockRewardTransaction(
 
 	// Calculate uncle rewards
 	for _, b := range uncles {
-		uncleMiner := b.Coinbase.String()
+		uncleMiner := FormatAddress(b.Coinbase)
 		uncleBlock := b.Number.Int64()
 		uncleRewardBlock := new(
 			big.Int,
@@ -677,7 +826,7 @@ func (ec *SDKClient) BlockRewardTransaction(
 			Type:   sdkTypes.UncleRewardOpType,
 			Status: RosettaTypes.String(sdkTypes.SuccessStatus),
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(uncleMiner),
+				Address: uncleMiner,
 			},
 			Amount: &RosettaTypes.Amount{
 				Value:    uncleRewardBlock.String(),
@@ -723,24 +872,35 @@ func (ec *SDKClient) GetGasPrice(
 	ctx context.Context,
 	input Options,
 ) (*big.Int, error) {
-	var gasPrice *big.Int
-	var err error
-	if input.GasPrice == nil || input.GasPrice.Uint64() == 0 {
-		gasPrice, err = ec.SuggestGasPrice(ctx)
-		if err != nil {
-			return nil, err
-		}
-		if input.SuggestedFeeMultiplier != nil {
-			newGasPrice := new(big.Float).Mul(
-				big.NewFloat(*input.SuggestedFeeMultiplier),
-				new(big.Float).SetInt(gasPrice),
-			)
-			newGasPrice.Int(gasPrice)
-		}
-	} else {
-		gasPrice = input.GasPrice
+	if input.GasPrice != nil && input.GasPrice.Uint64() != 0 {
+		return input.GasPrice, nil
+	}
+
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return SuggestedGasPrice(input, gasPrice)
+}
+
+// SuggestedGasPrice returns the gas price of input, which is its gas price
+// when it is set and the gas price of the node scaled by its suggested fee
+// multiplier otherwise
+func SuggestedGasPrice(input Options, nodeGasPrice *big.Int) (*big.Int, error) {
+	if input.GasPrice != nil && input.GasPrice.Uint64() != 0 {
+		return input.GasPrice, nil
+	}
+	if input.SuggestedFeeMultiplier == nil {
+		return nodeGasPrice, nil
 	}
-	return gasPrice, nil
+
+	// big.Rat holds the float64 multiplier exactly, so large gas prices keep their precision
+	multiplier := new(big.Rat).SetFloat64(*input.SuggestedFeeMultiplier)
+	if multiplier == nil || multiplier.Sign() < 0 {
+		return nil, fmt.Errorf("suggested fee multiplier %v is invalid", *input.SuggestedFeeMultiplier)
+	}
+	newGasPrice := multiplier.Mul(multiplier, new(big.Rat).SetInt(nodeGasPrice))
+	return new(big.Int).Quo(newGasPrice.Num(), newGasPrice.Denom()), nil
 }
 
 func (ec *SDKClient) GetGasTipCap(ctx context.Context, input Options) (*big.Int, error) {
@@ -750,16 +910,64 @@ func (ec *SDKClient) GetGasTipCap(ctx context.Context, input Options) (*big.Int,
 			return nil, err
 		}
 
-		gasTipCap := hex.ToInt()
-		priorityFeeDivisor := getPriorityFeeDivisor(ec.rosettaConfig)
-		adjustedPriorityFee := new(big.Int).Div(gasTipCap, priorityFeeDivisor)
+		return SuggestedGasTipCap(ec.rosettaConfig, input, hex.ToInt())
+	}
+
+	return SuggestedGasTipCap(ec.rosettaConfig, input, nil)
+}
+
+// SuggestedGasTipCap returns the gas tip cap of input within its priority fee
+// bounds. Without a gas tip cap, it is the max priority fee per gas of the
+// node divided by the priority fee divisor. Explicit gas tip caps out of the
+// bounds are rejected.
+func SuggestedGasTipCap(
+	rosettaConfig configuration.RosettaConfig,
+	input Options,
+	maxPriorityFeePerGas *big.Int,
+) (*big.Int, error) {
+	feeFloor, feeCap := PriorityFeeBounds(rosettaConfig, input)
+	if input.GasTipCap == nil {
+		if maxPriorityFeePerGas == nil {
+			return nil, errors.New("max priority fee per gas is unknown")
+		}
+		priorityFeeDivisor := getPriorityFeeDivisor(rosettaConfig)
+		adjustedPriorityFee := new(big.Int).Div(maxPriorityFeePerGas, priorityFeeDivisor)
+		if feeFloor != nil {
+			adjustedPriorityFee = bigIntMax(adjustedPriorityFee, feeFloor)
+		}
+		if feeCap != nil && adjustedPriorityFee.Cmp(feeCap) > 0 {
+			adjustedPriorityFee = feeCap
+		}
 
 		return adjustedPriorityFee, nil
 	}
 
+	if feeFloor != nil && input.GasTipCap.Cmp(feeFloor) < 0 {
+		return nil, fmt.Errorf("gas tip cap %s is below the priority fee floor %s", input.GasTipCap, feeFloor)
+	}
+	if feeCap != nil && input.GasTipCap.Cmp(feeCap) > 0 {
+		return nil, fmt.Errorf("gas tip cap %s is above the priority fee cap %s", input.GasTipCap, feeCap)
+	}
+
 	return input.GasTipCap, nil
 }
 
+// PriorityFeeBounds returns the effective priority fee floor and cap of a
+// request, which are the options when set and the configured values otherwise.
+// Either is nil when it isn't set.
+func PriorityFeeBounds(rosettaConfig configuration.RosettaConfig, input Options) (*big.Int, *big.Int) {
+	feeFloor := rosettaConfig.PriorityFeeFloor
+	if input.PriorityFeeFloor != nil {
+		feeFloor = input.PriorityFeeFloor
+	}
+	feeCap := rosettaConfig.PriorityFeeCap
+	if input.PriorityFeeCap != nil {
+		feeCap = input.PriorityFeeCap
+	}
+
+	return feeFloor, feeCap
+}
+
 func (ec *SDKClient) GetGasFeeCap(ctx context.Context, input Options, gasTipCap *big.Int) (*big.Int, error) {
 	if input.GasFeeCap == nil {
 		baseFee, err := ec.GetBaseFee(ctx)
@@ -767,26 +975,39 @@ func (ec *SDKClient) GetGasFeeCap(ctx context.Context, input Options, gasTipCap
 			return nil, err
 		}
 
-		if baseFee != nil {
-			// Calculate max fee per gas (i.e. gas fee cap)
-			// Formula: GasFeeCap = max(BaseFeeMultiplier * BaseFee, BaseFeeFloor) + GasTipCap
-			// BaseFeeFloor: when base fee is decreasing dramatically, we can leverage BaseFeeFloor to speed up the tx onchain landing process
-			// BaseFeeMultiplier: when base fee is increasing dramatically, we can leverage BaseFeeMultiplier to ensure the tx can be landed onchain with enough fee
-			// BaseFeeFloor and BaseFeeMultiplier are chain specific, if the downstream service doesn't specify them in Rosetta config,
-			// the default formula in Rosetta layer is EIP-1559 neutral, which is GasFeeCap = BaseFee + GasTipCap
-			baseFeeFloor := getBaseFeeFloor(ec.rosettaConfig)
-			baseFeeMultiplier := getBaseFeeMultiplier(ec.rosettaConfig)
-			adjustedBaseFee := new(big.Int).Mul(baseFee, baseFeeMultiplier)
-			gasFeeCap := new(big.Int).Set(bigIntMax(adjustedBaseFee, baseFeeFloor))
-			gasFeeCap.Add(gasFeeCap, gasTipCap)
-
-			return gasFeeCap, nil
-		}
+		return SuggestedGasFeeCap(ec.rosettaConfig, input, baseFee, gasTipCap), nil
 	}
 
 	return input.GasFeeCap, nil
 }
 
+// SuggestedGasFeeCap returns the gas fee cap of input, which is its gas fee
+// cap when it is set and is derived from the base fee otherwise
+func SuggestedGasFeeCap(
+	rosettaConfig configuration.RosettaConfig,
+	input Options,
+	baseFee *big.Int,
+	gasTipCap *big.Int,
+) *big.Int {
+	if input.GasFeeCap != nil || baseFee == nil {
+		return input.GasFeeCap
+	}
+
+	// Calculate max fee per gas (i.e. gas fee cap)
+	// Formula: GasFeeCap = max(BaseFeeMultiplier * BaseFee, BaseFeeFloor) + GasTipCap
+	// BaseFeeFloor: when base fee is decreasing dramatically, we can leverage BaseFeeFloor to speed up the tx onchain landing process
+	// BaseFeeMultiplier: when base fee is increasing dramatically, we can leverage BaseFeeMultiplier to ensure the tx can be landed onchain with enough fee
+	// BaseFeeFloor and BaseFeeMultiplier are chain specific, if the downstream service doesn't specify them in Rosetta config,
+	// the default formula in Rosetta layer is EIP-1559 neutral, which is GasFeeCap = BaseFee + GasTipCap
+	baseFeeFloor := getBaseFeeFloor(rosettaConfig)
+	baseFeeMultiplier := getBaseFeeMultiplier(rosettaConfig)
+	adjustedBaseFee := new(big.Int).Mul(baseFee, baseFeeMultiplier)
+	gasFeeCap := new(big.Int).Set(bigIntMax(adjustedBaseFee, baseFeeFloor))
+	gasFeeCap.Add(gasFeeCap, gasTipCap)
+
+	return gasFeeCap
+}
+
 func getBaseFeeFloor(rosettaConfig configuration.RosettaConfig) *big.Int {
 	baseFeeFloor := big.NewInt(configuration.DefaultBaseFeeFloor)
 	if rosettaConfig.BaseFeeFloor != nil {
@@ -824,7 +1045,7 @@ func bigIntMax(a *big.Int, b *big.Int) *big.Int {
 
 func (ec *SDKClient) GetBaseFee(ctx context.Context) (*big.Int, error) {
 	var head *Header
-	if err := ec.CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil {
+	if err := ec.CallContext(ctx, &head, "eth_getBlockByNumber", string(ec.rosettaConfig.DefaultBlock()), false); err != nil {
 		return nil, err
 	}
 	if head == nil {
@@ -881,21 +1102,55 @@ func (ec *SDKClient) GetContractCallGasLimit(
 	return gasLimit, nil
 }
 
-// GetContractCurrency returns the currency for a specific address
+// GetContractDeploymentGasLimit returns the estimated gas limit of a contract
+// deployment with the given init code
+func (ec *SDKClient) GetContractDeploymentGasLimit(
+	ctx context.Context,
+	fromAddress string,
+	value *big.Int,
+	data []byte,
+) (uint64, error) {
+	gasLimit, err := ec.EstimateGas(ctx, goEthereum.CallMsg{
+		From:  common.HexToAddress(fromAddress),
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return gasLimit, nil
+}
+
+// GetContractCurrency returns the currency for a specific address. The
+// standard of the contract is detected, see detectTokenStandard, and erc20 only
+// picks the unknown currency of contracts whose standard can't be detected.
 func (ec *SDKClient) GetContractCurrency(
 	addr common.Address,
 	erc20 bool,
 ) (*ContractCurrency, error) {
-	token, err := NewContractInfoToken(addr, ec.EthClient)
-	if err != nil {
-		return nil, err
+	if token := GetValidERC20Token(ec.rosettaConfig.TokenMetadataOverrides, addr.String()); token != nil {
+		return &ContractCurrency{
+			Symbol:   token.Symbol,
+			Decimals: int32(token.Decimals),
+			Standard: ERC20TokenStandard,
+		}, nil
 	}
 
-	symbol, symbolErr := token.Symbol(nil)
-	decimals, decimalErr := token.Decimals(nil)
+	ctx := context.Background()
+	symbol, symbolErr := ec.tokenSymbol(ctx, addr)
+	decimals, decimalErr := ec.tokenDecimals(ctx, addr)
+
+	standard := ec.detectTokenStandard(ctx, addr, decimalErr == nil)
+	switch standard {
+	case ERC20TokenStandard:
+		erc20 = true
+	case ERC721TokenStandard:
+		erc20 = false
+	}
 
-	// Any of these indicate a failure to get complete information from contract
-	if symbolErr != nil || decimalErr != nil || symbol == "" || decimals == 0 {
+	// Any of these indicate a failure to get complete information from contract.
+	// Tokens with 0 decimals are valid, e.g. indivisible tokens.
+	if symbolErr != nil || (erc20 && decimalErr != nil) || symbol == "" {
 		if erc20 {
 			symbol = UnknownERC20Symbol
 			decimals = UnknownERC20Decimals
@@ -908,6 +1163,7 @@ func (ec *SDKClient) GetContractCurrency(
 	currency := &ContractCurrency{
 		Symbol:   symbol,
 		Decimals: int32(decimals),
+		Standard: standard,
 	}
 
 	return currency, nil
@@ -924,55 +1180,111 @@ func (ec *SDKClient) GetLoadedTransaction(
 	}
 
 	hash := common.HexToHash(request.TransactionIdentifier.Hash)
-	tx, pending, err := ec.TransactionByHash(ctx, hash)
-	if err != nil {
-		return nil, fmt.Errorf("failure getting tx: %w", err)
-	}
-	if pending {
-		return nil, nil
-	}
-
-	signer := EthTypes.LatestSignerForChainID(ec.P.ChainID)
-	msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
-	if err != nil {
-		return nil, err
-	}
+	blockHash := common.HexToHash(request.BlockIdentifier.Hash)
 	blockNumber := header.Number.String()
-	from := msg.From
-	txHash := tx.Hash()
+	var rpcTx RPCTransaction
+	if ec.rosettaConfig.SupportsOpStack {
+		// go-ethereum can't decode the deposit transactions of OP stack chains
+		// nor recover their sender, so the RPC transaction is decoded instead
+		opStackTx, err := ec.opStackTransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting tx: %w", err)
+		}
+		if opStackTx == nil {
+			return nil, nil
+		}
+		rpcTx = *opStackTx
+		rpcTx.BlockNumber = &blockNumber
+		rpcTx.BlockHash = &blockHash
+	} else {
+		tx, pending, err := ec.TransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting tx: %w", err)
+		}
+		if pending {
+			return nil, nil
+		}
 
-	txInfo := TxExtraInfo{
-		BlockNumber: &blockNumber,
-		BlockHash:   &hash,
-		From:        &from,
-		TxHash:      &txHash,
+		signer := EthTypes.LatestSignerForChainID(ec.P.ChainID)
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, err
+		}
+		from := msg.From
+		txHash := tx.Hash()
+
+		txInfo := TxExtraInfo{
+			BlockNumber: &blockNumber,
+			BlockHash:   &blockHash,
+			From:        &from,
+			TxHash:      &txHash,
+		}
+		rpcTx = RPCTransaction{tx, txInfo}
 	}
-	rpcTx := RPCTransaction{tx, txInfo}
 	loadedTx := rpcTx.LoadedTransaction()
 
 	loadedTx.BaseFee = header.BaseFee
+	loadedTx.FeeCurrency = ec.rosettaConfig.GasCurrency
+	loadedTx.EIP6780 = ec.P != nil && ec.P.IsCancun(header.Number, header.Time)
 
 	if ec.rosettaConfig.SupportsBlockAuthor {
 		blockAuthor, err := ec.BlockAuthor(ctx, header.Number.Int64())
 		if err != nil {
-			return nil, fmt.Errorf("could not get block author for %x: %w", hash, err)
+			return nil, fmt.Errorf("could not get block author for %x: %w", blockHash, err)
 		}
-		loadedTx.Author = MustChecksum(blockAuthor)
+		loadedTx.Author = checksumOrRaw(blockAuthor)
 	} else {
-		miner := header.Coinbase.Hex()
-		loadedTx.Miner = MustChecksum(miner)
+		loadedTx.Miner = FormatAddress(header.Coinbase)
 	}
 	return loadedTx, nil
 }
 
+// GetBlockHash returns the block identifier hash, except for OP stack blocks
+// from before the bedrock upgrade, see OPStackBlockHash.
 func (ec *SDKClient) GetBlockHash(ctx context.Context, blockIdentifier RosettaTypes.BlockIdentifier) (string, error) {
+	if ec.rosettaConfig.SupportsOpStack && ec.rosettaConfig.BedrockBlock != nil {
+		return ec.OPStackBlockHash(ctx, blockIdentifier, ec.rosettaConfig.BedrockBlock)
+	}
+
 	return blockIdentifier.Hash, nil
 }
 
+// OPStackBlockHash returns the hash of a block of an OP stack chain. The headers
+// of blocks before the bedrock upgrade were migrated from the legacy l2geth
+// database and don't hash to the block hash of the legacy chain, so the hash
+// of these blocks is fetched from the node instead.
+func (ec *SDKClient) OPStackBlockHash(
+	ctx context.Context,
+	blockIdentifier RosettaTypes.BlockIdentifier,
+	bedrockBlock *big.Int,
+) (string, error) {
+	if big.NewInt(blockIdentifier.Index).Cmp(bedrockBlock) >= 0 {
+		return blockIdentifier.Hash, nil
+	}
+
+	var header struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	blockNumber := hexutil.EncodeBig(big.NewInt(blockIdentifier.Index))
+	if err := ec.CallContext(ctx, &header, "eth_getBlockByNumber", blockNumber, false); err != nil {
+		return "", fmt.Errorf("could not get block %d: %w", blockIdentifier.Index, err)
+	}
+	if header.Hash == nil {
+		return "", fmt.Errorf("could not get block %d: %w", blockIdentifier.Index, goEthereum.NotFound)
+	}
+
+	return header.Hash.Hex(), nil
+}
+
 func (ec *SDKClient) SkipTxReceiptParsing(contractAddress string) bool {
 	return false
 }
 
+// HashOverride uses the standard transaction hashing by default
+func (ec *SDKClient) HashOverride(ctx context.Context, rawTx []byte) (string, bool, error) {
+	return "", false, nil
+}
+
 ///////////////////////////////////////////////////////////////////////////
 // Below are functions that should be implemented by chain specific Rosetta
 ///////////////////////////////////////////////////////////////////////////
@@ -983,8 +1295,16 @@ func (ec *SDKClient) ParseOps(
 	return nil, errors.New("ParseOps not implemented")
 }
 
-// nolint:staticcheck
+// BlockAuthor returns the clique signer of the block for clique chains, and
+// the proposer of the block for IBFT and QBFT chains, see
+// RosettaConfig.ConsensusEngine. Other chains need to implement it.
 func (ec *SDKClient) BlockAuthor(ctx context.Context, blockIndex int64) (string, error) {
+	switch ec.consensusEngine {
+	case configuration.CliqueConsensusEngine:
+		return ec.cliqueBlockAuthor(ctx, blockIndex)
+	case configuration.IBFTConsensusEngine, configuration.QBFTConsensusEngine, configuration.IstanbulConsensusEngine:
+		return ec.istanbulBlockAuthor(ctx, blockIndex)
+	}
 	return "", errors.New("BlockAuthor not implemented")
 }
 
@@ -1009,7 +1329,13 @@ func (ec *SDKClient) GetNativeTransferGasLimit(ctx context.Context, toAddress st
 	return 0, errors.New("GetNativeTransferGasLimit not implemented")
 }
 
+// GetL1DataFee returns the L1 data fee of an unsigned transaction. It is
+// computed by the GasPriceOracle on OP stack chains, see OPStackL1DataFee.
 func (ec *SDKClient) GetL1DataFee(ctx context.Context, ethTxBytes []byte) (*big.Int, error) {
+	if ec.rosettaConfig.SupportsOpStack {
+		return ec.OPStackL1DataFee(ctx, ethTxBytes)
+	}
+
 	return nil, errors.New("GetL1DataFee not implemented")
 }
 
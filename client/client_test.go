@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"testing"
@@ -227,6 +228,9 @@ func TestOpenEthTraceAPI_MultiTxns(t *testing.T) {
 	// block 10324614 has 1 tx, and 26 traces.
 	assert.Equal(t, len(m), 1)
 	assert.Equal(t, len(m[txHash]), 26)
+	assert.Equal(t, 0, m[txHash][0].Depth)
+	assert.Equal(t, 1, m[txHash][1].Depth)
+	assert.Equal(t, 2, m[txHash][25].Depth)
 
 	mockJSONRPC.AssertExpectations(t)
 }
@@ -362,3 +366,265 @@ func TestBalance(t *testing.T) {
 
 	mockJSONRPC.AssertExpectations(t)
 }
+
+func TestGetBlockHash(t *testing.T) {
+	ctx := context.Background()
+	legacyHash := common.HexToHash("0x1a2b3c")
+
+	tests := map[string]struct {
+		rosettaConfig configuration.RosettaConfig
+		index         int64
+		expected      string
+	}{
+		"default": {
+			index:    10,
+			expected: "0xcomputed",
+		},
+		"op stack after bedrock": {
+			rosettaConfig: configuration.RosettaConfig{SupportsOpStack: true, BedrockBlock: big.NewInt(10)},
+			index:         10,
+			expected:      "0xcomputed",
+		},
+		"op stack before bedrock": {
+			rosettaConfig: configuration.RosettaConfig{SupportsOpStack: true, BedrockBlock: big.NewInt(10)},
+			index:         9,
+			expected:      legacyHash.Hex(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: test.rosettaConfig,
+			}
+			if test.expected == legacyHash.Hex() {
+				mockJSONRPC.On(
+					"CallContext",
+					ctx,
+					mock.Anything,
+					"eth_getBlockByNumber",
+					"0x9",
+					false,
+				).Return(
+					nil,
+				).Run(
+					func(args mock.Arguments) {
+						assert.NoError(t, json.Unmarshal([]byte(`{"hash":"`+legacyHash.Hex()+`"}`), args.Get(1)))
+					},
+				).Once()
+			}
+
+			hash, err := sdkClient.GetBlockHash(ctx, RosettaTypes.BlockIdentifier{
+				Index: test.index,
+				Hash:  "0xcomputed",
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, hash)
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBaseFee_DefaultBlockTag(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		rosettaConfig configuration.RosettaConfig
+		expected      string
+	}{
+		"default": {
+			expected: "latest",
+		},
+		"default block number": {
+			rosettaConfig: configuration.RosettaConfig{DefaultBlockNumber: "finalized"},
+			expected:      "finalized",
+		},
+		"default block tag": {
+			rosettaConfig: configuration.RosettaConfig{
+				DefaultBlockNumber: "finalized",
+				DefaultBlockTag:    configuration.SafeBlockTag,
+			},
+			expected: "safe",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: test.rosettaConfig,
+			}
+			mockJSONRPC.On(
+				"CallContext",
+				ctx,
+				mock.Anything,
+				"eth_getBlockByNumber",
+				test.expected,
+				false,
+			).Return(
+				nil,
+			).Run(
+				func(args mock.Arguments) {
+					assert.NoError(t, json.Unmarshal([]byte(`{"baseFeePerGas":"0x7"}`), args.Get(1)))
+				},
+			).Once()
+
+			baseFee, err := sdkClient.GetBaseFee(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(7), baseFee)
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+
+	assert.Error(t, configuration.BlockTag("pending").Validate())
+}
+
+func TestGetGasTipCap_PriorityFeeBounds(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		rosettaConfig configuration.RosettaConfig
+		input         Options
+		suggested     string
+		expected      *big.Int
+		expectedError string
+	}{
+		"suggested": {
+			suggested: `"0x64"`,
+			expected:  big.NewInt(100),
+		},
+		"suggested below floor": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeFloor: big.NewInt(200)},
+			suggested:     `"0x0"`,
+			expected:      big.NewInt(200),
+		},
+		"suggested above cap": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeCap: big.NewInt(50)},
+			suggested:     `"0x64"`,
+			expected:      big.NewInt(50),
+		},
+		"floor override": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeFloor: big.NewInt(200)},
+			input:         Options{PriorityFeeFloor: big.NewInt(10)},
+			suggested:     `"0x0"`,
+			expected:      big.NewInt(10),
+		},
+		"explicit tip": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeFloor: big.NewInt(10)},
+			input:         Options{GasTipCap: big.NewInt(20)},
+			expected:      big.NewInt(20),
+		},
+		"explicit tip below floor": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeFloor: big.NewInt(10)},
+			input:         Options{GasTipCap: big.NewInt(0)},
+			expectedError: "gas tip cap 0 is below the priority fee floor 10",
+		},
+		"explicit tip above cap override": {
+			rosettaConfig: configuration.RosettaConfig{PriorityFeeCap: big.NewInt(100)},
+			input:         Options{GasTipCap: big.NewInt(20), PriorityFeeCap: big.NewInt(10)},
+			expectedError: "gas tip cap 20 is above the priority fee cap 10",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: test.rosettaConfig,
+			}
+			if len(test.suggested) > 0 {
+				mockJSONRPC.On(
+					"CallContext",
+					ctx,
+					mock.Anything,
+					"eth_maxPriorityFeePerGas",
+				).Return(
+					nil,
+				).Run(
+					func(args mock.Arguments) {
+						assert.NoError(t, json.Unmarshal([]byte(test.suggested), args.Get(1)))
+					},
+				).Once()
+			}
+
+			gasTipCap, err := sdkClient.GetGasTipCap(ctx, test.input)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, gasTipCap)
+			}
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceiptGasPrice(t *testing.T) {
+	dynamicFeeTx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20)})
+	cappedTx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(12)})
+	blobTx := types.NewTx(&types.BlobTx{})
+	legacyTx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(15)})
+
+	tests := map[string]struct {
+		receiptPrice *big.Int
+		tx           *types.Transaction
+		baseFee      *big.Int
+
+		expectedPrice  *big.Int
+		expectedSource string
+		expectedError  string
+	}{
+		"receipt": {
+			receiptPrice:   big.NewInt(11),
+			tx:             dynamicFeeTx,
+			baseFee:        big.NewInt(10),
+			expectedPrice:  big.NewInt(11),
+			expectedSource: ReceiptGasPriceSource,
+		},
+		"dynamic fee": {
+			tx:             dynamicFeeTx,
+			baseFee:        big.NewInt(10),
+			expectedPrice:  big.NewInt(12),
+			expectedSource: ComputedGasPriceSource,
+		},
+		"capped tip": {
+			tx:             cappedTx,
+			baseFee:        big.NewInt(10),
+			expectedPrice:  big.NewInt(12),
+			expectedSource: ComputedGasPriceSource,
+		},
+		"blob": {
+			tx:             blobTx,
+			baseFee:        big.NewInt(0),
+			expectedPrice:  big.NewInt(0),
+			expectedSource: ComputedGasPriceSource,
+		},
+		"legacy": {
+			tx:             legacyTx,
+			baseFee:        big.NewInt(10),
+			expectedPrice:  big.NewInt(15),
+			expectedSource: TransactionGasPriceSource,
+		},
+		"no base fee": {
+			tx:            dynamicFeeTx,
+			expectedError: "no base fee to compute the gas price of " + dynamicFeeTx.Hash().Hex(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			price, source, err := ReceiptGasPrice(test.receiptPrice, test.tx, test.baseFee)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedPrice, price)
+			assert.Equal(t, test.expectedSource, source)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// cliqueSignerCacheSize is the number of recovered clique signers cached by
+// block hash
+const cliqueSignerCacheSize = 4096
+
+// CliqueSigner recovers the signer of a clique header from the seal at the end
+// of its extra data
+func CliqueSigner(header *EthTypes.Header) (common.Address, error) {
+	if len(header.Extra) < crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("extra data of block %d is too short for a clique seal", header.Number)
+	}
+	signature := header.Extra[len(header.Extra)-crypto.SignatureLength:]
+
+	pubkey, err := crypto.Ecrecover(clique.SealHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("could not recover clique signer of block %d: %w", header.Number, err)
+	}
+
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// cliqueBlockAuthor returns the clique signer of the block at blockIndex. The
+// genesis block is not sealed, so its author is its coinbase.
+func (ec *SDKClient) cliqueBlockAuthor(ctx context.Context, blockIndex int64) (string, error) {
+	header, err := ec.blockHeader(ctx, &RosettaTypes.PartialBlockIdentifier{Index: &blockIndex})
+	if err != nil {
+		return "", err
+	}
+	if blockIndex == 0 {
+		return FormatAddress(header.Coinbase), nil
+	}
+
+	hash := header.Hash()
+	if ec.cliqueSigners != nil {
+		if signer, ok := ec.cliqueSigners.Get(hash); ok {
+			return FormatAddress(signer.(common.Address)), nil
+		}
+	}
+
+	signer, err := CliqueSigner(header)
+	if err != nil {
+		return "", err
+	}
+	if ec.cliqueSigners != nil {
+		ec.cliqueSigners.Add(hash, signer)
+	}
+
+	return FormatAddress(signer), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// sealedHeader returns a clique header at number sealed by a new signer
+func sealedHeader(t *testing.T, number int64) (*EthTypes.Header, common.Address) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	header := &EthTypes.Header{
+		Number:     big.NewInt(number),
+		Difficulty: big.NewInt(2),
+		Coinbase:   common.HexToAddress("0x01"),
+		Extra:      make([]byte, 32+crypto.SignatureLength),
+	}
+	signature, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+	assert.NoError(t, err)
+	copy(header.Extra[32:], signature)
+
+	return header, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestCliqueSigner(t *testing.T) {
+	header, signer := sealedHeader(t, 10)
+	recovered, err := CliqueSigner(header)
+	assert.NoError(t, err)
+	assert.Equal(t, signer, recovered)
+
+	header.Extra = header.Extra[:32]
+	_, err = CliqueSigner(header)
+	assert.EqualError(t, err, "extra data of block 10 is too short for a clique seal")
+}
+
+func TestBlockAuthor_Clique(t *testing.T) {
+	header, signer := sealedHeader(t, 10)
+	genesis := &EthTypes.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		Coinbase:   common.HexToAddress("0x02"),
+		Extra:      make([]byte, 32+crypto.SignatureLength),
+	}
+	cache, err := lru.New(cliqueSignerCacheSize)
+	assert.NoError(t, err)
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient:       &RPCClient{JSONRPC: mockJSONRPC},
+		consensusEngine: configuration.CliqueConsensusEngine,
+		cliqueSigners:   cache,
+	}
+	for arg, h := range map[string]*EthTypes.Header{"0xa": header, "0x0": genesis} {
+		h := h
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", arg, false).Return(nil).Run(
+			func(args mock.Arguments) {
+				*args.Get(1).(**EthTypes.Header) = h
+			},
+		)
+	}
+
+	author, err := sdkClient.BlockAuthor(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Hex(), author)
+	cached, ok := cache.Get(header.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, signer, cached)
+
+	author, err = sdkClient.BlockAuthor(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.Coinbase.Hex(), author)
+
+	_, err = (&SDKClient{}).BlockAuthor(context.Background(), 10)
+	assert.EqualError(t, err, "BlockAuthor not implemented")
+}
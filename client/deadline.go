@@ -0,0 +1,46 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// CallContext performs a JSON RPC call with the given arguments. It fails
+// without calling the node when ctx is already done, and the call is limited
+// to UpstreamCallTimeout. Calls beyond their slow call threshold are logged.
+func (ec *SDKClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := ec.upstreamContext(ctx)
+	defer cancel()
+
+	defer ec.slowCalls.observeCall(method, args, time.Now())
+	return ec.RPCClient.CallContext(ctx, result, method, args...)
+}
+
+// upstreamContext derives the context of a single call to the node from the
+// request context. The deadline of ctx still applies when it is earlier.
+func (ec *SDKClient) upstreamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := ec.rosettaConfig.UpstreamCallTimeout
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
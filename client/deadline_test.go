@@ -0,0 +1,72 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCallContext_Deadlines(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+		rosettaConfig: configuration.RosettaConfig{
+			UpstreamCallTimeout: time.Second,
+		},
+	}
+
+	// The call timeout is capped by the request deadline
+	requestDeadline := time.Now().Add(time.Millisecond * 500)
+	ctx, cancel := context.WithDeadline(context.Background(), requestDeadline)
+	defer cancel()
+	mockJSONRPC.On(
+		"CallContext",
+		mock.MatchedBy(func(callCtx context.Context) bool {
+			deadline, ok := callCtx.Deadline()
+			return ok && deadline.Equal(requestDeadline)
+		}),
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(nil).Once()
+	var result string
+	assert.NoError(t, sdkClient.CallContext(ctx, &result, "eth_blockNumber"))
+
+	// The call timeout applies without a request deadline
+	start := time.Now()
+	mockJSONRPC.On(
+		"CallContext",
+		mock.MatchedBy(func(callCtx context.Context) bool {
+			deadline, ok := callCtx.Deadline()
+			return ok && !deadline.Before(start.Add(time.Second))
+		}),
+		mock.Anything,
+		"eth_chainId",
+	).Return(nil).Once()
+	assert.NoError(t, sdkClient.CallContext(context.Background(), &result, "eth_chainId"))
+
+	// Cancelled requests don't reach the node
+	cancel()
+	assert.ErrorIs(t, sdkClient.CallContext(ctx, &result, "eth_gasPrice"), context.Canceled)
+
+	mockJSONRPC.AssertExpectations(t)
+}
@@ -14,7 +14,12 @@
 
 package client
 
-import "net/http"
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
 
 func NewDefaultHTTPTransport() http.RoundTripper {
 	// Override transport idle connection settings
@@ -28,3 +33,53 @@ func NewDefaultHTTPTransport() http.RoundTripper {
 
 	return defaultTransport
 }
+
+// NewGzipRequestTransport returns a transport compressing the request bodies
+// sent with next with gzip, for nodes accepting compressed JSON RPC requests.
+// Requests without a body, or already encoded, are sent as is.
+func NewGzipRequestTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = NewDefaultHTTPTransport()
+	}
+
+	return &gzipRequestTransport{next: next}
+}
+
+// gzipRequestTransport compresses request bodies, see NewGzipRequestTransport
+type gzipRequestTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sends a copy of req with a compressed body
+func (t *gzipRequestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || len(req.Header.Get("Content-Encoding")) > 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(body); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the request
+	encoded := req.Clone(req.Context())
+	encoded.Header.Set("Content-Encoding", "gzip")
+	encoded.ContentLength = int64(compressed.Len())
+	payload := compressed.Bytes()
+	encoded.Body = io.NopCloser(bytes.NewReader(payload))
+	encoded.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+
+	return t.next.RoundTrip(encoded)
+}
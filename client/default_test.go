@@ -0,0 +1,63 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipRequestTransport(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = reader
+		}
+		raw, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		if len(raw) > 0 {
+			assert.Equal(t, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`, string(raw))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer server.Close()
+
+	rpcClient, err := NewRPCClient(server.URL, NewGzipRequestTransport(nil))
+	require.NoError(t, err)
+	var number string
+	require.NoError(t, rpcClient.CallContext(context.Background(), &number, "eth_blockNumber"))
+	assert.Equal(t, "0x10", number)
+	assert.Equal(t, []string{"gzip"}, encodings)
+
+	// Requests without a body are sent as is
+	httpClient := &http.Client{Transport: NewGzipRequestTransport(nil)}
+	resp, err := httpClient.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, []string{"gzip", ""}, encodings)
+}
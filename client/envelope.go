@@ -0,0 +1,128 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// LegacyTransactionVersion is the version of transaction payloads
+	// encoded before payloads carried a version field.
+	LegacyTransactionVersion = 1
+
+	// TransactionVersion is the version of the transaction payloads
+	// returned by /construction/payloads and /construction/combine.
+	TransactionVersion = 2
+)
+
+// transactionMigrations upgrade a decoded transaction payload of version v
+// to version v+1. Version 2 only added optional fields (e.g. access_list),
+// so legacy payloads decode as is.
+var transactionMigrations = map[int]func(map[string]json.RawMessage) error{
+	LegacyTransactionVersion: func(map[string]json.RawMessage) error { return nil },
+}
+
+// transactionEnvelope is the versioned JSON encoding of an unsigned transaction
+type transactionEnvelope struct {
+	Version int `json:"v"`
+	*Transaction
+}
+
+// MarshalUnsignedTransaction encodes tx as a versioned unsigned transaction payload.
+func MarshalUnsignedTransaction(tx *Transaction) ([]byte, error) {
+	return json.Marshal(&transactionEnvelope{
+		Version:     TransactionVersion,
+		Transaction: tx,
+	})
+}
+
+// UnmarshalUnsignedTransaction decodes an unsigned transaction payload of any
+// supported version, migrating older payloads to the current Transaction.
+func UnmarshalUnsignedTransaction(data []byte) (*Transaction, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	version, err := transactionVersion(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for v := version; v < TransactionVersion; v++ {
+		if err := transactionMigrations[v](fields); err != nil {
+			return nil, fmt.Errorf("unable to migrate transaction from version %d: %w", v, err)
+		}
+	}
+	delete(fields, "v")
+
+	migrated, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx Transaction
+	if err := json.Unmarshal(migrated, &tx); err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+// MarshalSignedTransaction encodes wrapper as a versioned signed transaction payload.
+func MarshalSignedTransaction(wrapper *SignedTransactionWrapper) ([]byte, error) {
+	wrapper.Version = TransactionVersion
+	return json.Marshal(wrapper)
+}
+
+// UnmarshalSignedTransaction decodes a signed transaction payload of any supported version.
+func UnmarshalSignedTransaction(data []byte) (*SignedTransactionWrapper, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	if _, err := transactionVersion(fields); err != nil {
+		return nil, err
+	}
+
+	var wrapper SignedTransactionWrapper
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	wrapper.Version = TransactionVersion
+
+	return &wrapper, nil
+}
+
+// transactionVersion returns the version of a decoded transaction payload
+func transactionVersion(fields map[string]json.RawMessage) (int, error) {
+	raw, ok := fields["v"]
+	if !ok {
+		return LegacyTransactionVersion, nil
+	}
+
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("invalid transaction version %s: %w", raw, err)
+	}
+	if version < LegacyTransactionVersion || version > TransactionVersion {
+		return 0, fmt.Errorf("unsupported transaction version %d", version)
+	}
+
+	return version, nil
+}
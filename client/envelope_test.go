@@ -0,0 +1,90 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyUnsignedTx = `{"from":"0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1","to":"0xdF7C4fFf31A190E8D46FC9Ba8CdE6aaD8F69Fc76","value":1,"data":"","nonce":67,"gas_price":5000000000,"gas":21000,"chain_id":3,"currency":{"symbol":"ETH","decimals":18}}` // nolint
+
+func TestUnsignedTransactionRoundTrip(t *testing.T) {
+	tx := &Transaction{
+		From:     "0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1",
+		To:       "0xdF7C4fFf31A190E8D46FC9Ba8CdE6aaD8F69Fc76",
+		Value:    big.NewInt(1),
+		Data:     []byte{},
+		Nonce:    67,
+		GasPrice: big.NewInt(5000000000),
+		GasLimit: 21000,
+		ChainID:  big.NewInt(3),
+		Currency: &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18},
+		AccessList: EthTypes.AccessList{
+			{Address: common.HexToAddress("0x1"), StorageKeys: []common.Hash{common.HexToHash("0x2")}},
+		},
+	}
+
+	data, err := MarshalUnsignedTransaction(tx)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `{"v":2,"from":`)
+
+	decoded, err := UnmarshalUnsignedTransaction(data)
+	assert.NoError(t, err)
+	assert.Equal(t, tx, decoded)
+}
+
+func TestUnmarshalUnsignedTransaction_Legacy(t *testing.T) {
+	tx, err := UnmarshalUnsignedTransaction([]byte(legacyUnsignedTx))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(67), tx.Nonce)
+	assert.Equal(t, big.NewInt(5000000000), tx.GasPrice)
+	assert.Nil(t, tx.AccessList)
+}
+
+func TestUnmarshalUnsignedTransaction_UnsupportedVersion(t *testing.T) {
+	_, err := UnmarshalUnsignedTransaction([]byte(`{"v":3,"from":"0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1"}`))
+	assert.EqualError(t, err, "unsupported transaction version 3")
+
+	_, err = UnmarshalUnsignedTransaction([]byte(`{"v":"2"}`))
+	assert.Error(t, err)
+}
+
+func TestSignedTransactionRoundTrip(t *testing.T) {
+	wrapper := &SignedTransactionWrapper{
+		SignedTransaction: []byte(`{"type":"0x0"}`),
+		Currency:          &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18},
+	}
+
+	data, err := MarshalSignedTransaction(wrapper)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `{"v":2,"signed_tx":`)
+
+	decoded, err := UnmarshalSignedTransaction(data)
+	assert.NoError(t, err)
+	assert.Equal(t, wrapper, decoded)
+
+	legacy, err := UnmarshalSignedTransaction([]byte(`{"signed_tx":"eyJ0eXBlIjoiMHgwIn0="}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"type":"0x0"}`), legacy.SignedTransaction)
+
+	_, err = UnmarshalSignedTransaction([]byte(`{"v":9,"signed_tx":"eyJ0eXBlIjoiMHgwIn0="}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCTransactionExtensions(t *testing.T) {
+	raw := `{
+		"type": "0x0",
+		"nonce": "0x1",
+		"gasPrice": "0x3b9aca00",
+		"gas": "0x5208",
+		"to": "0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0",
+		"value": "0x1",
+		"input": "0x",
+		"v": "0x1b",
+		"r": "0x1",
+		"s": "0x1",
+		"hash": "0x5e77a04531c7c107af1882d76cbff9486d0a9aa53701c30888509d4f5f2b003a",
+		"blockNumber": "0xa",
+		"from": "0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4",
+		"l1BlockNumber": "0x10",
+		"queueOrigin": "sequencer"
+	}`
+
+	var rpcTx RPCTransaction
+	assert.NoError(t, json.Unmarshal([]byte(raw), &rpcTx))
+	assert.Equal(t, map[string]interface{}{
+		"l1BlockNumber": "0x10",
+		"queueOrigin":   "sequencer",
+	}, rpcTx.Extensions)
+
+	tx := rpcTx.LoadedTransaction()
+	origin, ok := Extension[string](tx, "queueOrigin")
+	assert.True(t, ok)
+	assert.Equal(t, "sequencer", origin)
+
+	_, ok = Extension[int64](tx, "queueOrigin")
+	assert.False(t, ok)
+	_, ok = Extension[string](tx, "missing")
+	assert.False(t, ok)
+
+	empty := &LoadedTransaction{}
+	_, ok = Extension[string](empty, "queueOrigin")
+	assert.False(t, ok)
+	empty.SetExtension("l1Fee", int64(7))
+	fee, ok := Extension[int64](empty, "l1Fee")
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), fee)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"log"
+
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// FeatureDetector is an optional interface a client can implement to detect the
+// RosettaConfig feature flags left unset, like HasUncles and HasAdminRPC, from the
+// node at startup.
+type FeatureDetector interface {
+	DetectFeatures(ctx context.Context) error
+}
+
+// DetectFeatures detects HasUncles and HasAdminRPC if they are not set. Only
+// proof of work chains using ethash have uncles, so HasUncles is false for chains
+// whose ChainConfig has no ethash config. HasAdminRPC is false if the node rejects
+// admin_peers.
+func (ec *SDKClient) DetectFeatures(ctx context.Context) error {
+	cfg := &ec.rosettaConfig
+
+	if cfg.HasUncles == nil && cfg.SupportRewardTx && ec.P != nil {
+		hasUncles := ec.P.Ethash != nil && !cfg.SupportsOpStack
+		cfg.HasUncles = &hasUncles
+		log.Printf("detected uncle support: %t", hasUncles)
+	}
+
+	if cfg.HasAdminRPC == nil && cfg.SupportsPeering {
+		var info []*p2p.PeerInfo
+		err := ec.CallContext(ctx, &info, "admin_peers")
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		hasAdminRPC := err == nil
+		cfg.HasAdminRPC = &hasAdminRPC
+		if err != nil {
+			log.Printf("admin_peers is not available, skipping peers: %v", err)
+		}
+	}
+
+	return nil
+}
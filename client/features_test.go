@@ -0,0 +1,97 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectFeatures(t *testing.T) {
+	ctx := context.Background()
+	enabled := true
+
+	tests := map[string]struct {
+		rosettaConfig configuration.RosettaConfig
+		chainConfig   *params.ChainConfig
+		peersErr      error
+		expectPeers   bool
+
+		expectedUncles  bool
+		expectedPeering bool
+	}{
+		"proof of work": {
+			rosettaConfig:   configuration.RosettaConfig{SupportRewardTx: true, SupportsPeering: true},
+			chainConfig:     params.MainnetChainConfig,
+			expectPeers:     true,
+			expectedUncles:  true,
+			expectedPeering: true,
+		},
+		"op stack without admin namespace": {
+			rosettaConfig: configuration.RosettaConfig{
+				SupportRewardTx: true,
+				SupportsPeering: true,
+				SupportsOpStack: true,
+			},
+			chainConfig: params.MainnetChainConfig,
+			peersErr:    errors.New("the method admin_peers does not exist/is not available"),
+			expectPeers: true,
+		},
+		"no ethash": {
+			rosettaConfig: configuration.RosettaConfig{SupportRewardTx: true},
+			chainConfig:   &params.ChainConfig{},
+		},
+		"configured": {
+			rosettaConfig: configuration.RosettaConfig{
+				SupportRewardTx: true,
+				SupportsPeering: true,
+				HasUncles:       &enabled,
+				HasAdminRPC:     &enabled,
+			},
+			chainConfig:     &params.ChainConfig{},
+			expectedUncles:  true,
+			expectedPeering: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				P:             test.chainConfig,
+				RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: test.rosettaConfig,
+			}
+			if test.expectPeers {
+				mockJSONRPC.On("CallContext", ctx, mock.Anything, "admin_peers").Return(test.peersErr).Once()
+			}
+
+			assert.NoError(t, sdkClient.DetectFeatures(ctx))
+			cfg := sdkClient.GetRosettaConfig()
+			assert.Equal(t, test.expectedUncles, cfg.UnclesEnabled())
+			assert.Equal(t, test.expectedPeering, cfg.PeersEnabled())
+
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderMapper is an optional interface a client can implement to map the
+// chain specific fields of a block header into the canonical header, e.g. so
+// the header hashes to the block hash. It is called when
+// RosettaConfig.TolerantHeaderDecoding is enabled.
+type HeaderMapper interface {
+	MapHeader(extraFields map[string]json.RawMessage, header *EthTypes.Header) error
+}
+
+// requiredHeaderFields are the defaults of the header fields geth requires,
+// used for chains that omit them
+var requiredHeaderFields = map[string]interface{}{
+	"parentHash":       common.Hash{},
+	"sha3Uncles":       EthTypes.EmptyUncleHash,
+	"miner":            common.Address{},
+	"stateRoot":        common.Hash{},
+	"transactionsRoot": EthTypes.EmptyTxsHash,
+	"receiptsRoot":     EthTypes.EmptyReceiptsHash,
+	"logsBloom":        EthTypes.Bloom{},
+	"difficulty":       (*hexutil.Big)(common.Big0),
+	"gasLimit":         hexutil.Uint64(0),
+	"gasUsed":          hexutil.Uint64(0),
+	"timestamp":        hexutil.Uint64(0),
+	"extraData":        hexutil.Bytes{},
+}
+
+// knownBlockFields are the fields of a JSON RPC block that are decoded by
+// EthTypes.Header or RPCBlock
+var knownBlockFields = map[string]bool{
+	"parentHash":            true,
+	"sha3Uncles":            true,
+	"miner":                 true,
+	"stateRoot":             true,
+	"transactionsRoot":      true,
+	"receiptsRoot":          true,
+	"logsBloom":             true,
+	"difficulty":            true,
+	"number":                true,
+	"gasLimit":              true,
+	"gasUsed":               true,
+	"timestamp":             true,
+	"extraData":             true,
+	"mixHash":               true,
+	"nonce":                 true,
+	"baseFeePerGas":         true,
+	"withdrawalsRoot":       true,
+	"blobGasUsed":           true,
+	"excessBlobGas":         true,
+	"parentBeaconBlockRoot": true,
+	"hash":                  true,
+	"size":                  true,
+	"totalDifficulty":       true,
+	"transactions":          true,
+	"uncles":                true,
+	"withdrawals":           true,
+}
+
+// HeaderExtraFields returns the fields of a JSON RPC block that are not part
+// of a standard block
+func HeaderExtraFields(raw json.RawMessage) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return extraBlockFields(fields), nil
+}
+
+// extraBlockFields returns the fields that are not in knownBlockFields
+func extraBlockFields(fields map[string]json.RawMessage) map[string]json.RawMessage {
+	return unknownFields(fields, knownBlockFields)
+}
+
+// unknownFields returns the fields that are not in known
+func unknownFields(fields map[string]json.RawMessage, known map[string]bool) map[string]json.RawMessage {
+	extraFields := map[string]json.RawMessage{}
+	for name, value := range fields {
+		if !known[name] {
+			extraFields[name] = value
+		}
+	}
+
+	return extraFields
+}
+
+// withDefaultFields returns the JSON encoding of fields with the defaults of
+// the fields that are missing or null
+func withDefaultFields(fields map[string]json.RawMessage, defaults map[string]interface{}) ([]byte, error) {
+	for name, value := range defaults {
+		if v, ok := fields[name]; ok && string(v) != "null" {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = encoded
+	}
+
+	return json.Marshal(fields)
+}
+
+// DecodeHeaderTolerant decodes the header of a JSON RPC block like
+// EthTypes.Header, but defaults the required header fields the block omits
+// instead of erroring. The fields that are not part of a standard block are
+// returned as extra fields.
+func DecodeHeaderTolerant(raw json.RawMessage) (*EthTypes.Header, map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, err
+	}
+	extraFields := extraBlockFields(fields)
+
+	completed, err := withDefaultFields(fields, requiredHeaderFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	var header EthTypes.Header
+	if err := json.Unmarshal(completed, &header); err != nil {
+		return nil, nil, fmt.Errorf("could not decode header: %w", err)
+	}
+
+	return &header, extraFields, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeHeaderTolerant(t *testing.T) {
+	// A header without sha3Uncles, difficulty and logsBloom, with extra fields
+	raw := json.RawMessage(`{
+		"parentHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+		"miner": "0x0000000000000000000000000000000000000002",
+		"stateRoot": "0x0000000000000000000000000000000000000000000000000000000000000003",
+		"transactionsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"receiptsRoot": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"number": "0xa",
+		"gasLimit": "0x1c9c380",
+		"gasUsed": "0x0",
+		"timestamp": "0x65f1b0a0",
+		"extraData": "0x",
+		"hash": "0x0000000000000000000000000000000000000000000000000000000000000004",
+		"transactions": [],
+		"l1BlockNumber": "0x12a05f2",
+		"sendCount": "0x1"
+	}`)
+
+	_, _, err := DecodeHeaderTolerant(json.RawMessage(`{"number":`))
+	assert.Error(t, err)
+
+	var strict EthTypes.Header
+	assert.Error(t, json.Unmarshal(raw, &strict))
+
+	header, extraFields, err := DecodeHeaderTolerant(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), header.Number)
+	assert.Equal(t, EthTypes.EmptyUncleHash, header.UncleHash)
+	assert.Zero(t, header.Difficulty.Sign())
+	assert.Equal(t, map[string]json.RawMessage{
+		"l1BlockNumber": json.RawMessage(`"0x12a05f2"`),
+		"sendCount":     json.RawMessage(`"0x1"`),
+	}, extraFields)
+}
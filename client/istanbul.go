@@ -0,0 +1,278 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// istanbulExtraVanity is the length of the vanity prefix of the extra data of
+// GoQuorum IBFT headers
+const istanbulExtraVanity = 32
+
+// IstanbulVote is the validator vote of an IBFT or QBFT header. VoteType is
+// 0xff to add Recipient to the validator set and 0x00 to remove it.
+type IstanbulVote struct {
+	Recipient common.Address
+	VoteType  byte
+}
+
+// IstanbulHeader is the consensus data of an IBFT or QBFT header
+type IstanbulHeader struct {
+	Proposer       common.Address
+	Validators     []common.Address
+	Vote           *IstanbulVote
+	Round          uint32
+	CommittedSeals [][]byte
+}
+
+// besuExtra is the extra data of Besu IBFT 2.0 and QBFT headers, an RLP list of
+// the vanity, validators, vote, round and committed seals. The vote and round
+// are kept raw, since their encoding differs between engines and clients.
+type besuExtra struct {
+	Vanity         []byte
+	Validators     []common.Address
+	Vote           rlp.RawValue
+	Round          []byte
+	CommittedSeals [][]byte
+}
+
+// quorumIstanbulExtra is the extra data of GoQuorum IBFT headers after the
+// vanity prefix. Seal is the signature of the proposer.
+type quorumIstanbulExtra struct {
+	Validators    []common.Address
+	Seal          []byte
+	CommittedSeal [][]byte
+}
+
+// ParseIstanbulHeader returns the consensus data in the extra data of a header
+// of engine, one of the IBFT or QBFT consensus engines of configuration. The
+// proposer of IBFT 2.0 and QBFT blocks is their coinbase, while the proposer of
+// GoQuorum IBFT blocks is recovered from their seal, except for the genesis
+// block.
+func ParseIstanbulHeader(engine string, header *EthTypes.Header) (*IstanbulHeader, error) {
+	switch engine {
+	case configuration.IBFTConsensusEngine, configuration.QBFTConsensusEngine:
+		var extra besuExtra
+		if err := rlp.DecodeBytes(header.Extra, &extra); err != nil {
+			return nil, fmt.Errorf("could not decode %s extra data of block %d: %w", engine, header.Number, err)
+		}
+		vote, err := decodeIstanbulVote(extra.Vote)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s vote of block %d: %w", engine, header.Number, err)
+		}
+		if len(extra.Round) > 4 {
+			return nil, fmt.Errorf("round of block %d is longer than 4 bytes", header.Number)
+		}
+		var round uint32
+		for _, b := range extra.Round {
+			round = round<<8 | uint32(b)
+		}
+
+		return &IstanbulHeader{
+			Proposer:       header.Coinbase,
+			Validators:     extra.Validators,
+			Vote:           vote,
+			Round:          round,
+			CommittedSeals: extra.CommittedSeals,
+		}, nil
+	case configuration.IstanbulConsensusEngine:
+		extra, err := decodeQuorumIstanbulExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		// The genesis block is not proposed
+		proposer := header.Coinbase
+		if header.Number.Sign() > 0 {
+			proposer, err = quorumIstanbulProposer(header, extra)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &IstanbulHeader{
+			Proposer:       proposer,
+			Validators:     extra.Validators,
+			CommittedSeals: extra.CommittedSeal,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%q is not an IBFT or QBFT consensus engine", engine)
+	}
+}
+
+// IstanbulHeaderHash returns the block hash of a header of engine, one of the
+// IBFT or QBFT consensus engines of configuration. The committed seals are
+// added to the extra data after the block is proposed, so the block hash is
+// the hash of the header with the committed seals removed from its extra data,
+// and, for IBFT 2.0 and QBFT, with the round removed or zeroed.
+func IstanbulHeaderHash(engine string, header *EthTypes.Header) (common.Hash, error) {
+	var filtered []byte
+	switch engine {
+	case configuration.IBFTConsensusEngine, configuration.QBFTConsensusEngine:
+		var fields []rlp.RawValue
+		if err := rlp.DecodeBytes(header.Extra, &fields); err != nil {
+			return common.Hash{}, fmt.Errorf("could not decode %s extra data of block %d: %w", engine, header.Number, err)
+		}
+		if len(fields) != 5 {
+			return common.Hash{}, fmt.Errorf("%s extra data of block %d has %d fields", engine, header.Number, len(fields))
+		}
+		// The vanity, validators and vote are kept as encoded
+		fields = fields[:3]
+		if engine == configuration.QBFTConsensusEngine {
+			fields = append(fields, rlp.EmptyString, rlp.EmptyList)
+		}
+		encoded, err := rlp.EncodeToBytes(fields)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		filtered = encoded
+	case configuration.IstanbulConsensusEngine:
+		extra, err := decodeQuorumIstanbulExtra(header)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		extra.CommittedSeal = [][]byte{}
+		filtered, err = encodeQuorumIstanbulExtra(header, extra)
+		if err != nil {
+			return common.Hash{}, err
+		}
+	default:
+		return common.Hash{}, fmt.Errorf("%q is not an IBFT or QBFT consensus engine", engine)
+	}
+
+	filteredHeader := EthTypes.CopyHeader(header)
+	filteredHeader.Extra = filtered
+	return filteredHeader.Hash(), nil
+}
+
+// HashHeader implements validator.HeaderHasher, computing the block hash of
+// IBFT and QBFT headers with IstanbulHeaderHash
+func (ec *SDKClient) HashHeader(header *EthTypes.Header, _ map[string]json.RawMessage) (common.Hash, error) {
+	switch ec.consensusEngine {
+	case configuration.IBFTConsensusEngine, configuration.QBFTConsensusEngine, configuration.IstanbulConsensusEngine:
+		return IstanbulHeaderHash(ec.consensusEngine, header)
+	default:
+		return header.Hash(), nil
+	}
+}
+
+// istanbulBlockAuthor returns the proposer of the block at blockIndex
+func (ec *SDKClient) istanbulBlockAuthor(ctx context.Context, blockIndex int64) (string, error) {
+	header, err := ec.blockHeader(ctx, &RosettaTypes.PartialBlockIdentifier{Index: &blockIndex})
+	if err != nil {
+		return "", err
+	}
+
+	istanbulHeader, err := ParseIstanbulHeader(ec.consensusEngine, header)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatAddress(istanbulHeader.Proposer), nil
+}
+
+// decodeIstanbulVote decodes the vote of an IBFT 2.0 or QBFT header, which is
+// an empty string or list when the header has no vote
+func decodeIstanbulVote(raw rlp.RawValue) (*IstanbulVote, error) {
+	kind, content, _, err := rlp.Split(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	if kind != rlp.List {
+		return nil, errors.New("vote is not a list")
+	}
+
+	var vote struct {
+		Recipient common.Address
+		VoteType  []byte
+	}
+	if err := rlp.DecodeBytes(raw, &vote); err != nil {
+		return nil, err
+	}
+	if len(vote.VoteType) > 1 {
+		return nil, errors.New("vote type is longer than 1 byte")
+	}
+
+	decoded := &IstanbulVote{Recipient: vote.Recipient}
+	if len(vote.VoteType) == 1 {
+		decoded.VoteType = vote.VoteType[0]
+	}
+	return decoded, nil
+}
+
+// decodeQuorumIstanbulExtra decodes the extra data of a GoQuorum IBFT header
+func decodeQuorumIstanbulExtra(header *EthTypes.Header) (*quorumIstanbulExtra, error) {
+	if len(header.Extra) < istanbulExtraVanity {
+		return nil, fmt.Errorf("extra data of block %d is too short for istanbul extra data", header.Number)
+	}
+
+	var extra quorumIstanbulExtra
+	if err := rlp.DecodeBytes(header.Extra[istanbulExtraVanity:], &extra); err != nil {
+		return nil, fmt.Errorf("could not decode istanbul extra data of block %d: %w", header.Number, err)
+	}
+	return &extra, nil
+}
+
+// encodeQuorumIstanbulExtra returns the extra data of header with its istanbul
+// extra data replaced by extra
+func encodeQuorumIstanbulExtra(header *EthTypes.Header, extra *quorumIstanbulExtra) ([]byte, error) {
+	encoded, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]byte, 0, istanbulExtraVanity+len(encoded))
+	filtered = append(filtered, header.Extra[:istanbulExtraVanity]...)
+	return append(filtered, encoded...), nil
+}
+
+// quorumIstanbulProposer recovers the proposer of a GoQuorum IBFT header from
+// its seal, which signs the keccak256 hash of the hash of the header without
+// the seal and committed seals
+func quorumIstanbulProposer(header *EthTypes.Header, extra *quorumIstanbulExtra) (common.Address, error) {
+	unsealed := &quorumIstanbulExtra{
+		Validators:    extra.Validators,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	}
+	unsealedExtra, err := encodeQuorumIstanbulExtra(header, unsealed)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unsealedHeader := EthTypes.CopyHeader(header)
+	unsealedHeader.Extra = unsealedExtra
+
+	sigHash := unsealedHeader.Hash()
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(sigHash.Bytes()), extra.Seal)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("could not recover proposer of block %d: %w", header.Number, err)
+	}
+
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
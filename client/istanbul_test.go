@@ -0,0 +1,269 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	istanbulValidators = []common.Address{common.HexToAddress("0x0a"), common.HexToAddress("0x0b")}
+	istanbulSeals      = [][]byte{make([]byte, crypto.SignatureLength), make([]byte, crypto.SignatureLength)}
+)
+
+// besuExtraData encodes the extra data of an IBFT 2.0 or QBFT header with the
+// raw vote and round
+func besuExtraData(t *testing.T, vote []byte, round []byte, seals [][]byte) []byte {
+	extra, err := rlp.EncodeToBytes([]interface{}{
+		make([]byte, 32),
+		istanbulValidators,
+		rlp.RawValue(vote),
+		rlp.RawValue(round),
+		seals,
+	})
+	assert.NoError(t, err)
+	return extra
+}
+
+// quorumIstanbulHeader returns a GoQuorum IBFT header at number proposed by a
+// new proposer, with seals as its committed seals
+func quorumIstanbulHeader(t *testing.T, number int64, seals [][]byte) (*EthTypes.Header, common.Address) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	header := &EthTypes.Header{
+		Number:     big.NewInt(number),
+		Difficulty: big.NewInt(1),
+		Coinbase:   common.HexToAddress("0x01"),
+	}
+	extra := &quorumIstanbulExtra{Validators: istanbulValidators, Seal: []byte{}, CommittedSeal: [][]byte{}}
+	header.Extra, err = encodeQuorumIstanbulExtra(&EthTypes.Header{Extra: make([]byte, istanbulExtraVanity)}, extra)
+	assert.NoError(t, err)
+
+	extra.Seal, err = crypto.Sign(crypto.Keccak256(header.Hash().Bytes()), key)
+	assert.NoError(t, err)
+	extra.CommittedSeal = seals
+	header.Extra, err = encodeQuorumIstanbulExtra(header, extra)
+	assert.NoError(t, err)
+
+	return header, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestParseIstanbulHeader(t *testing.T) {
+	coinbase := common.HexToAddress("0x01")
+	quorumHeader, proposer := quorumIstanbulHeader(t, 10, istanbulSeals)
+
+	tests := map[string]struct {
+		engine        string
+		header        *EthTypes.Header
+		expected      *IstanbulHeader
+		expectedError string
+	}{
+		"ibft": {
+			engine: configuration.IBFTConsensusEngine,
+			header: &EthTypes.Header{
+				Number:   big.NewInt(10),
+				Coinbase: coinbase,
+				// No vote and a 4 byte round
+				Extra: besuExtraData(t, rlp.EmptyString, []byte{0x84, 0, 0, 0, 2}, istanbulSeals),
+			},
+			expected: &IstanbulHeader{
+				Proposer:       coinbase,
+				Validators:     istanbulValidators,
+				Round:          2,
+				CommittedSeals: istanbulSeals,
+			},
+		},
+		"qbft": {
+			engine: configuration.QBFTConsensusEngine,
+			header: &EthTypes.Header{
+				Number:   big.NewInt(10),
+				Coinbase: coinbase,
+				Extra: besuExtraData(
+					t,
+					append([]byte{0xd7, 0x94}, append(common.HexToAddress("0x0c").Bytes(), 0x81, 0xff)...),
+					[]byte{0x03},
+					istanbulSeals,
+				),
+			},
+			expected: &IstanbulHeader{
+				Proposer:       coinbase,
+				Validators:     istanbulValidators,
+				Vote:           &IstanbulVote{Recipient: common.HexToAddress("0x0c"), VoteType: 0xff},
+				Round:          3,
+				CommittedSeals: istanbulSeals,
+			},
+		},
+		"qbft drop vote": {
+			engine: configuration.QBFTConsensusEngine,
+			header: &EthTypes.Header{
+				Number:   big.NewInt(10),
+				Coinbase: coinbase,
+				Extra: besuExtraData(
+					t,
+					append([]byte{0xd6, 0x94}, append(common.HexToAddress("0x0c").Bytes(), 0x00)...),
+					rlp.EmptyString,
+					[][]byte{},
+				),
+			},
+			expected: &IstanbulHeader{
+				Proposer:       coinbase,
+				Validators:     istanbulValidators,
+				Vote:           &IstanbulVote{Recipient: common.HexToAddress("0x0c")},
+				CommittedSeals: [][]byte{},
+			},
+		},
+		"istanbul": {
+			engine: configuration.IstanbulConsensusEngine,
+			header: quorumHeader,
+			expected: &IstanbulHeader{
+				Proposer:       proposer,
+				Validators:     istanbulValidators,
+				CommittedSeals: istanbulSeals,
+			},
+		},
+		"malformed extra data": {
+			engine:        configuration.QBFTConsensusEngine,
+			header:        &EthTypes.Header{Number: big.NewInt(10), Extra: make([]byte, 32)},
+			expectedError: "could not decode qbft extra data of block 10",
+		},
+		"not istanbul": {
+			engine:        configuration.CliqueConsensusEngine,
+			header:        &EthTypes.Header{Number: big.NewInt(10)},
+			expectedError: `"clique" is not an IBFT or QBFT consensus engine`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			istanbulHeader, err := ParseIstanbulHeader(test.engine, test.header)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, istanbulHeader)
+		})
+	}
+}
+
+func TestIstanbulHeaderHash(t *testing.T) {
+	t.Run("ibft", func(t *testing.T) {
+		header := &EthTypes.Header{
+			Number: big.NewInt(10),
+			Extra:  besuExtraData(t, rlp.EmptyString, []byte{0x84, 0, 0, 0, 2}, istanbulSeals),
+		}
+		filtered, err := rlp.EncodeToBytes([]interface{}{make([]byte, 32), istanbulValidators, rlp.RawValue(rlp.EmptyString)})
+		assert.NoError(t, err)
+
+		hash, err := IstanbulHeaderHash(configuration.IBFTConsensusEngine, header)
+		assert.NoError(t, err)
+		assert.Equal(t, (&EthTypes.Header{Number: big.NewInt(10), Extra: filtered}).Hash(), hash)
+		assert.NotEqual(t, header.Hash(), hash)
+	})
+
+	t.Run("qbft", func(t *testing.T) {
+		header := &EthTypes.Header{
+			Number: big.NewInt(10),
+			Extra:  besuExtraData(t, rlp.EmptyList, []byte{0x03}, istanbulSeals),
+		}
+		hash, err := IstanbulHeaderHash(configuration.QBFTConsensusEngine, header)
+		assert.NoError(t, err)
+
+		// The hash excludes the round and the committed seals
+		proposed := &EthTypes.Header{
+			Number: big.NewInt(10),
+			Extra:  besuExtraData(t, rlp.EmptyList, rlp.EmptyString, [][]byte{}),
+		}
+		assert.Equal(t, proposed.Hash(), hash)
+	})
+
+	t.Run("istanbul", func(t *testing.T) {
+		header, _ := quorumIstanbulHeader(t, 10, istanbulSeals)
+		hash, err := IstanbulHeaderHash(configuration.IstanbulConsensusEngine, header)
+		assert.NoError(t, err)
+
+		extra, err := decodeQuorumIstanbulExtra(header)
+		assert.NoError(t, err)
+		extra.CommittedSeal = [][]byte{}
+		proposed := EthTypes.CopyHeader(header)
+		proposed.Extra, err = encodeQuorumIstanbulExtra(header, extra)
+		assert.NoError(t, err)
+		assert.Equal(t, proposed.Hash(), hash)
+		assert.NotEqual(t, header.Hash(), hash)
+	})
+
+	t.Run("malformed extra data", func(t *testing.T) {
+		_, err := IstanbulHeaderHash(configuration.IstanbulConsensusEngine, &EthTypes.Header{Number: big.NewInt(10)})
+		assert.EqualError(t, err, "extra data of block 10 is too short for istanbul extra data")
+	})
+}
+
+func TestHashHeader(t *testing.T) {
+	header, _ := quorumIstanbulHeader(t, 10, istanbulSeals)
+
+	hash, err := (&SDKClient{}).HashHeader(header, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Hash(), hash)
+
+	hash, err = (&SDKClient{consensusEngine: configuration.IstanbulConsensusEngine}).HashHeader(header, nil)
+	assert.NoError(t, err)
+	expected, err := IstanbulHeaderHash(configuration.IstanbulConsensusEngine, header)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+}
+
+func TestBlockAuthor_Istanbul(t *testing.T) {
+	header, proposer := quorumIstanbulHeader(t, 10, istanbulSeals)
+	genesis := &EthTypes.Header{
+		Number:   big.NewInt(0),
+		Coinbase: common.HexToAddress("0x02"),
+		Extra:    header.Extra,
+	}
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient:       &RPCClient{JSONRPC: mockJSONRPC},
+		consensusEngine: configuration.IstanbulConsensusEngine,
+	}
+	for arg, h := range map[string]*EthTypes.Header{"0xa": header, "0x0": genesis} {
+		h := h
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", arg, false).Return(nil).Run(
+			func(args mock.Arguments) {
+				*args.Get(1).(**EthTypes.Header) = h
+			},
+		)
+	}
+
+	author, err := sdkClient.BlockAuthor(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, proposer.Hex(), author)
+
+	author, err = sdkClient.BlockAuthor(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.Coinbase.Hex(), author)
+}
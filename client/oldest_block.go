@@ -0,0 +1,152 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// oldestBlockRefreshInterval is how long the oldest block is cached. Pruning
+// only moves the oldest block forward, so a stale oldest block is still served
+// by the node until it is pruned again.
+const oldestBlockRefreshInterval = 10 * time.Minute
+
+// OldestBlockFinder is an optional interface a client can implement to report
+// the oldest block the node can serve, for nodes with pruned block history.
+type OldestBlockFinder interface {
+	OldestBlock(ctx context.Context) (*RosettaTypes.BlockIdentifier, error)
+}
+
+// oldestBlockCache is the oldest block found and when it was found. It is
+// shared by the copies of an SDKClient.
+type oldestBlockCache struct {
+	mu        sync.Mutex
+	block     *RosettaTypes.BlockIdentifier
+	checkedAt time.Time
+}
+
+// blockNumberAndHash is the part of a JSON RPC block needed to identify it
+type blockNumberAndHash struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+// OldestBlock returns the oldest block the node can serve. It calls
+// RosettaConfig.OldestBlockMethod when it is set, and otherwise searches for
+// the oldest block available from eth_getBlockByNumber, assuming the node
+// serves every block after it. The result is cached for
+// oldestBlockRefreshInterval by clients created with NewClient.
+func (ec *SDKClient) OldestBlock(ctx context.Context) (*RosettaTypes.BlockIdentifier, error) {
+	cache := ec.oldestBlock
+	if cache == nil {
+		cache = &oldestBlockCache{}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.block != nil && time.Since(cache.checkedAt) < oldestBlockRefreshInterval {
+		return cache.block, nil
+	}
+
+	var (
+		oldest *blockNumberAndHash
+		err    error
+	)
+	if method := ec.rosettaConfig.OldestBlockMethod; len(method) > 0 {
+		var number hexutil.Uint64
+		if err := ec.CallContext(ctx, &number, method); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", method, err)
+		}
+		oldest, err = ec.blockNumberAndHash(ctx, ToBlockNumArg(new(big.Int).SetUint64(uint64(number))))
+	} else {
+		oldest, err = ec.searchOldestBlock(ctx, cache.block)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cache.block = &RosettaTypes.BlockIdentifier{
+		Index: int64(oldest.Number),
+		Hash:  oldest.Hash.Hex(),
+	}
+	cache.checkedAt = time.Now()
+
+	return cache.block, nil
+}
+
+// searchOldestBlock binary searches the oldest block available from
+// eth_getBlockByNumber, from previous, the previous oldest block, or genesis
+// to the default block
+func (ec *SDKClient) searchOldestBlock(
+	ctx context.Context,
+	previous *RosettaTypes.BlockIdentifier,
+) (*blockNumberAndHash, error) {
+	var low uint64
+	if previous != nil {
+		low = uint64(previous.Index)
+	}
+
+	oldest, err := ec.blockNumberAndHash(ctx, ToBlockNumArg(new(big.Int).SetUint64(low)))
+	if err == nil {
+		return oldest, nil
+	} else if err != goEthereum.NotFound {
+		return nil, err
+	}
+
+	oldest, err = ec.blockNumberAndHash(ctx, string(ec.rosettaConfig.DefaultBlock()))
+	if err != nil {
+		return nil, fmt.Errorf("could not get the current block: %w", err)
+	}
+
+	// Block low is unavailable and oldest, the current block, is available
+	high := uint64(oldest.Number)
+	for low+1 < high {
+		mid := low + (high-low)/2
+		block, err := ec.blockNumberAndHash(ctx, ToBlockNumArg(new(big.Int).SetUint64(mid)))
+		switch {
+		case err == goEthereum.NotFound:
+			low = mid
+		case err != nil:
+			return nil, err
+		default:
+			high, oldest = mid, block
+		}
+	}
+
+	return oldest, nil
+}
+
+// blockNumberAndHash returns the number and hash of the block at arg, or
+// goEthereum.NotFound if the node doesn't have it
+func (ec *SDKClient) blockNumberAndHash(ctx context.Context, arg string) (*blockNumberAndHash, error) {
+	var block *blockNumberAndHash
+	if err := ec.CallContext(ctx, &block, "eth_getBlockByNumber", arg, false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, goEthereum.NotFound
+	}
+
+	return block, nil
+}
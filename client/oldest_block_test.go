@@ -0,0 +1,115 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// prunedNode mocks eth_getBlockByNumber of a node serving the blocks from
+// oldest to head
+func prunedNode(mockJSONRPC *mocks.JSONRPC, oldest uint64, head uint64) {
+	mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", mock.Anything, false).Return(nil).Run(
+		func(args mock.Arguments) {
+			number := head
+			if arg := args.Get(3).(string); arg != "latest" {
+				number = hexutil.MustDecodeUint64(arg)
+			}
+			if number >= oldest && number <= head {
+				*args.Get(1).(**blockNumberAndHash) = &blockNumberAndHash{
+					Number: hexutil.Uint64(number),
+					Hash:   common.BigToHash(new(big.Int).SetUint64(number)),
+				}
+			}
+		},
+	)
+}
+
+func TestOldestBlock(t *testing.T) {
+	tests := map[string]struct {
+		oldest uint64
+		method string
+	}{
+		"full history": {
+			oldest: 0,
+		},
+		"pruned": {
+			oldest: 37,
+		},
+		"pruned up to the head": {
+			oldest: 100,
+		},
+		"oldest block method": {
+			oldest: 37,
+			method: "custom_oldestBlock",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: configuration.RosettaConfig{OldestBlockMethod: test.method},
+				oldestBlock:   &oldestBlockCache{},
+			}
+			prunedNode(mockJSONRPC, test.oldest, 100)
+			if len(test.method) > 0 {
+				mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, test.method).Return(nil).Run(
+					func(args mock.Arguments) {
+						*args.Get(1).(*hexutil.Uint64) = hexutil.Uint64(test.oldest)
+					},
+				).Once()
+			}
+
+			expected := &RosettaTypes.BlockIdentifier{
+				Index: int64(test.oldest),
+				Hash:  common.BigToHash(new(big.Int).SetUint64(test.oldest)).Hex(),
+			}
+			oldestBlock, err := sdkClient.OldestBlock(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, oldestBlock)
+
+			// The oldest block is cached
+			calls := len(mockJSONRPC.Calls)
+			oldestBlock, err = sdkClient.OldestBlock(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, oldestBlock)
+			assert.Len(t, mockJSONRPC.Calls, calls)
+		})
+	}
+}
+
+func TestOldestBlock_SearchFromPrevious(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+	prunedNode(mockJSONRPC, 60, 100)
+
+	block, err := sdkClient.searchOldestBlock(context.Background(), &RosettaTypes.BlockIdentifier{Index: 37})
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(60), block.Number)
+	mockJSONRPC.AssertNotCalled(t, "CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", "0x24", false)
+}
@@ -0,0 +1,331 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// DepositTxType is the type of the deposit transactions of OP stack chains,
+	// which are derived from L1 and credit the minted ETH of deposits
+	DepositTxType = 0x7e
+
+	// GetL1FeeMethodID is the method id of getL1Fee(bytes) of GasPriceOracle
+	GetL1FeeMethodID = "0x49948e0e"
+
+	// SourceHashExtension is the extension holding the source hash of a deposit
+	// transaction, which identifies the L1 event it is derived from
+	SourceHashExtension = "sourceHash"
+)
+
+var (
+	// SequencerFeeVault is the OP stack predeploy receiving the priority fees
+	SequencerFeeVault = common.HexToAddress("0x4200000000000000000000000000000000000011")
+
+	// BaseFeeVault is the OP stack predeploy receiving the base fees, which are
+	// not burned
+	BaseFeeVault = common.HexToAddress("0x4200000000000000000000000000000000000019")
+
+	// L1FeeVault is the OP stack predeploy receiving the L1 data fees
+	L1FeeVault = common.HexToAddress("0x420000000000000000000000000000000000001A")
+
+	// GasPriceOracle is the OP stack predeploy computing the L1 data fee of a
+	// transaction with getL1Fee
+	GasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+)
+
+// l1FeeFields are the receipt fields of OP stack chains detailing the L1 data
+// fee, added to the transaction extensions
+var l1FeeFields = []string{"l1Fee", "l1GasPrice", "l1GasUsed", "l1FeeScalar"}
+
+// depositTransactionDefaults are the legacy transaction fields that deposit
+// transactions may omit, as deposits are not signed and pay no gas price
+var depositTransactionDefaults = map[string]interface{}{
+	"gasPrice": hexutil.Big{},
+	"nonce":    hexutil.Uint64(0),
+	"v":        hexutil.Big{},
+	"r":        hexutil.Big{},
+	"s":        hexutil.Big{},
+}
+
+// IsDepositTransaction returns whether tx is a deposit transaction of an OP
+// stack chain
+func IsDepositTransaction(tx *LoadedTransaction) bool {
+	_, ok := Extension[string](tx, SourceHashExtension)
+	return ok
+}
+
+// decodeDepositTransaction decodes a JSON RPC deposit transaction of an OP
+// stack chain, which go-ethereum doesn't support, as an unsigned legacy
+// transaction with the same recipient, value, gas and input. The sender and
+// hash are kept in TxExtraInfo, the minted value in TxExtraInfo.Mint and the
+// source hash in the extensions.
+func decodeDepositTransaction(msg []byte) (*EthTypes.Transaction, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return nil, false, err
+	}
+	var txType hexutil.Uint64
+	if err := json.Unmarshal(fields["type"], &txType); err != nil || txType != DepositTxType {
+		return nil, false, nil
+	}
+
+	fields["type"] = json.RawMessage(`"0x0"`)
+	legacyTx, err := withDefaultFields(fields, depositTransactionDefaults)
+	if err != nil {
+		return nil, false, err
+	}
+	var tx EthTypes.Transaction
+	if err := json.Unmarshal(legacyTx, &tx); err != nil {
+		return nil, false, fmt.Errorf("could not decode deposit transaction: %w", err)
+	}
+
+	return &tx, true, nil
+}
+
+// OPStackReceipt returns the receipt of a transaction of an OP stack chain from
+// its JSON RPC receipt, for clients of chains with RosettaConfig.SupportsOpStack
+// set. The fee is the gas used at the effective gas price of the receipt plus
+// its L1 data fee, which is kept in L1Fee. Deposit transactions pay no fee. The
+// raw receipt is kept in RawMessage for the L1 fee fields, see
+// ApplyOPStackReceipt.
+func OPStackReceipt(raw json.RawMessage) (*RosettaTxReceipt, error) {
+	receipt, extraFields, err := DecodeReceiptTolerant(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, gasPriceSource := receipt.EffectiveGasPrice, ReceiptGasPriceSource
+	if gasPrice == nil {
+		if receipt.Type != DepositTxType {
+			return nil, fmt.Errorf("receipt of %s has no effective gas price", receipt.TxHash)
+		}
+		gasPrice, gasPriceSource = new(big.Int), ""
+	}
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	fee := new(big.Int).Mul(gasUsed, gasPrice)
+	l1Fee, err := decodeL1Fee(extraFields, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if l1Fee != nil {
+		fee.Add(fee, l1Fee)
+	}
+
+	return &RosettaTxReceipt{
+		Type:           receipt.Type,
+		GasPrice:       gasPrice,
+		GasUsed:        gasUsed,
+		TransactionFee: fee,
+		L1Fee:          l1Fee,
+		Logs:           receipt.Logs,
+		RawMessage:     raw,
+		Status:         receipt.Status,
+		Bloom:          receipt.Bloom,
+		GasPriceSource: gasPriceSource,
+	}, nil
+}
+
+// decodeL1Fee returns the l1Fee field of the receipt of hash, or nil if the
+// receipt has none
+func decodeL1Fee(extraFields map[string]json.RawMessage, hash common.Hash) (*big.Int, error) {
+	l1Fee, ok := extraFields["l1Fee"]
+	if !ok || string(l1Fee) == "null" {
+		return nil, nil
+	}
+	var decoded hexutil.Big
+	if err := json.Unmarshal(l1Fee, &decoded); err != nil {
+		return nil, fmt.Errorf("could not decode l1 fee of %s: %w", hash, err)
+	}
+
+	return decoded.ToInt(), nil
+}
+
+// ApplyOPStackReceipt adjusts tx to the fee semantics of OP stack chains once
+// its receipt is loaded. Deposit transactions pay no fee. Other transactions
+// keep the base fee in FeeBurned, which OP stack chains pay to BaseFeeVault
+// instead of burning, see services.OPStackFeeOps. The L1 fee fields of the raw
+// receipt are added to the extensions of tx, unless the transaction already
+// has them.
+func ApplyOPStackReceipt(tx *LoadedTransaction) error {
+	if tx.Receipt == nil {
+		return errors.New("transaction has no receipt")
+	}
+	if IsDepositTransaction(tx) {
+		tx.FeeAmount = big.NewInt(0)
+		tx.FeeBurned = nil
+		return nil
+	}
+	if len(tx.Receipt.RawMessage) == 0 {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(tx.Receipt.RawMessage, &fields); err != nil {
+		return fmt.Errorf("could not decode receipt: %w", err)
+	}
+	for _, field := range l1FeeFields {
+		value, ok := fields[field]
+		if !ok || value == nil {
+			continue
+		}
+		if _, ok := tx.Extensions[field]; !ok {
+			tx.SetExtension(field, value)
+		}
+	}
+
+	return nil
+}
+
+// opStackTransactionByHash returns the transaction hash of an OP stack chain
+// decoded from its RPC transaction, with its sender and extensions, or nil if
+// it is pending. It errors with ethereum.NotFound if there is no transaction.
+func (ec *SDKClient) opStackTransactionByHash(ctx context.Context, hash common.Hash) (*RPCTransaction, error) {
+	var tx *RPCTransaction
+	if err := ec.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ethereum.NotFound
+	}
+	if tx.TxExtraInfo.BlockNumber == nil {
+		return nil, nil
+	}
+	if tx.TxExtraInfo.From == nil || tx.TxExtraInfo.TxHash == nil {
+		return nil, fmt.Errorf("transaction %s has no sender or hash", hash)
+	}
+
+	return tx, nil
+}
+
+// OPStackBlockReceipts returns the receipts of the transactions txs of the
+// block blockHash of an OP stack chain, see OPStackReceipt. It errors with
+// sdkTypes.ErrClientBlockOrphaned if a receipt is of another block. Clients of
+// OP stack chains call it from GetBlockReceipts.
+func (ec *SDKClient) OPStackBlockReceipts(
+	ctx context.Context,
+	blockHash common.Hash,
+	txs []RPCTransaction,
+) ([]*RosettaTxReceipt, error) {
+	receipts := make([]*RosettaTxReceipt, len(txs))
+	if len(txs) == 0 {
+		return receipts, nil
+	}
+
+	rawReceipts := make([]json.RawMessage, len(txs))
+	reqs := make([]rpc.BatchElem, len(txs))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{txs[i].TxExtraInfo.TxHash.String()},
+			Result: &rawReceipts[i],
+		}
+	}
+	if err := ec.BatchCallContext(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, reqs[i].Error
+		}
+		if len(rawReceipts[i]) == 0 || string(rawReceipts[i]) == "null" {
+			return nil, fmt.Errorf("got empty receipt for %s", txs[i].TxExtraInfo.TxHash.Hex())
+		}
+
+		var location struct {
+			BlockHash common.Hash `json:"blockHash"`
+		}
+		if err := json.Unmarshal(rawReceipts[i], &location); err != nil {
+			return nil, fmt.Errorf("could not decode receipt of %s: %w", txs[i].TxExtraInfo.TxHash.Hex(), err)
+		}
+		if location.BlockHash != blockHash {
+			return nil, fmt.Errorf(
+				"expected block hash %s for Transaction but got %s: %w",
+				blockHash.Hex(),
+				location.BlockHash.Hex(),
+				sdkTypes.ErrClientBlockOrphaned,
+			)
+		}
+
+		receipt, err := OPStackReceipt(rawReceipts[i])
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = receipt
+	}
+
+	return receipts, nil
+}
+
+// OPStackTransactionReceipt returns the receipt of the transaction txHash of an
+// OP stack chain, see OPStackReceipt. Clients of OP stack chains call it from
+// GetTransactionReceipt.
+func (ec *SDKClient) OPStackTransactionReceipt(ctx context.Context, txHash common.Hash) (*RosettaTxReceipt, error) {
+	var raw json.RawMessage
+	if err := ec.CallContext(ctx, &raw, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("got empty receipt for %s", txHash.Hex())
+	}
+
+	return OPStackReceipt(raw)
+}
+
+// OPStackL1DataFee returns the L1 data fee of the serialized unsigned
+// transaction ethTxBytes, computed by the GasPriceOracle of the chain at the
+// latest block
+func (ec *SDKClient) OPStackL1DataFee(ctx context.Context, ethTxBytes []byte) (*big.Int, error) {
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	args, err := abi.Arguments{{Type: bytesType}}.Pack(ethTxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	callParams := map[string]string{
+		"to":   GasPriceOracle.String(),
+		"data": GetL1FeeMethodID + common.Bytes2Hex(args),
+	}
+	var resp string
+	if err := ec.CallContext(ctx, &resp, "eth_call", callParams, "latest"); err != nil {
+		return nil, err
+	}
+	output, err := hexutil.Decode(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(output) != common.HashLength {
+		return nil, fmt.Errorf("unexpected getL1Fee output %s", resp)
+	}
+
+	return new(big.Int).SetBytes(output), nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testDepositTransaction = `{
+	"blockHash": "0x2c9b2ab2d4a1bbd3c0f9c8a5f86c0f1dbf5c3a1e02c1b8a5b0c5a9d1e0f2a3b4",
+	"blockNumber": "0xa4e1c2",
+	"from": "0x977f82a600a1414e583f7f13623f1ac5d58b1c0b",
+	"gas": "0x186a0",
+	"hash": "0x5d4f1bcb5a4c8b1e1e3d5d9c2a8f7e6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e",
+	"input": "0x",
+	"isSystemTx": false,
+	"mint": "0xde0b6b3a7640000",
+	"nonce": "0x4d2",
+	"sourceHash": "0x9a3bde2a4d2e2ccc0b0cd5cb7b6ec6e2cfb8a8f62d3dd4a7d4e0c1f0b9d2a3b4",
+	"to": "0x977f82a600a1414e583f7f13623f1ac5d58b1c0b",
+	"transactionIndex": "0x1",
+	"type": "0x7e",
+	"value": "0xde0b6b3a7640000"
+}`
+
+const testOPStackReceipt = `{
+	"blockHash": "0x2c9b2ab2d4a1bbd3c0f9c8a5f86c0f1dbf5c3a1e02c1b8a5b0c5a9d1e0f2a3b4",
+	"blockNumber": "0xa4e1c2",
+	"contractAddress": null,
+	"cumulativeGasUsed": "0x1e8d4",
+	"effectiveGasPrice": "0x3b9aca64",
+	"from": "0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0",
+	"gasUsed": "0x5208",
+	"l1Fee": "0x2540be400",
+	"l1FeeScalar": "0.684",
+	"l1GasPrice": "0x6fc23ac00",
+	"l1GasUsed": "0x640",
+	"logs": [],
+	"logsBloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	"status": "0x1",
+	"to": "0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d",
+	"transactionHash": "0x2a4ad3e4c7ae3e8f5c1f1e64a5e3d7d2c54e2f2b0f0d3c3e9c1d1e7a0b6c5d4e",
+	"transactionIndex": "0x2",
+	"type": "0x2"
+}`
+
+func TestDecodeDepositTransaction(t *testing.T) {
+	var tx RPCTransaction
+	assert.NoError(t, json.Unmarshal([]byte(testDepositTransaction), &tx))
+	assert.Equal(t, uint8(EthTypes.LegacyTxType), tx.Tx.Type())
+	assert.Equal(t, uint64(100000), tx.Tx.Gas())
+	assert.Equal(t, int64(0), tx.Tx.GasPrice().Int64())
+	assert.Equal(t, big.NewInt(1e18), tx.Tx.Value())
+	assert.Equal(t, common.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"), *tx.Tx.To())
+	assert.Equal(t,
+		common.HexToHash("0x5d4f1bcb5a4c8b1e1e3d5d9c2a8f7e6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e"),
+		*tx.TxExtraInfo.TxHash,
+	)
+	assert.Equal(t, common.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"), *tx.TxExtraInfo.From)
+
+	loaded := &LoadedTransaction{Mint: tx.Mint, Extensions: tx.Extensions}
+	assert.True(t, IsDepositTransaction(loaded))
+	assert.Equal(t, big.NewInt(1e18), loaded.GetMint())
+	assert.False(t, IsDepositTransaction(&LoadedTransaction{}))
+}
+
+func TestOPStackReceipt(t *testing.T) {
+	receipt, err := OPStackReceipt(json.RawMessage(testOPStackReceipt))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(21000), receipt.GasUsed)
+	assert.Equal(t, big.NewInt(0x3b9aca64), receipt.GasPrice)
+	assert.Equal(t, big.NewInt(1e10), receipt.L1Fee)
+	assert.Equal(t,
+		new(big.Int).Add(new(big.Int).Mul(big.NewInt(21000), big.NewInt(0x3b9aca64)), big.NewInt(1e10)),
+		receipt.TransactionFee,
+	)
+
+	// Deposit receipts have no effective gas price nor L1 fee
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(testOPStackReceipt), &fields))
+	for _, field := range []string{"effectiveGasPrice", "l1Fee", "l1FeeScalar", "l1GasPrice", "l1GasUsed"} {
+		delete(fields, field)
+	}
+	fields["depositNonce"] = "0x4d2"
+	fields["type"] = "0x7e"
+	deposit, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	receipt, err = OPStackReceipt(deposit)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt.L1Fee)
+	assert.Equal(t, int64(0), receipt.TransactionFee.Int64())
+
+	fields["type"] = "0x2"
+	withoutPrice, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	_, err = OPStackReceipt(withoutPrice)
+	assert.EqualError(
+		t,
+		err,
+		"receipt of 0x2a4ad3e4c7ae3e8f5c1f1e64a5e3d7d2c54e2f2b0f0d3c3e9c1d1e7a0b6c5d4e has no effective gas price",
+	)
+}
+
+func TestApplyOPStackReceipt(t *testing.T) {
+	receipt, err := OPStackReceipt(json.RawMessage(testOPStackReceipt))
+	assert.NoError(t, err)
+	tx := &LoadedTransaction{
+		FeeAmount:  receipt.TransactionFee,
+		FeeBurned:  big.NewInt(21000),
+		Receipt:    receipt,
+		Extensions: map[string]interface{}{"l1GasUsed": "0x641"},
+	}
+
+	assert.NoError(t, ApplyOPStackReceipt(tx))
+	// The base fee is kept for the BaseFeeVault
+	assert.Equal(t, big.NewInt(21000), tx.FeeBurned)
+	// The fields of the transaction take precedence over the ones of the receipt
+	assert.Equal(t, map[string]interface{}{
+		"l1Fee":       "0x2540be400",
+		"l1FeeScalar": "0.684",
+		"l1GasPrice":  "0x6fc23ac00",
+		"l1GasUsed":   "0x641",
+	}, tx.Extensions)
+
+	deposit := &LoadedTransaction{
+		FeeAmount:  big.NewInt(10),
+		FeeBurned:  big.NewInt(10),
+		Receipt:    &RosettaTxReceipt{},
+		Extensions: map[string]interface{}{SourceHashExtension: "0x01"},
+	}
+	assert.NoError(t, ApplyOPStackReceipt(deposit))
+	assert.Equal(t, int64(0), deposit.FeeAmount.Int64())
+	assert.Nil(t, deposit.FeeBurned)
+
+	assert.EqualError(t, ApplyOPStackReceipt(&LoadedTransaction{}), "transaction has no receipt")
+}
+
+func TestOPStackBlockReceipts(t *testing.T) {
+	ctx := context.Background()
+	txHash := common.HexToHash("0x2a4ad3e4c7ae3e8f5c1f1e64a5e3d7d2c54e2f2b0f0d3c3e9c1d1e7a0b6c5d4e")
+	blockHash := common.HexToHash("0x2c9b2ab2d4a1bbd3c0f9c8a5f86c0f1dbf5c3a1e02c1b8a5b0c5a9d1e0f2a3b4")
+	txs := []RPCTransaction{{TxExtraInfo: TxExtraInfo{TxHash: &txHash}}}
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			assert.Len(t, r, 1)
+			assert.Equal(t, "eth_getTransactionReceipt", r[0].Method)
+			*(r[0].Result.(*json.RawMessage)) = json.RawMessage(testOPStackReceipt)
+		},
+	).Twice()
+	sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+
+	receipts, err := sdkClient.OPStackBlockReceipts(ctx, blockHash, txs)
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	assert.Equal(t, big.NewInt(1e10), receipts[0].L1Fee)
+
+	// Receipts of another block are orphaned
+	_, err = sdkClient.OPStackBlockReceipts(ctx, common.HexToHash("0x01"), txs)
+	assert.ErrorIs(t, err, sdkTypes.ErrClientBlockOrphaned)
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestGetL1DataFee(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to": GasPriceOracle.String(),
+			"data": GetL1FeeMethodID +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"c0de000000000000000000000000000000000000000000000000000000000000",
+		},
+		"latest",
+	).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*string)) = "0x00000000000000000000000000000000000000000000000000000002540be400"
+		},
+	).Once()
+
+	sdkClient := &SDKClient{
+		RPCClient:     &RPCClient{JSONRPC: mockJSONRPC},
+		rosettaConfig: configuration.RosettaConfig{SupportsOpStack: true},
+	}
+	fee, err := sdkClient.GetL1DataFee(ctx, []byte{0xc0, 0xde})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e10), fee)
+	mockJSONRPC.AssertExpectations(t)
+
+	// Only OP stack chains have an L1 data fee oracle
+	_, err = (&SDKClient{}).GetL1DataFee(ctx, []byte{0xc0, 0xde})
+	assert.EqualError(t, err, "GetL1DataFee not implemented")
+}
+
+func TestOPStackTransactionByHash(t *testing.T) {
+	ctx := context.Background()
+	hash := common.HexToHash("0x5d4f1bcb5a4c8b1e1e3d5d9c2a8f7e6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e")
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+	respond := func(raw string) {
+		mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_getTransactionByHash", hash).Return(nil).Run(
+			func(args mock.Arguments) {
+				assert.NoError(t, json.Unmarshal([]byte(raw), args.Get(1)))
+			},
+		).Once()
+	}
+
+	respond(testDepositTransaction)
+	tx, err := sdkClient.opStackTransactionByHash(ctx, hash)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, *tx.TxHash)
+	assert.True(t, IsDepositTransaction(tx.LoadedTransaction()))
+
+	// Pending transactions have no block
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(testDepositTransaction), &fields))
+	delete(fields, "blockNumber")
+	pending, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	respond(string(pending))
+	tx, err = sdkClient.opStackTransactionByHash(ctx, hash)
+	assert.NoError(t, err)
+	assert.Nil(t, tx)
+
+	respond("null")
+	_, err = sdkClient.opStackTransactionByHash(ctx, hash)
+	assert.ErrorIs(t, err, ethereum.NotFound)
+	mockJSONRPC.AssertExpectations(t)
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync"
+)
+
+// maxPooledBufferLen is the largest buffer returned to a pool, so a single huge
+// block doesn't pin its buffers in memory
+const maxPooledBufferLen = 1 << 16
+
+// rpcCallPool holds the buffers debug_traceBlockByHash results are decoded into
+var rpcCallPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]*rpcCall, 0)
+		return &buf
+	},
+}
+
+// flatCallPool holds the scratch buffers traces are flattened into
+var flatCallPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]*FlatCall, 0)
+		return &buf
+	},
+}
+
+// releaseBuffer clears the elements of s, so the pool doesn't keep them alive,
+// and returns s to pool through buf
+func releaseBuffer[T any](pool *sync.Pool, buf *[]*T, s []*T) {
+	if cap(s) > maxPooledBufferLen {
+		return
+	}
+	for i := range s {
+		s[i] = nil
+	}
+	*buf = s[:0]
+	pool.Put(buf)
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/sync/semaphore"
+)
+
+// callTree returns a call with depth levels of width nested calls
+func callTree(depth int, width int) *Call {
+	call := &Call{Type: "CALL", Value: big.NewInt(int64(depth)), GasUsed: new(big.Int)}
+	if depth == 0 {
+		return call
+	}
+	for i := 0; i < width; i++ {
+		call.Calls = append(call.Calls, callTree(depth-1, width))
+	}
+	return call
+}
+
+// callTreeJSON returns the debug trace JSON of callTree
+func callTreeJSON(depth int, width int) string {
+	calls := make([]string, width)
+	for i := range calls {
+		if depth > 0 {
+			calls[i] = callTreeJSON(depth-1, width)
+		}
+	}
+	if depth == 0 {
+		calls = nil
+	}
+	return fmt.Sprintf(
+		`{"type":"CALL","from":"0x1","to":"0x0000000000000000000000000000000000000002","value":"0x%x","gasUsed":"0x0","calls":[%s]}`,
+		depth,
+		strings.Join(calls, ","),
+	)
+}
+
+func TestFlattenTraces(t *testing.T) {
+	root := callTree(2, 2)
+	root.ErrorMessage = "execution reverted"
+	root.Revert = true
+
+	prefix := &FlatCall{Type: "STATICCALL"}
+	flattened := FlattenTraces(root, []*FlatCall{prefix})
+
+	values := make([]int64, len(flattened)-1)
+	for i, call := range flattened[1:] {
+		values[i] = call.Value.Int64()
+		assert.Equal(t, 2-int(values[i]), call.Depth)
+		assert.True(t, call.Revert)
+		assert.Equal(t, "execution reverted", call.ErrorMessage)
+	}
+	assert.Equal(t, prefix, flattened[0])
+	assert.Equal(t, []int64{2, 1, 0, 0, 1, 0, 0}, values)
+
+	// Flattening again must not see calls of the pooled buffer
+	assert.Len(t, FlattenTraces(callTree(0, 0), nil), 1)
+	assert.Empty(t, FlattenTraces(nil, nil))
+}
+
+func BenchmarkFlattenTraces(b *testing.B) {
+	root := callTree(4, 6)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FlattenTraces(root, []*FlatCall{})
+	}
+}
+
+func BenchmarkTraceBlockByHash(b *testing.B) {
+	const numTxs = 200
+	ctx := context.Background()
+
+	txs := make([]RPCTransaction, numTxs)
+	calls := make([]string, numTxs)
+	for i := range txs {
+		hash := common.BigToHash(big.NewInt(int64(i)))
+		txs[i] = RPCTransaction{TxExtraInfo: TxExtraInfo{TxHash: &hash}}
+		calls[i] = `{"result":` + callTreeJSON(2, 4) + `}`
+	}
+	raw := json.RawMessage("[" + strings.Join(calls, ",") + "]")
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "debug_traceBlockByHash", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			*(result.(*json.RawMessage)) = raw
+			return nil
+		},
+	)
+	sdkClient := &SDKClient{
+		RPCClient:      &RPCClient{JSONRPC: mockJSONRPC},
+		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := sdkClient.TraceBlockByHash(ctx, common.Hash{}, txs)
+		if err != nil || len(m) != numTxs {
+			b.Fatal(fmt.Errorf("unexpected traces: %w", err))
+		}
+	}
+}
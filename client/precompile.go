@@ -0,0 +1,190 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	precompileDecodersMu sync.Mutex
+
+	// precompileDecoders are the decoders added by RegisterPrecompileDecoder
+	precompileDecoders = map[string]PrecompileDecoder{}
+)
+
+// PrecompileActivity is what a precompile did in a transaction: its successful
+// calls and the logs it emitted
+type PrecompileActivity struct {
+	Address common.Address
+	Tx      *LoadedTransaction
+	Calls   []*FlatCall
+	Logs    []*EthTypes.Log
+}
+
+// PrecompileDecoder synthesizes the operations of the transfers of the native
+// currency made by a precompile in a transaction, which are not visible to the
+// call tracer, see configuration.RosettaConfig.Precompiles. The operations are
+// appended to the operations of the transaction and re-indexed, so they must
+// have consecutive indexes and only relate to each other. The value of the
+// calls to the precompile is already part of the trace operations.
+type PrecompileDecoder func(activity *PrecompileActivity) ([]*RosettaTypes.Operation, error)
+
+// RegisterPrecompileDecoder registers the decoder with name, for the precompiles
+// of the configuration using it. The names must be unique.
+func RegisterPrecompileDecoder(name string, decoder PrecompileDecoder) error {
+	if len(name) == 0 {
+		return errors.New("precompile decoder name is empty")
+	}
+	if decoder == nil {
+		return fmt.Errorf("precompile decoder %s is nil", name)
+	}
+
+	precompileDecodersMu.Lock()
+	defer precompileDecodersMu.Unlock()
+	if _, ok := precompileDecoders[name]; ok {
+		return fmt.Errorf("precompile decoder %s is already registered", name)
+	}
+	precompileDecoders[name] = decoder
+
+	return nil
+}
+
+// GetPrecompileDecoder returns the decoder registered with name
+func GetPrecompileDecoder(name string) (PrecompileDecoder, bool) {
+	precompileDecodersMu.Lock()
+	defer precompileDecodersMu.Unlock()
+
+	decoder, ok := precompileDecoders[name]
+	return decoder, ok
+}
+
+// NewPrecompileEventDecoder returns a PrecompileDecoder of the transfers logged
+// by the precompile with the event of signature, e.g.
+// "Delegate(address indexed delegator, address indexed validator, uint256 amount)".
+// Each event debits the account of the address argument from and credits the
+// account of the address argument to with the amount of the integer argument
+// amount, in operations of type opType, which must be registered with
+// types.RegisterOpType. One of from and to can be empty for events minting or
+// burning the native currency.
+func NewPrecompileEventDecoder(signature string, from string, to string, amount string, opType string) (PrecompileDecoder, error) {
+	event, err := parseEventSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(from) == 0 && len(to) == 0 {
+		return nil, errors.New("from or to argument is required")
+	}
+	for _, account := range []string{from, to} {
+		if len(account) == 0 {
+			continue
+		}
+		if err := checkEventArgument(event, account, abi.AddressTy); err != nil {
+			return nil, fmt.Errorf("account: %w", err)
+		}
+	}
+	if err := checkEventArgument(event, amount, abi.UintTy); err != nil {
+		return nil, fmt.Errorf("amount: %w", err)
+	}
+	if len(opType) == 0 {
+		return nil, errors.New("operation type is empty")
+	}
+
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+
+	return func(activity *PrecompileActivity) ([]*RosettaTypes.Operation, error) {
+		var ops []*RosettaTypes.Operation
+		for _, log := range activity.Logs {
+			if len(log.Topics) == 0 || log.Topics[0] != event.ID {
+				continue
+			}
+			if len(log.Topics) != len(indexed)+1 {
+				return nil, fmt.Errorf("log %d has %d topics, expected %d", log.Index, len(log.Topics), len(indexed)+1)
+			}
+			values := map[string]interface{}{}
+			if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+				return nil, err
+			}
+			if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+				return nil, err
+			}
+			value, err := bridgeAmount(values[amount])
+			if err != nil {
+				return nil, err
+			}
+
+			var debit *RosettaTypes.Operation
+			if len(from) > 0 {
+				debit, err = precompileOp(int64(len(ops)), opType, values, from, new(big.Int).Neg(value))
+				if err != nil {
+					return nil, err
+				}
+				ops = append(ops, debit)
+			}
+			if len(to) > 0 {
+				credit, err := precompileOp(int64(len(ops)), opType, values, to, value)
+				if err != nil {
+					return nil, err
+				}
+				if debit != nil {
+					credit.RelatedOperations = []*RosettaTypes.OperationIdentifier{{
+						Index: debit.OperationIdentifier.Index,
+					}}
+				}
+				ops = append(ops, credit)
+			}
+		}
+
+		return ops, nil
+	}, nil
+}
+
+// precompileOp returns the operation at index transferring value to or from
+// the address of the account argument of the event values
+func precompileOp(
+	index int64,
+	opType string,
+	values map[string]interface{},
+	account string,
+	value *big.Int,
+) (*RosettaTypes.Operation, error) {
+	address, ok := values[account].(common.Address)
+	if !ok {
+		return nil, fmt.Errorf("account %s is not an address", account)
+	}
+
+	return &RosettaTypes.Operation{
+		OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: index},
+		Type:                opType,
+		Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account:             &RosettaTypes.AccountIdentifier{Address: FormatAddress(address)},
+		Amount:              &RosettaTypes.Amount{Value: value.String(), Currency: sdkTypes.Currency},
+	}, nil
+}
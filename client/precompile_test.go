@@ -0,0 +1,125 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPrecompileDecoder(t *testing.T) {
+	decoder := func(*PrecompileActivity) ([]*RosettaTypes.Operation, error) { return nil, nil }
+
+	assert.NoError(t, RegisterPrecompileDecoder("test-registry", decoder))
+	_, ok := GetPrecompileDecoder("test-registry")
+	assert.True(t, ok)
+	_, ok = GetPrecompileDecoder("test-missing")
+	assert.False(t, ok)
+
+	assert.EqualError(t, RegisterPrecompileDecoder("test-registry", decoder), "precompile decoder test-registry is already registered")
+	assert.EqualError(t, RegisterPrecompileDecoder("", decoder), "precompile decoder name is empty")
+	assert.EqualError(t, RegisterPrecompileDecoder("test-nil", nil), "precompile decoder test-nil is nil")
+}
+
+func TestNewPrecompileEventDecoder(t *testing.T) {
+	signature := "Delegate(address indexed delegator, address indexed validator, uint256 amount)"
+	delegator := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	validator := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	delegate := &EthTypes.Log{
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Delegate(address,address,uint256)")),
+			common.BytesToHash(delegator.Bytes()),
+			common.BytesToHash(validator.Bytes()),
+		},
+		Data: common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+	other := &EthTypes.Log{Topics: []common.Hash{crypto.Keccak256Hash([]byte("Other()"))}}
+
+	tests := map[string]struct {
+		from string
+		to   string
+
+		expected []string
+	}{
+		"transfer": {
+			from:     "delegator",
+			to:       "validator",
+			expected: []string{delegator.Hex() + " -100", validator.Hex() + " 100"},
+		},
+		"burn": {
+			from:     "delegator",
+			expected: []string{delegator.Hex() + " -100"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decoder, err := NewPrecompileEventDecoder(signature, test.from, test.to, "amount", "STAKE")
+			assert.NoError(t, err)
+
+			ops, err := decoder(&PrecompileActivity{Logs: []*EthTypes.Log{other, delegate}})
+			assert.NoError(t, err)
+			summaries := make([]string, 0, len(ops))
+			for i, op := range ops {
+				assert.Equal(t, int64(i), op.OperationIdentifier.Index)
+				assert.Equal(t, "STAKE", op.Type)
+				summaries = append(summaries, op.Account.Address+" "+op.Amount.Value)
+			}
+			assert.Equal(t, test.expected, summaries)
+			if len(ops) == 2 {
+				assert.Equal(t, int64(0), ops[1].RelatedOperations[0].Index)
+			}
+		})
+	}
+
+	_, err := NewPrecompileEventDecoder(signature, "", "", "amount", "STAKE")
+	assert.EqualError(t, err, "from or to argument is required")
+	_, err = NewPrecompileEventDecoder(signature, "amount", "", "amount", "STAKE")
+	assert.ErrorContains(t, err, "account: argument amount of Delegate(address,address,uint256) has type uint256")
+	_, err = NewPrecompileEventDecoder(signature, "delegator", "", "value", "STAKE")
+	assert.ErrorContains(t, err, `amount: Delegate(address,address,uint256) has no argument "value"`)
+	_, err = NewPrecompileEventDecoder(signature, "delegator", "", "amount", "")
+	assert.EqualError(t, err, "operation type is empty")
+
+	_, err = precompileOp(0, "STAKE", map[string]interface{}{"delegator": "0x01"}, "delegator", big.NewInt(1))
+	assert.EqualError(t, err, "account delegator is not an address")
+}
+
+func TestFlattenTraces_Input(t *testing.T) {
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"type": "CALL",
+		"from": "0x1111111111111111111111111111111111111111",
+		"to": "0x0000000000000000000000000000000000000800",
+		"value": "0x0",
+		"input": "0x026e402b"
+	}`), &call))
+
+	flattened := FlattenTraces(&call, []*FlatCall{})
+	assert.Len(t, flattened, 1)
+	assert.Equal(t, "0x026e402b", flattened[0].Input.String())
+
+	// The input is not part of the trace metadata
+	encoded, err := json.Marshal(flattened[0])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "026e402b")
+}
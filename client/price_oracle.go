@@ -0,0 +1,247 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// latestRoundDataMethodID is the method id of latestRoundData() of a
+	// Chainlink aggregator
+	latestRoundDataMethodID = hexutil.MustDecode("0xfeaf968c")
+
+	// decimalsMethodID is the method id of decimals() of a Chainlink aggregator
+	decimalsMethodID = hexutil.MustDecode("0x313ce567")
+)
+
+// latestRoundDataWords is the number of 32 bytes words returned by
+// latestRoundData: roundId, answer, startedAt, updatedAt and answeredInRound
+const latestRoundDataWords = 5
+
+// PriceOracle prices currencies in a reference currency, see
+// RosettaConfig.ReferenceCurrency. Clients can implement it to use their own
+// price source.
+type PriceOracle interface {
+	// Price returns the price of one whole unit of currency in whole units of
+	// the reference currency
+	Price(ctx context.Context, currency *RosettaTypes.Currency) (*big.Rat, error)
+}
+
+// BatchCaller sends batches of JSON RPC calls to the node
+type BatchCaller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// StaticPriceOracle is a PriceOracle with fixed prices, keyed by currency symbol
+type StaticPriceOracle struct {
+	prices map[string]*big.Rat
+}
+
+// NewStaticPriceOracle returns a StaticPriceOracle of the decimal prices,
+// keyed by currency symbol
+func NewStaticPriceOracle(prices map[string]string) (*StaticPriceOracle, error) {
+	oracle := &StaticPriceOracle{prices: make(map[string]*big.Rat, len(prices))}
+	for symbol, price := range prices {
+		p, ok := new(big.Rat).SetString(price)
+		if !ok || p.Sign() <= 0 {
+			return nil, fmt.Errorf("price %q of %s is not a positive number", price, symbol)
+		}
+		oracle.prices[symbol] = p
+	}
+
+	return oracle, nil
+}
+
+// Price returns the static price of currency
+func (o *StaticPriceOracle) Price(_ context.Context, currency *RosettaTypes.Currency) (*big.Rat, error) {
+	price, ok := o.prices[currency.Symbol]
+	if !ok {
+		return nil, fmt.Errorf("no static price for %s", currency.Symbol)
+	}
+
+	return new(big.Rat).Set(price), nil
+}
+
+// ChainlinkPriceOracle is a PriceOracle reading the latest round of Chainlink
+// aggregators, keyed by currency symbol
+type ChainlinkPriceOracle struct {
+	caller BatchCaller
+	feeds  map[string]common.Address
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewChainlinkPriceOracle returns a ChainlinkPriceOracle of the feeds, keyed
+// by currency symbol, called through caller. Rounds older than maxAge are
+// rejected, unless it is zero.
+func NewChainlinkPriceOracle(
+	caller BatchCaller,
+	feeds map[string]string,
+	maxAge time.Duration,
+) (*ChainlinkPriceOracle, error) {
+	oracle := &ChainlinkPriceOracle{
+		caller: caller,
+		feeds:  make(map[string]common.Address, len(feeds)),
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+	for symbol, feed := range feeds {
+		if !common.IsHexAddress(feed) {
+			return nil, fmt.Errorf("price feed %q of %s is not an address", feed, symbol)
+		}
+		oracle.feeds[symbol] = common.HexToAddress(feed)
+	}
+
+	return oracle, nil
+}
+
+// Price returns the answer of the latest round of the feed of currency, read
+// with its decimals in one batch
+func (o *ChainlinkPriceOracle) Price(ctx context.Context, currency *RosettaTypes.Currency) (*big.Rat, error) {
+	feed, ok := o.feeds[currency.Symbol]
+	if !ok {
+		return nil, fmt.Errorf("no price feed for %s", currency.Symbol)
+	}
+
+	var roundData, decimals hexutil.Bytes
+	reqs := []rpc.BatchElem{
+		{
+			Method: "eth_call",
+			Args:   []interface{}{map[string]interface{}{"to": feed.String(), "data": hexutil.Encode(latestRoundDataMethodID)}, "latest"},
+			Result: &roundData,
+		},
+		{
+			Method: "eth_call",
+			Args:   []interface{}{map[string]interface{}{"to": feed.String(), "data": hexutil.Encode(decimalsMethodID)}, "latest"},
+			Result: &decimals,
+		},
+	}
+	if err := o.caller.BatchCallContext(ctx, reqs); err != nil {
+		return nil, fmt.Errorf("could not read price feed %s: %w", feed, err)
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("could not read price feed %s: %w", feed, req.Error)
+		}
+	}
+
+	if len(roundData) != latestRoundDataWords*common.HashLength {
+		return nil, fmt.Errorf("price feed %s returned %d bytes of round data", feed, len(roundData))
+	}
+	if len(decimals) != common.HashLength {
+		return nil, fmt.Errorf("price feed %s returned %d bytes of decimals", feed, len(decimals))
+	}
+	answer := new(big.Int).SetBytes(roundData[common.HashLength : 2*common.HashLength])
+	// The answer is an int256, negative when its first bit is set
+	if answer.Sign() == 0 || roundData[common.HashLength]&0x80 != 0 {
+		return nil, fmt.Errorf("price feed %s answered a price that is not positive", feed)
+	}
+	updatedAt := new(big.Int).SetBytes(roundData[3*common.HashLength : 4*common.HashLength])
+	if o.maxAge > 0 && o.now().Sub(time.Unix(updatedAt.Int64(), 0)) > o.maxAge {
+		return nil, fmt.Errorf("price feed %s was last updated at %s", feed, time.Unix(updatedAt.Int64(), 0).UTC())
+	}
+
+	// decimals() returns a uint8, so larger values would only make the scale huge
+	decimalsValue := new(big.Int).SetBytes(decimals)
+	if decimalsValue.Cmp(big.NewInt(math.MaxUint8)) > 0 {
+		return nil, fmt.Errorf("price feed %s returned %s decimals", feed, decimalsValue)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), decimalsValue, nil) // nolint:gomnd
+	return new(big.Rat).SetFrac(answer, scale), nil
+}
+
+// fallbackPriceOracle prices currencies with the first oracle having a price
+type fallbackPriceOracle []PriceOracle
+
+// Price returns the first price of currency found by the oracles, or the
+// error of the last oracle
+func (o fallbackPriceOracle) Price(ctx context.Context, currency *RosettaTypes.Currency) (*big.Rat, error) {
+	err := errors.New("no price oracle")
+	for _, oracle := range o {
+		var price *big.Rat
+		price, err = oracle.Price(ctx, currency)
+		if err == nil {
+			return price, nil
+		}
+	}
+
+	return nil, err
+}
+
+// NewPriceOracle returns the PriceOracle of the Chainlink feeds and static
+// prices of cfg, Chainlink feeds first, or nil when neither is configured
+func NewPriceOracle(cfg configuration.RosettaConfig, caller BatchCaller) (PriceOracle, error) {
+	var oracles fallbackPriceOracle
+	if len(cfg.ChainlinkPriceFeeds) > 0 {
+		chainlink, err := NewChainlinkPriceOracle(caller, cfg.ChainlinkPriceFeeds, cfg.MaxPriceAge)
+		if err != nil {
+			return nil, err
+		}
+		oracles = append(oracles, chainlink)
+	}
+	if len(cfg.StaticPrices) > 0 {
+		static, err := NewStaticPriceOracle(cfg.StaticPrices)
+		if err != nil {
+			return nil, err
+		}
+		oracles = append(oracles, static)
+	}
+	if len(oracles) == 0 {
+		return nil, nil
+	}
+
+	return oracles, nil
+}
+
+// ReferenceAmount converts amount to the reference currency with the price
+// of its currency from oracle, rounded down to the decimals of reference
+func ReferenceAmount(
+	ctx context.Context,
+	oracle PriceOracle,
+	amount *RosettaTypes.Amount,
+	reference *RosettaTypes.Currency,
+) (*RosettaTypes.Amount, error) {
+	value, ok := new(big.Int).SetString(amount.Value, 10) // nolint:gomnd
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid amount", amount.Value)
+	}
+	price, err := oracle.Price(ctx, amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// value / 10^decimals whole units at price, in the smallest unit of reference
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt(value), price)
+	converted.Mul(converted, new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(reference.Decimals)), nil),       // nolint:gomnd
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(amount.Currency.Decimals)), nil), // nolint:gomnd
+	))
+
+	return Amount(new(big.Int).Quo(converted.Num(), converted.Denom()), reference), nil
+}
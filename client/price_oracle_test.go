@@ -0,0 +1,166 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	ethCurrency   = &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18}
+	usdCurrency   = &RosettaTypes.Currency{Symbol: "USD", Decimals: 2}
+	testPriceFeed = "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"
+)
+
+// roundData returns the latestRoundData result of a Chainlink aggregator
+func roundData(answer *big.Int, updatedAt int64) hexutil.Bytes {
+	data := make([]byte, 0, latestRoundDataWords*common.HashLength)
+	data = append(data, common.BigToHash(big.NewInt(1)).Bytes()...)
+	data = append(data, common.BigToHash(answer).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(updatedAt)).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(updatedAt)).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(1)).Bytes()...)
+	return data
+}
+
+func TestStaticPriceOracle(t *testing.T) {
+	oracle, err := NewStaticPriceOracle(map[string]string{"ETH": "3125.42"})
+	assert.NoError(t, err)
+
+	price, err := oracle.Price(context.Background(), ethCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(312542, 100), price)
+
+	_, err = oracle.Price(context.Background(), &RosettaTypes.Currency{Symbol: "BTC", Decimals: 8})
+	assert.EqualError(t, err, "no static price for BTC")
+
+	_, err = NewStaticPriceOracle(map[string]string{"ETH": "0"})
+	assert.EqualError(t, err, `price "0" of ETH is not a positive number`)
+}
+
+func TestChainlinkPriceOracle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	// A negative int256 answer
+	negative := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)) // nolint:gomnd
+
+	tests := map[string]struct {
+		roundData     hexutil.Bytes
+		decimals      int64
+		batchErr      error
+		expected      *big.Rat
+		expectedError string
+	}{
+		"price": {
+			roundData: roundData(big.NewInt(312542000000), now.Unix()-60),
+			decimals:  8,
+			expected:  big.NewRat(312542, 100),
+		},
+		"too many decimals": {
+			roundData:     roundData(big.NewInt(312542000000), now.Unix()-60),
+			decimals:      256,
+			expectedError: "price feed " + common.HexToAddress(testPriceFeed).String() + " returned 256 decimals",
+		},
+		"stale": {
+			roundData:     roundData(big.NewInt(312542000000), now.Unix()-7200),
+			expectedError: "price feed " + common.HexToAddress(testPriceFeed).String() + " was last updated at 2023-11-14 20:13:20 +0000 UTC",
+		},
+		"negative": {
+			roundData:     roundData(negative, now.Unix()),
+			expectedError: "price feed " + common.HexToAddress(testPriceFeed).String() + " answered a price that is not positive",
+		},
+		"batch error": {
+			batchErr:      errors.New("connection refused"),
+			expectedError: "could not read price feed " + common.HexToAddress(testPriceFeed).String() + ": connection refused",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(test.batchErr).Run(
+				func(args mock.Arguments) {
+					reqs := args.Get(1).([]rpc.BatchElem)
+					assert.Len(t, reqs, 2)
+					*reqs[0].Result.(*hexutil.Bytes) = test.roundData
+					*reqs[1].Result.(*hexutil.Bytes) = common.BigToHash(big.NewInt(test.decimals)).Bytes()
+				},
+			).Once()
+
+			oracle, err := NewChainlinkPriceOracle(mockJSONRPC, map[string]string{"ETH": testPriceFeed}, time.Hour)
+			assert.NoError(t, err)
+			oracle.now = func() time.Time { return now }
+
+			price, err := oracle.Price(context.Background(), ethCurrency)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, price)
+			}
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewPriceOracle(t *testing.T) {
+	oracle, err := NewPriceOracle(configuration.RosettaConfig{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, oracle)
+
+	// The static prices price the currencies without a feed
+	mockJSONRPC := &mocks.JSONRPC{}
+	oracle, err = NewPriceOracle(configuration.RosettaConfig{
+		ReferenceCurrency:   usdCurrency,
+		ChainlinkPriceFeeds: map[string]string{"ETH": testPriceFeed},
+		StaticPrices:        map[string]string{"USDC": "1"},
+	}, mockJSONRPC)
+	assert.NoError(t, err)
+	price, err := oracle.Price(context.Background(), &RosettaTypes.Currency{Symbol: "USDC", Decimals: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(1, 1), price)
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestReferenceAmount(t *testing.T) {
+	oracle, err := NewStaticPriceOracle(map[string]string{"ETH": "3125.42"})
+	assert.NoError(t, err)
+
+	// 21000 gas at 100 gwei is 0.0021 ETH, 6.563382 USD rounded down to cents
+	amount, err := ReferenceAmount(
+		context.Background(),
+		oracle,
+		&RosettaTypes.Amount{Value: "2100000000000000", Currency: ethCurrency},
+		usdCurrency,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.Amount{Value: "656", Currency: usdCurrency}, amount)
+
+	_, err = ReferenceAmount(context.Background(), oracle, &RosettaTypes.Amount{Value: "0x1", Currency: ethCurrency}, usdCurrency)
+	assert.EqualError(t, err, "0x1 is not a valid amount")
+}
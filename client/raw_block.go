@@ -0,0 +1,178 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RawBlock is the RLP encoding of a block and of its header, whose Keccak256
+// hash is the block hash
+type RawBlock struct {
+	Hash   common.Hash   `json:"hash"`
+	Header hexutil.Bytes `json:"header"`
+	Block  hexutil.Bytes `json:"block"`
+}
+
+// rawBlockBody is the part of a JSON RPC block with full transactions that
+// isn't in its header
+type rawBlockBody struct {
+	Hash         common.Hash             `json:"hash"`
+	Transactions []*EthTypes.Transaction `json:"transactions"`
+	UncleHashes  []common.Hash           `json:"uncles"`
+	Withdrawals  []*EthTypes.Withdrawal  `json:"withdrawals,omitempty"`
+}
+
+// blockNumberOrHash returns the JSON RPC block parameter of blockIdentifier,
+// which is the default block of RosettaConfig.DefaultBlock when neither the
+// hash nor the index is set
+func (ec *SDKClient) blockNumberOrHash(blockIdentifier *RosettaTypes.PartialBlockIdentifier) string {
+	if blockIdentifier != nil {
+		if blockIdentifier.Hash != nil {
+			return common.HexToHash(*blockIdentifier.Hash).Hex()
+		}
+		if blockIdentifier.Index != nil {
+			return ToBlockNumArg(big.NewInt(*blockIdentifier.Index))
+		}
+	}
+
+	return string(ec.rosettaConfig.DefaultBlock())
+}
+
+// GetRawBlock returns the RLP encoding of a block, so consumers can hash and
+// archive blocks independently. It uses debug_getRawHeader and debug_getRawBlock,
+// and reconstructs the encoding from the JSON RPC block when the node doesn't
+// support them.
+func (ec *SDKClient) GetRawBlock(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) (*RawBlock, error) {
+	arg := ec.blockNumberOrHash(blockIdentifier)
+
+	var header, block hexutil.Bytes
+	reqs := []rpc.BatchElem{
+		{Method: "debug_getRawHeader", Args: []interface{}{arg}, Result: &header},
+		{Method: "debug_getRawBlock", Args: []interface{}{arg}, Result: &block},
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if IsMethodNotFound(req.Error) {
+			return ec.reconstructRawBlock(ctx, arg)
+		}
+		if req.Error != nil {
+			return nil, fmt.Errorf("%s failed: %w", req.Method, req.Error)
+		}
+	}
+	if len(header) == 0 || len(block) == 0 {
+		return nil, goEthereum.NotFound
+	}
+
+	return &RawBlock{
+		Hash:   crypto.Keccak256Hash(header),
+		Header: header,
+		Block:  block,
+	}, nil
+}
+
+// reconstructRawBlock encodes the JSON RPC block at arg. The hash of the
+// encoded header must match the block hash returned by the node, which fails
+// for chains whose blocks aren't standard Ethereum blocks.
+func (ec *SDKClient) reconstructRawBlock(ctx context.Context, arg string) (*RawBlock, error) {
+	method := "eth_getBlockByNumber"
+	if len(arg) == len(common.Hash{}.Hex()) {
+		method = "eth_getBlockByHash"
+	}
+
+	var raw json.RawMessage
+	if err := ec.CallContext(ctx, &raw, method, arg, true); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, goEthereum.NotFound
+	}
+
+	var header EthTypes.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not decode header: %w", err)
+	}
+	var body rawBlockBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("could not decode block body: %w", err)
+	}
+
+	uncles := make([]*EthTypes.Header, len(body.UncleHashes))
+	if len(uncles) > 0 {
+		reqs := make([]rpc.BatchElem, len(uncles))
+		for i := range reqs {
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_getUncleByBlockHashAndIndex",
+				Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(i))},
+				Result: &uncles[i],
+			}
+		}
+		if err := ec.batchCall(ctx, reqs); err != nil {
+			return nil, err
+		}
+		for i := range reqs {
+			if reqs[i].Error != nil {
+				return nil, fmt.Errorf("could not get uncle %d: %w", i, reqs[i].Error)
+			}
+			if uncles[i] == nil {
+				return nil, fmt.Errorf("uncle %d of block %s not found", i, body.Hash)
+			}
+		}
+	}
+
+	encodedBlock := EthTypes.NewBlockWithHeader(&header).WithBody(body.Transactions, uncles)
+	if body.Withdrawals != nil {
+		encodedBlock = encodedBlock.WithWithdrawals(body.Withdrawals)
+	}
+	if encodedBlock.Hash() != body.Hash {
+		return nil, fmt.Errorf(
+			"reconstructed block hash %s does not match block hash %s",
+			encodedBlock.Hash(),
+			body.Hash,
+		)
+	}
+
+	headerBytes, err := rlp.EncodeToBytes(&header)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode header: %w", err)
+	}
+	blockBytes, err := rlp.EncodeToBytes(encodedBlock)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode block: %w", err)
+	}
+
+	return &RawBlock{
+		Hash:   body.Hash,
+		Header: headerBytes,
+		Block:  blockBytes,
+	}, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// rpcError is a JSON RPC error returned by the node
+type rpcError struct{ code int }
+
+func (e *rpcError) Error() string  { return "rpc error" }
+func (e *rpcError) ErrorCode() int { return e.code }
+
+// testBlock returns a block with a signed transaction and its JSON RPC encoding
+func testBlock(t *testing.T) (*EthTypes.Block, json.RawMessage) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	tx, err := EthTypes.SignTx(
+		EthTypes.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), nil),
+		EthTypes.HomesteadSigner{},
+		key,
+	)
+	assert.NoError(t, err)
+
+	header := &EthTypes.Header{
+		Number:     big.NewInt(10),
+		Difficulty: big.NewInt(1),
+		GasLimit:   30000000,
+		GasUsed:    21000,
+		Time:       1700000000,
+		Extra:      []byte{},
+	}
+	block := EthTypes.NewBlock(header, []*EthTypes.Transaction{tx}, nil, nil, trie.NewStackTrie(nil))
+
+	encoded, err := json.Marshal(block.Header())
+	assert.NoError(t, err)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	fields["transactions"] = block.Transactions()
+	fields["uncles"] = []common.Hash{}
+	raw, err := json.Marshal(fields)
+	assert.NoError(t, err)
+
+	return block, raw
+}
+
+func TestGetRawBlock(t *testing.T) {
+	block, raw := testBlock(t)
+	encodedHeader, err := rlp.EncodeToBytes(block.Header())
+	assert.NoError(t, err)
+	encodedBlock, err := rlp.EncodeToBytes(block)
+	assert.NoError(t, err)
+	expected := &RawBlock{
+		Hash:   block.Hash(),
+		Header: encodedHeader,
+		Block:  encodedBlock,
+	}
+	index := int64(10)
+
+	t.Run("debug methods", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+		mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+			func(args mock.Arguments) {
+				reqs := args.Get(1).([]rpc.BatchElem)
+				assert.Equal(t, "debug_getRawHeader", reqs[0].Method)
+				assert.Equal(t, "debug_getRawBlock", reqs[1].Method)
+				assert.Equal(t, []interface{}{"0xa"}, reqs[0].Args)
+				*reqs[0].Result.(*hexutil.Bytes) = encodedHeader
+				*reqs[1].Result.(*hexutil.Bytes) = encodedBlock
+			},
+		).Once()
+
+		rawBlock, err := sdkClient.GetRawBlock(context.Background(), &RosettaTypes.PartialBlockIdentifier{Index: &index})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rawBlock)
+		mockJSONRPC.AssertExpectations(t)
+	})
+
+	t.Run("reconstructed", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+		mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+			func(args mock.Arguments) {
+				reqs := args.Get(1).([]rpc.BatchElem)
+				for i := range reqs {
+					reqs[i].Error = &rpcError{code: methodNotFoundCode}
+				}
+			},
+		).Once()
+		hash := block.Hash().Hex()
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByHash", hash, true).Return(nil).Run(
+			func(args mock.Arguments) {
+				*args.Get(1).(*json.RawMessage) = raw
+			},
+		).Once()
+
+		rawBlock, err := sdkClient.GetRawBlock(context.Background(), &RosettaTypes.PartialBlockIdentifier{Hash: &hash})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rawBlock)
+		mockJSONRPC.AssertExpectations(t)
+	})
+
+	t.Run("reconstructed hash mismatch", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+		mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+			func(args mock.Arguments) {
+				args.Get(1).([]rpc.BatchElem)[1].Error = &rpcError{code: methodNotFoundCode}
+			},
+		).Once()
+		var fields map[string]interface{}
+		assert.NoError(t, json.Unmarshal(raw, &fields))
+		fields["hash"] = common.Hash{}.Hex()
+		altered, err := json.Marshal(fields)
+		assert.NoError(t, err)
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", "latest", true).Return(nil).Run(
+			func(args mock.Arguments) {
+				*args.Get(1).(*json.RawMessage) = altered
+			},
+		).Once()
+
+		_, err = sdkClient.GetRawBlock(context.Background(), nil)
+		assert.EqualError(
+			t,
+			err,
+			"reconstructed block hash "+block.Hash().Hex()+" does not match block hash "+common.Hash{}.Hex(),
+		)
+		mockJSONRPC.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMethodNotFound(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"method not found": {
+			err:      &rpcError{code: methodNotFoundCode},
+			expected: true,
+		},
+		"wrapped method not found": {
+			err:      fmt.Errorf("debug_getRawBlock failed: %w", &rpcError{code: methodNotFoundCode}),
+			expected: true,
+		},
+		"other json rpc error": {
+			err: &rpcError{code: -32000},
+		},
+		"other error": {
+			err: errors.New("connection refused"),
+		},
+		"no error": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsMethodNotFound(test.err))
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxSlowCallParamsLen is the length of the JSON parameters summary of a slow call
+const maxSlowCallParamsLen = 256
+
+// blockTags are the block parameters of JSON RPC methods that are not numbers
+var blockTags = map[string]bool{
+	"latest":    true,
+	"pending":   true,
+	"safe":      true,
+	"finalized": true,
+	"earliest":  true,
+}
+
+// SlowCall is a JSON RPC call or batch to the node that exceeded its slow call
+// threshold, see RosettaConfig.SlowCallThreshold
+type SlowCall struct {
+	// Method is the JSON RPC method, or the comma separated methods of a batch
+	Method string `json:"method"`
+
+	// Params is the truncated JSON of the parameters, or the number of
+	// requests of a batch
+	Params string `json:"params"`
+
+	// BlockNumber is the block number or tag parameter of the call, if any
+	BlockNumber string `json:"block_number,omitempty"`
+
+	Start    time.Time `json:"start"`
+	Duration string    `json:"duration"`
+
+	elapsed time.Duration
+}
+
+// SlowCallReporter is an optional interface a client can implement to report
+// the slowest JSON RPC calls to the node, for the get_slow_calls /call method
+type SlowCallReporter interface {
+	SlowCalls() []*SlowCall
+}
+
+// slowCallLog logs the calls exceeding their threshold and keeps the slowest
+// ones, slowest first
+type slowCallLog struct {
+	threshold  time.Duration
+	thresholds map[string]time.Duration
+	limit      int
+
+	mu      sync.Mutex
+	slowest []*SlowCall
+}
+
+// newSlowCallLog returns the slow call log of cfg, or nil if slow call
+// logging is disabled
+func newSlowCallLog(cfg configuration.RosettaConfig) *slowCallLog {
+	if !cfg.SlowCallLoggingEnabled() {
+		return nil
+	}
+
+	return &slowCallLog{
+		threshold:  cfg.SlowCallThreshold,
+		thresholds: cfg.SlowCallThresholds,
+		limit:      cfg.SlowCallReportLimit(),
+	}
+}
+
+// methodThreshold returns the slow call threshold of method, zero if its
+// calls are never slow
+func (l *slowCallLog) methodThreshold(method string) time.Duration {
+	if threshold, ok := l.thresholds[method]; ok {
+		return threshold
+	}
+
+	return l.threshold
+}
+
+// observeCall records a call of method with args that started at start
+func (l *slowCallLog) observeCall(method string, args []interface{}, start time.Time) {
+	if l == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	threshold := l.methodThreshold(method)
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+
+	l.add(&SlowCall{
+		Method:      method,
+		Params:      summarizeParams(args),
+		BlockNumber: blockNumberParam(args),
+		Start:       start,
+		Duration:    elapsed.String(),
+		elapsed:     elapsed,
+	})
+}
+
+// observeBatch records a batch that started at start. A batch is slow when it
+// exceeds the lowest threshold of its methods.
+func (l *slowCallLog) observeBatch(batch []rpc.BatchElem, start time.Time) {
+	if l == nil || len(batch) == 0 {
+		return
+	}
+	elapsed := time.Since(start)
+
+	var threshold time.Duration
+	var methods []string
+	seen := map[string]bool{}
+	blockNumber := ""
+	for _, elem := range batch {
+		if methodThreshold := l.methodThreshold(elem.Method); methodThreshold > 0 &&
+			(threshold == 0 || methodThreshold < threshold) {
+			threshold = methodThreshold
+		}
+		if !seen[elem.Method] {
+			seen[elem.Method] = true
+			methods = append(methods, elem.Method)
+		}
+		if len(blockNumber) == 0 {
+			blockNumber = blockNumberParam(elem.Args)
+		}
+	}
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+
+	l.add(&SlowCall{
+		Method:      strings.Join(methods, ","),
+		Params:      fmt.Sprintf("batch of %d requests", len(batch)),
+		BlockNumber: blockNumber,
+		Start:       start,
+		Duration:    elapsed.String(),
+		elapsed:     elapsed,
+	})
+}
+
+// add logs call and keeps it if it is one of the slowest calls
+func (l *slowCallLog) add(call *SlowCall) {
+	log.Printf(
+		"slow call %s took %s, params %s, block %s",
+		call.Method,
+		call.Duration,
+		call.Params,
+		call.BlockNumber,
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := sort.Search(len(l.slowest), func(i int) bool {
+		return l.slowest[i].elapsed < call.elapsed
+	})
+	if i >= l.limit {
+		return
+	}
+	l.slowest = append(l.slowest, nil)
+	copy(l.slowest[i+1:], l.slowest[i:])
+	l.slowest[i] = call
+	if len(l.slowest) > l.limit {
+		l.slowest = l.slowest[:l.limit]
+	}
+}
+
+// report returns the slowest calls, slowest first
+func (l *slowCallLog) report() []*SlowCall {
+	if l == nil {
+		return []*SlowCall{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]*SlowCall{}, l.slowest...)
+}
+
+// SlowCalls returns the slowest JSON RPC calls and batches to the node, slowest
+// first. It is empty when slow call logging is disabled.
+func (ec *SDKClient) SlowCalls() []*SlowCall {
+	return ec.slowCalls.report()
+}
+
+// summarizeParams returns the JSON of args, truncated to maxSlowCallParamsLen
+func summarizeParams(args []interface{}) string {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return "unknown"
+	}
+	if len(raw) > maxSlowCallParamsLen {
+		return string(raw[:maxSlowCallParamsLen]) + "..."
+	}
+
+	return string(raw)
+}
+
+// blockNumberParam returns the first block number or tag of args, or an empty
+// string if there is none. Hashes are not block numbers.
+func blockNumberParam(args []interface{}) string {
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			if blockTags[v] {
+				return v
+			}
+			if _, err := hexutil.DecodeUint64(v); err == nil {
+				return v
+			}
+		case *big.Int:
+			if v != nil {
+				return hexutil.EncodeBig(v)
+			}
+		case rpc.BlockNumber:
+			return v.String()
+		case hexutil.Uint64:
+			return v.String()
+		}
+	}
+
+	return ""
+}
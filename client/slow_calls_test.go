@@ -0,0 +1,108 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSlowCallLog(t *testing.T) {
+	assert.Nil(t, newSlowCallLog(configuration.RosettaConfig{}))
+	assert.Nil(t, newSlowCallLog(configuration.RosettaConfig{
+		SlowCallThresholds: map[string]time.Duration{"eth_call": 0},
+	}))
+
+	l := newSlowCallLog(configuration.RosettaConfig{
+		SlowCallThreshold: time.Second,
+		SlowCallThresholds: map[string]time.Duration{
+			"debug_traceBlockByHash": time.Minute,
+			"eth_chainId":            0,
+		},
+		SlowCallReportSize: 2,
+	})
+	now := time.Now()
+
+	// Calls below their threshold are not slow
+	l.observeCall("eth_getBlockByNumber", []interface{}{"0x10", true}, now.Add(-time.Millisecond))
+	l.observeCall("debug_traceBlockByHash", []interface{}{"0x01"}, now.Add(-time.Second*2))
+	l.observeCall("eth_chainId", nil, now.Add(-time.Hour))
+	assert.Empty(t, l.report())
+
+	l.observeCall("eth_getBlockByNumber", []interface{}{"0x10", true}, now.Add(-time.Second*2))
+	l.observeCall("eth_getBalance", []interface{}{"0x01", "latest"}, now.Add(-time.Second*3))
+	l.observeBatch([]rpc.BatchElem{
+		{Method: "eth_getTransactionReceipt", Args: []interface{}{"0x01"}},
+		{Method: "eth_getTransactionReceipt", Args: []interface{}{"0x02"}},
+		{Method: "debug_traceBlockByHash", Args: []interface{}{"0x03"}},
+	}, now.Add(-time.Second*4))
+
+	report := l.report()
+	assert.Len(t, report, 2)
+	assert.Equal(t, "eth_getTransactionReceipt,debug_traceBlockByHash", report[0].Method)
+	assert.Equal(t, "batch of 3 requests", report[0].Params)
+	assert.Equal(t, "", report[0].BlockNumber)
+	assert.Equal(t, "eth_getBalance", report[1].Method)
+	assert.Equal(t, `["0x01","latest"]`, report[1].Params)
+	assert.Equal(t, "latest", report[1].BlockNumber)
+	assert.Equal(t, now.Add(-time.Second*3), report[1].Start)
+
+	// Faster calls than the slowest calls are dropped
+	l.observeCall("eth_getBlockByNumber", []interface{}{"0x11", true}, now.Add(-time.Second*2))
+	assert.Equal(t, report, l.report())
+}
+
+func TestBlockNumberParam(t *testing.T) {
+	hash := "0x9b3b0ad5a3f9ebf74e4a9b6bd8e3f3a1a1e6b4f2a8c7d6e5f4a3b2c1d0e9f8a7"
+	assert.Equal(t, "0x10", blockNumberParam([]interface{}{"0x10", true}))
+	assert.Equal(t, "finalized", blockNumberParam([]interface{}{"finalized", false}))
+	assert.Equal(t, "0x10", blockNumberParam([]interface{}{big.NewInt(16)}))
+	assert.Equal(t, "latest", blockNumberParam([]interface{}{map[string]interface{}{"to": "0x01"}, rpc.LatestBlockNumber}))
+	assert.Equal(t, "", blockNumberParam([]interface{}{hash, map[string]interface{}{"tracer": "callTracer"}}))
+}
+
+func TestCallContext_SlowCalls(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+		slowCalls: newSlowCallLog(configuration.RosettaConfig{SlowCallThreshold: time.Millisecond}),
+	}
+	ctx := context.Background()
+
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "0x10", false).
+		Return(nil).
+		Run(func(mock.Arguments) { time.Sleep(time.Millisecond * 5) }).
+		Once()
+	var result interface{}
+	assert.NoError(t, sdkClient.CallContext(ctx, &result, "eth_getBlockByNumber", "0x10", false))
+
+	slowCalls := sdkClient.SlowCalls()
+	assert.Len(t, slowCalls, 1)
+	assert.Equal(t, "eth_getBlockByNumber", slowCalls[0].Method)
+	assert.Equal(t, "0x10", slowCalls[0].BlockNumber)
+	mockJSONRPC.AssertExpectations(t)
+
+	// Slow calls are not logged when slow call logging is disabled
+	assert.Empty(t, (&SDKClient{}).SlowCalls())
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
+)
+
+// Sync stages reported in the sync status of /network/status
+const (
+	// SyncedStage is the stage of a synced node
+	SyncedStage = "SYNCED"
+
+	// BlockSyncStage is the stage of a node downloading blocks
+	BlockSyncStage = "BLOCK_SYNC"
+
+	// StateHealingStage is the stage of a node which downloaded the blocks
+	// and heals its state
+	StateHealingStage = "STATE_HEALING"
+
+	// StaleStage is the stage of a node claiming to be synced whose current
+	// block is older than RosettaConfig.SyncStalenessThreshold
+	StaleStage = "STALE"
+)
+
+// nodeSyncStatus returns the sync status of a node from its sync progress,
+// nil when it isn't syncing, and its current block. The stage of syncing nodes
+// includes their progress in percent, e.g. "BLOCK_SYNC 42.50%". When
+// staleness is positive, nodes that aren't syncing are synced unless their
+// current block is older than staleness at now, in which case they are stale,
+// e.g. "STALE 5m0s behind". Otherwise nodes that aren't syncing have no sync
+// status.
+func nodeSyncStatus(
+	progress *goEthereum.SyncProgress,
+	currentIndex int64,
+	currentTime time.Time,
+	now time.Time,
+	staleness time.Duration,
+) *RosettaTypes.SyncStatus {
+	if progress != nil {
+		current := int64(progress.CurrentBlock)
+		target := int64(progress.HighestBlock)
+		stage := BlockSyncStage
+		if progress.CurrentBlock >= progress.HighestBlock &&
+			(progress.HealingTrienodes > 0 || progress.HealingBytecode > 0) {
+			stage = StateHealingStage
+		}
+
+		return &RosettaTypes.SyncStatus{
+			CurrentIndex: &current,
+			TargetIndex:  &target,
+			Stage:        RosettaTypes.String(fmt.Sprintf("%s %.2f%%", stage, syncPercentage(progress))),
+			Synced:       RosettaTypes.Bool(false),
+		}
+	}
+
+	if staleness <= 0 {
+		return nil
+	}
+
+	if lag := now.Sub(currentTime); lag > staleness {
+		return &RosettaTypes.SyncStatus{
+			CurrentIndex: &currentIndex,
+			Stage:        RosettaTypes.String(fmt.Sprintf("%s %s behind", StaleStage, lag.Truncate(time.Second))),
+			Synced:       RosettaTypes.Bool(false),
+		}
+	}
+
+	return &RosettaTypes.SyncStatus{
+		CurrentIndex: &currentIndex,
+		Stage:        RosettaTypes.String(SyncedStage),
+		Synced:       RosettaTypes.Bool(true),
+	}
+}
+
+// syncPercentage returns the percentage of the blocks downloaded since the
+// start of the sync
+func syncPercentage(progress *goEthereum.SyncProgress) float64 {
+	if progress.HighestBlock <= progress.StartingBlock || progress.CurrentBlock >= progress.HighestBlock {
+		return 100 // nolint:gomnd
+	}
+	if progress.CurrentBlock <= progress.StartingBlock {
+		return 0
+	}
+
+	done := float64(progress.CurrentBlock - progress.StartingBlock)
+	total := float64(progress.HighestBlock - progress.StartingBlock)
+	return 100 * done / total // nolint:gomnd
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeSyncStatus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	index := int64(100)
+	current, target := int64(150), int64(200)
+
+	tests := map[string]struct {
+		progress    *goEthereum.SyncProgress
+		currentTime time.Time
+		staleness   time.Duration
+
+		expected *RosettaTypes.SyncStatus
+	}{
+		"block sync": {
+			progress:    &goEthereum.SyncProgress{StartingBlock: 100, CurrentBlock: 150, HighestBlock: 200},
+			currentTime: now,
+			staleness:   time.Minute,
+			expected: &RosettaTypes.SyncStatus{
+				CurrentIndex: &current,
+				TargetIndex:  &target,
+				Stage:        RosettaTypes.String("BLOCK_SYNC 50.00%"),
+				Synced:       RosettaTypes.Bool(false),
+			},
+		},
+		"state healing": {
+			progress:    &goEthereum.SyncProgress{StartingBlock: 100, CurrentBlock: 200, HighestBlock: 200, HealingTrienodes: 7},
+			currentTime: now,
+			expected: &RosettaTypes.SyncStatus{
+				CurrentIndex: &target,
+				TargetIndex:  &target,
+				Stage:        RosettaTypes.String("STATE_HEALING 100.00%"),
+				Synced:       RosettaTypes.Bool(false),
+			},
+		},
+		"synced without staleness check": {
+			currentTime: now.Add(-time.Hour),
+		},
+		"synced": {
+			currentTime: now.Add(-30 * time.Second),
+			staleness:   time.Minute,
+			expected: &RosettaTypes.SyncStatus{
+				CurrentIndex: &index,
+				Stage:        RosettaTypes.String(SyncedStage),
+				Synced:       RosettaTypes.Bool(true),
+			},
+		},
+		"stale": {
+			currentTime: now.Add(-5*time.Minute - 300*time.Millisecond),
+			staleness:   time.Minute,
+			expected: &RosettaTypes.SyncStatus{
+				CurrentIndex: &index,
+				Stage:        RosettaTypes.String("STALE 5m0s behind"),
+				Synced:       RosettaTypes.Bool(false),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, nodeSyncStatus(test.progress, index, test.currentTime, now, test.staleness))
+		})
+	}
+}
+
+func TestSyncPercentage(t *testing.T) {
+	assert.Equal(t, 25.0, syncPercentage(&goEthereum.SyncProgress{StartingBlock: 0, CurrentBlock: 25, HighestBlock: 100}))
+	assert.Equal(t, 0.0, syncPercentage(&goEthereum.SyncProgress{StartingBlock: 50, CurrentBlock: 10, HighestBlock: 100}))
+	assert.Equal(t, 100.0, syncPercentage(&goEthereum.SyncProgress{StartingBlock: 100, CurrentBlock: 100, HighestBlock: 100}))
+}
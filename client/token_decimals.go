@@ -0,0 +1,102 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenDecimalsVerifier is an optional interface a client can implement to
+// verify the decimals of the token white list against the token contracts at
+// startup, according to RosettaConfig.TokenDecimalsPolicy.
+type TokenDecimalsVerifier interface {
+	VerifyTokenDecimals(ctx context.Context) error
+}
+
+// CheckTokenDecimals applies policy to a token white list entry whose contract
+// returns onChain from decimals(). It returns the decimals to use for the token,
+// or an error if they don't match and policy is FailTokenDecimalsPolicy.
+func CheckTokenDecimals(policy string, token configuration.Token, onChain uint64) (uint64, error) {
+	if len(policy) == 0 || token.Decimals == onChain {
+		return token.Decimals, nil
+	}
+
+	if policy == configuration.FailTokenDecimalsPolicy {
+		return 0, fmt.Errorf(
+			"token %s %s has %d decimals in the white list but %d decimals on chain",
+			token.Symbol,
+			token.Address,
+			token.Decimals,
+			onChain,
+		)
+	}
+
+	log.Printf(
+		"correcting decimals of token %s %s from %d to %d",
+		token.Symbol,
+		token.Address,
+		token.Decimals,
+		onChain,
+	)
+	return onChain, nil
+}
+
+// VerifyTokenDecimals checks the decimals of every token white list entry
+// against decimals() of its contract. Tokens whose decimals() call fails are
+// skipped, since GetContractCurrency doesn't use their contracts either. With
+// CorrectTokenDecimalsPolicy, mismatching entries are corrected in place.
+func (ec *SDKClient) VerifyTokenDecimals(ctx context.Context) error {
+	policy := ec.rosettaConfig.TokenDecimalsPolicy
+	if len(policy) == 0 {
+		return nil
+	}
+
+	// Copy the white list so the configuration the client was created with is
+	// only changed through GetRosettaConfig
+	whiteList := make([]configuration.Token, len(ec.rosettaConfig.TokenWhiteList))
+	copy(whiteList, ec.rosettaConfig.TokenWhiteList)
+
+	var problems []error
+	for i, token := range whiteList {
+		onChain, err := ec.tokenDecimals(ctx, common.HexToAddress(token.Address))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Printf("could not verify decimals of token %s %s: %v", token.Symbol, token.Address, err)
+			continue
+		}
+
+		decimals, err := CheckTokenDecimals(policy, token, uint64(onChain))
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		whiteList[i].Decimals = decimals
+	}
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+
+	ec.rosettaConfig.TokenWhiteList = whiteList
+	return nil
+}
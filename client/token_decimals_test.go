@@ -0,0 +1,104 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVerifyTokenDecimals(t *testing.T) {
+	mkr := "0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2"
+	usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	whiteList := []configuration.Token{
+		{Address: mkr, Symbol: "MKR", Decimals: 6},
+		{Address: usdc, Symbol: "USDC", Decimals: 6},
+	}
+
+	tests := map[string]struct {
+		policy            string
+		expectedWhiteList []configuration.Token
+		expectedError     string
+	}{
+		"no policy": {
+			expectedWhiteList: whiteList,
+		},
+		"fail": {
+			policy:        configuration.FailTokenDecimalsPolicy,
+			expectedError: "token MKR " + mkr + " has 6 decimals in the white list but 18 decimals on chain",
+		},
+		"correct": {
+			policy: configuration.CorrectTokenDecimalsPolicy,
+			expectedWhiteList: []configuration.Token{
+				{Address: mkr, Symbol: "MKR", Decimals: 18},
+				{Address: usdc, Symbol: "USDC", Decimals: 6},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: configuration.RosettaConfig{
+					TokenWhiteList:      whiteList,
+					TokenDecimalsPolicy: test.policy,
+				},
+			}
+			if len(test.policy) > 0 {
+				mockJSONRPC.On(
+					"CallContext",
+					context.Background(),
+					mock.Anything,
+					"eth_call",
+					map[string]string{"to": common.HexToAddress(mkr).String(), "data": DecimalsMethodID},
+					"latest",
+				).Return(nil).Run(func(args mock.Arguments) {
+					*args.Get(1).(*string) = eighteenDecimalsOutput
+				}).Once()
+				// The decimals of tokens without decimals() can't be verified
+				mockJSONRPC.On(
+					"CallContext",
+					context.Background(),
+					mock.Anything,
+					"eth_call",
+					map[string]string{"to": common.HexToAddress(usdc).String(), "data": DecimalsMethodID},
+					"latest",
+				).Return(errors.New("execution reverted")).Once()
+			}
+
+			err := sdkClient.VerifyTokenDecimals(context.Background())
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, whiteList, sdkClient.GetRosettaConfig().TokenWhiteList)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedWhiteList, sdkClient.GetRosettaConfig().TokenWhiteList)
+			}
+			// The configured white list is left unchanged
+			assert.Equal(t, uint64(6), whiteList[0].Decimals)
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
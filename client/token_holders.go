@@ -0,0 +1,224 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultLogsPageSize is the number of blocks queried by a single
+// eth_getLogs request when streaming logs
+const DefaultLogsPageSize = 2000
+
+// topicsInErc20Transfer is the number of topics of an ERC20 Transfer log
+const topicsInErc20Transfer = 3
+
+// limitExceededCode is the EIP-1474 JSON RPC error code of requests exceeding
+// a limit of the node
+const limitExceededCode = -32005
+
+// logsLimitErrors are the error messages of node providers rejecting
+// eth_getLogs requests for their result count or block range, in lowercase
+var logsLimitErrors = []string{
+	"query returned more than",
+	"too many results",
+	"response size exceeded",
+	"response size is larger than",
+	"block range is too",
+	"range is too large",
+	"range too large",
+	"exceed maximum block range",
+	"limited to a",
+}
+
+// TokenHolder is the balance of an account in an ERC20 token
+type TokenHolder struct {
+	Account *RosettaTypes.AccountIdentifier `json:"account_identifier"`
+	Amount  *RosettaTypes.Amount            `json:"amount"`
+}
+
+// StreamTransferLogs calls fn with the ERC20 Transfer logs of contract between
+// the start and end blocks (inclusive), in order. Logs are fetched with
+// eth_getLogs in pages of pageSize blocks, and pages rejected by the node for
+// returning too many logs or spanning too many blocks are split in half and
+// retried. Other errors are returned.
+func (ec *SDKClient) StreamTransferLogs(
+	ctx context.Context,
+	contract common.Address,
+	start uint64,
+	end uint64,
+	pageSize uint64,
+	fn func(*EthTypes.Log) error,
+) error {
+	if pageSize == 0 {
+		pageSize = DefaultLogsPageSize
+	}
+
+	for from := start; from <= end; {
+		to := from + pageSize - 1
+		if to > end || to < from {
+			to = end
+		}
+
+		logs, err := ec.transferLogs(ctx, contract, from, to)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return err
+			}
+		}
+
+		if to == end {
+			break
+		}
+		from = to + 1
+	}
+
+	return nil
+}
+
+// transferLogs returns the ERC20 Transfer logs of contract between the from and
+// to blocks, splitting the range in half while the node rejects it for its
+// size, see isLogsLimitError
+func (ec *SDKClient) transferLogs(
+	ctx context.Context,
+	contract common.Address,
+	from uint64,
+	to uint64,
+) ([]*EthTypes.Log, error) {
+	filter := map[string]interface{}{
+		"address":   contract,
+		"fromBlock": hexutil.EncodeUint64(from),
+		"toBlock":   hexutil.EncodeUint64(to),
+		"topics":    [][]common.Hash{{common.HexToHash(Erc20LogTopicMap[Erc20TransferLogTopic])}},
+	}
+	var logs []*EthTypes.Log
+	err := ec.CallContext(ctx, &logs, "eth_getLogs", filter)
+	if err == nil {
+		return logs, nil
+	}
+	if from == to || ctx.Err() != nil || !isLogsLimitError(err) {
+		return nil, fmt.Errorf("could not get logs of %s from block %d to %d: %w", contract, from, to, err)
+	}
+
+	mid := from + (to-from)/2 // nolint:gomnd
+	first, err := ec.transferLogs(ctx, contract, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	second, err := ec.transferLogs(ctx, contract, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(first, second...), nil
+}
+
+// isLogsLimitError returns true if err is the rejection of an eth_getLogs
+// request for its result count or block range, which a smaller range avoids
+func isLogsLimitError(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, limitError := range logsLimitErrors {
+		if strings.Contains(message, limitError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TokenHolders aggregates the ERC20 Transfer logs of contract between the start
+// and end blocks (inclusive) into the balance change of every account, sorted by
+// address. Accounts without a balance change are omitted. When start is the block
+// the token was deployed at, the changes are the balances of the token holders at
+// the end block, which can be used to bootstrap ERC20 reconciliation.
+func (ec *SDKClient) TokenHolders(
+	ctx context.Context,
+	contract common.Address,
+	currency *ContractCurrency,
+	start uint64,
+	end uint64,
+	pageSize uint64,
+) ([]*TokenHolder, error) {
+	balances := map[common.Address]*big.Int{}
+	addBalance := func(addr common.Address, value *big.Int) {
+		if addr == (common.Address{}) {
+			// Mints and burns
+			return
+		}
+		if _, ok := balances[addr]; !ok {
+			balances[addr] = new(big.Int)
+		}
+		balances[addr].Add(balances[addr], value)
+	}
+
+	err := ec.StreamTransferLogs(ctx, contract, start, end, pageSize, func(log *EthTypes.Log) error {
+		// ERC721 Transfer events have the same signature with an indexed token id
+		if log.Removed || len(log.Topics) != topicsInErc20Transfer {
+			return nil
+		}
+
+		value := new(big.Int).SetBytes(log.Data)
+		addBalance(common.BytesToAddress(log.Topics[1].Bytes()), new(big.Int).Neg(value))
+		addBalance(common.BytesToAddress(log.Topics[2].Bytes()), value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]common.Address, 0, len(balances))
+	for addr, balance := range balances {
+		if balance.Sign() != 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+
+	holders := make([]*TokenHolder, len(addrs))
+	for i, addr := range addrs {
+		addr := addr
+		holders[i] = &TokenHolder{
+			Account: Account(&addr),
+			Amount: &RosettaTypes.Amount{
+				Value:    balances[addr].String(),
+				Currency: Erc20Currency(currency.Symbol, currency.Decimals, contract.String()),
+			},
+		}
+	}
+
+	return holders, nil
+}
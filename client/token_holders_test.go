@@ -0,0 +1,168 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func transferLog(from, to common.Address, value int64) *EthTypes.Log {
+	return &EthTypes.Log{
+		Topics: []common.Hash{
+			common.HexToHash(Erc20LogTopicMap[Erc20TransferLogTopic]),
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+	}
+}
+
+func TestTokenHolders(t *testing.T) {
+	ctx := context.Background()
+	contract := common.HexToAddress("0x1E77ad77925Ac0075CF61Fb76bA35D884985019d")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	carol := common.HexToAddress("0x0000000000000000000000000000000000000ca1")
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+	}
+
+	pages := map[[2]string][]*EthTypes.Log{
+		// Mint to alice, then alice pays bob
+		{"0x1", "0x2"}: {
+			transferLog(common.Address{}, alice, 100),
+			transferLog(alice, bob, 40),
+		},
+		// The 0x3-0x4 page is rejected and split
+		{"0x3", "0x3"}: {transferLog(bob, carol, 40)},
+		{"0x4", "0x4"}: {
+			// ERC721 transfers are ignored
+			{Topics: append(transferLog(alice, bob, 1).Topics, common.Hash{})},
+		},
+		{"0x5", "0x5"}: {transferLog(alice, common.Address{}, 10)},
+	}
+	var requested [][2]string
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getLogs",
+		mock.Anything,
+	).Return(
+		func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			filter := args[0].(map[string]interface{})
+			assert.Equal(t, contract, filter["address"])
+			page := [2]string{filter["fromBlock"].(string), filter["toBlock"].(string)}
+			requested = append(requested, page)
+
+			logs, ok := pages[page]
+			if !ok {
+				return errors.New("query returned more than 10000 results")
+			}
+			*(result.(*[]*EthTypes.Log)) = logs
+			return nil
+		},
+	)
+
+	holders, err := sdkClient.TokenHolders(
+		ctx,
+		contract,
+		&ContractCurrency{Symbol: "USDC", Decimals: 6},
+		1,
+		5,
+		2,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]string{
+		{"0x1", "0x2"},
+		{"0x3", "0x4"},
+		{"0x3", "0x3"},
+		{"0x4", "0x4"},
+		{"0x5", "0x5"},
+	}, requested)
+
+	assert.Len(t, holders, 2)
+	assert.Equal(t, alice.Hex(), holders[0].Account.Address)
+	assert.Equal(t, "50", holders[0].Amount.Value)
+	assert.Equal(t, carol.Hex(), holders[1].Account.Address)
+	assert.Equal(t, "40", holders[1].Amount.Value)
+	assert.Equal(t, Erc20Currency("USDC", 6, contract.String()), holders[1].Amount.Currency)
+}
+
+func TestStreamTransferLogs_NodeError(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	sdkClient := &SDKClient{
+		RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+	}
+
+	// Errors other than the limits of the node are not retried on smaller ranges
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_getLogs", mock.Anything).
+		Return(errors.New("connection refused")).Once()
+
+	err := sdkClient.StreamTransferLogs(ctx, common.HexToAddress("0x1"), 1, 10, 10, func(*EthTypes.Log) error {
+		return nil
+	})
+	assert.EqualError(t, err, "could not get logs of 0x0000000000000000000000000000000000000001 from block 1 to 10: connection refused")
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestIsLogsLimitError(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"limit exceeded code": {
+			err:      &rpcError{code: limitExceededCode},
+			expected: true,
+		},
+		"geth result limit": {
+			err:      errors.New("query returned more than 10000 results"),
+			expected: true,
+		},
+		"provider block range": {
+			err:      errors.New("Block range is too large"),
+			expected: true,
+		},
+		"provider response size": {
+			err:      errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"),
+			expected: true,
+		},
+		"other error": {
+			err: errors.New("connection refused"),
+		},
+		"other json rpc error": {
+			err: &rpcError{code: -32000},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isLogsLimitError(test.err))
+		})
+	}
+}
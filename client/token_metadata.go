@@ -0,0 +1,175 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Method ids of the optional ERC20 metadata methods
+const (
+	SymbolMethodID   = "0x95d89b41"
+	DecimalsMethodID = "0x313ce567"
+)
+
+// ERC-165 supportsInterface(bytes4) method id and the interface ids it is
+// called with
+const (
+	SupportsInterfaceMethodID = "0x01ffc9a7"
+	ERC721InterfaceID         = "0x80ac58cd"
+	invalidInterfaceID        = "0xffffffff"
+)
+
+// TokenStandard is the token standard a contract implements
+type TokenStandard string
+
+const (
+	UnknownTokenStandard TokenStandard = ""
+	ERC20TokenStandard   TokenStandard = "ERC20"
+	ERC721TokenStandard  TokenStandard = "ERC721"
+)
+
+var stringArguments = abi.Arguments{{Type: mustNewType("string")}}
+
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return typ
+}
+
+// DecodeTokenString decodes the output of an ERC20 name() or symbol() call.
+// Besides the standard string, it supports tokens like MKR and SAI that
+// return a bytes32 padded with zeros.
+func DecodeTokenString(output []byte) (string, error) {
+	var s string
+	if len(output) == requiredPaddingBytes {
+		s = string(bytes.TrimRight(output, "\x00"))
+	} else {
+		unpacked, err := stringArguments.Unpack(output)
+		if err != nil {
+			return "", fmt.Errorf("invalid string output %x: %w", output, err)
+		}
+		s = unpacked[0].(string)
+	}
+
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("invalid utf8 string output %x", output)
+	}
+
+	return s, nil
+}
+
+// DecodeTokenDecimals decodes the output of an ERC20 decimals() call
+func DecodeTokenDecimals(output []byte) (uint8, error) {
+	if len(output) != requiredPaddingBytes {
+		return 0, fmt.Errorf("invalid decimals output %x", output)
+	}
+
+	decimals := new(big.Int).SetBytes(output)
+	if !decimals.IsUint64() || decimals.Uint64() > math.MaxUint8 {
+		return 0, fmt.Errorf("decimals %s out of range", decimals)
+	}
+
+	return uint8(decimals.Uint64()), nil
+}
+
+// callToken calls a method without arguments on a token contract
+func (ec *SDKClient) callToken(
+	ctx context.Context,
+	addr common.Address,
+	methodID string,
+) ([]byte, error) {
+	callParams := map[string]string{
+		"to":   addr.String(),
+		"data": methodID,
+	}
+	var resp string
+	if err := ec.CallContext(ctx, &resp, "eth_call", callParams, "latest"); err != nil {
+		return nil, err
+	}
+
+	return hexutil.Decode(resp)
+}
+
+// tokenSymbol returns the symbol of a token contract
+func (ec *SDKClient) tokenSymbol(ctx context.Context, addr common.Address) (string, error) {
+	output, err := ec.callToken(ctx, addr, SymbolMethodID)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeTokenString(output)
+}
+
+// tokenDecimals returns the decimals of a token contract
+func (ec *SDKClient) tokenDecimals(ctx context.Context, addr common.Address) (uint8, error) {
+	output, err := ec.callToken(ctx, addr, DecimalsMethodID)
+	if err != nil {
+		return 0, err
+	}
+
+	return DecodeTokenDecimals(output)
+}
+
+// supportsInterfaceData returns the call data of supportsInterface(interfaceID)
+func supportsInterfaceData(interfaceID string) string {
+	argument := common.RightPadBytes(common.FromHex(interfaceID), requiredPaddingBytes)
+	return SupportsInterfaceMethodID + common.Bytes2Hex(argument)
+}
+
+// supportsInterface returns whether addr implements interfaceID according to
+// ERC-165. Contracts without supportsInterface don't implement any interface.
+func (ec *SDKClient) supportsInterface(ctx context.Context, addr common.Address, interfaceID string) bool {
+	output, err := ec.callToken(ctx, addr, supportsInterfaceData(interfaceID))
+	if err != nil || len(output) != requiredPaddingBytes {
+		return false
+	}
+
+	return new(big.Int).SetBytes(output).Cmp(big.NewInt(1)) == 0
+}
+
+// detectTokenStandard detects the standard of a token contract. Contracts that
+// declare ERC-721 through ERC-165 are ERC721 tokens, as long as they don't claim
+// the invalid interface id like contracts whose fallback returns true for any
+// call. Otherwise contracts with decimals() are ERC20 tokens. ERC721 Transfer
+// events have an indexed token id, so the topic count of their logs already
+// tells them apart from ERC20 transfers.
+func (ec *SDKClient) detectTokenStandard(
+	ctx context.Context,
+	addr common.Address,
+	hasDecimals bool,
+) TokenStandard {
+	if ec.supportsInterface(ctx, addr, ERC721InterfaceID) &&
+		!ec.supportsInterface(ctx, addr, invalidInterfaceID) {
+		return ERC721TokenStandard
+	}
+	if hasDecimals {
+		return ERC20TokenStandard
+	}
+
+	return UnknownTokenStandard
+}
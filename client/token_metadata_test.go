@@ -0,0 +1,209 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	// symbol() of MKR, a bytes32
+	mkrSymbolOutput = "0x4d4b520000000000000000000000000000000000000000000000000000000000"
+	// symbol() of USDC, a string
+	usdcSymbolOutput = "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"5553444300000000000000000000000000000000000000000000000000000000"
+	zeroDecimalsOutput     = "0x0000000000000000000000000000000000000000000000000000000000000000"
+	falseOutput            = zeroDecimalsOutput
+	trueOutput             = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	eighteenDecimalsOutput = "0x0000000000000000000000000000000000000000000000000000000000000012"
+)
+
+func TestDecodeTokenString(t *testing.T) {
+	tests := map[string]struct {
+		output   string
+		expected string
+		err      bool
+	}{
+		"string": {
+			output:   usdcSymbolOutput,
+			expected: "USDC",
+		},
+		"bytes32": {
+			output:   mkrSymbolOutput,
+			expected: "MKR",
+		},
+		"empty": {
+			output: "0x",
+			err:    true,
+		},
+		"invalid utf8": {
+			output: "0xff00000000000000000000000000000000000000000000000000000000000000",
+			err:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := DecodeTokenString(common.FromHex(test.output))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, s)
+		})
+	}
+}
+
+func TestGetContractCurrency(t *testing.T) {
+	tokenAddress := common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+
+	tests := map[string]struct {
+		overrides      []configuration.Token
+		symbolOutput   string
+		decimalsOutput string
+		symbolErr      error
+		decimalsErr    error
+		// interfaces are the supportsInterface outputs by interface id, calls
+		// for other interface ids revert
+		interfaces map[string]string
+		expected   *ContractCurrency
+	}{
+		"bytes32 symbol": {
+			symbolOutput:   mkrSymbolOutput,
+			decimalsOutput: eighteenDecimalsOutput,
+			expected:       &ContractCurrency{Symbol: "MKR", Decimals: 18, Standard: ERC20TokenStandard},
+		},
+		"zero decimals": {
+			symbolOutput:   usdcSymbolOutput,
+			decimalsOutput: zeroDecimalsOutput,
+			expected:       &ContractCurrency{Symbol: "USDC", Decimals: 0, Standard: ERC20TokenStandard},
+		},
+		"erc721": {
+			symbolOutput: usdcSymbolOutput,
+			decimalsErr:  errors.New("execution reverted"),
+			interfaces: map[string]string{
+				ERC721InterfaceID:  trueOutput,
+				invalidInterfaceID: falseOutput,
+			},
+			expected: &ContractCurrency{Symbol: "USDC", Decimals: 0, Standard: ERC721TokenStandard},
+		},
+		"unknown erc721": {
+			symbolErr:   errors.New("execution reverted"),
+			decimalsErr: errors.New("execution reverted"),
+			interfaces: map[string]string{
+				ERC721InterfaceID:  trueOutput,
+				invalidInterfaceID: falseOutput,
+			},
+			expected: &ContractCurrency{
+				Symbol:   UnknownERC721Symbol,
+				Decimals: UnknownERC721Decimals,
+				Standard: ERC721TokenStandard,
+			},
+		},
+		"supports any interface": {
+			symbolOutput:   usdcSymbolOutput,
+			decimalsOutput: zeroDecimalsOutput,
+			interfaces: map[string]string{
+				ERC721InterfaceID:  trueOutput,
+				invalidInterfaceID: trueOutput,
+			},
+			expected: &ContractCurrency{Symbol: "USDC", Decimals: 0, Standard: ERC20TokenStandard},
+		},
+		"missing decimals": {
+			symbolOutput: usdcSymbolOutput,
+			decimalsErr:  errors.New("execution reverted"),
+			expected:     &ContractCurrency{Symbol: UnknownERC20Symbol, Decimals: UnknownERC20Decimals},
+		},
+		"override": {
+			overrides: []configuration.Token{
+				{Address: "0x9f8f72aa9304c8b593d555f12ef6589cc3a579a2", Symbol: "MKR", Decimals: 18},
+			},
+			expected: &ContractCurrency{Symbol: "MKR", Decimals: 18, Standard: ERC20TokenStandard},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{
+				RPCClient: &RPCClient{JSONRPC: mockJSONRPC},
+				rosettaConfig: configuration.RosettaConfig{
+					TokenMetadataOverrides: test.overrides,
+				},
+			}
+
+			calls := map[string]string{
+				SymbolMethodID:   test.symbolOutput,
+				DecimalsMethodID: test.decimalsOutput,
+			}
+			if len(test.overrides) == 0 {
+				calls[supportsInterfaceData(ERC721InterfaceID)] = test.interfaces[ERC721InterfaceID]
+				if output, ok := test.interfaces[invalidInterfaceID]; ok {
+					calls[supportsInterfaceData(invalidInterfaceID)] = output
+				}
+			}
+			for methodID, output := range calls {
+				var callErr error
+				switch {
+				case methodID == DecimalsMethodID:
+					callErr = test.decimalsErr
+				case methodID == SymbolMethodID:
+					callErr = test.symbolErr
+				case strings.HasPrefix(methodID, SupportsInterfaceMethodID) && output == "":
+					callErr = errors.New("execution reverted")
+				}
+				if output == "" && callErr == nil {
+					continue
+				}
+				output := output
+				mockJSONRPC.On(
+					"CallContext",
+					context.Background(),
+					mock.Anything,
+					"eth_call",
+					map[string]string{
+						"to":   tokenAddress.String(),
+						"data": methodID,
+					},
+					"latest",
+				).Return(
+					callErr,
+				).Run(
+					func(args mock.Arguments) {
+						*(args.Get(1).(*string)) = output
+					},
+				).Once()
+			}
+
+			currency, err := sdkClient.GetContractCurrency(tokenAddress, true)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, currency)
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+)
+
+// maxTokenWhiteListSize is the maximum size in bytes of a token white list
+// loaded by TokenWhiteListSource
+const maxTokenWhiteListSize = 16 << 20
+
+// ErrTokenWhiteListUnavailable is returned by a TokenWhiteListAccessor that has
+// no token white list to serve
+var ErrTokenWhiteListUnavailable = errors.New("token white list is unavailable")
+
+// TokenWhiteListAccessor provides the token white list when it is maintained
+// outside the configuration. The block service uses the list of clients
+// implementing it instead of RosettaConfig.TokenWhiteList.
+//
+// TokenWhiteList returns the current list, which callers must not modify. When
+// the list can't be refreshed, the last loaded list is returned, so that an
+// unreachable source doesn't change the operations of blocks; an error wrapping
+// ErrTokenWhiteListUnavailable is only returned while no list was loaded.
+type TokenWhiteListAccessor interface {
+	TokenWhiteList(ctx context.Context) ([]configuration.Token, error)
+}
+
+// TokenWhiteListSource is the TokenWhiteListAccessor of
+// RosettaConfig.TokenWhiteListURL, loading the token white list as a JSON array
+// of tokens from an http(s) URL or a file. The list is loaded on first access
+// and reloaded on access once the refresh interval passed since the last
+// attempt. HTTP lists are requested with the ETag of the last response, so an
+// unchanged list is not transferred again, and files are only read again when
+// their modification time changes. Lists with invalid entries, see
+// configuration.ValidateTokens, are rejected like unreachable ones.
+type TokenWhiteListSource struct {
+	location   string
+	refresh    time.Duration
+	chainID    uint64
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu      sync.Mutex
+	tokens  []configuration.Token
+	loaded  bool
+	checked time.Time
+	etag    string
+	modTime time.Time
+}
+
+// NewTokenWhiteListSource returns a TokenWhiteListSource loading the list at
+// location every refresh interval. Tokens must have no chain id or chainID.
+// httpClient defaults to http.DefaultClient.
+func NewTokenWhiteListSource(
+	location string,
+	refresh time.Duration,
+	chainID uint64,
+	httpClient *http.Client,
+) *TokenWhiteListSource {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &TokenWhiteListSource{
+		location:   location,
+		refresh:    refresh,
+		chainID:    chainID,
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+}
+
+// TokenWhiteList returns the token white list, refreshing it if it is due
+func (s *TokenWhiteListSource) TokenWhiteList(ctx context.Context) ([]configuration.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded && s.now().Sub(s.checked) < s.refresh {
+		return s.tokens, nil
+	}
+	s.checked = s.now()
+
+	if err := s.load(ctx); err != nil {
+		if !s.loaded {
+			return nil, fmt.Errorf("%w: %v", ErrTokenWhiteListUnavailable, err)
+		}
+		log.Printf("could not refresh token white list %s, keeping the last one: %v", s.location, err)
+		return s.tokens, nil
+	}
+
+	return s.tokens, nil
+}
+
+// load loads the list unless it is unchanged
+func (s *TokenWhiteListSource) load(ctx context.Context) error {
+	u, err := url.Parse(s.location)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return s.loadHTTP(ctx)
+	case "file":
+		return s.loadFile(u.Path)
+	default:
+		return s.loadFile(s.location)
+	}
+}
+
+func (s *TokenWhiteListSource) loadHTTP(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return err
+	}
+	if s.loaded && len(s.etag) > 0 {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if !s.loaded {
+			return errors.New("list not modified before it was loaded")
+		}
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenWhiteListSize+1))
+	if err != nil {
+		return err
+	}
+	if err := s.set(data); err != nil {
+		return err
+	}
+	s.etag = resp.Header.Get("ETag")
+
+	return nil
+}
+
+func (s *TokenWhiteListSource) loadFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if s.loaded && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	if info.Size() > maxTokenWhiteListSize {
+		return fmt.Errorf("list is larger than %d bytes", maxTokenWhiteListSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := s.set(data); err != nil {
+		return err
+	}
+	s.modTime = info.ModTime()
+
+	return nil
+}
+
+// set decodes and validates the list data and makes it the current list
+func (s *TokenWhiteListSource) set(data []byte) error {
+	if len(data) > maxTokenWhiteListSize {
+		return fmt.Errorf("list is larger than %d bytes", maxTokenWhiteListSize)
+	}
+	var tokens []configuration.Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return fmt.Errorf("could not decode list: %w", err)
+	}
+	if err := configuration.ValidateTokens("token white list", tokens, s.chainID); err != nil {
+		return err
+	}
+
+	s.tokens = tokens
+	s.loaded = true
+	return nil
+}
+
+// TokenWhiteList returns the token white list of RosettaConfig.TokenWhiteListURL
+// if it is set, and RosettaConfig.TokenWhiteList otherwise
+func (ec *SDKClient) TokenWhiteList(ctx context.Context) ([]configuration.Token, error) {
+	if ec.tokenWhiteListSource != nil {
+		return ec.tokenWhiteListSource.TokenWhiteList(ctx)
+	}
+
+	return ec.rosettaConfig.TokenWhiteList, nil
+}
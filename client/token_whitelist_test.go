@@ -0,0 +1,170 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	usdcWhiteList = `[{"chainId": 1, "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "symbol": "USDC", "decimals": 6}]`
+	daiWhiteList  = `[{"chainId": 1, "address": "0x6B175474E89094C44Da98b954EedeAC495271d0F", "symbol": "DAI", "decimals": 18}]`
+)
+
+func TestTokenWhiteListSource_HTTP(t *testing.T) {
+	list, etag, status := usdcWhiteList, `"v1"`, http.StatusOK
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(list))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	source := NewTokenWhiteListSource(server.URL, time.Minute, 1, nil)
+	source.now = func() time.Time { return now }
+
+	// The list is only requested again once the refresh interval passed
+	tokens, err := source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []configuration.Token{{
+		ChainID:  1,
+		Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Symbol:   "USDC",
+		Decimals: 6,
+	}}, tokens)
+	now = now.Add(30 * time.Second)
+	_, err = source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// An unchanged list is not transferred again
+	now = now.Add(time.Minute)
+	tokens, err = source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "USDC", tokens[0].Symbol)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+
+	list, etag = daiWhiteList, `"v2"`
+	now = now.Add(time.Minute)
+	tokens, err = source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", tokens[0].Symbol)
+
+	// Failed refreshes and invalid lists keep the last list
+	status = http.StatusServiceUnavailable
+	now = now.Add(time.Minute)
+	tokens, err = source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", tokens[0].Symbol)
+
+	list, etag, status = `[{"address": "0x123", "symbol": "BAD"}]`, `"v3"`, http.StatusOK
+	now = now.Add(time.Minute)
+	tokens, err = source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", tokens[0].Symbol)
+}
+
+func TestTokenWhiteListSource_Unavailable(t *testing.T) {
+	ctx := context.Background()
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(usdcWhiteList))
+	}))
+	defer server.Close()
+
+	// Until a list is loaded, every access retries and fails
+	source := NewTokenWhiteListSource(server.URL, time.Hour, 1, nil)
+	_, err := source.TokenWhiteList(ctx)
+	assert.ErrorIs(t, err, ErrTokenWhiteListUnavailable)
+	assert.ErrorContains(t, err, "unexpected status 404 Not Found")
+
+	status = http.StatusOK
+	tokens, err := source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	// Tokens of other chains are rejected
+	source = NewTokenWhiteListSource(server.URL, time.Hour, 10, nil)
+	_, err = source.TokenWhiteList(ctx)
+	assert.ErrorIs(t, err, ErrTokenWhiteListUnavailable)
+	assert.ErrorContains(t, err, "has chain id 1, expected 10")
+}
+
+func TestTokenWhiteListSource_File(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	assert.NoError(t, os.WriteFile(path, []byte(usdcWhiteList), 0o600))
+
+	for _, location := range []string{path, "file://" + path} {
+		source := NewTokenWhiteListSource(location, 0, 1, nil)
+		tokens, err := source.TokenWhiteList(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "USDC", tokens[0].Symbol)
+	}
+
+	// The file is read again when it is modified
+	source := NewTokenWhiteListSource(path, 0, 1, nil)
+	_, err := source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte(daiWhiteList), 0o600))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Hour)))
+	tokens, err := source.TokenWhiteList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", tokens[0].Symbol)
+
+	_, err = NewTokenWhiteListSource(filepath.Join(t.TempDir(), "missing.json"), 0, 1, nil).TokenWhiteList(ctx)
+	assert.ErrorIs(t, err, ErrTokenWhiteListUnavailable)
+}
+
+func TestSDKClient_TokenWhiteList(t *testing.T) {
+	whiteList := []configuration.Token{{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC"}}
+	sdkClient := &SDKClient{rosettaConfig: configuration.RosettaConfig{TokenWhiteList: whiteList}}
+	tokens, err := sdkClient.TokenWhiteList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, whiteList, tokens)
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	assert.NoError(t, os.WriteFile(path, []byte(daiWhiteList), 0o600))
+	sdkClient.tokenWhiteListSource = NewTokenWhiteListSource(path, time.Minute, 1, nil)
+	tokens, err = sdkClient.TokenWhiteList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", tokens[0].Symbol)
+}
@@ -15,11 +15,14 @@
 package client
 
 import (
+	_ "embed" // for the JS call tracer
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
 
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/eth/tracers"
@@ -27,36 +30,104 @@ import (
 
 // convert raw eth data from SDKClient to rosetta
 
-const (
-	tracerPath = "client/call_tracer.js"
-)
+// callTracer is the JS call tracer used with configuration.GethJsTrace
+//
+//go:embed call_tracer.js
+var callTracer string
 
 var (
 	tracerTimeout = "120s"
 	nativeTracer  = "callTracer"
 )
 
+// GetTraceConfig returns the config of the native call tracer, or of the
+// embedded JS call tracer if useNative is false
 func GetTraceConfig(useNative bool) (*tracers.TraceConfig, error) {
+	tracer := callTracer
 	if useNative {
+		tracer = nativeTracer
+	}
+
+	return &tracers.TraceConfig{
+		Timeout: &tracerTimeout,
+		Tracer:  &tracer,
+	}, nil
+}
+
+// LoadTraceConfig returns the trace config of cfg: the tracer of
+// RosettaConfig.CustomTracer, with RosettaConfig.CustomTracerConfig, or of
+// RosettaConfig.CustomTracerPath if set, and the call tracer of the trace type
+// otherwise, see GetTraceConfig
+func LoadTraceConfig(cfg configuration.RosettaConfig) (*tracers.TraceConfig, error) {
+	switch {
+	case len(cfg.CustomTracer) > 0:
+		tracer := cfg.CustomTracer
+		return &tracers.TraceConfig{
+			Timeout:      &tracerTimeout,
+			Tracer:       &tracer,
+			TracerConfig: cfg.CustomTracerConfig,
+		}, nil
+	case len(cfg.CustomTracerPath) > 0:
+		loadedFile, err := os.ReadFile(cfg.CustomTracerPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load tracer file: %w", err)
+		}
+		tracer := string(loadedFile)
 		return &tracers.TraceConfig{
 			Timeout: &tracerTimeout,
-			Tracer:  &nativeTracer,
+			Tracer:  &tracer,
 		}, nil
+	default:
+		return GetTraceConfig(cfg.TraceType == configuration.GethNativeTrace)
 	}
-	return loadTraceConfig()
 }
 
-func loadTraceConfig() (*tracers.TraceConfig, error) {
-	loadedFile, err := os.ReadFile(tracerPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not load tracer file: %w", err)
+// TraceDecoder maps the tracer result of a transaction into its flattened
+// calls, for custom tracers whose result doesn't decode into Call, see
+// RosettaConfig.CustomTracer. Transactions it returns no calls for have no
+// trace.
+type TraceDecoder func(result json.RawMessage) ([]*FlatCall, error)
+
+// SetTraceDecoder sets the TraceDecoder of the results of debug_traceBlockByHash
+// and debug_traceTransaction. Clients embedding SDKClient set it before copying
+// it.
+func (ec *SDKClient) SetTraceDecoder(decoder TraceDecoder) {
+	ec.traceDecoder = decoder
+}
+
+// decodeBlockTraces maps the debug_traceBlockByHash result raw of the block
+// with txs into the flattened calls of each transaction with the TraceDecoder
+func (ec *SDKClient) decodeBlockTraces(
+	raw json.RawMessage,
+	blockHash common.Hash,
+	txs []RPCTransaction,
+) (map[string][]*FlatCall, error) {
+	var results []struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(txs) {
+		return nil, fmt.Errorf("got %d traces for %d txs of block %s", len(results), len(txs), blockHash.Hex())
 	}
 
-	loadedTracer := string(loadedFile)
-	return &tracers.TraceConfig{
-		Timeout: &tracerTimeout,
-		Tracer:  &loadedTracer,
-	}, nil
+	m := make(map[string][]*FlatCall, len(results))
+	for i, result := range results {
+		flatCalls, err := ec.traceDecoder(result.Result)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode trace of %dth tx of block %s: %w", i, blockHash.Hex(), err)
+		}
+		if len(flatCalls) == 0 {
+			continue
+		}
+		if txs[i].TxExtraInfo.TxHash == nil {
+			return nil, fmt.Errorf("could not get %dth tx hash for block %s", i, blockHash.Hex())
+		}
+		m[txs[i].TxExtraInfo.TxHash.Hex()] = flatCalls
+	}
+
+	return m, nil
 }
 
 // geth traces types
@@ -116,8 +187,10 @@ type Call struct {
 	Value              *big.Int       `json:"value"`
 	GasUsed            *big.Int       `json:"gasUsed"`
 	Revert             bool
-	ErrorMessage       string  `json:"error"`
-	Calls              []*Call `json:"calls"`
+	ErrorMessage       string        `json:"error"`
+	Input              hexutil.Bytes `json:"input,omitempty"`
+	Output             hexutil.Bytes `json:"output,omitempty"`
+	Calls              []*Call       `json:"calls"`
 }
 
 type FlatCall struct {
@@ -129,7 +202,18 @@ type FlatCall struct {
 	Value              *big.Int       `json:"value"`
 	GasUsed            *big.Int       `json:"gasUsed"`
 	Revert             bool
-	ErrorMessage       string `json:"error"`
+	ErrorMessage       string        `json:"error"`
+	Output             hexutil.Bytes `json:"output,omitempty"`
+
+	// Input is the input of the call, which is not part of the trace metadata
+	// of transactions. It is used to decode precompile calls, see
+	// PrecompileDecoder.
+	Input hexutil.Bytes `json:"-"`
+
+	// Depth is the depth of the call in the call tree of the transaction, 0
+	// for the top level call. It bounds the trace metadata of transactions,
+	// see configuration.RosettaConfig.TraceMetadataMaxDepth.
+	Depth int `json:"-"`
 }
 
 func (t *Call) flatten() *FlatCall {
@@ -143,6 +227,8 @@ func (t *Call) flatten() *FlatCall {
 		GasUsed:            t.GasUsed,
 		Revert:             t.Revert,
 		ErrorMessage:       t.ErrorMessage,
+		Output:             t.Output,
+		Input:              t.Input,
 	}
 }
 
@@ -157,8 +243,10 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		Value              *hexutil.Big   `json:"value"`
 		GasUsed            *hexutil.Big   `json:"gasUsed"`
 		Revert             bool
-		ErrorMessage       string  `json:"error"`
-		Calls              []*Call `json:"calls"`
+		ErrorMessage       string        `json:"error"`
+		Input              hexutil.Bytes `json:"input"`
+		Output             hexutil.Bytes `json:"output"`
+		Calls              []*Call       `json:"calls"`
 	}
 	var dec CustomTrace
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -170,6 +258,7 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 	t.Type = dec.Type
 	t.From = common.HexToAddress(dec.From)
 	t.To = dec.To
+	t.Input = dec.Input
 	if dec.Value != nil {
 		t.Value = (*big.Int)(dec.Value)
 	} else {
@@ -186,6 +275,7 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		t.Revert = true
 	}
 	t.ErrorMessage = dec.ErrorMessage
+	t.Output = dec.Output
 	t.Calls = dec.Calls
 	return nil
 }
@@ -198,6 +288,7 @@ type OpenEthTraceCall struct {
 
 type OpenEthTrace struct {
 	Subtraces       int64         `json:"subtraces"`
+	TraceAddress    []int         `json:"traceAddress"`
 	Action          OpenEthAction `json:"action"`
 	Type            string        `json:"type"`
 	TransactionHash string        `json:"transactionHash"`
@@ -209,6 +300,7 @@ type OpenEthAction struct {
 	To      common.Address `json:"to"`
 	Value   *big.Int       `json:"value"`
 	GasUsed *big.Int       `json:"gas"`
+	Input   hexutil.Bytes  `json:"input"`
 }
 
 func (t *Call) init() []*FlatCall { // nolint
@@ -253,6 +345,7 @@ func (t *OpenEthAction) UnmarshalJSON(input []byte) error {
 		To      common.Address `json:"to"`
 		Value   *hexutil.Big   `json:"value"`
 		GasUsed *hexutil.Big   `json:"gas"`
+		Input   hexutil.Bytes  `json:"input"`
 	}
 	var dec CustomTrace
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -262,6 +355,7 @@ func (t *OpenEthAction) UnmarshalJSON(input []byte) error {
 	t.Type = dec.Type
 	t.From = dec.From
 	t.To = dec.To
+	t.Input = dec.Input
 	if dec.Value != nil {
 		t.Value = dec.Value.ToInt()
 	} else {
@@ -289,6 +383,8 @@ func FlattenOpenEthTraces(data *OpenEthTraceCall, flattened []*FlatCall) []*Flat
 			To:      action.To,
 			Value:   action.Value,
 			GasUsed: action.GasUsed,
+			Input:   action.Input,
+			Depth:   len(child.TraceAddress),
 			// Revert:       t.Revert,
 			// ErrorMessage: t.ErrorMessage,
 		}
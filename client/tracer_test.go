@@ -0,0 +1,177 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestLoadTraceConfig(t *testing.T) {
+	embedded, err := os.ReadFile("call_tracer.js")
+	assert.NoError(t, err)
+
+	tracerPath := filepath.Join(t.TempDir(), "tracer.js")
+	assert.NoError(t, os.WriteFile(tracerPath, []byte("{result: function() { return {}; }}"), 0o600))
+
+	tests := map[string]struct {
+		cfg                  configuration.RosettaConfig
+		expectedTracer       string
+		expectedTracerConfig json.RawMessage
+		expectedError        bool
+	}{
+		"native": {
+			cfg:            configuration.RosettaConfig{TraceType: configuration.GethNativeTrace},
+			expectedTracer: "callTracer",
+		},
+		"js": {
+			cfg:            configuration.RosettaConfig{TraceType: configuration.GethJsTrace},
+			expectedTracer: string(embedded),
+		},
+		"custom tracer": {
+			cfg: configuration.RosettaConfig{
+				TraceType:          configuration.GethNativeTrace,
+				CustomTracer:       "callTracer",
+				CustomTracerConfig: json.RawMessage(`{"withLog":true}`),
+			},
+			expectedTracer:       "callTracer",
+			expectedTracerConfig: json.RawMessage(`{"withLog":true}`),
+		},
+		"custom tracer path": {
+			cfg: configuration.RosettaConfig{
+				TraceType:        configuration.GethJsTrace,
+				CustomTracerPath: tracerPath,
+			},
+			expectedTracer: "{result: function() { return {}; }}",
+		},
+		"missing custom tracer path": {
+			cfg: configuration.RosettaConfig{
+				TraceType:        configuration.GethJsTrace,
+				CustomTracerPath: filepath.Join(t.TempDir(), "missing.js"),
+			},
+			expectedError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc, err := LoadTraceConfig(test.cfg)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedTracer, *tc.Tracer)
+			assert.Equal(t, test.expectedTracerConfig, tc.TracerConfig)
+			assert.Equal(t, "120s", *tc.Timeout)
+		})
+	}
+}
+
+// precompileTransfers is the result of a custom tracer capturing the value
+// transfers of a transaction, including those of precompiles
+type precompileTransfers struct {
+	Transfers []struct {
+		From  common.Address `json:"from"`
+		To    common.Address `json:"to"`
+		Value *hexutil.Big   `json:"value"`
+	} `json:"transfers"`
+}
+
+func decodePrecompileTransfers(result json.RawMessage) ([]*FlatCall, error) {
+	var trace precompileTransfers
+	if err := json.Unmarshal(result, &trace); err != nil {
+		return nil, err
+	}
+	calls := make([]*FlatCall, 0, len(trace.Transfers))
+	for _, transfer := range trace.Transfers {
+		calls = append(calls, &FlatCall{
+			Type:    "CALL",
+			From:    transfer.From,
+			To:      transfer.To,
+			Value:   transfer.Value.ToInt(),
+			GasUsed: new(big.Int),
+		})
+	}
+
+	return calls, nil
+}
+
+func TestTraceDecoder(t *testing.T) {
+	ctx := context.Background()
+	blockHash := common.HexToHash("0x01")
+	tx1 := common.HexToHash("0xaa")
+	tx2 := common.HexToHash("0xbb")
+	alice := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	precompile := common.HexToAddress("0x0000000000000000000000000000000000000800")
+	transfer := `{"transfers": [{"from": "` + alice.Hex() + `", "to": "` + precompile.Hex() + `", "value": "0x64"}]}`
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "debug_traceBlockByHash", blockHash, mock.Anything).Return(
+		func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			*(result.(*json.RawMessage)) = json.RawMessage(`[{"result": ` + transfer + `}, {"result": {"transfers": []}}]`)
+			return nil
+		},
+	).Once()
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "debug_traceTransaction", tx1, mock.Anything).Return(
+		func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			*(result.(*json.RawMessage)) = json.RawMessage(transfer)
+			return nil
+		},
+	).Once()
+
+	sdkClient := &SDKClient{
+		RPCClient:      &RPCClient{JSONRPC: mockJSONRPC},
+		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
+	}
+	sdkClient.SetTraceDecoder(decodePrecompileTransfers)
+
+	expected := []*FlatCall{{
+		Type:    "CALL",
+		From:    alice,
+		To:      precompile,
+		Value:   big.NewInt(100),
+		GasUsed: new(big.Int),
+	}}
+	txs := []RPCTransaction{
+		{TxExtraInfo: TxExtraInfo{TxHash: &tx1}},
+		{TxExtraInfo: TxExtraInfo{TxHash: &tx2}},
+	}
+	m, err := sdkClient.TraceBlockByHash(ctx, blockHash, txs)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]*FlatCall{tx1.Hex(): expected}, m)
+
+	_, flattened, err := sdkClient.TraceTransaction(ctx, tx1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, flattened)
+
+	// The traces must match the transactions of the block
+	_, err = sdkClient.decodeBlockTraces(json.RawMessage(`[]`), blockHash, txs)
+	assert.EqualError(t, err, "got 0 traces for 2 txs of block "+blockHash.Hex())
+	mockJSONRPC.AssertExpectations(t)
+}
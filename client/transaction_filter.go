@@ -0,0 +1,40 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import "context"
+
+// TransactionFilterAction is what the block service does with a transaction
+// inspected by a TransactionFilter
+type TransactionFilterAction int
+
+const (
+	// KeepTransaction maps the transaction, with any changes made by the filter
+	KeepTransaction TransactionFilterAction = iota
+
+	// DropTransaction leaves the transaction out of the block
+	DropTransaction
+)
+
+// TransactionFilter is an optional interface a client can implement to handle
+// the pseudo transactions injected by the consensus layer of some chains, like
+// the system reward transactions of BSC and the state sync transactions of
+// Polygon. FilterTransaction is called for every transaction of a block once its
+// receipt and fees are loaded, before its operations are mapped. Besides dropping
+// the transaction, it can rewrite it in place, e.g. zero FeeAmount and FeeBurned
+// for transactions that don't pay fees, or reclassify it with SetExtension.
+type TransactionFilter interface {
+	FilterTransaction(ctx context.Context, tx *LoadedTransaction) (TransactionFilterAction, error)
+}
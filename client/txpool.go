@@ -0,0 +1,147 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxNonceGaps is the maximum number of nonce gaps returned, since a queued
+// transaction can have an arbitrarily high nonce
+const maxNonceGaps = 256
+
+// PoolTransaction is a transaction in the transaction pool of the node
+type PoolTransaction struct {
+	Hash                 common.Hash     `json:"hash"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	To                   *common.Address `json:"to"`
+	Value                *hexutil.Big    `json:"value"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+}
+
+// SenderPoolTransactions are the transactions of a sender in the transaction
+// pool. Pending transactions are executable, while queued transactions wait
+// for the transactions of lower nonces. NonceGaps are the nonces between the
+// account nonce and the highest nonce in the pool without a transaction, which
+// keep the transactions above them queued.
+type SenderPoolTransactions struct {
+	Address      string             `json:"address"`
+	AccountNonce uint64             `json:"account_nonce"`
+	Pending      []*PoolTransaction `json:"pending"`
+	Queued       []*PoolTransaction `json:"queued"`
+	NonceGaps    []uint64           `json:"nonce_gaps"`
+}
+
+// poolContent is the pool content of a sender returned by txpool_contentFrom,
+// keyed by nonce
+type poolContent struct {
+	Pending map[string]*PoolTransaction `json:"pending"`
+	Queued  map[string]*PoolTransaction `json:"queued"`
+}
+
+// GetPendingTransactionsForSender returns the transactions of address in the
+// transaction pool, to diagnose stuck transactions. It uses txpool_contentFrom,
+// and txpool_content when the node doesn't support it.
+func (ec *SDKClient) GetPendingTransactionsForSender(
+	ctx context.Context,
+	address common.Address,
+) (*SenderPoolTransactions, error) {
+	var (
+		content      poolContent
+		accountNonce hexutil.Uint64
+	)
+	reqs := []rpc.BatchElem{
+		{Method: "txpool_contentFrom", Args: []interface{}{address}, Result: &content},
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, "latest"}, Result: &accountNonce},
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+
+	if IsMethodNotFound(reqs[0].Error) {
+		var all struct {
+			Pending map[common.Address]map[string]*PoolTransaction `json:"pending"`
+			Queued  map[common.Address]map[string]*PoolTransaction `json:"queued"`
+		}
+		if err := ec.CallContext(ctx, &all, "txpool_content"); err != nil {
+			return nil, fmt.Errorf("txpool_content failed: %w", err)
+		}
+		content = poolContent{Pending: all.Pending[address], Queued: all.Queued[address]}
+	} else if reqs[0].Error != nil {
+		return nil, fmt.Errorf("txpool_contentFrom failed: %w", reqs[0].Error)
+	}
+	if reqs[1].Error != nil {
+		return nil, fmt.Errorf("eth_getTransactionCount failed: %w", reqs[1].Error)
+	}
+
+	pending := sortedPoolTransactions(content.Pending)
+	queued := sortedPoolTransactions(content.Queued)
+
+	return &SenderPoolTransactions{
+		Address:      FormatAddress(address),
+		AccountNonce: uint64(accountNonce),
+		Pending:      pending,
+		Queued:       queued,
+		NonceGaps:    nonceGaps(uint64(accountNonce), pending, queued),
+	}, nil
+}
+
+// sortedPoolTransactions returns the transactions of a pool content map
+// sorted by nonce
+func sortedPoolTransactions(txs map[string]*PoolTransaction) []*PoolTransaction {
+	sorted := make([]*PoolTransaction, 0, len(txs))
+	for _, tx := range txs {
+		sorted = append(sorted, tx)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Nonce < sorted[j].Nonce
+	})
+
+	return sorted
+}
+
+// nonceGaps returns the first maxNonceGaps nonces from accountNonce up to the
+// highest nonce of pending and queued that have no transaction
+func nonceGaps(accountNonce uint64, pending []*PoolTransaction, queued []*PoolTransaction) []uint64 {
+	nonces := map[uint64]bool{}
+	highest := accountNonce
+	for _, txs := range [][]*PoolTransaction{pending, queued} {
+		for _, tx := range txs {
+			nonce := uint64(tx.Nonce)
+			nonces[nonce] = true
+			if nonce > highest {
+				highest = nonce
+			}
+		}
+	}
+
+	gaps := []uint64{}
+	for nonce := accountNonce; nonce < highest && len(gaps) < maxNonceGaps; nonce++ {
+		if !nonces[nonce] {
+			gaps = append(gaps, nonce)
+		}
+	}
+
+	return gaps
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	mocks "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetPendingTransactionsForSender(t *testing.T) {
+	sender := common.HexToAddress("0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1")
+	pending := `{
+		"6": {"hash": "` + common.HexToHash("0x06").Hex() + `", "nonce": "0x6", "gas": "0x5208", "value": "0x1"},
+		"5": {"hash": "` + common.HexToHash("0x05").Hex() + `", "nonce": "0x5", "gas": "0x5208", "value": "0x1"}
+	}`
+	queued := `{
+		"9": {"hash": "` + common.HexToHash("0x09").Hex() + `", "nonce": "0x9", "gas": "0x5208", "value": "0x1"}
+	}`
+	other := `{
+		"0": {"hash": "` + common.HexToHash("0x10").Hex() + `", "nonce": "0x0", "gas": "0x5208", "value": "0x1"}
+	}`
+	expected := &SenderPoolTransactions{
+		Address:      sender.Hex(),
+		AccountNonce: 5,
+		Pending: []*PoolTransaction{
+			{Hash: common.HexToHash("0x05"), Nonce: 5, Gas: 21000, Value: (*hexutil.Big)(common.Big1)},
+			{Hash: common.HexToHash("0x06"), Nonce: 6, Gas: 21000, Value: (*hexutil.Big)(common.Big1)},
+		},
+		Queued: []*PoolTransaction{
+			{Hash: common.HexToHash("0x09"), Nonce: 9, Gas: 21000, Value: (*hexutil.Big)(common.Big1)},
+		},
+		NonceGaps: []uint64{7, 8},
+	}
+
+	tests := map[string]struct {
+		contentFromErr error
+		fallback       bool
+		expectedError  string
+	}{
+		"content from": {},
+		"content fallback": {
+			contentFromErr: &rpcError{code: methodNotFoundCode},
+			fallback:       true,
+		},
+		"content from error": {
+			contentFromErr: errors.New("txpool disabled"),
+			expectedError:  "txpool_contentFrom failed: txpool disabled",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			sdkClient := &SDKClient{RPCClient: &RPCClient{JSONRPC: mockJSONRPC}}
+			mockJSONRPC.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+				func(args mock.Arguments) {
+					reqs := args.Get(1).([]rpc.BatchElem)
+					assert.Equal(t, "txpool_contentFrom", reqs[0].Method)
+					assert.Equal(t, []interface{}{sender, "latest"}, reqs[1].Args)
+					reqs[0].Error = test.contentFromErr
+					if test.contentFromErr == nil {
+						content := `{"pending": ` + pending + `, "queued": ` + queued + `}`
+						assert.NoError(t, json.Unmarshal([]byte(content), reqs[0].Result))
+					}
+					*reqs[1].Result.(*hexutil.Uint64) = 5
+				},
+			).Once()
+			if test.fallback {
+				mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "txpool_content").Return(nil).Run(
+					func(args mock.Arguments) {
+						content := `{
+							"pending": {"` + sender.Hex() + `": ` + pending + `, "0x0000000000000000000000000000000000000001": ` + other + `},
+							"queued": {"` + strings.ToLower(sender.Hex()) + `": ` + queued + `}
+						}`
+						assert.NoError(t, json.Unmarshal([]byte(content), args.Get(1)))
+					},
+				).Once()
+			}
+
+			txs, err := sdkClient.GetPendingTransactionsForSender(context.Background(), sender)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expected, txs)
+			}
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNonceGaps(t *testing.T) {
+	tests := map[string]struct {
+		accountNonce uint64
+		pending      []uint64
+		queued       []uint64
+		expected     []uint64
+	}{
+		"empty pool": {
+			accountNonce: 3,
+			expected:     []uint64{},
+		},
+		"no gaps": {
+			accountNonce: 3,
+			pending:      []uint64{3, 4},
+			expected:     []uint64{},
+		},
+		"missing account nonce": {
+			accountNonce: 3,
+			queued:       []uint64{5},
+			expected:     []uint64{3, 4},
+		},
+		"capped": {
+			queued:   []uint64{1 << 40},
+			expected: make([]uint64, maxNonceGaps),
+		},
+	}
+	for i := range tests["capped"].expected {
+		tests["capped"].expected[i] = uint64(i)
+	}
+
+	poolTransactions := func(nonces []uint64) []*PoolTransaction {
+		txs := make([]*PoolTransaction, len(nonces))
+		for i, nonce := range nonces {
+			txs[i] = &PoolTransaction{Nonce: hexutil.Uint64(nonce)}
+		}
+		return txs
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gaps := nonceGaps(test.accountNonce, poolTransactions(test.pending), poolTransactions(test.queued))
+			assert.Equal(t, test.expected, gaps)
+		})
+	}
+}
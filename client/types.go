@@ -50,6 +50,11 @@ const (
 	eip1559TxType = 2
 
 	ContractAddressMetadata = "contractAddress"
+
+	// Sources of the gas price of a receipt, see RosettaTxReceipt.GasPriceSource
+	ReceiptGasPriceSource     = "receipt"
+	ComputedGasPriceSource    = "computed"
+	TransactionGasPriceSource = "transaction"
 )
 
 var (
@@ -64,12 +69,25 @@ var (
 type ContractCurrency struct {
 	Symbol   string `json:"symbol"`
 	Decimals int32  `json:"decimals"`
+
+	// Standard is the detected standard of the token contract
+	Standard TokenStandard `json:"standard,omitempty"`
 }
 
 type RPCBlock struct {
 	Hash         common.Hash      `json:"hash"`
 	Transactions []RPCTransaction `json:"transactions"`
 	UncleHashes  []common.Hash    `json:"uncles"`
+
+	// Withdrawals are the EIP-4895 withdrawals of the block, if any
+	Withdrawals []*EthTypes.Withdrawal `json:"withdrawals,omitempty"`
+
+	// Size is the size of the block in bytes reported by the node, if any
+	Size *hexutil.Uint64 `json:"size,omitempty"`
+
+	// HeaderExtraFields are the non standard header fields of the block,
+	// captured when RosettaConfig.TolerantHeaderDecoding is enabled
+	HeaderExtraFields map[string]json.RawMessage `json:"-"`
 }
 
 type TxExtraInfo struct {
@@ -78,18 +96,35 @@ type TxExtraInfo struct {
 	From        *common.Address `json:"from,omitempty"`
 	TxHash      *common.Hash    `json:"hash,omitempty"`
 	Mint        string          `json:"mint,omitempty"`
+
+	// Extensions are the non standard fields of the RPC transaction
+	Extensions map[string]interface{} `json:"-"`
 }
 
 type Metadata struct {
-	Nonce           uint64      `json:"nonce"`
-	GasPrice        *big.Int    `json:"gas_price"`
-	GasLimit        uint64      `json:"gas_limit"`
-	GasTipCap       *big.Int    `json:"gas_tip_cap,omitempty"`
-	GasFeeCap       *big.Int    `json:"gas_fee_cap,omitempty"`
-	ContractData    string      `json:"data,omitempty"`
-	MethodSignature string      `json:"method_signature,omitempty"`
-	MethodArgs      interface{} `json:"method_args,omitempty"`
-	L1DataFee       *big.Int    `json:"l1_data_fee,omitempty"`
+	Nonce            uint64      `json:"nonce"`
+	GasPrice         *big.Int    `json:"gas_price"`
+	GasLimit         uint64      `json:"gas_limit"`
+	GasTipCap        *big.Int    `json:"gas_tip_cap,omitempty"`
+	GasFeeCap        *big.Int    `json:"gas_fee_cap,omitempty"`
+	ContractData     string      `json:"data,omitempty"`
+	MethodSignature  string      `json:"method_signature,omitempty"`
+	MethodArgs       interface{} `json:"method_args,omitempty"`
+	L1DataFee        *big.Int    `json:"l1_data_fee,omitempty"`
+	ChainID          *big.Int    `json:"chain_id,omitempty"`
+	PriorityFeeFloor *big.Int    `json:"priority_fee_floor,omitempty"`
+	PriorityFeeCap   *big.Int    `json:"priority_fee_cap,omitempty"`
+
+	// GasCurrency is the currency of the suggested fee on chains with a custom gas token
+	GasCurrency *RosettaTypes.Currency `json:"gas_currency,omitempty"`
+
+	// FeeCurrency is the ERC20 token the transaction pays its fees in, on chains
+	// with fee currencies like Celo
+	FeeCurrency string `json:"fee_currency,omitempty"`
+
+	// AccessList is the EIP-2930 access list of the transaction. Transactions
+	// without EIP-1559 fees and with an access list are EIP-2930 transactions.
+	AccessList EthTypes.AccessList `json:"access_list,omitempty"`
 }
 
 type ParseMetadata struct {
@@ -99,6 +134,13 @@ type ParseMetadata struct {
 	GasTipCap *big.Int `json:"gas_tip_cap,omitempty"`
 	GasFeeCap *big.Int `json:"gas_fee_cap,omitempty"`
 	ChainID   *big.Int `json:"chain_id"`
+
+	// ContractAddress is the address of the contract created by a
+	// contract deployment
+	ContractAddress string `json:"contract_address,omitempty"`
+
+	// FeeCurrency is the ERC20 token a CIP-64 transaction pays its fees in
+	FeeCurrency string `json:"fee_currency,omitempty"`
 }
 
 type Transaction struct {
@@ -113,6 +155,12 @@ type Transaction struct {
 	GasFeeCap *big.Int               `json:"gas_fee_cap,omitempty"`
 	ChainID   *big.Int               `json:"chain_id"`
 	Currency  *RosettaTypes.Currency `json:"currency,omitempty"`
+
+	// AccessList is the EIP-2930 access list, added in TransactionVersion 2
+	AccessList EthTypes.AccessList `json:"access_list,omitempty"`
+
+	// FeeCurrency is the ERC20 token a CIP-64 transaction pays its fees in
+	FeeCurrency *common.Address `json:"fee_currency,omitempty"`
 }
 
 type LoadedTransaction struct {
@@ -134,14 +182,74 @@ type LoadedTransaction struct {
 	BaseFee      *big.Int
 	IsBridgedTxn bool
 
+	// EIP6780 is set when the block of the transaction is after Cancun
+	// according to the chain config, so a SELFDESTRUCT only destroys a
+	// contract created in the same transaction, see
+	// services.TransactionTraceOps.
+	EIP6780 bool
+
+	// FeeCurrency is the currency of the fee operations, for chains with a
+	// custom gas token. Fee operations use ETH when it is nil.
+	FeeCurrency *RosettaTypes.Currency
+
 	Mint string
+
+	// Extensions hold chain specific data of the transaction, like an L1 block
+	// number or sequencer fields. They are decoded from the non standard fields
+	// of the RPC transaction, can be set by the client, e.g. in ParseOps, and
+	// are added to the Rosetta transaction metadata.
+	Extensions map[string]interface{}
+}
+
+// SetExtension sets the chain specific extension key of the transaction
+func (tx *LoadedTransaction) SetExtension(key string, value interface{}) {
+	if tx.Extensions == nil {
+		tx.Extensions = map[string]interface{}{}
+	}
+	tx.Extensions[key] = value
+}
+
+// Extension returns the chain specific extension key of tx if it is set
+// and has type T.
+func Extension[T any](tx *LoadedTransaction, key string) (T, bool) {
+	value, ok := tx.Extensions[key].(T)
+	return value, ok
 }
 
 type SignedTransactionWrapper struct {
+	Version           int                    `json:"v,omitempty"`
 	SignedTransaction []byte                 `json:"signed_tx"`
 	Currency          *RosettaTypes.Currency `json:"currency,omitempty"`
 }
 
+// standardTransactionFields are the fields of a JSON RPC transaction decoded
+// by EthTypes.Transaction or TxExtraInfo
+var standardTransactionFields = map[string]bool{
+	"type":                 true,
+	"chainId":              true,
+	"nonce":                true,
+	"to":                   true,
+	"gas":                  true,
+	"gasPrice":             true,
+	"maxPriorityFeePerGas": true,
+	"maxFeePerGas":         true,
+	"maxFeePerBlobGas":     true,
+	"value":                true,
+	"input":                true,
+	"accessList":           true,
+	"blobVersionedHashes":  true,
+	"v":                    true,
+	"r":                    true,
+	"s":                    true,
+	"yParity":              true,
+	"hash":                 true,
+	"blockHash":            true,
+	"blockNumber":          true,
+	"from":                 true,
+	"transactionIndex":     true,
+	"mint":                 true,
+}
+
 // EthTypes.Transaction contains TxData, which is DynamicFeeTx:
 // https://github.com/ethereum/go-ethereum/blob/980b7682b474db61ecbd78171e7cacfec8214048
 // /core/types/dynamic_fee_tx.go#L25
@@ -158,6 +266,19 @@ type RosettaTxReceipt struct {
 	Logs           []*EthTypes.Log
 	RawMessage     json.RawMessage
 	Status         uint64 `json:"status"`
+
+	// L1Fee is the L1 data fee of a rollup transaction included in
+	// TransactionFee, nil if the receipt has none
+	L1Fee *big.Int `json:"-"`
+
+	// Bloom is the logs bloom of the receipt. It is used to detect receipts
+	// whose logs were dropped by the node provider.
+	Bloom EthTypes.Bloom `json:"-"`
+
+	// GasPriceSource is where GasPrice comes from: ReceiptGasPriceSource,
+	// ComputedGasPriceSource or TransactionGasPriceSource, see
+	// ReceiptGasPrice. It is empty when the client doesn't set it.
+	GasPriceSource string `json:"-"`
 }
 
 type FeeSetResult struct {
@@ -203,6 +324,15 @@ type Options struct {
 	MethodSignature        string                 `json:"method_signature,omitempty"`
 	MethodArgs             interface{}            `json:"method_args,omitempty"`
 	ContractData           string                 `json:"data,omitempty"`
+	Simulate               bool                   `json:"simulate,omitempty"`
+	ChainID                *big.Int               `json:"chain_id,omitempty"`
+	PriorityFeeFloor       *big.Int               `json:"priority_fee_floor,omitempty"`
+	PriorityFeeCap         *big.Int               `json:"priority_fee_cap,omitempty"`
+	FeeMode                string                 `json:"fee_mode,omitempty"`
+	FeeCurrency            string                 `json:"fee_currency,omitempty"`
+	IntrinsicGas           bool                   `json:"intrinsic_gas,omitempty"`
+	AccessList             EthTypes.AccessList    `json:"access_list,omitempty"`
+	AccountNonce           *AccountNonce          `json:"account_nonce,omitempty"`
 }
 
 // Receipt represents the results of a transaction.
@@ -0,0 +1,274 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// entryPointABI is the part of the ERC-4337 EntryPoint ABI used to decode user
+// operations: the handleOps of versions 0.6 and 0.7, whose user operations
+// only differ by their packed gas fields, and UserOperationEvent
+const entryPointABI = `[
+	{
+		"type": "function",
+		"name": "handleOps",
+		"inputs": [
+			{
+				"name": "ops",
+				"type": "tuple[]",
+				"components": [
+					{"name": "sender", "type": "address"},
+					{"name": "nonce", "type": "uint256"},
+					{"name": "initCode", "type": "bytes"},
+					{"name": "callData", "type": "bytes"},
+					{"name": "callGasLimit", "type": "uint256"},
+					{"name": "verificationGasLimit", "type": "uint256"},
+					{"name": "preVerificationGas", "type": "uint256"},
+					{"name": "maxFeePerGas", "type": "uint256"},
+					{"name": "maxPriorityFeePerGas", "type": "uint256"},
+					{"name": "paymasterAndData", "type": "bytes"},
+					{"name": "signature", "type": "bytes"}
+				]
+			},
+			{"name": "beneficiary", "type": "address"}
+		]
+	},
+	{
+		"type": "function",
+		"name": "handleOps",
+		"inputs": [
+			{
+				"name": "ops",
+				"type": "tuple[]",
+				"components": [
+					{"name": "sender", "type": "address"},
+					{"name": "nonce", "type": "uint256"},
+					{"name": "initCode", "type": "bytes"},
+					{"name": "callData", "type": "bytes"},
+					{"name": "accountGasLimits", "type": "bytes32"},
+					{"name": "preVerificationGas", "type": "uint256"},
+					{"name": "gasFees", "type": "bytes32"},
+					{"name": "paymasterAndData", "type": "bytes"},
+					{"name": "signature", "type": "bytes"}
+				]
+			},
+			{"name": "beneficiary", "type": "address"}
+		]
+	},
+	{
+		"type": "event",
+		"name": "UserOperationEvent",
+		"inputs": [
+			{"name": "userOpHash", "type": "bytes32", "indexed": true},
+			{"name": "sender", "type": "address", "indexed": true},
+			{"name": "paymaster", "type": "address", "indexed": true},
+			{"name": "nonce", "type": "uint256", "indexed": false},
+			{"name": "success", "type": "bool", "indexed": false},
+			{"name": "actualGasCost", "type": "uint256", "indexed": false},
+			{"name": "actualGasUsed", "type": "uint256", "indexed": false}
+		]
+	}
+]`
+
+// entryPoint is the parsed entryPointABI. Both handleOps keep their selector,
+// the overloaded method of version 0.7 is named handleOps0.
+var entryPoint = mustParseABI(entryPointABI)
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+// UserOperation is an ERC-4337 user operation of a bundle, see
+// ParseUserOperations
+type UserOperation struct {
+	EntryPoint common.Address
+	Hash       common.Hash
+	Sender     common.Address
+
+	// Paymaster is the zero address when the sender pays for the user operation
+	Paymaster common.Address
+
+	Nonce *big.Int
+
+	// Success is false when the execution of the user operation reverted. Its
+	// gas is still paid.
+	Success bool
+
+	ActualGasCost *big.Int
+	ActualGasUsed *big.Int
+
+	// LogIndex is the index of the UserOperationEvent of the user operation
+	LogIndex uint
+
+	// CallData, InitCode and Beneficiary are decoded from the handleOps
+	// calldata of the bundle. They are unset when the bundle doesn't call
+	// handleOps of the EntryPoint directly.
+	CallData    []byte
+	InitCode    []byte
+	Beneficiary *common.Address
+}
+
+// handleOpsCall is a user operation of the handleOps calldata of a bundle
+type handleOpsCall struct {
+	callData    []byte
+	initCode    []byte
+	beneficiary common.Address
+}
+
+// ParseUserOperations returns the ERC-4337 user operations of the bundle tx,
+// in log order, from the UserOperationEvent logs of the entryPoints. The
+// calldata of a user operation is decoded from tx when it calls handleOps of
+// the EntryPoint directly, and is matched to the event by sender and nonce.
+func ParseUserOperations(
+	entryPoints []common.Address,
+	tx *EthTypes.Transaction,
+	logs []*EthTypes.Log,
+) ([]*UserOperation, error) {
+	eventID := entryPoint.Events["UserOperationEvent"].ID
+	var calls map[string]*handleOpsCall
+	var userOps []*UserOperation
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) == 0 || log.Topics[0] != eventID || !containsAddress(entryPoints, log.Address) {
+			continue
+		}
+
+		userOp, err := parseUserOperationEvent(log)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse user operation event %d: %w", log.Index, err)
+		}
+
+		if calls == nil {
+			calls, err = parseHandleOps(tx, log.Address)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse handleOps: %w", err)
+			}
+		}
+		if call, ok := calls[userOperationKey(userOp.Sender, userOp.Nonce)]; ok {
+			userOp.CallData = call.callData
+			userOp.InitCode = call.initCode
+			beneficiary := call.beneficiary
+			userOp.Beneficiary = &beneficiary
+		}
+
+		userOps = append(userOps, userOp)
+	}
+
+	return userOps, nil
+}
+
+// parseUserOperationEvent returns the user operation of a UserOperationEvent log
+func parseUserOperationEvent(log *EthTypes.Log) (*UserOperation, error) {
+	event := entryPoint.Events["UserOperationEvent"]
+	if len(log.Topics) != 4 { // nolint:gomnd
+		return nil, fmt.Errorf("log has %d topics, expected 4", len(log.Topics))
+	}
+
+	var fields struct {
+		Nonce         *big.Int
+		Success       bool
+		ActualGasCost *big.Int
+		ActualGasUsed *big.Int
+	}
+	if err := entryPoint.UnpackIntoInterface(&fields, event.Name, log.Data); err != nil {
+		return nil, err
+	}
+
+	return &UserOperation{
+		EntryPoint:    log.Address,
+		Hash:          log.Topics[1],
+		Sender:        common.BytesToAddress(log.Topics[2].Bytes()),
+		Paymaster:     common.BytesToAddress(log.Topics[3].Bytes()),
+		Nonce:         fields.Nonce,
+		Success:       fields.Success,
+		ActualGasCost: fields.ActualGasCost,
+		ActualGasUsed: fields.ActualGasUsed,
+		LogIndex:      log.Index,
+	}, nil
+}
+
+// parseHandleOps returns the user operations of the handleOps calldata of tx,
+// keyed by userOperationKey. It is empty when tx doesn't call handleOps of
+// the entry point.
+func parseHandleOps(tx *EthTypes.Transaction, entryPointAddress common.Address) (map[string]*handleOpsCall, error) {
+	calls := map[string]*handleOpsCall{}
+	data := tx.Data()
+	if tx.To() == nil || *tx.To() != entryPointAddress || len(data) < 4 { // nolint:gomnd
+		return calls, nil
+	}
+
+	method, err := entryPoint.MethodById(data[:4])
+	if err != nil {
+		// The bundle calls another method of the entry point
+		return calls, nil // nolint:nilerr
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	beneficiary, ok := args[1].(common.Address)
+	if !ok {
+		return nil, fmt.Errorf("beneficiary %v is not an address", args[1])
+	}
+
+	// The user operations are slices of structs generated by the abi package
+	ops := reflect.ValueOf(args[0])
+	for i := 0; i < ops.Len(); i++ {
+		op := ops.Index(i)
+		sender, _ := op.FieldByName("Sender").Interface().(common.Address)
+		nonce, _ := op.FieldByName("Nonce").Interface().(*big.Int)
+		initCode, _ := op.FieldByName("InitCode").Interface().([]byte)
+		callData, _ := op.FieldByName("CallData").Interface().([]byte)
+		if nonce == nil {
+			return nil, fmt.Errorf("user operation %d has no nonce", i)
+		}
+		calls[userOperationKey(sender, nonce)] = &handleOpsCall{
+			callData:    callData,
+			initCode:    initCode,
+			beneficiary: beneficiary,
+		}
+	}
+
+	return calls, nil
+}
+
+// userOperationKey identifies a user operation of a bundle, a nonce is only
+// used once by its sender
+func userOperationKey(sender common.Address, nonce *big.Int) string {
+	return sender.Hex() + "/" + nonce.String()
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+
+	return false
+}
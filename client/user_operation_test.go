@@ -0,0 +1,196 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// packedUserOperation is a user operation of handleOps of EntryPoint 0.7
+type packedUserOperation struct {
+	Sender             common.Address
+	Nonce              *big.Int
+	InitCode           []byte
+	CallData           []byte
+	AccountGasLimits   [32]byte
+	PreVerificationGas *big.Int
+	GasFees            [32]byte
+	PaymasterAndData   []byte
+	Signature          []byte
+}
+
+// userOperation is a user operation of handleOps of EntryPoint 0.6
+type userOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+// userOperationEvent returns a UserOperationEvent log of entryPoint
+func userOperationEvent(
+	t *testing.T,
+	address common.Address,
+	index uint,
+	hash common.Hash,
+	sender common.Address,
+	paymaster common.Address,
+	nonce int64,
+	success bool,
+) *EthTypes.Log {
+	event := entryPoint.Events["UserOperationEvent"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(nonce), success, big.NewInt(1000), big.NewInt(100))
+	assert.NoError(t, err)
+
+	return &EthTypes.Log{
+		Address: address,
+		Index:   index,
+		Topics: []common.Hash{
+			event.ID,
+			hash,
+			common.BytesToHash(sender.Bytes()),
+			common.BytesToHash(paymaster.Bytes()),
+		},
+		Data: data,
+	}
+}
+
+func TestParseUserOperations(t *testing.T) {
+	entryPointV6 := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	entryPointV7 := common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032")
+	entryPoints := []common.Address{entryPointV6, entryPointV7}
+	sender := common.HexToAddress("0x01")
+	otherSender := common.HexToAddress("0x02")
+	paymaster := common.HexToAddress("0x03")
+	beneficiary := common.HexToAddress("0x04")
+	callData := []byte{0xb6, 0x1d, 0x27, 0xf6}
+
+	v6Data, err := entryPoint.Methods["handleOps"].Inputs.Pack(
+		[]userOperation{{
+			Sender:               sender,
+			Nonce:                big.NewInt(7),
+			CallData:             callData,
+			CallGasLimit:         big.NewInt(1),
+			VerificationGasLimit: big.NewInt(1),
+			PreVerificationGas:   big.NewInt(1),
+			MaxFeePerGas:         big.NewInt(1),
+			MaxPriorityFeePerGas: big.NewInt(1),
+		}},
+		beneficiary,
+	)
+	assert.NoError(t, err)
+	v6Data = append(append([]byte{}, entryPoint.Methods["handleOps"].ID...), v6Data...)
+
+	v7Data, err := entryPoint.Methods["handleOps0"].Inputs.Pack(
+		[]packedUserOperation{
+			{Sender: sender, Nonce: big.NewInt(7), InitCode: []byte{0x05}, CallData: callData, PreVerificationGas: big.NewInt(1)},
+			{Sender: otherSender, Nonce: big.NewInt(1), PreVerificationGas: big.NewInt(1)},
+		},
+		beneficiary,
+	)
+	assert.NoError(t, err)
+	v7Data = append(append([]byte{}, entryPoint.Methods["handleOps0"].ID...), v7Data...)
+
+	t.Run("handleOps of EntryPoint 0.7", func(t *testing.T) {
+		tx := EthTypes.NewTx(&EthTypes.LegacyTx{To: &entryPointV7, Data: v7Data})
+		logs := []*EthTypes.Log{
+			userOperationEvent(t, entryPointV7, 3, common.HexToHash("0xaa"), sender, paymaster, 7, true),
+			// Events of other contracts are not user operations
+			userOperationEvent(t, common.HexToAddress("0x06"), 4, common.HexToHash("0xbb"), sender, paymaster, 8, true),
+			userOperationEvent(t, entryPointV7, 5, common.HexToHash("0xcc"), otherSender, common.Address{}, 1, false),
+		}
+
+		userOps, err := ParseUserOperations(entryPoints, tx, logs)
+		assert.NoError(t, err)
+		assert.Equal(t, []*UserOperation{
+			{
+				EntryPoint:    entryPointV7,
+				Hash:          common.HexToHash("0xaa"),
+				Sender:        sender,
+				Paymaster:     paymaster,
+				Nonce:         big.NewInt(7),
+				Success:       true,
+				ActualGasCost: big.NewInt(1000),
+				ActualGasUsed: big.NewInt(100),
+				LogIndex:      3,
+				CallData:      callData,
+				InitCode:      []byte{0x05},
+				Beneficiary:   &beneficiary,
+			},
+			{
+				EntryPoint:    entryPointV7,
+				Hash:          common.HexToHash("0xcc"),
+				Sender:        otherSender,
+				Nonce:         big.NewInt(1),
+				Success:       false,
+				ActualGasCost: big.NewInt(1000),
+				ActualGasUsed: big.NewInt(100),
+				LogIndex:      5,
+				CallData:      []byte{},
+				InitCode:      []byte{},
+				Beneficiary:   &beneficiary,
+			},
+		}, userOps)
+	})
+
+	t.Run("handleOps of EntryPoint 0.6", func(t *testing.T) {
+		tx := EthTypes.NewTx(&EthTypes.LegacyTx{To: &entryPointV6, Data: v6Data})
+		logs := []*EthTypes.Log{
+			userOperationEvent(t, entryPointV6, 0, common.HexToHash("0xaa"), sender, common.Address{}, 7, true),
+		}
+
+		userOps, err := ParseUserOperations(entryPoints, tx, logs)
+		assert.NoError(t, err)
+		assert.Len(t, userOps, 1)
+		assert.Equal(t, callData, userOps[0].CallData)
+		assert.Equal(t, &beneficiary, userOps[0].Beneficiary)
+	})
+
+	t.Run("bundle through another contract", func(t *testing.T) {
+		bundler := common.HexToAddress("0x07")
+		tx := EthTypes.NewTx(&EthTypes.LegacyTx{To: &bundler, Data: v6Data})
+		logs := []*EthTypes.Log{
+			userOperationEvent(t, entryPointV6, 0, common.HexToHash("0xaa"), sender, common.Address{}, 7, true),
+		}
+
+		userOps, err := ParseUserOperations(entryPoints, tx, logs)
+		assert.NoError(t, err)
+		assert.Len(t, userOps, 1)
+		assert.Equal(t, sender, userOps[0].Sender)
+		assert.Nil(t, userOps[0].CallData)
+		assert.Nil(t, userOps[0].Beneficiary)
+	})
+
+	t.Run("malformed event", func(t *testing.T) {
+		tx := EthTypes.NewTx(&EthTypes.LegacyTx{To: &entryPointV6})
+		log := userOperationEvent(t, entryPointV6, 2, common.HexToHash("0xaa"), sender, common.Address{}, 7, true)
+		log.Topics = log.Topics[:3]
+
+		_, err := ParseUserOperations(entryPoints, tx, []*EthTypes.Log{log})
+		assert.EqualError(t, err, "could not parse user operation event 2: log has 3 topics, expected 4")
+	})
+}
@@ -15,7 +15,9 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"log"
 	"math/big"
 	"strings"
@@ -27,6 +29,7 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 const (
@@ -100,13 +103,64 @@ func convertTime(time uint64) int64 {
 // Custom UnmarshalJSON for RPCTransaction to populate tx, and extra info.
 func (tx *RPCTransaction) UnmarshalJSON(msg []byte) error {
 	if err := json.Unmarshal(msg, &tx.Tx); err != nil {
+		if !errors.Is(err, EthTypes.ErrTxTypeNotSupported) {
+			return err
+		}
+		decoded, decodeErr := decodeUnsupportedTransaction(msg)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		if decoded == nil {
+			return err
+		}
+		tx.Tx = decoded
+	}
+	if err := json.Unmarshal(msg, &tx.TxExtraInfo); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(msg, &fields); err != nil {
 		return err
 	}
-	return json.Unmarshal(msg, &tx.TxExtraInfo)
+	for key, value := range fields {
+		if standardTransactionFields[key] {
+			continue
+		}
+		if tx.Extensions == nil {
+			tx.Extensions = map[string]interface{}{}
+		}
+		tx.Extensions[key] = value
+	}
+
+	return nil
+}
+
+// decodeUnsupportedTransaction decodes a JSON RPC transaction of a type that
+// go-ethereum doesn't support, like CIP-64 and OP stack deposit transactions,
+// as a supported transaction. It returns nil if the type is unknown.
+func decodeUnsupportedTransaction(msg []byte) (*EthTypes.Transaction, error) {
+	for _, decode := range []func([]byte) (*EthTypes.Transaction, bool, error){
+		decodeFeeCurrencyTransaction,
+		decodeDepositTransaction,
+	} {
+		tx, ok, err := decode(msg)
+		if err != nil || ok {
+			return tx, err
+		}
+	}
+
+	return nil, nil
 }
 
 // UnmarshalJSONMap converts map[string]interface{} into a interface{}.
+// It returns ErrPrecisionLoss if m holds an integer that may have been
+// rounded when it was decoded into a float64.
 func UnmarshalJSONMap(m map[string]interface{}, i interface{}) error {
+	if err := checkJSONNumbers(m); err != nil {
+		return err
+	}
+
 	b, err := json.Marshal(m)
 	if err != nil {
 		return err
@@ -115,15 +169,52 @@ func UnmarshalJSONMap(m map[string]interface{}, i interface{}) error {
 	return json.Unmarshal(b, i)
 }
 
-// marshalJSONMap converts an interface into a map[string]interface{}.
+// MarshalJSONMap converts an interface into a map[string]interface{}.
+// Integers too large for a float64 are kept as json.Number, see BigIntToJSON.
 func MarshalJSONMap(i interface{}) (map[string]interface{}, error) {
+	m, err := decodeJSONMap(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := normalizeJSONNumbers(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// MarshalCanonicalJSONMap converts an interface into a map[string]interface{}
+// that encodes to the same JSON whoever decodes and re-encodes it, for the
+// metadata of /block responses that consumers hash. Integers up to
+// MaxSafeJSONInteger are numbers and larger integers are decimal strings, and
+// object keys are sorted when the map is encoded. Unlike the maps returned by
+// MarshalJSONMap, the maps can't be decoded with UnmarshalJSONMap.
+func MarshalCanonicalJSONMap(i interface{}) (map[string]interface{}, error) {
+	m, err := decodeJSONMap(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := canonicalJSONNumbers(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// decodeJSONMap converts an interface into a map[string]interface{} holding
+// its numbers as json.Number
+func decodeJSONMap(i interface{}) (map[string]interface{}, error) {
 	b, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
 
 	var m map[string]interface{}
-	if err := json.Unmarshal(b, &m); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&m); err != nil {
 		return nil, err
 	}
 
@@ -143,7 +234,7 @@ func Account(address *common.Address) *types.AccountIdentifier {
 		return nil
 	}
 	return &types.AccountIdentifier{
-		Address: address.String(),
+		Address: FormatAddress(*address),
 	}
 }
 
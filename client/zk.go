@@ -0,0 +1,151 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// zkBatchFields are the receipt fields of zk rollups locating the transaction
+// in the L1 batch that commits it, added to the transaction extensions
+var zkBatchFields = []string{"l1BatchNumber", "l1BatchTxIndex"}
+
+// requiredReceiptFields are the defaults of the receipt fields geth requires
+// that zk rollups may omit
+var requiredReceiptFields = map[string]interface{}{
+	"cumulativeGasUsed": hexutil.Uint64(0),
+	"logsBloom":         EthTypes.Bloom{},
+	"logs":              []*EthTypes.Log{},
+}
+
+// knownReceiptFields are the fields of a JSON RPC receipt that are decoded by
+// EthTypes.Receipt
+var knownReceiptFields = map[string]bool{
+	"type":              true,
+	"root":              true,
+	"status":            true,
+	"cumulativeGasUsed": true,
+	"logsBloom":         true,
+	"logs":              true,
+	"transactionHash":   true,
+	"contractAddress":   true,
+	"gasUsed":           true,
+	"effectiveGasPrice": true,
+	"blobGasUsed":       true,
+	"blobGasPrice":      true,
+	"blockHash":         true,
+	"blockNumber":       true,
+	"transactionIndex":  true,
+	"from":              true,
+	"to":                true,
+}
+
+// DecodeReceiptTolerant decodes a JSON RPC receipt like EthTypes.Receipt, but
+// defaults the required receipt fields the receipt omits instead of erroring.
+// The fields that are not part of a standard receipt, like the L1 batch fields
+// of zk rollups, are returned as extra fields.
+func DecodeReceiptTolerant(raw json.RawMessage) (*EthTypes.Receipt, map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, err
+	}
+	extraFields := unknownFields(fields, knownReceiptFields)
+
+	completed, err := withDefaultFields(fields, requiredReceiptFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	var receipt EthTypes.Receipt
+	if err := json.Unmarshal(completed, &receipt); err != nil {
+		return nil, nil, fmt.Errorf("could not decode receipt: %w", err)
+	}
+
+	return &receipt, extraFields, nil
+}
+
+// ZkReceipt returns the receipt of a transaction of a zk rollup from its JSON
+// RPC receipt, for clients of chains with RosettaConfig.RollupType set to
+// configuration.ZKRollupType. zk rollups refund unused gas at a price that can
+// differ from the gas price of the transaction, so the fee is the gas used at
+// the effective gas price of the receipt, plus its L1 fee, if any. The raw
+// receipt is kept in RawMessage for the batch fields, see ApplyZkReceipt.
+func ZkReceipt(raw json.RawMessage) (*RosettaTxReceipt, error) {
+	receipt, extraFields, err := DecodeReceiptTolerant(raw)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.EffectiveGasPrice == nil {
+		return nil, fmt.Errorf("receipt of %s has no effective gas price", receipt.TxHash)
+	}
+
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	fee := new(big.Int).Mul(gasUsed, receipt.EffectiveGasPrice)
+	l1Fee, err := decodeL1Fee(extraFields, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if l1Fee != nil {
+		fee.Add(fee, l1Fee)
+	}
+
+	return &RosettaTxReceipt{
+		Type:           receipt.Type,
+		GasPrice:       receipt.EffectiveGasPrice,
+		GasUsed:        gasUsed,
+		TransactionFee: fee,
+		L1Fee:          l1Fee,
+		Logs:           receipt.Logs,
+		RawMessage:     raw,
+		Status:         receipt.Status,
+		Bloom:          receipt.Bloom,
+		GasPriceSource: ReceiptGasPriceSource,
+	}, nil
+}
+
+// ApplyZkReceipt adjusts tx to the gas semantics of zk rollups once its receipt
+// is loaded. zk rollups don't burn the base fee, which is paid to the operator
+// with the rest of the fee, and the L1 batch fields of the raw receipt are
+// added to the extensions of tx, unless the transaction already has them.
+func ApplyZkReceipt(tx *LoadedTransaction) error {
+	tx.FeeBurned = nil
+	if tx.Receipt == nil {
+		return errors.New("transaction has no receipt")
+	}
+	if len(tx.Receipt.RawMessage) == 0 {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(tx.Receipt.RawMessage, &fields); err != nil {
+		return fmt.Errorf("could not decode receipt: %w", err)
+	}
+	for _, field := range zkBatchFields {
+		value, ok := fields[field]
+		if !ok || value == nil {
+			continue
+		}
+		if _, ok := tx.Extensions[field]; !ok {
+			tx.SetExtension(field, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// zkSyncReceipt is a zkSync Era receipt of an EIP-712 transaction, without a
+// cumulative gas used
+const zkSyncReceipt = `{
+	"type": "0x71",
+	"status": "0x1",
+	"transactionHash": "0x2a4ad3e4c7ae3e8f5c1f1e64a5e3d7d2c54e2f2b0f0d3c3e9c1d1e7a0b6c5d4e",
+	"transactionIndex": "0x0",
+	"blockHash": "0x5a9b1e4d2c3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b",
+	"blockNumber": "0x1d1a7a",
+	"from": "0x36615cf349d7f6344891b1e7ca7c72883f5dc049",
+	"to": "0x000000000000000000000000000000000000800a",
+	"gasUsed": "0x2d0f6",
+	"effectiveGasPrice": "0xee6b280",
+	"logs": [],
+	"logsBloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	"l1BatchNumber": "0x1b5a",
+	"l1BatchTxIndex": "0x12",
+	"l2ToL1Logs": []
+}`
+
+func TestZkReceipt(t *testing.T) {
+	receipt, err := ZkReceipt(json.RawMessage(zkSyncReceipt))
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(0x71), receipt.Type)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, big.NewInt(0x2d0f6), receipt.GasUsed)
+	assert.Equal(t, big.NewInt(0xee6b280), receipt.GasPrice)
+	assert.Equal(t, new(big.Int).Mul(big.NewInt(0x2d0f6), big.NewInt(0xee6b280)), receipt.TransactionFee)
+	assert.JSONEq(t, zkSyncReceipt, string(receipt.RawMessage))
+
+	// The L1 fee of the receipt is added to the fee
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(zkSyncReceipt), &fields))
+	fields["l1Fee"] = "0x64"
+	withL1Fee, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	receipt, err = ZkReceipt(withL1Fee)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		new(big.Int).Add(new(big.Int).Mul(big.NewInt(0x2d0f6), big.NewInt(0xee6b280)), big.NewInt(100)),
+		receipt.TransactionFee,
+	)
+
+	delete(fields, "effectiveGasPrice")
+	withoutPrice, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	_, err = ZkReceipt(withoutPrice)
+	assert.EqualError(
+		t,
+		err,
+		"receipt of 0x2a4ad3e4c7ae3e8f5c1f1e64a5e3d7d2c54e2f2b0f0d3c3e9c1d1e7a0b6c5d4e has no effective gas price",
+	)
+}
+
+func TestDecodeReceiptTolerant(t *testing.T) {
+	receipt, extraFields, err := DecodeReceiptTolerant(json.RawMessage(zkSyncReceipt))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), receipt.CumulativeGasUsed)
+	assert.Equal(t, map[string]json.RawMessage{
+		"l1BatchNumber":  json.RawMessage(`"0x1b5a"`),
+		"l1BatchTxIndex": json.RawMessage(`"0x12"`),
+		"l2ToL1Logs":     json.RawMessage(`[]`),
+	}, extraFields)
+
+	_, _, err = DecodeReceiptTolerant(json.RawMessage(`{"gasUsed":"0x1"}`))
+	assert.ErrorContains(t, err, "could not decode receipt")
+}
+
+func TestApplyZkReceipt(t *testing.T) {
+	receipt, err := ZkReceipt(json.RawMessage(zkSyncReceipt))
+	assert.NoError(t, err)
+	tx := &LoadedTransaction{
+		FeeBurned:  big.NewInt(10),
+		Receipt:    receipt,
+		Extensions: map[string]interface{}{"l1BatchNumber": "0x1b59"},
+	}
+
+	assert.NoError(t, ApplyZkReceipt(tx))
+	assert.Nil(t, tx.FeeBurned)
+	// The batch of the transaction takes precedence over the one of the receipt
+	assert.Equal(t, map[string]interface{}{
+		"l1BatchNumber":  "0x1b59",
+		"l1BatchTxIndex": "0x12",
+	}, tx.Extensions)
+
+	assert.EqualError(t, ApplyZkReceipt(&LoadedTransaction{}), "transaction has no receipt")
+}
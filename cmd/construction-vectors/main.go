@@ -0,0 +1,108 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// construction-vectors generates the construction test vectors of a network,
+// signed and unsigned transactions of every supported transaction type built
+// with the offline construction endpoints, for regression tests with
+// construction.CheckVector, e.g.
+//
+//	go run ./cmd/construction-vectors -config config.json -out vectors.json
+//
+// The configuration is a configuration.Configuration encoded as JSON, as
+// checked by validate-config. Signatures are deterministic, so the vectors
+// only change when the construction endpoints do. Chains whose client
+// overrides the transaction hash must generate their vectors with
+// construction.GenerateVectors and their client instead.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/coinbase/rosetta-geth-sdk/services/construction"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// defaultKey is the well known private key of the sender of the vectors,
+	// which must never hold funds
+	defaultKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+	// defaultRecipient is the recipient of the vectors
+	defaultRecipient = "0xdF7C4fFf31A190E8D46FC9Ba8CdE6aaD8F69Fc76"
+)
+
+func main() {
+	configPath := flag.String("config", "config.json", "configuration of the network")
+	out := flag.String("out", "vectors.json", "file the vectors are written to")
+	keyHex := flag.String("key", defaultKey, "hex private key of the sender")
+	recipient := flag.String("to", defaultRecipient, "address of the recipient")
+	flag.Parse()
+
+	if err := run(*configPath, *out, *keyHex, *recipient); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, out string, keyHex string, recipient string) error {
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", configPath, err)
+	}
+	var cfg configuration.Configuration
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return fmt.Errorf("could not decode %s: %w", configPath, err)
+	}
+	cfg.Mode = configuration.ModeOffline
+	if err := configuration.Validate(&cfg); err != nil {
+		return fmt.Errorf("invalid configuration %s: %w", configPath, err)
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	if !common.IsHexAddress(recipient) {
+		return fmt.Errorf("invalid recipient %s", recipient)
+	}
+
+	specs, err := construction.DefaultVectorSpecs(&cfg, crypto.PubkeyToAddress(key.PublicKey), common.HexToAddress(recipient))
+	if err != nil {
+		return err
+	}
+
+	// The offline endpoints only use the client to hash transactions, which
+	// the SDK client hashes like geth
+	service := construction.NewAPIService(&cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, &client.SDKClient{})
+	vectors, err := construction.GenerateVectors(context.Background(), service, key, specs)
+	if err != nil {
+		return err
+	}
+	if err := construction.SaveVectors(out, vectors); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d vectors to %s\n", len(vectors), out)
+	return nil
+}
@@ -0,0 +1,319 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	ethereum "github.com/coinbase/rosetta-geth-sdk/examples/ethereum/client"
+	"github.com/coinbase/rosetta-geth-sdk/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// blockCommand prints the /block response of a block
+func blockCommand(cfg *configuration.Configuration, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: block <index|hash>")
+	}
+	block, err := parseBlock(args[0])
+	if err != nil {
+		return err
+	}
+
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return err
+	}
+	resp, rosettaErr := services.NewBlockAPIService(cfg, sdkClient).Block(context.Background(), &RosettaTypes.BlockRequest{
+		NetworkIdentifier: cfg.Network,
+		BlockIdentifier:   block,
+	})
+	if rosettaErr != nil {
+		return rosettaError(rosettaErr)
+	}
+
+	return printJSON(resp)
+}
+
+// txCommand prints the /block/transaction response of a transaction, in the
+// block of its receipt
+func txCommand(cfg *configuration.Configuration, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tx <hash>")
+	}
+	hash, err := parseHash(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return err
+	}
+	receipt, err := sdkClient.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("could not get the receipt of %s: %w", hash, err)
+	}
+
+	resp, rosettaErr := services.NewBlockAPIService(cfg, sdkClient).BlockTransaction(
+		ctx,
+		&RosettaTypes.BlockTransactionRequest{
+			NetworkIdentifier: cfg.Network,
+			BlockIdentifier: &RosettaTypes.BlockIdentifier{
+				Index: receipt.BlockNumber.Int64(),
+				Hash:  receipt.BlockHash.Hex(),
+			},
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: hash.Hex()},
+		},
+	)
+	if rosettaErr != nil {
+		return rosettaError(rosettaErr)
+	}
+
+	return printJSON(resp)
+}
+
+// balanceCommand prints the /account/balance response of an account, in the
+// native currency or in an ERC20 token
+func balanceCommand(cfg *configuration.Configuration, args []string) error {
+	flags := flag.NewFlagSet("balance", flag.ContinueOnError)
+	at := flags.String("block", "", "index or hash of the block, the current block by default")
+	token := flags.String("token", "", "address of the ERC20 token, the native currency by default")
+	symbol := flags.String("symbol", "", "symbol of the ERC20 token")
+	decimals := flags.Int("decimals", 18, "decimals of the ERC20 token") // nolint:gomnd
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: balance [-block <index|hash>] [-token <address> -symbol <symbol>] <address>")
+	}
+
+	var block *RosettaTypes.PartialBlockIdentifier
+	if len(*at) > 0 {
+		var err error
+		if block, err = parseBlock(*at); err != nil {
+			return err
+		}
+	}
+
+	currency := cfg.RosettaCfg.Currency
+	if len(*token) > 0 {
+		if !common.IsHexAddress(*token) {
+			return fmt.Errorf("invalid token address %s", *token)
+		}
+		if len(*symbol) == 0 {
+			return errors.New("the symbol of the token is required")
+		}
+		currency = client.Erc20Currency(*symbol, int32(*decimals), common.HexToAddress(*token).Hex())
+	}
+
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return err
+	}
+	accountAPI := services.NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, sdkClient)
+	resp, rosettaErr := accountAPI.AccountBalance(context.Background(), &RosettaTypes.AccountBalanceRequest{
+		NetworkIdentifier: cfg.Network,
+		AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: flags.Arg(0)},
+		BlockIdentifier:   block,
+		Currencies:        []*RosettaTypes.Currency{currency},
+	})
+	if rosettaErr != nil {
+		return rosettaError(rosettaErr)
+	}
+
+	return printJSON(resp)
+}
+
+// traceCommand prints the trace of a transaction returned by the node and the
+// calls flattened from it
+func traceCommand(cfg *configuration.Configuration, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: trace <hash>")
+	}
+	hash, err := parseHash(args[0])
+	if err != nil {
+		return err
+	}
+
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return err
+	}
+	raw, calls, err := sdkClient.TraceTransaction(context.Background(), hash)
+	if err != nil {
+		return fmt.Errorf("could not trace %s: %w", hash, err)
+	}
+
+	return printJSON(struct {
+		Trace json.RawMessage    `json:"trace"`
+		Calls []*client.FlatCall `json:"calls"`
+	}{raw, calls})
+}
+
+// validateBlockCommand validates a block against its header, and the senders of
+// its transactions against their signatures, by fetching it like the server
+// with trustless block validation failing /block
+func validateBlockCommand(cfg *configuration.Configuration, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: validate-block <index|hash>")
+	}
+	block, err := parseBlock(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg.RosettaCfg.EnableTrustlessBlockValidation = true
+	cfg.RosettaCfg.TrustlessValidationPolicy = configuration.FailValidationPolicy
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return err
+	}
+	resp, rosettaErr := services.NewBlockAPIService(cfg, sdkClient).Block(context.Background(), &RosettaTypes.BlockRequest{
+		NetworkIdentifier: cfg.Network,
+		BlockIdentifier:   block,
+	})
+	if rosettaErr != nil {
+		return rosettaError(rosettaErr)
+	}
+
+	identifier := resp.Block.BlockIdentifier
+	fmt.Printf("block %d %s is valid\n", identifier.Index, identifier.Hash)
+	return nil
+}
+
+// diffBlockCommand prints the divergences between the operations of a block
+// built from the node and from another node, e.g. when on-boarding a provider
+// whose tracer differs
+func diffBlockCommand(cfg *configuration.Configuration, args []string) error {
+	flags := flag.NewFlagSet("diff-block", flag.ContinueOnError)
+	against := flags.String("against", "", "url of the node to compare with")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || len(*against) == 0 {
+		return errors.New("usage: diff-block -against <node url> <index|hash>")
+	}
+	block, err := parseBlock(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	primary, err := fetchBlock(cfg, block)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cfg.GethURL, err)
+	}
+	// Hash queries select the same block on both nodes
+	block = &RosettaTypes.PartialBlockIdentifier{Hash: &primary.BlockIdentifier.Hash}
+
+	secondaryCfg := *cfg
+	secondaryCfg.GethURL = *against
+	secondary, err := fetchBlock(&secondaryCfg, block)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *against, err)
+	}
+
+	report, err := services.DiffBlockOperations(primary, secondary)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if len(report.Divergences) > 0 {
+		return fmt.Errorf("%d divergences", len(report.Divergences))
+	}
+	return nil
+}
+
+// fetchBlock returns the /block response of a block from the node of cfg
+func fetchBlock(
+	cfg *configuration.Configuration,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*RosettaTypes.Block, error) {
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	resp, rosettaErr := services.NewBlockAPIService(cfg, sdkClient).Block(context.Background(), &RosettaTypes.BlockRequest{
+		NetworkIdentifier: cfg.Network,
+		BlockIdentifier:   block,
+	})
+	if rosettaErr != nil {
+		return nil, rosettaError(rosettaErr)
+	}
+
+	return resp.Block, nil
+}
+
+// parseBlock parses a block index or hash
+func parseBlock(arg string) (*RosettaTypes.PartialBlockIdentifier, error) {
+	if strings.HasPrefix(arg, "0x") {
+		hash, err := parseHash(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &RosettaTypes.PartialBlockIdentifier{Hash: RosettaTypes.String(hash.Hex())}, nil
+	}
+
+	index, err := strconv.ParseInt(arg, 10, 64) // nolint:gomnd
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("invalid block %s, expected an index or a hash", arg)
+	}
+	return &RosettaTypes.PartialBlockIdentifier{Index: &index}, nil
+}
+
+// parseHash parses a 32 bytes hash
+func parseHash(arg string) (common.Hash, error) {
+	raw, err := hexutil.Decode(arg)
+	if err != nil || len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid hash %s", arg)
+	}
+	return common.BytesToHash(raw), nil
+}
+
+// rosettaError returns rosettaErr as an error
+func rosettaError(rosettaErr *RosettaTypes.Error) error {
+	msg := fmt.Sprintf("rosetta error %d: %s", rosettaErr.Code, rosettaErr.Message)
+	if rosettaErr.Description != nil {
+		msg += ": " + *rosettaErr.Description
+	}
+	if len(rosettaErr.Details) > 0 {
+		details, _ := json.Marshal(rosettaErr.Details)
+		msg += " " + string(details)
+	}
+	return errors.New(msg)
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
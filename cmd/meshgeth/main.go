@@ -0,0 +1,119 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// meshgeth inspects blocks, transactions and accounts of a node with the same
+// client, services and validator as the server, so operators can reproduce the
+// parsing and validation of production from the command line, e.g.
+//
+//	go run ./cmd/meshgeth -config config.json block 0x5e1b...
+//	go run ./cmd/meshgeth -config config.json -url http://localhost:8545 validate-block 17000000
+//
+// The configuration is a configuration.Configuration encoded as JSON, as
+// checked by validate-config. Results are printed as JSON.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+)
+
+const usage = `usage: %s [-config <config.json>] [-url <node url>] <command> [arguments]
+
+commands:
+  block <index|hash>            the /block response of the block
+  tx <hash>                     the /block/transaction response of the transaction
+  balance [flags] <address>     the /account/balance response of the account
+  trace <hash>                  the trace of the transaction and its flattened calls
+  validate-block <index|hash>   validates the block against its header like the
+                                server with trustless block validation
+  diff-block -against <node url> <index|hash>
+                                the divergences between the operations of the
+                                block built from the node and from another node
+`
+
+// commands are the subcommands, by name
+var commands = map[string]func(cfg *configuration.Configuration, args []string) error{
+	"block":          blockCommand,
+	"tx":             txCommand,
+	"balance":        balanceCommand,
+	"trace":          traceCommand,
+	"validate-block": validateBlockCommand,
+	"diff-block":     diffBlockCommand,
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	configPath := flag.String("config", "config.json", "configuration of the network")
+	url := flag.String("url", "", "node url, instead of the geth url of the configuration")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2) // nolint:gomnd
+	}
+	command, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2) // nolint:gomnd
+	}
+
+	cfg, err := loadConfiguration(*configPath, *url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := command(cfg, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+// loadConfiguration reads the configuration at path for an online service,
+// with the node at url if set
+func loadConfiguration(path string, url string) (*configuration.Configuration, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	var cfg configuration.Configuration
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+	cfg.Mode = configuration.ModeOnline
+	if len(url) > 0 {
+		cfg.GethURL = url
+	}
+
+	// The currency store can be locked by a running server, and the block
+	// cache is useless for a single request
+	cfg.RosettaCfg.CurrencyStorePath = ""
+	cfg.RosettaCfg.BlockCacheSize = 0
+	cfg.RosettaCfg.BlockPrefetchDepth = 0
+
+	if err := configuration.Validate(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
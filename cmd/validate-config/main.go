@@ -0,0 +1,62 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// validate-config checks a configuration.Configuration encoded as JSON and
+// prints all its problems, e.g.
+//
+//	go run ./cmd/validate-config config.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+)
+
+func main() {
+	if len(os.Args) != 2 { // nolint:gomnd
+		fmt.Fprintf(os.Stderr, "usage: %s <config.json>\n", os.Args[0])
+		os.Exit(2) // nolint:gomnd
+	}
+
+	raw, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+
+	var cfg configuration.Configuration
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "could not decode %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+
+	err = configuration.Validate(&cfg)
+	if err == nil {
+		fmt.Printf("%s is valid\n", os.Args[1])
+		return
+	}
+
+	problems := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		problems = joined.Unwrap()
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	fmt.Fprintf(os.Stderr, "%s has %d problems\n", os.Args[1], len(problems))
+	os.Exit(1)
+}
@@ -15,9 +15,13 @@
 package configuration
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/big"
+	"time"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -73,21 +77,159 @@ type RosettaConfig struct {
 	// Peers retrieving is used in Rosetta /network/status api
 	SupportsPeering bool
 
+	// HasUncles indicates if the blockchain has uncle blocks that are loaded with
+	// eth_getUncleByBlockHashAndIndex for reward transactions. Chains without proof
+	// of work uncles like OP stack chains, Arbitrum and Polygon PoS don't. When nil,
+	// it is detected at startup.
+	HasUncles *bool
+
+	// HasAdminRPC indicates if the node serves the admin namespace, e.g. admin_peers.
+	// When nil, it is detected at startup.
+	HasAdminRPC *bool
+
 	// SupportsBlockAuthor indicates if blockchain supports author
 	SupportsBlockAuthor bool
 
+	// ConsensusEngine is the consensus engine of the chain, when the default client
+	// implementation depends on it. The options are: CliqueConsensusEngine, with
+	// which the block author is the clique signer recovered from the header seal,
+	// and IBFTConsensusEngine, QBFTConsensusEngine and IstanbulConsensusEngine,
+	// with which the block author is the proposer of the block, its proposer and
+	// validator set are added to the block metadata and block hashes exclude the
+	// committed seals of the extra data.
+	// It defaults to CliqueConsensusEngine when ChainConfig has a clique config.
+	ConsensusEngine string
+
+	// ChainProfile enables the built-in handling of a chain. The options are:
+	// BSCChainProfile, with which the system transactions of BNB Smart Chain are
+	// classified and those that move no value are dropped, see
+	// client.BSCTransactionFilter. A TransactionFilter implemented by the client
+	// takes precedence over the one of the profile.
+	ChainProfile string
+
+	// RollupType enables the compatibility mode of a kind of rollup. The options
+	// are: ZKRollupType, with which the base fee is not burned and the L1 batch
+	// fields of receipts are added to the transaction metadata, see
+	// client.ApplyZkReceipt. Clients decode the receipts of zk rollups with
+	// client.ZkReceipt.
+	RollupType string
+
+	// BridgeEvents describe the events of bridge contracts from which the default
+	// PopulateCrossChainTransactions populates cross-chain transactions, so bridges
+	// whose transfers are not visible in traces, like the message services of
+	// some zk rollups, are supported without a custom client
+	BridgeEvents []BridgeEvent
+
+	// Precompiles are the precompiles moving the native currency without calls
+	// visible to the call tracer, like the staking precompiles of some chains.
+	// The operations of their transfers are synthesized from their calls and
+	// logs by their decoder, see client.PrecompileDecoder.
+	Precompiles []Precompile
+
+	// DecodeUserOperations adds a USER_OPERATION operation for every ERC-4337
+	// user operation bundled in a transaction, decoded from the
+	// UserOperationEvent logs of the EntryPoint contracts and the handleOps
+	// calldata of the bundle, see client.ParseUserOperations
+	DecodeUserOperations bool
+
+	// EntryPointAddresses are the ERC-4337 EntryPoint contracts whose user
+	// operations are decoded. Empty means DefaultEntryPointAddresses.
+	EntryPointAddresses []string
+
+	// UseEVMTransferAnnotations parses the fee operations of transactions from
+	// the beforeEVMTransfers and afterEVMTransfers annotations of their call
+	// traces instead of their receipts. Arbitrum Nitro tracers annotate the
+	// transfers the EVM doesn't execute, like gas prepayments and refunds, this
+	// way. It requires a geth trace type, see services.ParseFeeOps.
+	UseEVMTransferAnnotations bool
+
 	// SupportsEIP1559 indicates if the blockchain supports EIP-1559
 	SupportsEIP1559 bool
 
-	// SupportsOpStack indicates if the blockchain supports OP stack
+	// AutoSelectFeeMode indicates whether construction builds dynamic fee (type 2)
+	// transactions when London is active at the head according to ChainConfig and
+	// the head has a base fee, and legacy transactions otherwise, instead of
+	// following SupportsEIP1559. Requests can still select the fee mode explicitly.
+	AutoSelectFeeMode bool
+
+	// SupportsOpStack indicates if the blockchain is an OP stack chain. Deposit
+	// transactions are decoded and pay no fee, and the fee of other transactions
+	// is paid to the fee vaults of the chain instead of the miner and burned,
+	// see client.ApplyOPStackReceipt and services.OPStackFeeOps. Clients decode
+	// the receipts of OP stack chains with client.OPStackReceipt.
 	SupportsOpStack bool
 
+	// BedrockBlock is the first block of an OP stack chain after the bedrock upgrade,
+	// e.g. OptimismBedrockBlock. The hashes of earlier blocks are fetched from the node
+	// since their migrated headers don't hash to the legacy block hash.
+	BedrockBlock *big.Int
+
 	// Currency is the native currency blockchain supports
 	Currency *RosettaTypes.Currency
 
+	// GasCurrency is the currency fees are paid in, for chains with a custom gas
+	// token like some OP stack forks. It denominates fee operations, suggested fees
+	// and construction metadata. When it isn't set, fee operations use ETH and
+	// suggested fees use Currency.
+	GasCurrency *RosettaTypes.Currency
+
+	// SupportsWithdrawals adds a transaction with a WITHDRAWAL operation for
+	// every EIP-4895 withdrawal of a block, the validator withdrawals and
+	// staking rewards pushed by the consensus layer through the Engine API
+	SupportsWithdrawals bool
+
+	// WithdrawalCurrency is the currency of withdrawal operations, for chains
+	// whose staking rewards are paid in another token than Currency. Defaults
+	// to Currency.
+	WithdrawalCurrency *RosettaTypes.Currency
+
+	// WithdrawalMultiplier converts the withdrawal amounts of blocks, in gwei on
+	// Ethereum, to the smallest unit of WithdrawalCurrency. Defaults to
+	// DefaultWithdrawalMultiplier.
+	WithdrawalMultiplier *big.Int
+
+	// ReferenceCurrency is the currency, e.g. USD, suggested fees are also
+	// reported in by /construction/metadata for wallet UIs. Fees are priced by
+	// a client implementing client.PriceOracle, or by ChainlinkPriceFeeds and
+	// StaticPrices.
+	ReferenceCurrency *RosettaTypes.Currency
+
+	// ChainlinkPriceFeeds are the Chainlink aggregators pricing currencies in
+	// ReferenceCurrency, keyed by currency symbol, e.g. the ETH / USD feed
+	ChainlinkPriceFeeds map[string]string
+
+	// MaxPriceAge is how old the last round of a Chainlink feed can be. Older
+	// prices are not used. Zero accepts any round.
+	MaxPriceAge time.Duration
+
+	// StaticPrices are the prices of currencies in ReferenceCurrency, keyed by
+	// currency symbol, as decimal numbers like "3125.42". They are used when a
+	// currency has no Chainlink feed or its feed can't be read.
+	StaticPrices map[string]string
+
+	// BalanceExemptions are the accounts and currencies whose balances can change
+	// without operations, like the rebasing native yield of Blast, declared in
+	// /network/options so tools like rosetta-cli skip them during reconciliation
+	BalanceExemptions []*RosettaTypes.BalanceExemption
+
 	// TracePrefix is the prefix appended to trace RPC calls
 	TracePrefix string
 
+	// CustomTracer overrides the call tracer of the geth trace types. It is the
+	// name of a native tracer or the body of a JS tracer, e.g. one capturing the
+	// transfers of precompiles. Its result must decode into client.Call, unless
+	// the client maps it with a client.TraceDecoder.
+	CustomTracer string
+
+	// CustomTracerConfig is the tracerConfig passed to CustomTracer, e.g.
+	// {"onlyTopCall": true} for the native call tracer
+	CustomTracerConfig json.RawMessage
+
+	// CustomTracerPath is the path of a JS tracer file overriding the call
+	// tracer of the geth trace types, read at startup. The JS call tracer of
+	// GethJsTrace is embedded in the SDK and doesn't need a path.
+	CustomTracerPath string
+
 	// IngestionMode indicates if blockchain ingestion mode
 	IngestionMode string
 
@@ -100,13 +242,48 @@ type RosettaConfig struct {
 	// TokenWhiteList is a list of ERC20 tokens we only support
 	TokenWhiteList []Token
 
+	// TokenWhiteListURL is the location of a token white list that is loaded at
+	// runtime and replaces TokenWhiteList: an http(s) URL or a file path serving
+	// a JSON array of tokens, see client.TokenWhiteListSource.
+	TokenWhiteListURL string
+
+	// TokenWhiteListRefreshInterval is how often the token white list of
+	// TokenWhiteListURL is refreshed. It defaults to
+	// DefaultTokenWhiteListRefreshInterval.
+	TokenWhiteListRefreshInterval time.Duration
+
+	// TokenMetadataOverrides is a list of ERC20 tokens whose symbol and decimals are
+	// used instead of the ones returned by the token contract, e.g. for tokens with
+	// non standard symbol() or decimals() methods
+	TokenMetadataOverrides []Token
+
 	// UseTokenWhiteListMetadata indicates whether we use token metadata from token white list or fetch from nodes
 	UseTokenWhiteListMetadata bool
 
+	// TokenDecimalsPolicy enables the verification of the decimals of the token white
+	// list against the decimals() of the token contracts, at startup and whenever a
+	// token currency is fetched from the node. The options are: FailTokenDecimalsPolicy,
+	// which fails on mismatches, and CorrectTokenDecimalsPolicy, which logs mismatches
+	// and uses the decimals of the contract. Decimals are not verified by default.
+	TokenDecimalsPolicy string
+
+	// AddressPolicies change how the transactions touching specific addresses are
+	// parsed, for the common cases that would otherwise require implementing
+	// SkipTxReceiptParsing or ParseOps in the client
+	AddressPolicies []AddressPolicy
+
 	// DefaultBlockNumber is the default block number if block identifier is not specified
 	// This is mainly used for Optimism and Base, it can be "safe" or "finalized" to avoid reorg issues
+	//
+	// Deprecated: use DefaultBlockTag instead.
 	DefaultBlockNumber string
 
+	// DefaultBlockTag is the block used when a block identifier is not specified, for
+	// balances, the network status, the current block and base fees. Chains with
+	// frequent shallow reorgs like Base, Optimism and Polygon can use SafeBlockTag
+	// or FinalizedBlockTag. Defaults to LatestBlockTag.
+	DefaultBlockTag BlockTag
+
 	// BaseFeeFloor is the floor base fee for EIP-1559
 	BaseFeeFloor *big.Int
 
@@ -116,6 +293,15 @@ type RosettaConfig struct {
 	// PriorityFeeDivisor is the divisor of priority fee for EIP-1559
 	PriorityFeeDivisor *big.Int
 
+	// PriorityFeeFloor is the minimum priority fee (gas tip cap) for EIP-1559, for
+	// chains that reject transactions with zero tips or have sequencer minimums.
+	// Suggested tips are raised to it and lower explicit tips are rejected.
+	PriorityFeeFloor *big.Int
+
+	// PriorityFeeCap is the maximum priority fee (gas tip cap) for EIP-1559.
+	// Suggested tips are lowered to it and higher explicit tips are rejected.
+	PriorityFeeCap *big.Int
+
 	// SupportCustomizedTraceConfig indicates if the blockchain supports customized trace config
 	SupportCustomizedTraceConfig bool
 
@@ -125,12 +311,221 @@ type RosettaConfig struct {
 	// SupportCustomizedBlockBody indicates if the blockchain supports customized block body
 	SupportCustomizedBlockBody bool
 
+	// TolerantHeaderDecoding indicates whether block headers missing fields required by
+	// geth are decoded with default values instead of erroring. Non standard header
+	// fields are added to the block metadata, and clients implementing
+	// client.HeaderMapper can map them into the header.
+	TolerantHeaderDecoding bool
+
+	// BlockMetadataFields are the header fields added to the metadata of /block
+	// responses, so consumers displaying blocks don't need another connection to
+	// the node. The fields are GasUsedBlockMetadataField,
+	// GasLimitBlockMetadataField, BaseFeeBlockMetadataField,
+	// SizeBlockMetadataField, ExtraDataBlockMetadataField and
+	// MixHashBlockMetadataField, which are also their metadata keys.
+	BlockMetadataFields []string
+
+	// TraceMetadataMaxDepth, TraceMetadataMaxEntries and TraceMetadataMaxBytes
+	// bound the trace list of the transaction metadata, which can reach
+	// megabytes for deeply nested calls. Calls nested deeper than the depth are
+	// left out, and the list ends before the entry exceeding the number of
+	// entries or the size of their JSON encoding. Truncated traces are marked
+	// in the metadata, and the operations are still parsed from every call.
+	// Zero means no limit.
+	TraceMetadataMaxDepth   int
+	TraceMetadataMaxEntries int
+	TraceMetadataMaxBytes   int
+
+	// IncludeReceiptMetadata and IncludeTraceMetadata indicate if the receipt and
+	// the trace list are added to the transaction metadata, which is most of the
+	// size of /block responses. Consumers only reading operations can leave them
+	// out. When nil, they are added.
+	IncludeReceiptMetadata *bool
+	IncludeTraceMetadata   *bool
+
+	// IncludeRawTrace indicates if the trace returned by the node is added as is
+	// to the metadata of /block/transaction responses. Blocks are traced at
+	// once, so the transactions of /block responses have no raw trace.
+	IncludeRawTrace bool
+
 	// SupportHeaderForwarding indicates if rosetta should forward rosetta request headers to the
 	// native node, and forward native node response headers to the rosetta caller
 	SupportHeaderForwarding bool
 
 	// ForwardHeaders is the list of headers to forward to and from the native node
 	ForwardHeaders []string
+
+	// OldestBlockMethod is a JSON RPC method without parameters returning the number
+	// of the oldest block the node serves, for clients that expose it. When unset,
+	// the oldest block of pruned nodes is found with a binary search.
+	OldestBlockMethod string
+
+	// SyncStalenessThreshold is the age of the current block of the node beyond
+	// which /network/status reports the node as stale instead of synced, for
+	// nodes claiming to be synced while their head doesn't move. Zero disables
+	// the check.
+	SyncStalenessThreshold time.Duration
+
+	// CompressResponses indicates if the responses of requests accepting gzip
+	// are compressed, which divides the size of /block responses several times.
+	// Other encodings like zstd are not supported.
+	CompressResponses bool
+
+	// CompressRPCRequests indicates if the bodies of the JSON RPC requests to the
+	// node are compressed with gzip, for providers accepting compressed requests.
+	// Compressed responses of the node are always accepted.
+	CompressRPCRequests bool
+
+	// RequestTimeout is the time budget of a Rosetta API request. The request context
+	// is cancelled when it runs out, which aborts the remaining node calls. Zero means
+	// requests only end when the caller goes away.
+	RequestTimeout time.Duration
+
+	// UpstreamCallTimeout is the timeout of a single JSON RPC call or batch to the node,
+	// capped by the remaining time budget of the request. Zero means calls are only
+	// limited by the request.
+	UpstreamCallTimeout time.Duration
+
+	// SlowCallThreshold is the duration beyond which a JSON RPC call or batch to
+	// the node is logged as slow and kept for the slow call report of the
+	// get_slow_calls /call method. Zero disables slow call logging, except for
+	// the methods of SlowCallThresholds.
+	SlowCallThreshold time.Duration
+
+	// SlowCallThresholds overrides SlowCallThreshold for JSON RPC methods, e.g. a
+	// higher threshold for debug_traceBlockByHash on trace heavy chains
+	SlowCallThresholds map[string]time.Duration
+
+	// SlowCallReportSize is the number of slowest calls kept for the slow call
+	// report. Zero means DefaultSlowCallReportSize.
+	SlowCallReportSize int
+
+	// ConcurrencyLimits limits the concurrent requests to Rosetta endpoints, keyed
+	// by endpoint path such as "/block". Requests to endpoints without a limit are
+	// never queued or rejected.
+	ConcurrencyLimits map[string]ConcurrencyLimit
+
+	// BlockCacheSize is the number of populated /block responses cached by block hash,
+	// for indexers re-requesting recent blocks. Cached blocks are dropped when a
+	// conflicting block is observed at their height. Zero disables the cache.
+	BlockCacheSize int
+
+	// BlockCacheTTL is how long a block stays in the block cache. Zero means blocks
+	// only leave the cache when they are evicted or invalidated.
+	BlockCacheTTL time.Duration
+
+	// BlockPrefetchDepth is the number of blocks after the highest block
+	// requested from /block that are fetched in the background into the block
+	// cache, so that sequential indexers don't wait for their traces and
+	// receipts. Blocks requested by index are then also served from the block
+	// cache, once their hash is checked against the node. Zero disables the
+	// prefetching. It requires BlockCacheSize to be at least the depth.
+	BlockPrefetchDepth int
+
+	// CurrencyStorePath is the directory of a LevelDB database persisting the
+	// currencies of the token contracts fetched from the node, so they are not
+	// fetched again after a restart. The currencies are only kept in memory when
+	// it is empty.
+	CurrencyStorePath string
+
+	// CurrencyStoreTTL is how long a currency of the currency store is used
+	// before it is fetched again from the node. Zero means stored currencies are
+	// never fetched again.
+	CurrencyStoreTTL time.Duration
+
+	// MaxBatchSize is the maximum total weight of a single JSON RPC batch request.
+	// Larger batches are split into several requests, which is needed for node
+	// providers that reject big batches. Zero means batches are never split.
+	MaxBatchSize int
+
+	// BatchMethodWeights is the weight of a JSON RPC method when counted against
+	// MaxBatchSize. Methods that are not listed have a weight of 1.
+	BatchMethodWeights map[string]int
+
+	// AddressChecksum is the checksum format of addresses returned by Rosetta APIs.
+	// The options are: EIP55AddressChecksum (default), EIP1191AddressChecksum which
+	// uses the chain id of ChainConfig, and LowercaseAddressChecksum
+	AddressChecksum string
+
+	// ExtractRevertReasons indicates whether the revert reason of failed transactions
+	// is added to the metadata of their failed operations. The reason is decoded from
+	// the trace output, or from re-executing the transaction at the parent block.
+	ExtractRevertReasons bool
+
+	// EnableTrustlessBlockValidation indicates whether /block responses are validated
+	// against the block header: the header hash, the transactions root and the
+	// receipts root, using the raw receipts of eth_getBlockReceipts
+	EnableTrustlessBlockValidation bool
+
+	// EnableTrustlessAccountValidation indicates whether the native balance of
+	// /account/balance responses is validated against the state root of the block
+	// with eth_getProof
+	EnableTrustlessAccountValidation bool
+
+	// ValidateNearestProvableBlock indicates whether accounts of blocks outside
+	// the proof window of the node are still proven at the nearest provable
+	// block. Validation of those blocks is skipped either way, the balance
+	// proven at the nearest block is only logged.
+	ValidateNearestProvableBlock bool
+
+	// TrustlessValidationPolicy is what happens when trustless validation fails.
+	// The options are: WarnValidationPolicy (default), which logs the failure, and
+	// FailValidationPolicy, which fails the request
+	TrustlessValidationPolicy string
+
+	// TransactionParsePolicy is what happens when the operations of a transaction
+	// of a block can't be parsed. The options are: StrictParsePolicy (default),
+	// which fails the block unless the transaction references malformed
+	// addresses, and LenientParsePolicy, which serves any such transaction
+	// without operations and with the parse error in its metadata, so the rest
+	// of the block is still served. Failures to query the node, such as
+	// timeouts, fail the block with either policy, and blocks served with
+	// transactions that could not be parsed are never cached.
+	TransactionParsePolicy string
+
+	// SignerValidationRoutines is the number of goroutines recovering transaction
+	// senders during trustless validation. Defaults to DefaultSignerValidationRoutines.
+	SignerValidationRoutines int
+
+	// SignerValidationFailFast indicates whether sender validation stops at the first
+	// mismatch instead of reporting the mismatches of all transactions
+	SignerValidationFailFast bool
+
+	// AllowedChainIDs are the chain ids, other than the chain id of ChainConfig, that
+	// /construction/payloads requests can select with the chain_id metadata. This
+	// lets a single offline deployment construct transactions for several networks.
+	// The node only provides the metadata of its own chain, so /construction/preprocess
+	// rejects them and callers supply the nonce and fees of the payloads themselves.
+	// Requests without the override must be for the configured network.
+	AllowedChainIDs []uint64
+
+	// CheckSubmittedTransactions indicates whether /construction/submit looks up a
+	// transaction by hash before submitting it. Transactions the node already knows,
+	// pending or included, are not resubmitted.
+	CheckSubmittedTransactions bool
+
+	// SubmitValidateOnly makes /construction/submit check signed transactions
+	// against the latest block instead of submitting them, and return the
+	// construction.SubmissionReport in the response metadata. It lets callers
+	// verify transactions signed elsewhere, e.g. imported from another wallet,
+	// with a deployment that never broadcasts.
+	SubmitValidateOnly bool
+
+	// ServerSideSigning enables the sign_and_submit /call method, which signs,
+	// combines and submits the unsigned transactions of /construction/payloads
+	// sent by the account of the server signer, for custodial deployments. The
+	// method has no authentication: any caller of /call can spend the funds of
+	// the account, so the server must never be exposed publicly and must only
+	// be reachable by trusted callers. The signer is provided by clients
+	// implementing signer.Provider, e.g. with a key management service, or read
+	// from SignerKeystorePath.
+	ServerSideSigning bool
+
+	// SignerKeystorePath is the encrypted JSON keystore file of the server
+	// signer, decrypted with the passphrase in the environment variable
+	// SignerKeystorePassphraseEnv
+	SignerKeystorePath          string
+	SignerKeystorePassphraseEnv string
 }
 
 type Token struct {
@@ -139,6 +534,89 @@ type Token struct {
 	Name     string `json:"name"`
 	Symbol   string `json:"symbol"`
 	Decimals uint64 `json:"decimals"`
+
+	// NonStandardTransfers marks tokens whose balances don't change by the value
+	// of their Transfer events, like fee-on-transfer and rebasing tokens
+	NonStandardTransfers bool `json:"nonStandardTransfers,omitempty"`
+}
+
+// AddressPolicy is how the transactions touching an address are parsed
+type AddressPolicy struct {
+	Address string `json:"address"`
+
+	// SkipLogs skips the ERC20 operations of the logs emitted by the address
+	SkipLogs bool `json:"skipLogs,omitempty"`
+
+	// SkipTraces skips the operations of the trace calls to the address
+	SkipTraces bool `json:"skipTraces,omitempty"`
+
+	// OperationTypes renames the types of the operations of the address. The
+	// new types must be in the operation types of the asserter.
+	OperationTypes map[string]string `json:"operationTypes,omitempty"`
+}
+
+// BridgeEvent describes the event a bridge contract emits for each transfer of
+// a cross-chain message, see client.BridgeEventParser
+type BridgeEvent struct {
+	// Contract is the address of the bridge contract emitting the event
+	Contract string `json:"contract"`
+
+	// Event is the signature of the event with the names of its arguments, e.g.
+	// "MessageClaimed(bytes32 indexed messageHash, address indexed to, uint256 value)"
+	Event string `json:"event"`
+
+	// Direction is DepositBridgeDirection when the transfer credits Account, and
+	// WithdrawalBridgeDirection when it debits Account
+	Direction string `json:"direction"`
+
+	// Account is the name of the address argument of the event holding the
+	// account of the transfer
+	Account string `json:"account"`
+
+	// Amount is the name of the integer argument of the event holding the
+	// amount of the transfer
+	Amount string `json:"amount"`
+
+	// OperationType is the type of the operations of the transfers, which must
+	// be in the operation types of the asserter. It defaults to
+	// types.BridgeDepositOpType or types.BridgeWithdrawalOpType.
+	OperationType string `json:"operationType,omitempty"`
+
+	// Currency is the currency of the transfers. It defaults to the native
+	// currency.
+	Currency *RosettaTypes.Currency `json:"currency,omitempty"`
+}
+
+// Precompile is a precompile whose transfers are synthesized by a decoder, see
+// RosettaConfig.Precompiles
+type Precompile struct {
+	// Address is the address of the precompile
+	Address string `json:"address"`
+
+	// Decoder is the name of the decoder of the precompile, registered with
+	// client.RegisterPrecompileDecoder
+	Decoder string `json:"decoder"`
+}
+
+// ConcurrencyLimit limits the concurrent requests to a Rosetta endpoint. Requests
+// beyond MaxConcurrent wait in a queue of QueueDepth requests, and requests that
+// don't fit in the queue fail with a retriable error.
+type ConcurrencyLimit struct {
+	MaxConcurrent int `json:"maxConcurrent"`
+	QueueDepth    int `json:"queueDepth"`
+}
+
+// BlockTag is a block parameter of the JSON RPC API that is not a block number
+type BlockTag string
+
+// Validate returns an error if the block tag is not supported
+func (t BlockTag) Validate() error {
+	switch t {
+	case "", LatestBlockTag, SafeBlockTag, FinalizedBlockTag:
+		return nil
+	default:
+		return fmt.Errorf("unsupported block tag %s", t)
+	}
 }
 
 // Mode is the setting that determines if
@@ -164,8 +642,206 @@ const (
 	DefaultBaseFeeFloor       = 0
 	DefaultBaseFeeMultiplier  = 1
 	DefaultPriorityFeeDivisor = 1
+
+	DefaultBatchMethodWeight = 1
+
+	// OptimismBedrockBlock is the bedrock upgrade block of OP Mainnet
+	OptimismBedrockBlock = 105235063
+
+	LatestBlockTag    BlockTag = "latest"
+	SafeBlockTag      BlockTag = "safe"
+	FinalizedBlockTag BlockTag = "finalized"
+
+	DefaultSignerValidationRoutines = 10
+
+	WarnValidationPolicy = "warn"
+	FailValidationPolicy = "fail"
+
+	StrictParsePolicy  = "strict"
+	LenientParsePolicy = "lenient"
+
+	FailTokenDecimalsPolicy    = "fail"
+	CorrectTokenDecimalsPolicy = "correct"
+
+	CliqueConsensusEngine = "clique"
+	// IBFTConsensusEngine is the IBFT 2.0 engine of Besu
+	IBFTConsensusEngine = "ibft"
+	// QBFTConsensusEngine is the QBFT engine of Besu and GoQuorum
+	QBFTConsensusEngine = "qbft"
+	// IstanbulConsensusEngine is the legacy IBFT engine of GoQuorum
+	IstanbulConsensusEngine = "istanbul"
+
+	BSCChainProfile = "bsc"
+
+	ZKRollupType = "zk"
+
+	DepositBridgeDirection    = "deposit"
+	WithdrawalBridgeDirection = "withdrawal"
+
+	EIP55AddressChecksum     = "eip55"
+	EIP1191AddressChecksum   = "eip1191"
+	LowercaseAddressChecksum = "lowercase"
+
+	GasUsedBlockMetadataField   = "gas_used"
+	GasLimitBlockMetadataField  = "gas_limit"
+	BaseFeeBlockMetadataField   = "base_fee_per_gas"
+	SizeBlockMetadataField      = "size"
+	ExtraDataBlockMetadataField = "extra_data"
+	// MixHashBlockMetadataField is the mix hash of the header, which is the
+	// prevRandao of blocks after the merge
+	MixHashBlockMetadataField = "mix_hash"
 )
 
+// DefaultTokenWhiteListRefreshInterval is the default refresh interval of the
+// token white list of TokenWhiteListURL
+const DefaultTokenWhiteListRefreshInterval = 5 * time.Minute
+
+// DefaultEntryPointAddresses are the canonical ERC-4337 EntryPoint contracts
+// of versions 0.6 and 0.7
+var DefaultEntryPointAddresses = []string{
+	"0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789",
+	"0x0000000071727De22E5E9d8BAf0edAc6f37da032",
+}
+
+// DefaultWithdrawalMultiplier converts the gwei withdrawal amounts of
+// Ethereum to wei
+var DefaultWithdrawalMultiplier = big.NewInt(params.GWei)
+
+// DefaultSlowCallReportSize is the default number of slowest calls kept for
+// the slow call report
+const DefaultSlowCallReportSize = 50
+
+// DefaultBlock returns the block tag used when a block identifier is not specified
+func (c RosettaConfig) DefaultBlock() BlockTag {
+	if len(c.DefaultBlockTag) != 0 {
+		return c.DefaultBlockTag
+	}
+	if len(c.DefaultBlockNumber) != 0 {
+		return BlockTag(c.DefaultBlockNumber)
+	}
+
+	return LatestBlockTag
+}
+
+// AddressPolicy returns the policy of address, or nil if it has none
+func (c RosettaConfig) AddressPolicy(address common.Address) *AddressPolicy {
+	for i, policy := range c.AddressPolicies {
+		if common.HexToAddress(policy.Address) == address {
+			return &c.AddressPolicies[i]
+		}
+	}
+
+	return nil
+}
+
+// FeeCurrency returns the currency of suggested fees, which is GasCurrency
+// when it is set and Currency otherwise
+func (c RosettaConfig) FeeCurrency() *RosettaTypes.Currency {
+	if c.GasCurrency != nil {
+		return c.GasCurrency
+	}
+
+	return c.Currency
+}
+
+// WithdrawalOpCurrency returns the currency of withdrawal operations, which is
+// WithdrawalCurrency when it is set and Currency otherwise
+func (c RosettaConfig) WithdrawalOpCurrency() *RosettaTypes.Currency {
+	if c.WithdrawalCurrency != nil {
+		return c.WithdrawalCurrency
+	}
+
+	return c.Currency
+}
+
+// WithdrawalAmountMultiplier returns the multiplier of withdrawal amounts,
+// see WithdrawalMultiplier
+func (c RosettaConfig) WithdrawalAmountMultiplier() *big.Int {
+	if c.WithdrawalMultiplier != nil {
+		return c.WithdrawalMultiplier
+	}
+
+	return DefaultWithdrawalMultiplier
+}
+
+// TokenWhiteListRefresh returns the refresh interval of the token white list
+// of TokenWhiteListURL
+func (c RosettaConfig) TokenWhiteListRefresh() time.Duration {
+	if c.TokenWhiteListRefreshInterval > 0 {
+		return c.TokenWhiteListRefreshInterval
+	}
+
+	return DefaultTokenWhiteListRefreshInterval
+}
+
+// SlowCallLoggingEnabled returns true if slow JSON RPC calls to the node are
+// logged, see SlowCallThreshold
+func (c RosettaConfig) SlowCallLoggingEnabled() bool {
+	if c.SlowCallThreshold > 0 {
+		return true
+	}
+	for _, threshold := range c.SlowCallThresholds {
+		if threshold > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SlowCallReportLimit returns the number of slowest calls kept for the slow
+// call report
+func (c RosettaConfig) SlowCallReportLimit() int {
+	if c.SlowCallReportSize > 0 {
+		return c.SlowCallReportSize
+	}
+
+	return DefaultSlowCallReportSize
+}
+
+// EntryPoints returns the ERC-4337 EntryPoint contracts whose user operations
+// are decoded, see DecodeUserOperations
+func (c RosettaConfig) EntryPoints() []common.Address {
+	addresses := c.EntryPointAddresses
+	if len(addresses) == 0 {
+		addresses = DefaultEntryPointAddresses
+	}
+
+	entryPoints := make([]common.Address, 0, len(addresses))
+	for _, address := range addresses {
+		entryPoints = append(entryPoints, common.HexToAddress(address))
+	}
+
+	return entryPoints
+}
+
+// UnclesEnabled returns true if uncle blocks are loaded for reward transactions
+func (c RosettaConfig) UnclesEnabled() bool {
+	return c.SupportRewardTx && (c.HasUncles == nil || *c.HasUncles)
+}
+
+// ReceiptMetadataEnabled returns true if the receipt is added to the
+// transaction metadata, see IncludeReceiptMetadata
+func (c RosettaConfig) ReceiptMetadataEnabled() bool {
+	return c.IncludeReceiptMetadata == nil || *c.IncludeReceiptMetadata
+}
+
+// TraceMetadataEnabled returns true if the trace list is added to the
+// transaction metadata, see IncludeTraceMetadata
+func (c RosettaConfig) TraceMetadataEnabled() bool {
+	return c.IncludeTraceMetadata == nil || *c.IncludeTraceMetadata
+}
+
+// IsZkRollup returns whether the chain is a zk rollup, see RollupType
+func (c RosettaConfig) IsZkRollup() bool {
+	return c.RollupType == ZKRollupType
+}
+
+// PeersEnabled returns true if peers are retrieved with admin_peers
+func (c RosettaConfig) PeersEnabled() bool {
+	return c.SupportsPeering && (c.HasAdminRPC == nil || *c.HasAdminRPC)
+}
+
 // IsOfflineMode returns true if running in offline mode
 func (c Configuration) IsOfflineMode() bool {
 	return c.Mode == ModeOffline
@@ -190,3 +866,22 @@ func (c Configuration) IsAnalyticsMode() bool {
 func (c Configuration) IsTokenListEmpty() bool {
 	return len(c.RosettaCfg.TokenWhiteList) == 0
 }
+
+// Consensus returns the consensus engine of the chain, see ConsensusEngine
+func (c Configuration) Consensus() string {
+	if len(c.RosettaCfg.ConsensusEngine) == 0 && c.ChainConfig != nil && c.ChainConfig.Clique != nil {
+		return CliqueConsensusEngine
+	}
+	return c.RosettaCfg.ConsensusEngine
+}
+
+// IsIstanbulConsensus returns whether the chain uses one of the IBFT or QBFT
+// consensus engines, whose headers carry the validator set and committed seals
+// in their extra data
+func (c Configuration) IsIstanbulConsensus() bool {
+	switch c.Consensus() {
+	case IBFTConsensusEngine, QBFTConsensusEngine, IstanbulConsensusEngine:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensus(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Configuration
+		expected string
+	}{
+		"unset": {
+			cfg: Configuration{ChainConfig: params.MainnetChainConfig},
+		},
+		"configured": {
+			cfg:      Configuration{RosettaCfg: RosettaConfig{ConsensusEngine: CliqueConsensusEngine}},
+			expected: CliqueConsensusEngine,
+		},
+		"clique chain config": {
+			cfg:      Configuration{ChainConfig: &params.ChainConfig{Clique: &params.CliqueConfig{Period: 15}}},
+			expected: CliqueConsensusEngine,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.Consensus())
+		})
+	}
+}
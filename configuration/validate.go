@@ -0,0 +1,429 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxTokenDecimals is the number of decimals of the largest ERC20 amount,
+// 2^256 - 1, that still has an integer part
+const maxTokenDecimals = 77
+
+// Validate checks the consistency of cfg and returns all its problems at once,
+// joined with errors.Join, instead of failing lazily at runtime
+func Validate(cfg *Configuration) error {
+	if cfg == nil {
+		return errors.New("configuration is not set")
+	}
+
+	var problems []error
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if cfg.Mode != ModeOnline && cfg.Mode != ModeOffline {
+		report("mode %q is not %s or %s", cfg.Mode, ModeOnline, ModeOffline)
+	}
+	if cfg.Mode == ModeOnline && len(cfg.GethURL) == 0 {
+		report("geth url is not set in online mode")
+	}
+	if cfg.Network == nil {
+		report("network identifier is not set")
+	}
+
+	var chainID uint64
+	if cfg.ChainConfig == nil || cfg.ChainConfig.ChainID == nil {
+		report("chain config does not have a chain id")
+	} else {
+		if cfg.ChainConfig.ChainID.Sign() <= 0 || !cfg.ChainConfig.ChainID.IsUint64() {
+			report("chain id %s is not a positive 64 bit integer", cfg.ChainConfig.ChainID)
+		} else {
+			chainID = cfg.ChainConfig.ChainID.Uint64()
+		}
+	}
+
+	rosettaCfg := cfg.RosettaCfg
+	for _, allowed := range rosettaCfg.AllowedChainIDs {
+		if allowed == 0 {
+			report("allowed chain id 0 is not a valid chain id")
+		}
+	}
+
+	switch rosettaCfg.TraceType {
+	case GethNativeTrace, GethJsTrace:
+		if len(rosettaCfg.TracePrefix) != 0 {
+			report("trace prefix %q is only used with OpenEthereumTrace", rosettaCfg.TracePrefix)
+		}
+	case OpenEthereumTrace:
+		if len(rosettaCfg.TracePrefix) == 0 {
+			report("trace prefix is required with OpenEthereumTrace, e.g. trace or arbtrace")
+		}
+	default:
+		report("unsupported trace type %d", rosettaCfg.TraceType)
+	}
+	if len(rosettaCfg.CustomTracer) > 0 || len(rosettaCfg.CustomTracerPath) > 0 {
+		if rosettaCfg.TraceType == OpenEthereumTrace {
+			report("custom tracers are not supported with OpenEthereumTrace")
+		}
+		if len(rosettaCfg.CustomTracer) > 0 && len(rosettaCfg.CustomTracerPath) > 0 {
+			report("custom tracer and custom tracer path are mutually exclusive")
+		}
+	}
+	if rosettaCfg.UseEVMTransferAnnotations && rosettaCfg.TraceType == OpenEthereumTrace {
+		report("EVM transfer annotations are not supported with OpenEthereumTrace")
+	}
+
+	if rosettaCfg.Currency == nil {
+		report("native currency is not set")
+	} else {
+		if len(rosettaCfg.Currency.Symbol) == 0 {
+			report("native currency does not have a symbol")
+		}
+		if rosettaCfg.Currency.Decimals < 0 || rosettaCfg.Currency.Decimals > maxTokenDecimals {
+			report("native currency has invalid decimals %d", rosettaCfg.Currency.Decimals)
+		}
+	}
+
+	if rosettaCfg.GasCurrency != nil {
+		if len(rosettaCfg.GasCurrency.Symbol) == 0 {
+			report("gas currency does not have a symbol")
+		}
+		if rosettaCfg.GasCurrency.Decimals < 0 || rosettaCfg.GasCurrency.Decimals > maxTokenDecimals {
+			report("gas currency has invalid decimals %d", rosettaCfg.GasCurrency.Decimals)
+		}
+	}
+	if rosettaCfg.WithdrawalCurrency != nil {
+		if len(rosettaCfg.WithdrawalCurrency.Symbol) == 0 {
+			report("withdrawal currency does not have a symbol")
+		}
+		if rosettaCfg.WithdrawalCurrency.Decimals < 0 || rosettaCfg.WithdrawalCurrency.Decimals > maxTokenDecimals {
+			report("withdrawal currency has invalid decimals %d", rosettaCfg.WithdrawalCurrency.Decimals)
+		}
+	}
+	if rosettaCfg.WithdrawalMultiplier != nil && rosettaCfg.WithdrawalMultiplier.Sign() <= 0 {
+		report("withdrawal multiplier %s is not positive", rosettaCfg.WithdrawalMultiplier)
+	}
+	if (rosettaCfg.WithdrawalCurrency != nil || rosettaCfg.WithdrawalMultiplier != nil) &&
+		!rosettaCfg.SupportsWithdrawals {
+		report("withdrawal currency and multiplier are only used when withdrawals are supported")
+	}
+	if rosettaCfg.ReferenceCurrency != nil {
+		if len(rosettaCfg.ReferenceCurrency.Symbol) == 0 {
+			report("reference currency does not have a symbol")
+		}
+		if rosettaCfg.ReferenceCurrency.Decimals < 0 || rosettaCfg.ReferenceCurrency.Decimals > maxTokenDecimals {
+			report("reference currency has invalid decimals %d", rosettaCfg.ReferenceCurrency.Decimals)
+		}
+	} else if len(rosettaCfg.ChainlinkPriceFeeds) > 0 || len(rosettaCfg.StaticPrices) > 0 {
+		report("price feeds and static prices require a reference currency")
+	}
+	for symbol, feed := range rosettaCfg.ChainlinkPriceFeeds {
+		if !common.IsHexAddress(feed) {
+			report("chainlink price feed %q of %s is not an address", feed, symbol)
+		}
+	}
+	if rosettaCfg.MaxPriceAge < 0 {
+		report("max price age %s is negative", rosettaCfg.MaxPriceAge)
+	}
+	for symbol, price := range rosettaCfg.StaticPrices {
+		if p, ok := new(big.Rat).SetString(price); !ok || p.Sign() <= 0 {
+			report("static price %q of %s is not a positive number", price, symbol)
+		}
+	}
+	if err := asserter.BalanceExemptions(rosettaCfg.BalanceExemptions); err != nil {
+		report("balance exemptions: %w", err)
+	}
+
+	switch rosettaCfg.IngestionMode {
+	case "", StandardIngestion, AnalyticsIngestion:
+	default:
+		report("unsupported ingestion mode %q", rosettaCfg.IngestionMode)
+	}
+	if err := rosettaCfg.DefaultBlock().Validate(); err != nil {
+		report("default block: %w", err)
+	}
+	switch rosettaCfg.AddressChecksum {
+	case "", EIP55AddressChecksum, LowercaseAddressChecksum:
+	case EIP1191AddressChecksum:
+		if chainID == 0 {
+			report("address checksum %s requires a chain id", EIP1191AddressChecksum)
+		}
+	default:
+		report("unsupported address checksum %q", rosettaCfg.AddressChecksum)
+	}
+	switch rosettaCfg.TrustlessValidationPolicy {
+	case "", WarnValidationPolicy, FailValidationPolicy:
+	default:
+		report("unsupported trustless validation policy %q", rosettaCfg.TrustlessValidationPolicy)
+	}
+	switch rosettaCfg.TransactionParsePolicy {
+	case "", StrictParsePolicy, LenientParsePolicy:
+	default:
+		report("unsupported transaction parse policy %q", rosettaCfg.TransactionParsePolicy)
+	}
+	switch rosettaCfg.TokenDecimalsPolicy {
+	case "", FailTokenDecimalsPolicy, CorrectTokenDecimalsPolicy:
+	default:
+		report("unsupported token decimals policy %q", rosettaCfg.TokenDecimalsPolicy)
+	}
+	switch rosettaCfg.ConsensusEngine {
+	case "", CliqueConsensusEngine, IBFTConsensusEngine, QBFTConsensusEngine, IstanbulConsensusEngine:
+	default:
+		report("unsupported consensus engine %q", rosettaCfg.ConsensusEngine)
+	}
+	switch rosettaCfg.ChainProfile {
+	case "", BSCChainProfile:
+	default:
+		report("unsupported chain profile %q", rosettaCfg.ChainProfile)
+	}
+	switch rosettaCfg.RollupType {
+	case "", ZKRollupType:
+	default:
+		report("unsupported rollup type %q", rosettaCfg.RollupType)
+	}
+	for _, field := range rosettaCfg.BlockMetadataFields {
+		switch field {
+		case GasUsedBlockMetadataField, GasLimitBlockMetadataField, BaseFeeBlockMetadataField,
+			SizeBlockMetadataField, ExtraDataBlockMetadataField, MixHashBlockMetadataField:
+		default:
+			report("unsupported block metadata field %q", field)
+		}
+	}
+	if rosettaCfg.TraceMetadataMaxDepth < 0 {
+		report("trace metadata max depth %d is negative", rosettaCfg.TraceMetadataMaxDepth)
+	}
+	if rosettaCfg.TraceMetadataMaxEntries < 0 {
+		report("trace metadata max entries %d is negative", rosettaCfg.TraceMetadataMaxEntries)
+	}
+	if rosettaCfg.TraceMetadataMaxBytes < 0 {
+		report("trace metadata max bytes %d is negative", rosettaCfg.TraceMetadataMaxBytes)
+	}
+	if rosettaCfg.SupportsOpStack {
+		if rosettaCfg.IsZkRollup() {
+			report("OP stack chains are not zk rollups")
+		}
+		if rosettaCfg.UseEVMTransferAnnotations {
+			report("EVM transfer annotations are not supported on OP stack chains")
+		}
+	}
+
+	if rosettaCfg.PriorityFeeFloor != nil && rosettaCfg.PriorityFeeFloor.Sign() < 0 {
+		report("priority fee floor %s is negative", rosettaCfg.PriorityFeeFloor)
+	}
+	if rosettaCfg.PriorityFeeCap != nil && rosettaCfg.PriorityFeeCap.Sign() < 0 {
+		report("priority fee cap %s is negative", rosettaCfg.PriorityFeeCap)
+	}
+	if rosettaCfg.PriorityFeeFloor != nil && rosettaCfg.PriorityFeeCap != nil &&
+		rosettaCfg.PriorityFeeFloor.Cmp(rosettaCfg.PriorityFeeCap) > 0 {
+		report(
+			"priority fee floor %s is above the priority fee cap %s",
+			rosettaCfg.PriorityFeeFloor,
+			rosettaCfg.PriorityFeeCap,
+		)
+	}
+	if rosettaCfg.RequestTimeout < 0 {
+		report("request timeout %s is negative", rosettaCfg.RequestTimeout)
+	}
+	if len(rosettaCfg.SignerKeystorePath) > 0 && !rosettaCfg.ServerSideSigning {
+		report("signer keystore %s is only used with server side signing", rosettaCfg.SignerKeystorePath)
+	}
+	if rosettaCfg.ServerSideSigning && cfg.Mode == ModeOffline {
+		report("server side signing is not supported in offline mode")
+	}
+	if rosettaCfg.SyncStalenessThreshold < 0 {
+		report("sync staleness threshold %s is negative", rosettaCfg.SyncStalenessThreshold)
+	}
+	if rosettaCfg.UpstreamCallTimeout < 0 {
+		report("upstream call timeout %s is negative", rosettaCfg.UpstreamCallTimeout)
+	}
+	if rosettaCfg.SlowCallThreshold < 0 {
+		report("slow call threshold %s is negative", rosettaCfg.SlowCallThreshold)
+	}
+	for method, threshold := range rosettaCfg.SlowCallThresholds {
+		if threshold < 0 {
+			report("slow call threshold %s of %s is negative", threshold, method)
+		}
+	}
+	if rosettaCfg.SlowCallReportSize < 0 {
+		report("slow call report size %d is negative", rosettaCfg.SlowCallReportSize)
+	}
+	for path, limit := range rosettaCfg.ConcurrencyLimits {
+		if limit.MaxConcurrent <= 0 {
+			report("max concurrent requests %d of %s is not positive", limit.MaxConcurrent, path)
+		}
+		if limit.QueueDepth < 0 {
+			report("queue depth %d of %s is negative", limit.QueueDepth, path)
+		}
+	}
+	if rosettaCfg.BlockCacheSize < 0 {
+		report("block cache size %d is negative", rosettaCfg.BlockCacheSize)
+	}
+	if rosettaCfg.BlockCacheTTL < 0 {
+		report("block cache ttl %s is negative", rosettaCfg.BlockCacheTTL)
+	}
+	if rosettaCfg.BlockPrefetchDepth < 0 {
+		report("block prefetch depth %d is negative", rosettaCfg.BlockPrefetchDepth)
+	} else if rosettaCfg.BlockPrefetchDepth > rosettaCfg.BlockCacheSize {
+		report("block prefetch depth %d exceeds the block cache size %d", rosettaCfg.BlockPrefetchDepth, rosettaCfg.BlockCacheSize)
+	}
+	if rosettaCfg.CurrencyStoreTTL < 0 {
+		report("currency store ttl %s is negative", rosettaCfg.CurrencyStoreTTL)
+	}
+	if rosettaCfg.MaxBatchSize < 0 {
+		report("max batch size %d is negative", rosettaCfg.MaxBatchSize)
+	}
+	for method, weight := range rosettaCfg.BatchMethodWeights {
+		if weight <= 0 {
+			report("batch weight %d of %s is not positive", weight, method)
+		}
+	}
+	if rosettaCfg.SignerValidationRoutines < 0 {
+		report("signer validation routines %d is negative", rosettaCfg.SignerValidationRoutines)
+	}
+	if rosettaCfg.FilterTokens && len(rosettaCfg.TokenWhiteList) == 0 && len(rosettaCfg.TokenWhiteListURL) == 0 {
+		report("token filtering is enabled with an empty token white list")
+	}
+	if len(rosettaCfg.TokenWhiteListURL) > 0 {
+		if u, err := url.Parse(rosettaCfg.TokenWhiteListURL); err != nil {
+			report("invalid token white list url: %v", err)
+		} else if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file" {
+			report("unsupported token white list url scheme %q", u.Scheme)
+		}
+	}
+	if rosettaCfg.TokenWhiteListRefreshInterval < 0 {
+		report("token white list refresh interval %s is negative", rosettaCfg.TokenWhiteListRefreshInterval)
+	}
+
+	problems = append(problems, validateTokens("token white list", rosettaCfg.TokenWhiteList, chainID)...)
+	problems = append(problems, validateTokens("token metadata overrides", rosettaCfg.TokenMetadataOverrides, chainID)...)
+
+	seenPolicies := map[common.Address]int{}
+	for i, policy := range rosettaCfg.AddressPolicies {
+		if !common.IsHexAddress(policy.Address) {
+			report("address policy %d: invalid address %q", i, policy.Address)
+			continue
+		}
+		address := common.HexToAddress(policy.Address)
+		if j, ok := seenPolicies[address]; ok {
+			report("address policy %d: address %s is a duplicate of policy %d", i, policy.Address, j)
+		}
+		seenPolicies[address] = i
+		for from, to := range policy.OperationTypes {
+			if len(to) == 0 {
+				report("address policy %d: operation type %s is renamed to an empty type", i, from)
+			}
+		}
+	}
+	for i, event := range rosettaCfg.BridgeEvents {
+		if !common.IsHexAddress(event.Contract) {
+			report("bridge event %d: invalid contract %q", i, event.Contract)
+		}
+		if len(event.Event) == 0 {
+			report("bridge event %d: event signature is not set", i)
+		}
+		switch event.Direction {
+		case DepositBridgeDirection, WithdrawalBridgeDirection:
+		default:
+			report("bridge event %d: unsupported direction %q", i, event.Direction)
+		}
+		if len(event.Account) == 0 || len(event.Amount) == 0 {
+			report("bridge event %d: account and amount arguments are required", i)
+		}
+	}
+	for i, address := range rosettaCfg.EntryPointAddresses {
+		if !common.IsHexAddress(address) {
+			report("entry point %d: invalid address %q", i, address)
+		}
+	}
+	if len(rosettaCfg.EntryPointAddresses) > 0 && !rosettaCfg.DecodeUserOperations {
+		report("entry points are only used when user operations are decoded")
+	}
+	precompiles := map[common.Address]bool{}
+	for i, precompile := range rosettaCfg.Precompiles {
+		if !common.IsHexAddress(precompile.Address) {
+			report("precompile %d: invalid address %q", i, precompile.Address)
+		} else if address := common.HexToAddress(precompile.Address); precompiles[address] {
+			report("precompile %d: duplicate address %s", i, address)
+		} else {
+			precompiles[address] = true
+		}
+		if len(precompile.Decoder) == 0 {
+			report("precompile %d: decoder is not set", i)
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// ValidateTokens checks the entries of the token list named list, like the
+// token white list of the configuration is checked by Validate
+func ValidateTokens(list string, tokens []Token, chainID uint64) error {
+	return errors.Join(validateTokens(list, tokens, chainID)...)
+}
+
+// validateTokens returns the problems of the token entries of list
+func validateTokens(list string, tokens []Token, chainID uint64) []error {
+	var problems []error
+	report := func(i int, format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("%s entry %d: %s", list, i, fmt.Sprintf(format, args...)))
+	}
+
+	seen := map[common.Address]int{}
+	for i, token := range tokens {
+		if !common.IsHexAddress(token.Address) {
+			report(i, "invalid address %q", token.Address)
+		} else {
+			address := common.HexToAddress(token.Address)
+			if !isChecksummed(token.Address) {
+				report(i, "address %s is not checksummed, expected %s", token.Address, address.Hex())
+			}
+			if j, ok := seen[address]; ok {
+				report(i, "address %s is a duplicate of entry %d", token.Address, j)
+			}
+			seen[address] = i
+		}
+
+		if len(token.Symbol) == 0 {
+			report(i, "token %s does not have a symbol", token.Address)
+		}
+		if token.Decimals > maxTokenDecimals {
+			report(i, "token %s has invalid decimals %d", token.Address, token.Decimals)
+		}
+		if token.ChainID != 0 && chainID != 0 && token.ChainID != chainID {
+			report(i, "token %s has chain id %d, expected %d", token.Address, token.ChainID, chainID)
+		}
+	}
+
+	return problems
+}
+
+// isChecksummed returns true if address is EIP-55 checksummed or all lowercase,
+// which carries no checksum
+func isChecksummed(address string) bool {
+	hex := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if hex == strings.ToLower(hex) {
+		return true
+	}
+
+	return "0x"+hex == common.HexToAddress(address).Hex()
+}
@@ -0,0 +1,354 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfiguration() *Configuration {
+	return &Configuration{
+		Mode:        ModeOnline,
+		Network:     &RosettaTypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"},
+		GethURL:     "http://localhost:8545",
+		ChainConfig: params.MainnetChainConfig,
+		RosettaCfg: RosettaConfig{
+			TraceType: GethNativeTrace,
+			Currency:  &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18},
+			TokenWhiteList: []Token{
+				{ChainID: 1, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC", Decimals: 6},
+				{Address: "0xdac17f958d2ee523a2206206994597c13d831ec7", Symbol: "USDT", Decimals: 6},
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		update func(cfg *Configuration)
+
+		expectedErrs []string
+	}{
+		"valid": {
+			update: func(cfg *Configuration) {},
+		},
+		"open ethereum trace without prefix": {
+			update: func(cfg *Configuration) { cfg.RosettaCfg.TraceType = OpenEthereumTrace },
+			expectedErrs: []string{
+				"trace prefix is required with OpenEthereumTrace, e.g. trace or arbtrace",
+			},
+		},
+		"custom tracers": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.TraceType = OpenEthereumTrace
+				cfg.RosettaCfg.TracePrefix = "trace"
+				cfg.RosettaCfg.CustomTracer = "flatCallTracer"
+				cfg.RosettaCfg.CustomTracerPath = "tracer.js"
+			},
+			expectedErrs: []string{
+				"custom tracers are not supported with OpenEthereumTrace",
+				"custom tracer and custom tracer path are mutually exclusive",
+			},
+		},
+		"evm transfer annotations without geth traces": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.TraceType = OpenEthereumTrace
+				cfg.RosettaCfg.TracePrefix = "arbtrace"
+				cfg.RosettaCfg.UseEVMTransferAnnotations = true
+			},
+			expectedErrs: []string{
+				"EVM transfer annotations are not supported with OpenEthereumTrace",
+			},
+		},
+		"op stack conflicts": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.SupportsOpStack = true
+				cfg.RosettaCfg.RollupType = ZKRollupType
+				cfg.RosettaCfg.UseEVMTransferAnnotations = true
+			},
+			expectedErrs: []string{
+				"OP stack chains are not zk rollups",
+				"EVM transfer annotations are not supported on OP stack chains",
+			},
+		},
+		"unused trace prefix": {
+			update: func(cfg *Configuration) { cfg.RosettaCfg.TracePrefix = "arbtrace" },
+			expectedErrs: []string{
+				`trace prefix "arbtrace" is only used with OpenEthereumTrace`,
+			},
+		},
+		"invalid currency and chain config": {
+			update: func(cfg *Configuration) {
+				cfg.ChainConfig = nil
+				cfg.RosettaCfg.Currency = &RosettaTypes.Currency{Decimals: -1}
+				cfg.RosettaCfg.AddressChecksum = EIP1191AddressChecksum
+			},
+			expectedErrs: []string{
+				"chain config does not have a chain id",
+				"native currency does not have a symbol",
+				"native currency has invalid decimals -1",
+				"address checksum eip1191 requires a chain id",
+			},
+		},
+		"invalid allowed chain id": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.AllowedChainIDs = []uint64{10, 0}
+			},
+			expectedErrs: []string{
+				"allowed chain id 0 is not a valid chain id",
+			},
+		},
+		"invalid priority fee bounds": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.PriorityFeeFloor = big.NewInt(10)
+				cfg.RosettaCfg.PriorityFeeCap = big.NewInt(5)
+			},
+			expectedErrs: []string{
+				"priority fee floor 10 is above the priority fee cap 5",
+			},
+		},
+		"invalid address policies": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.AddressPolicies = []AddressPolicy{
+					{Address: "0x123"},
+					{Address: "0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b", SkipLogs: true},
+					{Address: "0x4dbcdf9b62e891a7cec5a2568c3f4faf9e8abe2b", OperationTypes: map[string]string{"CALL": ""}},
+				}
+			},
+			expectedErrs: []string{
+				`address policy 0: invalid address "0x123"`,
+				"address policy 2: address 0x4dbcdf9b62e891a7cec5a2568c3f4faf9e8abe2b is a duplicate of policy 1",
+				"address policy 2: operation type CALL is renamed to an empty type",
+			},
+		},
+		"invalid bridge events": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.BridgeEvents = []BridgeEvent{
+					{
+						Contract:  "0x508Ca82Df566dCD1B0DE8296e70a96332cD644ec",
+						Event:     "MessageClaimed(bytes32 indexed messageHash, address indexed to, uint256 value)",
+						Direction: DepositBridgeDirection,
+						Account:   "to",
+						Amount:    "value",
+					},
+					{Contract: "0x123", Direction: "mint", Account: "to"},
+				}
+			},
+			expectedErrs: []string{
+				`bridge event 1: invalid contract "0x123"`,
+				"bridge event 1: event signature is not set",
+				`bridge event 1: unsupported direction "mint"`,
+				"bridge event 1: account and amount arguments are required",
+			},
+		},
+		"invalid precompiles": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.Precompiles = []Precompile{
+					{Address: "0x0000000000000000000000000000000000000800", Decoder: "staking"},
+					{Address: "0x800", Decoder: "staking"},
+					{Address: "0x0000000000000000000000000000000000000800"},
+				}
+			},
+			expectedErrs: []string{
+				`precompile 1: invalid address "0x800"`,
+				"precompile 2: duplicate address 0x0000000000000000000000000000000000000800",
+				"precompile 2: decoder is not set",
+			},
+		},
+		"invalid balance exemptions": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.BalanceExemptions = []*RosettaTypes.BalanceExemption{
+					{ExemptionType: RosettaTypes.BalanceDynamic},
+				}
+			},
+			expectedErrs: []string{
+				`balance exemptions: balance exemption {"exemption_type":"dynamic"} is invalid: BalanceExemption missing subject`,
+			},
+		},
+		"invalid gas currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.GasCurrency = &RosettaTypes.Currency{Decimals: 6}
+			},
+			expectedErrs: []string{
+				"gas currency does not have a symbol",
+			},
+		},
+		"invalid withdrawal currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.WithdrawalCurrency = &RosettaTypes.Currency{Symbol: "GNO", Decimals: 99}
+				cfg.RosettaCfg.WithdrawalMultiplier = big.NewInt(0)
+			},
+			expectedErrs: []string{
+				"withdrawal currency has invalid decimals 99",
+				"withdrawal multiplier 0 is not positive",
+				"withdrawal currency and multiplier are only used when withdrawals are supported",
+			},
+		},
+		"withdrawal currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.SupportsWithdrawals = true
+				cfg.RosettaCfg.WithdrawalCurrency = &RosettaTypes.Currency{Symbol: "GNO", Decimals: 18}
+				cfg.RosettaCfg.WithdrawalMultiplier = big.NewInt(31250000)
+			},
+		},
+		"invalid reference currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.ReferenceCurrency = &RosettaTypes.Currency{Decimals: 99}
+				cfg.RosettaCfg.ChainlinkPriceFeeds = map[string]string{"ETH": "0x123"}
+				cfg.RosettaCfg.MaxPriceAge = -time.Hour
+				cfg.RosettaCfg.StaticPrices = map[string]string{"ETH": "-1"}
+			},
+			expectedErrs: []string{
+				"reference currency does not have a symbol",
+				"reference currency has invalid decimals 99",
+				`chainlink price feed "0x123" of ETH is not an address`,
+				"max price age -1h0m0s is negative",
+				`static price "-1" of ETH is not a positive number`,
+			},
+		},
+		"price sources without reference currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.StaticPrices = map[string]string{"ETH": "3125.42"}
+			},
+			expectedErrs: []string{
+				"price feeds and static prices require a reference currency",
+			},
+		},
+		"reference currency": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.ReferenceCurrency = &RosettaTypes.Currency{Symbol: "USD", Decimals: 2}
+				cfg.RosettaCfg.ChainlinkPriceFeeds = map[string]string{"ETH": "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"}
+				cfg.RosettaCfg.MaxPriceAge = time.Hour
+				cfg.RosettaCfg.StaticPrices = map[string]string{"USDC": "1"}
+			},
+		},
+		"invalid tokens": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.TokenWhiteList = append(
+					cfg.RosettaCfg.TokenWhiteList,
+					Token{ChainID: 10, Address: "0xa0B86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC"},
+					Token{Address: "0x123", Decimals: 78},
+				)
+			},
+			expectedErrs: []string{
+				"token white list entry 2: address 0xa0B86991c6218b36c1d19D4a2e9Eb0cE3606eB48 is not checksummed, " +
+					"expected 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+				"token white list entry 2: address 0xa0B86991c6218b36c1d19D4a2e9Eb0cE3606eB48 is a duplicate of entry 0",
+				"token white list entry 2: token 0xa0B86991c6218b36c1d19D4a2e9Eb0cE3606eB48 has chain id 10, expected 1",
+				`token white list entry 3: invalid address "0x123"`,
+				"token white list entry 3: token 0x123 does not have a symbol",
+				"token white list entry 3: token 0x123 has invalid decimals 78",
+			},
+		},
+		"token white list url": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.FilterTokens = true
+				cfg.RosettaCfg.TokenWhiteList = nil
+				cfg.RosettaCfg.TokenWhiteListURL = "https://tokens.example.com/ethereum.json"
+			},
+		},
+		"invalid token white list url": {
+			update: func(cfg *Configuration) {
+				cfg.RosettaCfg.TokenWhiteListURL = "s3://tokens/ethereum.json"
+				cfg.RosettaCfg.TokenWhiteListRefreshInterval = -time.Minute
+			},
+			expectedErrs: []string{
+				`unsupported token white list url scheme "s3"`,
+				"token white list refresh interval -1m0s is negative",
+			},
+		},
+		"invalid options": {
+			update: func(cfg *Configuration) {
+				cfg.Mode = "online"
+				cfg.RosettaCfg.DefaultBlockTag = "pending"
+				cfg.RosettaCfg.TrustlessValidationPolicy = "panic"
+				cfg.RosettaCfg.TransactionParsePolicy = "skip"
+				cfg.RosettaCfg.TokenDecimalsPolicy = "ignore"
+				cfg.RosettaCfg.ConsensusEngine = "aura"
+				cfg.RosettaCfg.ChainProfile = "heco"
+				cfg.RosettaCfg.RollupType = "optimistic"
+				cfg.RosettaCfg.BlockMetadataFields = []string{"gas_used", "difficulty"}
+				cfg.RosettaCfg.MaxBatchSize = -1
+				cfg.RosettaCfg.BlockCacheSize = -1
+				cfg.RosettaCfg.BlockPrefetchDepth = 2
+				cfg.RosettaCfg.CurrencyStoreTTL = -time.Hour
+				cfg.RosettaCfg.TraceMetadataMaxDepth = -1
+				cfg.RosettaCfg.TraceMetadataMaxBytes = -1
+				cfg.RosettaCfg.SyncStalenessThreshold = -time.Minute
+				cfg.RosettaCfg.SlowCallThresholds = map[string]time.Duration{"debug_traceBlockByHash": -time.Second}
+				cfg.RosettaCfg.SlowCallReportSize = -1
+				cfg.RosettaCfg.EntryPointAddresses = []string{"0x123"}
+				cfg.RosettaCfg.SignerKeystorePath = "keystore.json"
+				cfg.RosettaCfg.ConcurrencyLimits = map[string]ConcurrencyLimit{
+					"/block": {MaxConcurrent: 0, QueueDepth: -1},
+				}
+			},
+			expectedErrs: []string{
+				`mode "online" is not ONLINE or OFFLINE`,
+				"default block: unsupported block tag pending",
+				`unsupported trustless validation policy "panic"`,
+				`unsupported transaction parse policy "skip"`,
+				`unsupported token decimals policy "ignore"`,
+				`unsupported consensus engine "aura"`,
+				`unsupported chain profile "heco"`,
+				`unsupported rollup type "optimistic"`,
+				`unsupported block metadata field "difficulty"`,
+				"trace metadata max depth -1 is negative",
+				"trace metadata max bytes -1 is negative",
+				"signer keystore keystore.json is only used with server side signing",
+				"sync staleness threshold -1m0s is negative",
+				"slow call threshold -1s of debug_traceBlockByHash is negative",
+				"slow call report size -1 is negative",
+				"max concurrent requests 0 of /block is not positive",
+				"queue depth -1 of /block is negative",
+				"block cache size -1 is negative",
+				"block prefetch depth 2 exceeds the block cache size -1",
+				"currency store ttl -1h0m0s is negative",
+				"max batch size -1 is negative",
+				`entry point 0: invalid address "0x123"`,
+				"entry points are only used when user operations are decoded",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfiguration()
+			test.update(cfg)
+
+			err := Validate(cfg)
+			if len(test.expectedErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			assert.True(t, ok)
+			problems := make([]string, 0, len(joined.Unwrap()))
+			for _, problem := range joined.Unwrap() {
+				problems = append(problems, problem.Error())
+			}
+			assert.Equal(t, test.expectedErrs, problems)
+		})
+	}
+
+	assert.EqualError(t, Validate(nil), "configuration is not set")
+}
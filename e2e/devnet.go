@@ -0,0 +1,262 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// NodeURLEnv is the environment variable with the URL of an already running
+	// dev chain, which is used instead of starting one
+	NodeURLEnv = "E2E_NODE_URL"
+
+	// GethImageEnv is the environment variable with the Docker image of geth used
+	// when neither geth nor anvil are on the PATH
+	GethImageEnv = "E2E_GETH_IMAGE"
+
+	defaultGethImage = "ethereum/client-go:v1.13.8"
+	devChainID       = 1337
+	devnetAPIs       = "eth,net,web3,debug,txpool"
+	startTimeout     = 30 * time.Second
+	receiptTimeout   = 30 * time.Second
+	pollInterval     = 100 * time.Millisecond
+)
+
+// Devnet is an ephemeral dev chain with unlocked, funded accounts
+type Devnet struct {
+	URL     string
+	ChainID *big.Int
+
+	RPC *rpc.Client
+	Eth *ethclient.Client
+}
+
+// StartDevnet connects to the dev chain at NodeURLEnv, or starts geth --dev or
+// anvil from the PATH, or geth --dev in Docker. The chain is stopped when the
+// test ends.
+func StartDevnet(t *testing.T) *Devnet {
+	t.Helper()
+
+	url := os.Getenv(NodeURLEnv)
+	if len(url) == 0 {
+		url = startNode(t)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	for {
+		devnet, err := dialDevnet(ctx, url)
+		if err == nil {
+			t.Cleanup(devnet.RPC.Close)
+			return devnet
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("dev chain at %s did not start: %v", url, err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// startNode starts a dev chain process and returns its URL
+func startNode(t *testing.T) string {
+	port := freePort(t)
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	var cmd *exec.Cmd
+	switch {
+	case hasBinary("geth"):
+		cmd = exec.Command(
+			"geth", "--dev", "--datadir", t.TempDir(),
+			"--http", "--http.addr", "127.0.0.1", "--http.port", strconv.Itoa(port), "--http.api", devnetAPIs,
+		)
+	case hasBinary("anvil"):
+		cmd = exec.Command("anvil", "--port", strconv.Itoa(port), "--chain-id", strconv.Itoa(devChainID), "--silent")
+	case hasBinary("docker"):
+		image := os.Getenv(GethImageEnv)
+		if len(image) == 0 {
+			image = defaultGethImage
+		}
+		name := fmt.Sprintf("rosetta-geth-sdk-e2e-%d", port)
+		cmd = exec.Command(
+			"docker", "run", "--rm", "--name", name, "-p", fmt.Sprintf("127.0.0.1:%d:8545", port), image,
+			"--dev", "--http", "--http.addr", "0.0.0.0", "--http.api", devnetAPIs,
+		)
+		t.Cleanup(func() {
+			_ = exec.Command("docker", "stop", name).Run()
+		})
+	default:
+		t.Skipf("set %s or install geth, anvil or docker to run end-to-end tests", NodeURLEnv)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("could not start %s: %v", cmd.Path, err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	return url
+}
+
+func hasBinary(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not find a free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func dialDevnet(ctx context.Context, url string) (*Devnet, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	eth := ethclient.NewClient(rpcClient)
+	chainID, err := eth.ChainID(ctx)
+	if err != nil {
+		rpcClient.Close()
+		return nil, err
+	}
+
+	return &Devnet{URL: url, ChainID: chainID, RPC: rpcClient, Eth: eth}, nil
+}
+
+// Fund sends value from the first unlocked account of the dev chain to address
+func (d *Devnet) Fund(t *testing.T, address common.Address, value *big.Int) {
+	t.Helper()
+	ctx := context.Background()
+
+	var accounts []common.Address
+	if err := d.RPC.CallContext(ctx, &accounts, "eth_accounts"); err != nil || len(accounts) == 0 {
+		t.Fatalf("dev chain has no unlocked accounts: %v", err)
+	}
+
+	var hash common.Hash
+	err := d.RPC.CallContext(ctx, &hash, "eth_sendTransaction", map[string]interface{}{
+		"from":  accounts[0],
+		"to":    address,
+		"value": (*hexutil.Big)(value),
+	})
+	if err != nil {
+		t.Fatalf("could not fund %s: %v", address, err)
+	}
+	d.WaitReceipt(t, hash)
+}
+
+// SendTransaction signs and sends a dynamic fee transaction of key and returns
+// its receipt. to is nil for contract creations.
+func (d *Devnet) SendTransaction(
+	t *testing.T,
+	key *ecdsa.PrivateKey,
+	to *common.Address,
+	value *big.Int,
+	data []byte,
+) *EthTypes.Receipt {
+	t.Helper()
+	ctx := context.Background()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	nonce, err := d.Eth.PendingNonceAt(ctx, from)
+	if err != nil {
+		t.Fatalf("could not get nonce of %s: %v", from, err)
+	}
+	gas, err := d.Eth.EstimateGas(ctx, ethereum.CallMsg{From: from, To: to, Value: value, Data: data})
+	if err != nil {
+		t.Fatalf("could not estimate gas: %v", err)
+	}
+	tip, err := d.Eth.SuggestGasTipCap(ctx)
+	if err != nil {
+		t.Fatalf("could not get gas tip: %v", err)
+	}
+	head, err := d.Eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("could not get head: %v", err)
+	}
+
+	tx, err := EthTypes.SignNewTx(key, EthTypes.LatestSignerForChainID(d.ChainID), &EthTypes.DynamicFeeTx{
+		ChainID:   d.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))), // nolint:gomnd
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if err := d.Eth.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+
+	return d.WaitReceipt(t, tx.Hash())
+}
+
+// WaitReceipt waits for the transaction with hash to be mined and returns its
+// receipt, failing the test if it reverted
+func (d *Devnet) WaitReceipt(t *testing.T, hash common.Hash) *EthTypes.Receipt {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), receiptTimeout)
+	defer cancel()
+	for {
+		receipt, err := d.Eth.TransactionReceipt(ctx, hash)
+		if err == nil {
+			if receipt.Status != EthTypes.ReceiptStatusSuccessful {
+				t.Fatalf("transaction %s reverted", hash)
+			}
+			return receipt
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			t.Fatalf("could not get receipt of %s: %v", hash, err)
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("transaction %s was not mined", hash)
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	ethereum "github.com/coinbase/rosetta-geth-sdk/examples/ethereum/client"
+	"github.com/coinbase/rosetta-geth-sdk/services"
+	"github.com/coinbase/rosetta-geth-sdk/services/construction"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var network = &RosettaTypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Devnet"}
+
+// ether is 10^18 wei
+var ether = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // nolint:gomnd
+
+func TestEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	devnet := StartDevnet(t)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	devnet.Fund(t, sender, new(big.Int).Mul(big.NewInt(10), ether)) // nolint:gomnd
+
+	deployment := devnet.SendTransaction(t, key, nil, new(big.Int), TokenCreationCode())
+	token := deployment.ContractAddress
+	tokenCurrency := client.Erc20Currency(TokenSymbol, TokenDecimals, token.Hex())
+
+	chainConfig := *params.AllDevChainProtocolChanges
+	chainConfig.ChainID = devnet.ChainID
+	cfg := &configuration.Configuration{
+		Mode:        configuration.ModeOnline,
+		Network:     network,
+		GethURL:     devnet.URL,
+		ChainConfig: &chainConfig,
+		RosettaCfg: configuration.RosettaConfig{
+			TraceType:                 configuration.GethNativeTrace,
+			SupportsEIP1559:           true,
+			Currency:                  &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18},
+			FilterTokens:              true,
+			UseTokenWhiteListMetadata: true,
+			TokenWhiteList: []configuration.Token{{
+				ChainID:  devnet.ChainID.Uint64(),
+				Address:  token.Hex(),
+				Symbol:   TokenSymbol,
+				Decimals: TokenDecimals,
+			}},
+		},
+	}
+	require.NoError(t, configuration.Validate(cfg))
+
+	sdkClient, err := ethereum.NewEthereumClient(cfg)
+	require.NoError(t, err)
+	types := AssetTypes.LoadTypes()
+	blockAPI := services.NewBlockAPIService(cfg, sdkClient)
+	accountAPI := services.NewAccountAPIService(cfg, types, AssetTypes.Errors, sdkClient)
+	constructionAPI := construction.NewAPIService(cfg, types, AssetTypes.Errors, sdkClient)
+
+	t.Run("erc20 transfer", func(t *testing.T) {
+		data := append(common.CopyBytes(transferSelector), common.LeftPadBytes(recipient.Bytes(), wordSize)...)
+		data = append(data, common.LeftPadBytes(big.NewInt(100).Bytes(), wordSize)...) // nolint:gomnd
+		receipt := devnet.SendTransaction(t, key, &token, new(big.Int), data)
+
+		transaction := blockTransaction(ctx, t, blockAPI, receipt.BlockNumber.Int64(), receipt.TxHash)
+		assertTransfer(t, transaction, AssetTypes.OpErc20Transfer, sender, recipient, "100", tokenCurrency)
+
+		balance := accountBalance(ctx, t, accountAPI, recipient, receipt.BlockNumber.Int64(), tokenCurrency)
+		assert.Equal(t, "100", balance)
+	})
+
+	t.Run("construction", func(t *testing.T) {
+		nativeCurrency := cfg.RosettaCfg.Currency
+		value := ether.String()
+		operations := []*RosettaTypes.Operation{
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+				Type:                AssetTypes.CallOpType,
+				Account:             &RosettaTypes.AccountIdentifier{Address: sender.Hex()},
+				Amount:              &RosettaTypes.Amount{Value: "-" + value, Currency: nativeCurrency},
+			},
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+				Type:                AssetTypes.CallOpType,
+				Account:             &RosettaTypes.AccountIdentifier{Address: recipient.Hex()},
+				Amount:              &RosettaTypes.Amount{Value: value, Currency: nativeCurrency},
+			},
+		}
+		publicKey := &RosettaTypes.PublicKey{
+			Bytes:     crypto.CompressPubkey(&key.PublicKey),
+			CurveType: RosettaTypes.Secp256k1,
+		}
+
+		derived, rosettaErr := constructionAPI.ConstructionDerive(ctx, &RosettaTypes.ConstructionDeriveRequest{
+			NetworkIdentifier: network,
+			PublicKey:         publicKey,
+		})
+		require.Nil(t, rosettaErr)
+		assert.Equal(t, sender.Hex(), derived.AccountIdentifier.Address)
+
+		preprocessed, rosettaErr := constructionAPI.ConstructionPreprocess(ctx, &RosettaTypes.ConstructionPreprocessRequest{
+			NetworkIdentifier: network,
+			Operations:        operations,
+		})
+		require.Nil(t, rosettaErr)
+
+		metadata, rosettaErr := constructionAPI.ConstructionMetadata(ctx, &RosettaTypes.ConstructionMetadataRequest{
+			NetworkIdentifier: network,
+			Options:           preprocessed.Options,
+			PublicKeys:        []*RosettaTypes.PublicKey{publicKey},
+		})
+		require.Nil(t, rosettaErr)
+
+		payloads, rosettaErr := constructionAPI.ConstructionPayloads(ctx, &RosettaTypes.ConstructionPayloadsRequest{
+			NetworkIdentifier: network,
+			Operations:        operations,
+			Metadata:          metadata.Metadata,
+			PublicKeys:        []*RosettaTypes.PublicKey{publicKey},
+		})
+		require.Nil(t, rosettaErr)
+		require.Len(t, payloads.Payloads, 1)
+
+		parsed, rosettaErr := constructionAPI.ConstructionParse(ctx, &RosettaTypes.ConstructionParseRequest{
+			NetworkIdentifier: network,
+			Signed:            false,
+			Transaction:       payloads.UnsignedTransaction,
+		})
+		require.Nil(t, rosettaErr)
+		assert.Len(t, parsed.Operations, len(operations))
+
+		signature, err := crypto.Sign(payloads.Payloads[0].Bytes, key)
+		require.NoError(t, err)
+		combined, rosettaErr := constructionAPI.ConstructionCombine(ctx, &RosettaTypes.ConstructionCombineRequest{
+			NetworkIdentifier:   network,
+			UnsignedTransaction: payloads.UnsignedTransaction,
+			Signatures: []*RosettaTypes.Signature{{
+				SigningPayload: payloads.Payloads[0],
+				PublicKey:      publicKey,
+				SignatureType:  RosettaTypes.EcdsaRecovery,
+				Bytes:          signature,
+			}},
+		})
+		require.Nil(t, rosettaErr)
+
+		parsed, rosettaErr = constructionAPI.ConstructionParse(ctx, &RosettaTypes.ConstructionParseRequest{
+			NetworkIdentifier: network,
+			Signed:            true,
+			Transaction:       combined.SignedTransaction,
+		})
+		require.Nil(t, rosettaErr)
+		assert.Equal(t, sender.Hex(), parsed.AccountIdentifierSigners[0].Address)
+
+		hash, rosettaErr := constructionAPI.ConstructionHash(ctx, &RosettaTypes.ConstructionHashRequest{
+			NetworkIdentifier: network,
+			SignedTransaction: combined.SignedTransaction,
+		})
+		require.Nil(t, rosettaErr)
+
+		submitted, rosettaErr := constructionAPI.ConstructionSubmit(ctx, &RosettaTypes.ConstructionSubmitRequest{
+			NetworkIdentifier: network,
+			SignedTransaction: combined.SignedTransaction,
+		})
+		require.Nil(t, rosettaErr)
+		assert.Equal(t, hash.TransactionIdentifier.Hash, submitted.TransactionIdentifier.Hash)
+
+		receipt := devnet.WaitReceipt(t, common.HexToHash(submitted.TransactionIdentifier.Hash))
+		transaction := blockTransaction(ctx, t, blockAPI, receipt.BlockNumber.Int64(), receipt.TxHash)
+		assertTransfer(t, transaction, AssetTypes.CallOpType, sender, recipient, value, nativeCurrency)
+
+		balance := accountBalance(ctx, t, accountAPI, recipient, receipt.BlockNumber.Int64(), nativeCurrency)
+		assert.Equal(t, value, balance)
+	})
+}
+
+// blockTransaction returns the transaction with hash of the /block response at index
+func blockTransaction(
+	ctx context.Context,
+	t *testing.T,
+	blockAPI *services.BlockAPIService,
+	index int64,
+	hash common.Hash,
+) *RosettaTypes.Transaction {
+	resp, rosettaErr := blockAPI.Block(ctx, &RosettaTypes.BlockRequest{
+		NetworkIdentifier: network,
+		BlockIdentifier:   &RosettaTypes.PartialBlockIdentifier{Index: &index},
+	})
+	require.Nil(t, rosettaErr)
+	assert.Equal(t, index, resp.Block.BlockIdentifier.Index)
+
+	for _, transaction := range resp.Block.Transactions {
+		if transaction.TransactionIdentifier.Hash == hash.Hex() {
+			return transaction
+		}
+	}
+	t.Fatalf("transaction %s is not in block %d", hash, index)
+	return nil
+}
+
+// assertTransfer asserts that transaction has the debit and credit operations of
+// a transfer of value from sender to recipient
+func assertTransfer(
+	t *testing.T,
+	transaction *RosettaTypes.Transaction,
+	opType string,
+	sender common.Address,
+	recipient common.Address,
+	value string,
+	currency *RosettaTypes.Currency,
+) {
+	var debited, credited bool
+	for _, op := range transaction.Operations {
+		if op.Type != opType || op.Amount == nil || RosettaTypes.Hash(op.Amount.Currency) != RosettaTypes.Hash(currency) {
+			continue
+		}
+		switch {
+		case op.Account.Address == sender.Hex() && op.Amount.Value == "-"+value:
+			debited = true
+		case op.Account.Address == recipient.Hex() && op.Amount.Value == value:
+			credited = true
+		}
+	}
+	assert.True(t, debited, "missing debit of %s", sender)
+	assert.True(t, credited, "missing credit of %s", recipient)
+}
+
+// accountBalance returns the /account/balance of address in currency at index
+func accountBalance(
+	ctx context.Context,
+	t *testing.T,
+	accountAPI *services.AccountAPIService,
+	address common.Address,
+	index int64,
+	currency *RosettaTypes.Currency,
+) string {
+	resp, rosettaErr := accountAPI.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+		NetworkIdentifier: network,
+		AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: address.Hex()},
+		BlockIdentifier:   &RosettaTypes.PartialBlockIdentifier{Index: &index},
+		Currencies:        []*RosettaTypes.Currency{currency},
+	})
+	require.Nil(t, rosettaErr)
+	require.Len(t, resp.Balances, 1)
+
+	return resp.Balances[0].Value
+}
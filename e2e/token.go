@@ -0,0 +1,197 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package e2e runs end-to-end tests of the SDK against an ephemeral dev chain.
+// The tests are built with the e2e tag:
+//
+//	go test -tags=e2e ./e2e/...
+//
+// The dev chain is the node at E2E_NODE_URL when it is set, and otherwise a
+// geth --dev or anvil binary on the PATH, or geth --dev in Docker.
+package e2e
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// TokenSymbol is the symbol and name of the test token
+	TokenSymbol = "E2E"
+
+	// TokenDecimals is the number of decimals of the test token
+	TokenDecimals = 18
+
+	// labelSize is the size of the jump destinations pushed by the assembler
+	labelSize = 2
+
+	// Offsets and sizes of the token calldata and memory
+	selectorShift = 0xe0
+	firstArg      = 4
+	secondArg     = 0x24
+	wordSize      = 32
+)
+
+// TokenSupply is the supply of the test token, owned by its deployer
+var TokenSupply = new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil) // nolint:gomnd
+
+// ERC20 method selectors of the test token
+var (
+	balanceOfSelector   = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+	transferSelector    = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	decimalsSelector    = crypto.Keccak256([]byte("decimals()"))[:4]
+	symbolSelector      = crypto.Keccak256([]byte("symbol()"))[:4]
+	nameSelector        = crypto.Keccak256([]byte("name()"))[:4]
+	totalSupplySelector = crypto.Keccak256([]byte("totalSupply()"))[:4]
+
+	transferTopic = crypto.Keccak256([]byte("Transfer(address,address,uint256)"))
+)
+
+// assembler writes EVM bytecode with named jump destinations
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, fixups: map[int]string{}}
+}
+
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push pushes value with the smallest PUSH instruction
+func (a *assembler) push(value []byte) *assembler {
+	value = new(big.Int).SetBytes(value).Bytes()
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+func (a *assembler) pushInt(value int64) *assembler {
+	return a.push(big.NewInt(value).Bytes())
+}
+
+// pushLabel pushes the offset of a label, which can be marked later
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.fixups[len(a.code)] = name
+	a.code = append(a.code, make([]byte, labelSize)...)
+	return a
+}
+
+// mark names the current offset
+func (a *assembler) mark(name string) *assembler {
+	a.labels[name] = len(a.code)
+	return a
+}
+
+// jumpDest marks a jump destination
+func (a *assembler) jumpDest(name string) *assembler {
+	return a.mark(name).op(vm.JUMPDEST)
+}
+
+func (a *assembler) bytes() []byte {
+	code := common.CopyBytes(a.code)
+	for offset, name := range a.fixups {
+		target, ok := a.labels[name]
+		if !ok {
+			panic("undefined label " + name)
+		}
+		code[offset] = byte(target >> 8) // nolint:gomnd
+		code[offset+1] = byte(target)
+	}
+	return code
+}
+
+// tokenRuntimeCode returns the code of a minimal ERC20 token keeping the
+// balance of every account in the storage slot of its address
+func tokenRuntimeCode() []byte {
+	a := newAssembler()
+
+	// Dispatch on the method selector
+	a.pushInt(0).op(vm.CALLDATALOAD).pushInt(selectorShift).op(vm.SHR)
+	for _, method := range []struct {
+		selector []byte
+		label    string
+	}{
+		{balanceOfSelector, "balanceOf"},
+		{transferSelector, "transfer"},
+		{decimalsSelector, "decimals"},
+		{symbolSelector, "symbol"},
+		{nameSelector, "symbol"},
+		{totalSupplySelector, "totalSupply"},
+	} {
+		a.op(vm.DUP1).push(method.selector).op(vm.EQ).pushLabel(method.label).op(vm.JUMPI)
+	}
+	a.jumpDest("revert").pushInt(0).op(vm.DUP1, vm.REVERT)
+
+	// Return the word on top of the stack
+	a.jumpDest("return").pushInt(0).op(vm.MSTORE).pushInt(wordSize).pushInt(0).op(vm.RETURN)
+
+	a.jumpDest("balanceOf").pushInt(firstArg).op(vm.CALLDATALOAD, vm.SLOAD).pushLabel("return").op(vm.JUMP)
+
+	// Debit the caller, reverting when its balance is too low
+	a.jumpDest("transfer")
+	a.pushInt(secondArg).op(vm.CALLDATALOAD, vm.CALLER, vm.SLOAD) // [balance, amount]
+	a.op(vm.DUP2, vm.DUP2, vm.LT).pushLabel("revert").op(vm.JUMPI)
+	a.op(vm.SUB, vm.CALLER, vm.SSTORE)
+	// Credit the recipient
+	a.pushInt(secondArg).op(vm.CALLDATALOAD)
+	a.pushInt(firstArg).op(vm.CALLDATALOAD, vm.DUP1, vm.SLOAD) // [balance, to, amount]
+	a.op(vm.DUP3, vm.ADD, vm.SWAP1, vm.SSTORE)                 // [amount]
+	// Emit Transfer(caller, to, amount) and return true
+	a.pushInt(0).op(vm.MSTORE)
+	a.pushInt(firstArg).op(vm.CALLDATALOAD, vm.CALLER).push(transferTopic)
+	a.pushInt(wordSize).pushInt(0).op(vm.LOG3)
+	a.pushInt(1).pushLabel("return").op(vm.JUMP)
+
+	a.jumpDest("decimals").pushInt(TokenDecimals).pushLabel("return").op(vm.JUMP)
+
+	a.jumpDest("totalSupply").push(TokenSupply.Bytes()).pushLabel("return").op(vm.JUMP)
+
+	// Return the ABI encoding of the symbol
+	a.jumpDest("symbol")
+	a.pushInt(wordSize).pushInt(0).op(vm.MSTORE)
+	a.pushInt(int64(len(TokenSymbol))).pushInt(wordSize).op(vm.MSTORE)
+	a.push(common.RightPadBytes([]byte(TokenSymbol), wordSize)).pushInt(2 * wordSize).op(vm.MSTORE)
+	a.pushInt(3 * wordSize).pushInt(0).op(vm.RETURN)
+
+	return a.bytes()
+}
+
+// TokenCreationCode returns the creation code of the test token, which mints
+// TokenSupply to the deployer
+func TokenCreationCode() []byte {
+	runtime := tokenRuntimeCode()
+
+	a := newAssembler()
+	a.push(TokenSupply.Bytes()).op(vm.CALLER, vm.SSTORE)
+	a.pushInt(int64(len(runtime))).op(vm.DUP1).pushLabel("runtime").pushInt(0).op(vm.CODECOPY)
+	a.pushInt(0).op(vm.RETURN)
+	a.mark("runtime")
+
+	return append(a.bytes(), runtime...)
+}
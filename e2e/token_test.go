@@ -0,0 +1,86 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenCreationCode(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	deployer := common.HexToAddress("0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0")
+	recipient := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	cfg := &runtime.Config{Origin: deployer, State: statedb}
+
+	_, token, _, err := runtime.Create(TokenCreationCode(), cfg)
+	assert.NoError(t, err)
+
+	call := func(sender common.Address, selector []byte, args ...[]byte) ([]byte, error) {
+		input := common.CopyBytes(selector)
+		for _, arg := range args {
+			input = append(input, common.LeftPadBytes(arg, wordSize)...)
+		}
+		cfg.Origin = sender
+		ret, _, err := runtime.Call(token, input, cfg)
+		return ret, err
+	}
+	balanceOf := func(addr common.Address) *big.Int {
+		ret, err := call(deployer, balanceOfSelector, addr.Bytes())
+		assert.NoError(t, err)
+		return new(big.Int).SetBytes(ret)
+	}
+
+	ret, err := call(deployer, totalSupplySelector)
+	assert.NoError(t, err)
+	assert.Equal(t, TokenSupply, new(big.Int).SetBytes(ret))
+	assert.Equal(t, TokenSupply, balanceOf(deployer))
+
+	ret, err = call(deployer, decimalsSelector)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(TokenDecimals), new(big.Int).SetBytes(ret).Int64())
+
+	ret, err = call(deployer, symbolSelector)
+	assert.NoError(t, err)
+	assert.Len(t, ret, 3*wordSize)
+	assert.Equal(t, TokenSymbol, string(ret[2*wordSize:2*wordSize+len(TokenSymbol)]))
+
+	ret, err = call(deployer, transferSelector, recipient.Bytes(), big.NewInt(100).Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, common.LeftPadBytes([]byte{1}, wordSize), ret)
+	assert.Equal(t, big.NewInt(100), balanceOf(recipient))
+	assert.Equal(t, new(big.Int).Sub(TokenSupply, big.NewInt(100)), balanceOf(deployer))
+
+	logs := statedb.Logs()
+	assert.Len(t, logs, 1)
+	assert.Equal(t, token, logs[0].Address)
+	assert.Equal(t, common.BytesToHash(transferTopic), logs[0].Topics[0])
+	assert.Equal(t, common.BytesToHash(deployer.Bytes()), logs[0].Topics[1])
+	assert.Equal(t, common.BytesToHash(recipient.Bytes()), logs[0].Topics[2])
+	assert.Equal(t, big.NewInt(100), new(big.Int).SetBytes(logs[0].Data))
+
+	// Overdrafts and unknown methods revert
+	_, err = call(recipient, transferSelector, deployer.Bytes(), big.NewInt(101).Bytes())
+	assert.Error(t, err)
+	_, err = call(deployer, []byte{1, 2, 3, 4})
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math/big"
+
+	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/coinbase/rosetta-geth-sdk/services"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nativeTransferGasLimit is the gas limit of a transfer of ETH to an
+// account without code
+const nativeTransferGasLimit = 21000
+
+type BaseClient struct {
+	// Use embedding for inheritance. So all the methods of the SDKClient
+	// are instantly available on BaseClient, including GetL1DataFee, which
+	// queries the GasPriceOracle since SupportsOpStack is set.
+	evmClient.SDKClient
+}
+
+// ParseOps returns the operations of tx: the fee operations paying the fee
+// vaults, or the mint of a deposit transaction, followed by the operations of
+// its traces
+func (c *BaseClient) ParseOps(
+	tx *evmClient.LoadedTransaction,
+) ([]*RosettaTypes.Operation, error) {
+	b := services.NewOperationBuilder(0)
+
+	feeOps, err := services.ParseFeeOps(tx, c.GetRosettaConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Append(feeOps...); err != nil {
+		return nil, err
+	}
+
+	// Append re-indexes the trace operations to follow the fee operations
+	if err := b.Append(services.TransactionTraceOps(tx, 0)...); err != nil {
+		return nil, err
+	}
+
+	return b.Operations(), nil
+}
+
+// GetBlockReceipts returns the receipts of a block, with the L1 data fee
+// included in the transaction fees
+func (c *BaseClient) GetBlockReceipts(
+	ctx context.Context,
+	blockHash common.Hash,
+	txs []evmClient.RPCTransaction,
+	baseFee *big.Int,
+) ([]*evmClient.RosettaTxReceipt, error) {
+	return c.OPStackBlockReceipts(ctx, blockHash, txs)
+}
+
+// GetTransactionReceipt returns the receipt of a transaction, with the L1
+// data fee included in the transaction fee
+func (c *BaseClient) GetTransactionReceipt(
+	ctx context.Context,
+	tx *evmClient.LoadedTransaction,
+) (*evmClient.RosettaTxReceipt, error) {
+	return c.OPStackTransactionReceipt(ctx, *tx.TxHash)
+}
+
+// GetNativeTransferGasLimit estimates the gas of a transfer of value from
+// fromAddress to toAddress
+func (c *BaseClient) GetNativeTransferGasLimit(ctx context.Context, toAddress string,
+	fromAddress string, value *big.Int) (uint64, error) {
+	if len(toAddress) == 0 || value == nil {
+		return nativeTransferGasLimit, nil
+	}
+	to := common.HexToAddress(toAddress)
+	return c.EstimateGas(ctx, ethereum.CallMsg{
+		From:  common.HexToAddress(fromAddress),
+		To:    &to,
+		Value: value,
+	})
+}
+
+// NewBaseClient creates a client that can interact with the Base network.
+func NewBaseClient(cfg *configuration.Configuration) (*BaseClient, error) {
+	sdkClient, err := evmClient.NewClient(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BaseClient{*sdkClient}, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+const depositTransaction = `{
+	"from": "0x977f82a600a1414e583f7f13623f1ac5d58b1c0b",
+	"gas": "0x186a0",
+	"hash": "0x5d4f1bcb5a4c8b1e1e3d5d9c2a8f7e6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e",
+	"input": "0x",
+	"isSystemTx": false,
+	"mint": "0xde0b6b3a7640000",
+	"sourceHash": "0x9a3bde2a4d2e2ccc0b0cd5cb7b6ec6e2cfb8a8f62d3dd4a7d4e0c1f0b9d2a3b4",
+	"to": "0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0",
+	"type": "0x7e",
+	"value": "0x6f05b59d3b20000"
+}`
+
+func TestParseOps(t *testing.T) {
+	c, err := NewBaseClient(&configuration.Configuration{
+		GethURL:     "http://127.0.0.1:8545",
+		ChainConfig: &params.ChainConfig{ChainID: big.NewInt(8453)},
+		RosettaCfg: configuration.RosettaConfig{
+			SupportsOpStack: true,
+			TraceType:       configuration.GethNativeTrace,
+		},
+	})
+	assert.NoError(t, err)
+
+	var rpcTx evmClient.RPCTransaction
+	assert.NoError(t, json.Unmarshal([]byte(depositTransaction), &rpcTx))
+	deposit := &evmClient.LoadedTransaction{
+		Transaction: rpcTx.Tx,
+		From:        rpcTx.From,
+		TxHash:      rpcTx.TxHash,
+		Mint:        rpcTx.Mint,
+		Extensions:  rpcTx.Extensions,
+		Receipt:     &evmClient.RosettaTxReceipt{},
+		FeeAmount:   big.NewInt(0),
+		Trace: []*evmClient.FlatCall{{
+			Type:  "CALL",
+			From:  *rpcTx.From,
+			To:    *rpcTx.Tx.To(),
+			Value: rpcTx.Tx.Value(),
+		}},
+	}
+	assert.NoError(t, evmClient.ApplyOPStackReceipt(deposit))
+
+	// The deposit mints 1 ETH to the sender, who transfers 0.5 ETH
+	ops, err := c.ParseOps(deposit)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 3)
+	assert.Equal(t, sdkTypes.BridgeDepositOpType, ops[0].Type)
+	assert.Equal(t, "1000000000000000000", ops[0].Amount.Value)
+	assert.Equal(t, sdkTypes.CallOpType, ops[1].Type)
+	assert.Equal(t, "-500000000000000000", ops[1].Amount.Value)
+	assert.Equal(t, int64(2), ops[2].OperationIdentifier.Index)
+	assert.Equal(t, "500000000000000000", ops[2].Amount.Value)
+
+	// The fee of other transactions is paid to the fee vaults
+	from := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		FeeAmount: big.NewInt(1000),
+		FeeBurned: big.NewInt(600),
+		Receipt:   &evmClient.RosettaTxReceipt{L1Fee: big.NewInt(300)},
+	}
+	ops, err = c.ParseOps(tx)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 4)
+	assert.Equal(t, evmClient.SequencerFeeVault.Hex(), ops[1].Account.Address)
+	assert.Equal(t, evmClient.BaseFeeVault.Hex(), ops[2].Account.Address)
+	assert.Equal(t, evmClient.L1FeeVault.Hex(), ops[3].Account.Address)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// Blockchain is Base.
+	Blockchain string = "Base"
+
+	// MainnetNetwork is the value of the network
+	// in the mainnet NetworkIdentifier.
+	MainnetNetwork string = "Mainnet"
+
+	// SepoliaNetwork is the value of the network
+	// in the Sepolia NetworkIdentifier.
+	SepoliaNetwork string = "Sepolia"
+
+	// Mainnet is Base Mainnet.
+	Mainnet string = "MAINNET"
+
+	// Sepolia is the Base Sepolia testnet.
+	Sepolia string = "SEPOLIA"
+
+	// ModeEnv is the environment variable read
+	// to determine mode.
+	ModeEnv = "MODE"
+
+	// NetworkEnv is the environment variable
+	// read to determine network.
+	NetworkEnv = "NETWORK"
+
+	// PortEnv is the environment variable
+	// read to determine the port for the Rosetta
+	// implementation.
+	PortEnv = "PORT"
+
+	// GethEnv is an optional environment variable
+	// used to connect to an already running op-geth node.
+	GethEnv = "GETH"
+
+	// DefaultGethURL is the default URL for
+	// a running op-geth node. This is used
+	// when GethEnv is not populated.
+	DefaultGethURL = "http://127.0.0.1:8545"
+
+	// GenesisBlockIndex is the index of the genesis block.
+	GenesisBlockIndex = int64(0)
+)
+
+var (
+	// MainnetChainID is the chain id of Base Mainnet.
+	MainnetChainID = big.NewInt(8453)
+
+	// SepoliaChainID is the chain id of Base Sepolia.
+	SepoliaChainID = big.NewInt(84532)
+
+	// MainnetGenesisBlockIdentifier is the *types.BlockIdentifier
+	// of the mainnet genesis block.
+	MainnetGenesisBlockIdentifier = &RosettaTypes.BlockIdentifier{
+		Hash:  "0xf712aa9241cc24369b143cf6dce85f0902a9731e70d66818a3a5845b296c73dd",
+		Index: GenesisBlockIndex,
+	}
+
+	// SepoliaGenesisBlockIdentifier is the *types.BlockIdentifier
+	// of the Sepolia genesis block.
+	SepoliaGenesisBlockIdentifier = &RosettaTypes.BlockIdentifier{
+		Hash:  "0x0dcc9e089e30b90ddfc55be9a37dd15bc551aeee999d2e2b51414c54eaf934e4",
+		Index: GenesisBlockIndex,
+	}
+)
+
+// chainConfig returns the chain config of a Base network. Base launched
+// with bedrock, so every fork up to London is active from genesis.
+func chainConfig(chainID *big.Int) *params.ChainConfig {
+	zero := big.NewInt(0)
+	return &params.ChainConfig{
+		ChainID:             chainID,
+		HomesteadBlock:      zero,
+		EIP150Block:         zero,
+		EIP155Block:         zero,
+		EIP158Block:         zero,
+		ByzantiumBlock:      zero,
+		ConstantinopleBlock: zero,
+		PetersburgBlock:     zero,
+		IstanbulBlock:       zero,
+		MuirGlacierBlock:    zero,
+		BerlinBlock:         zero,
+		LondonBlock:         zero,
+	}
+}
+
+// LoadConfiguration attempts to create a new Configuration
+// using the ENVs in the environment.
+func LoadConfiguration() (*configuration.Configuration, error) {
+	config := &configuration.Configuration{}
+
+	switch mode := configuration.Mode(os.Getenv(ModeEnv)); mode {
+	case configuration.ModeOnline, configuration.ModeOffline:
+		config.Mode = mode
+	case "":
+		return nil, errors.New("MODE must be populated")
+	default:
+		return nil, fmt.Errorf("%s is not a valid mode", mode)
+	}
+
+	networkValue := os.Getenv(NetworkEnv)
+	switch networkValue {
+	case Mainnet:
+		config.Network = &RosettaTypes.NetworkIdentifier{
+			Blockchain: Blockchain,
+			Network:    MainnetNetwork,
+		}
+		config.GenesisBlockIdentifier = MainnetGenesisBlockIdentifier
+		config.ChainConfig = chainConfig(MainnetChainID)
+	case Sepolia:
+		config.Network = &RosettaTypes.NetworkIdentifier{
+			Blockchain: Blockchain,
+			Network:    SepoliaNetwork,
+		}
+		config.GenesisBlockIdentifier = SepoliaGenesisBlockIdentifier
+		config.ChainConfig = chainConfig(SepoliaChainID)
+	default:
+		return nil, fmt.Errorf("%s is not a valid network", networkValue)
+	}
+
+	// Base nodes are run separately, with op-node, so the node is always
+	// remote and doesn't expose the admin API
+	config.GethURL = DefaultGethURL
+	if envGethURL := os.Getenv(GethEnv); len(envGethURL) > 0 {
+		config.GethURL = envGethURL
+	}
+	config.RemoteGeth = true
+	config.SkipGethAdmin = true
+
+	portValue := os.Getenv(PortEnv)
+	if len(portValue) == 0 {
+		return nil, errors.New("PORT must be populated")
+	}
+	port, err := strconv.Atoi(portValue)
+	if err != nil || port <= 0 {
+		return nil, fmt.Errorf("unable to parse port %s: %w", portValue, err)
+	}
+	config.Port = port
+
+	config.RosettaCfg = configuration.RosettaConfig{
+		// Deposit transactions are decoded, the fee is paid to the fee vaults
+		// and GetL1DataFee adds the L1 data fee to suggested fees
+		SupportsOpStack: true,
+		SupportsEIP1559: true,
+		// L2 blocks have no block reward
+		SupportRewardTx: false,
+		TraceType:       configuration.GethNativeTrace,
+		Currency: &RosettaTypes.Currency{
+			Symbol:   "ETH",
+			Decimals: 18,
+		},
+		// The ETH of deposits is minted by deposit transactions and the ETH of
+		// withdrawals is sent to the L2ToL1MessagePasser in traces, and the
+		// tokens of the standard bridge are minted and burned with Transfer
+		// logs, so no bridge events are needed
+		BridgeEvents: nil,
+	}
+
+	return config, nil
+}
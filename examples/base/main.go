@@ -0,0 +1,49 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log"
+
+	"github.com/coinbase/rosetta-geth-sdk/examples/base/client"
+	"github.com/coinbase/rosetta-geth-sdk/examples/base/config"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+	"github.com/coinbase/rosetta-geth-sdk/utils"
+)
+
+func main() {
+	// Load configuration using the ENVs in the environment.
+	cfg, err := config.LoadConfiguration()
+	if err != nil {
+		log.Fatalf("unable to load configuration: %v", err)
+	}
+
+	// Create a new Base client by leveraging SDK functionalities. The
+	// client registers the operation type of deposits.
+	client, err := client.NewBaseClient(cfg)
+	if err != nil {
+		log.Fatalf("cannot initialize client: %v", err)
+	}
+
+	// Load all the supported operation types, status
+	types := sdkTypes.LoadTypes()
+	errors := sdkTypes.Errors
+
+	// Bootstrap to start the Rosetta API server
+	err = utils.BootStrap(cfg, types, errors, client)
+	if err != nil {
+		log.Fatalf("unable to bootstrap Rosetta server: %v", err)
+	}
+}
@@ -43,16 +43,23 @@ type EthereumClient struct {
 func (c *EthereumClient) ParseOps(
 	tx *evmClient.LoadedTransaction,
 ) ([]*RosettaTypes.Operation, error) {
-	var ops []*RosettaTypes.Operation
+	b := services.NewOperationBuilder(0)
 
 	// Compute fee operations
-	feeOps := services.FeeOps(tx)
-	ops = append(ops, feeOps...)
+	feeOps, err := services.ParseFeeOps(tx, c.GetRosettaConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Append(feeOps...); err != nil {
+		return nil, err
+	}
 
-	traceOps := services.TraceOps(tx.Trace, len(ops))
-	ops = append(ops, traceOps...)
+	// Append re-indexes the trace operations to follow the fee operations
+	if err := b.Append(services.TransactionTraceOps(tx, 0)...); err != nil {
+		return nil, err
+	}
 
-	return ops, nil
+	return b.Operations(), nil
 }
 
 func (c *EthereumClient) GetBlockReceipts(
@@ -83,7 +90,11 @@ func (c *EthereumClient) GetBlockReceipts(
 			return nil, reqs[i].Error
 		}
 
-		gasPrice, err := evmClient.EffectiveGasPrice(txs[i].Tx, baseFee)
+		if ethReceipts[i] == nil {
+			return nil, fmt.Errorf("got empty receipt for %x", txs[i].Tx.Hash().Hex())
+		}
+
+		gasPrice, gasPriceSource, err := evmClient.ReceiptGasPrice(ethReceipts[i].EffectiveGasPrice, txs[i].Tx, baseFee)
 		if err != nil {
 			return nil, err
 		}
@@ -97,14 +108,13 @@ func (c *EthereumClient) GetBlockReceipts(
 			Logs:           ethReceipts[i].Logs,
 			RawMessage:     nil,
 			TransactionFee: feeAmount,
+			Bloom:          ethReceipts[i].Bloom,
+			Status:         ethReceipts[i].Status,
+			GasPriceSource: gasPriceSource,
 		}
 
 		receipts[i] = receipt
 
-		if ethReceipts[i] == nil {
-			return nil, fmt.Errorf("got empty receipt for %x", txs[i].Tx.Hash().Hex())
-		}
-
 		if ethReceipts[i].BlockHash != blockHash {
 			return nil, fmt.Errorf(
 				"expected block hash %s for Transaction but got %s: %w",
@@ -129,7 +139,7 @@ func (c *EthereumClient) GetTransactionReceipt(
 			return nil, ethereum.NotFound
 		}
 	}
-	gasPrice, err := evmClient.EffectiveGasPrice(tx.Transaction, tx.BaseFee)
+	gasPrice, gasPriceSource, err := evmClient.ReceiptGasPrice(r.EffectiveGasPrice, tx.Transaction, tx.BaseFee)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +152,9 @@ func (c *EthereumClient) GetTransactionReceipt(
 		Logs:           r.Logs,
 		RawMessage:     nil,
 		TransactionFee: feeAmount,
+		Bloom:          r.Bloom,
+		Status:         r.Status,
+		GasPriceSource: gasPriceSource,
 	}, err
 }
 
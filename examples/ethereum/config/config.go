@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -102,6 +103,26 @@ const (
 	// from the token white list or fetch from nodes
 	UseTokenWhiteListMetadataEnv = "USE_TOKEN_WHITE_LIST_METADATA"
 
+	// TokenWhiteListURLEnv is an optional environment variable
+	// read to load the token white list from an http(s) URL
+	// or a file, refreshed at runtime, instead of the built-in list
+	TokenWhiteListURLEnv = "TOKEN_WHITE_LIST_URL"
+
+	// TokenWhiteListRefreshEnv is an optional environment variable
+	// read to determine how often the token white list of
+	// TokenWhiteListURLEnv is refreshed, e.g. 10m
+	TokenWhiteListRefreshEnv = "TOKEN_WHITE_LIST_REFRESH"
+
+	// CurrencyStorePathEnv is an optional environment variable
+	// read to persist the currencies of ERC20 tokens fetched
+	// from the node in a directory, so they survive restarts
+	CurrencyStorePathEnv = "CURRENCY_STORE_PATH"
+
+	// CurrencyStoreTTLEnv is an optional environment variable
+	// read to determine after how long the currencies of
+	// CurrencyStorePathEnv are fetched again, e.g. 24h
+	CurrencyStoreTTLEnv = "CURRENCY_STORE_TTL"
+
 	// GethEnv is an optional environment variable
 	// used to connect rosetta-ethereum to an already
 	// running geth node.
@@ -246,6 +267,22 @@ func LoadConfiguration() (*configuration.Configuration, error) {
 		}
 	}
 
+	var tokenWhiteListRefresh time.Duration
+	if val := os.Getenv(TokenWhiteListRefreshEnv); val != "" {
+		tokenWhiteListRefresh, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse token white list refresh %s: %w", val, err)
+		}
+	}
+
+	var currencyStoreTTL time.Duration
+	if val := os.Getenv(CurrencyStoreTTLEnv); val != "" {
+		currencyStoreTTL, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse currency store ttl %s: %w", val, err)
+		}
+	}
+
 	payload := []configuration.Token{}
 	config.RosettaCfg = configuration.RosettaConfig{
 		SupportRewardTx: true,
@@ -258,6 +295,12 @@ func LoadConfiguration() (*configuration.Configuration, error) {
 		FilterTokens:              tokenFilterValue,
 		UseTokenWhiteListMetadata: useTokenWhiteListMetadataValue,
 		TokenWhiteList:            payload,
+		// The SDK client serves the list of TokenWhiteListURL, see
+		// client.TokenWhiteListSource
+		TokenWhiteListURL:             os.Getenv(TokenWhiteListURLEnv),
+		TokenWhiteListRefreshInterval: tokenWhiteListRefresh,
+		CurrencyStorePath:             os.Getenv(CurrencyStorePathEnv),
+		CurrencyStoreTTL:              currencyStoreTTL,
 	}
 
 	return config, nil
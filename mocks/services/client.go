@@ -291,6 +291,34 @@ func (_m *Client) GetContractCurrency(addr common.Address, erc20 bool) (*client.
 	return r0, r1
 }
 
+// GetContractDeploymentGasLimit provides a mock function with given fields: ctx, fromAddress, value, data
+func (_m *Client) GetContractDeploymentGasLimit(ctx context.Context, fromAddress string, value *big.Int, data []byte) (uint64, error) {
+	ret := _m.Called(ctx, fromAddress, value, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetContractDeploymentGasLimit")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *big.Int, []byte) (uint64, error)); ok {
+		return rf(ctx, fromAddress, value, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *big.Int, []byte) uint64); ok {
+		r0 = rf(ctx, fromAddress, value, data)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *big.Int, []byte) error); ok {
+		r1 = rf(ctx, fromAddress, value, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCustomizedBlockBody provides a mock function with given fields: raw, body
 func (_m *Client) GetCustomizedBlockBody(raw json.RawMessage, body *client.RPCBlock) error {
 	ret := _m.Called(raw, body)
@@ -543,6 +571,66 @@ func (_m *Client) GetNonce(_a0 context.Context, _a1 client.Options) (uint64, err
 	return r0, r1
 }
 
+// GetPendingTransactionsForSender provides a mock function with given fields: ctx, address
+func (_m *Client) GetPendingTransactionsForSender(ctx context.Context, address common.Address) (*client.SenderPoolTransactions, error) {
+	ret := _m.Called(ctx, address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingTransactionsForSender")
+	}
+
+	var r0 *client.SenderPoolTransactions
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address) (*client.SenderPoolTransactions, error)); ok {
+		return rf(ctx, address)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address) *client.SenderPoolTransactions); ok {
+		r0 = rf(ctx, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.SenderPoolTransactions)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address) error); ok {
+		r1 = rf(ctx, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawBlock provides a mock function with given fields: ctx, blockIdentifier
+func (_m *Client) GetRawBlock(ctx context.Context, blockIdentifier *types.PartialBlockIdentifier) (*client.RawBlock, error) {
+	ret := _m.Called(ctx, blockIdentifier)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRawBlock")
+	}
+
+	var r0 *client.RawBlock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) (*client.RawBlock, error)); ok {
+		return rf(ctx, blockIdentifier)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) *client.RawBlock); ok {
+		r0 = rf(ctx, blockIdentifier)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.RawBlock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.PartialBlockIdentifier) error); ok {
+		r1 = rf(ctx, blockIdentifier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRosettaConfig provides a mock function with given fields:
 func (_m *Client) GetRosettaConfig() configuration.RosettaConfig {
 	ret := _m.Called()
@@ -621,6 +709,41 @@ func (_m *Client) GetUncles(ctx context.Context, head *coretypes.Header, body *c
 	return r0, r1
 }
 
+// HashOverride provides a mock function with given fields: ctx, rawTx
+func (_m *Client) HashOverride(ctx context.Context, rawTx []byte) (string, bool, error) {
+	ret := _m.Called(ctx, rawTx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashOverride")
+	}
+
+	var r0 string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (string, bool, error)); ok {
+		return rf(ctx, rawTx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) string); ok {
+		r0 = rf(ctx, rawTx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) bool); ok {
+		r1 = rf(ctx, rawTx)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []byte) error); ok {
+		r2 = rf(ctx, rawTx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // ParseOps provides a mock function with given fields: tx
 func (_m *Client) ParseOps(tx *client.LoadedTransaction) ([]*types.Operation, error) {
 	ret := _m.Called(tx)
@@ -0,0 +1,401 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rosettaclient is a typed client of the Rosetta services built with
+// this SDK, for Go services consuming a deployed endpoint. It wraps the client
+// of rosetta-sdk-go with EVM specific helpers.
+package rosettaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaClient "github.com/coinbase/rosetta-sdk-go/client"
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// DefaultPollInterval is how often AwaitConfirmation polls the network
+	// status by default
+	DefaultPollInterval = 2 * time.Second
+
+	defaultUserAgent = "rosetta-geth-sdk"
+)
+
+// Error is an error returned by a Rosetta endpoint
+type Error struct {
+	Endpoint string
+	Err      *RosettaTypes.Error
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: rosetta error %d: %s", e.Endpoint, e.Err.Code, e.Err.Message)
+	if e.Err.Description != nil {
+		msg += ": " + *e.Err.Description
+	}
+
+	return msg
+}
+
+// Client is a typed client of a Rosetta service for one network
+type Client struct {
+	api          *RosettaClient.APIClient
+	network      *RosettaTypes.NetworkIdentifier
+	currency     *RosettaTypes.Currency
+	httpClient   *http.Client
+	userAgent    string
+	pollInterval time.Duration
+}
+
+// Transfer is a transaction submitted by a Client
+type Transfer struct {
+	TransactionIdentifier *RosettaTypes.TransactionIdentifier
+
+	// SubmittedAt is the index of the current block when the transaction was
+	// submitted, from which AwaitConfirmation looks for it
+	SubmittedAt int64
+}
+
+// New returns a Client of the Rosetta service at url for network
+func New(url string, network *RosettaTypes.NetworkIdentifier, opts ...Option) *Client {
+	c := &Client{
+		network:      network,
+		currency:     sdkTypes.Currency,
+		httpClient:   http.DefaultClient,
+		userAgent:    defaultUserAgent,
+		pollInterval: DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.api = RosettaClient.NewAPIClient(RosettaClient.NewConfiguration(url, c.userAgent, c.httpClient))
+
+	return c
+}
+
+// API returns the rosetta-sdk-go client, for the endpoints without helpers
+func (c *Client) API() *RosettaClient.APIClient {
+	return c.api
+}
+
+// NetworkStatus returns the /network/status of the network
+func (c *Client) NetworkStatus(ctx context.Context) (*RosettaTypes.NetworkStatusResponse, error) {
+	resp, rosettaErr, err := c.api.NetworkAPI.NetworkStatus(ctx, &RosettaTypes.NetworkRequest{
+		NetworkIdentifier: c.network,
+	})
+	if err := wrapError("/network/status", rosettaErr, err); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Block returns the block at index
+func (c *Client) Block(ctx context.Context, index int64) (*RosettaTypes.Block, error) {
+	resp, rosettaErr, err := c.api.BlockAPI.Block(ctx, &RosettaTypes.BlockRequest{
+		NetworkIdentifier: c.network,
+		BlockIdentifier:   &RosettaTypes.PartialBlockIdentifier{Index: &index},
+	})
+	if err := wrapError("/block", rosettaErr, err); err != nil {
+		return nil, err
+	}
+	if resp.Block == nil {
+		return nil, fmt.Errorf("block %d is not available", index)
+	}
+
+	return resp.Block, nil
+}
+
+// Balance returns the balance of address in currency at block, or at the
+// current block when block is nil, and the block of the balance
+func (c *Client) Balance(
+	ctx context.Context,
+	address common.Address,
+	currency *RosettaTypes.Currency,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*big.Int, *RosettaTypes.BlockIdentifier, error) {
+	resp, rosettaErr, err := c.api.AccountAPI.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+		NetworkIdentifier: c.network,
+		AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: address.Hex()},
+		BlockIdentifier:   block,
+		Currencies:        []*RosettaTypes.Currency{currency},
+	})
+	if err := wrapError("/account/balance", rosettaErr, err); err != nil {
+		return nil, nil, err
+	}
+
+	for _, balance := range resp.Balances {
+		if RosettaTypes.Hash(balance.Currency) != RosettaTypes.Hash(currency) {
+			continue
+		}
+		value, ok := new(big.Int).SetString(balance.Value, 10) // nolint:gomnd
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid balance %q of %s", balance.Value, address)
+		}
+		return value, resp.BlockIdentifier, nil
+	}
+
+	return nil, nil, fmt.Errorf("no balance of %s in %s", address, currency.Symbol)
+}
+
+// NativeBalance returns the balance of address in the native currency
+func (c *Client) NativeBalance(
+	ctx context.Context,
+	address common.Address,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*big.Int, *RosettaTypes.BlockIdentifier, error) {
+	return c.Balance(ctx, address, c.currency, block)
+}
+
+// ERC20Balance returns the balance of address in the ERC20 token of contract.
+// symbol and decimals must match the token white list of the service when it
+// filters tokens.
+func (c *Client) ERC20Balance(
+	ctx context.Context,
+	address common.Address,
+	contract common.Address,
+	symbol string,
+	decimals int32,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*big.Int, *RosettaTypes.BlockIdentifier, error) {
+	return c.Balance(ctx, address, client.Erc20Currency(symbol, decimals, contract.Hex()), block)
+}
+
+// TransferNative builds, signs and submits the transfer of value in the native
+// currency from the account of signer to to, with the /construction
+// endpoints. The unsigned transaction is parsed back and checked against the
+// transfer before it is signed.
+func (c *Client) TransferNative(
+	ctx context.Context,
+	signer Signer,
+	to common.Address,
+	value *big.Int,
+) (*Transfer, error) {
+	publicKey := signer.PublicKey()
+	derived, rosettaErr, err := c.api.ConstructionAPI.ConstructionDerive(ctx, &RosettaTypes.ConstructionDeriveRequest{
+		NetworkIdentifier: c.network,
+		PublicKey:         publicKey,
+	})
+	if err := wrapError("/construction/derive", rosettaErr, err); err != nil {
+		return nil, err
+	}
+	if derived.AccountIdentifier == nil {
+		return nil, errors.New("no account derived from the public key")
+	}
+	from := derived.AccountIdentifier.Address
+
+	operations := []*RosettaTypes.Operation{
+		transferOp(0, from, new(big.Int).Neg(value), c.currency),
+		transferOp(1, to.Hex(), value, c.currency),
+	}
+
+	preprocessed, rosettaErr, err := c.api.ConstructionAPI.ConstructionPreprocess(
+		ctx,
+		&RosettaTypes.ConstructionPreprocessRequest{
+			NetworkIdentifier: c.network,
+			Operations:        operations,
+		},
+	)
+	if err := wrapError("/construction/preprocess", rosettaErr, err); err != nil {
+		return nil, err
+	}
+
+	metadata, rosettaErr, err := c.api.ConstructionAPI.ConstructionMetadata(ctx, &RosettaTypes.ConstructionMetadataRequest{
+		NetworkIdentifier: c.network,
+		Options:           preprocessed.Options,
+		PublicKeys:        []*RosettaTypes.PublicKey{publicKey},
+	})
+	if err := wrapError("/construction/metadata", rosettaErr, err); err != nil {
+		return nil, err
+	}
+
+	payloads, rosettaErr, err := c.api.ConstructionAPI.ConstructionPayloads(ctx, &RosettaTypes.ConstructionPayloadsRequest{
+		NetworkIdentifier: c.network,
+		Operations:        operations,
+		Metadata:          metadata.Metadata,
+		PublicKeys:        []*RosettaTypes.PublicKey{publicKey},
+	})
+	if err := wrapError("/construction/payloads", rosettaErr, err); err != nil {
+		return nil, err
+	}
+	if len(payloads.Payloads) != 1 {
+		return nil, fmt.Errorf("expected 1 payload to sign, got %d", len(payloads.Payloads))
+	}
+
+	parsed, rosettaErr, err := c.api.ConstructionAPI.ConstructionParse(ctx, &RosettaTypes.ConstructionParseRequest{
+		NetworkIdentifier: c.network,
+		Signed:            false,
+		Transaction:       payloads.UnsignedTransaction,
+	})
+	if err := wrapError("/construction/parse", rosettaErr, err); err != nil {
+		return nil, err
+	}
+	if err := checkOperations(operations, parsed.Operations); err != nil {
+		return nil, fmt.Errorf("unsigned transaction does not match the transfer: %w", err)
+	}
+
+	signature, err := signer.Sign(ctx, payloads.Payloads[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the transaction: %w", err)
+	}
+
+	combined, rosettaErr, err := c.api.ConstructionAPI.ConstructionCombine(ctx, &RosettaTypes.ConstructionCombineRequest{
+		NetworkIdentifier:   c.network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures:          []*RosettaTypes.Signature{signature},
+	})
+	if err := wrapError("/construction/combine", rosettaErr, err); err != nil {
+		return nil, err
+	}
+
+	status, err := c.NetworkStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted, rosettaErr, err := c.api.ConstructionAPI.ConstructionSubmit(ctx, &RosettaTypes.ConstructionSubmitRequest{
+		NetworkIdentifier: c.network,
+		SignedTransaction: combined.SignedTransaction,
+	})
+	if err := wrapError("/construction/submit", rosettaErr, err); err != nil {
+		return nil, err
+	}
+
+	return &Transfer{
+		TransactionIdentifier: submitted.TransactionIdentifier,
+		SubmittedAt:           status.CurrentBlockIdentifier.Index,
+	}, nil
+}
+
+// AwaitConfirmation waits until the transaction is in a block with at least
+// confirmations blocks on top of it, including its own, looking for it from
+// the block at fromIndex, and returns the block. The block is fetched again
+// once confirmed, and the transaction is looked for again from its index when
+// the block was reorged out. It returns when ctx is done.
+func (c *Client) AwaitConfirmation(
+	ctx context.Context,
+	transaction *RosettaTypes.TransactionIdentifier,
+	fromIndex int64,
+	confirmations int64,
+) (*RosettaTypes.BlockIdentifier, error) {
+	if confirmations < 1 {
+		confirmations = 1
+	}
+
+	next := fromIndex
+	var found *RosettaTypes.BlockIdentifier
+	for {
+		status, err := c.NetworkStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tip := status.CurrentBlockIdentifier.Index
+
+		for ; found == nil && next <= tip; next++ {
+			block, err := c.Block(ctx, next)
+			if err != nil {
+				return nil, err
+			}
+			if containsTransaction(block, transaction) {
+				found = block.BlockIdentifier
+			}
+		}
+
+		if found != nil && tip-found.Index+1 >= confirmations {
+			block, err := c.Block(ctx, found.Index)
+			if err != nil {
+				return nil, err
+			}
+			if block.BlockIdentifier.Hash == found.Hash {
+				return found, nil
+			}
+
+			next, found = found.Index, nil
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// wrapError returns the error of a call to endpoint, if any
+func wrapError(endpoint string, rosettaErr *RosettaTypes.Error, err error) error {
+	if rosettaErr != nil {
+		return &Error{Endpoint: endpoint, Err: rosettaErr}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// transferOp returns the operation at index transferring value to or from the
+// account of address
+func transferOp(index int64, address string, value *big.Int, currency *RosettaTypes.Currency) *RosettaTypes.Operation {
+	return &RosettaTypes.Operation{
+		OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: index},
+		Type:                sdkTypes.CallOpType,
+		Account:             &RosettaTypes.AccountIdentifier{Address: address},
+		Amount:              &RosettaTypes.Amount{Value: value.String(), Currency: currency},
+	}
+}
+
+// checkOperations checks that the parsed operations transfer the amounts of
+// the expected operations between the same accounts
+func checkOperations(expected []*RosettaTypes.Operation, parsed []*RosettaTypes.Operation) error {
+	if len(parsed) != len(expected) {
+		return fmt.Errorf("expected %d operations, got %d", len(expected), len(parsed))
+	}
+
+	for i, op := range expected {
+		got := parsed[i]
+		if got.Account == nil || !strings.EqualFold(got.Account.Address, op.Account.Address) {
+			return fmt.Errorf("operation %d: expected account %s", i, op.Account.Address)
+		}
+		if got.Amount == nil || got.Amount.Value != op.Amount.Value {
+			return fmt.Errorf("operation %d: expected amount %s", i, op.Amount.Value)
+		}
+		if RosettaTypes.Hash(got.Amount.Currency) != RosettaTypes.Hash(op.Amount.Currency) {
+			return fmt.Errorf("operation %d: expected currency %s", i, op.Amount.Currency.Symbol)
+		}
+	}
+
+	return nil
+}
+
+// containsTransaction returns whether block contains transaction
+func containsTransaction(block *RosettaTypes.Block, transaction *RosettaTypes.TransactionIdentifier) bool {
+	for _, tx := range block.Transactions {
+		if strings.EqualFold(tx.TransactionIdentifier.Hash, transaction.Hash) {
+			return true
+		}
+	}
+
+	return false
+}
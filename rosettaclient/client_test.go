@@ -0,0 +1,286 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rosettaclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var network = &RosettaTypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"}
+
+// handler answers the requests to an endpoint with a response or a Rosetta
+// error
+type handler func(body json.RawMessage) (interface{}, *RosettaTypes.Error)
+
+// newFakeService returns a Rosetta service answering with handlers, keyed by
+// endpoint. The server is closed when the test ends.
+func newFakeService(t *testing.T, handlers map[string]handler) *httptest.Server {
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		handle, ok := handlers[r.URL.Path]
+		mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, rosettaErr := handle(body)
+		w.Header().Set("Content-Type", "application/json")
+		if rosettaErr != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			assert.NoError(t, json.NewEncoder(w).Encode(rosettaErr))
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestERC20Balance(t *testing.T) {
+	address := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	currency := client.Erc20Currency("USDC", 6, usdc.Hex())
+	block := &RosettaTypes.BlockIdentifier{Index: 10, Hash: "0x0a"}
+
+	server := newFakeService(t, map[string]handler{
+		"/account/balance": func(body json.RawMessage) (interface{}, *RosettaTypes.Error) {
+			var req RosettaTypes.AccountBalanceRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, network, req.NetworkIdentifier)
+			assert.Equal(t, address.Hex(), req.AccountIdentifier.Address)
+			assert.Equal(t, []*RosettaTypes.Currency{currency}, req.Currencies)
+
+			return &RosettaTypes.AccountBalanceResponse{
+				BlockIdentifier: block,
+				Balances:        []*RosettaTypes.Amount{{Value: "1500000", Currency: currency}},
+			}, nil
+		},
+	})
+
+	balance, at, err := New(server.URL, network).ERC20Balance(context.Background(), address, usdc, "USDC", 6, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1500000), balance)
+	assert.Equal(t, block, at)
+}
+
+func TestError(t *testing.T) {
+	server := newFakeService(t, map[string]handler{
+		"/network/status": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+			return nil, sdkTypes.ErrGeth
+		},
+	})
+
+	_, err := New(server.URL, network).NetworkStatus(context.Background())
+	var rosettaErr *Error
+	assert.True(t, errors.As(err, &rosettaErr))
+	assert.Equal(t, "/network/status", rosettaErr.Endpoint)
+	assert.Equal(t, sdkTypes.ErrGeth.Code, rosettaErr.Err.Code)
+}
+
+func TestTransferNative(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	value := big.NewInt(1000)
+	payload := &RosettaTypes.SigningPayload{
+		AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: sender.Hex()},
+		Bytes:             crypto.Keccak256([]byte("unsigned")),
+		SignatureType:     RosettaTypes.EcdsaRecovery,
+	}
+
+	tests := map[string]struct {
+		parsedValue string
+
+		expectedErr string
+	}{
+		"transfer": {
+			parsedValue: "1000",
+		},
+		"tampered transaction": {
+			parsedValue: "2000",
+			expectedErr: "unsigned transaction does not match the transfer: operation 1: expected amount 1000",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			submitted := false
+			server := newFakeService(t, map[string]handler{
+				"/construction/derive": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					return &RosettaTypes.ConstructionDeriveResponse{
+						AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: sender.Hex()},
+					}, nil
+				},
+				"/construction/preprocess": func(body json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					var req RosettaTypes.ConstructionPreprocessRequest
+					assert.NoError(t, json.Unmarshal(body, &req))
+					assert.Len(t, req.Operations, 2)
+					assert.Equal(t, "-1000", req.Operations[0].Amount.Value)
+					assert.Equal(t, recipient.Hex(), req.Operations[1].Account.Address)
+
+					return &RosettaTypes.ConstructionPreprocessResponse{
+						Options: map[string]interface{}{"from": sender.Hex()},
+					}, nil
+				},
+				"/construction/metadata": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					return &RosettaTypes.ConstructionMetadataResponse{
+						Metadata: map[string]interface{}{"nonce": "0x0"},
+					}, nil
+				},
+				"/construction/payloads": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					return &RosettaTypes.ConstructionPayloadsResponse{
+						UnsignedTransaction: "unsigned",
+						Payloads:            []*RosettaTypes.SigningPayload{payload},
+					}, nil
+				},
+				"/construction/parse": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					return &RosettaTypes.ConstructionParseResponse{
+						Operations: []*RosettaTypes.Operation{
+							transferOp(0, sender.Hex(), big.NewInt(-1000), sdkTypes.Currency),
+							{
+								OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+								Type:                sdkTypes.CallOpType,
+								Account:             &RosettaTypes.AccountIdentifier{Address: recipient.Hex()},
+								Amount:              &RosettaTypes.Amount{Value: test.parsedValue, Currency: sdkTypes.Currency},
+							},
+						},
+					}, nil
+				},
+				"/construction/combine": func(body json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					var req RosettaTypes.ConstructionCombineRequest
+					assert.NoError(t, json.Unmarshal(body, &req))
+					require.Len(t, req.Signatures, 1)
+					publicKey, err := crypto.SigToPub(payload.Bytes, req.Signatures[0].Bytes)
+					assert.NoError(t, err)
+					assert.Equal(t, sender, crypto.PubkeyToAddress(*publicKey))
+
+					return &RosettaTypes.ConstructionCombineResponse{SignedTransaction: "signed"}, nil
+				},
+				"/network/status": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					return &RosettaTypes.NetworkStatusResponse{
+						CurrentBlockIdentifier: &RosettaTypes.BlockIdentifier{Index: 42, Hash: "0x2a"},
+						GenesisBlockIdentifier: &RosettaTypes.BlockIdentifier{Index: 0, Hash: "0x00"},
+					}, nil
+				},
+				"/construction/submit": func(body json.RawMessage) (interface{}, *RosettaTypes.Error) {
+					submitted = true
+					return &RosettaTypes.TransactionIdentifierResponse{
+						TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0xabc"},
+					}, nil
+				},
+			})
+
+			transfer, err := New(server.URL, network).TransferNative(
+				context.Background(),
+				NewKeySigner(key),
+				recipient,
+				value,
+			)
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				assert.False(t, submitted)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &Transfer{
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0xabc"},
+				SubmittedAt:           42,
+			}, transfer)
+		})
+	}
+}
+
+func TestAwaitConfirmation(t *testing.T) {
+	transaction := &RosettaTypes.TransactionIdentifier{Hash: "0xabc"}
+	block := func(index int64, hash string, transactions ...*RosettaTypes.Transaction) *RosettaTypes.Block {
+		return &RosettaTypes.Block{
+			BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: index, Hash: hash},
+			Transactions:    transactions,
+		}
+	}
+	included := &RosettaTypes.Transaction{TransactionIdentifier: transaction}
+
+	// The transaction is included in block 11, which is reorged out once the
+	// chain reaches block 12, and included again in block 12
+	var mu sync.Mutex
+	tip := int64(10)
+	chain := map[int64]*RosettaTypes.Block{
+		10: block(10, "0x10"),
+		11: block(11, "0x11", included),
+	}
+	server := newFakeService(t, map[string]handler{
+		"/network/status": func(json.RawMessage) (interface{}, *RosettaTypes.Error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if tip < 13 {
+				tip++
+			}
+			if tip == 12 {
+				chain[11] = block(11, "0x11b")
+				chain[12] = block(12, "0x12", included)
+			}
+			if tip == 13 {
+				chain[13] = block(13, "0x13")
+			}
+			return &RosettaTypes.NetworkStatusResponse{
+				CurrentBlockIdentifier: chain[tip].BlockIdentifier,
+			}, nil
+		},
+		"/block": func(body json.RawMessage) (interface{}, *RosettaTypes.Error) {
+			var req RosettaTypes.BlockRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+
+			mu.Lock()
+			defer mu.Unlock()
+			return &RosettaTypes.BlockResponse{Block: chain[*req.BlockIdentifier.Index]}, nil
+		},
+	})
+
+	c := New(server.URL, network, WithPollInterval(time.Millisecond))
+	confirmed, err := c.AwaitConfirmation(context.Background(), transaction, 10, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{Index: 12, Hash: "0x12"}, confirmed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.AwaitConfirmation(ctx, &RosettaTypes.TransactionIdentifier{Hash: "0xdef"}, 13, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rosettaclient
+
+import (
+	"net/http"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Option configures a Client created with New
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with, e.g. to set a
+// timeout or authenticate requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the user agent of the requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithCurrency sets the native currency of the network, ETH by default
+func WithCurrency(currency *RosettaTypes.Currency) Option {
+	return func(c *Client) {
+		c.currency = currency
+	}
+}
+
+// WithPollInterval sets how often AwaitConfirmation polls the network status
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rosettaclient
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/coinbase/rosetta-geth-sdk/signer"
+)
+
+// Signer signs the payloads of the transactions built by a Client, see
+// signer.Signer
+type Signer = signer.Signer
+
+// KeySigner is a Signer holding a secp256k1 private key in memory
+type KeySigner = signer.KeySigner
+
+// NewKeySigner returns a KeySigner signing with key
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return signer.NewKeySigner(key)
+}
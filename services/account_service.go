@@ -17,20 +17,40 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
+	"math/big"
 
+	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 
 	construction "github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/services/validator"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
+// SubAccountProvider is an optional interface a client can implement to expose
+// additional balance buckets of an address (e.g. staked, escrowed or vesting
+// balances) as Rosetta sub-accounts. AccountBalance calls it for any request
+// that specifies a SubAccountIdentifier.
+type SubAccountProvider interface {
+	SubAccountBalance(
+		ctx context.Context,
+		account *types.AccountIdentifier,
+		blockIdentifier *types.PartialBlockIdentifier,
+		currencies []*types.Currency,
+	) (*types.AccountBalanceResponse, error)
+}
+
 // AccountAPIService implements the server.AccountAPIServicer interface.
 type AccountAPIService struct {
-	config *configuration.Configuration
-	types  *AssetTypes.Types
-	errors []*types.Error
-	client construction.Client
+	config    *configuration.Configuration
+	types     *AssetTypes.Types
+	errors    []*types.Error
+	client    construction.Client
+	validator *validator.TrustlessValidator
 }
 
 // NewAccountAPIService returns a new *AccountAPIService.
@@ -40,11 +60,17 @@ func NewAccountAPIService(
 	errors []*types.Error,
 	client construction.Client,
 ) *AccountAPIService {
+	var trustlessValidator *validator.TrustlessValidator
+	if cfg.RosettaCfg.EnableTrustlessAccountValidation {
+		trustlessValidator = validator.NewTrustlessValidator(cfg, client)
+	}
+
 	return &AccountAPIService{
-		config: cfg,
-		types:  types,
-		errors: errors,
-		client: client,
+		config:    cfg,
+		types:     types,
+		errors:    errors,
+		client:    client,
+		validator: trustlessValidator,
 	}
 }
 
@@ -61,16 +87,38 @@ func (s *AccountAPIService) AccountBalance(
 		return nil, AssetTypes.ErrInvalidInput
 	}
 
-	balanceResponse, err := s.client.Balance(
-		ctx,
-		request.AccountIdentifier,
-		request.BlockIdentifier,
-		request.Currencies,
-	)
+	address, err := evmClient.NormalizeAddress(request.AccountIdentifier.Address)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrInvalidAddress, err)
+	}
+	if address != request.AccountIdentifier.Address {
+		normalized := *request
+		normalized.AccountIdentifier = &types.AccountIdentifier{
+			Address:    address,
+			SubAccount: request.AccountIdentifier.SubAccount,
+			Metadata:   request.AccountIdentifier.Metadata,
+		}
+		request = &normalized
+	}
+
+	if request.AccountIdentifier.SubAccount != nil {
+		if _, ok := s.client.(SubAccountProvider); !ok {
+			return nil, AssetTypes.WrapErr(
+				AssetTypes.ErrInvalidInput,
+				fmt.Errorf("sub account %s is not supported", request.AccountIdentifier.SubAccount.Address),
+			)
+		}
+	}
+
+	balanceResponse, err := s.balance(ctx, request)
 	if err != nil {
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
 	}
 
+	if err := s.validateBalance(ctx, request, balanceResponse); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrValidationFailed, err)
+	}
+
 	// get block hash if the block hash can't be calculated from keccak256 hash of its RLP encoding
 	balanceResponse.BlockIdentifier.Hash, err = s.client.GetBlockHash(ctx, *balanceResponse.BlockIdentifier)
 	if err != nil {
@@ -80,6 +128,81 @@ func (s *AccountAPIService) AccountBalance(
 	return balanceResponse, nil
 }
 
+// validateBalance validates the native balance of resp against the state root of
+// its block when trustless account validation is enabled. Failures are only logged
+// unless the validation policy is FailValidationPolicy.
+func (s *AccountAPIService) validateBalance(
+	ctx context.Context,
+	request *types.AccountBalanceRequest,
+	resp *types.AccountBalanceResponse,
+) error {
+	if s.validator == nil || request.AccountIdentifier.SubAccount != nil {
+		return nil
+	}
+
+	var nativeBalance *types.Amount
+	for _, amount := range resp.Balances {
+		if utils.Equal(amount.Currency, s.config.RosettaCfg.Currency) {
+			nativeBalance = amount
+		}
+	}
+	if nativeBalance == nil {
+		return nil
+	}
+
+	balance, ok := new(big.Int).SetString(nativeBalance.Value, 10) // nolint:gomnd
+	if !ok {
+		return fmt.Errorf("invalid balance %s", nativeBalance.Value)
+	}
+	result, err := s.validator.ValidateAccountWithResult(
+		ctx,
+		common.HexToAddress(request.AccountIdentifier.Address),
+		common.HexToHash(resp.BlockIdentifier.Hash),
+		balance,
+	)
+	if result != nil && result.Skipped {
+		if result.ProvenBalance != nil {
+			log.Printf(
+				"account %s could not be proven at block %d, proven balance %s at block %d",
+				request.AccountIdentifier.Address,
+				resp.BlockIdentifier.Index,
+				result.ProvenBalance,
+				result.BlockNumber,
+			)
+		} else {
+			log.Printf(
+				"account %s could not be proven at block %d",
+				request.AccountIdentifier.Address,
+				resp.BlockIdentifier.Index,
+			)
+		}
+	}
+
+	return applyValidationPolicy(ctx, s.config, fmt.Sprintf("account %s", request.AccountIdentifier.Address), err)
+}
+
+// balance returns the balance of the account, or of its sub-account if one is specified
+func (s *AccountAPIService) balance(
+	ctx context.Context,
+	request *types.AccountBalanceRequest,
+) (*types.AccountBalanceResponse, error) {
+	if provider, ok := s.client.(SubAccountProvider); ok && request.AccountIdentifier.SubAccount != nil {
+		return provider.SubAccountBalance(
+			ctx,
+			request.AccountIdentifier,
+			request.BlockIdentifier,
+			request.Currencies,
+		)
+	}
+
+	return s.client.Balance(
+		ctx,
+		request.AccountIdentifier,
+		request.BlockIdentifier,
+		request.Currencies,
+	)
+}
+
 // AccountCoins implements /account/coins.
 func (s *AccountAPIService) AccountCoins(
 	ctx context.Context,
@@ -13,3 +13,204 @@
 // limitations under the License.
 
 package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type subAccountClient struct {
+	*mockedServices.Client
+}
+
+func (c *subAccountClient) SubAccountBalance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	ret := c.Called(ctx, account, blockIdentifier, currencies)
+	return ret.Get(0).(*RosettaTypes.AccountBalanceResponse), ret.Error(1)
+}
+
+// aliasNormalizer maps a single alias to its address, and rejects other
+// aliases
+type aliasNormalizer struct {
+	alias   string
+	address string
+}
+
+func (n aliasNormalizer) NormalizeAddress(address string) (string, error) {
+	switch {
+	case address == n.alias:
+		return n.address, nil
+	case strings.HasPrefix(address, "0x"):
+		return address, nil
+	default:
+		return "", fmt.Errorf("unknown alias %s", address)
+	}
+}
+
+func TestAccountBalance(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	ctx := context.Background()
+	blockIdentifier := &RosettaTypes.BlockIdentifier{
+		Index: 10992,
+		Hash:  "0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+	}
+	account := &RosettaTypes.AccountIdentifier{
+		Address: "0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1",
+	}
+	stakedAccount := &RosettaTypes.AccountIdentifier{
+		Address:    account.Address,
+		SubAccount: &RosettaTypes.SubAccountIdentifier{Address: "staked"},
+	}
+
+	t.Run("main account", func(t *testing.T) {
+		mockClient := &mockedServices.Client{}
+		servicer := NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, mockClient)
+
+		resp := &RosettaTypes.AccountBalanceResponse{
+			BlockIdentifier: blockIdentifier,
+			Balances:        []*RosettaTypes.Amount{{Value: "10", Currency: AssetTypes.Currency}},
+		}
+		mockClient.On("Balance", ctx, account, mock.Anything, mock.Anything).Return(resp, nil).Once()
+		mockClient.On("GetBlockHash", ctx, *blockIdentifier).Return(blockIdentifier.Hash, nil).Once()
+
+		balance, err := servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+			AccountIdentifier: account,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, resp, balance)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("sub account", func(t *testing.T) {
+		mockClient := &subAccountClient{&mockedServices.Client{}}
+		servicer := NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, mockClient)
+
+		resp := &RosettaTypes.AccountBalanceResponse{
+			BlockIdentifier: blockIdentifier,
+			Balances:        []*RosettaTypes.Amount{{Value: "32", Currency: AssetTypes.Currency}},
+		}
+		mockClient.On("SubAccountBalance", ctx, stakedAccount, mock.Anything, mock.Anything).Return(resp, nil).Once()
+		mockClient.On("GetBlockHash", ctx, *blockIdentifier).Return(blockIdentifier.Hash, nil).Once()
+
+		balance, err := servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+			AccountIdentifier: stakedAccount,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, resp, balance)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("sub account not supported", func(t *testing.T) {
+		mockClient := &mockedServices.Client{}
+		servicer := NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, mockClient)
+
+		balance, err := servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+			AccountIdentifier: stakedAccount,
+		})
+		assert.Nil(t, balance)
+		assert.Equal(t, AssetTypes.ErrInvalidInput.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+	t.Run("aliased account", func(t *testing.T) {
+		evmClient.SetAddressNormalizer(aliasNormalizer{alias: "0.0.1234", address: account.Address})
+		t.Cleanup(func() { evmClient.SetAddressNormalizer(nil) })
+		mockClient := &mockedServices.Client{}
+		servicer := NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, mockClient)
+
+		resp := &RosettaTypes.AccountBalanceResponse{
+			BlockIdentifier: blockIdentifier,
+			Balances:        []*RosettaTypes.Amount{{Value: "10", Currency: AssetTypes.Currency}},
+		}
+		mockClient.On("Balance", ctx, account, mock.Anything, mock.Anything).Return(resp, nil).Once()
+		mockClient.On("GetBlockHash", ctx, *blockIdentifier).Return(blockIdentifier.Hash, nil).Once()
+
+		balance, err := servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+			AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: "0.0.1234"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, resp, balance)
+
+		_, err = servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+			AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: "0.0.1"},
+		})
+		assert.Equal(t, AssetTypes.ErrInvalidAddress.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAccountBalance_TrustlessValidation(t *testing.T) {
+	ctx := context.Background()
+	blockIdentifier := &RosettaTypes.BlockIdentifier{
+		Index: 10992,
+		Hash:  "0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+	}
+	account := &RosettaTypes.AccountIdentifier{
+		Address: "0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1",
+	}
+
+	for policy, fails := range map[string]bool{
+		configuration.WarnValidationPolicy: false,
+		configuration.FailValidationPolicy: true,
+	} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode: configuration.ModeOnline,
+				RosettaCfg: configuration.RosettaConfig{
+					Currency:                         AssetTypes.Currency,
+					EnableTrustlessAccountValidation: true,
+					TrustlessValidationPolicy:        policy,
+				},
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewAccountAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, mockClient)
+
+			resp := &RosettaTypes.AccountBalanceResponse{
+				BlockIdentifier: blockIdentifier,
+				Balances:        []*RosettaTypes.Amount{{Value: "10", Currency: AssetTypes.Currency}},
+			}
+			mockClient.On("Balance", ctx, account, mock.Anything, mock.Anything).Return(resp, nil).Once()
+			// The node doesn't know the block, so the balance can't be proven
+			mockClient.On(
+				"CallContext",
+				ctx,
+				mock.Anything,
+				"eth_getBlockByHash",
+				common.HexToHash(blockIdentifier.Hash),
+				false,
+			).Return(nil).Once()
+			if !fails {
+				mockClient.On("GetBlockHash", ctx, *blockIdentifier).Return(blockIdentifier.Hash, nil).Once()
+			}
+
+			balance, err := servicer.AccountBalance(ctx, &RosettaTypes.AccountBalanceRequest{
+				AccountIdentifier: account,
+			})
+			if fails {
+				assert.Nil(t, balance)
+				assert.Equal(t, AssetTypes.ErrValidationFailed.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, resp, balance)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// tokenAccount is the balance of an account in a token contract
+type tokenAccount struct {
+	contractAddress string
+	address         string
+}
+
+// tokenAccountChange is the change of a tokenAccount described by the
+// operations of a block
+type tokenAccountChange struct {
+	currency *RosettaTypes.Currency
+	amount   *big.Int
+
+	// transaction is the last transaction of the block changing the balance
+	transaction *RosettaTypes.Transaction
+}
+
+// nonStandardTokens returns the lowercase addresses of the whitelisted tokens
+// with non standard transfer semantics
+func (s *BlockAPIService) nonStandardTokens(ctx context.Context) (map[string]bool, error) {
+	tokenWhiteList, err := s.tokenWhiteList(ctx, s.config.RosettaCfg.TokenWhiteList)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := map[string]bool{}
+	for _, token := range tokenWhiteList {
+		if token.NonStandardTransfers {
+			tokens[strings.ToLower(token.Address)] = true
+		}
+	}
+
+	return tokens, nil
+}
+
+// nonStandardTokenAddress returns the contract address of the currency of op
+// if it is a successful operation of one of tokens
+func nonStandardTokenAddress(op *RosettaTypes.Operation, tokens map[string]bool) (string, bool) {
+	if op.Account == nil || op.Amount == nil || op.Amount.Currency == nil {
+		return "", false
+	}
+	if op.Status != nil && *op.Status != AssetTypes.SuccessStatus {
+		return "", false
+	}
+	contractAddress, ok := op.Amount.Currency.Metadata[client.ContractAddressMetadata].(string)
+	if !ok || !tokens[strings.ToLower(contractAddress)] {
+		return "", false
+	}
+
+	return contractAddress, true
+}
+
+// blockTransactionWithAdjustments returns transaction of the block
+// blockIdentifier with its balance adjustments, see addBalanceAdjustments.
+// The adjustments depend on the other transactions of the block, so when
+// transaction touches a token with non standard transfer semantics it is
+// taken from the populated block, which may be cached.
+func (s *BlockAPIService) blockTransactionWithAdjustments(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transaction *RosettaTypes.Transaction,
+) (*RosettaTypes.Transaction, error) {
+	tokens, err := s.nonStandardTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	touched := false
+	for _, op := range transaction.Operations {
+		if _, ok := nonStandardTokenAddress(op, tokens); ok {
+			touched = true
+			break
+		}
+	}
+	if !touched {
+		return transaction, nil
+	}
+
+	response, rerr := s.sharedBlock(ctx, &RosettaTypes.BlockRequest{
+		BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{
+			Index: &blockIdentifier.Index,
+			Hash:  &blockIdentifier.Hash,
+		},
+	})
+	if rerr != nil {
+		return nil, fmt.Errorf("could not get block %s: %s", blockIdentifier.Hash, rerr.Message)
+	}
+	for _, blockTransaction := range response.Block.Transactions {
+		if !strings.EqualFold(blockTransaction.TransactionIdentifier.Hash, transaction.TransactionIdentifier.Hash) {
+			continue
+		}
+
+		// The block may be cached, so its transaction is copied before its
+		// metadata is extended
+		adjusted := *blockTransaction
+		if blockTransaction.Metadata != nil {
+			adjusted.Metadata = make(map[string]interface{}, len(blockTransaction.Metadata))
+			for key, value := range blockTransaction.Metadata {
+				adjusted.Metadata[key] = value
+			}
+		}
+		return &adjusted, nil
+	}
+
+	return nil, fmt.Errorf("transaction %s is not in block %s", transaction.TransactionIdentifier.Hash, blockIdentifier.Hash)
+}
+
+// addBalanceAdjustments reconciles the balances of tokens with non standard
+// transfer semantics. The operations of these tokens are computed from their
+// Transfer events, which don't include transfer fees or rebases, so for every
+// account they touch in the block, the difference between its balance change
+// and its operations is added as an OpErc20BalanceAdjustment operation to the
+// last transaction touching it. Balances are fetched with eth_call at the
+// parent and current block, by hash so that a reorg can't mix up branches.
+func (s *BlockAPIService) addBalanceAdjustments(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	parentBlockIdentifier *RosettaTypes.BlockIdentifier,
+	transactions []*RosettaTypes.Transaction,
+) error {
+	tokens, err := s.nonStandardTokens(ctx)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 || blockIdentifier.Index == AssetTypes.GenesisBlockIndex {
+		return nil
+	}
+
+	var accounts []tokenAccount
+	changes := map[tokenAccount]*tokenAccountChange{}
+	for _, tx := range transactions {
+		for _, op := range tx.Operations {
+			contractAddress, ok := nonStandardTokenAddress(op, tokens)
+			if !ok {
+				continue
+			}
+			value, ok := new(big.Int).SetString(op.Amount.Value, 10) // nolint:gomnd
+			if !ok {
+				return fmt.Errorf("invalid amount %s of operation %d", op.Amount.Value, op.OperationIdentifier.Index)
+			}
+
+			account := tokenAccount{contractAddress: contractAddress, address: op.Account.Address}
+			change, ok := changes[account]
+			if !ok {
+				change = &tokenAccountChange{currency: op.Amount.Currency, amount: new(big.Int)}
+				changes[account] = change
+				accounts = append(accounts, account)
+			}
+			change.amount.Add(change.amount, value)
+			change.transaction = tx
+		}
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	parentBlock := map[string]interface{}{"blockHash": parentBlockIdentifier.Hash}
+	currentBlock := map[string]interface{}{"blockHash": blockIdentifier.Hash}
+	reqs := make([]rpc.BatchElem, 0, 2*len(accounts)) // nolint:gomnd
+	for _, account := range accounts {
+		callParams := map[string]string{
+			"to":   account.contractAddress,
+			"data": client.BalanceOfMethodPrefix + common.HexToAddress(account.address).Hex()[2:],
+		}
+		for _, block := range []map[string]interface{}{parentBlock, currentBlock} {
+			reqs = append(reqs, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callParams, block},
+				Result: new(hexutil.Bytes),
+			})
+		}
+	}
+	if err := s.client.BatchCallContext(ctx, reqs); err != nil {
+		return fmt.Errorf("could not get token balances: %w", err)
+	}
+
+	for i, account := range accounts {
+		before, after := reqs[2*i], reqs[2*i+1]
+		for _, req := range []rpc.BatchElem{before, after} {
+			if req.Error != nil {
+				return fmt.Errorf("could not get %s balance of %s: %w", account.contractAddress, account.address, req.Error)
+			}
+		}
+
+		delta := new(big.Int).Sub(
+			new(big.Int).SetBytes(*after.Result.(*hexutil.Bytes)),
+			new(big.Int).SetBytes(*before.Result.(*hexutil.Bytes)),
+		)
+		change := changes[account]
+		adjustment := delta.Sub(delta, change.amount)
+		if adjustment.Sign() == 0 {
+			continue
+		}
+
+		tx := change.transaction
+		tx.Operations = append(tx.Operations, &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: int64(len(tx.Operations)),
+			},
+			Type:    AssetTypes.OpErc20BalanceAdjustment,
+			Status:  RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account: &RosettaTypes.AccountIdentifier{Address: account.address},
+			Amount: &RosettaTypes.Amount{
+				Value:    adjustment.String(),
+				Currency: change.currency,
+			},
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddBalanceAdjustments(t *testing.T) {
+	feeToken := "0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b"
+	standardToken := "0x1E77ad77925Ac0075CF61Fb76bA35D884985019d"
+	sender := "0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0"
+	recipient := "0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4"
+
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{
+			TokenWhiteList: []configuration.Token{
+				{Address: feeToken, Symbol: "FEE", Decimals: 18, NonStandardTransfers: true},
+				{Address: standardToken, Symbol: "USDC", Decimals: 6},
+			},
+		},
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	feeCurrency := client.Erc20Currency("FEE", 18, feeToken)
+	transferOps := func(currency *RosettaTypes.Currency) []*RosettaTypes.Operation {
+		return []*RosettaTypes.Operation{
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+				Type:                AssetTypes.OpErc20Transfer,
+				Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+				Account:             &RosettaTypes.AccountIdentifier{Address: sender},
+				Amount:              &RosettaTypes.Amount{Value: "-100", Currency: currency},
+			},
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+				RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 0}},
+				Type:                AssetTypes.OpErc20Transfer,
+				Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+				Account:             &RosettaTypes.AccountIdentifier{Address: recipient},
+				Amount:              &RosettaTypes.Amount{Value: "100", Currency: currency},
+			},
+		}
+	}
+	transactions := []*RosettaTypes.Transaction{
+		{Operations: transferOps(feeCurrency)},
+		{Operations: transferOps(client.Erc20Currency("USDC", 6, standardToken))},
+	}
+
+	// The sender is charged 100 but the recipient only receives 98
+	balances := map[string][2]int64{
+		sender:    {1000, 900},
+		recipient: {0, 98},
+	}
+	mockClient.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(reqs []rpc.BatchElem) bool { return len(reqs) == 4 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			reqs := args.Get(1).([]rpc.BatchElem)
+			for i, req := range reqs {
+				callParams := req.Args[0].(map[string]string)
+				assert.Equal(t, feeToken, callParams["to"])
+				assert.Equal(t, []map[string]interface{}{{"blockHash": "0x9"}, {"blockHash": "0xa"}}[i%2], req.Args[1])
+
+				account := sender
+				if i >= 2 {
+					account = recipient
+				}
+				assert.Equal(t, client.BalanceOfMethodPrefix+common.HexToAddress(account).Hex()[2:], callParams["data"])
+				balance := common.LeftPadBytes(big.NewInt(balances[account][i%2]).Bytes(), 32)
+				*(req.Result.(*hexutil.Bytes)) = balance
+			}
+		},
+	).Once()
+
+	assert.NoError(t, servicer.addBalanceAdjustments(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Index: 10, Hash: "0xa"},
+		&RosettaTypes.BlockIdentifier{Index: 9, Hash: "0x9"},
+		transactions,
+	))
+
+	assert.Len(t, transactions[0].Operations, 3)
+	adjustment := transactions[0].Operations[2]
+	assert.Equal(t, int64(2), adjustment.OperationIdentifier.Index)
+	assert.Equal(t, AssetTypes.OpErc20BalanceAdjustment, adjustment.Type)
+	assert.Equal(t, recipient, adjustment.Account.Address)
+	assert.Equal(t, "-2", adjustment.Amount.Value)
+	assert.Equal(t, feeCurrency, adjustment.Amount.Currency)
+	assert.NoError(t, ValidateOperationIndexes(transactions[0].Operations))
+
+	assert.Len(t, transactions[1].Operations, 2)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockTransactionWithAdjustments(t *testing.T) {
+	feeToken := "0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b"
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{
+			TokenWhiteList: []configuration.Token{
+				{Address: feeToken, Symbol: "FEE", Decimals: 18, NonStandardTransfers: true},
+			},
+			BlockCacheSize: 1,
+		},
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockIdentifier := &RosettaTypes.BlockIdentifier{Index: 10, Hash: "0xa"}
+	transfer := &RosettaTypes.Operation{
+		OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+		Type:                AssetTypes.OpErc20Transfer,
+		Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+		Account:             &RosettaTypes.AccountIdentifier{Address: "0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4"},
+		Amount:              &RosettaTypes.Amount{Value: "100", Currency: client.Erc20Currency("FEE", 18, feeToken)},
+	}
+	adjustment := &RosettaTypes.Operation{
+		OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+		Type:                AssetTypes.OpErc20BalanceAdjustment,
+		Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+		Account:             transfer.Account,
+		Amount:              &RosettaTypes.Amount{Value: "-2", Currency: transfer.Amount.Currency},
+	}
+	blockTransaction := &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0x1"},
+		Operations:            []*RosettaTypes.Operation{transfer, adjustment},
+		Metadata:              map[string]interface{}{"gas_limit": "0x5208"},
+	}
+	servicer.blockCache.add(&RosettaTypes.Block{
+		BlockIdentifier: blockIdentifier,
+		Transactions:    []*RosettaTypes.Transaction{blockTransaction},
+	})
+
+	// Transactions not touching a non standard token are kept
+	native := &RosettaTypes.Transaction{TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0x2"}}
+	transaction, err := servicer.blockTransactionWithAdjustments(ctx, blockIdentifier, native)
+	assert.NoError(t, err)
+	assert.Same(t, native, transaction)
+
+	// Others are taken from the block, with its adjustments
+	transaction, err = servicer.blockTransactionWithAdjustments(ctx, blockIdentifier, &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0x1"},
+		Operations:            []*RosettaTypes.Operation{transfer},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, blockTransaction, transaction)
+	assert.NotSame(t, blockTransaction, transaction)
+
+	_, err = servicer.blockTransactionWithAdjustments(ctx, blockIdentifier, &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0x3"},
+		Operations:            []*RosettaTypes.Operation{transfer},
+	})
+	assert.EqualError(t, err, "transaction 0x3 is not in block 0xa")
+
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	// BlockCacheMetadataKey is the block metadata key holding the block cache
+	// status of a /block response when the block cache is enabled
+	BlockCacheMetadataKey = "cache_status"
+
+	// Block cache statuses
+	BlockCacheHit  = "hit"
+	BlockCacheMiss = "miss"
+)
+
+// blockCacheEntry is a cached block and the time it was added
+type blockCacheEntry struct {
+	block *RosettaTypes.Block
+	added time.Time
+}
+
+// blockCache caches populated blocks by hash. Only one block is cached per
+// height: a block conflicting with a cached block at the same height drops the
+// cached block and the cached blocks above it, which may build on it.
+type blockCache struct {
+	mu      sync.Mutex
+	blocks  *lru.Cache
+	hashes  map[int64]string
+	ttl     time.Duration
+	nowFunc func() time.Time
+}
+
+// newBlockCache returns a cache of size blocks, which expire after ttl when
+// it isn't zero
+func newBlockCache(size int, ttl time.Duration) (*blockCache, error) {
+	c := &blockCache{
+		hashes:  map[int64]string{},
+		ttl:     ttl,
+		nowFunc: time.Now,
+	}
+
+	blocks, err := lru.NewWithEvict(size, func(key interface{}, value interface{}) {
+		index := value.(*blockCacheEntry).block.BlockIdentifier.Index
+		if c.hashes[index] == key {
+			delete(c.hashes, index)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.blocks = blocks
+
+	return c, nil
+}
+
+// get returns the cached block with hash, if it hasn't expired
+func (c *blockCache) get(hash string) (*RosettaTypes.Block, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(hash)
+	value, ok := c.blocks.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(*blockCacheEntry)
+	if c.ttl > 0 && c.nowFunc().Sub(entry.added) > c.ttl {
+		c.blocks.Remove(key)
+		return nil, false
+	}
+
+	return entry.block, true
+}
+
+// getByIndex returns the cached block at index, if it hasn't expired
+func (c *blockCache) getByIndex(index int64) (*RosettaTypes.Block, bool) {
+	c.mu.Lock()
+	hash, ok := c.hashes[index]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	return c.get(hash)
+}
+
+// add caches block, dropping the cached blocks it conflicts with
+func (c *blockCache) add(block *RosettaTypes.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := block.BlockIdentifier.Index
+	key := strings.ToLower(block.BlockIdentifier.Hash)
+
+	conflict := false
+	if hash, ok := c.hashes[index]; ok && hash != key {
+		conflict = true
+	}
+	if parent, ok := c.hashes[index-1]; ok && block.ParentBlockIdentifier != nil &&
+		parent != strings.ToLower(block.ParentBlockIdentifier.Hash) {
+		// The cached parent isn't the parent of block anymore
+		c.invalidateFrom(index - 1)
+	} else if conflict {
+		c.invalidateFrom(index)
+	}
+
+	c.blocks.Add(key, &blockCacheEntry{block: block, added: c.nowFunc()})
+	c.hashes[index] = key
+}
+
+// invalidateFrom drops the cached blocks at index and above
+func (c *blockCache) invalidateFrom(index int64) {
+	for height, hash := range c.hashes {
+		if height >= index {
+			c.blocks.Remove(hash)
+		}
+	}
+}
+
+// withCacheStatus returns a copy of block with status in its metadata, so
+// cached blocks are never modified
+func withCacheStatus(block *RosettaTypes.Block, status string) *RosettaTypes.Block {
+	withStatus := *block
+	withStatus.Metadata = make(map[string]interface{}, len(block.Metadata)+1)
+	for k, v := range block.Metadata {
+		withStatus.Metadata[k] = v
+	}
+	withStatus.Metadata[BlockCacheMetadataKey] = status
+
+	return &withStatus
+}
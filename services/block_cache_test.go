@@ -0,0 +1,152 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/testkit"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func cachedTestBlock(index int64, hash string, parentHash string) *types.Block {
+	return &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: hash},
+		ParentBlockIdentifier: &types.BlockIdentifier{Index: index - 1, Hash: parentHash},
+		Metadata:              map[string]interface{}{"gas_used": "0x0"},
+	}
+}
+
+func TestBlockCache(t *testing.T) {
+	tests := map[string]struct {
+		blocks   []*types.Block
+		advance  time.Duration
+		cached   []string
+		uncached []string
+	}{
+		"hit is case insensitive": {
+			blocks: []*types.Block{cachedTestBlock(1, "0xAA", "0x00")},
+			cached: []string{"0xaa", "0xAA"},
+		},
+		"expired": {
+			blocks:   []*types.Block{cachedTestBlock(1, "0xaa", "0x00")},
+			advance:  2 * time.Minute,
+			uncached: []string{"0xaa"},
+		},
+		"same height conflict": {
+			blocks: []*types.Block{
+				cachedTestBlock(1, "0xaa", "0x00"),
+				cachedTestBlock(2, "0xbb", "0xaa"),
+				cachedTestBlock(3, "0xcc", "0xbb"),
+				cachedTestBlock(2, "0xb2", "0xaa"),
+			},
+			cached:   []string{"0xaa", "0xb2"},
+			uncached: []string{"0xbb", "0xcc"},
+		},
+		"parent mismatch": {
+			blocks: []*types.Block{
+				cachedTestBlock(1, "0xaa", "0x00"),
+				cachedTestBlock(2, "0xbb", "0xaa"),
+				cachedTestBlock(3, "0xc2", "0xb2"),
+			},
+			cached:   []string{"0xaa", "0xc2"},
+			uncached: []string{"0xbb"},
+		},
+		"evicted": {
+			blocks: []*types.Block{
+				cachedTestBlock(1, "0xaa", "0x00"),
+				cachedTestBlock(2, "0xbb", "0xaa"),
+				cachedTestBlock(3, "0xcc", "0xbb"),
+				cachedTestBlock(4, "0xdd", "0xcc"),
+			},
+			cached:   []string{"0xbb", "0xcc", "0xdd"},
+			uncached: []string{"0xaa"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache, err := newBlockCache(3, time.Minute)
+			assert.NoError(t, err)
+
+			now := time.Unix(1700000000, 0)
+			cache.nowFunc = func() time.Time { return now }
+			for _, block := range test.blocks {
+				cache.add(block)
+			}
+			now = now.Add(test.advance)
+
+			for _, hash := range test.cached {
+				block, ok := cache.get(hash)
+				assert.True(t, ok, hash)
+				assert.NotNil(t, block)
+			}
+			for _, hash := range test.uncached {
+				_, ok := cache.get(hash)
+				assert.False(t, ok, hash)
+			}
+		})
+	}
+}
+
+func TestBlockCache_Reorgs(t *testing.T) {
+	var cache *blockCache
+	addBlocks := func(chain *testkit.ReorgChain, identifiers []*types.BlockIdentifier) {
+		for _, identifier := range identifiers {
+			block := &types.Block{BlockIdentifier: identifier, ParentBlockIdentifier: chain.Parent(identifier.Hash)}
+			if block.ParentBlockIdentifier == nil {
+				block.ParentBlockIdentifier = identifier
+			}
+			cache.add(block)
+		}
+	}
+
+	testkit.RunReorgScenarios(t, testkit.ReorgScenarios(), big.NewInt(1), nil, testkit.ReorgTest{
+		Before: func(t *testing.T, chain *testkit.ReorgChain) {
+			var err error
+			cache, err = newBlockCache(32, 0)
+			assert.NoError(t, err)
+			addBlocks(chain, chain.Canonical())
+		},
+		After: func(t *testing.T, chain *testkit.ReorgChain, orphaned []*types.BlockIdentifier) {
+			// The blocks of the new branch are synced from the fork
+			canonical := chain.Canonical()
+			addBlocks(chain, canonical[orphaned[0].Index:])
+
+			for _, identifier := range orphaned {
+				_, ok := cache.get(identifier.Hash)
+				assert.False(t, ok, identifier.Hash)
+			}
+			for _, identifier := range canonical {
+				block, ok := cache.getByIndex(identifier.Index)
+				assert.True(t, ok, identifier.Index)
+				assert.Equal(t, identifier, block.BlockIdentifier)
+			}
+		},
+	})
+}
+
+func TestWithCacheStatus(t *testing.T) {
+	block := cachedTestBlock(1, "0xaa", "0x00")
+
+	withStatus := withCacheStatus(block, BlockCacheHit)
+	assert.Equal(t, BlockCacheHit, withStatus.Metadata[BlockCacheMetadataKey])
+	assert.Equal(t, "0x0", withStatus.Metadata["gas_used"])
+	assert.NotContains(t, block.Metadata, BlockCacheMetadataKey)
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// blockFlightResult is the result of a block fetch shared by concurrent
+// identical requests
+type blockFlightResult struct {
+	response   *RosettaTypes.BlockResponse
+	fetched    *fetchedBlock
+	rosettaErr *RosettaTypes.Error
+	err        error
+}
+
+// detachedContext carries the values of its parent without its cancellation,
+// so a shared fetch outlives the request that started it
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// detach returns ctx without its cancellation
+func detach(ctx context.Context) context.Context {
+	if ctx.Done() == nil {
+		return ctx
+	}
+
+	return detachedContext{ctx}
+}
+
+// sharedBlock populates the block of request like block, sharing a single
+// fetch between the concurrent requests of the same block. The fetch is not
+// cancelled when a request goes away, while every request still returns when
+// its own context is done. Requests are not shared when headers are
+// forwarded, as the fetch forwards the headers of a single request.
+func (s *BlockAPIService) sharedBlock(
+	ctx context.Context,
+	request *RosettaTypes.BlockRequest,
+) (*RosettaTypes.BlockResponse, *RosettaTypes.Error) {
+	if s.config.RosettaCfg.SupportHeaderForwarding {
+		return s.block(ctx, request)
+	}
+
+	results := s.blockFlights.DoChan("block:"+blockFlightKey(request.BlockIdentifier), func() (interface{}, error) {
+		response, err := s.block(detach(ctx), request)
+		return &blockFlightResult{response: response, rosettaErr: err}, nil
+	})
+
+	select {
+	case result := <-results:
+		shared := result.Val.(*blockFlightResult)
+		return shared.response, shared.rosettaErr
+	case <-ctx.Done():
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, ctx.Err())
+	}
+}
+
+// sharedEthBlock fetches the block at blockIdentifier without its receipts
+// like fetchEthBlock, sharing a single fetch between the concurrent fetches of
+// the same block. Every caller gets its own copies of the loaded transactions,
+// which callers populate. Fetches are shared like in sharedBlock.
+func (s *BlockAPIService) sharedEthBlock(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) (*fetchedBlock, error) {
+	if s.config.RosettaCfg.SupportHeaderForwarding {
+		return s.fetchEthBlock(ctx, blockIdentifier, false)
+	}
+
+	results := s.blockFlights.DoChan("eth_block:"+blockFlightKey(blockIdentifier), func() (interface{}, error) {
+		fetched, err := s.fetchEthBlock(detach(ctx), blockIdentifier, false)
+		return &blockFlightResult{fetched: fetched, err: err}, nil
+	})
+
+	select {
+	case result := <-results:
+		shared := result.Val.(*blockFlightResult)
+		if shared.err != nil {
+			return nil, shared.err
+		}
+		return copyFetchedBlock(shared.fetched), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// copyFetchedBlock returns a copy of fetched with copies of its loaded
+// transactions
+func copyFetchedBlock(fetched *fetchedBlock) *fetchedBlock {
+	copied := *fetched
+	copied.loadedTxs = make([]*client.LoadedTransaction, len(fetched.loadedTxs))
+	for i, tx := range fetched.loadedTxs {
+		loadedTx := *tx
+		copied.loadedTxs[i] = &loadedTx
+	}
+
+	return &copied
+}
+
+// blockFlightKey returns the key of the fetches of the block at
+// blockIdentifier. Blocks are fetched by hash when it is set, and the default
+// block is fetched without an identifier.
+func blockFlightKey(blockIdentifier *RosettaTypes.PartialBlockIdentifier) string {
+	if blockIdentifier != nil {
+		if blockIdentifier.Hash != nil {
+			return "hash:" + strings.ToLower(*blockIdentifier.Hash)
+		}
+		if blockIdentifier.Index != nil {
+			return "index:" + strconv.FormatInt(*blockIdentifier.Index, 10) // nolint:gomnd
+		}
+	}
+
+	return "default"
+}
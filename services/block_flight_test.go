@@ -0,0 +1,193 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlock_SharedFetches(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// The block is fetched once, while the other requests wait for it
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "latest", true).Return(nil).Run(
+		func(args mock.Arguments) {
+			close(started)
+			<-release
+			file, err := os.ReadFile("testdata/block_10992.json")
+			assert.NoError(t, err)
+			*args.Get(1).(*json.RawMessage) = file
+		},
+	).Once()
+	txs := make([]client.RPCTransaction, 0)
+	var baseFee *big.Int
+	mockClient.On("TraceBlockByHash", ctx, mock.Anything, txs).Return(nil, nil).Once()
+	mockClient.On("GetBlockReceipts", ctx, mock.Anything, txs, baseFee).Return(nil, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("GetBlockHash", ctx, mock.Anything).Return(
+		"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+		nil,
+	).Once()
+	mockClient.On("PopulateCrossChainTransactions", mock.Anything, mock.Anything).
+		Return([]*RosettaTypes.Transaction{}, nil).Once()
+
+	requests := 3
+	responses := make([]*RosettaTypes.BlockResponse, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{})
+			assert.Nil(t, err)
+			responses[i] = response
+		}(i)
+		if i == 0 {
+			<-started
+		}
+	}
+	// Let the other requests join the fetch of the first one
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, response := range responses {
+		assert.Same(t, responses[0], response)
+	}
+	assert.Equal(t, int64(10992), responses[0].Block.BlockIdentifier.Index)
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlock_SharedFetchDetached(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	index := int64(3)
+	request := &RosettaTypes.BlockRequest{
+		BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Index: &index},
+	}
+
+	// The fetch isn't cancelled with the request that started it
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockClient.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", "0x3", true).Return(
+		nil,
+	).Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Once()
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan *RosettaTypes.Error)
+	go func() {
+		_, err := servicer.Block(cancelledCtx, request)
+		cancelled <- err
+	}()
+	<-started
+	shared := make(chan *RosettaTypes.Error)
+	go func() {
+		_, err := servicer.Block(context.Background(), request)
+		shared <- err
+	}()
+	// Let the other request join the fetch of the first one
+	time.Sleep(100 * time.Millisecond)
+
+	// The first request returns as soon as it is cancelled
+	cancel()
+	assert.NotNil(t, <-cancelled)
+	close(release)
+	assert.NotNil(t, <-shared)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlock_NotSharedWithHeaderForwarding(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{SupportHeaderForwarding: true},
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	index := int64(3)
+	request := &RosettaTypes.BlockRequest{
+		BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Index: &index},
+	}
+
+	// Every request fetches the block with its own context, which carries its
+	// forwarded headers
+	type requestKey struct{}
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		ctx := context.WithValue(context.Background(), requestKey{}, i)
+		mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "0x3", true).Return(
+			nil,
+		).Run(func(mock.Arguments) {
+			<-release
+		}).Once()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := servicer.Block(ctx, request)
+			assert.NotNil(t, err)
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestCopyFetchedBlock(t *testing.T) {
+	tx := &client.LoadedTransaction{FeeAmount: big.NewInt(1)}
+	fetched := &fetchedBlock{loadedTxs: []*client.LoadedTransaction{tx}}
+
+	copied := copyFetchedBlock(fetched)
+	assert.Equal(t, fetched, copied)
+	copied.loadedTxs[0].FeeAmount = big.NewInt(2)
+	assert.Equal(t, big.NewInt(1), tx.FeeAmount)
+}
+
+func TestBlockFlightKey(t *testing.T) {
+	index := int64(10)
+	hash := "0xABCD"
+
+	assert.Equal(t, "default", blockFlightKey(nil))
+	assert.Equal(t, "default", blockFlightKey(&RosettaTypes.PartialBlockIdentifier{}))
+	assert.Equal(t, "index:10", blockFlightKey(&RosettaTypes.PartialBlockIdentifier{Index: &index}))
+	assert.Equal(t, "hash:0xabcd", blockFlightKey(&RosettaTypes.PartialBlockIdentifier{Index: &index, Hash: &hash}))
+}
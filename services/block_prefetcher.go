@@ -0,0 +1,129 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// blockPrefetcher fetches the blocks following the highest requested block in
+// the background, see RosettaConfig.BlockPrefetchDepth. Blocks are fetched one
+// at a time in order, and fetching stops at the first failure, which is usually
+// a block that isn't produced yet, until a higher block is requested.
+type blockPrefetcher struct {
+	depth int64
+	fetch func(ctx context.Context, index int64) error
+
+	mu      sync.Mutex
+	highest int64
+	next    int64
+	wake    chan struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newBlockPrefetcher returns a prefetcher fetching the depth blocks following
+// the highest requested block with fetch
+func newBlockPrefetcher(depth int, fetch func(ctx context.Context, index int64) error) *blockPrefetcher {
+	return &blockPrefetcher{
+		depth:   int64(depth),
+		fetch:   fetch,
+		highest: -1,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// requested records that the block at index was requested
+func (p *blockPrefetcher) requested(index int64) {
+	p.mu.Lock()
+	if index <= p.highest {
+		p.mu.Unlock()
+		return
+	}
+	p.highest = index
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start prefetches blocks in the background until stop is called
+func (p *blockPrefetcher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		p.run(ctx)
+	}()
+}
+
+// stop stops the prefetching started by start, and waits for the fetch in
+// progress to return
+func (p *blockPrefetcher) stop() {
+	p.cancel()
+	<-p.done
+}
+
+// run prefetches blocks until ctx is done
+func (p *blockPrefetcher) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		}
+
+		for {
+			index, ok := p.nextIndex()
+			if !ok || ctx.Err() != nil {
+				break
+			}
+			if err := p.fetch(ctx, index); err != nil {
+				break
+			}
+			p.fetched(index)
+		}
+	}
+}
+
+// nextIndex returns the next block to prefetch, if any. Prefetching restarts
+// after the highest requested block when the next block is out of the window,
+// e.g. after an indexer restarted from a lower block.
+func (p *blockPrefetcher) nextIndex() (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.next <= p.highest || p.next > p.highest+p.depth+1 {
+		p.next = p.highest + 1
+	}
+
+	return p.next, p.next <= p.highest+p.depth
+}
+
+// fetched records that the block at index was prefetched
+func (p *blockPrefetcher) fetched(index int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if index == p.next {
+		p.next++
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlockPrefetcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Blocks above the tip can't be fetched
+	tip := int64(12)
+	fetched := make(chan int64, 100)
+	prefetcher := newBlockPrefetcher(3, func(ctx context.Context, index int64) error {
+		if index > tip {
+			fetched <- -1
+			return errors.New("not found")
+		}
+		fetched <- index
+		return nil
+	})
+	go prefetcher.run(ctx)
+
+	receive := func(expected ...int64) {
+		for _, index := range expected {
+			select {
+			case got := <-fetched:
+				assert.Equal(t, index, got)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("block %d was not prefetched", index)
+			}
+		}
+	}
+
+	prefetcher.requested(5)
+	receive(6, 7, 8)
+
+	// Only the blocks entering the window are fetched
+	prefetcher.requested(6)
+	receive(9)
+	prefetcher.requested(4)
+	prefetcher.requested(10)
+	receive(11, 12, -1)
+
+	// Prefetching restarts after an indexer restarted from a lower block
+	prefetcher = newBlockPrefetcher(2, prefetcher.fetch)
+	prefetcher.highest, prefetcher.next = 9, 15
+	index, ok := prefetcher.nextIndex()
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), index)
+	prefetcher.highest = 2
+	index, ok = prefetcher.nextIndex()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), index)
+	prefetcher.fetched(3)
+	prefetcher.fetched(4)
+	_, ok = prefetcher.nextIndex()
+	assert.False(t, ok)
+}
+
+func TestBlockPrefetcher_Stop(t *testing.T) {
+	fetching := make(chan struct{})
+	prefetcher := newBlockPrefetcher(1, func(ctx context.Context, index int64) error {
+		close(fetching)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	prefetcher.start()
+	prefetcher.requested(1)
+	<-fetching
+
+	// The fetch in progress is cancelled and stop waits for it
+	stopped := make(chan struct{})
+	go func() {
+		prefetcher.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("prefetching did not stop")
+	}
+}
+
+func TestBlock_PrefetchedBlock(t *testing.T) {
+	ctx := context.Background()
+	index := int64(10)
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{
+			BlockCacheSize: 4,
+		},
+	}
+
+	tests := map[string]struct {
+		nodeHash string
+		expected bool
+	}{
+		"canonical": {
+			nodeHash: "0x00000000000000000000000000000000000000000000000000000000000000aa",
+			expected: true,
+		},
+		"reorged": {
+			nodeHash: "0x00000000000000000000000000000000000000000000000000000000000000bb",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+			servicer.prefetcher = newBlockPrefetcher(1, servicer.prefetchBlock)
+			servicer.blockCache.add(cachedTestBlock(
+				index,
+				"0x00000000000000000000000000000000000000000000000000000000000000AA",
+				"0x09",
+			))
+
+			mockClient.On(
+				"CallContext",
+				ctx,
+				mock.Anything,
+				"eth_getBlockByNumber",
+				client.ToBlockNumArg(big.NewInt(index)),
+				false,
+			).Return(nil).Run(func(args mock.Arguments) {
+				assert.NoError(t, json.Unmarshal([]byte(`{"hash":"`+test.nodeHash+`"}`), args.Get(1)))
+			}).Once()
+			mockClient.On(
+				"GetBlockHash",
+				ctx,
+				RosettaTypes.BlockIdentifier{Index: index, Hash: test.nodeHash},
+			).Return(test.nodeHash, nil).Once()
+
+			block, ok := servicer.canonicalCachedBlock(ctx, index)
+			assert.Equal(t, test.expected, ok)
+			if test.expected {
+				assert.Equal(t, index, block.BlockIdentifier.Index)
+
+				// Cached blocks are served to index requests
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", mock.Anything, false).
+					Return(nil).Run(func(args mock.Arguments) {
+					assert.NoError(t, json.Unmarshal([]byte(`{"hash":"`+test.nodeHash+`"}`), args.Get(1)))
+				}).Once()
+				mockClient.On("GetBlockHash", ctx, mock.Anything).Return(test.nodeHash, nil).Once()
+				resp, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{
+					BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Index: &index},
+				})
+				assert.Nil(t, err)
+				assert.Equal(t, BlockCacheHit, resp.Block.Metadata[BlockCacheMetadataKey])
+				assert.Equal(t, index, servicer.prefetcher.highest)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -18,18 +18,28 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"math/big"
+	"net"
+	"strings"
+	"time"
 
 	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
 	client "github.com/coinbase/rosetta-geth-sdk/client"
 	construction "github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/services/validator"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
@@ -44,14 +54,81 @@ const (
 	// LRUCacheSize determines how many contract currencies we cache
 	LRUCacheSize = 100
 
+	// ParseErrorMetadataKey is the transaction metadata key holding the
+	// error of a transaction whose operations could not be parsed
+	ParseErrorMetadataKey = "parse_error"
+
+	// RevertReasonMetadataKey is the operation metadata key holding the
+	// revert reason of a failed operation
+	RevertReasonMetadataKey = "revert_reason"
+
+	// Block metadata keys of the EIP-4844 and EIP-4788 header fields
+	ParentBeaconBlockRootMetadataKey = "parent_beacon_block_root"
+	BlobGasUsedMetadataKey           = "blob_gas_used"
+	ExcessBlobGasMetadataKey         = "excess_blob_gas"
+
+	// ExtensionsMetadataKey is the transaction metadata key holding the
+	// chain specific extensions of a LoadedTransaction
+	ExtensionsMetadataKey = "extensions"
+
+	// GasPriceSourceMetadataKey is the transaction metadata key holding the
+	// source of the gas price of the receipt, see
+	// client.RosettaTxReceipt.GasPriceSource
+	GasPriceSourceMetadataKey = "gas_price_source"
+
+	// TraceTruncatedMetadataKey is the transaction metadata key holding the
+	// TraceTruncation of a trace list bounded by the trace metadata limits of
+	// the configuration
+	TraceTruncatedMetadataKey = "trace_truncated"
+
+	// RawTraceMetadataKey is the transaction metadata key holding the trace
+	// returned by the node, see configuration.RosettaConfig.IncludeRawTrace
+	RawTraceMetadataKey = "raw_trace"
+
+	// Limits of the trace metadata reported by TraceTruncation
+	TraceMaxDepthLimit   = "max_depth"
+	TraceMaxEntriesLimit = "max_entries"
+	TraceMaxBytesLimit   = "max_bytes"
+
+	// TransactionProofMetadataKey is the transaction metadata key holding the
+	// proof of inclusion of the transaction in its block, see
+	// validator.TransactionProof
+	TransactionProofMetadataKey = "transaction_proof"
+
+	// HeaderExtraFieldsMetadataKey is the block metadata key holding the
+	// non standard header fields of the block
+	HeaderExtraFieldsMetadataKey = "header_extra_fields"
+
+	// Block metadata keys of the consensus data of IBFT and QBFT blocks, see
+	// client.ParseIstanbulHeader
+	ProposerMetadataKey   = "proposer"
+	ValidatorsMetadataKey = "validators"
+	RoundMetadataKey      = "round"
+
 	OpenEthereumTrace = iota // == 2
 )
 
+// parseStats counts the transactions served without operations because they
+// could not be parsed (parse_errors)
+var parseStats = expvar.NewMap("transaction_parsing")
+
 // BlockAPIService implements the server.BlockAPIServicer interface.
 type BlockAPIService struct {
 	config        *configuration.Configuration
 	client        construction.Client
 	currencyCache *lru.Cache
+	currencyStore CurrencyStore
+	validator     *validator.TrustlessValidator
+	blockCache    *blockCache
+	prefetcher    *blockPrefetcher
+	precompiles   []precompile
+
+	// blockFlights shares the fetches of a block between concurrent requests
+	blockFlights singleflight.Group
+
+	// transactionFilter is the TransactionFilter of the client, or of the
+	// chain profile if the client has none
+	transactionFilter client.TransactionFilter
 }
 
 // NewBlockAPIService creates a new instance of a BlockAPIService.
@@ -64,11 +141,122 @@ func NewBlockAPIService(
 		log.Fatalln(err)
 	}
 
-	return &BlockAPIService{
-		config:        cfg,
-		client:        client,
-		currencyCache: currencyCache,
+	var trustlessValidator *validator.TrustlessValidator
+	if cfg.RosettaCfg.EnableTrustlessBlockValidation {
+		trustlessValidator = validator.NewTrustlessValidator(cfg, client)
+	}
+
+	var currencyStore CurrencyStore
+	if len(cfg.RosettaCfg.CurrencyStorePath) > 0 {
+		currencyStore, err = OpenLevelDBCurrencyStore(cfg.RosettaCfg.CurrencyStorePath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var cache *blockCache
+	if cfg.RosettaCfg.BlockCacheSize > 0 {
+		cache, err = newBlockCache(cfg.RosettaCfg.BlockCacheSize, cfg.RosettaCfg.BlockCacheTTL)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	precompiles, err := loadPrecompiles(cfg.RosettaCfg.Precompiles)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	s := &BlockAPIService{
+		config:            cfg,
+		client:            client,
+		currencyCache:     currencyCache,
+		currencyStore:     currencyStore,
+		validator:         trustlessValidator,
+		blockCache:        cache,
+		precompiles:       precompiles,
+		transactionFilter: transactionFilter(cfg, client),
+	}
+	if cfg.IsOnlineMode() && cache != nil && cfg.RosettaCfg.BlockPrefetchDepth > 0 {
+		s.prefetcher = newBlockPrefetcher(cfg.RosettaCfg.BlockPrefetchDepth, s.prefetchBlock)
+		s.prefetcher.start()
+	}
+
+	return s
+}
+
+// Close stops the prefetching of blocks and closes the currency store opened
+// for RosettaConfig.CurrencyStorePath. The service must not be used after it
+// is closed.
+func (s *BlockAPIService) Close() error {
+	if s.prefetcher != nil {
+		s.prefetcher.stop()
+	}
+	if s.currencyStore != nil {
+		return s.currencyStore.Close()
+	}
+
+	return nil
+}
+
+// transactionFilter returns the TransactionFilter of c, or of the chain profile
+// of cfg if c doesn't implement one
+func transactionFilter(cfg *configuration.Configuration, c construction.Client) client.TransactionFilter {
+	if filter, ok := c.(client.TransactionFilter); ok {
+		return filter
+	}
+	if cfg.RosettaCfg.ChainProfile == configuration.BSCChainProfile {
+		return client.BSCTransactionFilter{}
+	}
+
+	return nil
+}
+
+// validateBlock validates block against its header, and the senders of its
+// transactions against their signatures, when trustless block validation is
+// enabled. Failures are only logged unless the validation policy is
+// FailValidationPolicy.
+func (s *BlockAPIService) validateBlock(
+	ctx context.Context,
+	block *EthTypes.Block,
+	rpcBlock *client.RPCBlock,
+	loadedTxs []*client.LoadedTransaction,
+) error {
+	if s.validator == nil {
+		return nil
+	}
+
+	err := s.validator.ValidateBlockWithExtraFields(ctx, block, rpcBlock.Hash, rpcBlock.HeaderExtraFields)
+	if err == nil {
+		err = s.validator.ValidateTransactions(ctx, block, transactionSenders(loadedTxs))
+	}
+
+	return applyValidationPolicy(ctx, s.config, fmt.Sprintf("block %s", rpcBlock.Hash), err)
+}
+
+// addTransactionProof adds the proof of inclusion of transaction in its block to
+// the transaction metadata when trustless block validation is enabled. Failures
+// are only logged unless the validation policy is FailValidationPolicy.
+func (s *BlockAPIService) addTransactionProof(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transaction *RosettaTypes.Transaction,
+) error {
+	if s.validator == nil {
+		return nil
+	}
+
+	txHash := common.HexToHash(transaction.TransactionIdentifier.Hash)
+	proof, err := s.validator.GetTransactionProof(ctx, common.HexToHash(blockIdentifier.Hash), txHash)
+	if err != nil {
+		return applyValidationPolicy(ctx, s.config, fmt.Sprintf("transaction %s", txHash), err)
+	}
+
+	if transaction.Metadata == nil {
+		transaction.Metadata = map[string]interface{}{}
 	}
+	transaction.Metadata[TransactionProofMetadataKey] = proof
+	return nil
 }
 
 func (s *BlockAPIService) populateTransactions(
@@ -90,12 +278,20 @@ func (s *BlockAPIService) populateTransactions(
 		transactions = append(transactions, rewardTx)
 	}
 
+	if rosettaCfg.SupportsWithdrawals && len(block.Withdrawals()) > 0 {
+		transactions = append(transactions, withdrawalTransaction(rosettaCfg, blockIdentifier, block.Withdrawals()))
+	}
+
+	if err := s.fillReceiptLogs(ctx, common.HexToHash(blockIdentifier.Hash), loadedTransactions); err != nil {
+		return nil, err
+	}
+
 	for _, tx := range loadedTransactions {
 		if tx.IsBridgedTxn {
 			// Bridge tx is already handled in PopulateCrossChainTransactions flow
 			continue
 		}
-		transaction, err := s.PopulateTransaction(ctx, tx)
+		transaction, err := s.populateTransactionWithPolicy(ctx, tx)
 		if err != nil {
 			return nil, fmt.Errorf("cannot parse %s: %w", tx.TxHash, err)
 		}
@@ -105,35 +301,265 @@ func (s *BlockAPIService) populateTransactions(
 	return transactions, nil
 }
 
+// filterTransactions applies the TransactionFilter of the client or chain
+// profile, if any, to loadedTxs and returns the transactions that are kept
+func (s *BlockAPIService) filterTransactions(
+	ctx context.Context,
+	loadedTxs []*client.LoadedTransaction,
+) ([]*client.LoadedTransaction, error) {
+	filter := s.transactionFilter
+	if filter == nil {
+		return loadedTxs, nil
+	}
+
+	kept := make([]*client.LoadedTransaction, 0, len(loadedTxs))
+	for _, tx := range loadedTxs {
+		action, err := filter.FilterTransaction(ctx, tx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot filter %s: %w", tx.TxHash, err)
+		}
+		if action == client.DropTransaction {
+			continue
+		}
+		kept = append(kept, tx)
+	}
+
+	return kept, nil
+}
+
+// populateTransactionWithPolicy populates tx, or returns parseErrorTransaction
+// when its operations failed to parse and the parse error is served, see
+// servesParseError
+func (s *BlockAPIService) populateTransactionWithPolicy(
+	ctx context.Context,
+	tx *client.LoadedTransaction,
+) (*RosettaTypes.Transaction, error) {
+	transaction, err := s.PopulateTransaction(ctx, tx)
+	if err != nil && s.servesParseError(ctx, err) {
+		log.Printf("cannot parse %s: %v", tx.TxHash, err)
+		parseStats.Add("parse_errors", 1)
+		return parseErrorTransaction(tx, err), nil
+	}
+
+	return transaction, err
+}
+
+// servesParseError returns whether a transaction whose operations failed to
+// parse with err is served with parseErrorTransaction instead of failing the
+// block, see RosettaConfig.TransactionParsePolicy
+func (s *BlockAPIService) servesParseError(ctx context.Context, err error) bool {
+	if errors.Is(err, client.ErrInvalidAddress) {
+		return true
+	}
+
+	return s.config.RosettaCfg.TransactionParsePolicy == configuration.LenientParsePolicy &&
+		ctx.Err() == nil &&
+		!isNodeError(err)
+}
+
+// isNodeError returns whether err is a failure to query the node, such as a
+// timeout, a dropped connection or an error response, rather than a failure
+// to decode or parse what the node returned
+func isNodeError(err error) bool {
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	var rpcErr rpc.Error
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) ||
+		errors.As(err, &httpErr) ||
+		errors.As(err, &rpcErr)
+}
+
+// hasParseErrors returns whether any transaction of block was served with
+// parseErrorTransaction
+func hasParseErrors(block *RosettaTypes.Block) bool {
+	for _, tx := range block.Transactions {
+		if _, ok := tx.Metadata[ParseErrorMetadataKey]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseErrorTransaction returns a transaction without operations for a
+// transaction whose operations could not be parsed, so that the rest of the
+// block can still be served. The parse error is recorded in the metadata.
+func parseErrorTransaction(tx *client.LoadedTransaction, err error) *RosettaTypes.Transaction {
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: tx.TxHash.String(),
+		},
+		Operations: []*RosettaTypes.Operation{},
+		Metadata: map[string]interface{}{
+			ParseErrorMetadataKey: err.Error(),
+		},
+	}
+}
+
 // getCurrencyFromNodeOrCache checks if the currency is in the cache and fetches it from the node if not.
-func (s *BlockAPIService) getCurrencyFromNodeOrCache(address common.Address, addressStr string) (*client.ContractCurrency, error) {
+func (s *BlockAPIService) getCurrencyFromNodeOrCache(
+	ctx context.Context,
+	address common.Address,
+	addressStr string,
+) (*client.ContractCurrency, error) {
 	if cachedCurrency, found := s.currencyCache.Get(addressStr); found {
 		return cachedCurrency.(*client.ContractCurrency), nil
 	}
+	stored := s.storedCurrency(address)
+	if stored != nil && (s.config.RosettaCfg.CurrencyStoreTTL == 0 ||
+		time.Since(stored.FetchedAt) < s.config.RosettaCfg.CurrencyStoreTTL) {
+		s.currencyCache.Add(addressStr, stored.Currency)
+		return stored.Currency, nil
+	}
 	currency, err := s.client.GetContractCurrency(address, true)
 	if err != nil {
+		if stored != nil {
+			log.Printf("could not refresh currency of %s, using the stored currency: %v", address, err)
+			return stored.Currency, nil
+		}
 		return nil, err
 	}
+	if policy := s.config.RosettaCfg.TokenDecimalsPolicy; len(policy) > 0 && currency.Symbol != client.UnknownERC20Symbol {
+		// The node and the white list must agree on the decimals of a token
+		tokenWhiteList, err := s.tokenWhiteList(ctx, s.client.GetRosettaConfig().TokenWhiteList)
+		if err != nil {
+			return nil, err
+		}
+		if token := client.GetValidERC20Token(tokenWhiteList, addressStr); token != nil {
+			if _, err := client.CheckTokenDecimals(policy, *token, uint64(currency.Decimals)); err != nil {
+				return nil, err
+			}
+		}
+	}
 	s.currencyCache.Add(addressStr, currency)
+	s.storeCurrency(address, currency)
 	return currency, nil
 }
 
+// storedCurrency returns the currency of the contract address in the currency
+// store, or nil if there is none. Failures of the store are logged, as the
+// currency can still be fetched from the node.
+func (s *BlockAPIService) storedCurrency(address common.Address) *StoredCurrency {
+	if s.currencyStore == nil {
+		return nil
+	}
+	stored, err := s.currencyStore.GetCurrency(address)
+	if err != nil {
+		log.Printf("could not get stored currency of %s: %v", address, err)
+		return nil
+	}
+
+	return stored
+}
+
+// storeCurrency adds the currency of the contract address to the currency
+// store. Unknown currencies are not stored, since the calls to their contract
+// may have failed transiently.
+func (s *BlockAPIService) storeCurrency(address common.Address, currency *client.ContractCurrency) {
+	if s.currencyStore == nil || currency.Symbol == client.UnknownERC20Symbol {
+		return
+	}
+	stored := &StoredCurrency{Currency: currency, FetchedAt: time.Now()}
+	if err := s.currencyStore.PutCurrency(address, stored); err != nil {
+		log.Printf("could not store currency of %s: %v", address, err)
+	}
+}
+
+// tokenWhiteList returns the token white list of the client if it implements
+// client.TokenWhiteListAccessor, and the configured list otherwise
+func (s *BlockAPIService) tokenWhiteList(
+	ctx context.Context,
+	configured []configuration.Token,
+) ([]configuration.Token, error) {
+	if accessor, ok := s.client.(client.TokenWhiteListAccessor); ok {
+		tokens, err := accessor.TokenWhiteList(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get token white list: %w", err)
+		}
+		return tokens, nil
+	}
+
+	return configured, nil
+}
+
+// applyReceiptFees applies the rollup fees of the receipt of tx and its fee
+// currency, the same way for /block and /block/transaction
+func (s *BlockAPIService) applyReceiptFees(ctx context.Context, tx *client.LoadedTransaction) *RosettaTypes.Error {
+	if s.config.RosettaCfg.IsZkRollup() && tx.Receipt != nil {
+		if err := client.ApplyZkReceipt(tx); err != nil {
+			return AssetTypes.WrapErr(AssetTypes.ErrInternalError, fmt.Errorf("%s: %w", tx.TxHash, err))
+		}
+	}
+	if s.config.RosettaCfg.SupportsOpStack && tx.Receipt != nil {
+		if err := client.ApplyOPStackReceipt(tx); err != nil {
+			return AssetTypes.WrapErr(AssetTypes.ErrInternalError, fmt.Errorf("%s: %w", tx.TxHash, err))
+		}
+	}
+	if address, ok := client.FeeCurrencyAddress(tx); ok {
+		if err := s.applyFeeCurrency(ctx, tx, address); err != nil {
+			return AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+		}
+	}
+
+	return nil
+}
+
+// applyFeeCurrency denominates the fee operations of tx, which pays its fees in
+// the ERC20 fee currency at address, in that token. Fees paid in a fee currency
+// are not burned, the base fee is paid with the rest of the fee.
+func (s *BlockAPIService) applyFeeCurrency(
+	ctx context.Context,
+	tx *client.LoadedTransaction,
+	address common.Address,
+) error {
+	currency, err := s.getCurrencyFromNodeOrCache(ctx, address, address.String())
+	if err != nil {
+		return fmt.Errorf("could not get fee currency %s of %s: %w", address, tx.TxHash, err)
+	}
+	tx.FeeCurrency = client.Erc20Currency(currency.Symbol, currency.Decimals, address.String())
+	tx.FeeBurned = nil
+
+	return nil
+}
+
 func (s *BlockAPIService) PopulateTransaction(
 	ctx context.Context,
 	tx *client.LoadedTransaction,
 ) (*RosettaTypes.Transaction, error) {
-	ops, err := s.client.ParseOps(tx)
+	ops, err := s.client.ParseOps(s.withoutSkippedTraces(tx))
 	if err != nil {
 		return nil, err
 	}
 
+	if s.client.GetRosettaConfig().ExtractRevertReasons {
+		s.addRevertReason(ctx, tx, ops)
+	}
+
 	var receiptLogs []*EthTypes.Log
 	if tx.Receipt != nil {
 		receiptLogs = tx.Receipt.Logs
 	}
 
+	ops, err = s.appendPrecompileOps(tx, receiptLogs, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err = s.appendUserOperationOps(tx, receiptLogs, ops)
+	if err != nil {
+		return nil, err
+	}
+
 	filterTokens := s.client.GetRosettaConfig().FilterTokens
-	tokenWhiteList := s.client.GetRosettaConfig().TokenWhiteList
+	tokenWhiteList, err := s.tokenWhiteList(ctx, s.client.GetRosettaConfig().TokenWhiteList)
+	if err != nil {
+		return nil, err
+	}
 	useTokenWhiteListMetadata := s.client.GetRosettaConfig().UseTokenWhiteListMetadata
 	indexUnknownTokens := s.config.RosettaCfg.IndexUnknownTokens
 
@@ -144,6 +570,9 @@ func (s *BlockAPIService) PopulateTransaction(
 		if s.client.SkipTxReceiptParsing(contractAddress) {
 			continue
 		}
+		if policy := s.config.RosettaCfg.AddressPolicy(log.Address); policy != nil && policy.SkipLogs {
+			continue
+		}
 
 		// Only process ERC20 transfers/deposits/withdrawals
 		if len(log.Topics) != TopicsInErc20DepositOrWithdrawal &&
@@ -171,14 +600,14 @@ func (s *BlockAPIService) PopulateTransaction(
 				}
 			} else {
 				var err error
-				currency, err = s.getCurrencyFromNodeOrCache(log.Address, contractAddress)
+				currency, err = s.getCurrencyFromNodeOrCache(ctx, log.Address, contractAddress)
 				if err != nil {
 					return nil, err
 				}
 			}
 		} else {
 			var err error
-			currency, err = s.getCurrencyFromNodeOrCache(log.Address, contractAddress)
+			currency, err = s.getCurrencyFromNodeOrCache(ctx, log.Address, contractAddress)
 			if err != nil {
 				return nil, err
 			}
@@ -189,25 +618,17 @@ func (s *BlockAPIService) PopulateTransaction(
 			}
 		}
 
+		// ERC721 contracts whose Transfer events don't index the token id look
+		// like ERC20 transfers
+		if currency.Standard == client.ERC721TokenStandard {
+			continue
+		}
+
 		erc20Ops := Erc20Ops(log, currency, int64(len(ops)))
 		ops = append(ops, erc20Ops...)
 	}
 
-	// Marshal receipt and trace data
-	receiptMap, err := client.MarshalJSONMap(tx.Receipt)
-	if err != nil {
-		return nil, err
-	}
-
-	var traceList []map[string]interface{}
-	for _, trace := range tx.Trace {
-		traceBytes, _ := json.Marshal(trace)
-		var traceMap map[string]interface{}
-		if err := json.Unmarshal(traceBytes, &traceMap); err != nil {
-			return nil, err
-		}
-		traceList = append(traceList, traceMap)
-	}
+	s.renameOperations(ops)
 
 	var gasLimit uint64
 	if tx.Receipt != nil && tx.Receipt.GasUsed != nil {
@@ -231,32 +652,360 @@ func (s *BlockAPIService) PopulateTransaction(
 		Metadata: map[string]interface{}{
 			"gas_limit": hexutil.EncodeUint64(gasLimit),
 			"gas_price": hexutil.EncodeBig(gasPrice),
-			"receipt":   receiptMap,
-			"trace":     traceList,
 		},
 	}
 
+	// Marshal receipt and trace data canonically, so /block responses hash
+	// the same across runs and consumers
+	rosettaCfg := s.config.RosettaCfg
+	if rosettaCfg.ReceiptMetadataEnabled() {
+		receiptMap, err := client.MarshalCanonicalJSONMap(tx.Receipt)
+		if err != nil {
+			return nil, err
+		}
+		populatedTransaction.Metadata["receipt"] = receiptMap
+	}
+	if rosettaCfg.TraceMetadataEnabled() {
+		traceList, truncation, err := s.traceMetadata(tx.Trace)
+		if err != nil {
+			return nil, err
+		}
+		populatedTransaction.Metadata["trace"] = traceList
+		if truncation != nil {
+			populatedTransaction.Metadata[TraceTruncatedMetadataKey] = truncation
+		}
+	}
+	if rosettaCfg.IncludeRawTrace && len(tx.RawTrace) > 0 {
+		populatedTransaction.Metadata[RawTraceMetadataKey] = tx.RawTrace
+	}
+	if len(tx.Extensions) > 0 {
+		populatedTransaction.Metadata[ExtensionsMetadataKey] = tx.Extensions
+	}
+	if tx.Receipt != nil && len(tx.Receipt.GasPriceSource) > 0 {
+		populatedTransaction.Metadata[GasPriceSourceMetadataKey] = tx.Receipt.GasPriceSource
+	}
+
 	return populatedTransaction, nil
 }
 
+// TraceTruncation marks a trace list of the transaction metadata which
+// exceeded the limits of the configuration
+type TraceTruncation struct {
+	// OmittedCalls is the number of calls left out of the list
+	OmittedCalls int `json:"omitted_calls"`
+
+	// Limits are the exceeded limits, TraceMaxDepthLimit,
+	// TraceMaxEntriesLimit and TraceMaxBytesLimit
+	Limits []string `json:"limits"`
+}
+
+// traceMetadata returns the trace list of the transaction metadata, encoded
+// canonically and bounded by the trace metadata limits of the configuration,
+// and its truncation, nil when every call is in the list
+func (s *BlockAPIService) traceMetadata(
+	trace []*client.FlatCall,
+) ([]map[string]interface{}, *TraceTruncation, error) {
+	rosettaCfg := s.config.RosettaCfg
+	var truncation *TraceTruncation
+	omit := func(calls int, limit string) {
+		if truncation == nil {
+			truncation = &TraceTruncation{}
+		}
+		truncation.OmittedCalls += calls
+		for _, exceeded := range truncation.Limits {
+			if exceeded == limit {
+				return
+			}
+		}
+		truncation.Limits = append(truncation.Limits, limit)
+	}
+
+	var traceList []map[string]interface{}
+	size := 0
+	for i, call := range trace {
+		if rosettaCfg.TraceMetadataMaxDepth > 0 && call.Depth > rosettaCfg.TraceMetadataMaxDepth {
+			omit(1, TraceMaxDepthLimit)
+			continue
+		}
+		if rosettaCfg.TraceMetadataMaxEntries > 0 && len(traceList) == rosettaCfg.TraceMetadataMaxEntries {
+			omit(len(trace)-i, TraceMaxEntriesLimit)
+			break
+		}
+
+		traceMap, err := client.MarshalCanonicalJSONMap(call)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rosettaCfg.TraceMetadataMaxBytes > 0 {
+			encoded, err := json.Marshal(traceMap)
+			if err != nil {
+				return nil, nil, err
+			}
+			// Entries are separated by a comma in the encoded list
+			size += len(encoded) + 1
+			if size > rosettaCfg.TraceMetadataMaxBytes {
+				omit(len(trace)-i, TraceMaxBytesLimit)
+				break
+			}
+		}
+		traceList = append(traceList, traceMap)
+	}
+
+	return traceList, truncation, nil
+}
+
+// withoutSkippedTraces returns tx without the trace calls to the addresses
+// whose policy skips traces. The traces of tx itself are left unchanged, so
+// they are still part of the transaction metadata.
+func (s *BlockAPIService) withoutSkippedTraces(tx *client.LoadedTransaction) *client.LoadedTransaction {
+	if len(s.config.RosettaCfg.AddressPolicies) == 0 {
+		return tx
+	}
+
+	trace := make([]*client.FlatCall, 0, len(tx.Trace))
+	for _, call := range tx.Trace {
+		if policy := s.config.RosettaCfg.AddressPolicy(call.To); policy != nil && policy.SkipTraces {
+			continue
+		}
+		trace = append(trace, call)
+	}
+	if len(trace) == len(tx.Trace) {
+		return tx
+	}
+
+	filtered := *tx
+	filtered.Trace = trace
+	return &filtered
+}
+
+// renameOperations renames the types of ops according to the policies of
+// their accounts
+func (s *BlockAPIService) renameOperations(ops []*RosettaTypes.Operation) {
+	if len(s.config.RosettaCfg.AddressPolicies) == 0 {
+		return
+	}
+
+	for _, op := range ops {
+		if op.Account == nil || !common.IsHexAddress(op.Account.Address) {
+			continue
+		}
+		policy := s.config.RosettaCfg.AddressPolicy(common.HexToAddress(op.Account.Address))
+		if policy == nil {
+			continue
+		}
+		if opType, ok := policy.OperationTypes[op.Type]; ok {
+			op.Type = opType
+		}
+	}
+}
+
+// fillReceiptLogs fills the logs of the receipts of txs that come without
+// them. Some node providers return receipts without logs for very old blocks,
+// so when a receipt bloom indicates ERC20 events, all the logs of the block
+// are fetched with a single eth_getLogs and split by transaction.
+func (s *BlockAPIService) fillReceiptLogs(
+	ctx context.Context,
+	blockHash common.Hash,
+	txs []*client.LoadedTransaction,
+) error {
+	missing := make(map[common.Hash]*client.RosettaTxReceipt)
+	for _, tx := range txs {
+		if tx.Receipt == nil || tx.TxHash == nil || len(tx.Receipt.Logs) > 0 || !hasErc20LogTopic(tx.Receipt.Bloom) {
+			continue
+		}
+		missing[*tx.TxHash] = tx.Receipt
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var logs []*EthTypes.Log
+	if err := s.client.CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{"blockHash": blockHash}); err != nil {
+		return fmt.Errorf("could not get logs of block %s: %w", blockHash, err)
+	}
+
+	for _, log := range logs {
+		if receipt, ok := missing[log.TxHash]; ok {
+			receipt.Logs = append(receipt.Logs, log)
+		}
+	}
+
+	return nil
+}
+
+// addRevertReason adds the revert reason of tx to the metadata of its failed
+// operations. The reason is best effort, so it is skipped when it cannot be found.
+func (s *BlockAPIService) addRevertReason(
+	ctx context.Context,
+	tx *client.LoadedTransaction,
+	ops []*RosettaTypes.Operation,
+) {
+	var failedOps []*RosettaTypes.Operation
+	for _, op := range ops {
+		if op.Status != nil && *op.Status == AssetTypes.FailureStatus {
+			failedOps = append(failedOps, op)
+		}
+	}
+	if len(failedOps) == 0 {
+		return
+	}
+
+	reason, ok := s.revertReason(ctx, tx)
+	if !ok {
+		return
+	}
+
+	for _, op := range failedOps {
+		if op.Metadata == nil {
+			op.Metadata = map[string]interface{}{}
+		}
+		op.Metadata[RevertReasonMetadataKey] = reason
+	}
+}
+
+// revertReason decodes the Error(string) or Panic(uint256) revert reason of tx
+// from the output of its reverted calls. Tracers that don't return the output
+// fall back to re-executing the transaction with eth_call at the parent block,
+// which may not match the original execution if earlier transactions of the
+// block changed the state it depends on.
+func (s *BlockAPIService) revertReason(
+	ctx context.Context,
+	tx *client.LoadedTransaction,
+) (string, bool) {
+	for _, call := range tx.Trace {
+		if !call.Revert || len(call.Output) == 0 {
+			continue
+		}
+		if reason, err := abi.UnpackRevert(call.Output); err == nil {
+			return reason, true
+		}
+	}
+
+	if tx.Transaction == nil || tx.From == nil || tx.BlockNumber == nil {
+		return "", false
+	}
+	// The block number is hex encoded by eth_getBlockByHash but decimal
+	// encoded by GetLoadedTransaction
+	blockNumber, err := client.BigIntFromJSON(*tx.BlockNumber)
+	if err != nil || blockNumber.Sign() <= 0 {
+		return "", false
+	}
+
+	msg := map[string]interface{}{
+		"from":  tx.From,
+		"to":    tx.Transaction.To(),
+		"gas":   hexutil.Uint64(tx.Transaction.Gas()),
+		"value": (*hexutil.Big)(tx.Transaction.Value()),
+		"data":  hexutil.Bytes(tx.Transaction.Data()),
+	}
+	parent := hexutil.EncodeBig(new(big.Int).Sub(blockNumber, big.NewInt(1)))
+
+	var output hexutil.Bytes
+	err = s.client.CallContext(ctx, &output, "eth_call", msg, parent)
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return "", false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return "", false
+	}
+	revertData, err := hexutil.Decode(data)
+	if err != nil {
+		return "", false
+	}
+	reason, err := abi.UnpackRevert(revertData)
+	if err != nil {
+		return "", false
+	}
+
+	return reason, true
+}
+
+// erc20LogTopics returns the topics of all the ERC20 events we index
+func erc20LogTopics() []common.Hash {
+	return []common.Hash{
+		common.HexToHash(client.Erc20LogTopicMap[client.Erc20TransferLogTopic]),
+		common.HexToHash(client.Erc20LogTopicMap[client.Erc20DepositLogTopic]),
+		common.HexToHash(client.Erc20LogTopicMap[client.Erc20WithdrawalLogTopic]),
+	}
+}
+
+// hasErc20LogTopic returns true if the bloom may contain any of the ERC20 events we index
+func hasErc20LogTopic(bloom EthTypes.Bloom) bool {
+	for _, topic := range erc20LogTopics() {
+		if bloom.Test(topic.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetEthBlock returns a populated block at the *RosettaTypes.PartialBlockIdentifier.
 // If neither the hash or index is populated in the *RosettaTypes.PartialBlockIdentifier,
-// the current block is returned.
+// the default block of RosettaConfig.DefaultBlock is returned.
 func (s *BlockAPIService) GetEthBlock(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
 ) (*EthTypes.Block, []*client.LoadedTransaction, *client.RPCBlock, error) {
+	fetched, err := s.sharedEthBlock(ctx, blockIdentifier)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return fetched.block, fetched.loadedTxs, fetched.rpcBlock, nil
+}
+
+// fetchEthBlock fetches the block at blockIdentifier like GetEthBlock, and
+// also its receipts when withReceipts is set
+func (s *BlockAPIService) fetchEthBlock(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+	withReceipts bool,
+) (*fetchedBlock, error) {
 	if blockIdentifier != nil {
 		if blockIdentifier.Hash != nil {
-			return s.GetBlock(ctx, "eth_getBlockByHash", *blockIdentifier.Hash, true)
+			return s.fetchBlock(ctx, withReceipts, "eth_getBlockByHash", *blockIdentifier.Hash, true)
 		}
 
 		if blockIdentifier.Index != nil {
-			return s.GetBlock(ctx, "eth_getBlockByNumber", client.ToBlockNumArg(big.NewInt(*blockIdentifier.Index)), true)
+			return s.fetchBlock(
+				ctx,
+				withReceipts,
+				"eth_getBlockByNumber",
+				client.ToBlockNumArg(big.NewInt(*blockIdentifier.Index)),
+				true,
+			)
+		}
+	}
+
+	return s.fetchBlock(ctx, withReceipts, "eth_getBlockByNumber", string(s.config.RosettaCfg.DefaultBlock()), true)
+}
+
+// decodeHeader decodes the header of a JSON RPC block, returning its non
+// standard fields when RosettaConfig.TolerantHeaderDecoding is enabled
+func (s *BlockAPIService) decodeHeader(
+	raw json.RawMessage,
+) (*EthTypes.Header, map[string]json.RawMessage, error) {
+	if !s.config.RosettaCfg.TolerantHeaderDecoding {
+		var head EthTypes.Header
+		if err := json.Unmarshal(raw, &head); err != nil {
+			return nil, nil, err
 		}
+		return &head, nil, nil
 	}
 
-	return s.GetBlock(ctx, "eth_getBlockByNumber", client.ToBlockNumArg(nil), true)
+	head, extraFields, err := client.DecodeHeaderTolerant(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mapper, ok := s.client.(client.HeaderMapper); ok {
+		if err := mapper.MapHeader(extraFields, head); err != nil {
+			return nil, nil, fmt.Errorf("could not map header: %w", err)
+		}
+	}
+
+	return head, extraFields, nil
 }
 
 func (s *BlockAPIService) GetBlock(
@@ -269,72 +1018,154 @@ func (s *BlockAPIService) GetBlock(
 	*client.RPCBlock,
 	error,
 ) {
+	fetched, err := s.fetchBlock(ctx, false, blockMethod, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return fetched.block, fetched.loadedTxs, fetched.rpcBlock, nil
+}
+
+// fetchedBlock is a block with the data fetched for it by fetchBlock
+type fetchedBlock struct {
+	block     *EthTypes.Block
+	loadedTxs []*client.LoadedTransaction
+	rpcBlock  *client.RPCBlock
+
+	// raw is the JSON RPC encoding of the block
+	raw json.RawMessage
+
+	// receipts and receiptsErr are the result of GetBlockReceipts, when
+	// the receipts are fetched
+	receipts    []*client.RosettaTxReceipt
+	receiptsErr error
+}
+
+// fetchBlock fetches a block with blockMethod. Once its body is decoded, the
+// block author, traces, uncles and, when withReceipts is set, receipts are
+// fetched concurrently, so a block costs two round trips to the node instead
+// of one per kind of data.
+func (s *BlockAPIService) fetchBlock(
+	ctx context.Context,
+	withReceipts bool,
+	blockMethod string,
+	args ...interface{},
+) (*fetchedBlock, error) {
 	var raw json.RawMessage
 	err := s.client.CallContext(ctx, &raw, blockMethod, args...)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("block fetch failed: %w", err)
+		return nil, fmt.Errorf("block fetch failed: %w", err)
 	} else if len(raw) == 0 {
-		return nil, nil, nil, goEthereum.NotFound
+		return nil, goEthereum.NotFound
 	}
 
 	// Decode header and transactions
-	var head EthTypes.Header
-	var body client.RPCBlock
-	if err := json.Unmarshal(raw, &head); err != nil {
-		return nil, nil, nil, err
+	head, headerExtraFields, err := s.decodeHeader(raw)
+	if err != nil {
+		return nil, err
 	}
+	var body client.RPCBlock
 	if s.config.RosettaCfg.SupportCustomizedBlockBody {
 		err = s.client.GetCustomizedBlockBody(raw, &body)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, err
 		}
 	} else {
 		if err := json.Unmarshal(raw, &body); err != nil {
-			return nil, nil, nil, err
+			return nil, err
 		}
 	}
+	body.HeaderExtraFields = headerExtraFields
 
 	// Note: We need a full node to return a complete RPCBlock,
 	// otherwise, only body.Hash is populated. body.Transactions is empty.
 	// TODO(xiaying): log warn if len(body.Hash) > 1 && len(body.txs) == 0
 
-	var blockAuthor string
-	if s.client.GetRosettaConfig().SupportsBlockAuthor {
-		blockAuthor, err = s.client.BlockAuthor(ctx, head.Number.Int64())
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("could not get block author for %x: %w", body.Hash[:], err)
-		}
+	// The errors of the group are returned as is, so it doesn't cancel the
+	// context of the other fetches
+	var (
+		g           errgroup.Group
+		fetched     = &fetchedBlock{rpcBlock: &body, raw: raw}
+		blockAuthor string
+		m           map[string][]*client.FlatCall
+		uncles      = []*EthTypes.Header{}
+	)
+
+	supportsBlockAuthor := s.client.GetRosettaConfig().SupportsBlockAuthor
+	if supportsBlockAuthor {
+		g.Go(func() error {
+			author, err := s.client.BlockAuthor(ctx, head.Number.Int64())
+			if err != nil {
+				return fmt.Errorf("could not get block author for %x: %w", body.Hash[:], err)
+			}
+			// A malformed author is kept as is and fails the fee operations of
+			// each transaction instead of the whole block
+			if checksummed, err := client.ChecksumAddress(author); err == nil {
+				author = checksummed
+			}
+			blockAuthor = author
+			return nil
+		})
 	}
 
-	var m map[string][]*client.FlatCall
-	var addTraces bool
-	if head.Number.Int64() != AssetTypes.GenesisBlockIndex {
-		addTraces = true
+	addTraces := head.Number.Int64() != AssetTypes.GenesisBlockIndex
+	if addTraces {
 		// Use open ethereum trace API if selected.
-		if s.client.GetRosettaConfig().TraceType == configuration.OpenEthereumTrace {
-			m, err = s.client.TraceReplayBlockTransactions(ctx, body.Hash.String())
-		} else {
-			m, err = s.client.TraceBlockByHash(ctx, body.Hash, body.Transactions)
-		}
+		openEthereumTrace := s.client.GetRosettaConfig().TraceType == configuration.OpenEthereumTrace
+		g.Go(func() error {
+			var err error
+			if openEthereumTrace {
+				m, err = s.client.TraceReplayBlockTransactions(ctx, body.Hash.String())
+			} else {
+				m, err = s.client.TraceBlockByHash(ctx, body.Hash, body.Transactions)
+			}
+			return err
+		})
+	}
 
-		if err != nil {
-			return nil, nil, nil, err
+	if s.client.GetRosettaConfig().UnclesEnabled() {
+		g.Go(func() error {
+			var err error
+			uncles, err = s.client.GetUncles(ctx, head, &body)
+			if err != nil {
+				return fmt.Errorf("unable to get uncles: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if withReceipts {
+		var baseFee *big.Int
+		// in internal is len(loadedTxns) > 1
+		if len(body.Transactions) > 0 {
+			baseFee = head.BaseFee
 		}
+		g.Go(func() error {
+			fetched.receipts, fetched.receiptsErr = s.client.GetBlockReceipts(ctx, body.Hash, body.Transactions, baseFee)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
 	// Convert all txs to loaded txs
 	txs := make([]*EthTypes.Transaction, len(body.Transactions))
 	loadedTxs := make([]*client.LoadedTransaction, len(body.Transactions))
+	eip6780 := s.config.ChainConfig != nil && s.config.ChainConfig.IsCancun(head.Number, head.Time)
 	for i, tx := range body.Transactions {
 		txs[i] = tx.Tx
 		loadedTxs[i] = tx.LoadedTransaction()
 		loadedTxs[i].Transaction = txs[i]
 		loadedTxs[i].BaseFee = head.BaseFee
+		loadedTxs[i].FeeCurrency = s.config.RosettaCfg.GasCurrency
+		loadedTxs[i].EIP6780 = eip6780
 
-		if s.client.GetRosettaConfig().SupportsBlockAuthor {
-			loadedTxs[i].Author = client.MustChecksum(blockAuthor)
+		if supportsBlockAuthor {
+			loadedTxs[i].Author = blockAuthor
 		} else {
-			loadedTxs[i].Miner = client.MustChecksum(head.Coinbase.Hex())
+			loadedTxs[i].Miner = client.FormatAddress(head.Coinbase)
 		}
 
 		// Continue if calls does not exist (occurs at genesis)
@@ -348,46 +1179,160 @@ func (s *BlockAPIService) GetBlock(
 		}
 	}
 
-	uncles := []*EthTypes.Header{}
-	if s.client.GetRosettaConfig().SupportRewardTx {
-		uncles, err = s.client.GetUncles(ctx, &head, &body)
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("unable to get uncles: %w", err)
+	fetched.block = EthTypes.NewBlockWithHeader(head).WithBody(txs, uncles)
+	if body.Withdrawals != nil {
+		fetched.block = fetched.block.WithWithdrawals(body.Withdrawals)
+	}
+	fetched.loadedTxs = loadedTxs
+	return fetched, nil
+}
+
+// transactionSenders returns the senders of loadedTxs returned by the node
+func transactionSenders(loadedTxs []*client.LoadedTransaction) []common.Address {
+	senders := make([]common.Address, len(loadedTxs))
+	for i, tx := range loadedTxs {
+		if tx.From != nil {
+			senders[i] = *tx.From
 		}
 	}
+	return senders
+}
 
-	return EthTypes.NewBlockWithHeader(&head).WithBody(txs, uncles), loadedTxs, &body, nil
+// blockPruned returns whether the block of blockIdentifier is older than the
+// oldest block the node serves, when the client can find it
+func (s *BlockAPIService) blockPruned(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) bool {
+	finder, ok := s.client.(client.OldestBlockFinder)
+	if !ok || blockIdentifier == nil || blockIdentifier.Index == nil {
+		return false
+	}
+
+	oldestBlock, err := finder.OldestBlock(ctx)
+	if err != nil {
+		log.Printf("could not find the oldest block: %v", err)
+		return false
+	}
+
+	return *blockIdentifier.Index < oldestBlock.Index
 }
 
 // Block implements the /block endpoint.
 func (s *BlockAPIService) Block(
 	ctx context.Context,
 	request *RosettaTypes.BlockRequest,
+) (*RosettaTypes.BlockResponse, *RosettaTypes.Error) {
+	if s.prefetcher == nil || request.BlockIdentifier == nil {
+		return s.sharedBlock(ctx, request)
+	}
+
+	// Prefetched blocks are requested by index by sequential indexers
+	if request.BlockIdentifier.Hash == nil && request.BlockIdentifier.Index != nil {
+		if block, ok := s.canonicalCachedBlock(ctx, *request.BlockIdentifier.Index); ok {
+			s.prefetcher.requested(block.BlockIdentifier.Index)
+			return &RosettaTypes.BlockResponse{
+				Block: withCacheStatus(block, BlockCacheHit),
+			}, nil
+		}
+	}
+
+	response, err := s.sharedBlock(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.prefetcher.requested(response.Block.BlockIdentifier.Index)
+
+	return response, nil
+}
+
+// canonicalCachedBlock returns the cached block at index if it is still the
+// block of the node at index
+func (s *BlockAPIService) canonicalCachedBlock(ctx context.Context, index int64) (*RosettaTypes.Block, bool) {
+	block, ok := s.blockCache.getByIndex(index)
+	if !ok {
+		return nil, false
+	}
+
+	var header struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	blockNumber := client.ToBlockNumArg(big.NewInt(index))
+	if err := s.client.CallContext(ctx, &header, "eth_getBlockByNumber", blockNumber, false); err != nil || header.Hash == nil {
+		return nil, false
+	}
+	hash, err := s.client.GetBlockHash(ctx, RosettaTypes.BlockIdentifier{Index: index, Hash: header.Hash.Hex()})
+	if err != nil {
+		return nil, false
+	}
+
+	return block, strings.EqualFold(hash, block.BlockIdentifier.Hash)
+}
+
+// prefetchBlock fetches the block at index into the block cache, unless it is
+// already cached
+func (s *BlockAPIService) prefetchBlock(ctx context.Context, index int64) error {
+	if _, ok := s.blockCache.getByIndex(index); ok {
+		return nil
+	}
+
+	request := &RosettaTypes.BlockRequest{
+		BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Index: &index},
+	}
+	if _, err := s.sharedBlock(ctx, request); err != nil {
+		return fmt.Errorf("could not prefetch block %d: %s", index, err.Message)
+	}
+
+	return nil
+}
+
+// block populates the block of request
+func (s *BlockAPIService) block(
+	ctx context.Context,
+	request *RosettaTypes.BlockRequest,
 ) (*RosettaTypes.BlockResponse, *RosettaTypes.Error) {
 	if s.config.IsOfflineMode() {
 		return nil, AssetTypes.ErrUnavailableOffline
 	}
 
+	// Blocks requested by hash can be served from the cache
+	if s.blockCache != nil && request.BlockIdentifier != nil && request.BlockIdentifier.Hash != nil {
+		if block, ok := s.blockCache.get(*request.BlockIdentifier.Hash); ok {
+			return &RosettaTypes.BlockResponse{
+				Block: withCacheStatus(block, BlockCacheHit),
+			}, nil
+		}
+	}
+
 	var (
 		blockIdentifier       *RosettaTypes.BlockIdentifier
 		parentBlockIdentifier *RosettaTypes.BlockIdentifier
 	)
 
-	block, loadedTxns, rpcBlock, err := s.GetEthBlock(ctx, request.BlockIdentifier)
+	fetched, err := s.fetchEthBlock(ctx, request.BlockIdentifier, true)
 	if errors.Is(err, AssetTypes.ErrClientBlockOrphaned) {
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrBlockOrphaned, err)
 	}
 
 	if err != nil {
+		if s.blockPruned(ctx, request.BlockIdentifier) {
+			return nil, AssetTypes.WrapErr(AssetTypes.ErrBlockPruned, err)
+		}
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
 	}
 
+	block, loadedTxns, rpcBlock := fetched.block, fetched.loadedTxs, fetched.rpcBlock
+
+	if err := s.validateBlock(ctx, block, rpcBlock, loadedTxns); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrValidationFailed, err)
+	}
+
 	var baseFee *big.Int
 	// in internal is len(loadedTxns) > 1
 	if len(loadedTxns) > 0 {
 		baseFee = loadedTxns[0].BaseFee
 	}
-	receipts, err := s.client.GetBlockReceipts(ctx, rpcBlock.Hash, rpcBlock.Transactions, baseFee)
+	receipts, err := fetched.receipts, fetched.receiptsErr
 	if err != nil {
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrInternalError, fmt.Errorf("could not get receipts for %x: %w", rpcBlock.Hash[:], err))
 	}
@@ -407,11 +1352,19 @@ func (s *BlockAPIService) Block(
 		} else {
 			tx.FeeBurned = baseFee
 		}
+
+		if err := s.applyReceiptFees(ctx, tx); err != nil {
+			return nil, err
+		}
 	}
 
+	hash, err := s.blockHash(block, rpcBlock)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrInternalError, fmt.Errorf("could not hash block %d: %w", block.NumberU64(), err))
+	}
 	blockIdentifier = &RosettaTypes.BlockIdentifier{
 		Index: block.Number().Int64(),
-		Hash:  block.Hash().String(),
+		Hash:  hash.String(),
 	}
 	blockIdentifier.Hash, err = s.client.GetBlockHash(ctx, *blockIdentifier)
 	if err != nil {
@@ -426,10 +1379,22 @@ func (s *BlockAPIService) Block(
 		}
 	}
 
+	loadedTxns, err = s.filterTransactions(ctx, loadedTxns)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
 	crossTxns, err := s.client.PopulateCrossChainTransactions(block, loadedTxns)
 	if err != nil {
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
 	}
+	if provider, ok := s.client.(client.AtomicTransactionProvider); ok {
+		atomicTxns, err := provider.AtomicTransactions(ctx, block, fetched.raw)
+		if err != nil {
+			return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+		}
+		crossTxns = append(crossTxns, atomicTxns...)
+	}
 
 	transactions, err := s.populateTransactions(
 		ctx,
@@ -442,17 +1407,132 @@ func (s *BlockAPIService) Block(
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
 	}
 
+	if err := s.addBalanceAdjustments(ctx, blockIdentifier, parentBlockIdentifier, transactions); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	metadata := blockMetadata(block, rpcBlock, s.config.RosettaCfg.BlockMetadataFields)
+	if s.config.IsIstanbulConsensus() {
+		metadata, err = withIstanbulMetadata(metadata, s.config.Consensus(), block.Header())
+		if err != nil {
+			return nil, AssetTypes.WrapErr(AssetTypes.ErrInternalError, err)
+		}
+	}
+
+	rosettaBlock := &RosettaTypes.Block{
+		BlockIdentifier:       blockIdentifier,
+		ParentBlockIdentifier: parentBlockIdentifier,
+		Timestamp:             int64(block.Time() * utils.MillisecondsInSecond),
+		Transactions:          append(transactions, crossTxns...),
+		Metadata:              metadata,
+	}
+	if s.blockCache != nil {
+		// Blocks with transactions that could not be parsed are fetched again
+		// rather than served from the cache
+		if !hasParseErrors(rosettaBlock) {
+			s.blockCache.add(rosettaBlock)
+		}
+		rosettaBlock = withCacheStatus(rosettaBlock, BlockCacheMiss)
+	}
+
 	return &RosettaTypes.BlockResponse{
-		Block: &RosettaTypes.Block{
-			BlockIdentifier:       blockIdentifier,
-			ParentBlockIdentifier: parentBlockIdentifier,
-			Timestamp:             int64(block.Time() * utils.MillisecondsInSecond),
-			Transactions:          append(transactions, crossTxns...),
-			Metadata:              nil,
-		},
+		Block: rosettaBlock,
 	}, nil
 }
 
+// blockMetadata returns the header fields added by Dencun, so consumers can
+// tie a block to its beacon chain slot without another RPC call, the non
+// standard header fields of the block, and the header fields of fields, see
+// RosettaConfig.BlockMetadataFields. It returns nil for standard blocks from
+// before Dencun without fields.
+func blockMetadata(block *EthTypes.Block, rpcBlock *client.RPCBlock, fields []string) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for _, field := range fields {
+		switch field {
+		case configuration.GasUsedBlockMetadataField:
+			metadata[field] = hexutil.EncodeUint64(block.GasUsed())
+		case configuration.GasLimitBlockMetadataField:
+			metadata[field] = hexutil.EncodeUint64(block.GasLimit())
+		case configuration.BaseFeeBlockMetadataField:
+			if baseFee := block.BaseFee(); baseFee != nil {
+				metadata[field] = hexutil.EncodeBig(baseFee)
+			}
+		case configuration.SizeBlockMetadataField:
+			// The size computed from the decoded block misses the non
+			// standard fields of the block
+			if rpcBlock != nil && rpcBlock.Size != nil {
+				metadata[field] = rpcBlock.Size.String()
+			} else {
+				metadata[field] = hexutil.EncodeUint64(block.Size())
+			}
+		case configuration.ExtraDataBlockMetadataField:
+			metadata[field] = hexutil.Encode(block.Extra())
+		case configuration.MixHashBlockMetadataField:
+			metadata[field] = block.MixDigest().Hex()
+		}
+	}
+	if rpcBlock != nil && len(rpcBlock.HeaderExtraFields) > 0 {
+		metadata[HeaderExtraFieldsMetadataKey] = rpcBlock.HeaderExtraFields
+	}
+	if root := block.BeaconRoot(); root != nil {
+		metadata[ParentBeaconBlockRootMetadataKey] = root.Hex()
+	}
+	if blobGasUsed := block.BlobGasUsed(); blobGasUsed != nil {
+		metadata[BlobGasUsedMetadataKey] = hexutil.EncodeUint64(*blobGasUsed)
+	}
+	if excessBlobGas := block.ExcessBlobGas(); excessBlobGas != nil {
+		metadata[ExcessBlobGasMetadataKey] = hexutil.EncodeUint64(*excessBlobGas)
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	return metadata
+}
+
+// blockHash returns the hash of the header of block, computed by the client
+// when it implements validator.HeaderHasher
+func (s *BlockAPIService) blockHash(block *EthTypes.Block, rpcBlock *client.RPCBlock) (common.Hash, error) {
+	hasher, ok := s.client.(validator.HeaderHasher)
+	if !ok {
+		return block.Hash(), nil
+	}
+
+	var extraFields map[string]json.RawMessage
+	if rpcBlock != nil {
+		extraFields = rpcBlock.HeaderExtraFields
+	}
+	return hasher.HashHeader(block.Header(), extraFields)
+}
+
+// withIstanbulMetadata adds the proposer, validator set and round of an IBFT
+// or QBFT header to the block metadata. GoQuorum IBFT headers have no round.
+func withIstanbulMetadata(
+	metadata map[string]interface{},
+	engine string,
+	header *EthTypes.Header,
+) (map[string]interface{}, error) {
+	istanbulHeader, err := client.ParseIstanbulHeader(engine, header)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make([]string, len(istanbulHeader.Validators))
+	for i, address := range istanbulHeader.Validators {
+		validators[i] = client.FormatAddress(address)
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata[ProposerMetadataKey] = client.FormatAddress(istanbulHeader.Proposer)
+	metadata[ValidatorsMetadataKey] = validators
+	if engine != configuration.IstanbulConsensusEngine {
+		metadata[RoundMetadataKey] = hexutil.EncodeUint64(uint64(istanbulHeader.Round))
+	}
+
+	return metadata, nil
+}
+
 // BlockTransaction implements the /block/transaction endpoint.
 func (s *BlockAPIService) BlockTransaction(
 	ctx context.Context,
@@ -500,12 +1580,39 @@ func (s *BlockAPIService) BlockTransaction(
 	} else {
 		loadedTx.FeeBurned = nil
 	}
+	if err := s.applyReceiptFees(ctx, loadedTx); err != nil {
+		return nil, err
+	}
 
-	transaction, err := s.PopulateTransaction(ctx, loadedTx)
+	filtered, err := s.filterTransactions(ctx, []*client.LoadedTransaction{loadedTx})
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+	if len(filtered) == 0 {
+		return nil, AssetTypes.WrapErr(
+			AssetTypes.ErrInvalidInput,
+			fmt.Errorf("transaction %s is dropped by the transaction filter", loadedTx.TxHash),
+		)
+	}
+
+	if err := s.fillReceiptLogs(ctx, common.HexToHash(request.BlockIdentifier.Hash), filtered); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	transaction, err := s.populateTransactionWithPolicy(ctx, loadedTx)
 	if err != nil {
 		return nil, AssetTypes.WrapErr(AssetTypes.ErrInternalError, fmt.Errorf("unable to populate tx: %w", err))
 	}
 
+	transaction, err = s.blockTransactionWithAdjustments(ctx, request.BlockIdentifier, transaction)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	if err := s.addTransactionProof(ctx, request.BlockIdentifier, transaction); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrValidationFailed, err)
+	}
+
 	return &RosettaTypes.BlockTransactionResponse{
 		Transaction: transaction,
 	}, nil
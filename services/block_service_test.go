@@ -17,21 +17,33 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
 
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
 
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/coinbase/rosetta-geth-sdk/client"
 	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	"github.com/coinbase/rosetta-geth-sdk/services/validator"
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
 
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 
@@ -544,3 +556,1420 @@ func TestBlockService_Online(t *testing.T) {
 	})
 	mockClient.AssertExpectations(t)
 }
+
+func TestPopulateTransactions_ReceiptLogsFallback(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockHash := common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae")
+	txHash := common.HexToHash(hsh)
+	otherTxHash := common.HexToHash("0x01")
+	from := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	transferEvent := common.HexToHash(client.Erc20LogTopicMap[client.Erc20TransferLogTopic])
+
+	var bloom EthTypes.Bloom
+	bloom.Add(transferEvent.Bytes())
+
+	newTx := func(hash *common.Hash) *client.LoadedTransaction {
+		return &client.LoadedTransaction{
+			Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+			From:        &from,
+			BlockHash:   &blockHash,
+			TxHash:      hash,
+			Receipt: &client.RosettaTxReceipt{
+				Bloom: bloom,
+			},
+		}
+	}
+	tx := newTx(&txHash)
+	otherTx := newTx(&otherTxHash)
+
+	transferLog := &EthTypes.Log{
+		Address: common.HexToAddress("0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b"),
+		Topics: []common.Hash{
+			transferEvent,
+			common.HexToHash("0x0000000000000000000000004dc8f417d4eb731d179a0f08b1feaf25216cefd0"),
+			common.HexToHash("0x0000000000000000000000000d2b2fb39b10cd50cab7aa8e834879069ab1a8d4"),
+		},
+		Data:   common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+		TxHash: txHash,
+	}
+	// Logs of other events are kept in the receipt too
+	approvalLog := &EthTypes.Log{
+		Address: transferLog.Address,
+		Topics:  []common.Hash{common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")},
+		TxHash:  txHash,
+	}
+	otherTxLog := &EthTypes.Log{
+		Address: transferLog.Address,
+		Topics:  []common.Hash{common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")},
+		TxHash:  otherTxHash,
+	}
+
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("ParseOps", otherTx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	// The logs of the block are fetched once for all its transactions
+	mockClient.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getLogs",
+		map[string]interface{}{"blockHash": blockHash},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*[]*EthTypes.Log)
+			*r = []*EthTypes.Log{otherTxLog, transferLog, approvalLog}
+		},
+	).Once()
+	mockClient.On("SkipTxReceiptParsing", mock.Anything).Return(false)
+	mockClient.On("GetContractCurrency", transferLog.Address, true).Return(
+		&client.ContractCurrency{
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+		nil,
+	).Once()
+
+	transactions, err := servicer.populateTransactions(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+		EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+		[]*client.LoadedTransaction{tx, otherTx},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, 2, len(transactions[0].Operations))
+	assert.Equal(t, AssetTypes.OpErc20Transfer, transactions[0].Operations[0].Type)
+	assert.Equal(t, "-100", transactions[0].Operations[0].Amount.Value)
+	assert.Equal(t, "100", transactions[0].Operations[1].Amount.Value)
+	assert.Empty(t, transactions[1].Operations)
+	assert.Equal(t, []*EthTypes.Log{transferLog, approvalLog}, tx.Receipt.Logs)
+	assert.Equal(t, []*EthTypes.Log{otherTxLog}, otherTx.Receipt.Logs)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestPopulateTransaction_NoReceiptLogsFallbackWithoutBloom(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockHash := common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae")
+	txHash := common.HexToHash(hsh)
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		BlockHash:   &blockHash,
+		TxHash:      &txHash,
+		Receipt:     &client.RosettaTxReceipt{},
+	}
+
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+	transaction, err := servicer.PopulateTransaction(ctx, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(transaction.Operations))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestPopulateTransaction_GasPriceSource(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	txHash := common.HexToHash(hsh)
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTx(&EthTypes.DynamicFeeTx{Gas: 21000, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20)}),
+		TxHash:      &txHash,
+		Receipt: &client.RosettaTxReceipt{
+			GasPrice:       big.NewInt(12),
+			GasUsed:        big.NewInt(21000),
+			GasPriceSource: client.ComputedGasPriceSource,
+		},
+	}
+
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+	transaction, err := servicer.PopulateTransaction(ctx, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xc", transaction.Metadata["gas_price"])
+	assert.Equal(t, client.ComputedGasPriceSource, transaction.Metadata[GasPriceSourceMetadataKey])
+
+	// Receipts of clients that don't set the source have none
+	tx.Receipt.GasPriceSource = ""
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	transaction, err = servicer.PopulateTransaction(ctx, tx)
+	assert.NoError(t, err)
+	assert.NotContains(t, transaction.Metadata, GasPriceSourceMetadataKey)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestPopulateTransaction_CanonicalMetadata(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+
+	oneEther, _ := new(big.Int).SetString("1000000000000000000", 10)
+	txHash := common.HexToHash(hsh)
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, oneEther, 21000, big.NewInt(1), nil),
+		TxHash:      &txHash,
+		Trace: []*client.FlatCall{{
+			Type:    "CALL",
+			Value:   oneEther,
+			GasUsed: big.NewInt(21000),
+		}},
+		Receipt: &client.RosettaTxReceipt{
+			GasPrice:       big.NewInt(1),
+			GasUsed:        big.NewInt(21000),
+			TransactionFee: new(big.Int).Mul(oneEther, big.NewInt(3)),
+			Status:         1,
+		},
+	}
+
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+	transaction, err := servicer.PopulateTransaction(context.Background(), tx)
+	assert.NoError(t, err)
+
+	// Amounts above 2^53 are not rounded by consumers decoding them as float64
+	receipt := transaction.Metadata["receipt"].(map[string]interface{})
+	assert.Equal(t, "3000000000000000000", receipt["TransactionFee"])
+	assert.Equal(t, float64(21000), receipt["GasUsed"])
+	assert.Equal(t, float64(1), receipt["status"])
+	trace := transaction.Metadata["trace"].([]map[string]interface{})
+	assert.Equal(t, "1000000000000000000", trace[0]["value"])
+	assert.Equal(t, float64(21000), trace[0]["gasUsed"])
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestPopulateTransaction_TraceLimits(t *testing.T) {
+	txHash := common.HexToHash(hsh)
+	var trace []*client.FlatCall
+	for _, depth := range []int{0, 1, 2, 1, 2} {
+		trace = append(trace, &client.FlatCall{
+			Type:    "CALL",
+			Value:   big.NewInt(1),
+			GasUsed: big.NewInt(21000),
+			Depth:   depth,
+		})
+	}
+	entry, err := client.MarshalCanonicalJSONMap(trace[0])
+	assert.NoError(t, err)
+	encoded, err := json.Marshal(entry)
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		rosettaCfg configuration.RosettaConfig
+
+		expectedEntries    int
+		expectedTruncation *TraceTruncation
+	}{
+		"no limits": {
+			expectedEntries: 5,
+		},
+		"limits not exceeded": {
+			rosettaCfg: configuration.RosettaConfig{
+				TraceMetadataMaxDepth:   2,
+				TraceMetadataMaxEntries: 5,
+				TraceMetadataMaxBytes:   5 * (len(encoded) + 1),
+			},
+			expectedEntries: 5,
+		},
+		"max depth": {
+			rosettaCfg:      configuration.RosettaConfig{TraceMetadataMaxDepth: 1},
+			expectedEntries: 3,
+			expectedTruncation: &TraceTruncation{
+				OmittedCalls: 2,
+				Limits:       []string{TraceMaxDepthLimit},
+			},
+		},
+		"max entries": {
+			rosettaCfg:      configuration.RosettaConfig{TraceMetadataMaxEntries: 2},
+			expectedEntries: 2,
+			expectedTruncation: &TraceTruncation{
+				OmittedCalls: 3,
+				Limits:       []string{TraceMaxEntriesLimit},
+			},
+		},
+		"max depth and entries": {
+			rosettaCfg: configuration.RosettaConfig{
+				TraceMetadataMaxDepth:   1,
+				TraceMetadataMaxEntries: 2,
+			},
+			expectedEntries: 2,
+			expectedTruncation: &TraceTruncation{
+				OmittedCalls: 3,
+				Limits:       []string{TraceMaxDepthLimit, TraceMaxEntriesLimit},
+			},
+		},
+		"max bytes": {
+			rosettaCfg:      configuration.RosettaConfig{TraceMetadataMaxBytes: 3*(len(encoded)+1) - 1},
+			expectedEntries: 2,
+			expectedTruncation: &TraceTruncation{
+				OmittedCalls: 3,
+				Limits:       []string{TraceMaxBytesLimit},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode:       configuration.ModeOnline,
+				RosettaCfg: test.rosettaCfg,
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+			tx := &client.LoadedTransaction{
+				Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil),
+				TxHash:      &txHash,
+				Trace:       trace,
+			}
+
+			mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+			mockClient.On("GetRosettaConfig").Return(test.rosettaCfg)
+
+			transaction, err := servicer.PopulateTransaction(context.Background(), tx)
+			assert.NoError(t, err)
+			assert.Len(t, transaction.Metadata["trace"], test.expectedEntries)
+			if test.expectedTruncation == nil {
+				assert.NotContains(t, transaction.Metadata, TraceTruncatedMetadataKey)
+			} else {
+				assert.Equal(t, test.expectedTruncation, transaction.Metadata[TraceTruncatedMetadataKey])
+			}
+
+			// Operations are still parsed from every call
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPopulateTransaction_MetadataOptions(t *testing.T) {
+	txHash := common.HexToHash(hsh)
+	rawTrace := json.RawMessage(`{"type":"CALL","value":"0x1"}`)
+	enabled, disabled := true, false
+
+	tests := map[string]struct {
+		rosettaCfg configuration.RosettaConfig
+
+		expectedKeys []string
+	}{
+		"default": {
+			expectedKeys: []string{"gas_limit", "gas_price", "receipt", "trace"},
+		},
+		"without receipt and trace": {
+			rosettaCfg: configuration.RosettaConfig{
+				IncludeReceiptMetadata: &disabled,
+				IncludeTraceMetadata:   &disabled,
+			},
+			expectedKeys: []string{"gas_limit", "gas_price"},
+		},
+		"with raw trace": {
+			rosettaCfg: configuration.RosettaConfig{
+				IncludeReceiptMetadata: &enabled,
+				IncludeTraceMetadata:   &disabled,
+				IncludeRawTrace:        true,
+			},
+			expectedKeys: []string{"gas_limit", "gas_price", "receipt", RawTraceMetadataKey},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode:       configuration.ModeOnline,
+				RosettaCfg: test.rosettaCfg,
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+			tx := &client.LoadedTransaction{
+				Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil),
+				TxHash:      &txHash,
+				Trace:       []*client.FlatCall{{Type: "CALL", Value: big.NewInt(1), GasUsed: new(big.Int)}},
+				RawTrace:    rawTrace,
+				Receipt:     &client.RosettaTxReceipt{GasPrice: big.NewInt(1), GasUsed: big.NewInt(21000)},
+			}
+
+			mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+			mockClient.On("GetRosettaConfig").Return(test.rosettaCfg)
+
+			transaction, err := servicer.PopulateTransaction(context.Background(), tx)
+			assert.NoError(t, err)
+			keys := make([]string, 0, len(transaction.Metadata))
+			for key := range transaction.Metadata {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, test.expectedKeys, keys)
+			if test.rosettaCfg.IncludeRawTrace {
+				assert.Equal(t, rawTrace, transaction.Metadata[RawTraceMetadataKey])
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPopulateTransactions_ParseErrors(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockHash := common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae")
+	badHash := common.HexToHash("0x01")
+	goodHash := common.HexToHash(hsh)
+	badTx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		BlockHash:   &blockHash,
+		TxHash:      &badHash,
+	}
+	goodTx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(1, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		BlockHash:   &blockHash,
+		TxHash:      &goodHash,
+	}
+
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("ParseOps", badTx).Return(
+		nil,
+		fmt.Errorf("fee recipient 0xgarbage: %w", client.ErrInvalidAddress),
+	).Once()
+	mockClient.On("ParseOps", goodTx).Return([]*RosettaTypes.Operation{}, nil).Once()
+
+	transactions, err := servicer.populateTransactions(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+		EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+		[]*client.LoadedTransaction{badTx, goodTx},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, badHash.Hex(), transactions[0].TransactionIdentifier.Hash)
+	assert.Empty(t, transactions[0].Operations)
+	assert.Equal(
+		t,
+		"fee recipient 0xgarbage: invalid address",
+		transactions[0].Metadata[ParseErrorMetadataKey],
+	)
+	assert.Equal(t, goodHash.Hex(), transactions[1].TransactionIdentifier.Hash)
+	assert.NotContains(t, transactions[1].Metadata, ParseErrorMetadataKey)
+
+	mockClient.On("ParseOps", badTx).Return(nil, errors.New("node unavailable")).Once()
+	_, err = servicer.populateTransactions(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+		EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+		[]*client.LoadedTransaction{badTx},
+	)
+	assert.Error(t, err)
+
+	// Any parse error is served with the lenient parse policy
+	cfg.RosettaCfg.TransactionParsePolicy = configuration.LenientParsePolicy
+	parseErrors := expvarValue(parseStats, "parse_errors")
+	mockClient.On("ParseOps", badTx).Return(nil, errors.New("unsupported trace")).Once()
+	mockClient.On("ParseOps", goodTx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	transactions, err = servicer.populateTransactions(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+		EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+		[]*client.LoadedTransaction{badTx, goodTx},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "unsupported trace", transactions[0].Metadata[ParseErrorMetadataKey])
+	assert.NotContains(t, transactions[1].Metadata, ParseErrorMetadataKey)
+	assert.Equal(t, parseErrors+1, expvarValue(parseStats, "parse_errors"))
+
+	// Or when the node could not be queried
+	nodeErrors := map[string]error{
+		"timeout":            fmt.Errorf("cannot get receipt: %w", context.DeadlineExceeded),
+		"dropped connection": &net.OpError{Op: "read", Net: "tcp", Err: io.ErrUnexpectedEOF},
+		"5xx":                rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"},
+		"rpc error":          &testutil.MethodNotFoundError{Method: "eth_getTransactionReceipt"},
+	}
+	for name, nodeErr := range nodeErrors {
+		mockClient.On("ParseOps", badTx).Return(nil, nodeErr).Once()
+		_, err = servicer.populateTransactions(
+			ctx,
+			&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+			EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+			[]*client.LoadedTransaction{badTx},
+		)
+		assert.ErrorContains(t, err, nodeErr.Error(), name)
+	}
+
+	// Except when the request is cancelled
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	mockClient.On("ParseOps", badTx).Return(nil, context.Canceled).Once()
+	_, err = servicer.populateTransactions(
+		cancelledCtx,
+		&RosettaTypes.BlockIdentifier{Hash: blockHash.Hex()},
+		EthTypes.NewBlockWithHeader(&EthTypes.Header{}),
+		[]*client.LoadedTransaction{badTx},
+	)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	mockClient.AssertExpectations(t)
+}
+
+// expvarValue returns the value of the counter key of stats
+func expvarValue(stats *expvar.Map, key string) int64 {
+	if v, ok := stats.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+type revertDataError struct {
+	data string
+}
+
+func (e *revertDataError) Error() string          { return "execution reverted" }
+func (e *revertDataError) ErrorData() interface{} { return e.data }
+
+func TestPopulateTransaction_RevertReason(t *testing.T) {
+	// Error(string) with reason "insufficient balance"
+	errorOutput := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"696e73756666696369656e742062616c616e6365000000000000000000000000"
+	// Panic(uint256) with an arithmetic overflow code
+	panicOutput := "0x4e487b71" +
+		"0000000000000000000000000000000000000000000000000000000000000011"
+
+	blockHash := common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae")
+	txHash := common.HexToHash(hsh)
+	from := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	blockNumber := "0x10"
+
+	tests := map[string]struct {
+		extract  bool
+		output   string
+		callErr  error
+		expected interface{}
+	}{
+		"disabled": {
+			output: errorOutput,
+		},
+		"trace output": {
+			extract:  true,
+			output:   errorOutput,
+			expected: "insufficient balance",
+		},
+		"eth_call": {
+			extract:  true,
+			callErr:  &revertDataError{data: panicOutput},
+			expected: "arithmetic underflow or overflow",
+		},
+		"eth_call without revert data": {
+			extract: true,
+			callErr: errors.New("missing trie node"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode: configuration.ModeOnline,
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+			ctx := context.Background()
+
+			tx := &client.LoadedTransaction{
+				Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+				From:        &from,
+				BlockNumber: &blockNumber,
+				BlockHash:   &blockHash,
+				TxHash:      &txHash,
+			}
+			if test.callErr == nil {
+				tx.Trace = []*client.FlatCall{
+					{Revert: true, Output: common.FromHex(test.output)},
+				}
+			}
+			failedOp := &RosettaTypes.Operation{
+				Type:   AssetTypes.CallOpType,
+				Status: RosettaTypes.String(AssetTypes.FailureStatus),
+			}
+			successOp := &RosettaTypes.Operation{
+				Type:   AssetTypes.FeeOpType,
+				Status: RosettaTypes.String(AssetTypes.SuccessStatus),
+			}
+
+			mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{successOp, failedOp}, nil).Once()
+			mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{
+				ExtractRevertReasons: test.extract,
+			})
+			if test.callErr != nil {
+				mockClient.On(
+					"CallContext",
+					ctx,
+					mock.Anything,
+					"eth_call",
+					mock.Anything,
+					"0xf",
+				).Return(
+					test.callErr,
+				).Once()
+			}
+
+			transaction, err := servicer.PopulateTransaction(ctx, tx)
+			assert.NoError(t, err)
+			assert.Len(t, transaction.Operations, 2)
+			assert.Equal(t, test.expected, transaction.Operations[1].Metadata[RevertReasonMetadataKey])
+			assert.NotContains(t, transaction.Operations[0].Metadata, RevertReasonMetadataKey)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPopulateTransaction_Extensions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockHash := common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae")
+	txHash := common.HexToHash(hsh)
+	from := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	blockNumber := "0x10"
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		From:        &from,
+		BlockNumber: &blockNumber,
+		BlockHash:   &blockHash,
+		TxHash:      &txHash,
+		Extensions:  map[string]interface{}{"queueOrigin": "sequencer"},
+	}
+
+	mockClient.On("ParseOps", tx).Return(
+		func(tx *client.LoadedTransaction) []*RosettaTypes.Operation {
+			tx.SetExtension("l1Fee", "0x7")
+			return []*RosettaTypes.Operation{}
+		},
+		nil,
+	).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+	transaction, err := servicer.PopulateTransaction(ctx, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"queueOrigin": "sequencer",
+		"l1Fee":       "0x7",
+	}, transaction.Metadata[ExtensionsMetadataKey])
+
+	tx.Extensions = nil
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	transaction, err = servicer.PopulateTransaction(ctx, tx)
+	assert.NoError(t, err)
+	assert.NotContains(t, transaction.Metadata, ExtensionsMetadataKey)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockMetadata(t *testing.T) {
+	assert.Nil(t, blockMetadata(EthTypes.NewBlockWithHeader(&EthTypes.Header{}), &client.RPCBlock{}, nil))
+
+	root := common.HexToHash("0xf1a2")
+	blobGasUsed := uint64(131072)
+	excessBlobGas := uint64(0)
+	block := EthTypes.NewBlockWithHeader(&EthTypes.Header{
+		ParentBeaconRoot: &root,
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+	})
+	assert.Equal(t, map[string]interface{}{
+		ParentBeaconBlockRootMetadataKey: root.Hex(),
+		BlobGasUsedMetadataKey:           "0x20000",
+		ExcessBlobGasMetadataKey:         "0x0",
+	}, blockMetadata(block, nil, nil))
+
+	size := hexutil.Uint64(1234)
+	block = EthTypes.NewBlockWithHeader(&EthTypes.Header{
+		GasUsed:   21000,
+		GasLimit:  30000000,
+		BaseFee:   big.NewInt(7),
+		Extra:     []byte{0x01, 0x02},
+		MixDigest: common.HexToHash("0xabcd"),
+	})
+	assert.Equal(t, map[string]interface{}{
+		configuration.GasUsedBlockMetadataField:   "0x5208",
+		configuration.GasLimitBlockMetadataField:  "0x1c9c380",
+		configuration.BaseFeeBlockMetadataField:   "0x7",
+		configuration.SizeBlockMetadataField:      "0x4d2",
+		configuration.ExtraDataBlockMetadataField: "0x0102",
+		configuration.MixHashBlockMetadataField:   common.HexToHash("0xabcd").Hex(),
+	}, blockMetadata(block, &client.RPCBlock{Size: &size}, []string{
+		configuration.GasUsedBlockMetadataField,
+		configuration.GasLimitBlockMetadataField,
+		configuration.BaseFeeBlockMetadataField,
+		configuration.SizeBlockMetadataField,
+		configuration.ExtraDataBlockMetadataField,
+		configuration.MixHashBlockMetadataField,
+	}))
+
+	// The size is computed without the node's, and blocks without base fee
+	// don't have one
+	block = EthTypes.NewBlockWithHeader(&EthTypes.Header{})
+	assert.Equal(t, map[string]interface{}{
+		configuration.SizeBlockMetadataField: hexutil.EncodeUint64(block.Size()),
+	}, blockMetadata(block, nil, []string{
+		configuration.BaseFeeBlockMetadataField,
+		configuration.SizeBlockMetadataField,
+	}))
+}
+
+func TestWithIstanbulMetadata(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x0a"), common.HexToAddress("0x0b")}
+	extra, err := rlp.EncodeToBytes([]interface{}{
+		make([]byte, 32),
+		validators,
+		rlp.RawValue(rlp.EmptyList),
+		uint32(2),
+		[][]byte{},
+	})
+	assert.NoError(t, err)
+	header := &EthTypes.Header{Number: big.NewInt(10), Coinbase: validators[1], Extra: extra}
+
+	metadata, err := withIstanbulMetadata(nil, configuration.QBFTConsensusEngine, header)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		ProposerMetadataKey:   validators[1].Hex(),
+		ValidatorsMetadataKey: []string{validators[0].Hex(), validators[1].Hex()},
+		RoundMetadataKey:      "0x2",
+	}, metadata)
+
+	_, err = withIstanbulMetadata(nil, configuration.IstanbulConsensusEngine, header)
+	assert.Error(t, err)
+}
+
+// headerHasherClient hashes headers by their number
+type headerHasherClient struct {
+	*mockedServices.Client
+}
+
+func (c *headerHasherClient) HashHeader(header *EthTypes.Header, _ map[string]json.RawMessage) (common.Hash, error) {
+	return common.BigToHash(header.Number), nil
+}
+
+func TestBlockHash(t *testing.T) {
+	block := EthTypes.NewBlockWithHeader(&EthTypes.Header{Number: big.NewInt(10)})
+
+	servicer := NewBlockAPIService(&configuration.Configuration{}, &mockedServices.Client{})
+	hash, err := servicer.blockHash(block, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash(), hash)
+
+	servicer = NewBlockAPIService(&configuration.Configuration{}, &headerHasherClient{&mockedServices.Client{}})
+	hash, err = servicer.blockHash(block, &client.RPCBlock{})
+	assert.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(10)), hash)
+}
+
+// headerMapperClient maps the l1BlockNumber header field into the mix digest
+type headerMapperClient struct {
+	*mockedServices.Client
+}
+
+func (c *headerMapperClient) MapHeader(extraFields map[string]json.RawMessage, header *EthTypes.Header) error {
+	var l1BlockNumber string
+	if err := json.Unmarshal(extraFields["l1BlockNumber"], &l1BlockNumber); err != nil {
+		return err
+	}
+	header.MixDigest = common.HexToHash(l1BlockNumber)
+	return nil
+}
+
+func TestDecodeHeader_Tolerant(t *testing.T) {
+	raw := json.RawMessage(`{"number":"0xa","l1BlockNumber":"0x12a05f2"}`)
+
+	strict := NewBlockAPIService(&configuration.Configuration{}, &mockedServices.Client{})
+	_, _, err := strict.decodeHeader(raw)
+	assert.Error(t, err)
+
+	tolerant := NewBlockAPIService(&configuration.Configuration{
+		RosettaCfg: configuration.RosettaConfig{TolerantHeaderDecoding: true},
+	}, &headerMapperClient{&mockedServices.Client{}})
+	head, extraFields, err := tolerant.decodeHeader(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), head.Number.Int64())
+	assert.Equal(t, common.HexToHash("0x12a05f2"), head.MixDigest)
+	assert.Contains(t, extraFields, "l1BlockNumber")
+
+	metadata := blockMetadata(EthTypes.NewBlockWithHeader(head), &client.RPCBlock{HeaderExtraFields: extraFields}, nil)
+	assert.Equal(t, extraFields, metadata[HeaderExtraFieldsMetadataKey])
+}
+
+func TestValidateBlock_Policy(t *testing.T) {
+	block := EthTypes.NewBlockWithHeader(&EthTypes.Header{Number: big.NewInt(1), Difficulty: new(big.Int)})
+	rpcBlock := &client.RPCBlock{Hash: common.HexToHash("0x1")}
+
+	for policy, fails := range map[string]bool{
+		"":                                 false,
+		configuration.WarnValidationPolicy: false,
+		configuration.FailValidationPolicy: true,
+	} {
+		cfg := &configuration.Configuration{
+			Mode: configuration.ModeOnline,
+			RosettaCfg: configuration.RosettaConfig{
+				EnableTrustlessBlockValidation: true,
+				TrustlessValidationPolicy:      policy,
+			},
+		}
+		servicer := NewBlockAPIService(cfg, &mockedServices.Client{})
+
+		err := servicer.validateBlock(context.Background(), block, rpcBlock, nil)
+		if fails {
+			assert.ErrorIs(t, err, validator.ErrBlockHashMismatch)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+
+	servicer := NewBlockAPIService(&configuration.Configuration{Mode: configuration.ModeOnline}, &mockedServices.Client{})
+	assert.NoError(t, servicer.validateBlock(context.Background(), block, rpcBlock, nil))
+}
+
+func TestAddTransactionProof(t *testing.T) {
+	ctx := context.Background()
+	to := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	txs := EthTypes.Transactions{
+		EthTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil),
+		EthTypes.NewTransaction(1, to, big.NewInt(2), 21000, big.NewInt(1), nil),
+	}
+	block := EthTypes.NewBlock(
+		&EthTypes.Header{Number: big.NewInt(10), Difficulty: new(big.Int)},
+		txs,
+		nil,
+		nil,
+		trie.NewStackTrie(nil),
+	)
+
+	var fields map[string]interface{}
+	rawHeader, err := json.Marshal(block.Header())
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(rawHeader, &fields))
+	fields["transactions"] = txs
+	rawBlock, err := json.Marshal(fields)
+	assert.NoError(t, err)
+
+	mockClient := &mockedServices.Client{}
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByHash", block.Hash(), true).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = rawBlock
+		},
+	).Once()
+
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{EnableTrustlessBlockValidation: true},
+	}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	transaction := &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: txs[1].Hash().Hex()},
+	}
+	err = servicer.addTransactionProof(
+		ctx,
+		&RosettaTypes.BlockIdentifier{Index: 10, Hash: block.Hash().Hex()},
+		transaction,
+	)
+	assert.NoError(t, err)
+
+	proof, ok := transaction.Metadata[TransactionProofMetadataKey].(*validator.TransactionProof)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), proof.Index)
+	tx, err := validator.VerifyTransactionProof(proof)
+	assert.NoError(t, err)
+	assert.Equal(t, txs[1].Hash(), tx.Hash())
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetCurrencyFromNodeOrCache_TokenDecimalsPolicy(t *testing.T) {
+	address := common.HexToAddress("0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b")
+	whiteList := []configuration.Token{{Address: address.Hex(), Symbol: "USDC", Decimals: 18}}
+
+	tests := map[string]struct {
+		policy        string
+		expectedError string
+	}{
+		"no policy": {},
+		"fail": {
+			policy:        configuration.FailTokenDecimalsPolicy,
+			expectedError: "token USDC " + address.Hex() + " has 18 decimals in the white list but 6 decimals on chain",
+		},
+		"correct": {
+			policy: configuration.CorrectTokenDecimalsPolicy,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode:       configuration.ModeOnline,
+				RosettaCfg: configuration.RosettaConfig{TokenDecimalsPolicy: test.policy},
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+
+			mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{TokenWhiteList: whiteList}).Maybe()
+			mockClient.On("GetContractCurrency", address, true).Return(
+				&client.ContractCurrency{Symbol: "USDC", Decimals: 6},
+				nil,
+			).Once()
+
+			currency, err := servicer.getCurrencyFromNodeOrCache(context.Background(), address, address.Hex())
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &client.ContractCurrency{Symbol: "USDC", Decimals: 6}, currency)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// filteringClient is a client dropping the transactions sent by system and
+// waiving the fees of the transactions to system
+type filteringClient struct {
+	*mockedServices.Client
+	system common.Address
+}
+
+func (c *filteringClient) FilterTransaction(
+	ctx context.Context,
+	tx *client.LoadedTransaction,
+) (client.TransactionFilterAction, error) {
+	if tx.From != nil && *tx.From == c.system {
+		return client.DropTransaction, nil
+	}
+	if to := tx.Transaction.To(); to != nil && *to == c.system {
+		tx.FeeAmount = big.NewInt(0)
+		tx.FeeBurned = nil
+		tx.SetExtension("system_transaction", true)
+	}
+	return client.KeepTransaction, nil
+}
+
+func TestFilterTransactions(t *testing.T) {
+	system := common.HexToAddress("0xffffFFFfFFffffffffffffffFfFFFfffFFFfFFfE")
+	user := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	newTx := func(from, to common.Address) *client.LoadedTransaction {
+		hash := common.BytesToHash(from.Bytes())
+		return &client.LoadedTransaction{
+			Transaction: EthTypes.NewTransaction(0, to, big.NewInt(0), 21000, big.NewInt(1), nil),
+			From:        &from,
+			TxHash:      &hash,
+			FeeAmount:   big.NewInt(21000),
+		}
+	}
+	systemTx := newTx(system, user)
+	rewardTx := newTx(user, system)
+	userTx := newTx(user, user)
+
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	servicer := NewBlockAPIService(cfg, &filteringClient{Client: &mockedServices.Client{}, system: system})
+	kept, err := servicer.filterTransactions(context.Background(), []*client.LoadedTransaction{systemTx, rewardTx, userTx})
+	assert.NoError(t, err)
+	assert.Equal(t, []*client.LoadedTransaction{rewardTx, userTx}, kept)
+	assert.Equal(t, big.NewInt(0), rewardTx.FeeAmount)
+	assert.Equal(t, map[string]interface{}{"system_transaction": true}, rewardTx.Extensions)
+	assert.Equal(t, big.NewInt(21000), userTx.FeeAmount)
+
+	// Clients without a filter keep every transaction
+	servicer = NewBlockAPIService(cfg, &mockedServices.Client{})
+	kept, err = servicer.filterTransactions(context.Background(), []*client.LoadedTransaction{systemTx, userTx})
+	assert.NoError(t, err)
+	assert.Equal(t, []*client.LoadedTransaction{systemTx, userTx}, kept)
+
+	// The chain profile provides a filter, unless the client has one
+	bscCfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{ChainProfile: configuration.BSCChainProfile},
+	}
+	servicer = NewBlockAPIService(bscCfg, &mockedServices.Client{})
+	assert.Equal(t, client.BSCTransactionFilter{}, servicer.transactionFilter)
+	servicer = NewBlockAPIService(bscCfg, &filteringClient{Client: &mockedServices.Client{}, system: system})
+	assert.IsType(t, &filteringClient{}, servicer.transactionFilter)
+}
+
+func TestPopulateTransaction_AddressPolicies(t *testing.T) {
+	skippedLogs := common.HexToAddress("0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b")
+	skippedTraces := common.HexToAddress("0x0d2b2fb39b10cd50cab7aa8e834879069ab1a8d4")
+	renamed := common.HexToAddress("0x4dc8f417d4eb731d179a0f08b1feaf25216cefd0")
+	cfg := &configuration.Configuration{
+		Mode: configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{
+			AddressPolicies: []configuration.AddressPolicy{
+				{Address: skippedLogs.Hex(), SkipLogs: true},
+				{Address: skippedTraces.Hex(), SkipTraces: true},
+				{Address: renamed.Hex(), OperationTypes: map[string]string{AssetTypes.CallOpType: "SYSTEM_CALL"}},
+			},
+		},
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+
+	txHash := common.HexToHash(hsh)
+	transferEvent := common.HexToHash(client.Erc20LogTopicMap[client.Erc20TransferLogTopic])
+	keptCall := &client.FlatCall{From: renamed, To: renamed}
+	skippedCall := &client.FlatCall{From: renamed, To: skippedTraces}
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		TxHash:      &txHash,
+		Trace:       []*client.FlatCall{keptCall, skippedCall},
+		Receipt: &client.RosettaTxReceipt{
+			Logs: []*EthTypes.Log{
+				{
+					Address: skippedLogs,
+					Topics: []common.Hash{
+						transferEvent,
+						common.BytesToHash(renamed.Bytes()),
+						common.BytesToHash(skippedTraces.Bytes()),
+					},
+					Data: common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+				},
+			},
+		},
+	}
+
+	mockClient.On("ParseOps", mock.MatchedBy(func(parsed *client.LoadedTransaction) bool {
+		return len(parsed.Trace) == 1 && parsed.Trace[0] == keptCall
+	})).Return([]*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                AssetTypes.CallOpType,
+			Account:             &RosettaTypes.AccountIdentifier{Address: renamed.Hex()},
+		},
+	}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("SkipTxReceiptParsing", skippedLogs.String()).Return(false).Once()
+
+	transaction, err := servicer.PopulateTransaction(context.Background(), tx)
+	assert.NoError(t, err)
+	assert.Len(t, transaction.Operations, 1)
+	assert.Equal(t, "SYSTEM_CALL", transaction.Operations[0].Type)
+	// The skipped traces are still part of the metadata
+	assert.Len(t, transaction.Metadata["trace"], 2)
+	assert.Len(t, tx.Trace, 2)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestPopulateTransaction_ERC721Logs(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+
+	txHash := common.HexToHash(hsh)
+	nft := common.HexToAddress("0x06012c8cf97BEaD5deAe237070F9587f8E7A266d")
+	tx := &client.LoadedTransaction{
+		Transaction: EthTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		TxHash:      &txHash,
+		Receipt: &client.RosettaTxReceipt{
+			Logs: []*EthTypes.Log{
+				{
+					Address: nft,
+					Topics: []common.Hash{
+						common.HexToHash(client.Erc20LogTopicMap[client.Erc20TransferLogTopic]),
+						common.HexToHash("0x0000000000000000000000004dc8f417d4eb731d179a0f08b1feaf25216cefd0"),
+						common.HexToHash("0x0000000000000000000000000d2b2fb39b10cd50cab7aa8e834879069ab1a8d4"),
+					},
+					Data: common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+				},
+			},
+		},
+	}
+
+	mockClient.On("ParseOps", tx).Return([]*RosettaTypes.Operation{}, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("SkipTxReceiptParsing", nft.String()).Return(false).Once()
+	mockClient.On("GetContractCurrency", nft, true).Return(
+		&client.ContractCurrency{Symbol: "CK", Standard: client.ERC721TokenStandard},
+		nil,
+	).Once()
+
+	transaction, err := servicer.PopulateTransaction(context.Background(), tx)
+	assert.NoError(t, err)
+	assert.Empty(t, transaction.Operations)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlock_ConcurrentFetches(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "latest", true).Return(nil).Run(
+		func(args mock.Arguments) {
+			file, err := os.ReadFile("testdata/block_10992.json")
+			assert.NoError(t, err)
+			*args.Get(1).(*json.RawMessage) = file
+		},
+	).Once()
+
+	// Traces and receipts each wait for the other fetch to start, which only
+	// succeeds when they are fetched concurrently
+	tracesStarted := make(chan struct{})
+	receiptsStarted := make(chan struct{})
+	waitFor := func(started chan struct{}) {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Error("fetches are not concurrent")
+		}
+	}
+	txs := make([]client.RPCTransaction, 0)
+	mockClient.On("TraceBlockByHash", ctx, mock.Anything, txs).Return(nil, nil).Run(
+		func(mock.Arguments) {
+			close(tracesStarted)
+			waitFor(receiptsStarted)
+		},
+	).Once()
+	var baseFee *big.Int
+	mockClient.On("GetBlockReceipts", ctx, mock.Anything, txs, baseFee).Return(nil, nil).Run(
+		func(mock.Arguments) {
+			close(receiptsStarted)
+			waitFor(tracesStarted)
+		},
+	).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("GetBlockHash", ctx, mock.Anything).Return(
+		"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+		nil,
+	).Once()
+	mockClient.On("PopulateCrossChainTransactions", mock.Anything, mock.Anything).
+		Return([]*RosettaTypes.Transaction{}, nil).Once()
+
+	_, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{})
+	assert.Nil(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockTransaction_MatchesBlock(t *testing.T) {
+	ctx := context.Background()
+	blockHash := "0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50"
+	feeCurrency := common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+
+	// ParseOps pays the fee of the transaction in its fee currency
+	feeOps := func(tx *client.LoadedTransaction) ([]*RosettaTypes.Operation, error) {
+		currency := AssetTypes.Currency
+		if tx.FeeCurrency != nil {
+			currency = tx.FeeCurrency
+		}
+		return []*RosettaTypes.Operation{{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                AssetTypes.FeeOpType,
+			Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: tx.From.Hex()},
+			Amount:              client.Amount(new(big.Int).Neg(tx.FeeAmount), currency),
+		}}, nil
+	}
+
+	tests := map[string]struct {
+		parseOps interface{}
+	}{
+		"fee currency": {
+			parseOps: feeOps,
+		},
+		"parse error": {
+			parseOps: func(*client.LoadedTransaction) ([]*RosettaTypes.Operation, error) {
+				return nil, errors.New("unsupported trace")
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode: configuration.ModeOnline,
+				RosettaCfg: configuration.RosettaConfig{
+					TransactionParsePolicy: configuration.LenientParsePolicy,
+				},
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+
+			// The transaction of block 10994 as a CIP-64 transaction
+			mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByHash", blockHash, true).Return(nil).Run(
+				func(args mock.Arguments) {
+					file, err := os.ReadFile("testdata/block_10994.json")
+					assert.NoError(t, err)
+					var fields map[string]interface{}
+					assert.NoError(t, json.Unmarshal(file, &fields))
+					fields["transactions"].([]interface{})[0].(map[string]interface{})["feeCurrency"] = feeCurrency.Hex()
+					raw, err := json.Marshal(fields)
+					assert.NoError(t, err)
+					*args.Get(1).(*json.RawMessage) = raw
+				},
+			).Once()
+			trace := []*client.FlatCall{{
+				Type:    "call",
+				From:    common.HexToAddress("0x1234"),
+				To:      common.HexToAddress("0x4566"),
+				Value:   big.NewInt(0),
+				GasUsed: big.NewInt(10000),
+			}}
+			receipt := &client.RosettaTxReceipt{TransactionFee: big.NewInt(10000), GasUsed: big.NewInt(10000)}
+			mockClient.On("TraceBlockByHash", ctx, mock.Anything, mock.Anything).
+				Return(map[string][]*client.FlatCall{hsh: trace}, nil).Once()
+			var baseFee *big.Int
+			mockClient.On("GetBlockReceipts", ctx, mock.Anything, mock.Anything, baseFee).
+				Return([]*client.RosettaTxReceipt{receipt}, nil).Once()
+			mockClient.On("GetBlockHash", ctx, mock.Anything).Return(blockHash, nil).Once()
+			mockClient.On("PopulateCrossChainTransactions", mock.Anything, mock.Anything).
+				Return([]*RosettaTypes.Transaction{}, nil).Once()
+			mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+			mockClient.On("GetContractCurrency", feeCurrency, true).
+				Return(&client.ContractCurrency{Symbol: "cUSD", Decimals: 18}, nil).Once()
+			var loaded *client.LoadedTransaction
+			mockClient.On("ParseOps", mock.Anything).Return(test.parseOps, nil).Run(func(args mock.Arguments) {
+				if loaded == nil {
+					loaded = args.Get(0).(*client.LoadedTransaction)
+				}
+			}).Twice()
+
+			blockResp, blockErr := servicer.Block(ctx, &RosettaTypes.BlockRequest{
+				BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Hash: &blockHash},
+			})
+			assert.Nil(t, blockErr)
+			assert.Len(t, blockResp.Block.Transactions, 1)
+
+			// The same transaction, as loaded by the client
+			request := &RosettaTypes.BlockTransactionRequest{
+				BlockIdentifier:       blockResp.Block.BlockIdentifier,
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: hsh},
+			}
+			mockClient.On("GetLoadedTransaction", ctx, request).Return(&client.LoadedTransaction{
+				Transaction: loaded.Transaction,
+				From:        loaded.From,
+				BlockNumber: loaded.BlockNumber,
+				BlockHash:   loaded.BlockHash,
+				TxHash:      loaded.TxHash,
+				Miner:       loaded.Miner,
+				Status:      loaded.Status,
+				Extensions:  loaded.Extensions,
+			}, nil).Once()
+			mockClient.On("TraceTransaction", ctx, common.HexToHash(hsh)).Return(nil, trace, nil).Once()
+			mockClient.On("GetTransactionReceipt", ctx, mock.Anything).Return(receipt, nil).Once()
+
+			txResp, txErr := servicer.BlockTransaction(ctx, request)
+			assert.Nil(t, txErr)
+			assert.Equal(t, blockResp.Block.Transactions[0], txResp.Transaction)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlock_ParseErrorsNotCached(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{BlockCacheSize: 1},
+	}
+	mockClient := &mockedServices.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+	hash := "0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"
+
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByHash", hash, true).Return(nil).Run(
+		func(args mock.Arguments) {
+			file, err := os.ReadFile("testdata/block_10992.json")
+			assert.NoError(t, err)
+			*args.Get(1).(*json.RawMessage) = file
+		},
+	).Twice()
+	txs := make([]client.RPCTransaction, 0)
+	mockClient.On("TraceBlockByHash", ctx, mock.Anything, txs).Return(nil, nil).Twice()
+	var baseFee *big.Int
+	mockClient.On("GetBlockReceipts", ctx, mock.Anything, txs, baseFee).Return(nil, nil).Twice()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("GetBlockHash", ctx, mock.Anything).Return(hash, nil).Twice()
+	txHash := common.HexToHash("0x01")
+	mockClient.On("PopulateCrossChainTransactions", mock.Anything, mock.Anything).Return(
+		[]*RosettaTypes.Transaction{parseErrorTransaction(
+			&client.LoadedTransaction{TxHash: &txHash},
+			errors.New("unsupported trace"),
+		)},
+		nil,
+	).Twice()
+
+	// Both requests are served by the node
+	for i := 0; i < 2; i++ {
+		resp, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{
+			BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Hash: &hash},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, BlockCacheMiss, resp.Block.Metadata[BlockCacheMetadataKey])
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlock_Pruned(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		index         int64
+		expectedError *RosettaTypes.Error
+	}{
+		"before the oldest block": {
+			index:         3,
+			expectedError: AssetTypes.ErrBlockPruned,
+		},
+		"after the oldest block": {
+			index:         7,
+			expectedError: AssetTypes.ErrGeth,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, &prunedClient{
+				Client:      mockClient,
+				oldestBlock: &RosettaTypes.BlockIdentifier{Index: 5, Hash: "block 5"},
+			})
+			mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", client.ToBlockNumArg(big.NewInt(test.index)), true).
+				Return(nil).Once()
+
+			resp, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{
+				BlockIdentifier: &RosettaTypes.PartialBlockIdentifier{Index: &test.index},
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, test.expectedError.Code, err.Code)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// atomicClient adds an atomic import to the blocks with block extra data
+type atomicClient struct {
+	*mockedServices.Client
+	*client.AvalancheAtomicTransactions
+}
+
+func TestBlock_AtomicTransactions(t *testing.T) {
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	mockClient := &mockedServices.Client{}
+	recipient := common.HexToAddress("0x8db97c7cece249c2b98bdc0226cc4c2a57bf52fc")
+	servicer := NewBlockAPIService(cfg, &atomicClient{
+		Client: mockClient,
+		AvalancheAtomicTransactions: &client.AvalancheAtomicTransactions{
+			Decode: func(blockExtraData []byte) ([]*client.AtomicTransaction, error) {
+				assert.Equal(t, []byte{0x01}, blockExtraData)
+				return []*client.AtomicTransaction{{
+					ID:      "2ZfWNPnbKJyLvQk6DTkSCwSCCgQhWs7mJfNoHNzKZbQcCVJcbP",
+					Outputs: []client.AtomicTransfer{{Address: recipient, Amount: 5, AssetID: "AVAX"}},
+				}}, nil
+			},
+			AVAXAssetID: "AVAX",
+			Currency:    AssetTypes.Currency,
+		},
+	})
+	ctx := context.Background()
+
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "latest", true).Return(nil).Run(
+		func(args mock.Arguments) {
+			file, err := os.ReadFile("testdata/block_10992.json")
+			assert.NoError(t, err)
+			var fields map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(file, &fields))
+			fields["blockExtraData"] = json.RawMessage(`"0x01"`)
+			raw, err := json.Marshal(fields)
+			assert.NoError(t, err)
+			*args.Get(1).(*json.RawMessage) = raw
+		},
+	).Once()
+	txs := make([]client.RPCTransaction, 0)
+	mockClient.On("TraceBlockByHash", ctx, mock.Anything, txs).Return(nil, nil).Once()
+	var baseFee *big.Int
+	mockClient.On("GetBlockReceipts", ctx, mock.Anything, txs, baseFee).Return(nil, nil).Once()
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("GetBlockHash", ctx, mock.Anything).Return(
+		"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+		nil,
+	).Once()
+	mockClient.On("PopulateCrossChainTransactions", mock.Anything, mock.Anything).
+		Return([]*RosettaTypes.Transaction{}, nil).Once()
+
+	resp, err := servicer.Block(ctx, &RosettaTypes.BlockRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []*RosettaTypes.Transaction{{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: "2ZfWNPnbKJyLvQk6DTkSCwSCCgQhWs7mJfNoHNzKZbQcCVJcbP",
+		},
+		Operations: []*RosettaTypes.Operation{{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                AssetTypes.AtomicImportOpType,
+			Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: recipient.Hex()},
+			Amount:              &RosettaTypes.Amount{Value: "5000000000", Currency: AssetTypes.Currency},
+		}},
+	}}, resp.Block.Transactions)
+	mockClient.AssertExpectations(t)
+}
+
+// whiteListClient is a client maintaining its token white list
+type whiteListClient struct {
+	*mockedServices.Client
+	tokens []configuration.Token
+	err    error
+}
+
+func (c *whiteListClient) TokenWhiteList(ctx context.Context) ([]configuration.Token, error) {
+	return c.tokens, c.err
+}
+
+func TestTokenWhiteList(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configuration.Configuration{Mode: configuration.ModeOnline}
+	configured := []configuration.Token{{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC"}}
+	remote := []configuration.Token{{Address: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Symbol: "DAI"}}
+
+	servicer := NewBlockAPIService(cfg, &mockedServices.Client{})
+	tokens, err := servicer.tokenWhiteList(ctx, configured)
+	assert.NoError(t, err)
+	assert.Equal(t, configured, tokens)
+
+	accessor := &whiteListClient{Client: &mockedServices.Client{}, tokens: remote}
+	servicer = NewBlockAPIService(cfg, accessor)
+	tokens, err = servicer.tokenWhiteList(ctx, configured)
+	assert.NoError(t, err)
+	assert.Equal(t, remote, tokens)
+
+	accessor.err = client.ErrTokenWhiteListUnavailable
+	_, err = servicer.tokenWhiteList(ctx, configured)
+	assert.ErrorIs(t, err, client.ErrTokenWhiteListUnavailable)
+}
@@ -0,0 +1,318 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// CallParamsKey is the /call parameter holding the JSON RPC parameters of the
+	// methods passed through to the node
+	CallParamsKey = "params"
+
+	// CallAddressKey is the /call parameter holding the sender address of
+	// AssetTypes.GetPendingTransactionsMethod
+	CallAddressKey = "address"
+
+	// CallUnsignedTransactionKey is the /call parameter holding the unsigned
+	// transaction of AssetTypes.SignAndSubmitMethod
+	CallUnsignedTransactionKey = "unsigned_transaction"
+)
+
+// CallAPIService implements the server.CallAPIServicer interface.
+type CallAPIService struct {
+	config *configuration.Configuration
+	client construction.Client
+
+	// signer and constructionService serve AssetTypes.SignAndSubmitMethod,
+	// with server side signing
+	signer              signer.Signer
+	constructionService *construction.APIService
+}
+
+// NewCallAPIService creates a new instance of a CallAPIService.
+func NewCallAPIService(cfg *configuration.Configuration, client construction.Client) *CallAPIService {
+	return &CallAPIService{
+		config: cfg,
+		client: client,
+	}
+}
+
+// NewCallAPIServiceWithSigner creates a CallAPIService serving server side
+// signing. serverSigner signs the transactions of AssetTypes.SignAndSubmitMethod,
+// which are built and submitted with constructionService, see LoadServerSigner.
+func NewCallAPIServiceWithSigner(
+	cfg *configuration.Configuration,
+	client construction.Client,
+	serverSigner signer.Signer,
+	constructionService *construction.APIService,
+) *CallAPIService {
+	service := NewCallAPIService(cfg, client)
+	service.signer = serverSigner
+	service.constructionService = constructionService
+
+	return service
+}
+
+// LoadServerSigner returns the signer of the client if it implements
+// signer.Provider, or the signer of the keystore of the configuration
+func LoadServerSigner(
+	ctx context.Context,
+	cfg *configuration.Configuration,
+	c construction.Client,
+) (signer.Signer, error) {
+	if provider, ok := c.(signer.Provider); ok {
+		serverSigner, err := provider.ServerSigner(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the server signer: %w", err)
+		}
+		return serverSigner, nil
+	}
+
+	if len(cfg.RosettaCfg.SignerKeystorePath) == 0 {
+		return nil, errors.New("server side signing requires a signer keystore or a client implementing signer.Provider")
+	}
+	passphrase := os.Getenv(cfg.RosettaCfg.SignerKeystorePassphraseEnv)
+	return signer.NewKeystoreSigner(cfg.RosettaCfg.SignerKeystorePath, passphrase)
+}
+
+// Call implements the /call endpoint. AssetTypes.GetRawBlockMethod returns the
+// RLP encoding of the block identified by the parameters, a partial block
+// identifier. AssetTypes.GetPendingTransactionsMethod returns the transaction
+// pool transactions of the sender in CallAddressKey, and
+// AssetTypes.AccountNonceMethod its latest and pending nonces. The other call
+// methods are passed through to the node with the parameters in CallParamsKey,
+// and their result is returned in "result". With server side signing,
+// AssetTypes.SignAndSubmitMethod signs and submits the unsigned transaction in
+// CallUnsignedTransactionKey. With slow call logging,
+// AssetTypes.GetSlowCallsMethod returns the slowest calls to the node. The
+// asserter only allows the call methods of the network options.
+func (s *CallAPIService) Call(
+	ctx context.Context,
+	request *types.CallRequest,
+) (*types.CallResponse, *types.Error) {
+	if s.config.IsOfflineMode() {
+		return nil, AssetTypes.ErrUnavailableOffline
+	}
+
+	if request.Method == AssetTypes.GetRawBlockMethod {
+		return s.getRawBlock(ctx, request.Parameters)
+	}
+	if request.Method == AssetTypes.GetPendingTransactionsMethod {
+		return s.getPendingTransactions(ctx, request.Parameters)
+	}
+	if request.Method == AssetTypes.AccountNonceMethod {
+		return s.getAccountNonce(ctx, request.Parameters)
+	}
+	if request.Method == AssetTypes.SignAndSubmitMethod {
+		return s.signAndSubmit(ctx, request.Parameters)
+	}
+	if request.Method == AssetTypes.GetSlowCallsMethod {
+		return s.getSlowCalls()
+	}
+
+	var params []interface{}
+	if v, ok := request.Parameters[CallParamsKey]; ok {
+		if params, ok = v.([]interface{}); !ok {
+			return nil, AssetTypes.WrapErr(AssetTypes.ErrCallParametersInvalid, fmt.Errorf("%s is not a list", CallParamsKey))
+		}
+	}
+
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, request.Method, params...); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+	var decoded interface{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &decoded); err != nil {
+			return nil, AssetTypes.WrapErr(AssetTypes.ErrCallOutputMarshal, err)
+		}
+	}
+
+	return &types.CallResponse{
+		Result: map[string]interface{}{"result": decoded},
+	}, nil
+}
+
+// getRawBlock implements AssetTypes.GetRawBlockMethod. Blocks requested by
+// hash never change, so their responses are idempotent.
+func (s *CallAPIService) getRawBlock(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	var blockIdentifier types.PartialBlockIdentifier
+	if err := client.UnmarshalJSONMap(parameters, &blockIdentifier); err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrCallParametersInvalid, err)
+	}
+
+	rawBlock, err := s.client.GetRawBlock(ctx, &blockIdentifier)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	result, err := client.MarshalJSONMap(rawBlock)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrCallOutputMarshal, err)
+	}
+
+	return &types.CallResponse{
+		Result:     result,
+		Idempotent: blockIdentifier.Hash != nil,
+	}, nil
+}
+
+// getPendingTransactions implements AssetTypes.GetPendingTransactionsMethod,
+// for diagnosing stuck transactions of a sender
+func (s *CallAPIService) getPendingTransactions(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	address, rosettaErr := callAddress(parameters)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	txs, err := s.client.GetPendingTransactionsForSender(ctx, address)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	result, err := client.MarshalJSONMap(txs)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrCallOutputMarshal, err)
+	}
+
+	return &types.CallResponse{
+		Result: result,
+	}, nil
+}
+
+// getAccountNonce implements AssetTypes.AccountNonceMethod, for selecting the
+// nonce of a transaction before constructing it. Its result can be passed to
+// /construction/preprocess, see construction.AccountNonceMetadataKey.
+func (s *CallAPIService) getAccountNonce(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	address, rosettaErr := callAddress(parameters)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	var nonce *client.AccountNonce
+	var err error
+	if provider, ok := s.client.(client.AccountNonceProvider); ok {
+		nonce, err = provider.GetAccountNonce(ctx, address)
+	} else {
+		nonce, err = client.FetchAccountNonce(ctx, s.client.BatchCallContext, address)
+	}
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrGeth, err)
+	}
+
+	result, err := client.MarshalJSONMap(nonce)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrCallOutputMarshal, err)
+	}
+
+	return &types.CallResponse{
+		Result: result,
+	}, nil
+}
+
+// callAddress returns the address of the CallAddressKey parameter
+func callAddress(parameters map[string]interface{}) (common.Address, *types.Error) {
+	address, ok := parameters[CallAddressKey].(string)
+	if ok {
+		normalized, err := client.NormalizeAddress(address)
+		address, ok = normalized, err == nil
+	}
+	if !ok || !common.IsHexAddress(address) {
+		return common.Address{}, AssetTypes.WrapErr(
+			AssetTypes.ErrCallParametersInvalid,
+			fmt.Errorf("%s is not a valid address", CallAddressKey),
+		)
+	}
+
+	return common.HexToAddress(address), nil
+}
+
+// getSlowCalls implements AssetTypes.GetSlowCallsMethod, for capacity planning
+// of the node
+func (s *CallAPIService) getSlowCalls() (*types.CallResponse, *types.Error) {
+	reporter, ok := s.client.(client.SlowCallReporter)
+	if !ok || !s.config.RosettaCfg.SlowCallLoggingEnabled() {
+		return nil, AssetTypes.WrapErr(
+			AssetTypes.ErrCallMethodInvalid,
+			errors.New("slow call logging is not enabled"),
+		)
+	}
+
+	return &types.CallResponse{
+		Result: map[string]interface{}{"slow_calls": reporter.SlowCalls()},
+	}, nil
+}
+
+// signAndSubmit implements AssetTypes.SignAndSubmitMethod, returning the
+// identifier of the submitted transaction. It has no authentication: any
+// caller reaching /call spends the funds of the server signer, so servers
+// with server side signing must never be exposed publicly.
+func (s *CallAPIService) signAndSubmit(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (*types.CallResponse, *types.Error) {
+	if s.signer == nil {
+		return nil, AssetTypes.WrapErr(
+			AssetTypes.ErrCallMethodInvalid,
+			fmt.Errorf("%s requires server side signing", AssetTypes.SignAndSubmitMethod),
+		)
+	}
+
+	unsignedTransaction, ok := parameters[CallUnsignedTransactionKey].(string)
+	if !ok {
+		return nil, AssetTypes.WrapErr(
+			AssetTypes.ErrCallParametersInvalid,
+			fmt.Errorf("%s is not a string", CallUnsignedTransactionKey),
+		)
+	}
+
+	submitted, rosettaErr := s.constructionService.SignAndSubmit(ctx, s.signer, unsignedTransaction)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	result, err := client.MarshalJSONMap(submitted)
+	if err != nil {
+		return nil, AssetTypes.WrapErr(AssetTypes.ErrCallOutputMarshal, err)
+	}
+
+	return &types.CallResponse{
+		Result: result,
+	}, nil
+}
@@ -0,0 +1,301 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// slowCallClient is a client reporting slow calls
+type slowCallClient struct {
+	*mockedServices.Client
+	slowCalls []*client.SlowCall
+}
+
+func (c *slowCallClient) SlowCalls() []*client.SlowCall {
+	return c.slowCalls
+}
+
+// accountNonceClient is a client implementing client.AccountNonceProvider
+type accountNonceClient struct {
+	*mockedServices.Client
+	nonce *client.AccountNonce
+}
+
+func (c *accountNonceClient) GetAccountNonce(context.Context, common.Address) (*client.AccountNonce, error) {
+	return c.nonce, nil
+}
+
+// signerClient is a client implementing signer.Provider
+type signerClient struct {
+	*mockedServices.Client
+	signer signer.Signer
+}
+
+func (c *signerClient) ServerSigner(context.Context) (signer.Signer, error) {
+	return c.signer, nil
+}
+
+func TestCall(t *testing.T) {
+	ctx := context.Background()
+	hash := "0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"
+	rawBlock := &client.RawBlock{
+		Hash:   common.HexToHash(hash),
+		Header: []byte{0xc0},
+		Block:  []byte{0xc1, 0xc0},
+	}
+
+	sender := common.HexToAddress("0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1")
+	poolTxs := &client.SenderPoolTransactions{
+		Address:      sender.Hex(),
+		AccountNonce: 3,
+		Pending:      []*client.PoolTransaction{},
+		Queued: []*client.PoolTransaction{
+			{Hash: common.HexToHash("0x05"), Nonce: 5, Gas: 21000, Value: (*hexutil.Big)(common.Big1)},
+		},
+		NonceGaps: []uint64{3, 4},
+	}
+
+	tests := map[string]struct {
+		request          *types.CallRequest
+		mocks            func(*mockedServices.Client)
+		expectedResponse *types.CallResponse
+		expectedError    *types.Error
+	}{
+		"raw block by hash": {
+			request: &types.CallRequest{
+				Method:     AssetTypes.GetRawBlockMethod,
+				Parameters: map[string]interface{}{"hash": hash},
+			},
+			mocks: func(mockClient *mockedServices.Client) {
+				mockClient.On("GetRawBlock", ctx, &types.PartialBlockIdentifier{Hash: &hash}).Return(rawBlock, nil).Once()
+			},
+			expectedResponse: &types.CallResponse{
+				Result: map[string]interface{}{
+					"hash":   hash,
+					"header": "0xc0",
+					"block":  "0xc1c0",
+				},
+				Idempotent: true,
+			},
+		},
+		"raw block with invalid parameters": {
+			request: &types.CallRequest{
+				Method:     AssetTypes.GetRawBlockMethod,
+				Parameters: map[string]interface{}{"index": "ten"},
+			},
+			expectedError: AssetTypes.ErrCallParametersInvalid,
+		},
+		"pending transactions": {
+			request: &types.CallRequest{
+				Method:     AssetTypes.GetPendingTransactionsMethod,
+				Parameters: map[string]interface{}{CallAddressKey: sender.Hex()},
+			},
+			mocks: func(mockClient *mockedServices.Client) {
+				mockClient.On("GetPendingTransactionsForSender", ctx, sender).Return(poolTxs, nil).Once()
+			},
+			expectedResponse: &types.CallResponse{
+				Result: map[string]interface{}{
+					"address":       sender.Hex(),
+					"account_nonce": float64(3),
+					"pending":       []interface{}{},
+					"queued": []interface{}{
+						map[string]interface{}{
+							"hash":  common.HexToHash("0x05").Hex(),
+							"nonce": "0x5",
+							"to":    nil,
+							"value": "0x1",
+							"gas":   "0x5208",
+						},
+					},
+					"nonce_gaps": []interface{}{float64(3), float64(4)},
+				},
+			},
+		},
+		"pending transactions with invalid address": {
+			request: &types.CallRequest{
+				Method:     AssetTypes.GetPendingTransactionsMethod,
+				Parameters: map[string]interface{}{CallAddressKey: "0x123"},
+			},
+			expectedError: AssetTypes.ErrCallParametersInvalid,
+		},
+		"account nonce": {
+			request: &types.CallRequest{
+				Method:     AssetTypes.AccountNonceMethod,
+				Parameters: map[string]interface{}{CallAddressKey: strings.ToLower(sender.Hex())},
+			},
+			mocks: func(mockClient *mockedServices.Client) {
+				// Clients without an AccountNonceProvider are served from the node
+				mockClient.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					reqs := args.Get(1).([]rpc.BatchElem)
+					*reqs[0].Result.(*hexutil.Uint64) = 3
+					*reqs[1].Result.(*hexutil.Uint64) = 4
+					reqs[2].Error = errors.New("the method txpool_contentFrom does not exist/is not available")
+				}).Once()
+			},
+			expectedResponse: &types.CallResponse{
+				Result: map[string]interface{}{
+					"address":   sender.Hex(),
+					"latest":    float64(3),
+					"pending":   float64(4),
+					"in_flight": float64(1),
+				},
+			},
+		},
+		"account nonce with invalid address": {
+			request: &types.CallRequest{
+				Method: AssetTypes.AccountNonceMethod,
+			},
+			expectedError: AssetTypes.ErrCallParametersInvalid,
+		},
+		"passthrough": {
+			request: &types.CallRequest{
+				Method: "eth_estimateGas",
+				Parameters: map[string]interface{}{
+					CallParamsKey: []interface{}{map[string]interface{}{"to": "0x01"}},
+				},
+			},
+			mocks: func(mockClient *mockedServices.Client) {
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_estimateGas", map[string]interface{}{"to": "0x01"}).
+					Return(nil).
+					Run(func(args mock.Arguments) {
+						*args.Get(1).(*json.RawMessage) = json.RawMessage(`"0x5208"`)
+					}).Once()
+			},
+			expectedResponse: &types.CallResponse{
+				Result: map[string]interface{}{"result": "0x5208"},
+			},
+		},
+		"passthrough with invalid parameters": {
+			request: &types.CallRequest{
+				Method:     "eth_estimateGas",
+				Parameters: map[string]interface{}{CallParamsKey: "0x01"},
+			},
+			expectedError: AssetTypes.ErrCallParametersInvalid,
+		},
+		"slow calls without slow call logging": {
+			request: &types.CallRequest{
+				Method: AssetTypes.GetSlowCallsMethod,
+			},
+			expectedError: AssetTypes.ErrCallMethodInvalid,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			if test.mocks != nil {
+				test.mocks(mockClient)
+			}
+			servicer := NewCallAPIService(&configuration.Configuration{Mode: configuration.ModeOnline}, mockClient)
+
+			resp, err := servicer.Call(ctx, test.request)
+			if test.expectedError != nil {
+				assert.Nil(t, resp)
+				assert.Equal(t, test.expectedError.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.expectedResponse, resp)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+
+	t.Run("slow calls", func(t *testing.T) {
+		slowCalls := []*client.SlowCall{{Method: "debug_traceBlockByHash", Duration: "3s"}}
+		servicer := NewCallAPIService(&configuration.Configuration{
+			Mode:       configuration.ModeOnline,
+			RosettaCfg: configuration.RosettaConfig{SlowCallThreshold: time.Second},
+		}, &slowCallClient{Client: &mockedServices.Client{}, slowCalls: slowCalls})
+		resp, err := servicer.Call(ctx, &types.CallRequest{Method: AssetTypes.GetSlowCallsMethod})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.CallResponse{
+			Result: map[string]interface{}{"slow_calls": slowCalls},
+		}, resp)
+	})
+
+	t.Run("account nonce provider", func(t *testing.T) {
+		nonce := &client.AccountNonce{
+			Address:      sender.Hex(),
+			Latest:       3,
+			Pending:      3,
+			QueuedNonces: []uint64{5},
+			NonceGaps:    []uint64{3, 4},
+		}
+		servicer := NewCallAPIService(
+			&configuration.Configuration{Mode: configuration.ModeOnline},
+			&accountNonceClient{Client: &mockedServices.Client{}, nonce: nonce},
+		)
+		resp, err := servicer.Call(ctx, &types.CallRequest{
+			Method:     AssetTypes.AccountNonceMethod,
+			Parameters: map[string]interface{}{CallAddressKey: sender.Hex()},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.CallResponse{
+			Result: map[string]interface{}{
+				"address":       sender.Hex(),
+				"latest":        float64(3),
+				"pending":       float64(3),
+				"in_flight":     float64(0),
+				"queued_nonces": []interface{}{float64(5)},
+				"nonce_gaps":    []interface{}{float64(3), float64(4)},
+			},
+		}, resp)
+	})
+
+	t.Run("unavailable in offline mode", func(t *testing.T) {
+		servicer := NewCallAPIService(&configuration.Configuration{Mode: configuration.ModeOffline}, &mockedServices.Client{})
+		resp, err := servicer.Call(ctx, &types.CallRequest{Method: AssetTypes.GetRawBlockMethod})
+		assert.Nil(t, resp)
+		assert.Equal(t, AssetTypes.ErrUnavailableOffline.Code, err.Code)
+	})
+}
+
+func TestLoadServerSigner(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{ServerSideSigning: true},
+	}
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	keySigner := signer.NewKeySigner(key)
+	serverSigner, err := LoadServerSigner(ctx, cfg, &signerClient{Client: &mockedServices.Client{}, signer: keySigner})
+	assert.NoError(t, err)
+	assert.Equal(t, keySigner, serverSigner)
+
+	_, err = LoadServerSigner(ctx, cfg, &mockedServices.Client{})
+	assert.EqualError(t, err, "server side signing requires a signer keystore or a client implementing signer.Provider")
+}
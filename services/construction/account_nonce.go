@@ -0,0 +1,45 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+)
+
+// AccountNonceMetadataKey is the /construction/preprocess metadata key of the
+// result of the account_nonce /call method. Its pending nonce is the nonce of
+// the transaction, instead of fetching the nonce of the sender from the node.
+const AccountNonceMetadataKey = "account_nonce"
+
+// accountNonce returns the nonce of input: its nonce when it is set, the
+// pending nonce of its account nonce, or nil to fetch it from the node
+func accountNonce(input client.Options) (*big.Int, error) {
+	if input.Nonce != nil || input.AccountNonce == nil {
+		return input.Nonce, nil
+	}
+	if !strings.EqualFold(input.AccountNonce.Address, input.From) {
+		return nil, fmt.Errorf(
+			"account nonce of %s does not match the sender %s",
+			input.AccountNonce.Address,
+			input.From,
+		)
+	}
+
+	return new(big.Int).SetUint64(input.AccountNonce.Pending), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountNonce(t *testing.T) {
+	pending := &client.AccountNonce{Address: strings.ToLower(testingFromAddress), Latest: 3, Pending: 5}
+
+	tests := map[string]struct {
+		input         client.Options
+		expectedNonce *big.Int
+		expectedError string
+	}{
+		"node nonce": {
+			input: client.Options{From: testingFromAddress},
+		},
+		"explicit nonce": {
+			input:         client.Options{From: testingFromAddress, Nonce: big.NewInt(9), AccountNonce: pending},
+			expectedNonce: big.NewInt(9),
+		},
+		"pending nonce": {
+			input:         client.Options{From: testingFromAddress, AccountNonce: pending},
+			expectedNonce: big.NewInt(5),
+		},
+		"other account": {
+			input: client.Options{From: testingToAddress, AccountNonce: pending},
+			expectedError: "account nonce of " + strings.ToLower(testingFromAddress) +
+				" does not match the sender " + testingToAddress,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			nonce, err := accountNonce(test.input)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedNonce, nonce)
+		})
+	}
+}
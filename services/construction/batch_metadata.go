@@ -0,0 +1,400 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// BatchMetadataKey is the /construction/metadata options key of a list of
+	// /construction/preprocess options. Their metadata is fetched with a single
+	// batch of JSON RPC requests and returned in the same order under
+	// BatchMetadataKey.
+	BatchMetadataKey = "batch"
+
+	// BatchErrorMetadataKey is the metadata key of the error of a batch item
+	// whose metadata could not be fetched
+	BatchErrorMetadataKey = "error"
+
+	// BatchSuggestedFeeMetadataKey is the metadata key of the suggested fee of
+	// a batch item
+	BatchSuggestedFeeMetadataKey = "suggested_fee"
+
+	// MaxBatchMetadataSize is the maximum number of items of a batched
+	// metadata request
+	MaxBatchMetadataSize = 1000
+)
+
+// BatchMetadataClient is implemented by clients serving batched metadata.
+// Batched metadata reads the nonces, gas limits and fees with the JSON RPC
+// requests of client.SDKClient instead of calling GetNonce, GetGasPrice, the
+// gas limit methods, GetGasTipCap and GetGasFeeCap, so it is only served by
+// client.SDKClient and by the clients declaring that they don't override them.
+type BatchMetadataClient interface {
+	// SupportsBatchMetadata returns true if the nonces, gas limits and fees of
+	// the client are the ones of the JSON RPC requests of client.SDKClient
+	SupportsBatchMetadata() bool
+}
+
+// batchItem is an item of a batched metadata request, with the indexes of its
+// requests in the batch, -1 when it has no such request
+type batchItem struct {
+	input client.Options
+	err   *types.Error
+
+	nonceRequest    int
+	estimateRequest int
+
+	// nativeTransfer is true when the gas limit is estimated for a native
+	// currency transfer
+	nativeTransfer bool
+}
+
+// batchMetadata implements /construction/metadata for the options of batch,
+// a BatchMetadataKey list. The nonces of the senders, the gas limits, the gas
+// price, the priority fee and the head block are requested in one round trip
+// instead of one per item. Items of the same sender without a nonce get
+// consecutive nonces in batch order. Items that fail have a
+// BatchErrorMetadataKey error instead of failing the batch, and don't take a
+// nonce.
+//
+// Fees follow the SDK formulas of the client. Simulation, intrinsic gas, fee
+// currencies and the L1 data fee of OP stack chains need a request per item
+// and are not supported, and neither are clients overriding the metadata
+// methods, see BatchMetadataClient.
+func (s APIService) batchMetadata(
+	ctx context.Context,
+	batch interface{},
+) (*types.ConstructionMetadataResponse, *types.Error) {
+	rawItems, ok := batch.([]interface{})
+	if !ok || len(rawItems) == 0 {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, fmt.Errorf("%s is not a list of options", BatchMetadataKey))
+	}
+	if len(rawItems) > MaxBatchMetadataSize {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrInvalidInput,
+			fmt.Errorf("batch of %d items exceeds the limit of %d", len(rawItems), MaxBatchMetadataSize),
+		)
+	}
+	if !s.supportsBatchMetadata() {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrInvalidInput,
+			errors.New("batched metadata is not supported by the client, whose metadata methods may be overridden"),
+		)
+	}
+	rosettaConfig := s.client.GetRosettaConfig()
+	if rosettaConfig.SupportsOpStack {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrInvalidInput,
+			errors.New("batched metadata does not support the L1 data fee of OP stack chains"),
+		)
+	}
+
+	var (
+		gasPrice             hexutil.Big
+		maxPriorityFeePerGas hexutil.Big
+		head                 *feeModeHeader
+	)
+	reqs := []rpc.BatchElem{
+		{Method: "eth_gasPrice", Result: &gasPrice},
+		{Method: "eth_getBlockByNumber", Args: []interface{}{string(rosettaConfig.DefaultBlock()), false}, Result: &head},
+	}
+	gasPriceRequest, headRequest, tipRequest := 0, 1, -1
+
+	items := make([]*batchItem, len(rawItems))
+	senderRequests := map[string]int{}
+	for i, raw := range rawItems {
+		item := s.batchItem(raw)
+		items[i] = item
+		if item.err != nil {
+			continue
+		}
+
+		if item.input.GasLimit == nil || item.input.GasLimit.Uint64() == 0 {
+			callArgs, err := s.batchCallArgs(&item.input)
+			if err != nil {
+				item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+				continue
+			}
+			_, hasData := callArgs["data"]
+			item.nativeTransfer = !hasData
+			item.estimateRequest = len(reqs)
+			reqs = append(reqs, rpc.BatchElem{
+				Method: "eth_estimateGas",
+				Args:   []interface{}{callArgs},
+				Result: new(hexutil.Uint64),
+			})
+		}
+
+		if item.input.Nonce == nil {
+			request, ok := senderRequests[item.input.From]
+			if !ok {
+				request = len(reqs)
+				senderRequests[item.input.From] = request
+				reqs = append(reqs, rpc.BatchElem{
+					Method: "eth_getTransactionCount",
+					Args:   []interface{}{item.input.From, "latest"},
+					Result: new(hexutil.Uint64),
+				})
+			}
+			item.nonceRequest = request
+		}
+
+		if tipRequest < 0 && item.input.GasTipCap == nil && item.input.FeeMode != LegacyFeeMode &&
+			(rosettaConfig.SupportsEIP1559 || rosettaConfig.AutoSelectFeeMode || item.input.FeeMode == DynamicFeeMode) {
+			tipRequest = len(reqs)
+			reqs = append(reqs, rpc.BatchElem{Method: "eth_maxPriorityFeePerGas", Result: &maxPriorityFeePerGas})
+		}
+	}
+
+	if err := s.client.BatchCallContext(ctx, reqs); err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+	}
+
+	fees := &batchFees{head: head, headErr: reqs[headRequest].Error}
+	if reqs[gasPriceRequest].Error == nil {
+		fees.gasPrice = gasPrice.ToInt()
+	}
+	if tipRequest >= 0 && reqs[tipRequest].Error == nil {
+		fees.maxPriorityFeePerGas = maxPriorityFeePerGas.ToInt()
+	}
+	if head != nil && head.BaseFee != nil {
+		fees.baseFee = head.BaseFee.ToInt()
+	}
+
+	// nonceOffsets are the numbers of successful earlier items of each sender,
+	// whose nonces precede the nonce of the next item
+	nonceOffsets := map[string]uint64{}
+	results := make([]interface{}, len(items))
+	totalFee := new(big.Int)
+	for i, item := range items {
+		if item.err == nil {
+			var metadata map[string]interface{}
+			var suggestedFee *big.Int
+			metadata, suggestedFee, item.err = s.batchItemMetadata(item, nonceOffsets[item.input.From], reqs, fees)
+			if item.err == nil {
+				if item.nonceRequest >= 0 {
+					nonceOffsets[item.input.From]++
+				}
+				totalFee.Add(totalFee, suggestedFee)
+				results[i] = metadata
+				continue
+			}
+		}
+		results[i] = map[string]interface{}{BatchErrorMetadataKey: item.err}
+	}
+
+	metadata := map[string]interface{}{BatchMetadataKey: results}
+	fee := client.Amount(totalFee, rosettaConfig.FeeCurrency())
+	s.addReferenceFee(ctx, metadata, fee)
+
+	return &types.ConstructionMetadataResponse{
+		Metadata:     metadata,
+		SuggestedFee: []*types.Amount{fee},
+	}, nil
+}
+
+// supportsBatchMetadata returns true if the client is client.SDKClient or
+// supports batched metadata, see BatchMetadataClient
+func (s APIService) supportsBatchMetadata() bool {
+	if _, ok := s.client.(*client.SDKClient); ok {
+		return true
+	}
+	batchClient, ok := s.client.(BatchMetadataClient)
+	return ok && batchClient.SupportsBatchMetadata()
+}
+
+// batchFees are the fees of the node shared by the items of a batch. A fee is
+// nil when its request failed.
+type batchFees struct {
+	gasPrice             *big.Int
+	maxPriorityFeePerGas *big.Int
+	baseFee              *big.Int
+	head                 *feeModeHeader
+	headErr              error
+}
+
+// batchItem returns the item of the options raw, with an error when they are
+// invalid or need a request of their own
+func (s APIService) batchItem(raw interface{}) *batchItem {
+	item := &batchItem{nonceRequest: -1, estimateRequest: -1}
+	options, ok := raw.(map[string]interface{})
+	if !ok {
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, fmt.Errorf("%v is not an options object", raw))
+		return item
+	}
+	if err := client.UnmarshalJSONMap(options, &item.input); err != nil {
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		return item
+	}
+	if _, item.err = s.checkMetadataInput(&item.input); item.err != nil {
+		return item
+	}
+
+	switch {
+	case item.input.Simulate:
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("simulation is not supported in a batch"))
+	case item.input.IntrinsicGas:
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("intrinsic gas is not supported in a batch"))
+	case len(item.input.FeeCurrency) > 0:
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("fee currencies are not supported in a batch"))
+	}
+	if item.err != nil {
+		return item
+	}
+
+	nonce, err := accountNonce(item.input)
+	if err != nil {
+		item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		return item
+	}
+	item.input.Nonce = nonce
+
+	if len(item.input.ContractAddress) > 0 && len(item.input.ContractData) == 0 {
+		contractData, err := ConstructContractCallDataGeneric(item.input.MethodSignature, item.input.MethodArgs)
+		if err != nil {
+			item.err = sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+			return item
+		}
+		item.input.ContractData = hexutil.Encode(contractData)
+	}
+
+	return item
+}
+
+// batchCallArgs returns the eth_estimateGas arguments of the transaction
+// described by input
+func (s APIService) batchCallArgs(input *client.Options) (map[string]interface{}, error) {
+	to, value, data, err := s.transactionCall(input)
+	if err != nil {
+		return nil, err
+	}
+
+	callArgs := map[string]interface{}{
+		"from":  input.From,
+		"value": (*hexutil.Big)(value),
+	}
+	if len(to) > 0 {
+		callArgs["to"] = to
+	}
+	if len(data) > 0 {
+		callArgs["data"] = hexutil.Bytes(data)
+	}
+
+	return callArgs, nil
+}
+
+// batchItemMetadata returns the metadata and the suggested fee of item from
+// the responses of the batch. Without a nonce, the nonce of item is the nonce
+// of its sender plus nonceOffset.
+func (s APIService) batchItemMetadata(
+	item *batchItem,
+	nonceOffset uint64,
+	reqs []rpc.BatchElem,
+	fees *batchFees,
+) (map[string]interface{}, *big.Int, *types.Error) {
+	input := item.input
+	rosettaConfig := s.client.GetRosettaConfig()
+
+	var nonce uint64
+	if item.nonceRequest >= 0 {
+		if err := reqs[item.nonceRequest].Error; err != nil {
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrNonceError, err)
+		}
+		nonce = uint64(*reqs[item.nonceRequest].Result.(*hexutil.Uint64)) + nonceOffset
+	} else {
+		nonce = input.Nonce.Uint64()
+	}
+
+	var gasLimit uint64
+	if item.estimateRequest >= 0 {
+		if err := reqs[item.estimateRequest].Error; err != nil {
+			if item.nativeTransfer {
+				return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrNativeGasLimitError, err)
+			}
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrERC20GasLimitError, err)
+		}
+		gasLimit = uint64(*reqs[item.estimateRequest].Result.(*hexutil.Uint64))
+	} else {
+		gasLimit = input.GasLimit.Uint64()
+	}
+
+	nodeGasPrice := fees.gasPrice
+	if nodeGasPrice == nil && (input.GasPrice == nil || input.GasPrice.Uint64() == 0) {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrGasPriceError, errors.New("gas price is unknown"))
+	}
+	gasPrice, err := client.SuggestedGasPrice(input, nodeGasPrice)
+	if err != nil {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrGasPriceError, err)
+	}
+
+	var gasTipCap, gasFeeCap, priorityFeeFloor, priorityFeeCap *big.Int
+	dynamicFees, err := s.selectFeeMode(&input, func() (*feeModeHeader, error) {
+		return fees.head, fees.headErr
+	})
+	if err != nil {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrGasFeeCapError, err)
+	}
+	if dynamicFees {
+		priorityFeeFloor, priorityFeeCap = client.PriorityFeeBounds(rosettaConfig, input)
+		gasTipCap, err = client.SuggestedGasTipCap(rosettaConfig, input, fees.maxPriorityFeePerGas)
+		if err != nil {
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrGasTipCapError, err)
+		}
+		if input.GasFeeCap == nil && fees.baseFee == nil {
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrGasFeeCapError, errors.New("base fee is unknown"))
+		}
+		gasFeeCap = client.SuggestedGasFeeCap(rosettaConfig, input, fees.baseFee, gasTipCap)
+	}
+
+	metadata, err := client.MarshalJSONMap(&client.Metadata{
+		Nonce:            nonce,
+		GasPrice:         gasPrice,
+		GasLimit:         gasLimit,
+		GasTipCap:        gasTipCap,
+		GasFeeCap:        gasFeeCap,
+		ContractData:     input.ContractData,
+		MethodSignature:  input.MethodSignature,
+		MethodArgs:       input.MethodArgs,
+		ChainID:          input.ChainID,
+		PriorityFeeFloor: priorityFeeFloor,
+		PriorityFeeCap:   priorityFeeCap,
+		GasCurrency:      s.config.RosettaCfg.GasCurrency,
+		AccessList:       input.AccessList,
+	})
+	if err != nil {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
+	}
+
+	feePerGas := gasPrice
+	if gasFeeCap != nil {
+		feePerGas = effectiveGasPrice(fees.baseFee, gasTipCap, gasFeeCap)
+	}
+	suggestedFee := new(big.Int).Mul(feePerGas, new(big.Int).SetUint64(gasLimit))
+	metadata[BatchSuggestedFeeMetadataKey] = client.Amount(suggestedFee, rosettaConfig.FeeCurrency())
+
+	return metadata, suggestedFee, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// batchMetadataClient is a client supporting batched metadata
+type batchMetadataClient struct {
+	*mockedServices.Client
+}
+
+func (c *batchMetadataClient) SupportsBatchMetadata() bool {
+	return true
+}
+
+func TestBatchMetadata(t *testing.T) {
+	otherSender := "0x2222222222222222222222222222222222222222"
+	reverting := "0x3333333333333333333333333333333333333333"
+	items := []interface{}{
+		map[string]interface{}{"from": testingFromAddress, "to": testingToAddress, "value": "1"},
+		// A failed item doesn't take a nonce
+		map[string]interface{}{"from": testingFromAddress, "to": reverting, "value": "1"},
+		map[string]interface{}{"from": testingFromAddress, "to": testingToAddress, "value": "2"},
+		map[string]interface{}{
+			"from":        otherSender,
+			"to":          testingToAddress,
+			"value":       "3",
+			"nonce":       9,
+			"gas_limit":   30000,
+			"gas_tip_cap": 3,
+		},
+		map[string]interface{}{"from": testingFromAddress, "to": testingToAddress, "value": "1", "simulate": true},
+		map[string]interface{}{"from": testingFromAddress},
+	}
+
+	testingClient := newTestingClient()
+	testingClient.servicer.client = &batchMetadataClient{Client: testingClient.mockClient}
+	testingClient.mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{
+		SupportsEIP1559: true,
+		Currency:        ethereumCurrencyConfig,
+	})
+
+	var methods []string
+	testingClient.mockClient.On("BatchCallContext", mock.Anything, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			for i, req := range args.Get(1).([]rpc.BatchElem) {
+				methods = append(methods, req.Method)
+				switch req.Method {
+				case "eth_gasPrice":
+					*req.Result.(*hexutil.Big) = *(*hexutil.Big)(hexutil.MustDecodeBig("0x64"))
+				case "eth_maxPriorityFeePerGas":
+					*req.Result.(*hexutil.Big) = *(*hexutil.Big)(hexutil.MustDecodeBig("0x2"))
+				case "eth_getBlockByNumber":
+					*req.Result.(**feeModeHeader) = &feeModeHeader{
+						Number:  (*hexutil.Big)(hexutil.MustDecodeBig("0x10")),
+						BaseFee: (*hexutil.Big)(hexutil.MustDecodeBig("0xa")),
+					}
+				case "eth_getTransactionCount":
+					assert.Equal(t, []interface{}{testingFromAddress, "latest"}, req.Args)
+					*req.Result.(*hexutil.Uint64) = 5
+				case "eth_estimateGas":
+					if req.Args[0].(map[string]interface{})["to"] == reverting {
+						args.Get(1).([]rpc.BatchElem)[i].Error = errors.New("execution reverted")
+						continue
+					}
+					*req.Result.(*hexutil.Uint64) = 21000
+				}
+			}
+		},
+	).Once()
+
+	response, rosettaErr := testingClient.servicer.ConstructionMetadata(context.Background(), &types.ConstructionMetadataRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Options:           map[string]interface{}{BatchMetadataKey: items},
+	})
+	assert.Nil(t, rosettaErr)
+
+	// The fees and nonces are requested once, the gas limits once per item
+	assert.Equal(t, []string{
+		"eth_gasPrice",
+		"eth_getBlockByNumber",
+		"eth_estimateGas",
+		"eth_getTransactionCount",
+		"eth_maxPriorityFeePerGas",
+		"eth_estimateGas",
+		"eth_estimateGas",
+	}, methods)
+
+	results := response.Metadata[BatchMetadataKey].([]interface{})
+	assert.Len(t, results, len(items))
+	assert.Equal(t, map[string]interface{}{
+		"nonce":         float64(5),
+		"gas_price":     float64(100),
+		"gas_limit":     float64(21000),
+		"gas_tip_cap":   float64(2),
+		"gas_fee_cap":   float64(12),
+		"suggested_fee": &types.Amount{Value: "252000", Currency: ethereumCurrencyConfig},
+	}, results[0])
+	assert.Equal(t, AssetTypes.ErrNativeGasLimitError.Code, results[1].(map[string]interface{})[BatchErrorMetadataKey].(*types.Error).Code)
+	assert.Equal(t, float64(6), results[2].(map[string]interface{})["nonce"])
+	assert.Equal(t, map[string]interface{}{
+		"nonce":         float64(9),
+		"gas_price":     float64(100),
+		"gas_limit":     float64(30000),
+		"gas_tip_cap":   float64(3),
+		"gas_fee_cap":   float64(13),
+		"suggested_fee": &types.Amount{Value: "390000", Currency: ethereumCurrencyConfig},
+	}, results[3])
+	assert.Equal(t, AssetTypes.ErrInvalidInput.Code, results[4].(map[string]interface{})[BatchErrorMetadataKey].(*types.Error).Code)
+	assert.Equal(t, AssetTypes.ErrInvalidInput.Code, results[5].(map[string]interface{})[BatchErrorMetadataKey].(*types.Error).Code)
+	assert.Equal(t, []*types.Amount{{Value: "894000", Currency: ethereumCurrencyConfig}}, response.SuggestedFee)
+	testingClient.mockClient.AssertExpectations(t)
+}
+
+func TestBatchMetadata_Invalid(t *testing.T) {
+	items := []interface{}{map[string]interface{}{"from": testingFromAddress, "to": testingToAddress}}
+	tests := map[string]struct {
+		batch       interface{}
+		cfg         configuration.RosettaConfig
+		unsupported bool
+	}{
+		"not a list": {
+			batch: map[string]interface{}{"from": testingFromAddress},
+		},
+		"empty": {
+			batch: []interface{}{},
+		},
+		"too large": {
+			batch: make([]interface{}, MaxBatchMetadataSize+1),
+		},
+		"op stack": {
+			batch: items,
+			cfg:   configuration.RosettaConfig{SupportsOpStack: true},
+		},
+		// The client may override GetNonce, GetGasPrice or the gas limits
+		"client without batch support": {
+			batch:       items,
+			unsupported: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			if !test.unsupported {
+				testingClient.servicer.client = &batchMetadataClient{Client: testingClient.mockClient}
+			}
+			testingClient.mockClient.On("GetRosettaConfig").Return(test.cfg).Maybe()
+
+			_, rosettaErr := testingClient.servicer.ConstructionMetadata(context.Background(), &types.ConstructionMetadataRequest{
+				NetworkIdentifier: ethereumNetworkIdentifier,
+				Options:           map[string]interface{}{BatchMetadataKey: test.batch},
+			})
+			assert.Equal(t, AssetTypes.ErrInvalidInput.Code, rosettaErr.Code)
+			testingClient.mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// ChainIDMetadataKey is the /construction/payloads metadata key that
+// overrides the chain id of the constructed transaction. /construction/preprocess
+// only accepts the configured chain id, since the node can't provide the
+// metadata of other chains.
+const ChainIDMetadataKey = "chain_id"
+
+// chainIDAllowed returns whether transactions can be constructed for chainID,
+// which is either the configured chain id or one of RosettaConfig.AllowedChainIDs
+func (s *APIService) chainIDAllowed(chainID *big.Int) bool {
+	if chainID == nil {
+		return false
+	}
+	if chainID.Cmp(s.config.ChainConfig.ChainID) == 0 {
+		return true
+	}
+	for _, allowed := range s.config.RosettaCfg.AllowedChainIDs {
+		if chainID.IsUint64() && chainID.Uint64() == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveChainID returns the chain id of a construction request. Requests
+// without an override are for the configured chain, so their network must be
+// the configured network. Overrides must be allowed chain ids.
+func (s *APIService) resolveChainID(
+	network *types.NetworkIdentifier,
+	override *big.Int,
+) (*big.Int, error) {
+	if override != nil {
+		if !s.chainIDAllowed(override) {
+			return nil, fmt.Errorf("chain id %s is not allowed", override)
+		}
+		return override, nil
+	}
+
+	if network != nil && s.config.Network != nil && types.Hash(network) != types.Hash(s.config.Network) {
+		return nil, fmt.Errorf(
+			"network %s %s does not match the network %s %s of chain id %s",
+			network.Blockchain,
+			network.Network,
+			s.config.Network.Blockchain,
+			s.config.Network.Network,
+			s.config.ChainConfig.ChainID,
+		)
+	}
+
+	return s.config.ChainConfig.ChainID, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveChainID(t *testing.T) {
+	testingClient := newTestingClient()
+	testingClient.cfg.RosettaCfg.AllowedChainIDs = []uint64{5}
+	otherNetwork := &types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Goerli"}
+
+	tests := map[string]struct {
+		network         *types.NetworkIdentifier
+		override        *big.Int
+		expectedChainID *big.Int
+		expectedError   string
+	}{
+		"configured network": {
+			network:         ethereumNetworkIdentifier,
+			expectedChainID: big.NewInt(int64(ethRopstenChainID)),
+		},
+		"no network": {
+			expectedChainID: big.NewInt(int64(ethRopstenChainID)),
+		},
+		"other network": {
+			network:       otherNetwork,
+			expectedError: "network Ethereum Goerli does not match the network Ethereum Ropsten of chain id 3",
+		},
+		"override with configured chain id": {
+			network:         ethereumNetworkIdentifier,
+			override:        big.NewInt(int64(ethRopstenChainID)),
+			expectedChainID: big.NewInt(int64(ethRopstenChainID)),
+		},
+		"override with allowed chain id": {
+			network:         otherNetwork,
+			override:        big.NewInt(5),
+			expectedChainID: big.NewInt(5),
+		},
+		"override with unknown chain id": {
+			network:       ethereumNetworkIdentifier,
+			override:      big.NewInt(1),
+			expectedError: "chain id 1 is not allowed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			chainID, err := testingClient.servicer.resolveChainID(test.network, test.override)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedChainID, chainID)
+			}
+		})
+	}
+}
+
+func TestChainIDOverride(t *testing.T) {
+	testingClient := newTestingClient()
+	testingClient.cfg.RosettaCfg.AllowedChainIDs = []uint64{5}
+	ctx := context.Background()
+	operations := templateOperations(1, ethereumCurrencyConfig, "CALL")
+
+	preprocessResp, rosettaErr := testingClient.servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata:          map[string]interface{}{ChainIDMetadataKey: "3"},
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, float64(3), preprocessResp.Options[ChainIDMetadataKey])
+
+	// The node only provides metadata for its own chain, so allowed chain ids
+	// are only supported by /construction/payloads
+	_, rosettaErr = testingClient.servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata:          map[string]interface{}{ChainIDMetadataKey: "5"},
+	})
+	assert.Equal(
+		t,
+		templateError(AssetTypes.ErrChainIDMismatch, "chain id 5 is only supported by /construction/payloads with caller supplied metadata"),
+		rosettaErr,
+	)
+
+	_, rosettaErr = testingClient.servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata:          map[string]interface{}{ChainIDMetadataKey: "1"},
+	})
+	assert.Equal(t, templateError(AssetTypes.ErrChainIDMismatch, "chain id 1 is not allowed"), rosettaErr)
+
+	payloadsResp, rosettaErr := testingClient.servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata: map[string]interface{}{
+			"nonce":     float64(0),
+			"gas_price": float64(1),
+			"gas_limit": float64(21000),
+			"chain_id":  float64(5),
+		},
+	})
+	assert.Nil(t, rosettaErr)
+	unsignedTx, err := client.UnmarshalUnsignedTransaction([]byte(payloadsResp.UnsignedTransaction))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), unsignedTx.ChainID)
+}
@@ -16,7 +16,9 @@ package construction
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
 
 	"errors"
 
@@ -24,9 +26,14 @@ import (
 	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// secp256k1HalfN is half the order of the secp256k1 curve
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
 // ConstructionCombine implements /construction/combine endpoint.
 //
 // Combine creates a network-specific Transaction from an unsigned Transaction
@@ -43,30 +50,58 @@ func (s *APIService) ConstructionCombine(
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("signature is not provided"))
 	}
 
-	var unsignedTx client.Transaction
-	if err := json.Unmarshal([]byte(req.UnsignedTransaction), &unsignedTx); err != nil {
+	unsignedTx, err := client.UnmarshalUnsignedTransaction([]byte(req.UnsignedTransaction))
+	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	ethUnsignedTx := EthTransaction(&unsignedTx)
+	chainID := unsignedTx.ChainID
+	if !s.chainIDAllowed(chainID) {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignatureChainIDMismatch,
+			fmt.Errorf("transaction chain id %v does not match chain id %v", chainID, s.config.ChainConfig.ChainID),
+		)
+	}
+
+	signature, rosettaErr := normalizeSignature(req.Signatures[0].Bytes, chainID)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	if unsignedTx.FeeCurrency != nil {
+		return s.combineCIP64Transaction(unsignedTx, signature)
+	}
+
+	ethUnsignedTx := EthTransaction(unsignedTx)
 
 	signer := EthTypes.LatestSignerForChainID(unsignedTx.ChainID)
-	signedTx, err := ethUnsignedTx.WithSignature(signer, req.Signatures[0].Bytes)
+	signedTx, err := ethUnsignedTx.WithSignature(signer, signature)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
+	sender, err := EthTypes.Sender(signer, signedTx)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, err)
+	}
+	if !strings.EqualFold(sender.Hex(), unsignedTx.From) {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignerMismatch,
+			fmt.Errorf("recovered signer %s is not the transaction sender %s", sender.Hex(), unsignedTx.From),
+		)
+	}
+
 	signedTxJSON, err := signedTx.MarshalJSON()
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
 	}
 
-	wrappedSignedTx := client.SignedTransactionWrapper{
+	wrappedSignedTx := &client.SignedTransactionWrapper{
 		SignedTransaction: signedTxJSON,
 		Currency:          unsignedTx.Currency,
 	}
 
-	wrappedSignedTxJSON, err := json.Marshal(wrappedSignedTx)
+	wrappedSignedTxJSON, err := client.MarshalSignedTransaction(wrappedSignedTx)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
 	}
@@ -75,3 +110,73 @@ func (s *APIService) ConstructionCombine(
 		SignedTransaction: string(wrappedSignedTxJSON),
 	}, nil
 }
+
+// combineCIP64Transaction signs a CIP-64 transaction, which go-ethereum doesn't
+// support. The signed transaction is its raw binary encoding in hex.
+func (s *APIService) combineCIP64Transaction(
+	unsignedTx *client.Transaction,
+	signature []byte,
+) (*types.ConstructionCombineResponse, *types.Error) {
+	rawTx, sender, err := signCIP64Transaction(unsignedTx, signature)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, err)
+	}
+	if !strings.EqualFold(sender.Hex(), unsignedTx.From) {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignerMismatch,
+			fmt.Errorf("recovered signer %s is not the transaction sender %s", sender.Hex(), unsignedTx.From),
+		)
+	}
+
+	return &types.ConstructionCombineResponse{
+		SignedTransaction: hexutil.Encode(rawTx),
+	}, nil
+}
+
+// normalizeSignature validates a 65 byte [R || S || V] signature and returns it
+// with V as the recovery id expected by go-ethereum signers. V may be the
+// recovery id itself, 27/28, or an EIP-155 value, which must encode chainID.
+func normalizeSignature(signature []byte, chainID *big.Int) ([]byte, *types.Error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignatureInvalid,
+			fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(signature)),
+		)
+	}
+
+	v := uint64(signature[crypto.RecoveryIDOffset])
+	switch {
+	case v <= 1:
+	case v == 27 || v == 28: // nolint:gomnd
+		v -= 27
+	case v >= 35: // nolint:gomnd
+		sigChainID := new(big.Int).SetUint64((v - 35) / 2) // nolint:gomnd
+		if sigChainID.Cmp(chainID) != 0 {
+			return nil, sdkTypes.WrapErr(
+				sdkTypes.ErrSignatureChainIDMismatch,
+				fmt.Errorf("signature chain id %v does not match chain id %v", sigChainID, chainID),
+			)
+		}
+		v = (v - 35) % 2 // nolint:gomnd
+	default:
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, fmt.Errorf("invalid recovery id %d", v))
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	sValue := new(big.Int).SetBytes(signature[32:64])
+	if sValue.Cmp(secp256k1HalfN) > 0 {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignatureMalleable,
+			errors.New("signature s value must be in the lower half order"),
+		)
+	}
+	if !crypto.ValidateSignatureValues(byte(v), r, sValue, true) {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, errors.New("invalid signature values"))
+	}
+
+	normalized := make([]byte, crypto.SignatureLength)
+	copy(normalized, signature)
+	normalized[crypto.RecoveryIDOffset] = byte(v)
+
+	return normalized, nil
+}
@@ -17,19 +17,22 @@ package construction
 import (
 	"context"
 	"encoding/json"
+	"math/big"
+	"strings"
 	"testing"
 
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	combineUnsignedRaw = `{"from":"0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1","to":"0xdF7C4fFf31A190E8D46FC9Ba8CdE6aaD8F69Fc76","value":100,"data":"","nonce":0,"gas_price":2000000009,"gas":21000,"chain_id":3,"currency":{"symbol":"ETH","decimals":18}}` // nolint
+	combineUnsignedRaw = `{"from":"0xf1E72D1f1AaB7be78BE74645caBd48Da8334BEdf","to":"0xdF7C4fFf31A190E8D46FC9Ba8CdE6aaD8F69Fc76","value":100,"data":"","nonce":0,"gas_price":2000000009,"gas":21000,"chain_id":3,"currency":{"symbol":"ETH","decimals":18}}` // nolint
 
-	combineSignedRaw = `{"signed_tx":"eyJ0eXBlIjoiMHgwIiwiY2hhaW5JZCI6IjB4MyIsIm5vbmNlIjoiMHgwIiwidG8iOiIweGRmN2M0ZmZmMzFhMTkwZThkNDZmYzliYThjZGU2YWFkOGY2OWZjNzYiLCJnYXMiOiIweDUyMDgiLCJnYXNQcmljZSI6IjB4NzczNTk0MDkiLCJtYXhQcmlvcml0eUZlZVBlckdhcyI6bnVsbCwibWF4RmVlUGVyR2FzIjpudWxsLCJ2YWx1ZSI6IjB4NjQiLCJpbnB1dCI6IjB4IiwidiI6IjB4MmEiLCJyIjoiMHg3YTg2NzAzZGNlMWM0Y2E2NTc0MjZkYmI1OTg5MTEyZTAyODg5ZTk3NzZmMWY0NjFlYmVhYzI3MTVjN2IxOGU1IiwicyI6IjB4MzBkMzVkYzY3Zjk2YzAyOTY5M2U3NGM3OWI3ZWJlN2VmMTUxYzY5OTYwMjgwYTkxOWNkZWUwNzhmODZmZWFjZiIsImhhc2giOiIweDk5YWI2YmE4YTQ5YmVkYWM5MmU0ZThhNDhlNDhlMTc2NWZiZDBkOWU4YzgzZTcxNjExZjQxOTc4ZjM4OWU4MGEifQ==","currency":{"symbol":"ETH","decimals":18}}` // nolint
+	combineSignedRaw = `{"v":2,"signed_tx":"eyJ0eXBlIjoiMHgwIiwiY2hhaW5JZCI6IjB4MyIsIm5vbmNlIjoiMHgwIiwidG8iOiIweGRmN2M0ZmZmMzFhMTkwZThkNDZmYzliYThjZGU2YWFkOGY2OWZjNzYiLCJnYXMiOiIweDUyMDgiLCJnYXNQcmljZSI6IjB4NzczNTk0MDkiLCJtYXhQcmlvcml0eUZlZVBlckdhcyI6bnVsbCwibWF4RmVlUGVyR2FzIjpudWxsLCJ2YWx1ZSI6IjB4NjQiLCJpbnB1dCI6IjB4IiwidiI6IjB4MmEiLCJyIjoiMHg3YTg2NzAzZGNlMWM0Y2E2NTc0MjZkYmI1OTg5MTEyZTAyODg5ZTk3NzZmMWY0NjFlYmVhYzI3MTVjN2IxOGU1IiwicyI6IjB4MzBkMzVkYzY3Zjk2YzAyOTY5M2U3NGM3OWI3ZWJlN2VmMTUxYzY5OTYwMjgwYTkxOWNkZWUwNzhmODZmZWFjZiIsImhhc2giOiIweDk5YWI2YmE4YTQ5YmVkYWM5MmU0ZThhNDhlNDhlMTc2NWZiZDBkOWU4YzgzZTcxNjExZjQxOTc4ZjM4OWU4MGEifQ==","currency":{"symbol":"ETH","decimals":18}}` // nolint
 
-	combineSignaturesRaw = `[{"hex_bytes":"7a86703dce1c4ca657426dbb5989112e02889e9776f1f461ebeac2715c7b18e530d35dc67f96c029693e74c79b7ebe7ef151c69960280a919cdee078f86feacf01","signing_payload":{"address":"0x97158A00a4D227Ec7fe3234B52f21e5608FeE3d1","hex_bytes":"358b2c8be6153484861dac2b3668d6067759c4c427350432a595f7ffe31bfd26","signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"28eb23ef37ff86c8ab7cebaf0a46a792bcfeac32905fd859420b24d0c18e6637c7671c7d0dce2be04fd7c71039851776207410c87baadba7ea7130646c8faab4","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	combineSignaturesRaw = `[{"hex_bytes":"7a86703dce1c4ca657426dbb5989112e02889e9776f1f461ebeac2715c7b18e530d35dc67f96c029693e74c79b7ebe7ef151c69960280a919cdee078f86feacf01","signing_payload":{"address":"0xf1E72D1f1AaB7be78BE74645caBd48Da8334BEdf","hex_bytes":"358b2c8be6153484861dac2b3668d6067759c4c427350432a595f7ffe31bfd26","signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"28eb23ef37ff86c8ab7cebaf0a46a792bcfeac32905fd859420b24d0c18e6637c7671c7d0dce2be04fd7c71039851776207410c87baadba7ea7130646c8faab4","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
 )
 
 func TestConstructionCombine(t *testing.T) {
@@ -38,6 +41,32 @@ func TestConstructionCombine(t *testing.T) {
 	var signatures []*types.Signature
 	_ = json.Unmarshal([]byte(combineSignaturesRaw), &signatures)
 
+	withSignatureBytes := func(sig []byte) []*types.Signature {
+		s := *signatures[0]
+		s.Bytes = sig
+		return []*types.Signature{&s}
+	}
+
+	// the same signature with s = N - s and the recovery id flipped
+	malleableSignature := make([]byte, 65)
+	copy(malleableSignature, signatures[0].Bytes)
+	highS := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(signatures[0].Bytes[32:64]))
+	highS.FillBytes(malleableSignature[32:64])
+	malleableSignature[64] ^= 1
+
+	// the signature with an EIP-155 v value for mainnet
+	mainnetSignature := make([]byte, 65)
+	copy(mainnetSignature, signatures[0].Bytes)
+	mainnetSignature[64] += 37
+
+	// the signature with an EIP-155 v value for the testing chain
+	eip155Signature := make([]byte, 65)
+	copy(eip155Signature, signatures[0].Bytes)
+	eip155Signature[64] += byte(ethRopstenChainID*2 + 35)
+
+	wrongSender := strings.Replace(combineUnsignedRaw, "0xf1E72D1f1AaB7be78BE74645caBd48Da8334BEdf", testingFromAddress, 1)
+	wrongChainID := strings.Replace(combineUnsignedRaw, `"chain_id":3`, `"chain_id":1`, 1)
+
 	tests := map[string]struct {
 		request          *types.ConstructionCombineRequest
 		expectedResponse *types.ConstructionCombineResponse
@@ -49,6 +78,39 @@ func TestConstructionCombine(t *testing.T) {
 				SignedTransaction: combineSignedRaw,
 			},
 		},
+		"happy path: EIP-155 signature": {
+			request: templateConstructCombineRequest(combineUnsignedRaw, withSignatureBytes(eip155Signature)),
+			expectedResponse: &types.ConstructionCombineResponse{
+				SignedTransaction: combineSignedRaw,
+			},
+		},
+		"error: invalid signature length": {
+			request: templateConstructCombineRequest(combineUnsignedRaw, withSignatureBytes(signatures[0].Bytes[:64])),
+			expectedError: templateError(
+				AssetTypes.ErrSignatureInvalid, "signature must be 65 bytes, got 64"),
+		},
+		"error: malleable signature": {
+			request: templateConstructCombineRequest(combineUnsignedRaw, withSignatureBytes(malleableSignature)),
+			expectedError: templateError(
+				AssetTypes.ErrSignatureMalleable, "signature s value must be in the lower half order"),
+		},
+		"error: signature for another chain": {
+			request: templateConstructCombineRequest(combineUnsignedRaw, withSignatureBytes(mainnetSignature)),
+			expectedError: templateError(
+				AssetTypes.ErrSignatureChainIDMismatch, "signature chain id 1 does not match chain id 3"),
+		},
+		"error: transaction for another chain": {
+			request: templateConstructCombineRequest(wrongChainID, signatures),
+			expectedError: templateError(
+				AssetTypes.ErrSignatureChainIDMismatch, "transaction chain id 1 does not match chain id 3"),
+		},
+		"error: signer is not the sender": {
+			request: templateConstructCombineRequest(wrongSender, signatures),
+			expectedError: templateError(
+				AssetTypes.ErrSignerMismatch,
+				"recovered signer 0xf1E72D1f1AaB7be78BE74645caBd48Da8334BEdf is not the transaction sender "+testingFromAddress,
+			),
+		},
 		"error: missing transaction": {
 			request: &types.ConstructionCombineRequest{},
 			expectedError: templateError(
@@ -68,11 +130,8 @@ func TestConstructionCombine(t *testing.T) {
 				test.request,
 			)
 
-			if err != nil {
-				assert.Equal(t, test.expectedError, err)
-			} else {
-				assert.Equal(t, test.expectedResponse, resp)
-			}
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedResponse, resp)
 		})
 	}
 }
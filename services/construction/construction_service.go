@@ -16,7 +16,6 @@ package construction
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -26,6 +25,7 @@ import (
 
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/coinbase/rosetta-geth-sdk/client"
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
@@ -43,6 +43,10 @@ type APIService struct {
 	types  *sdkTypes.Types
 	errors []*types.Error
 	client Client
+
+	// priceOracle prices suggested fees in RosettaConfig.ReferenceCurrency,
+	// nil when no price source is configured
+	priceOracle client.PriceOracle
 }
 
 // NewAPIService creates a new instance of a APIService.
@@ -53,10 +57,11 @@ func NewAPIService(
 	client Client,
 ) *APIService {
 	return &APIService{
-		config: cfg,
-		types:  types,
-		errors: errors,
-		client: client,
+		config:      cfg,
+		types:       types,
+		errors:      errors,
+		client:      client,
+		priceOracle: newPriceOracle(cfg, client),
 	}
 }
 
@@ -64,6 +69,10 @@ func (s *APIService) CreateOperationDescription(
 	operations []*types.Operation,
 	isContractCall bool,
 ) ([]*parser.OperationDescription, error) {
+	if isDeployment(operations) {
+		return s.CreateOperationDescriptionDeployment(), nil
+	}
+
 	if len(operations) != numOfValidOpsForDescription {
 		return nil, errors.New("invalid number of operations")
 	}
@@ -212,19 +221,35 @@ func (s *APIService) ConstructionHash(
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("signed Transaction value is not provided"))
 	}
 
-	var wrappedTx client.SignedTransactionWrapper
-	if err := json.Unmarshal([]byte(req.SignedTransaction), &wrappedTx); err != nil {
+	rawTx, err := signedTransactionBytes(req.SignedTransaction)
+	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	var signedTx EthTypes.Transaction
-	if err := signedTx.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
+	hash, ok, err := s.client.HashOverride(ctx, rawTx)
+	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
+	if !ok {
+		// The hash of legacy and typed transactions is the keccak256 hash
+		// of their canonical encoding
+		hash = crypto.Keccak256Hash(rawTx).Hex()
+	}
+
+	// Transaction types unknown to go-ethereum are hashed without decoding
+	var metadata map[string]interface{}
+	var tx EthTypes.Transaction
+	if err := tx.UnmarshalBinary(rawTx); err == nil {
+		metadata, err = deploymentMetadata(&tx)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+	}
 
 	return &types.TransactionIdentifierResponse{
 		TransactionIdentifier: &types.TransactionIdentifier{
-			Hash: signedTx.Hash().Hex(),
+			Hash: hash,
 		},
+		Metadata: metadata,
 	}, nil
 }
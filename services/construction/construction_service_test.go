@@ -15,13 +15,23 @@
 package construction
 
 import (
+	"context"
+	"errors"
 	"math/big"
+	"testing"
 
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	"github.com/coinbase/rosetta-geth-sdk/mocks/services"
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var (
@@ -146,3 +156,88 @@ func templateError(error *types.Error, context string) *types.Error {
 		},
 	}
 }
+
+func TestConstructionHash(t *testing.T) {
+	combineSignedHash := "0x99ab6ba8a49bedac92e4e8a48e48e1765fbd0d9e8c83e71611f41978f389e80a"
+
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress(testingToAddress)
+	dynamicFeeTx, err := EthTypes.SignNewTx(
+		key,
+		EthTypes.LatestSignerForChainID(big.NewInt(int64(ethRopstenChainID))),
+		&EthTypes.DynamicFeeTx{
+			ChainID:   big.NewInt(int64(ethRopstenChainID)),
+			Nonce:     1,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(100),
+		},
+	)
+	assert.NoError(t, err)
+	dynamicFeeTxBytes, _ := dynamicFeeTx.MarshalBinary()
+
+	// a set code transaction (type 4), which go-ethereum can't decode yet
+	setCodePayload, _ := rlp.EncodeToBytes([]interface{}{uint64(3), uint64(1)})
+	setCodeTxBytes := append([]byte{0x04}, setCodePayload...)
+
+	tests := map[string]struct {
+		signedTx       string
+		override       string
+		overrideErr    error
+		expectedHash   string
+		expectedErrMsg string
+	}{
+		"signed transaction wrapper": {
+			signedTx:     combineSignedRaw,
+			expectedHash: combineSignedHash,
+		},
+		"raw dynamic fee transaction": {
+			signedTx:     hexutil.Encode(dynamicFeeTxBytes),
+			expectedHash: dynamicFeeTx.Hash().Hex(),
+		},
+		"raw set code transaction": {
+			signedTx:     hexutil.Encode(setCodeTxBytes),
+			expectedHash: crypto.Keccak256Hash(setCodeTxBytes).Hex(),
+		},
+		"hash override": {
+			signedTx:     combineSignedRaw,
+			override:     "0x1234",
+			expectedHash: "0x1234",
+		},
+		"hash override error": {
+			signedTx:       combineSignedRaw,
+			overrideErr:    errors.New("boom"),
+			expectedErrMsg: "boom",
+		},
+		"invalid typed transaction": {
+			signedTx:       "0x0401",
+			expectedErrMsg: "invalid payload for transaction type 4",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			testingClient.mockClient.On("HashOverride", mock.Anything, mock.Anything).Return(
+				test.override, test.override != "", test.overrideErr,
+			).Maybe()
+
+			resp, rosettaErr := testingClient.servicer.ConstructionHash(
+				context.Background(),
+				&types.ConstructionHashRequest{
+					NetworkIdentifier: ethereumNetworkIdentifier,
+					SignedTransaction: test.signedTx,
+				},
+			)
+
+			if test.expectedErrMsg != "" {
+				assert.Equal(t, templateError(AssetTypes.ErrInvalidInput, test.expectedErrMsg), rosettaErr)
+				return
+			}
+			assert.Nil(t, rosettaErr)
+			assert.Equal(t, test.expectedHash, resp.TransactionIdentifier.Hash)
+		})
+	}
+}
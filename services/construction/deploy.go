@@ -0,0 +1,108 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"errors"
+	"fmt"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// DeployDataMetadataKey is the /construction/preprocess metadata key of
+	// the init code of a contract deployment
+	DeployDataMetadataKey = "data"
+
+	// ContractAddressMetadataKey is the metadata key of the address of the
+	// contract created by a contract deployment
+	ContractAddressMetadataKey = "contract_address"
+)
+
+// isDeployment returns whether operations describe a contract deployment,
+// i.e. a single DEPLOY operation of the sender
+func isDeployment(operations []*types.Operation) bool {
+	return len(operations) == 1 && operations[0].Type == sdkTypes.DeployOpType
+}
+
+// CreateOperationDescriptionDeployment returns the description of a contract
+// deployment, whose amount is the value sent to the created contract
+func (s *APIService) CreateOperationDescriptionDeployment() []*parser.OperationDescription {
+	deploy := parser.OperationDescription{
+		Type: sdkTypes.DeployOpType,
+		Account: &parser.AccountDescription{
+			Exists: true,
+		},
+		Amount: &parser.AmountDescription{
+			Exists:   true,
+			Sign:     parser.NegativeOrZeroAmountSign,
+			Currency: s.config.RosettaCfg.Currency,
+		},
+	}
+
+	return []*parser.OperationDescription{&deploy}
+}
+
+// deploymentData decodes the init code of a contract deployment
+func deploymentData(metadata map[string]interface{}) ([]byte, error) {
+	v, ok := metadata[DeployDataMetadataKey]
+	if !ok {
+		return nil, errors.New("contract deployment data is not provided")
+	}
+	data, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a valid contract deployment data string", v)
+	}
+	code, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid contract deployment data: %w", data, err)
+	}
+	if len(code) == 0 {
+		return nil, errors.New("contract deployment data is empty")
+	}
+
+	return code, nil
+}
+
+// deploymentMetadata returns the transaction identifier metadata of a signed
+// transaction, which is the address of the created contract for contract
+// deployments and nil otherwise
+func deploymentMetadata(tx *EthTypes.Transaction) (map[string]interface{}, error) {
+	if tx.To() != nil {
+		return nil, nil
+	}
+
+	from, err := EthTypes.Sender(EthTypes.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover transaction sender: %w", err)
+	}
+
+	return map[string]interface{}{
+		ContractAddressMetadataKey: deployedContractAddress(from, tx.Nonce()),
+	}, nil
+}
+
+// deployedContractAddress returns the address of the contract created by the
+// deployment of sender with the given nonce
+func deployedContractAddress(sender common.Address, nonce uint64) string {
+	return crypto.CreateAddress(sender, nonce).Hex()
+}
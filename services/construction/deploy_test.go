@@ -0,0 +1,170 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeploymentFlow(t *testing.T) {
+	testingClient := newTestingClient()
+	servicer := testingClient.servicer
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	initCode := "0x6080604052348015600f57600080fd5b50"
+	expectedContract := crypto.CreateAddress(from, transferNonce).Hex()
+
+	operations := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                AssetTypes.DeployOpType,
+			Account:             &types.AccountIdentifier{Address: from.Hex()},
+			Amount:              &types.Amount{Value: "-5", Currency: ethereumCurrencyConfig},
+		},
+	}
+
+	// Preprocess
+	preprocessResp, rosettaErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata:          map[string]interface{}{DeployDataMetadataKey: initCode},
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, map[string]interface{}{
+		"from":  from.Hex(),
+		"to":    "",
+		"value": "5",
+		"data":  initCode,
+		"currency": map[string]interface{}{
+			"decimals": float64(18),
+			"symbol":   "ETH",
+		},
+	}, preprocessResp.Options)
+
+	// Metadata
+	mockClient := testingClient.mockClient
+	mockClient.On("GetNonce", ctx, mock.Anything).Return(transferNonce, nil)
+	mockClient.On("GetGasPrice", ctx, mock.Anything).Return(big.NewInt(int64(transferGasPrice)), nil)
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+	mockClient.On("GetContractDeploymentGasLimit", ctx, from.Hex(), big.NewInt(5), hexutil.MustDecode(initCode)).
+		Return(transferGasLimitContract, nil)
+	metadataResp, rosettaErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Options:           preprocessResp.Options,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, initCode, metadataResp.Metadata["data"])
+
+	// Payloads
+	payloadsResp, rosettaErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata:          metadataResp.Metadata,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Len(t, payloadsResp.Payloads, 1)
+
+	// Parse unsigned
+	parseResp, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Transaction:       payloadsResp.UnsignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, operations, parseResp.Operations)
+	assert.Equal(t, expectedContract, parseResp.Metadata[ContractAddressMetadataKey])
+
+	// Combine
+	signature, err := crypto.Sign(payloadsResp.Payloads[0].Bytes, key)
+	assert.NoError(t, err)
+	combineResp, rosettaErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   ethereumNetworkIdentifier,
+		UnsignedTransaction: payloadsResp.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				SigningPayload: payloadsResp.Payloads[0],
+				PublicKey:      &types.PublicKey{Bytes: crypto.FromECDSAPub(&key.PublicKey), CurveType: types.Secp256k1},
+				SignatureType:  types.EcdsaRecovery,
+				Bytes:          signature,
+			},
+		},
+	})
+	assert.Nil(t, rosettaErr)
+
+	// Parse signed
+	parseResp, rosettaErr = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Signed:            true,
+		Transaction:       combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, operations, parseResp.Operations)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: from.Hex()}}, parseResp.AccountIdentifierSigners)
+	assert.Equal(t, expectedContract, parseResp.Metadata[ContractAddressMetadataKey])
+
+	// Hash
+	mockClient.On("HashOverride", ctx, mock.Anything).Return("", false, nil)
+	hashResp, rosettaErr := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		SignedTransaction: combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, map[string]interface{}{ContractAddressMetadataKey: expectedContract}, hashResp.Metadata)
+
+	// Submit
+	mockClient.On("Submit", ctx, mock.Anything).Return(nil)
+	submitResp, rosettaErr := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		SignedTransaction: combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, hashResp.TransactionIdentifier, submitResp.TransactionIdentifier)
+	assert.Equal(t, hashResp.Metadata, submitResp.Metadata)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeploymentMissingData(t *testing.T) {
+	testingClient := newTestingClient()
+
+	_, rosettaErr := testingClient.servicer.ConstructionPreprocess(
+		context.Background(),
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: ethereumNetworkIdentifier,
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                AssetTypes.DeployOpType,
+					Account:             &types.AccountIdentifier{Address: testingFromAddress},
+					Amount:              &types.Amount{Value: "0", Currency: ethereumCurrencyConfig},
+				},
+			},
+		},
+	)
+	assert.Equal(t, templateError(AssetTypes.ErrInvalidInput, "contract deployment data is not provided"), rosettaErr)
+}
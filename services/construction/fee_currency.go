@@ -0,0 +1,227 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// FeeCurrencyMetadataKey is the /construction/preprocess metadata key of the
+// ERC20 token the constructed transaction pays its fees in, on chains with
+// fee currencies like Celo. Such transactions are CIP-64 transactions, whose
+// gas_tip_cap and gas_fee_cap are required since they are denominated in the
+// fee currency.
+const FeeCurrencyMetadataKey = "fee_currency"
+
+// cip64Transaction is the RLP payload of a signed CIP-64 transaction
+type cip64Transaction struct {
+	ChainID     *big.Int
+	Nonce       uint64
+	GasTipCap   *big.Int
+	GasFeeCap   *big.Int
+	Gas         uint64
+	To          *common.Address `rlp:"nil"`
+	Value       *big.Int
+	Data        []byte
+	AccessList  EthTypes.AccessList
+	FeeCurrency common.Address
+	V, R, S     *big.Int
+}
+
+// newCIP64Transaction returns the CIP-64 payload of tx, without signature
+func newCIP64Transaction(tx *client.Transaction) (*cip64Transaction, error) {
+	if tx.FeeCurrency == nil {
+		return nil, errors.New("transaction has no fee currency")
+	}
+	if tx.GasTipCap == nil || tx.GasFeeCap == nil {
+		return nil, errors.New("transaction with a fee currency has no gas tip cap or gas fee cap")
+	}
+	if tx.ChainID == nil {
+		return nil, errors.New("transaction with a fee currency has no chain id")
+	}
+
+	var to *common.Address
+	if tx.To != "" {
+		address := common.HexToAddress(tx.To)
+		to = &address
+	}
+	accessList := tx.AccessList
+	if accessList == nil {
+		accessList = EthTypes.AccessList{}
+	}
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return &cip64Transaction{
+		ChainID:     tx.ChainID,
+		Nonce:       tx.Nonce,
+		GasTipCap:   tx.GasTipCap,
+		GasFeeCap:   tx.GasFeeCap,
+		Gas:         tx.GasLimit,
+		To:          to,
+		Value:       value,
+		Data:        tx.Data,
+		AccessList:  accessList,
+		FeeCurrency: *tx.FeeCurrency,
+	}, nil
+}
+
+// sigHash returns the hash signed by the sender of tx
+func (tx *cip64Transaction) sigHash() (common.Hash, error) {
+	payload, err := rlp.EncodeToBytes([]interface{}{
+		tx.ChainID,
+		tx.Nonce,
+		tx.GasTipCap,
+		tx.GasFeeCap,
+		tx.Gas,
+		tx.To,
+		tx.Value,
+		tx.Data,
+		tx.AccessList,
+		tx.FeeCurrency,
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash([]byte{client.CIP64TxType}, payload), nil
+}
+
+// sender recovers the sender of the signed tx
+func (tx *cip64Transaction) sender() (common.Address, error) {
+	if tx.V == nil || tx.R == nil || tx.S == nil || !tx.V.IsUint64() || tx.V.Uint64() > 1 {
+		return common.Address{}, errors.New("invalid signature values")
+	}
+	if !crypto.ValidateSignatureValues(byte(tx.V.Uint64()), tx.R, tx.S, true) {
+		return common.Address{}, errors.New("invalid signature values")
+	}
+	hash, err := tx.sigHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	tx.R.FillBytes(signature[:32])
+	tx.S.FillBytes(signature[32:64])
+	signature[crypto.RecoveryIDOffset] = byte(tx.V.Uint64())
+	publicKey, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// cip64SigningHash returns the hash the sender of the CIP-64 transaction tx signs
+func cip64SigningHash(tx *client.Transaction) (common.Hash, error) {
+	payload, err := newCIP64Transaction(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return payload.sigHash()
+}
+
+// signCIP64Transaction returns the binary encoding of the CIP-64 transaction
+// tx signed with signature, a normalized [R || S || V] signature, and its
+// recovered signer
+func signCIP64Transaction(tx *client.Transaction, signature []byte) ([]byte, common.Address, error) {
+	payload, err := newCIP64Transaction(tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	payload.R = new(big.Int).SetBytes(signature[:32])
+	payload.S = new(big.Int).SetBytes(signature[32:64])
+	payload.V = new(big.Int).SetUint64(uint64(signature[crypto.RecoveryIDOffset]))
+
+	sender, err := payload.sender()
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	encoded, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+
+	return append([]byte{client.CIP64TxType}, encoded...), sender, nil
+}
+
+// isCIP64Transaction returns whether rawTx is the binary encoding of a CIP-64
+// transaction
+func isCIP64Transaction(rawTx []byte) bool {
+	return len(rawTx) > 0 && rawTx[0] == client.CIP64TxType
+}
+
+// rawCIP64Transaction returns the binary encoding of signedTx if it is a raw
+// CIP-64 transaction in hex
+func rawCIP64Transaction(signedTx string) ([]byte, bool) {
+	if !strings.HasPrefix(signedTx, "0x") {
+		return nil, false
+	}
+	rawTx, err := hexutil.Decode(signedTx)
+	if err != nil || !isCIP64Transaction(rawTx) {
+		return nil, false
+	}
+
+	return rawTx, true
+}
+
+// decodeCIP64Transaction decodes the binary encoding of a signed CIP-64
+// transaction
+func decodeCIP64Transaction(rawTx []byte) (*client.Transaction, error) {
+	if !isCIP64Transaction(rawTx) {
+		return nil, errors.New("not a CIP-64 transaction")
+	}
+	var payload cip64Transaction
+	if err := rlp.DecodeBytes(rawTx[1:], &payload); err != nil {
+		return nil, fmt.Errorf("invalid CIP-64 transaction: %w", err)
+	}
+	sender, err := payload.sender()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIP-64 transaction: %w", err)
+	}
+
+	tx := &client.Transaction{
+		From:        sender.Hex(),
+		Value:       payload.Value,
+		Data:        payload.Data,
+		Nonce:       payload.Nonce,
+		GasPrice:    payload.GasFeeCap,
+		GasLimit:    payload.Gas,
+		GasTipCap:   payload.GasTipCap,
+		GasFeeCap:   payload.GasFeeCap,
+		ChainID:     payload.ChainID,
+		AccessList:  payload.AccessList,
+		FeeCurrency: &payload.FeeCurrency,
+	}
+	if payload.To != nil {
+		tx.To = payload.To.Hex()
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testFeeCurrency is the address of cUSD on Celo
+var testFeeCurrency = common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+
+func TestPreprocess_FeeCurrency(t *testing.T) {
+	testingClient := newTestingClient()
+
+	tests := map[string]struct {
+		metadata        map[string]interface{}
+		expectedOptions map[string]interface{}
+		expectedError   *types.Error
+	}{
+		"fee currency": {
+			metadata: map[string]interface{}{
+				FeeCurrencyMetadataKey: "0x765de816845861e75a25fca122bb6898b8b1282a",
+				"gas_tip_cap":          "1000",
+				"gas_fee_cap":          "2000",
+			},
+			expectedOptions: map[string]interface{}{
+				"from":         testingFromAddress,
+				"to":           testingToAddress,
+				"value":        "1",
+				"currency":     map[string]interface{}{"symbol": "ETH", "decimals": float64(18)},
+				"gas_tip_cap":  float64(1000),
+				"gas_fee_cap":  float64(2000),
+				"fee_mode":     DynamicFeeMode,
+				"fee_currency": testFeeCurrency.Hex(),
+			},
+		},
+		"error: invalid fee currency": {
+			metadata: map[string]interface{}{FeeCurrencyMetadataKey: "cUSD"},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "cUSD is not a valid fee currency"),
+		},
+		"error: legacy fees": {
+			metadata: map[string]interface{}{
+				FeeCurrencyMetadataKey: testFeeCurrency.Hex(),
+				FeeModeMetadataKey:     LegacyFeeMode,
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "fee currency requires dynamic fees"),
+		},
+		"error: missing fee caps": {
+			metadata: map[string]interface{}{
+				FeeCurrencyMetadataKey: testFeeCurrency.Hex(),
+				"gas_tip_cap":          "1000",
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput,
+				"fee currency requires gas_tip_cap and gas_fee_cap denominated in the fee currency",
+			),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := testingClient.servicer.ConstructionPreprocess(
+				context.Background(),
+				&types.ConstructionPreprocessRequest{
+					NetworkIdentifier: ethereumNetworkIdentifier,
+					Operations:        templateOperations(1, ethereumCurrencyConfig, "CALL"),
+					Metadata:          test.metadata,
+				},
+			)
+			if test.expectedError != nil {
+				assert.Equal(t, test.expectedError, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedOptions, resp.Options)
+		})
+	}
+}
+
+func TestFeeCurrencyTransaction(t *testing.T) {
+	testingClient := newTestingClient()
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	operations := rosettaOperations(from, testingToAddress, big.NewInt(1), ethereumCurrencyConfig, "CALL")
+
+	payloadsResp, rosettaErr := testingClient.servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Operations:        operations,
+		Metadata: map[string]interface{}{
+			"nonce":        float64(2),
+			"gas_limit":    float64(60000),
+			"gas_tip_cap":  float64(1000),
+			"gas_fee_cap":  float64(2000),
+			"fee_currency": testFeeCurrency.Hex(),
+		},
+	})
+	assert.Nil(t, rosettaErr)
+	unsignedTx, err := client.UnmarshalUnsignedTransaction([]byte(payloadsResp.UnsignedTransaction))
+	assert.NoError(t, err)
+	assert.Equal(t, &testFeeCurrency, unsignedTx.FeeCurrency)
+
+	// The payload of a CIP-64 transaction is not the one of the dynamic fee
+	// transaction it extends
+	withoutFeeCurrency := *unsignedTx
+	withoutFeeCurrency.FeeCurrency = nil
+	dynamicFeePayload := EthTypes.LatestSignerForChainID(unsignedTx.ChainID).Hash(EthTransaction(&withoutFeeCurrency))
+	assert.NotEqual(t, dynamicFeePayload.Bytes(), payloadsResp.Payloads[0].Bytes)
+
+	signature, err := crypto.Sign(payloadsResp.Payloads[0].Bytes, key)
+	assert.NoError(t, err)
+	combineResp, rosettaErr := testingClient.servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   ethereumNetworkIdentifier,
+		UnsignedTransaction: payloadsResp.UnsignedTransaction,
+		Signatures: []*types.Signature{{
+			SigningPayload: payloadsResp.Payloads[0],
+			SignatureType:  types.EcdsaRecovery,
+			Bytes:          signature,
+		}},
+	})
+	assert.Nil(t, rosettaErr)
+	rawTx, err := hexutil.Decode(combineResp.SignedTransaction)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(client.CIP64TxType), rawTx[0])
+
+	parseResp, rosettaErr := testingClient.servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Signed:            true,
+		Transaction:       combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, operations, parseResp.Operations)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: from}}, parseResp.AccountIdentifierSigners)
+	assert.Equal(t, testFeeCurrency.Hex(), parseResp.Metadata["fee_currency"])
+
+	hash := crypto.Keccak256Hash(rawTx)
+	testingClient.mockClient.On("HashOverride", ctx, rawTx).Return("", false, nil).Once()
+	hashResp, rosettaErr := testingClient.servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		SignedTransaction: combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, hash.Hex(), hashResp.TransactionIdentifier.Hash)
+
+	testingClient.mockClient.On(
+		"CallContext", ctx, mock.Anything, "eth_sendRawTransaction", hexutil.Bytes(rawTx),
+	).Return(nil).Once()
+	submitResp, rosettaErr := testingClient.servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		SignedTransaction: combineResp.SignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, hash.Hex(), submitResp.TransactionIdentifier.Hash)
+	testingClient.mockClient.AssertExpectations(t)
+
+	// A signature of another key is rejected
+	otherKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	otherSignature, err := crypto.Sign(payloadsResp.Payloads[0].Bytes, otherKey)
+	assert.NoError(t, err)
+	_, rosettaErr = testingClient.servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   ethereumNetworkIdentifier,
+		UnsignedTransaction: payloadsResp.UnsignedTransaction,
+		Signatures:          []*types.Signature{{SigningPayload: payloadsResp.Payloads[0], Bytes: otherSignature}},
+	})
+	assert.Equal(t, AssetTypes.ErrSignerMismatch.Code, rosettaErr.Code)
+}
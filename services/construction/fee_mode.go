@@ -0,0 +1,89 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// FeeModeMetadataKey is the /construction/preprocess metadata key that
+	// selects the fee mode of the constructed transaction
+	FeeModeMetadataKey = "fee_mode"
+
+	// LegacyFeeMode constructs legacy transactions with a gas price
+	LegacyFeeMode = "legacy"
+
+	// DynamicFeeMode constructs EIP-1559 dynamic fee transactions
+	DynamicFeeMode = "dynamic"
+)
+
+// feeModeHeader is the part of the head block used to select the fee mode
+type feeModeHeader struct {
+	Number  *hexutil.Big `json:"number"`
+	BaseFee *hexutil.Big `json:"baseFeePerGas"`
+}
+
+// dynamicFees returns whether the transaction described by input uses dynamic
+// fees. The fee mode of the request takes precedence. Otherwise, dynamic fees
+// are used when the chain supports EIP-1559, or, when RosettaConfig.AutoSelectFeeMode
+// is set, when London is active at the head block and it has a base fee.
+func (s APIService) dynamicFees(ctx context.Context, input *client.Options) (bool, error) {
+	return s.selectFeeMode(input, func() (*feeModeHeader, error) {
+		var head *feeModeHeader
+		if err := s.client.CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil {
+			return nil, fmt.Errorf("could not get head block: %w", err)
+		}
+		return head, nil
+	})
+}
+
+// selectFeeMode implements dynamicFees with the head block returned by head,
+// which is only called when the fee mode is selected automatically
+func (s APIService) selectFeeMode(input *client.Options, head func() (*feeModeHeader, error)) (bool, error) {
+	switch input.FeeMode {
+	case LegacyFeeMode:
+		return false, nil
+	case DynamicFeeMode:
+		return true, nil
+	case "":
+	default:
+		return false, fmt.Errorf("unsupported fee mode %q", input.FeeMode)
+	}
+
+	rosettaConfig := s.client.GetRosettaConfig()
+	if !rosettaConfig.AutoSelectFeeMode {
+		return rosettaConfig.SupportsEIP1559, nil
+	}
+	if s.config.ChainConfig == nil || s.config.ChainConfig.LondonBlock == nil {
+		return false, nil
+	}
+
+	header, err := head()
+	if err != nil {
+		return false, err
+	}
+	if header == nil || header.Number == nil {
+		return false, errors.New("head block not found")
+	}
+
+	return s.config.ChainConfig.IsLondon(header.Number.ToInt()) && header.BaseFee != nil, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDynamicFees(t *testing.T) {
+	tests := map[string]struct {
+		rosettaConfig configuration.RosettaConfig
+		londonBlock   *big.Int
+		feeMode       string
+		head          string
+		expected      bool
+		expectedError string
+	}{
+		"legacy override": {
+			rosettaConfig: configuration.RosettaConfig{SupportsEIP1559: true},
+			feeMode:       LegacyFeeMode,
+			expected:      false,
+		},
+		"dynamic override": {
+			feeMode:  DynamicFeeMode,
+			expected: true,
+		},
+		"unsupported fee mode": {
+			feeMode:       "blob",
+			expectedError: `unsupported fee mode "blob"`,
+		},
+		"supports EIP-1559": {
+			rosettaConfig: configuration.RosettaConfig{SupportsEIP1559: true},
+			expected:      true,
+		},
+		"does not support EIP-1559": {
+			expected: false,
+		},
+		"auto: no London block": {
+			rosettaConfig: configuration.RosettaConfig{SupportsEIP1559: true, AutoSelectFeeMode: true},
+			expected:      false,
+		},
+		"auto: London active": {
+			rosettaConfig: configuration.RosettaConfig{AutoSelectFeeMode: true},
+			londonBlock:   big.NewInt(10),
+			head:          `{"number":"0xa","baseFeePerGas":"0x7"}`,
+			expected:      true,
+		},
+		"auto: before London": {
+			rosettaConfig: configuration.RosettaConfig{AutoSelectFeeMode: true},
+			londonBlock:   big.NewInt(10),
+			head:          `{"number":"0x9"}`,
+			expected:      false,
+		},
+		"auto: no base fee": {
+			rosettaConfig: configuration.RosettaConfig{AutoSelectFeeMode: true},
+			londonBlock:   big.NewInt(0),
+			head:          `{"number":"0xa"}`,
+			expected:      false,
+		},
+		"auto: head not found": {
+			rosettaConfig: configuration.RosettaConfig{AutoSelectFeeMode: true},
+			londonBlock:   big.NewInt(0),
+			head:          `null`,
+			expectedError: "head block not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			testingClient.cfg.ChainConfig.LondonBlock = test.londonBlock
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+
+			mockClient.On("GetRosettaConfig").Return(test.rosettaConfig).Maybe()
+			if len(test.head) > 0 {
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "latest", false).
+					Return(nil).
+					Run(func(args mock.Arguments) {
+						assert.NoError(t, json.Unmarshal([]byte(test.head), args.Get(1)))
+					}).Once()
+			}
+
+			dynamic, err := testingClient.servicer.dynamicFees(ctx, &client.Options{FeeMode: test.feeMode})
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, dynamic)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
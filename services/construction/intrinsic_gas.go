@@ -0,0 +1,141 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// IntrinsicGasMetadataKey is the /construction/preprocess metadata flag
+	// that adds the IntrinsicGas breakdown to the /construction/metadata response
+	IntrinsicGasMetadataKey = "intrinsic_gas"
+
+	// IntrinsicGasBreakdownMetadataKey is the /construction/metadata response
+	// metadata key of the IntrinsicGas
+	IntrinsicGasBreakdownMetadataKey = "intrinsic_gas_breakdown"
+
+	// AccessListMetadataKey is the /construction/preprocess metadata key of
+	// the access list of the transaction, which is charged intrinsic gas
+	AccessListMetadataKey = "access_list"
+)
+
+// IntrinsicGas is the gas a transaction is charged before it executes, under
+// the fork rules active at the head block
+type IntrinsicGas struct {
+	// Base is the cost of a transaction or, for contract deployments, of a
+	// contract creation transaction
+	Base uint64 `json:"base"`
+
+	// Calldata is the cost of the zero and non zero bytes of the data
+	Calldata uint64 `json:"calldata"`
+
+	// AccessList is the EIP-2930 cost of the addresses and storage keys of
+	// the access list
+	AccessList uint64 `json:"access_list"`
+
+	// InitCode is the EIP-3860 cost of the words of the init code of a
+	// contract deployment
+	InitCode uint64 `json:"init_code"`
+
+	Total uint64 `json:"total"`
+}
+
+// intrinsicGasHeader is the part of the head block used to select the fork rules
+type intrinsicGasHeader struct {
+	Number     *hexutil.Big   `json:"number"`
+	Time       hexutil.Uint64 `json:"timestamp"`
+	Difficulty *hexutil.Big   `json:"difficulty"`
+}
+
+// headRules returns the fork rules of the chain config at the head block
+func (s APIService) headRules(ctx context.Context) (params.Rules, error) {
+	if s.config.ChainConfig == nil {
+		return params.Rules{}, errors.New("chain config is not set")
+	}
+
+	var head *intrinsicGasHeader
+	if err := s.client.CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil {
+		return params.Rules{}, fmt.Errorf("could not get head block: %w", err)
+	}
+	if head == nil || head.Number == nil {
+		return params.Rules{}, errors.New("head block not found")
+	}
+
+	// Blocks after the merge have no difficulty
+	isMerge := s.config.ChainConfig.TerminalTotalDifficultyPassed ||
+		(head.Difficulty != nil && head.Difficulty.ToInt().Sign() == 0)
+
+	return s.config.ChainConfig.Rules(head.Number.ToInt(), isMerge, uint64(head.Time)), nil
+}
+
+// intrinsicGas returns the intrinsic gas of a transaction with data and
+// accessList under rules. It matches core.IntrinsicGas and additionally
+// rejects init code over the EIP-3860 size limit.
+func intrinsicGas(
+	data []byte,
+	accessList EthTypes.AccessList,
+	isCreate bool,
+	rules params.Rules,
+) (*IntrinsicGas, error) {
+	gas := &IntrinsicGas{Base: params.TxGas}
+	if isCreate && rules.IsHomestead {
+		gas.Base = params.TxGasContractCreation
+	}
+
+	nonZeroGas := params.TxDataNonZeroGasFrontier
+	if rules.IsIstanbul {
+		nonZeroGas = params.TxDataNonZeroGasEIP2028
+	}
+	for _, b := range data {
+		if b == 0 {
+			gas.Calldata += params.TxDataZeroGas
+		} else {
+			gas.Calldata += nonZeroGas
+		}
+	}
+
+	if isCreate && rules.IsShanghai {
+		if len(data) > params.MaxInitCodeSize {
+			return nil, fmt.Errorf(
+				"init code size %d exceeds the limit of %d bytes",
+				len(data),
+				params.MaxInitCodeSize,
+			)
+		}
+		gas.InitCode = params.InitCodeWordGas * toWordSize(uint64(len(data)))
+	}
+
+	if len(accessList) > 0 && !rules.IsBerlin {
+		return nil, errors.New("access lists are not supported before berlin")
+	}
+	gas.AccessList = uint64(len(accessList)) * params.TxAccessListAddressGas
+	gas.AccessList += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+
+	gas.Total = gas.Base + gas.Calldata + gas.AccessList + gas.InitCode
+
+	return gas, nil
+}
+
+// toWordSize returns the number of 32 byte words of size bytes
+func toWordSize(size uint64) uint64 {
+	return (size + 31) / 32 // nolint:gomnd
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIntrinsicGas(t *testing.T) {
+	data := []byte{0, 1, 2, 0, 0, 3}
+	accessList := EthTypes.AccessList{
+		{Address: common.HexToAddress("0x1"), StorageKeys: []common.Hash{{1}, {2}}},
+		{Address: common.HexToAddress("0x2")},
+	}
+	frontier := params.Rules{}
+	istanbul := params.Rules{IsHomestead: true, IsIstanbul: true}
+	berlin := params.Rules{IsHomestead: true, IsIstanbul: true, IsBerlin: true}
+	shanghai := params.Rules{IsHomestead: true, IsIstanbul: true, IsBerlin: true, IsShanghai: true}
+
+	tests := map[string]struct {
+		data          []byte
+		accessList    EthTypes.AccessList
+		isCreate      bool
+		rules         params.Rules
+		expected      *IntrinsicGas
+		expectedError string
+	}{
+		"frontier call": {
+			data:     data,
+			rules:    frontier,
+			expected: &IntrinsicGas{Base: 21000, Calldata: 3*4 + 3*68, Total: 21216},
+		},
+		"frontier create": {
+			data:     data,
+			isCreate: true,
+			rules:    frontier,
+			expected: &IntrinsicGas{Base: 21000, Calldata: 3*4 + 3*68, Total: 21216},
+		},
+		"istanbul call": {
+			data:     data,
+			rules:    istanbul,
+			expected: &IntrinsicGas{Base: 21000, Calldata: 3*4 + 3*16, Total: 21060},
+		},
+		"istanbul create": {
+			data:     data,
+			isCreate: true,
+			rules:    istanbul,
+			expected: &IntrinsicGas{Base: 53000, Calldata: 3*4 + 3*16, Total: 53060},
+		},
+		"berlin access list": {
+			accessList: accessList,
+			rules:      berlin,
+			expected:   &IntrinsicGas{Base: 21000, AccessList: 2*2400 + 2*1900, Total: 29600},
+		},
+		"shanghai create": {
+			data:     make([]byte, 33),
+			isCreate: true,
+			rules:    shanghai,
+			expected: &IntrinsicGas{Base: 53000, Calldata: 33 * 4, InitCode: 2 * 2, Total: 53136},
+		},
+		"shanghai call has no init code cost": {
+			data:     make([]byte, 33),
+			rules:    shanghai,
+			expected: &IntrinsicGas{Base: 21000, Calldata: 33 * 4, Total: 21132},
+		},
+		"error: access list before berlin": {
+			accessList:    accessList,
+			rules:         istanbul,
+			expectedError: "access lists are not supported before berlin",
+		},
+		"error: init code too large": {
+			data:          make([]byte, params.MaxInitCodeSize+1),
+			isCreate:      true,
+			rules:         shanghai,
+			expectedError: "init code size 49153 exceeds the limit of 49152 bytes",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gas, err := intrinsicGas(test.data, test.accessList, test.isCreate, test.rules)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, gas)
+
+			expected, err := core.IntrinsicGas(
+				test.data,
+				test.accessList,
+				test.isCreate,
+				test.rules.IsHomestead,
+				test.rules.IsIstanbul,
+				test.rules.IsShanghai,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, gas.Total)
+		})
+	}
+}
+
+func TestMetadataIntrinsicGas(t *testing.T) {
+	// Shanghai is active at the head block
+	head := `{"number":"0x64","timestamp":"0x64","difficulty":"0x0"}`
+
+	tests := map[string]struct {
+		options          map[string]interface{}
+		mocks            func(context.Context, *mockedServices.Client)
+		expectedResponse *types.ConstructionMetadataResponse
+		expectedError    *types.Error
+	}{
+		"happy path: breakdown": {
+			options: map[string]interface{}{
+				"from":          testingFromAddress,
+				"to":            testingToAddress,
+				"value":         transferValue,
+				"gas_limit":     transferGasLimit,
+				"intrinsic_gas": true,
+			},
+			mocks: func(ctx context.Context, client *mockedServices.Client) {
+				client.On("GetNonce", ctx, mock.Anything).
+					Return(transferNonce, nil)
+				client.On("GetGasPrice", ctx, mock.Anything).
+					Return(big.NewInt(int64(transferGasPrice)), nil)
+			},
+			expectedResponse: &types.ConstructionMetadataResponse{
+				Metadata: map[string]interface{}{
+					"nonce":     float64(transferNonce),
+					"gas_price": float64(transferGasPrice),
+					"gas_limit": float64(transferGasLimit),
+					IntrinsicGasBreakdownMetadataKey: &IntrinsicGas{
+						Base:  params.TxGas,
+						Total: params.TxGas,
+					},
+				},
+				SuggestedFee: []*types.Amount{
+					client.Amount(big.NewInt(int64(transferGasPrice)*int64(transferGasLimit)),
+						newTestingClient().cfg.RosettaCfg.Currency),
+				},
+			},
+		},
+		"error: gas limit below intrinsic gas": {
+			options: map[string]interface{}{
+				"from":             testingFromAddress,
+				"to":               testingToAddress,
+				"value":            transferContractValue,
+				"contract_address": tokenContractAddress,
+				"data":             metadataGenericData,
+				"gas_limit":        transferGasLimit,
+			},
+			mocks: func(ctx context.Context, client *mockedServices.Client) {
+				client.On("GetNonce", ctx, mock.Anything).
+					Return(transferNonce, nil)
+				client.On("GetGasPrice", ctx, mock.Anything).
+					Return(big.NewInt(int64(transferGasPrice)), nil)
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "gas limit 21000 is below the intrinsic gas 21584"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			testingClient.cfg.ChainConfig = &params.ChainConfig{
+				ChainID:                 testingClient.cfg.ChainConfig.ChainID,
+				HomesteadBlock:          big.NewInt(0),
+				IstanbulBlock:           big.NewInt(0),
+				BerlinBlock:             big.NewInt(0),
+				LondonBlock:             big.NewInt(0),
+				MergeNetsplitBlock:      big.NewInt(0),
+				ShanghaiTime:            new(uint64),
+				TerminalTotalDifficulty: big.NewInt(0),
+			}
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+
+			mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{}).Maybe()
+			mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", "latest", false).
+				Return(nil).
+				Run(func(args mock.Arguments) {
+					assert.NoError(t, json.Unmarshal([]byte(head), args.Get(1)))
+				}).Once()
+			test.mocks(ctx, mockClient)
+
+			resp, err := testingClient.servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+				NetworkIdentifier: ethereumNetworkIdentifier,
+				Options:           test.options,
+			})
+			if test.expectedError != nil {
+				assert.Equal(t, test.expectedError, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.expectedResponse, resp)
+			}
+		})
+	}
+}
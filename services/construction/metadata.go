@@ -21,6 +21,7 @@ import (
 
 	"errors"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	"github.com/coinbase/rosetta-geth-sdk/client"
@@ -43,25 +44,25 @@ func (s APIService) ConstructionMetadata( //nolint
 		return nil, sdkTypes.ErrUnavailableOffline
 	}
 
+	if batch, ok := req.Options[BatchMetadataKey]; ok {
+		return s.batchMetadata(ctx, batch)
+	}
+
 	var input client.Options
 	if err := client.UnmarshalJSONMap(req.Options, &input); err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	// Address validation
-	if len(input.From) == 0 {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("from address is not provided"))
-	}
-	if len(input.To) == 0 {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("to address is not provided"))
-	}
-	if _, err := client.ChecksumAddress(input.From); err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", input.From, err))
-	}
-	if _, err := client.ChecksumAddress(input.To); err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", input.To, err))
+	isDeployment, rosettaErr := s.checkMetadataInput(&input)
+	if rosettaErr != nil {
+		return nil, rosettaErr
 	}
 
+	var err error
+	input.Nonce, err = accountNonce(input)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
 	nonce, err := s.client.GetNonce(ctx, input)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrNonceError, err)
@@ -81,13 +82,50 @@ func (s APIService) ConstructionMetadata( //nolint
 		input.ContractData = hexutil.Encode(contractData)
 	}
 
+	// Gas limits below the intrinsic gas are rejected without estimating them
+	var intrinsic *IntrinsicGas
+	gasLimitProvided := input.GasLimit != nil && input.GasLimit.Uint64() > 0
+	if gasLimitProvided || input.IntrinsicGas {
+		to, _, data, err := s.transactionCall(&input)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+		rules, err := s.headRules(ctx)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+		}
+		intrinsic, err = intrinsicGas(data, input.AccessList, len(to) == 0, rules)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+		if gasLimitProvided && input.GasLimit.Uint64() < intrinsic.Total {
+			return nil, sdkTypes.WrapErr(
+				sdkTypes.ErrInvalidInput,
+				fmt.Errorf("gas limit %s is below the intrinsic gas %d", input.GasLimit, intrinsic.Total),
+			)
+		}
+	}
+
 	var gasLimit uint64
-	if input.GasLimit == nil || input.GasLimit.Uint64() == 0 {
+	if !gasLimitProvided {
 		switch {
+		case isDeployment:
+			initCode, err := hexutil.Decode(input.ContractData)
+			if err != nil {
+				return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+			}
+
+			value := new(big.Int)
+			value.SetString(input.Value, 10) // nolint:gomnd
+
+			gasLimit, err = s.client.GetContractDeploymentGasLimit(ctx, input.From, value, initCode)
+			if err != nil {
+				return nil, sdkTypes.WrapErr(sdkTypes.ErrERC20GasLimitError, err)
+			}
 		case len(input.ContractAddress) > 0:
 			contractAddress, err := client.ChecksumAddress(input.ContractAddress)
 			if err != nil {
-				return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", input.To, err))
+				return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
 			}
 
 			contractData, err := hexutil.Decode(input.ContractData)
@@ -126,7 +164,16 @@ func (s APIService) ConstructionMetadata( //nolint
 
 	var gasTipCap *big.Int
 	var gasFeeCap *big.Int
-	if s.client.GetRosettaConfig().SupportsEIP1559 {
+	var priorityFeeFloor *big.Int
+	var priorityFeeCap *big.Int
+	dynamicFees, err := s.dynamicFees(ctx, &input)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrGasFeeCapError, err)
+	}
+	if dynamicFees {
+		// The effective bounds of the tip are returned so clients can display them
+		priorityFeeFloor, priorityFeeCap = client.PriorityFeeBounds(s.client.GetRosettaConfig(), input)
+
 		gasTipCap, err = s.client.GetGasTipCap(ctx, input)
 		if err != nil {
 			return nil, sdkTypes.WrapErr(sdkTypes.ErrGasTipCapError, err)
@@ -142,7 +189,7 @@ func (s APIService) ConstructionMetadata( //nolint
 	var l1DataFee *big.Int
 	if s.client.GetRosettaConfig().SupportsOpStack {
 		isContractCall := false
-		if len(input.ContractAddress) > 0 {
+		if len(input.ContractAddress) > 0 || isDeployment {
 			isContractCall = true
 		}
 		value, ok := new(big.Int).SetString(input.Value, 10)
@@ -153,8 +200,8 @@ func (s APIService) ConstructionMetadata( //nolint
 
 		switch {
 		case isContractCall:
-			// Generic contract call
-			// data: contract data
+			// Generic contract call or contract deployment
+			// data: contract data or init code
 			// value: transfer value
 			contractData, err := hexutil.Decode(input.ContractData)
 			if err != nil {
@@ -197,32 +244,141 @@ func (s APIService) ConstructionMetadata( //nolint
 		}
 	}
 
+	var simulation *Simulation
+	if input.Simulate {
+		simulation, err = s.simulate(ctx, &input, gasLimit, gasPrice, gasTipCap, gasFeeCap)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrSimulationFailed, err)
+		}
+	}
+
 	metadata := &client.Metadata{
-		Nonce:           nonce,
-		GasPrice:        gasPrice,
-		GasLimit:        gasLimit,
-		GasTipCap:       gasTipCap,
-		GasFeeCap:       gasFeeCap,
-		ContractData:    input.ContractData,
-		MethodSignature: input.MethodSignature,
-		MethodArgs:      input.MethodArgs,
-		L1DataFee:       l1DataFee,
+		Nonce:            nonce,
+		GasPrice:         gasPrice,
+		GasLimit:         gasLimit,
+		GasTipCap:        gasTipCap,
+		GasFeeCap:        gasFeeCap,
+		ContractData:     input.ContractData,
+		MethodSignature:  input.MethodSignature,
+		MethodArgs:       input.MethodArgs,
+		L1DataFee:        l1DataFee,
+		ChainID:          input.ChainID,
+		PriorityFeeFloor: priorityFeeFloor,
+		PriorityFeeCap:   priorityFeeCap,
+		GasCurrency:      s.config.RosettaCfg.GasCurrency,
+		FeeCurrency:      input.FeeCurrency,
+		AccessList:       input.AccessList,
 	}
 
 	metadataMap, err := client.MarshalJSONMap(metadata)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
 	}
+	if simulation != nil {
+		metadataMap[SimulationMetadataKey] = simulation
+	}
+	if input.IntrinsicGas {
+		metadataMap[IntrinsicGasBreakdownMetadataKey] = intrinsic
+	}
 
-	suggestedFee := gasPrice.Int64() * int64(gasLimit)
-	if gasFeeCap != nil {
-		suggestedFee = gasFeeCap.Int64() * int64(gasLimit)
+	// The suggested fee is what the transaction is expected to cost once
+	// included, so dynamic fees are priced at the effective gas price rather
+	// than at the fee cap
+	feePerGas := gasPrice
+	feeCurrency := s.config.RosettaCfg.FeeCurrency()
+	if len(input.FeeCurrency) > 0 {
+		// The base fee is denominated in the native currency, so fees paid in
+		// a fee currency are priced at the fee cap
+		if !dynamicFees {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("fee currency requires dynamic fees"))
+		}
+		feeCurrency, err = s.feeCurrency(input.FeeCurrency)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+		}
+		feePerGas = gasFeeCap
+	} else if gasFeeCap != nil {
+		baseFee, err := s.client.GetBaseFee(ctx)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGasFeeCapError, err)
+		}
+		feePerGas = effectiveGasPrice(baseFee, gasTipCap, gasFeeCap)
 	}
+	suggestedFee := new(big.Int).Mul(feePerGas, new(big.Int).SetUint64(gasLimit))
+	if l1DataFee != nil {
+		suggestedFee.Add(suggestedFee, l1DataFee)
+	}
+
+	fee := client.Amount(suggestedFee, feeCurrency)
+	s.addReferenceFee(ctx, metadataMap, fee)
 
 	return &types.ConstructionMetadataResponse{
-		Metadata: metadataMap,
-		SuggestedFee: []*types.Amount{
-			client.Amount(big.NewInt(suggestedFee), s.config.RosettaCfg.Currency),
-		},
+		Metadata:     metadataMap,
+		SuggestedFee: []*types.Amount{fee},
 	}, nil
 }
+
+// checkMetadataInput checksums the addresses of input and checks its chain id.
+// It returns whether input is a contract deployment.
+func (s APIService) checkMetadataInput(input *client.Options) (bool, *types.Error) {
+	// Address validation
+	if len(input.From) == 0 {
+		return false, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("from address is not provided"))
+	}
+	// Contract deployments have init code but no destination address
+	isDeployment := len(input.To) == 0 && len(input.ContractAddress) == 0 && len(input.ContractData) > 0
+	if len(input.To) == 0 && !isDeployment {
+		return false, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("to address is not provided"))
+	}
+	from, err := client.ChecksumAddress(input.From)
+	if err != nil {
+		return false, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
+	}
+	input.From = from
+	if !isDeployment {
+		to, err := client.ChecksumAddress(input.To)
+		if err != nil {
+			return false, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
+		}
+		input.To = to
+	}
+
+	// The node can only provide the metadata of its own chain
+	if input.ChainID != nil && input.ChainID.Cmp(s.config.ChainConfig.ChainID) != 0 {
+		return false, sdkTypes.WrapErr(
+			sdkTypes.ErrChainIDMismatch,
+			fmt.Errorf("chain id %s does not match chain id %s", input.ChainID, s.config.ChainConfig.ChainID),
+		)
+	}
+
+	return isDeployment, nil
+}
+
+// feeCurrency returns the currency of the ERC20 fee currency at address
+func (s APIService) feeCurrency(address string) (*types.Currency, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%s is not a valid fee currency", address)
+	}
+	contract := common.HexToAddress(address)
+	currency, err := s.client.GetContractCurrency(contract, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not get fee currency %s: %w", address, err)
+	}
+
+	return client.Erc20Currency(currency.Symbol, currency.Decimals, contract.Hex()), nil
+}
+
+// effectiveGasPrice returns the price per gas of a dynamic fee transaction,
+// min(baseFee + gasTipCap, gasFeeCap). The fee cap is used when the base fee
+// is unknown.
+func effectiveGasPrice(baseFee, gasTipCap, gasFeeCap *big.Int) *big.Int {
+	if baseFee == nil || gasTipCap == nil {
+		return gasFeeCap
+	}
+	price := new(big.Int).Add(baseFee, gasTipCap)
+	if price.Cmp(gasFeeCap) > 0 {
+		return gasFeeCap
+	}
+
+	return price
+}
@@ -162,6 +162,52 @@ func TestMetadata(t *testing.T) {
 				},
 			},
 		},
+		"happy path: priority fee bounds": {
+			options: map[string]interface{}{
+				"from":               testingFromAddress,
+				"to":                 testingToAddress,
+				"value":              transferValue,
+				"priority_fee_floor": float64(transferGasTipCap),
+				"priority_fee_cap":   float64(2 * transferGasTipCap),
+			},
+			mocks: func(ctx context.Context, client *mockedServices.Client) {
+				client.On("GetNonce", ctx, mock.Anything).
+					Return(transferNonce, nil)
+
+				client.On("GetGasPrice", ctx, mock.Anything).
+					Return(big.NewInt(int64(transferGasPrice)), nil)
+
+				client.On("GetNativeTransferGasLimit", ctx, testingToAddress, testingFromAddress, big.NewInt(1)).
+					Return(transferGasLimit, nil)
+
+				client.On("GetGasTipCap", ctx, mock.Anything).
+					Return(big.NewInt(int64(transferGasTipCap)), nil)
+
+				client.On("GetGasFeeCap", ctx, mock.Anything, mock.Anything).
+					Return(big.NewInt(int64(transferGasFeeCap)), nil)
+
+				client.On("GetBaseFee", ctx).
+					Return(big.NewInt(int64(transferBaseFee)), nil)
+
+				client.On("GetRosettaConfig").
+					Return(rosettaConfig)
+			},
+			expectedResponse: &types.ConstructionMetadataResponse{
+				Metadata: map[string]interface{}{
+					"nonce":              float64(transferNonce),
+					"gas_price":          float64(transferGasPrice),
+					"gas_limit":          float64(transferGasLimit),
+					"gas_tip_cap":        float64(transferGasTipCap),
+					"gas_fee_cap":        float64(transferGasFeeCap),
+					"priority_fee_floor": float64(transferGasTipCap),
+					"priority_fee_cap":   float64(2 * transferGasTipCap),
+				},
+				SuggestedFee: []*types.Amount{
+					client.Amount(big.NewInt(int64(transferGasFeeCap)*int64(transferGasLimit)),
+						testingClient.cfg.RosettaCfg.Currency),
+				},
+			},
+		},
 		"happy path: ERC20 currency": {
 			options: map[string]interface{}{
 				"from":                     testingFromAddress,
@@ -263,3 +309,67 @@ func TestMetadata(t *testing.T) {
 		assert.Equal(t, AssetTypes.ErrUnavailableOffline.Code, err.Code)
 	})
 }
+
+func TestMetadataGasCurrency(t *testing.T) {
+	testingClient := newTestingClient()
+	gasCurrency := &types.Currency{Symbol: "GAS", Decimals: 6}
+	testingClient.cfg.RosettaCfg.GasCurrency = gasCurrency
+	ctx := context.Background()
+
+	mockClient := testingClient.mockClient
+	mockClient.On("GetNonce", ctx, mock.Anything).Return(transferNonce, nil)
+	mockClient.On("GetGasPrice", ctx, mock.Anything).Return(big.NewInt(int64(transferGasPrice)), nil)
+	mockClient.On("GetNativeTransferGasLimit", ctx, testingToAddress, testingFromAddress, big.NewInt(1)).
+		Return(transferGasLimit, nil)
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+	resp, err := testingClient.servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Options: map[string]interface{}{
+			"from":  testingFromAddress,
+			"to":    testingToAddress,
+			"value": transferValue,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"symbol": "GAS", "decimals": float64(6)}, resp.Metadata["gas_currency"])
+	assert.Equal(t, []*types.Amount{
+		client.Amount(big.NewInt(int64(transferGasPrice)*int64(transferGasLimit)), gasCurrency),
+	}, resp.SuggestedFee)
+}
+
+func TestMetadataSuggestedFee(t *testing.T) {
+	testingClient := newTestingClient()
+	ctx := context.Background()
+
+	mockClient := testingClient.mockClient
+	mockClient.On("GetNonce", ctx, mock.Anything).Return(transferNonce, nil)
+	mockClient.On("GetGasPrice", ctx, mock.Anything).Return(big.NewInt(int64(transferGasPrice)), nil)
+	mockClient.On("GetNativeTransferGasLimit", ctx, testingToAddress, testingFromAddress, big.NewInt(1)).
+		Return(transferGasLimit, nil)
+	mockClient.On("GetGasTipCap", ctx, mock.Anything).Return(big.NewInt(2), nil)
+	mockClient.On("GetGasFeeCap", ctx, mock.Anything, mock.Anything).Return(big.NewInt(200), nil)
+	mockClient.On("GetBaseFee", ctx).Return(big.NewInt(int64(transferBaseFee)), nil)
+	mockClient.On("GetL1DataFee", ctx, mock.Anything).Return(big.NewInt(1000), nil)
+	mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{
+		SupportsEIP1559: true,
+		SupportsOpStack: true,
+	})
+
+	resp, err := testingClient.servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		Options: map[string]interface{}{
+			"from":  testingFromAddress,
+			"to":    testingToAddress,
+			"value": transferValue,
+		},
+	})
+	assert.Nil(t, err)
+
+	// (base fee + tip) * gas limit + L1 data fee
+	expectedFee := int64(transferBaseFee+2)*int64(transferGasLimit) + 1000
+	assert.Equal(t, []*types.Amount{
+		client.Amount(big.NewInt(expectedFee), testingClient.cfg.RosettaCfg.Currency),
+	}, resp.SuggestedFee)
+	mockClient.AssertExpectations(t)
+}
@@ -17,9 +17,8 @@ package construction
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"math/big"
+	"strings"
 
 	"errors"
 
@@ -42,22 +41,32 @@ func (s *APIService) ConstructionParse(
 	var tx client.Transaction
 
 	if !request.Signed {
-		err := json.Unmarshal([]byte(request.Transaction), &tx)
+		unsignedTx, err := client.UnmarshalUnsignedTransaction([]byte(request.Transaction))
 		if err != nil {
 			return nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
 		}
+		tx = *unsignedTx
+	} else if rawTx, ok := rawCIP64Transaction(request.Transaction); ok {
+		cip64Tx, err := decodeCIP64Transaction(rawTx)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+		var to *common.Address
+		if len(cip64Tx.To) > 0 {
+			address := common.HexToAddress(cip64Tx.To)
+			to = &address
+		}
+		cip64Tx.Currency = s.rawTransactionCurrency(to, cip64Tx.Data)
+		tx = *cip64Tx
 	} else {
-		var wrappedTx client.SignedTransactionWrapper
-		if err := json.Unmarshal([]byte(request.Transaction), &wrappedTx); err != nil {
-			return nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
+		t, currency, rosettaErr := s.decodeSignedTransaction(request.Transaction)
+		if rosettaErr != nil {
+			return nil, rosettaErr
 		}
 
-		var t EthTypes.Transaction
-		if err := t.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
-			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		if t.To() != nil {
+			tx.To = t.To().String()
 		}
-
-		tx.To = t.To().String()
 		tx.Value = t.Value()
 		tx.Data = t.Data()
 		tx.Nonce = t.Nonce()
@@ -66,9 +75,10 @@ func (s *APIService) ConstructionParse(
 		tx.GasTipCap = t.GasTipCap()
 		tx.GasFeeCap = t.GasFeeCap()
 		tx.ChainID = t.ChainId()
-		tx.Currency = wrappedTx.Currency
+		tx.Currency = currency
+		tx.AccessList = t.AccessList()
 
-		msg, err := core.TransactionToMessage(&t, EthTypes.LatestSignerForChainID(t.ChainId()), nil)
+		msg, err := core.TransactionToMessage(t, EthTypes.LatestSignerForChainID(t.ChainId()), nil)
 		if err != nil {
 			return nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
 		}
@@ -82,6 +92,10 @@ func (s *APIService) ConstructionParse(
 	fromAddress := tx.From
 	toAddress := tx.To
 
+	if len(tx.To) == 0 {
+		return s.parseDeployment(request, &tx)
+	}
+
 	// ERC20 transfer
 	if len(tx.Data) != 0 && hasERC20TransferData(tx.Data) {
 		address, amountSent, err := parseErc20TransferData(tx.Data)
@@ -97,11 +111,11 @@ func (s *APIService) ConstructionParse(
 	// Address validation
 	from, err := client.ChecksumAddress(fromAddress)
 	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", tx.From, err))
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
 	}
 	to, err := client.ChecksumAddress(toAddress)
 	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", tx.To, err))
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
 	}
 
 	ops := []*types.Operation{
@@ -141,6 +155,9 @@ func (s *APIService) ConstructionParse(
 		GasFeeCap: tx.GasFeeCap,
 		ChainID:   tx.ChainID,
 	}
+	if tx.FeeCurrency != nil {
+		metadata.FeeCurrency = tx.FeeCurrency.Hex()
+	}
 	metaMap, err := client.MarshalJSONMap(metadata)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
@@ -167,6 +184,110 @@ func (s *APIService) ConstructionParse(
 	return resp, nil
 }
 
+// parseDeployment returns the DEPLOY operation of a contract deployment and
+// the address of the created contract in the metadata
+func (s *APIService) parseDeployment(
+	request *types.ConstructionParseRequest,
+	tx *client.Transaction,
+) (*types.ConstructionParseResponse, *types.Error) {
+	from, err := client.ChecksumAddress(tx.From)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
+	}
+
+	ops := []*types.Operation{
+		{
+			Type: sdkTypes.DeployOpType,
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Account: &types.AccountIdentifier{
+				Address: from,
+			},
+			Amount: &types.Amount{
+				Value:    new(big.Int).Neg(tx.Value).String(),
+				Currency: s.config.RosettaCfg.Currency,
+			},
+		},
+	}
+
+	metadata := &client.ParseMetadata{
+		Nonce:           tx.Nonce,
+		GasPrice:        tx.GasPrice,
+		GasLimit:        tx.GasLimit,
+		GasTipCap:       tx.GasTipCap,
+		GasFeeCap:       tx.GasFeeCap,
+		ChainID:         tx.ChainID,
+		ContractAddress: deployedContractAddress(common.HexToAddress(from), tx.Nonce),
+	}
+	if tx.FeeCurrency != nil {
+		metadata.FeeCurrency = tx.FeeCurrency.Hex()
+	}
+	metaMap, err := client.MarshalJSONMap(metadata)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
+	}
+
+	signers := []*types.AccountIdentifier{}
+	if request.Signed {
+		signers = append(signers, &types.AccountIdentifier{Address: from})
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: signers,
+		Metadata:                 metaMap,
+	}, nil
+}
+
+// decodeSignedTransaction decodes a signed transaction given either as the
+// SDK's signed transaction wrapper or as a raw RLP encoded transaction in hex,
+// as produced by wallets that sign externally. The currency of a raw
+// transaction is derived from its data, since it isn't part of the encoding.
+func (s *APIService) decodeSignedTransaction(
+	signedTx string,
+) (*EthTypes.Transaction, *types.Currency, *types.Error) {
+	var t EthTypes.Transaction
+	if strings.HasPrefix(signedTx, "0x") {
+		rawTx, err := hexutil.Decode(signedTx)
+		if err != nil {
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
+		}
+		if err := t.UnmarshalBinary(rawTx); err != nil {
+			return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+
+		return &t, s.rawTransactionCurrency(t.To(), t.Data()), nil
+	}
+
+	wrappedTx, err := client.UnmarshalSignedTransaction([]byte(signedTx))
+	if err != nil {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrUnableToParseIntermediateResult, err)
+	}
+	if err := t.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
+		return nil, nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
+
+	return &t, wrappedTx.Currency, nil
+}
+
+// rawTransactionCurrency returns the currency transferred by a raw transaction.
+// ERC20 transfers get an unknown token currency with the contract address in
+// its metadata, everything else is treated as a native currency transfer.
+func (s *APIService) rawTransactionCurrency(to *common.Address, data []byte) *types.Currency {
+	if to == nil || len(data) < 4 || !hasERC20TransferData(data) { // nolint:gomnd
+		return s.config.RosettaCfg.Currency
+	}
+
+	return &types.Currency{
+		Symbol:   client.UnknownERC20Symbol,
+		Decimals: client.UnknownERC20Decimals,
+		Metadata: map[string]interface{}{
+			client.ContractAddressMetadata: to.Hex(),
+		},
+	}
+}
+
 // erc20TransferMethodID calculates the first 4 bytes of the method
 // signature for transfer on an ERC20 contract
 func erc20TransferMethodID() ([]byte, error) {
@@ -16,10 +16,14 @@ package construction
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/coinbase/rosetta-geth-sdk/client"
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -124,3 +128,71 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+// rawSignedTransaction returns the RLP encoded hex of a wrapped signed transaction
+func rawSignedTransaction(t *testing.T, wrapped string) string {
+	var wrapper client.SignedTransactionWrapper
+	assert.NoError(t, json.Unmarshal([]byte(wrapped), &wrapper))
+
+	var tx EthTypes.Transaction
+	assert.NoError(t, tx.UnmarshalJSON(wrapper.SignedTransaction))
+
+	raw, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+
+	return hexutil.Encode(raw)
+}
+
+func TestParse_RawTransaction(t *testing.T) {
+	testingClient := newTestingClient()
+
+	tests := map[string]struct {
+		transaction        string
+		expectedOperations []*types.Operation
+		expectedError      *types.Error
+	}{
+		"happy path: raw Ethereum transfer": {
+			transaction:        rawSignedTransaction(t, parseSignedEthereumTransfer),
+			expectedOperations: templateOperations(parseTransferValue, ethereumCurrencyConfig, "CALL"),
+		},
+		"happy path: raw ERC20 transfer": {
+			transaction: rawSignedTransaction(t, parseSignedERC20Transfer),
+			expectedOperations: templateOperations(payloadsTransferValue, &types.Currency{
+				Symbol:   client.UnknownERC20Symbol,
+				Decimals: client.UnknownERC20Decimals,
+				Metadata: map[string]interface{}{
+					"contractAddress": "0x1E77ad77925Ac0075CF61Fb76bA35D884985019d",
+				},
+			}, "ERC20_TRANSFER"),
+		},
+		"error: invalid hex": {
+			transaction:   "0xzz",
+			expectedError: templateError(AssetTypes.ErrUnableToParseIntermediateResult, "invalid hex string"),
+		},
+		"error: invalid RLP": {
+			transaction:   "0x01",
+			expectedError: templateError(AssetTypes.ErrInvalidInput, "typed transaction too short"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := testingClient.servicer.ConstructionParse(
+				context.Background(),
+				&types.ConstructionParseRequest{
+					NetworkIdentifier: ethereumNetworkIdentifier,
+					Signed:            true,
+					Transaction:       test.transaction,
+				},
+			)
+
+			if test.expectedError != nil {
+				assert.Equal(t, test.expectedError, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedOperations, resp.Operations)
+			assert.Equal(t, []*types.AccountIdentifier{{Address: testingFromAddress}}, resp.AccountIdentifierSigners)
+		})
+	}
+}
@@ -17,7 +17,7 @@ package construction
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -71,32 +71,54 @@ func (s *APIService) ConstructionPayloads(
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
 	}
 
-	toOp, amount := matches[1].First()
-	toAddress := toOp.Account.Address
 	nonce := metadata.Nonce
 	gasPrice := metadata.GasPrice
 	gasLimit := metadata.GasLimit
 	gasTipCap := metadata.GasTipCap
 	gasFeeCap := metadata.GasFeeCap
-	chainID := s.config.ChainConfig.ChainID
-	fromOp, _ := matches[0].First()
+	chainID, err := s.resolveChainID(req.NetworkIdentifier, metadata.ChainID)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrChainIDMismatch, err)
+	}
+	fromOp, fromAmount := matches[0].First()
 	fromAddress := fromOp.Account.Address
 	fromCurrency := fromOp.Amount.Currency
+	deployment := isDeployment(req.Operations)
 
 	// Address validation
 	from, err := client.ChecksumAddress(fromAddress)
 	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, fmt.Errorf("%s is not a valid address: %w", fromAddress, err))
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
-	to, err := client.ChecksumAddress(toAddress)
-	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, fmt.Errorf("%s is not a valid address: %w", toAddress, err))
+
+	// Contract deployments have no destination address
+	var toAddress, to string
+	var amount *big.Int
+	if deployment {
+		amount = new(big.Int).Neg(fromAmount)
+	} else {
+		var toOp *types.Operation
+		toOp, amount = matches[1].First()
+		toAddress = toOp.Account.Address
+		to, err = client.ChecksumAddress(toAddress)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
 	}
 
 	var transferData []byte
 	var sendToAddress common.Address
 
 	switch {
+	case deployment:
+		initCode, err := hexutil.Decode(metadata.ContractData)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+		if len(initCode) == 0 {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("contract deployment data is empty"))
+		}
+		transferData = initCode
 	case isContractCall:
 		// Generic contract call logic
 		contractData, err := hexutil.Decode(metadata.ContractData)
@@ -139,10 +161,15 @@ func (s *APIService) ConstructionPayloads(
 		amount = big.NewInt(0)
 	}
 
+	sendTo := sendToAddress.Hex()
+	if deployment {
+		sendTo = ""
+	}
+
 	// Construct SigningPayload
 	unsignedTx := &client.Transaction{
 		From:      from,
-		To:        sendToAddress.Hex(),
+		To:        sendTo,
 		Value:     amount,
 		Data:      transferData,
 		Nonce:     nonce,
@@ -152,18 +179,32 @@ func (s *APIService) ConstructionPayloads(
 		GasFeeCap: gasFeeCap,
 		ChainID:   chainID,
 		Currency:  fromCurrency,
+
+		AccessList: metadata.AccessList,
 	}
-	unsignedEthTx := EthTransaction(unsignedTx)
 
-	signer := EthTypes.LatestSignerForChainID(chainID)
+	if len(metadata.FeeCurrency) > 0 {
+		if !common.IsHexAddress(metadata.FeeCurrency) {
+			return nil, sdkTypes.WrapErr(
+				sdkTypes.ErrInvalidInput,
+				fmt.Errorf("%s is not a valid fee currency", metadata.FeeCurrency),
+			)
+		}
+		feeCurrency := common.HexToAddress(metadata.FeeCurrency)
+		unsignedTx.FeeCurrency = &feeCurrency
+	}
+	signingHash, err := SigningHash(unsignedTx)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
 
 	payload := &types.SigningPayload{
 		AccountIdentifier: &types.AccountIdentifier{Address: from},
-		Bytes:             signer.Hash(unsignedEthTx).Bytes(),
+		Bytes:             signingHash.Bytes(),
 		SignatureType:     types.EcdsaRecovery,
 	}
 
-	unsignedTxJSON, err := json.Marshal(unsignedTx)
+	unsignedTxJSON, err := client.MarshalUnsignedTransaction(unsignedTx)
 	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
@@ -173,3 +214,14 @@ func (s *APIService) ConstructionPayloads(
 		Payloads:            []*types.SigningPayload{payload},
 	}, nil
 }
+
+// SigningHash returns the hash of unsignedTx signed by its sender, the
+// signing payload of /construction/payloads
+func SigningHash(unsignedTx *client.Transaction) (common.Hash, error) {
+	if unsignedTx.FeeCurrency != nil {
+		return cip64SigningHash(unsignedTx)
+	}
+
+	signer := EthTypes.LatestSignerForChainID(unsignedTx.ChainID)
+	return signer.Hash(EthTransaction(unsignedTx)), nil
+}
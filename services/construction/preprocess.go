@@ -16,10 +16,14 @@ package construction
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/coinbase/rosetta-geth-sdk/client"
 	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
@@ -55,20 +59,29 @@ func (s *APIService) ConstructionPreprocess( //nolint
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	fromOp, _ := matches[0].First()
+	fromOp, fromAmount := matches[0].First()
 	fromAddress := fromOp.Account.Address
 	currency := fromOp.Amount.Currency
-	toOp, amount := matches[1].First()
-	toAddress := toOp.Account.Address
 
 	// Address validation
 	from, err := client.ChecksumAddress(fromAddress)
 	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", fromAddress, err))
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
 	}
-	to, err := client.ChecksumAddress(toAddress)
-	if err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, fmt.Errorf("%s is not a valid address: %w", toAddress, err))
+
+	// Contract deployments have no destination address
+	var to string
+	var amount *big.Int
+	if isDeployment(req.Operations) {
+		amount = new(big.Int).Neg(fromAmount)
+	} else {
+		var toOp *types.Operation
+		toOp, amount = matches[1].First()
+		toAddress := toOp.Account.Address
+		to, err = client.ChecksumAddress(toAddress)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidAddress, err)
+		}
 	}
 
 	preprocessOptions := &client.Options{
@@ -83,6 +96,33 @@ func (s *APIService) ConstructionPreprocess( //nolint
 	if err := loadMetadata(req, preprocessOptions); err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
+	if v, ok := req.Metadata[ChainIDMetadataKey]; ok {
+		chainID, err := client.BigIntFromJSON(v)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, fmt.Errorf("%v is not a valid chain id: %w", v, err))
+		}
+		preprocessOptions.ChainID = chainID
+	}
+	chainID, err := s.resolveChainID(req.NetworkIdentifier, preprocessOptions.ChainID)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrChainIDMismatch, err)
+	}
+	// The node only provides the metadata of its own chain, so the transactions
+	// of the other allowed chains are built by /construction/payloads with the
+	// metadata supplied by the caller
+	if chainID.Cmp(s.config.ChainConfig.ChainID) != 0 {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrChainIDMismatch,
+			fmt.Errorf("chain id %s is only supported by /construction/payloads with caller supplied metadata", chainID),
+		)
+	}
+	if isDeployment(req.Operations) {
+		data, err := deploymentData(req.Metadata)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+		}
+		preprocessOptions.ContractData = hexutil.Encode(data)
+	}
 
 	options, err := client.MarshalJSONMap(preprocessOptions)
 	if err != nil {
@@ -96,14 +136,12 @@ func (s *APIService) ConstructionPreprocess( //nolint
 
 func loadNumericMetadata(req *types.ConstructionPreprocessRequest, metadata string, options *client.Options) error {
 	if v, ok := req.Metadata[metadata]; ok {
-		stringObj, ok := v.(string)
-		if !ok {
-			return fmt.Errorf("%s is not a valid %s string", v, metadata)
+		bigObj, err := client.BigIntFromJSON(v)
+		if err != nil {
+			return fmt.Errorf("%v is not a valid %s: %w", v, metadata, err)
 		}
-
-		bigObj, ok := new(big.Int).SetString(stringObj, 10) // nolint:gomnd
-		if !ok {
-			return fmt.Errorf("%s is not a valid %s", v, metadata)
+		if bigObj.Sign() < 0 {
+			return fmt.Errorf("%v is not a valid %s: must not be negative", v, metadata)
 		}
 
 		switch metadata {
@@ -117,6 +155,10 @@ func loadNumericMetadata(req *types.ConstructionPreprocessRequest, metadata stri
 			options.GasTipCap = bigObj
 		case "gas_fee_cap":
 			options.GasFeeCap = bigObj
+		case "priority_fee_floor":
+			options.PriorityFeeFloor = bigObj
+		case "priority_fee_cap":
+			options.PriorityFeeCap = bigObj
 		}
 	}
 
@@ -139,6 +181,75 @@ func loadMetadata(req *types.ConstructionPreprocessRequest, options *client.Opti
 	if err := loadNumericMetadata(req, "gas_fee_cap", options); err != nil {
 		return err
 	}
+	if err := loadNumericMetadata(req, "priority_fee_floor", options); err != nil {
+		return err
+	}
+	if err := loadNumericMetadata(req, "priority_fee_cap", options); err != nil {
+		return err
+	}
+
+	if v, ok := req.Metadata[SimulateMetadataKey]; ok {
+		simulate, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%v is not a valid simulate flag", v)
+		}
+		options.Simulate = simulate
+	}
+
+	if v, ok := req.Metadata[IntrinsicGasMetadataKey]; ok {
+		intrinsicGas, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%v is not a valid intrinsic gas flag", v)
+		}
+		options.IntrinsicGas = intrinsicGas
+	}
+
+	if v, ok := req.Metadata[AccessListMetadataKey]; ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%v is not a valid access list: %w", v, err)
+		}
+		var accessList EthTypes.AccessList
+		if err := json.Unmarshal(b, &accessList); err != nil {
+			return fmt.Errorf("%v is not a valid access list: %w", v, err)
+		}
+		options.AccessList = accessList
+	}
+
+	if v, ok := req.Metadata[AccountNonceMetadataKey]; ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%v is not a valid account nonce: %w", v, err)
+		}
+		var accountNonce client.AccountNonce
+		if err := json.Unmarshal(b, &accountNonce); err != nil {
+			return fmt.Errorf("%v is not a valid account nonce: %w", v, err)
+		}
+		options.AccountNonce = &accountNonce
+	}
+
+	if v, ok := req.Metadata[FeeModeMetadataKey]; ok {
+		feeMode, ok := v.(string)
+		if !ok || (feeMode != LegacyFeeMode && feeMode != DynamicFeeMode) {
+			return fmt.Errorf("%v is not a valid fee mode, expected %s or %s", v, LegacyFeeMode, DynamicFeeMode)
+		}
+		options.FeeMode = feeMode
+	}
+
+	if v, ok := req.Metadata[FeeCurrencyMetadataKey]; ok {
+		feeCurrency, ok := v.(string)
+		if !ok || !common.IsHexAddress(feeCurrency) {
+			return fmt.Errorf("%v is not a valid fee currency", v)
+		}
+		if options.FeeMode == LegacyFeeMode {
+			return errors.New("fee currency requires dynamic fees")
+		}
+		if options.GasTipCap == nil || options.GasFeeCap == nil {
+			return errors.New("fee currency requires gas_tip_cap and gas_fee_cap denominated in the fee currency")
+		}
+		options.FeeCurrency = common.HexToAddress(feeCurrency).Hex()
+		options.FeeMode = DynamicFeeMode
+	}
 
 	if v, ok := req.Metadata["method_signature"]; ok {
 		methodSigStringObj, ok := v.(string)
@@ -211,6 +211,113 @@ func TestConstructionPreprocess(t *testing.T) {
 				},
 			},
 		},
+		"happy path: simulate": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"simulate": true,
+			},
+			expectedResponse: &types.ConstructionPreprocessResponse{
+				Options: map[string]interface{}{
+					"from":  testingFromAddress,
+					"to":    testingToAddress,
+					"value": fmt.Sprint(preprocessTransferValue),
+					"currency": map[string]interface{}{
+						"decimals": float64(18),
+						"symbol":   "ETH",
+					},
+					"simulate": true,
+				},
+			},
+		},
+		"happy path: intrinsic gas": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"intrinsic_gas": true,
+			},
+			expectedResponse: &types.ConstructionPreprocessResponse{
+				Options: map[string]interface{}{
+					"from":  testingFromAddress,
+					"to":    testingToAddress,
+					"value": fmt.Sprint(preprocessTransferValue),
+					"currency": map[string]interface{}{
+						"decimals": float64(18),
+						"symbol":   "ETH",
+					},
+					"intrinsic_gas": true,
+				},
+			},
+		},
+		"happy path: account nonce": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"account_nonce": map[string]interface{}{
+					"address":    testingFromAddress,
+					"latest":     float64(3),
+					"pending":    float64(5),
+					"in_flight":  float64(2),
+					"nonce_gaps": []interface{}{},
+				},
+			},
+			expectedResponse: &types.ConstructionPreprocessResponse{
+				Options: map[string]interface{}{
+					"from":  testingFromAddress,
+					"to":    testingToAddress,
+					"value": fmt.Sprint(preprocessTransferValue),
+					"currency": map[string]interface{}{
+						"decimals": float64(18),
+						"symbol":   "ETH",
+					},
+					"account_nonce": map[string]interface{}{
+						"address":   testingFromAddress,
+						"latest":    float64(3),
+						"pending":   float64(5),
+						"in_flight": float64(2),
+					},
+				},
+			},
+		},
+		"error: invalid account nonce": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"account_nonce": map[string]interface{}{"pending": "five"},
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "map[pending:five] is not a valid account nonce: json: cannot unmarshal string into Go struct field AccountNonce.pending of type uint64"),
+		},
+		"happy path: fee mode": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"fee_mode": "legacy",
+			},
+			expectedResponse: &types.ConstructionPreprocessResponse{
+				Options: map[string]interface{}{
+					"from":  testingFromAddress,
+					"to":    testingToAddress,
+					"value": fmt.Sprint(preprocessTransferValue),
+					"currency": map[string]interface{}{
+						"decimals": float64(18),
+						"symbol":   "ETH",
+					},
+					"fee_mode": "legacy",
+				},
+			},
+		},
+		"error: invalid fee mode": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"fee_mode": "eip1559",
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "eip1559 is not a valid fee mode, expected legacy or dynamic"),
+		},
+		"error: invalid simulate flag": {
+			operations: templateOperations(preprocessTransferValue, ethereumCurrencyConfig, "CALL"),
+			metadata: map[string]interface{}{
+				"simulate": "yes",
+			},
+			expectedError: templateError(
+				AssetTypes.ErrInvalidInput, "yes is not a valid simulate flag"),
+		},
 		"happy path: ERC20 currency": {
 			operations: templateOperations(preprocessTransferValue, &types.Currency{
 				Symbol:   "USDC",
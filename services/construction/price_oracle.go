@@ -0,0 +1,63 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"log"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// SuggestedFeeReferenceMetadataKey is the metadata key of the suggested fee
+// in RosettaConfig.ReferenceCurrency
+const SuggestedFeeReferenceMetadataKey = "suggested_fee_reference"
+
+// newPriceOracle returns the price oracle of c if it implements
+// client.PriceOracle, or the oracle of the price sources of cfg. It is nil
+// without a reference currency.
+func newPriceOracle(cfg *configuration.Configuration, c Client) client.PriceOracle {
+	if cfg == nil || cfg.RosettaCfg.ReferenceCurrency == nil {
+		return nil
+	}
+	if oracle, ok := c.(client.PriceOracle); ok {
+		return oracle
+	}
+
+	oracle, err := client.NewPriceOracle(cfg.RosettaCfg, c)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return oracle
+}
+
+// addReferenceFee adds the suggested fee converted to the reference currency
+// to metadata. A fee that can't be priced is left out rather than failing the
+// request, since it is only informative.
+func (s APIService) addReferenceFee(ctx context.Context, metadata map[string]interface{}, fee *types.Amount) {
+	if s.priceOracle == nil || fee == nil {
+		return
+	}
+
+	referenceFee, err := client.ReferenceAmount(ctx, s.priceOracle, fee, s.config.RosettaCfg.ReferenceCurrency)
+	if err != nil {
+		log.Printf("could not price suggested fee in %s: %v", s.config.RosettaCfg.ReferenceCurrency.Symbol, err)
+		return
+	}
+	metadata[SuggestedFeeReferenceMetadataKey] = referenceFee
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// oracleClient is a client implementing client.PriceOracle
+type oracleClient struct {
+	*mockedServices.Client
+	err error
+}
+
+func (c *oracleClient) Price(context.Context, *types.Currency) (*big.Rat, error) {
+	return big.NewRat(2000, 1), c.err
+}
+
+func TestMetadataReferenceFee(t *testing.T) {
+	usd := &types.Currency{Symbol: "USD", Decimals: 2}
+	tests := map[string]struct {
+		static      map[string]string
+		oracleErr   error
+		useOracle   bool
+		expectedFee *types.Amount
+	}{
+		"static price": {
+			// 105000 gwei at 3125.42 USD per ETH
+			static:      map[string]string{"ETH": "3125.42"},
+			expectedFee: &types.Amount{Value: "32", Currency: usd},
+		},
+		"client oracle": {
+			// The client oracle takes precedence over the static prices
+			static:      map[string]string{"ETH": "3125.42"},
+			useOracle:   true,
+			expectedFee: &types.Amount{Value: "21", Currency: usd},
+		},
+		"no price": {
+			useOracle: true,
+			oracleErr: errors.New("feed is down"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			testingClient := newTestingClient()
+			cfg := testingClient.cfg
+			cfg.RosettaCfg.ReferenceCurrency = usd
+			cfg.RosettaCfg.StaticPrices = test.static
+
+			mockClient := testingClient.mockClient
+			mockClient.On("GetNonce", ctx, mock.Anything).Return(transferNonce, nil)
+			mockClient.On("GetGasPrice", ctx, mock.Anything).Return(big.NewInt(int64(transferGasPrice)), nil)
+			mockClient.On("GetNativeTransferGasLimit", ctx, testingToAddress, testingFromAddress, big.NewInt(1)).
+				Return(transferGasLimit, nil)
+			mockClient.On("GetRosettaConfig").Return(configuration.RosettaConfig{})
+
+			var c Client = mockClient
+			if test.useOracle {
+				c = &oracleClient{Client: mockClient, err: test.oracleErr}
+			}
+			servicer := NewAPIService(cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, c)
+
+			resp, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+				NetworkIdentifier: ethereumNetworkIdentifier,
+				Options: map[string]interface{}{
+					"from":  testingFromAddress,
+					"to":    testingToAddress,
+					"value": transferValue,
+				},
+			})
+			assert.Nil(t, err)
+			if test.expectedFee != nil {
+				assert.Equal(t, test.expectedFee, resp.Metadata[SuggestedFeeReferenceMetadataKey])
+			} else {
+				assert.NotContains(t, resp.Metadata, SuggestedFeeReferenceMetadataKey)
+			}
+			assert.Equal(t, "105000000000000", resp.SuggestedFee[0].Value)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
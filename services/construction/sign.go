@@ -0,0 +1,85 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// SignAndSubmit signs the unsigned transaction of /construction/payloads with
+// s, then combines and submits it like /construction/combine and
+// /construction/submit. The signing payload is computed from the transaction,
+// so only transactions sent by the account of s are signed.
+func (s *APIService) SignAndSubmit(
+	ctx context.Context,
+	transactionSigner signer.Signer,
+	unsignedTransaction string,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	if len(unsignedTransaction) == 0 {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, errors.New("transaction data is not provided"))
+	}
+
+	unsignedTx, err := client.UnmarshalUnsignedTransaction([]byte(unsignedTransaction))
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
+
+	address, err := signer.Address(transactionSigner)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
+	}
+	if !strings.EqualFold(address.Hex(), unsignedTx.From) {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrSignerMismatch,
+			fmt.Errorf("transaction sender %s is not the server signer %s", unsignedTx.From, address.Hex()),
+		)
+	}
+
+	signingHash, err := SigningHash(unsignedTx)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
+	signature, err := transactionSigner.Sign(ctx, &types.SigningPayload{
+		AccountIdentifier: &types.AccountIdentifier{Address: address.Hex()},
+		Bytes:             signingHash.Bytes(),
+		SignatureType:     types.EcdsaRecovery,
+	})
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, fmt.Errorf("could not sign: %w", err))
+	}
+
+	combined, rosettaErr := s.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   s.config.Network,
+		UnsignedTransaction: unsignedTransaction,
+		Signatures:          []*types.Signature{signature},
+	})
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	return s.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: s.config.Network,
+		SignedTransaction: combined.SignedTransaction,
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndSubmit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	unsignedTx, err := client.MarshalUnsignedTransaction(&client.Transaction{
+		From:     from.Hex(),
+		To:       testingToAddress,
+		Value:    big.NewInt(1),
+		Nonce:    3,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 21000,
+		ChainID:  big.NewInt(int64(ethRopstenChainID)),
+		Currency: ethereumCurrencyConfig,
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		signer              signer.Signer
+		unsignedTransaction string
+
+		expectedErrorCode int32
+	}{
+		"submitted": {
+			signer:              signer.NewKeySigner(key),
+			unsignedTransaction: string(unsignedTx),
+		},
+		"other sender": {
+			signer:              signer.NewKeySigner(otherKey),
+			unsignedTransaction: string(unsignedTx),
+			expectedErrorCode:   AssetTypes.ErrSignerMismatch.Code,
+		},
+		"invalid transaction": {
+			signer:              signer.NewKeySigner(key),
+			unsignedTransaction: "{",
+			expectedErrorCode:   AssetTypes.ErrInvalidInput.Code,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+
+			var submitted *EthTypes.Transaction
+			if test.expectedErrorCode == 0 {
+				mockClient.On("Submit", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+					submitted = args.Get(1).(*EthTypes.Transaction)
+				}).Once()
+			}
+
+			resp, rosettaErr := testingClient.servicer.SignAndSubmit(ctx, test.signer, test.unsignedTransaction)
+			if test.expectedErrorCode != 0 {
+				assert.Equal(t, test.expectedErrorCode, rosettaErr.Code)
+			} else {
+				assert.Nil(t, rosettaErr)
+				assert.Equal(t, submitted.Hash().Hex(), resp.TransactionIdentifier.Hash)
+				sender, err := EthTypes.Sender(EthTypes.LatestSignerForChainID(submitted.ChainId()), submitted)
+				assert.NoError(t, err)
+				assert.Equal(t, from, sender)
+				assert.Equal(t, uint64(3), submitted.Nonce())
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// SimulateMetadataKey is the /construction/preprocess metadata flag that
+	// enables the simulation of the transaction in /construction/metadata
+	SimulateMetadataKey = "simulate"
+
+	// SimulationMetadataKey is the /construction/metadata response metadata
+	// key of the Simulation
+	SimulationMetadataKey = "simulation"
+)
+
+// Simulation is the result of executing a transaction on top of the latest
+// block before it is signed
+type Simulation struct {
+	GasUsed string `json:"gas_used"`
+
+	// BalanceChanges are the native currency balance changes of the
+	// transaction, including its fee. They are omitted when the node doesn't
+	// support debug_traceCall.
+	BalanceChanges []*BalanceChange `json:"balance_changes,omitempty"`
+}
+
+// BalanceChange is the balance change of an account in a Simulation
+type BalanceChange struct {
+	Account *types.AccountIdentifier `json:"account_identifier"`
+	Amount  *types.Amount            `json:"amount"`
+}
+
+// simulationCall is the callTracer result of debug_traceCall
+type simulationCall struct {
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Output       hexutil.Bytes  `json:"output"`
+	Error        string         `json:"error"`
+	RevertReason string         `json:"revertReason"`
+}
+
+// simulationAccount is an account of the prestateTracer result of debug_traceCall
+type simulationAccount struct {
+	Balance *hexutil.Big `json:"balance"`
+}
+
+// simulationDiff is the prestateTracer result of debug_traceCall in diff mode
+type simulationDiff struct {
+	Pre  map[common.Address]*simulationAccount `json:"pre"`
+	Post map[common.Address]*simulationAccount `json:"post"`
+}
+
+// simulate executes the transaction described by input with debug_traceCall
+// at the latest block and returns its gas used and balance changes. It returns
+// an error when the transaction reverts. Nodes without debug_traceCall fall
+// back to eth_call, which only detects reverts.
+func (s APIService) simulate(
+	ctx context.Context,
+	input *client.Options,
+	gasLimit uint64,
+	gasPrice *big.Int,
+	gasTipCap *big.Int,
+	gasFeeCap *big.Int,
+) (*Simulation, error) {
+	msg, err := s.simulationMessage(input, gasLimit, gasPrice, gasTipCap, gasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+
+	var call simulationCall
+	var diff simulationDiff
+	reqs := []rpc.BatchElem{
+		{
+			Method: "debug_traceCall",
+			Args:   []interface{}{msg, "latest", map[string]interface{}{"tracer": "callTracer"}},
+			Result: &call,
+		},
+		{
+			Method: "debug_traceCall",
+			Args: []interface{}{
+				msg,
+				"latest",
+				map[string]interface{}{
+					"tracer":       "prestateTracer",
+					"tracerConfig": map[string]interface{}{"diffMode": true},
+				},
+			},
+			Result: &diff,
+		},
+	}
+	if err := s.client.BatchCallContext(ctx, reqs); err != nil {
+		return nil, fmt.Errorf("could not simulate transaction: %w", err)
+	}
+	if client.IsMethodNotFound(reqs[0].Error) {
+		return s.simulateWithCall(ctx, msg, gasLimit)
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("could not simulate transaction: %w", req.Error)
+		}
+	}
+
+	if len(call.Error) > 0 {
+		reason := call.RevertReason
+		if unpacked, err := abi.UnpackRevert(call.Output); err == nil {
+			reason = unpacked
+		}
+		return nil, revertError(call.Error, reason)
+	}
+
+	accounts, changes := balanceChanges(&diff)
+	simulation := &Simulation{GasUsed: new(big.Int).SetUint64(uint64(call.GasUsed)).String()}
+	for i := range accounts {
+		simulation.BalanceChanges = append(simulation.BalanceChanges, &BalanceChange{
+			Account: client.Account(&accounts[i]),
+			Amount:  client.Amount(changes[i], s.config.RosettaCfg.Currency),
+		})
+	}
+
+	return simulation, nil
+}
+
+// simulateWithCall executes the transaction with eth_call, for nodes that
+// don't support debug_traceCall
+func (s APIService) simulateWithCall(
+	ctx context.Context,
+	msg map[string]interface{},
+	gasLimit uint64,
+) (*Simulation, error) {
+	var output hexutil.Bytes
+	err := s.client.CallContext(ctx, &output, "eth_call", msg, "latest")
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		reason := ""
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if revertData, err := hexutil.Decode(data); err == nil {
+				reason, _ = abi.UnpackRevert(revertData)
+			}
+		}
+		return nil, revertError(err.Error(), reason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not simulate transaction: %w", err)
+	}
+
+	// eth_call doesn't report the gas used, so the gas limit is an upper bound
+	return &Simulation{GasUsed: new(big.Int).SetUint64(gasLimit).String()}, nil
+}
+
+// simulationMessage returns the call message of the transaction described by input
+func (s APIService) simulationMessage(
+	input *client.Options,
+	gasLimit uint64,
+	gasPrice *big.Int,
+	gasTipCap *big.Int,
+	gasFeeCap *big.Int,
+) (map[string]interface{}, error) {
+	to, value, data, err := s.transactionCall(input)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := map[string]interface{}{
+		"from":  common.HexToAddress(input.From),
+		"gas":   hexutil.Uint64(gasLimit),
+		"value": (*hexutil.Big)(value),
+		"data":  hexutil.Bytes(data),
+	}
+	if len(to) > 0 {
+		msg["to"] = common.HexToAddress(to)
+	}
+	if gasFeeCap != nil && gasTipCap != nil {
+		msg["maxFeePerGas"] = (*hexutil.Big)(gasFeeCap)
+		msg["maxPriorityFeePerGas"] = (*hexutil.Big)(gasTipCap)
+	} else {
+		msg["gasPrice"] = (*hexutil.Big)(gasPrice)
+	}
+
+	return msg, nil
+}
+
+// transactionCall returns the destination, value and data of the transaction
+// described by input. Contract deployments have no destination.
+func (s APIService) transactionCall(input *client.Options) (string, *big.Int, []byte, error) {
+	value, ok := new(big.Int).SetString(input.Value, 10) // nolint:gomnd
+	if !ok {
+		return "", nil, nil, fmt.Errorf("transaction value %s is invalid", input.Value)
+	}
+
+	to := input.To
+	var data []byte
+	switch {
+	case len(input.To) == 0:
+		// Contract deployments have no destination address
+		initCode, err := hexutil.Decode(input.ContractData)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("transaction data %s is invalid: %w", input.ContractData, err)
+		}
+		data = initCode
+	case len(input.ContractAddress) > 0:
+		contractData, err := hexutil.Decode(input.ContractData)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("transaction data %s is invalid: %w", input.ContractData, err)
+		}
+		to = input.ContractAddress
+		data = contractData
+	case input.Currency == nil || types.Hash(input.Currency) == types.Hash(s.config.RosettaCfg.Currency):
+		// Native currency transfers have no data
+	default:
+		contractAddress, ok := input.Currency.Metadata[client.ContractAddressMetadata].(string)
+		if !ok {
+			return "", nil, nil, fmt.Errorf("currency %s has no contract address", input.Currency.Symbol)
+		}
+		to = contractAddress
+		data = client.GenerateErc20TransferData(input.To, value)
+		value = big.NewInt(0)
+	}
+
+	return to, value, data, nil
+}
+
+// balanceChanges returns the accounts whose balance changed in the
+// prestateTracer diff, sorted by address, and their balance changes
+func balanceChanges(diff *simulationDiff) ([]common.Address, []*big.Int) {
+	balance := func(accounts map[common.Address]*simulationAccount, addr common.Address) (*big.Int, bool) {
+		account, ok := accounts[addr]
+		if !ok {
+			return new(big.Int), false
+		}
+		if account.Balance == nil {
+			return nil, true
+		}
+		return account.Balance.ToInt(), true
+	}
+
+	addrs := map[common.Address]bool{}
+	for addr := range diff.Pre {
+		addrs[addr] = true
+	}
+	for addr := range diff.Post {
+		addrs[addr] = true
+	}
+
+	var accounts []common.Address
+	changes := map[common.Address]*big.Int{}
+	for addr := range addrs {
+		pre, _ := balance(diff.Pre, addr)
+		post, ok := balance(diff.Post, addr)
+		if pre == nil || (ok && post == nil) {
+			// The balance is omitted from the diff when it didn't change
+			continue
+		}
+		change := new(big.Int).Sub(post, pre)
+		if change.Sign() == 0 {
+			continue
+		}
+		accounts = append(accounts, addr)
+		changes[addr] = change
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i].Bytes(), accounts[j].Bytes()) < 0
+	})
+
+	amounts := make([]*big.Int, len(accounts))
+	for i, addr := range accounts {
+		amounts[i] = changes[addr]
+	}
+
+	return accounts, amounts
+}
+
+// revertError returns the error of a reverted simulation
+func revertError(message string, reason string) error {
+	if len(reason) > 0 {
+		return fmt.Errorf("transaction reverted: %s: %s", message, reason)
+	}
+	return fmt.Errorf("transaction reverted: %s", message)
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// simulationError is a JSON RPC error returned by the node
+type simulationError struct {
+	code int
+	data interface{}
+}
+
+func (e *simulationError) Error() string          { return "execution reverted" }
+func (e *simulationError) ErrorCode() int         { return e.code }
+func (e *simulationError) ErrorData() interface{} { return e.data }
+
+func TestSimulate(t *testing.T) {
+	// Error(string) with reason "not enough balance"
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000012" +
+		"6e6f7420656e6f7567682062616c616e63650000000000000000000000000000"
+	coinbase := "0x0000000000000000000000000000000000000001"
+
+	tests := map[string]struct {
+		options            client.Options
+		callTrace          string
+		prestateTrace      string
+		traceErr           error
+		callErr            error
+		expectedTo         string
+		expectedSimulation *Simulation
+		expectedError      string
+	}{
+		"happy path: native transfer": {
+			options: client.Options{
+				From:  testingFromAddress,
+				To:    testingToAddress,
+				Value: "100",
+			},
+			callTrace: `{"gasUsed":"0x5208"}`,
+			prestateTrace: `{
+				"pre": {
+					"` + testingFromAddress + `": {"balance": "0x3e8", "nonce": 1},
+					"` + coinbase + `": {"balance": "0x0"}
+				},
+				"post": {
+					"` + testingFromAddress + `": {"balance": "0x320", "nonce": 2},
+					"` + testingToAddress + `": {"balance": "0x64"},
+					"` + coinbase + `": {"balance": "0x64"}
+				}
+			}`,
+			expectedTo: testingToAddress,
+			expectedSimulation: &Simulation{
+				GasUsed: "21000",
+				BalanceChanges: []*BalanceChange{
+					{
+						Account: &types.AccountIdentifier{Address: common.HexToAddress(coinbase).Hex()},
+						Amount:  client.Amount(big.NewInt(100), ethereumCurrencyConfig),
+					},
+					{
+						Account: &types.AccountIdentifier{Address: testingFromAddress},
+						Amount:  client.Amount(big.NewInt(-200), ethereumCurrencyConfig),
+					},
+					{
+						Account: &types.AccountIdentifier{Address: testingToAddress},
+						Amount:  client.Amount(big.NewInt(100), ethereumCurrencyConfig),
+					},
+				},
+			},
+		},
+		"happy path: ERC20 transfer": {
+			options: client.Options{
+				From:  testingFromAddress,
+				To:    testingToAddress,
+				Value: "100",
+				Currency: &types.Currency{
+					Symbol:   "USDC",
+					Decimals: 6,
+					Metadata: map[string]interface{}{client.ContractAddressMetadata: tokenContractAddress},
+				},
+			},
+			callTrace:          `{"gasUsed":"0x8000"}`,
+			prestateTrace:      `{"pre": {}, "post": {}}`,
+			expectedTo:         tokenContractAddress,
+			expectedSimulation: &Simulation{GasUsed: "32768"},
+		},
+		"error: reverted contract call": {
+			options: client.Options{
+				From:            testingFromAddress,
+				To:              testingToAddress,
+				Value:           "0",
+				ContractAddress: tokenContractAddress,
+				ContractData:    metadataGenericData,
+			},
+			callTrace:     `{"gasUsed":"0x8000","error":"execution reverted","output":"` + revertData + `"}`,
+			prestateTrace: `{"pre": {}, "post": {}}`,
+			expectedTo:    tokenContractAddress,
+			expectedError: "transaction reverted: execution reverted: not enough balance",
+		},
+		"happy path: eth_call fallback": {
+			options: client.Options{
+				From:  testingFromAddress,
+				To:    testingToAddress,
+				Value: "100",
+			},
+			traceErr:           &simulationError{code: testutil.MethodNotFoundCode},
+			expectedTo:         testingToAddress,
+			expectedSimulation: &Simulation{GasUsed: "21000"},
+		},
+		"error: eth_call fallback revert": {
+			options: client.Options{
+				From:  testingFromAddress,
+				To:    testingToAddress,
+				Value: "100",
+			},
+			traceErr:      &simulationError{code: testutil.MethodNotFoundCode},
+			callErr:       &simulationError{code: 3, data: revertData},
+			expectedTo:    testingToAddress,
+			expectedError: "transaction reverted: execution reverted: not enough balance",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+
+			checkMessage := func(msg map[string]interface{}) {
+				assert.Equal(t, common.HexToAddress(testingFromAddress), msg["from"])
+				assert.Equal(t, common.HexToAddress(test.expectedTo), msg["to"])
+				assert.Equal(t, hexutil.Uint64(transferGasLimit), msg["gas"])
+			}
+			mockClient.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(
+				func(args mock.Arguments) {
+					reqs := args.Get(1).([]rpc.BatchElem)
+					assert.Len(t, reqs, 2)
+					for i, trace := range []string{test.callTrace, test.prestateTrace} {
+						assert.Equal(t, "debug_traceCall", reqs[i].Method)
+						checkMessage(reqs[i].Args[0].(map[string]interface{}))
+						if test.traceErr != nil {
+							reqs[i].Error = test.traceErr
+							continue
+						}
+						assert.NoError(t, json.Unmarshal([]byte(trace), reqs[i].Result))
+					}
+				},
+			).Once()
+			if test.traceErr != nil {
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_call", mock.Anything, "latest").
+					Return(test.callErr).
+					Run(func(args mock.Arguments) {
+						checkMessage(args.Get(3).(map[string]interface{}))
+					}).Once()
+			}
+
+			simulation, err := testingClient.servicer.simulate(
+				ctx,
+				&test.options,
+				transferGasLimit,
+				big.NewInt(int64(transferGasPrice)),
+				nil,
+				nil,
+			)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedSimulation, simulation)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
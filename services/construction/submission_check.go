@@ -0,0 +1,187 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// SubmissionReportMetadataKey is the /construction/submit response
+	// metadata key of the SubmissionReport, when RosettaConfig.SubmitValidateOnly
+	// is set
+	SubmissionReportMetadataKey = "submission_report"
+
+	// Checks of a SubmissionReport
+	ChainIDCheck  = "chain_id"
+	NonceCheck    = "nonce"
+	BalanceCheck  = "balance"
+	GasLimitCheck = "gas_limit"
+)
+
+// SubmissionReport is the result of the checks of a signed transaction
+// before it is submitted
+type SubmissionReport struct {
+	Hash string `json:"hash"`
+	From string `json:"from"`
+
+	// Valid is whether all the checks passed
+	Valid  bool               `json:"valid"`
+	Checks []*SubmissionCheck `json:"checks"`
+}
+
+// SubmissionCheck is a check of a SubmissionReport
+type SubmissionCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+
+	// Reason is why the check failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// submissionState is the state of the sender and of the chain at the latest
+// block that a signed transaction is checked against
+type submissionState struct {
+	nonce    hexutil.Uint64
+	balance  hexutil.Big
+	gasLimit hexutil.Uint64
+}
+
+// CheckSignedTransaction checks a signed transaction of /construction/combine
+// against the latest block before it is submitted: its chain id is the chain
+// id of the network, its nonce isn't used yet, the balance of its sender
+// covers its value and maximum fee, and its gas limit fits in a block. The
+// transaction is valid when all the checks pass.
+func (s *APIService) CheckSignedTransaction(
+	ctx context.Context,
+	signedTransaction string,
+) (*SubmissionReport, *types.Error) {
+	if s.config.Mode != sdkTypes.Online {
+		return nil, sdkTypes.ErrUnavailableOffline
+	}
+	if _, ok := rawCIP64Transaction(signedTransaction); ok {
+		return nil, sdkTypes.WrapErr(
+			sdkTypes.ErrUnimplemented,
+			fmt.Errorf("CIP-64 transactions can't be checked"),
+		)
+	}
+
+	signedTx, err := unmarshalSignedTransaction(signedTransaction)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
+	}
+	from, err := EthTypes.Sender(EthTypes.LatestSignerForChainID(signedTx.ChainId()), signedTx)
+	if err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrSignatureInvalid, err)
+	}
+
+	var state submissionState
+	var header struct {
+		GasLimit *hexutil.Uint64 `json:"gasLimit"`
+	}
+	reqs := []rpc.BatchElem{
+		{Method: "eth_getTransactionCount", Args: []interface{}{from, "latest"}, Result: &state.nonce},
+		{Method: "eth_getBalance", Args: []interface{}{from, "latest"}, Result: &state.balance},
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"latest", false}, Result: &header},
+	}
+	if err := s.client.BatchCallContext(ctx, reqs); err != nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, fmt.Errorf("%s failed: %w", req.Method, req.Error))
+		}
+	}
+	if header.GasLimit == nil {
+		return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, fmt.Errorf("latest block has no gas limit"))
+	}
+	state.gasLimit = *header.GasLimit
+
+	report := &SubmissionReport{
+		Hash:  signedTx.Hash().String(),
+		From:  from.Hex(),
+		Valid: true,
+		Checks: []*SubmissionCheck{
+			s.checkChainID(signedTx),
+			checkNonce(signedTx, &state),
+			checkBalance(signedTx, &state),
+			checkGasLimit(signedTx, &state),
+		},
+	}
+	for _, check := range report.Checks {
+		report.Valid = report.Valid && check.Passed
+	}
+
+	return report, nil
+}
+
+// checkChainID checks that tx is replay protected for the chain of the network
+func (s *APIService) checkChainID(tx *EthTypes.Transaction) *SubmissionCheck {
+	check := &SubmissionCheck{Name: ChainIDCheck}
+	switch {
+	case !tx.Protected():
+		check.Reason = "transaction is not replay protected"
+	case tx.ChainId().Cmp(s.config.ChainConfig.ChainID) != 0:
+		check.Reason = fmt.Sprintf(
+			"transaction chain id %s does not match chain id %s",
+			tx.ChainId(),
+			s.config.ChainConfig.ChainID,
+		)
+	default:
+		check.Passed = true
+	}
+	return check
+}
+
+// checkNonce checks that the nonce of tx isn't used by an included transaction.
+// Nonces of pending transactions pass, since tx may replace them.
+func checkNonce(tx *EthTypes.Transaction, state *submissionState) *SubmissionCheck {
+	check := &SubmissionCheck{Name: NonceCheck, Passed: tx.Nonce() >= uint64(state.nonce)}
+	if !check.Passed {
+		check.Reason = fmt.Sprintf("nonce %d is already used, the next nonce is %d", tx.Nonce(), state.nonce)
+	}
+	return check
+}
+
+// checkBalance checks that the balance of the sender covers the value and the
+// maximum fee of tx
+func checkBalance(tx *EthTypes.Transaction, state *submissionState) *SubmissionCheck {
+	cost := tx.Cost()
+	balance := (*big.Int)(&state.balance)
+	check := &SubmissionCheck{Name: BalanceCheck, Passed: balance.Cmp(cost) >= 0}
+	if !check.Passed {
+		check.Reason = fmt.Sprintf("balance %s does not cover value and maximum fee %s", balance, cost)
+	}
+	return check
+}
+
+// checkGasLimit checks that the gas limit of tx is within the gas limit of the
+// latest block
+func checkGasLimit(tx *EthTypes.Transaction, state *submissionState) *SubmissionCheck {
+	check := &SubmissionCheck{Name: GasLimitCheck, Passed: tx.Gas() <= uint64(state.gasLimit)}
+	if !check.Passed {
+		check.Reason = fmt.Sprintf("gas limit %d exceeds block gas limit %d", tx.Gas(), state.gasLimit)
+	}
+	return check
+}
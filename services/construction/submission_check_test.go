@@ -0,0 +1,216 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// signedTestTransaction returns a signed transaction of /construction/combine
+// sending 1 wei with a gas price of 1 wei
+func signedTestTransaction(t *testing.T, nonce uint64, gas uint64, signer EthTypes.Signer) string {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signedTx, err := EthTypes.SignTx(
+		EthTypes.NewTransaction(nonce, common.HexToAddress(testingToAddress), big.NewInt(1), gas, big.NewInt(1), nil),
+		signer,
+		key,
+	)
+	require.NoError(t, err)
+	txJSON, err := signedTx.MarshalJSON()
+	require.NoError(t, err)
+	payload, err := client.MarshalSignedTransaction(&client.SignedTransactionWrapper{SignedTransaction: txJSON})
+	require.NoError(t, err)
+	return string(payload)
+}
+
+// mockSubmissionState mocks the batch of CheckSignedTransaction
+func mockSubmissionState(
+	t *testing.T,
+	mockClient *services.Client,
+	ctx context.Context,
+	nonce uint64,
+	balance int64,
+	gasLimit uint64,
+	batchErr error,
+) {
+	mockClient.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		reqs := args.Get(1).([]rpc.BatchElem)
+		if batchErr != nil {
+			reqs[1].Error = batchErr
+			return
+		}
+		results := []string{
+			hexutil.EncodeUint64(nonce),
+			hexutil.EncodeBig(big.NewInt(balance)),
+			`{"gasLimit":"` + hexutil.EncodeUint64(gasLimit) + `"}`,
+		}
+		for i, result := range results {
+			if i < 2 {
+				result = `"` + result + `"`
+			}
+			assert.NoError(t, json.Unmarshal([]byte(result), reqs[i].Result))
+		}
+	}).Once()
+}
+
+func TestCheckSignedTransaction(t *testing.T) {
+	ropsten := EthTypes.NewEIP155Signer(big.NewInt(int64(ethRopstenChainID)))
+
+	tests := map[string]struct {
+		nonce    uint64
+		gas      uint64
+		signer   EthTypes.Signer
+		balance  int64
+		batchErr error
+
+		expectedFailed []string
+		expectedError  *types.Error
+	}{
+		"valid": {
+			nonce:   2,
+			gas:     21000,
+			signer:  ropsten,
+			balance: 21001,
+		},
+		"replacement of a pending transaction": {
+			nonce:   5,
+			gas:     21000,
+			signer:  ropsten,
+			balance: 21001,
+		},
+		"used nonce": {
+			nonce:          1,
+			gas:            21000,
+			signer:         ropsten,
+			balance:        21001,
+			expectedFailed: []string{NonceCheck},
+		},
+		"insufficient balance": {
+			nonce:          2,
+			gas:            21000,
+			signer:         ropsten,
+			balance:        21000,
+			expectedFailed: []string{BalanceCheck},
+		},
+		"gas limit above block gas limit": {
+			nonce:          2,
+			gas:            40000000,
+			signer:         ropsten,
+			balance:        40000001,
+			expectedFailed: []string{GasLimitCheck},
+		},
+		"other chain": {
+			nonce:          2,
+			gas:            21000,
+			signer:         EthTypes.NewEIP155Signer(big.NewInt(1)),
+			balance:        21001,
+			expectedFailed: []string{ChainIDCheck},
+		},
+		"not replay protected": {
+			nonce:          2,
+			gas:            21000,
+			signer:         EthTypes.HomesteadSigner{},
+			balance:        0,
+			expectedFailed: []string{ChainIDCheck, BalanceCheck},
+		},
+		"node error": {
+			nonce:         2,
+			gas:           21000,
+			signer:        ropsten,
+			batchErr:      errors.New("connection refused"),
+			expectedError: templateError(AssetTypes.ErrGeth, "eth_getBalance failed: connection refused"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+			mockSubmissionState(t, mockClient, ctx, 2, test.balance, 30000000, test.batchErr)
+
+			report, rosettaErr := testingClient.servicer.CheckSignedTransaction(
+				ctx,
+				signedTestTransaction(t, test.nonce, test.gas, test.signer),
+			)
+			if test.expectedError != nil {
+				assert.Equal(t, test.expectedError, rosettaErr)
+				return
+			}
+			assert.Nil(t, rosettaErr)
+
+			var failed []string
+			for _, check := range report.Checks {
+				if !check.Passed {
+					assert.NotEmpty(t, check.Reason)
+					failed = append(failed, check.Name)
+				}
+			}
+			assert.Equal(t, test.expectedFailed, failed)
+			assert.Equal(t, len(test.expectedFailed) == 0, report.Valid)
+			assert.Len(t, report.Checks, 4)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckSignedTransaction_InvalidInput(t *testing.T) {
+	testingClient := newTestingClient()
+
+	_, rosettaErr := testingClient.servicer.CheckSignedTransaction(context.Background(), "{")
+	assert.Equal(t, AssetTypes.ErrInvalidInput.Code, rosettaErr.Code)
+}
+
+func TestConstructionSubmit_ValidateOnly(t *testing.T) {
+	testingClient := newTestingClient()
+	testingClient.cfg.RosettaCfg.SubmitValidateOnly = true
+	mockClient := testingClient.mockClient
+	ctx := context.Background()
+	mockSubmissionState(t, mockClient, ctx, 2, 0, 30000000, nil)
+
+	signedTransaction := signedTestTransaction(t, 2, 21000, EthTypes.NewEIP155Signer(big.NewInt(int64(ethRopstenChainID))))
+	resp, rosettaErr := testingClient.servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: ethereumNetworkIdentifier,
+		SignedTransaction: signedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+
+	report := resp.Metadata[SubmissionReportMetadataKey].(*SubmissionReport)
+	assert.Equal(t, resp.TransactionIdentifier.Hash, report.Hash)
+	assert.False(t, report.Valid)
+
+	// The transaction is only checked
+	mockClient.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
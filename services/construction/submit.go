@@ -18,12 +18,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/coinbase/rosetta-geth-sdk/client"
 	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // ConstructionSubmit implements /construction/submit endpoint.
@@ -44,23 +48,150 @@ func (s *APIService) ConstructionSubmit(
 		)
 	}
 
-	var wrappedTx client.SignedTransactionWrapper
-	if err := json.Unmarshal([]byte(req.SignedTransaction), &wrappedTx); err != nil {
+	if s.config.RosettaCfg.SubmitValidateOnly {
+		return s.validateSubmission(ctx, req.SignedTransaction)
+	}
+
+	if rawTx, ok := rawCIP64Transaction(req.SignedTransaction); ok {
+		return s.submitRawTransaction(ctx, rawTx)
+	}
+
+	signedTx, err := unmarshalSignedTransaction(req.SignedTransaction)
+	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	var signedTx EthTypes.Transaction
-	if err := signedTx.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
+	metadata, err := deploymentMetadata(signedTx)
+	if err != nil {
 		return nil, sdkTypes.WrapErr(sdkTypes.ErrInvalidInput, err)
 	}
 
-	if err := s.client.Submit(ctx, &signedTx); err != nil {
-		return nil, sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
+	known := false
+	if s.config.RosettaCfg.CheckSubmittedTransactions {
+		known, err = s.transactionKnown(ctx, signedTx.Hash())
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+		}
+	}
+
+	if !known {
+		if err := s.client.Submit(ctx, signedTx); err != nil {
+			// Resubmitting a transaction in the transaction pool is not a failure
+			if rosettaErr := SubmitError(err); rosettaErr.Code != sdkTypes.ErrAlreadyKnown.Code {
+				return nil, rosettaErr
+			}
+		}
 	}
 
 	return &types.TransactionIdentifierResponse{
 		TransactionIdentifier: &types.TransactionIdentifier{
 			Hash: signedTx.Hash().String(),
 		},
+		Metadata: metadata,
 	}, nil
 }
+
+// validateSubmission returns the SubmissionReport of a signed transaction in
+// the response metadata instead of submitting it
+func (s *APIService) validateSubmission(
+	ctx context.Context,
+	signedTransaction string,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	report, rosettaErr := s.CheckSignedTransaction(ctx, signedTransaction)
+	if rosettaErr != nil {
+		return nil, rosettaErr
+	}
+
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: report.Hash,
+		},
+		Metadata: map[string]interface{}{SubmissionReportMetadataKey: report},
+	}, nil
+}
+
+// unmarshalSignedTransaction decodes a signed transaction of /construction/combine
+func unmarshalSignedTransaction(signedTransaction string) (*EthTypes.Transaction, error) {
+	wrappedTx, err := client.UnmarshalSignedTransaction([]byte(signedTransaction))
+	if err != nil {
+		return nil, err
+	}
+
+	var signedTx EthTypes.Transaction
+	if err := signedTx.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
+		return nil, err
+	}
+
+	return &signedTx, nil
+}
+
+// submitRawTransaction submits the binary encoding of a signed transaction of
+// a type go-ethereum doesn't support, like CIP-64 transactions
+func (s *APIService) submitRawTransaction(
+	ctx context.Context,
+	rawTx []byte,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	hash := crypto.Keccak256Hash(rawTx)
+
+	known := false
+	if s.config.RosettaCfg.CheckSubmittedTransactions {
+		var err error
+		known, err = s.transactionKnown(ctx, hash)
+		if err != nil {
+			return nil, sdkTypes.WrapErr(sdkTypes.ErrGeth, err)
+		}
+	}
+
+	if !known {
+		if err := s.client.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(rawTx)); err != nil {
+			if rosettaErr := SubmitError(err); rosettaErr.Code != sdkTypes.ErrAlreadyKnown.Code {
+				return nil, rosettaErr
+			}
+		}
+	}
+
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: hash.String(),
+		},
+	}, nil
+}
+
+// submitErrorMessages maps the messages of transaction pool errors to Rosetta
+// errors. The messages are the ones of geth, and of other clients when they
+// differ.
+var submitErrorMessages = []struct {
+	message string
+	err     *types.Error
+}{
+	{"already known", sdkTypes.ErrAlreadyKnown},
+	{"known transaction", sdkTypes.ErrAlreadyKnown},
+	{"nonce too low", sdkTypes.ErrNonceTooLow},
+	{"replacement transaction underpriced", sdkTypes.ErrUnderpriced},
+	{"transaction underpriced", sdkTypes.ErrUnderpriced},
+}
+
+// SubmitError classifies an error of Client.Submit. Transaction pool errors
+// are mapped to ErrAlreadyKnown, ErrNonceTooLow or ErrUnderpriced, and other
+// errors to ErrInternalError.
+func SubmitError(err error) *types.Error {
+	message := strings.ToLower(err.Error())
+	for _, known := range submitErrorMessages {
+		if strings.Contains(message, known.message) {
+			return sdkTypes.WrapErr(known.err, err)
+		}
+	}
+
+	return sdkTypes.WrapErr(sdkTypes.ErrInternalError, err)
+}
+
+// transactionKnown returns whether the node knows the transaction with hash,
+// either in its transaction pool or in a block
+func (s *APIService) transactionKnown(ctx context.Context, hash common.Hash) (bool, error) {
+	var tx json.RawMessage
+	if err := s.client.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return false, fmt.Errorf("could not look up transaction %s: %w", hash, err)
+	}
+
+	return len(tx) > 0 && string(tx) != "null", nil
+}
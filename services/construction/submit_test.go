@@ -0,0 +1,133 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConstructionSubmit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signedTx, err := EthTypes.SignTx(
+		EthTypes.NewTransaction(1, common.HexToAddress(testingToAddress), big.NewInt(1), 21000, big.NewInt(1), nil),
+		EthTypes.NewEIP155Signer(big.NewInt(int64(ethRopstenChainID))),
+		key,
+	)
+	assert.NoError(t, err)
+	txJSON, err := signedTx.MarshalJSON()
+	assert.NoError(t, err)
+	payload, err := client.MarshalSignedTransaction(&client.SignedTransactionWrapper{SignedTransaction: txJSON})
+	assert.NoError(t, err)
+	hash := signedTx.Hash()
+
+	tests := map[string]struct {
+		check         bool
+		lookup        string
+		lookupErr     error
+		submitErr     error
+		submitted     bool
+		expectedError *types.Error
+	}{
+		"submitted": {
+			submitted: true,
+		},
+		"already known": {
+			submitErr: errors.New("already known"),
+			submitted: true,
+		},
+		"nonce too low": {
+			submitErr:     errors.New("nonce too low: address 0x1, tx: 1 state: 2"),
+			submitted:     true,
+			expectedError: templateError(AssetTypes.ErrNonceTooLow, "nonce too low: address 0x1, tx: 1 state: 2"),
+		},
+		"replacement underpriced": {
+			submitErr:     errors.New("replacement transaction underpriced"),
+			submitted:     true,
+			expectedError: templateError(AssetTypes.ErrUnderpriced, "replacement transaction underpriced"),
+		},
+		"other error": {
+			submitErr:     errors.New("insufficient funds for gas * price + value"),
+			submitted:     true,
+			expectedError: templateError(AssetTypes.ErrInternalError, "insufficient funds for gas * price + value"),
+		},
+		"unknown transaction": {
+			check:     true,
+			lookup:    "null",
+			submitted: true,
+		},
+		"known transaction": {
+			check:  true,
+			lookup: `{"hash":"` + hash.Hex() + `"}`,
+		},
+		"lookup error": {
+			check:     true,
+			lookupErr: errors.New("connection refused"),
+			expectedError: templateError(
+				AssetTypes.ErrGeth,
+				"could not look up transaction "+hash.Hex()+": connection refused",
+			),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testingClient := newTestingClient()
+			testingClient.cfg.RosettaCfg.CheckSubmittedTransactions = test.check
+			mockClient := testingClient.mockClient
+			ctx := context.Background()
+
+			if test.check {
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getTransactionByHash", hash).
+					Return(test.lookupErr).
+					Run(func(args mock.Arguments) {
+						if test.lookupErr == nil {
+							*args.Get(1).(*json.RawMessage) = json.RawMessage(test.lookup)
+						}
+					}).Once()
+			}
+			if test.submitted {
+				mockClient.On("Submit", ctx, mock.Anything).Return(test.submitErr).Once()
+			}
+
+			resp, rosettaErr := testingClient.servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+				NetworkIdentifier: ethereumNetworkIdentifier,
+				SignedTransaction: string(payload),
+			})
+			if test.expectedError != nil {
+				assert.Equal(t, test.expectedError, rosettaErr)
+			} else {
+				assert.Nil(t, rosettaErr)
+				assert.Equal(t, hash.Hex(), resp.TransactionIdentifier.Hash)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
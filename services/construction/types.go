@@ -72,12 +72,22 @@ type Client interface {
 	// GetRosettaConfig returns the Rosetta config we defined for the network
 	GetRosettaConfig() configuration.RosettaConfig
 
-	// GetBlockHash returns the block hash given block identifier
+	// GetBlockHash returns the block hash given block identifier. The block identifier
+	// hash is computed from the block header, which doesn't match the hash the node
+	// reports on chains whose headers were migrated or rewritten, e.g. OP stack blocks
+	// from before the bedrock upgrade. Clients can override it to normalize these
+	// hashes. SDKClient handles OP stack chains when RosettaConfig.BedrockBlock is set.
 	GetBlockHash(ctx context.Context, blockIdentifier RosettaTypes.BlockIdentifier) (string, error)
 
 	// SkipTxReceiptParsing determines if the tx receipt parsing can be skipped for specific contract address
+	// Fixed addresses can be skipped with the SkipLogs of RosettaConfig.AddressPolicies instead
 	SkipTxReceiptParsing(contractAddress string) bool
 
+	// HashOverride returns the hash of a signed transaction, given in its canonical
+	// binary encoding, for networks with modified transaction hashing. It returns
+	// false when the standard keccak256 hash of the encoding should be used.
+	HashOverride(ctx context.Context, rawTx []byte) (string, bool, error)
+
 	// GetCustomizedBlockBody returns the customized block body
 	GetCustomizedBlockBody(raw json.RawMessage, body *evmClient.RPCBlock) error
 
@@ -199,6 +209,28 @@ type Client interface {
 		data []byte,
 	) (uint64, error)
 
+	// GetRawBlock returns the RLP encoding of a block and of its header
+	GetRawBlock(
+		ctx context.Context,
+		blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+	) (*evmClient.RawBlock, error)
+
+	// GetPendingTransactionsForSender returns the transactions of a sender in the
+	// transaction pool, with the nonce gaps keeping them queued
+	GetPendingTransactionsForSender(
+		ctx context.Context,
+		address common.Address,
+	) (*evmClient.SenderPoolTransactions, error)
+
+	// GetContractDeploymentGasLimit returns the estimated gas limit for a contract deployment
+	// with the given init code. This method is used by Rosetta construction/metadata api
+	GetContractDeploymentGasLimit(
+		ctx context.Context,
+		fromAddress string,
+		value *big.Int,
+		data []byte,
+	) (uint64, error)
+
 	// ParseOps returns a list of operations
 	ParseOps(
 		tx *evmClient.LoadedTransaction,
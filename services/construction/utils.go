@@ -15,10 +15,16 @@
 package construction
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/coinbase/rosetta-geth-sdk/client"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func EthTransaction(tx *client.Transaction) *types.Transaction {
@@ -31,13 +37,25 @@ func EthTransaction(tx *client.Transaction) *types.Transaction {
 	eip1559Tx := tx.GasTipCap != nil && tx.GasFeeCap != nil
 	if eip1559Tx {
 		return types.NewTx(&types.DynamicFeeTx{
-			Nonce:     tx.Nonce,
-			GasTipCap: tx.GasTipCap,
-			GasFeeCap: tx.GasFeeCap,
-			Gas:       tx.GasLimit,
-			To:        to,
-			Value:     tx.Value,
-			Data:      tx.Data,
+			Nonce:      tx.Nonce,
+			GasTipCap:  tx.GasTipCap,
+			GasFeeCap:  tx.GasFeeCap,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
+		})
+	} else if len(tx.AccessList) > 0 {
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainID,
+			Nonce:      tx.Nonce,
+			GasPrice:   tx.GasPrice,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
 		})
 	} else {
 		return types.NewTx(&types.LegacyTx{
@@ -50,3 +68,58 @@ func EthTransaction(tx *client.Transaction) *types.Transaction {
 		})
 	}
 }
+
+// signedTransactionBytes returns the canonical binary encoding of a signed
+// transaction, given either as the SDK's signed transaction wrapper or as a
+// raw RLP encoded transaction in hex.
+func signedTransactionBytes(signedTx string) ([]byte, error) {
+	if !strings.HasPrefix(signedTx, "0x") {
+		wrappedTx, err := client.UnmarshalSignedTransaction([]byte(signedTx))
+		if err != nil {
+			return nil, err
+		}
+
+		var tx types.Transaction
+		if err := tx.UnmarshalJSON(wrappedTx.SignedTransaction); err != nil {
+			return nil, err
+		}
+
+		return tx.MarshalBinary()
+	}
+
+	rawTx, err := hexutil.Decode(signedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx types.Transaction
+	err = tx.UnmarshalBinary(rawTx)
+	if errors.Is(err, types.ErrTxTypeNotSupported) {
+		// Typed transactions unknown to go-ethereum (e.g. EIP-7702 set code
+		// transactions) are still hashed over their typed envelope
+		err = validateTypedTransactionEnvelope(rawTx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rawTx, nil
+}
+
+// validateTypedTransactionEnvelope checks that rawTx is an EIP-2718
+// transaction type followed by a single RLP list.
+func validateTypedTransactionEnvelope(rawTx []byte) error {
+	if len(rawTx) == 0 || rawTx[0] > 0x7f { // nolint:gomnd
+		return errors.New("invalid typed transaction")
+	}
+
+	kind, _, rest, err := rlp.Split(rawTx[1:])
+	if err != nil {
+		return err
+	}
+	if kind != rlp.List || len(rest) != 0 {
+		return fmt.Errorf("invalid payload for transaction type %d", rawTx[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,410 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// vectorToken is the ERC20 token of the default vectors of configurations
+// without a token white list
+var vectorToken = configuration.Token{
+	Address:  "0x1E77ad77925Ac0075CF61Fb76bA35D884985019d",
+	Symbol:   "USDC",
+	Decimals: 6, // nolint:gomnd
+}
+
+// vectorInitCode is the init code of the contract deployed by the default
+// vectors, which returns 42 from any call
+var vectorInitCode = hexutil.MustDecode("0x600a600c600039600a6000f3602a60005260206000f3")
+
+// VectorSpec is the intent of the transaction of a construction test vector
+type VectorSpec struct {
+	Name       string
+	Operations []*types.Operation
+	Metadata   *client.Metadata
+}
+
+// TestVector is a construction test vector: a transaction constructed, signed,
+// hashed and parsed with the construction endpoints. Vectors are regression
+// tests of the endpoints, see CheckVector.
+type TestVector struct {
+	Name                string                   `json:"name"`
+	NetworkIdentifier   *types.NetworkIdentifier `json:"network_identifier"`
+	Operations          []*types.Operation       `json:"operations"`
+	Metadata            map[string]interface{}   `json:"metadata"`
+	UnsignedTransaction string                   `json:"unsigned_transaction"`
+	Payloads            []*types.SigningPayload  `json:"payloads"`
+	Signatures          []*types.Signature       `json:"signatures"`
+	SignedTransaction   string                   `json:"signed_transaction"`
+	TransactionHash     string                   `json:"transaction_hash"`
+
+	// ParsedOperations are the operations parsed from the unsigned and the
+	// signed transaction
+	ParsedOperations []*types.Operation `json:"parsed_operations"`
+}
+
+// DefaultVectorSpecs returns the specs of the test vectors of the transaction
+// types the construction endpoints support: native currency transfers with
+// legacy, EIP-1559 and EIP-2930 fees, ERC20 transfers, a generic contract call
+// and a contract deployment, from from to to. ERC20 transfers are of the first
+// token of the white list of cfg, or of an example USDC token.
+func DefaultVectorSpecs(cfg *configuration.Configuration, from common.Address, to common.Address) ([]*VectorSpec, error) {
+	native := cfg.RosettaCfg.Currency
+	token := vectorToken
+	if len(cfg.RosettaCfg.TokenWhiteList) > 0 {
+		token = cfg.RosettaCfg.TokenWhiteList[0]
+	}
+	erc20 := client.Erc20Currency(token.Symbol, int32(token.Decimals), common.HexToAddress(token.Address).Hex())
+
+	approveArgs := []interface{}{to.Hex(), "1000"}
+	approveData, err := ConstructContractCallDataGeneric("approve(address,uint256)", approveArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := big.NewInt(5000000000)   // nolint:gomnd
+	gasTipCap := big.NewInt(1500000000)  // nolint:gomnd
+	gasFeeCap := big.NewInt(30000000000) // nolint:gomnd
+	legacy := func(gasLimit uint64) *client.Metadata {
+		return &client.Metadata{Nonce: 67, GasPrice: gasPrice, GasLimit: gasLimit} // nolint:gomnd
+	}
+	dynamic := func(gasLimit uint64) *client.Metadata {
+		return &client.Metadata{Nonce: 67, GasTipCap: gasTipCap, GasFeeCap: gasFeeCap, GasLimit: gasLimit} // nolint:gomnd
+	}
+
+	accessList := legacy(30000) // nolint:gomnd
+	accessList.AccessList = EthTypes.AccessList{{
+		Address:     to,
+		StorageKeys: []common.Hash{common.BigToHash(big.NewInt(1))},
+	}}
+
+	contractCall := legacy(50000) // nolint:gomnd
+	contractCall.ContractData = hexutil.Encode(approveData)
+	contractCall.MethodSignature = "approve(address,uint256)"
+	contractCall.MethodArgs = approveArgs
+
+	deployment := dynamic(100000) // nolint:gomnd
+	deployment.ContractData = hexutil.Encode(vectorInitCode)
+
+	return []*VectorSpec{
+		{
+			Name:       "legacy native transfer",
+			Operations: transferOperations(from, to, big.NewInt(1), native, sdkTypes.CallOpType),
+			Metadata:   legacy(21000), // nolint:gomnd
+		},
+		{
+			Name:       "eip1559 native transfer",
+			Operations: transferOperations(from, to, big.NewInt(1), native, sdkTypes.CallOpType),
+			Metadata:   dynamic(21000), // nolint:gomnd
+		},
+		{
+			Name:       "eip2930 native transfer",
+			Operations: transferOperations(from, to, big.NewInt(1), native, sdkTypes.CallOpType),
+			Metadata:   accessList,
+		},
+		{
+			Name:       "legacy erc20 transfer",
+			Operations: transferOperations(from, to, big.NewInt(1), erc20, sdkTypes.OpErc20Transfer),
+			Metadata:   legacy(65000), // nolint:gomnd
+		},
+		{
+			Name:       "eip1559 erc20 transfer",
+			Operations: transferOperations(from, to, big.NewInt(1), erc20, sdkTypes.OpErc20Transfer),
+			Metadata:   dynamic(65000), // nolint:gomnd
+		},
+		{
+			Name:       "contract call",
+			Operations: transferOperations(from, to, big.NewInt(1), native, sdkTypes.CallOpType),
+			Metadata:   contractCall,
+		},
+		{
+			Name: "contract deployment",
+			Operations: []*types.Operation{{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Type:                sdkTypes.DeployOpType,
+				Account:             &types.AccountIdentifier{Address: from.Hex()},
+				Amount:              &types.Amount{Value: "0", Currency: native},
+			}},
+			Metadata: deployment,
+		},
+	}, nil
+}
+
+// GenerateVectors constructs the transactions of specs with the /construction
+// payloads, combine, hash and parse endpoints of s, signing them with key
+func GenerateVectors(
+	ctx context.Context,
+	s *APIService,
+	key *ecdsa.PrivateKey,
+	specs []*VectorSpec,
+) ([]*TestVector, error) {
+	vectors := make([]*TestVector, 0, len(specs))
+	for _, spec := range specs {
+		vector, err := generateVector(ctx, s, key, spec)
+		if err != nil {
+			return nil, fmt.Errorf("vector %q: %w", spec.Name, err)
+		}
+		vectors = append(vectors, vector)
+	}
+
+	return vectors, nil
+}
+
+func generateVector(ctx context.Context, s *APIService, key *ecdsa.PrivateKey, spec *VectorSpec) (*TestVector, error) {
+	metadata, err := client.MarshalJSONMap(spec.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	vector := &TestVector{
+		Name:              spec.Name,
+		NetworkIdentifier: s.config.Network,
+		Operations:        spec.Operations,
+		Metadata:          metadata,
+	}
+
+	payloads, rosettaErr := s.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: vector.NetworkIdentifier,
+		Operations:        vector.Operations,
+		Metadata:          vector.Metadata,
+	})
+	if rosettaErr != nil {
+		return nil, vectorError("payloads", rosettaErr)
+	}
+	vector.UnsignedTransaction = payloads.UnsignedTransaction
+	vector.Payloads = payloads.Payloads
+
+	publicKey := &types.PublicKey{
+		Bytes:     crypto.CompressPubkey(&key.PublicKey),
+		CurveType: types.Secp256k1,
+	}
+	for _, payload := range payloads.Payloads {
+		signature, err := crypto.Sign(payload.Bytes, key)
+		if err != nil {
+			return nil, err
+		}
+		vector.Signatures = append(vector.Signatures, &types.Signature{
+			SigningPayload: payload,
+			PublicKey:      publicKey,
+			SignatureType:  types.EcdsaRecovery,
+			Bytes:          signature,
+		})
+	}
+
+	combined, rosettaErr := s.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   vector.NetworkIdentifier,
+		UnsignedTransaction: vector.UnsignedTransaction,
+		Signatures:          vector.Signatures,
+	})
+	if rosettaErr != nil {
+		return nil, vectorError("combine", rosettaErr)
+	}
+	vector.SignedTransaction = combined.SignedTransaction
+
+	hash, rosettaErr := s.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: vector.NetworkIdentifier,
+		SignedTransaction: vector.SignedTransaction,
+	})
+	if rosettaErr != nil {
+		return nil, vectorError("hash", rosettaErr)
+	}
+	vector.TransactionHash = hash.TransactionIdentifier.Hash
+
+	parsed, rosettaErr := s.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: vector.NetworkIdentifier,
+		Signed:            true,
+		Transaction:       vector.SignedTransaction,
+	})
+	if rosettaErr != nil {
+		return nil, vectorError("parse", rosettaErr)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	if len(parsed.AccountIdentifierSigners) != 1 || parsed.AccountIdentifierSigners[0].Address != sender {
+		return nil, fmt.Errorf("signed transaction is not signed by %s", sender)
+	}
+	vector.ParsedOperations = parsed.Operations
+
+	// The vector is round tripped through JSON, like the vectors loaded by
+	// LoadVectors, so generated and loaded vectors are equal
+	raw, err := json.Marshal(vector)
+	if err != nil {
+		return nil, err
+	}
+	var decoded TestVector
+	if err := decodeVectors(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	return &decoded, CheckVector(ctx, s, &decoded)
+}
+
+// CheckVector checks that the construction endpoints of s still construct,
+// hash and parse the transaction of vector like when it was generated
+func CheckVector(ctx context.Context, s *APIService, vector *TestVector) error {
+	payloads, rosettaErr := s.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: vector.NetworkIdentifier,
+		Operations:        vector.Operations,
+		Metadata:          vector.Metadata,
+	})
+	if rosettaErr != nil {
+		return vectorError("payloads", rosettaErr)
+	}
+	if payloads.UnsignedTransaction != vector.UnsignedTransaction {
+		return fmt.Errorf("payloads: unsigned transaction %s, expected %s", payloads.UnsignedTransaction, vector.UnsignedTransaction)
+	}
+	if err := checkVectorField("payloads", "payloads", payloads.Payloads, vector.Payloads); err != nil {
+		return err
+	}
+
+	combined, rosettaErr := s.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   vector.NetworkIdentifier,
+		UnsignedTransaction: vector.UnsignedTransaction,
+		Signatures:          vector.Signatures,
+	})
+	if rosettaErr != nil {
+		return vectorError("combine", rosettaErr)
+	}
+	if combined.SignedTransaction != vector.SignedTransaction {
+		return fmt.Errorf("combine: signed transaction %s, expected %s", combined.SignedTransaction, vector.SignedTransaction)
+	}
+
+	hash, rosettaErr := s.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: vector.NetworkIdentifier,
+		SignedTransaction: vector.SignedTransaction,
+	})
+	if rosettaErr != nil {
+		return vectorError("hash", rosettaErr)
+	}
+	if hash.TransactionIdentifier.Hash != vector.TransactionHash {
+		return fmt.Errorf("hash: transaction hash %s, expected %s", hash.TransactionIdentifier.Hash, vector.TransactionHash)
+	}
+
+	for _, signed := range []bool{false, true} {
+		transaction := vector.UnsignedTransaction
+		if signed {
+			transaction = vector.SignedTransaction
+		}
+		parsed, rosettaErr := s.ConstructionParse(ctx, &types.ConstructionParseRequest{
+			NetworkIdentifier: vector.NetworkIdentifier,
+			Signed:            signed,
+			Transaction:       transaction,
+		})
+		if rosettaErr != nil {
+			return vectorError("parse", rosettaErr)
+		}
+		if err := checkVectorField("parse", "operations", parsed.Operations, vector.ParsedOperations); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveVectors writes vectors to path as indented JSON
+func SaveVectors(path string, vectors []*TestVector) error {
+	raw, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(raw, '\n'), 0o644) // nolint:gosec
+}
+
+// LoadVectors reads the vectors written to path by SaveVectors
+func LoadVectors(path string) ([]*TestVector, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var vectors []*TestVector
+	if err := decodeVectors(raw, &vectors); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+
+	return vectors, nil
+}
+
+// decodeVectors decodes raw into v keeping the precision of the numbers of the
+// metadata, like requests decoded by the server
+func decodeVectors(raw []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// checkVectorField checks that the field of the response of endpoint is the
+// expected one, comparing their JSON encodings
+func checkVectorField(endpoint string, field string, got interface{}, expected interface{}) error {
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		return err
+	}
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(gotJSON, expectedJSON) {
+		return fmt.Errorf("%s: %s %s, expected %s", endpoint, field, gotJSON, expectedJSON)
+	}
+
+	return nil
+}
+
+// vectorError returns the error of endpoint as an error
+func vectorError(endpoint string, rosettaErr *types.Error) error {
+	return fmt.Errorf("%s: %s: %v", endpoint, rosettaErr.Message, rosettaErr.Details)
+}
+
+// transferOperations returns the operations transferring value in currency
+// from from to to
+func transferOperations(
+	from common.Address,
+	to common.Address,
+	value *big.Int,
+	currency *types.Currency,
+	opType string,
+) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                opType,
+			Account:             &types.AccountIdentifier{Address: from.Hex()},
+			Amount:              &types.Amount{Value: new(big.Int).Neg(value).String(), Currency: currency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                opType,
+			Account:             &types.AccountIdentifier{Address: to.Hex()},
+			Amount:              &types.Amount{Value: value.String(), Currency: currency},
+		},
+	}
+}
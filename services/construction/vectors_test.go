@@ -0,0 +1,90 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package construction
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vectorsPath are the vectors generated with
+//
+//	go run ./cmd/construction-vectors \
+//		-config services/construction/testdata/vectors_config.json \
+//		-out services/construction/testdata/vectors.json
+const vectorsPath = "testdata/vectors.json"
+
+func vectorsService(t *testing.T) *APIService {
+	raw, err := os.ReadFile("testdata/vectors_config.json")
+	require.NoError(t, err)
+	var cfg configuration.Configuration
+	require.NoError(t, json.Unmarshal(raw, &cfg))
+
+	return NewAPIService(&cfg, AssetTypes.LoadTypes(), AssetTypes.Errors, &client.SDKClient{})
+}
+
+func TestVectors(t *testing.T) {
+	service := vectorsService(t)
+	vectors, err := LoadVectors(vectorsPath)
+	require.NoError(t, err)
+	assert.Len(t, vectors, 7)
+
+	for _, vector := range vectors {
+		t.Run(vector.Name, func(t *testing.T) {
+			assert.NoError(t, CheckVector(context.Background(), service, vector))
+		})
+	}
+
+	// Changes of the endpoints are detected
+	tampered := *vectors[0]
+	tampered.TransactionHash = common.Hash{}.Hex()
+	assert.ErrorContains(t, CheckVector(context.Background(), service, &tampered), "hash: transaction hash")
+}
+
+func TestGenerateVectors(t *testing.T) {
+	service := vectorsService(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress(testingToAddress)
+
+	specs, err := DefaultVectorSpecs(service.config, crypto.PubkeyToAddress(key.PublicKey), to)
+	require.NoError(t, err)
+	vectors, err := GenerateVectors(context.Background(), service, key, specs)
+	require.NoError(t, err)
+	require.Len(t, vectors, len(specs))
+
+	// Saved vectors are loaded as generated
+	path := t.TempDir() + "/vectors.json"
+	require.NoError(t, SaveVectors(path, vectors))
+	loaded, err := LoadVectors(path)
+	assert.NoError(t, err)
+	assert.Equal(t, vectors, loaded)
+
+	// Vectors of invalid specs are not generated
+	specs[0].Metadata.ChainID = common.Big1
+	_, err = GenerateVectors(context.Background(), service, key, specs)
+	assert.ErrorContains(t, err, `vector "legacy native transfer": payloads`)
+}
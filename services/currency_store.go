@@ -0,0 +1,110 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// currencyKeyPrefix is the prefix of the keys of stored currencies, followed
+// by the contract address
+var currencyKeyPrefix = []byte("currency-")
+
+// StoredCurrency is a contract currency and the time it was fetched from the
+// node
+type StoredCurrency struct {
+	Currency  *client.ContractCurrency `json:"currency"`
+	FetchedAt time.Time                `json:"fetched_at"`
+}
+
+// CurrencyStore persists the contract currencies fetched from the node, so
+// that they are not fetched again after a restart, see
+// RosettaConfig.CurrencyStorePath.
+type CurrencyStore interface {
+	// GetCurrency returns the stored currency of the contract address, or nil
+	// if it has none
+	GetCurrency(address common.Address) (*StoredCurrency, error)
+
+	// PutCurrency stores the currency of the contract address
+	PutCurrency(address common.Address, currency *StoredCurrency) error
+
+	// Close releases the store
+	Close() error
+}
+
+// LevelDBCurrencyStore is a CurrencyStore backed by a LevelDB database
+type LevelDBCurrencyStore struct {
+	db *leveldb.DB
+}
+
+// OpenLevelDBCurrencyStore opens the LevelDB currency store in the directory
+// path, creating it if it doesn't exist
+func OpenLevelDBCurrencyStore(path string) (*LevelDBCurrencyStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not open currency store %s: %w", path, err)
+	}
+
+	return &LevelDBCurrencyStore{db: db}, nil
+}
+
+// GetCurrency returns the stored currency of the contract address, or nil if
+// it has none
+func (s *LevelDBCurrencyStore) GetCurrency(address common.Address) (*StoredCurrency, error) {
+	data, err := s.db.Get(currencyKey(address), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored StoredCurrency
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("could not decode stored currency of %s: %w", address, err)
+	}
+	if stored.Currency == nil {
+		return nil, fmt.Errorf("stored currency of %s is empty", address)
+	}
+
+	return &stored, nil
+}
+
+// PutCurrency stores the currency of the contract address
+func (s *LevelDBCurrencyStore) PutCurrency(address common.Address, currency *StoredCurrency) error {
+	data, err := json.Marshal(currency)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Put(currencyKey(address), data, nil)
+}
+
+// Close closes the database
+func (s *LevelDBCurrencyStore) Close() error {
+	return s.db.Close()
+}
+
+func currencyKey(address common.Address) []byte {
+	return append(append([]byte{}, currencyKeyPrefix...), address.Bytes()...)
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelDBCurrencyStore(t *testing.T) {
+	path := t.TempDir()
+	address := common.HexToAddress("0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b")
+	stored := &StoredCurrency{
+		Currency:  &client.ContractCurrency{Symbol: "USDC", Decimals: 6},
+		FetchedAt: time.Unix(1700000000, 0).UTC(),
+	}
+
+	store, err := OpenLevelDBCurrencyStore(path)
+	assert.NoError(t, err)
+	currency, err := store.GetCurrency(address)
+	assert.NoError(t, err)
+	assert.Nil(t, currency)
+	assert.NoError(t, store.PutCurrency(address, stored))
+	assert.NoError(t, store.Close())
+
+	// The currency is kept across restarts
+	store, err = OpenLevelDBCurrencyStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	currency, err = store.GetCurrency(address)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, currency)
+}
+
+func TestBlockAPIService_CloseCurrencyStore(t *testing.T) {
+	path := t.TempDir()
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOnline,
+		RosettaCfg: configuration.RosettaConfig{CurrencyStorePath: path},
+	}
+	servicer := NewBlockAPIService(cfg, &mockedServices.Client{})
+	assert.NoError(t, servicer.Close())
+
+	// The database is released
+	store, err := OpenLevelDBCurrencyStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Close())
+}
+
+func TestGetCurrencyFromNodeOrCache_CurrencyStore(t *testing.T) {
+	address := common.HexToAddress("0x4DBCdF9B62e891a7cec5A2568C3F4FAF9E8Abe2b")
+	usdc := &client.ContractCurrency{Symbol: "USDC", Decimals: 6}
+	stale := &client.ContractCurrency{Symbol: "USDC.e", Decimals: 6}
+	unknown := &client.ContractCurrency{Symbol: client.UnknownERC20Symbol, Decimals: 0}
+
+	tests := map[string]struct {
+		ttl        time.Duration
+		stored     *StoredCurrency
+		nodeResult *client.ContractCurrency
+		nodeError  error
+
+		expectedCurrency *client.ContractCurrency
+		expectedStored   *client.ContractCurrency
+		expectedError    string
+	}{
+		"not stored": {
+			nodeResult:       usdc,
+			expectedCurrency: usdc,
+			expectedStored:   usdc,
+		},
+		"fresh": {
+			ttl:              time.Hour,
+			stored:           &StoredCurrency{Currency: usdc, FetchedAt: time.Now()},
+			expectedCurrency: usdc,
+			expectedStored:   usdc,
+		},
+		"no ttl": {
+			stored:           &StoredCurrency{Currency: usdc, FetchedAt: time.Unix(0, 0)},
+			expectedCurrency: usdc,
+			expectedStored:   usdc,
+		},
+		"expired": {
+			ttl:              time.Hour,
+			stored:           &StoredCurrency{Currency: stale, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			nodeResult:       usdc,
+			expectedCurrency: usdc,
+			expectedStored:   usdc,
+		},
+		"expired and node error": {
+			ttl:              time.Hour,
+			stored:           &StoredCurrency{Currency: stale, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			nodeError:        errors.New("node unavailable"),
+			expectedCurrency: stale,
+			expectedStored:   stale,
+		},
+		"node error": {
+			nodeError:     errors.New("node unavailable"),
+			expectedError: "node unavailable",
+		},
+		"unknown currency": {
+			nodeResult:       unknown,
+			expectedCurrency: unknown,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &configuration.Configuration{
+				Mode:       configuration.ModeOnline,
+				RosettaCfg: configuration.RosettaConfig{CurrencyStoreTTL: test.ttl},
+			}
+			mockClient := &mockedServices.Client{}
+			servicer := NewBlockAPIService(cfg, mockClient)
+			store, err := OpenLevelDBCurrencyStore(t.TempDir())
+			assert.NoError(t, err)
+			defer store.Close()
+			servicer.currencyStore = store
+			if test.stored != nil {
+				assert.NoError(t, store.PutCurrency(address, test.stored))
+			}
+
+			if test.nodeResult != nil || test.nodeError != nil {
+				mockClient.On("GetContractCurrency", address, true).Return(
+					test.nodeResult,
+					test.nodeError,
+				).Once()
+			}
+
+			currency, err := servicer.getCurrencyFromNodeOrCache(context.Background(), address, address.Hex())
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedCurrency, currency)
+			}
+
+			stored, err := store.GetCurrency(address)
+			assert.NoError(t, err)
+			if test.expectedStored == nil {
+				assert.Nil(t, stored)
+			} else {
+				assert.Equal(t, test.expectedStored, stored.Currency)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -16,9 +16,12 @@ package services
 
 import (
 	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
 
+	"fmt"
 	"log"
 	"math/big"
 	"strings"
@@ -32,31 +35,33 @@ const (
 	zeroAddress                      = "0x0000000000000000000000000000000000000000000000000000000000000000"
 )
 
-func parseTransferOps(startIndex int, transfers []*evmClient.EVMTransfer, addrs map[string]*RosettaTypes.Operation) (
-	[]*RosettaTypes.Operation, map[string]*RosettaTypes.Operation) {
-	count := startIndex
-	var ops []*RosettaTypes.Operation
+func parseTransferOps(
+	b *OperationBuilder,
+	transfers []*evmClient.EVMTransfer,
+	addrs map[string]*RosettaTypes.Operation,
+	currency *RosettaTypes.Currency,
+) bool {
 	for _, transfer := range transfers {
 		var address string
 		var key string
 		amount := transfer.Value
-		if amount.Uint64() == 0 {
+		if amount == nil || amount.Sign() == 0 || (transfer.From == nil && transfer.To == nil) {
 			continue
 		}
 		shouldAdd := true
 		if transfer.From == nil {
-			address = transfer.To.String()
+			address = evmClient.FormatAddress(*transfer.To)
 			shouldAdd = false
 			key = address
 		} else if transfer.To == nil {
-			address = transfer.From.String()
+			address = evmClient.FormatAddress(*transfer.From)
 			amount = new(big.Int).Neg(transfer.Value)
 			shouldAdd = false
 			key = address
 		}
 
 		if shouldAdd {
-			address = transfer.From.String()
+			address = evmClient.FormatAddress(*transfer.From)
 			amount = new(big.Int).Neg(transfer.Value)
 			key = transfer.From.String() + transfer.To.String()
 		}
@@ -67,7 +72,7 @@ func parseTransferOps(startIndex int, transfers []*evmClient.EVMTransfer, addrs
 			amt, ok := amt.SetString(val.Amount.Value, 10) // nolint:gomnd
 			if !ok {
 				log.Println("error consolidating transfer data")
-				return nil, nil
+				return false
 			}
 			newAmt := amt.Add(amt, amount)
 			addrs[key].Amount.Value = newAmt.String()
@@ -77,60 +82,166 @@ func parseTransferOps(startIndex int, transfers []*evmClient.EVMTransfer, addrs
 			continue
 		}
 
-		singleOp := &RosettaTypes.Operation{
-			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: int64(count),
-			},
+		singleOp := b.Add(&RosettaTypes.Operation{
 			Type:   sdkTypes.FeeOpType,
 			Status: RosettaTypes.String(sdkTypes.SuccessStatus),
 			Account: &RosettaTypes.AccountIdentifier{
 				Address: address,
 			},
-			Amount: evmClient.Amount(amount, sdkTypes.Currency),
-		}
+			Amount: evmClient.Amount(amount, currency),
+		})
 		addrs[key] = singleOp
-		ops = append(ops, singleOp)
 
 		if shouldAdd {
-			doubleOp := &RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: int64(count + 1),
-				},
-				RelatedOperations: []*RosettaTypes.OperationIdentifier{
-					{
-						Index: int64(count),
-					},
-				},
+			doubleOp := b.Add(&RosettaTypes.Operation{
 				Type:   sdkTypes.FeeOpType,
 				Status: RosettaTypes.String(sdkTypes.SuccessStatus),
 				Account: &RosettaTypes.AccountIdentifier{
-					Address: transfer.To.String(),
+					Address: evmClient.FormatAddress(*transfer.To),
 				},
-				Amount: evmClient.Amount(transfer.Value, sdkTypes.Currency),
-			}
+				Amount: evmClient.Amount(transfer.Value, currency),
+			}, singleOp)
 			doubleKey := transfer.To.String() + transfer.From.String()
 			addrs[doubleKey] = doubleOp
-			ops = append(ops, doubleOp)
-			count++
 		}
-		count++
 	}
-	return ops, addrs
+	return true
 }
 
+// TransferOps returns the fee operations of the EVM transfer annotations of
+// the traces of tx, the beforeEVMTransfers and afterEVMTransfers of Arbitrum
+// Nitro call traces. A transfer from nil mints and a transfer to nil burns;
+// transfers between the same accounts are consolidated. Operations are indexed
+// from startIndex.
 func TransferOps(tx *evmClient.LoadedTransaction, startIndex int) []*RosettaTypes.Operation {
-	var ops []*RosettaTypes.Operation
+	b := NewOperationBuilder(int64(startIndex))
 	addrMap := make(map[string]*RosettaTypes.Operation)
+	currency := feeCurrency(tx)
 	for _, trace := range tx.Trace {
-		beforeOps, addrMap := parseTransferOps(startIndex+len(ops), trace.BeforeEVMTransfers, addrMap)
-		ops = append(ops, beforeOps...)
-		afterOps, _ := parseTransferOps(startIndex+len(ops), trace.AfterEVMTransfers, addrMap)
-		ops = append(ops, afterOps...)
+		if !parseTransferOps(b, trace.BeforeEVMTransfers, addrMap, currency) ||
+			!parseTransferOps(b, trace.AfterEVMTransfers, addrMap, currency) {
+			break
+		}
+	}
+	return b.Operations()
+}
+
+// ParseFeeOps returns the fee operations of tx from the operation source of
+// cfg: the EVM transfer annotations of its traces when
+// RosettaConfig.UseEVMTransferAnnotations is set, see TransferOps, the fee
+// vaults of OP stack chains when RosettaConfig.SupportsOpStack is set, see
+// OPStackFeeOps, and its receipt otherwise, see FeeOps. Clients call it from
+// ParseOps.
+func ParseFeeOps(
+	tx *evmClient.LoadedTransaction,
+	cfg configuration.RosettaConfig,
+) ([]*RosettaTypes.Operation, error) {
+	switch {
+	case cfg.UseEVMTransferAnnotations:
+		return TransferOps(tx, 0), nil
+	case cfg.SupportsOpStack:
+		return OPStackFeeOps(tx)
+	}
+
+	return FeeOps(tx)
+}
+
+// OPStackFeeOps returns the fee operations of tx on an OP stack chain. The
+// sender pays the fee, of which the base fee in tx.FeeBurned is paid to the
+// BaseFeeVault, the L1 data fee of the receipt to the L1FeeVault and the rest
+// to the SequencerFeeVault. Deposit transactions pay no fee, their minted value
+// is credited to the sender with a sdkTypes.BridgeDepositOpType operation.
+func OPStackFeeOps(tx *evmClient.LoadedTransaction) ([]*RosettaTypes.Operation, error) {
+	if evmClient.IsDepositTransaction(tx) {
+		return depositMintOps(tx), nil
+	}
+	if tx.FeeAmount == nil || tx.FeeAmount.Sign() == 0 {
+		return nil, nil
+	}
+
+	baseFee := big.NewInt(0)
+	if tx.FeeBurned != nil {
+		baseFee = tx.FeeBurned
+	}
+	l1Fee := big.NewInt(0)
+	if tx.Receipt != nil && tx.Receipt.L1Fee != nil {
+		l1Fee = tx.Receipt.L1Fee
+	}
+	sequencerFee := new(big.Int).Sub(tx.FeeAmount, baseFee)
+	sequencerFee.Sub(sequencerFee, l1Fee)
+	if sequencerFee.Sign() < 0 {
+		return nil, fmt.Errorf(
+			"fee %s of %s is less than its base fee %s and L1 fee %s",
+			tx.FeeAmount,
+			tx.TxHash,
+			baseFee,
+			l1Fee,
+		)
+	}
+
+	currency := feeCurrency(tx)
+	b := NewOperationBuilder(0)
+	payerOp := b.Add(&RosettaTypes.Operation{
+		Type:    sdkTypes.FeeOpType,
+		Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account: evmClient.Account(tx.From),
+		Amount:  evmClient.Amount(new(big.Int).Neg(tx.FeeAmount), currency),
+	})
+	for _, share := range []struct {
+		vault  common.Address
+		amount *big.Int
+	}{
+		{evmClient.SequencerFeeVault, sequencerFee},
+		{evmClient.BaseFeeVault, baseFee},
+		{evmClient.L1FeeVault, l1Fee},
+	} {
+		if share.amount.Sign() == 0 {
+			continue
+		}
+		vault := share.vault
+		b.Add(&RosettaTypes.Operation{
+			Type:    sdkTypes.FeeOpType,
+			Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
+			Account: evmClient.Account(&vault),
+			Amount:  evmClient.Amount(share.amount, currency),
+		}, payerOp)
 	}
-	return ops
+
+	return b.Operations(), nil
 }
 
-func FeeOps(tx *evmClient.LoadedTransaction) []*RosettaTypes.Operation {
+// depositMintOps returns the operation crediting the value minted by the OP
+// stack deposit transaction tx to its sender, if any. The mint is credited
+// even if the transaction fails.
+func depositMintOps(tx *evmClient.LoadedTransaction) []*RosettaTypes.Operation {
+	mint := tx.GetMint()
+	if mint.Sign() == 0 {
+		return nil
+	}
+
+	b := NewOperationBuilder(0)
+	b.Add(&RosettaTypes.Operation{
+		Type:    sdkTypes.BridgeDepositOpType,
+		Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account: evmClient.Account(tx.From),
+		Amount:  evmClient.Amount(mint, sdkTypes.Currency),
+	})
+
+	return b.Operations()
+}
+
+// feeCurrency returns the currency of the fee operations of tx
+func feeCurrency(tx *evmClient.LoadedTransaction) *RosettaTypes.Currency {
+	if tx.FeeCurrency != nil {
+		return tx.FeeCurrency
+	}
+
+	return sdkTypes.Currency
+}
+
+// FeeOps returns the fee operations of tx. It returns an error wrapping
+// evmClient.ErrInvalidAddress if the fee recipient is malformed.
+func FeeOps(tx *evmClient.LoadedTransaction) ([]*RosettaTypes.Operation, error) {
 	var minerEarnedAmount *big.Int
 	if tx.FeeBurned == nil {
 		minerEarnedAmount = tx.FeeAmount
@@ -139,77 +250,93 @@ func FeeOps(tx *evmClient.LoadedTransaction) []*RosettaTypes.Operation {
 	}
 
 	if minerEarnedAmount == nil {
-		return nil
+		return nil, nil
 	}
 
 	feeRewarder := tx.Miner
 	if len(tx.Author) > 0 {
 		feeRewarder = tx.Author
 	}
+	feeRecipient, err := evmClient.ChecksumAddress(feeRewarder)
+	if err != nil {
+		return nil, fmt.Errorf("fee recipient: %w", err)
+	}
 
-	ops := []*RosettaTypes.Operation{
-		{
-			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: 0,
-			},
-			Type:   sdkTypes.FeeOpType,
-			Status: RosettaTypes.String(sdkTypes.SuccessStatus),
-			Account: &RosettaTypes.AccountIdentifier{
-				Address: evmClient.MustChecksum(tx.From.String()),
-			},
-			Amount: evmClient.Amount(new(big.Int).Neg(minerEarnedAmount), sdkTypes.Currency),
+	currency := feeCurrency(tx)
+	b := NewOperationBuilder(0)
+	payerOp := b.Add(&RosettaTypes.Operation{
+		Type:   sdkTypes.FeeOpType,
+		Status: RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account: &RosettaTypes.AccountIdentifier{
+			Address: evmClient.FormatAddress(*tx.From),
 		},
-
-		{
-			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: 1,
-			},
-			RelatedOperations: []*RosettaTypes.OperationIdentifier{
-				{
-					Index: 0,
-				},
-			},
-			Type:   sdkTypes.FeeOpType,
-			Status: RosettaTypes.String(sdkTypes.SuccessStatus),
-			Account: &RosettaTypes.AccountIdentifier{
-				Address: evmClient.MustChecksum(feeRewarder),
-			},
-			Amount: evmClient.Amount(minerEarnedAmount, sdkTypes.Currency),
+		Amount: evmClient.Amount(new(big.Int).Neg(minerEarnedAmount), currency),
+	})
+	b.Add(&RosettaTypes.Operation{
+		Type:   sdkTypes.FeeOpType,
+		Status: RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account: &RosettaTypes.AccountIdentifier{
+			Address: feeRecipient,
 		},
-	}
+		Amount: evmClient.Amount(minerEarnedAmount, currency),
+	}, payerOp)
 
 	if tx.FeeBurned == nil {
-		return ops
+		return b.Operations(), nil
 	}
 
-	burntOp := &RosettaTypes.Operation{
-		OperationIdentifier: &RosettaTypes.OperationIdentifier{
-			Index: 2, // nolint:gomnd
-		},
+	b.Add(&RosettaTypes.Operation{
 		Type:    sdkTypes.FeeOpType,
 		Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 		Account: evmClient.Account(tx.From),
-		Amount:  evmClient.Amount(new(big.Int).Neg(tx.FeeBurned), sdkTypes.Currency),
-	}
-
-	ops = append(ops, burntOp)
+		Amount:  evmClient.Amount(new(big.Int).Neg(tx.FeeBurned), currency),
+	})
 
-	return ops
+	return b.Operations(), nil
 }
 
 // TraceOps returns all *RosettaTypes.Operation for a given
-// array of flattened traces.
-// nolint:gocognit
+// array of flattened traces, with the SELFDESTRUCT semantics from before
+// Cancun. Use TransactionTraceOps for chains that activated Cancun.
 func TraceOps(
 	calls []*evmClient.FlatCall,
 	startIndex int,
+) []*RosettaTypes.Operation {
+	return traceOps(calls, startIndex, false)
+}
+
+// TransactionTraceOps returns the operations of the traces of tx like
+// TraceOps, applying EIP-6780 when tx.EIP6780 is set: a SELFDESTRUCT only
+// destroys a contract created in the same transaction, and otherwise only
+// transfers its balance, which isn't burned when the contract is its own
+// beneficiary.
+func TransactionTraceOps(
+	tx *evmClient.LoadedTransaction,
+	startIndex int,
+) []*RosettaTypes.Operation {
+	return traceOps(tx.Trace, startIndex, tx.EIP6780)
+}
+
+// traceOps returns the operations of calls, applying EIP-6780 if eip6780 is set
+// nolint:gocognit
+func traceOps(
+	calls []*evmClient.FlatCall,
+	startIndex int,
+	eip6780 bool,
 ) []*RosettaTypes.Operation { // nolint: gocognit
-	var ops []*RosettaTypes.Operation
 	if len(calls) == 0 {
-		return ops
+		return nil
 	}
 
+	b := newPooledOperationBuilder(int64(startIndex))
+
 	destroyedAccounts := map[string]*big.Int{}
+	// createdAccounts are the accounts created by the transaction, the only
+	// ones a SELFDESTRUCT destroys under EIP-6780
+	var createdAccounts map[string]struct{}
+	if eip6780 {
+		createdAccounts = map[string]struct{}{}
+	}
 	for _, trace := range calls {
 		// Handle partial transaction success
 		metadata := map[string]interface{}{}
@@ -235,14 +362,12 @@ func TraceOps(
 		}
 
 		// Checksum addresses
-		from := evmClient.MustChecksum(trace.From.String())
-		to := evmClient.MustChecksum(trace.To.String())
+		from := evmClient.FormatAddress(trace.From)
+		to := evmClient.FormatAddress(trace.To)
 
+		var fromOp *RosettaTypes.Operation
 		if shouldAdd {
-			fromOp := &RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: int64(len(ops) + startIndex),
-				},
+			fromOp = &RosettaTypes.Operation{
 				Type:   traceType,
 				Status: RosettaTypes.String(opStatus),
 				Account: &RosettaTypes.AccountIdentifier{
@@ -263,12 +388,14 @@ func TraceOps(
 				}
 			}
 
-			ops = append(ops, fromOp)
+			b.Add(fromOp)
 		}
 
 		// Add to destroyed accounts if SELFDESTRUCT
-		// and overwrite existing balance.
-		if traceType == sdkTypes.SelfDestructOpType {
+		// and overwrite existing balance. Under EIP-6780,
+		// other SELFDESTRUCTs are plain transfers.
+		_, created := createdAccounts[from]
+		if traceType == sdkTypes.SelfDestructOpType && (!eip6780 || created) {
 			destroyedAccounts[from] = new(big.Int)
 
 			// If destination of of SELFDESTRUCT is self,
@@ -291,19 +418,13 @@ func TraceOps(
 		// the destroyed accounts map.
 		if sdkTypes.CreateType(traceType) {
 			delete(destroyedAccounts, to)
+			if eip6780 {
+				createdAccounts[to] = struct{}{}
+			}
 		}
 
 		if shouldAdd {
-			lastOpIndex := ops[len(ops)-1].OperationIdentifier.Index
 			toOp := &RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: lastOpIndex + 1,
-				},
-				RelatedOperations: []*RosettaTypes.OperationIdentifier{
-					{
-						Index: lastOpIndex,
-					},
-				},
 				Type:   traceType,
 				Status: RosettaTypes.String(opStatus),
 				Account: &RosettaTypes.AccountIdentifier{
@@ -325,7 +446,7 @@ func TraceOps(
 				}
 			}
 
-			ops = append(ops, toOp)
+			b.Add(toOp, fromOp)
 		}
 	}
 
@@ -344,10 +465,7 @@ func TraceOps(
 			log.Fatalf("negative balance for suicided account %s: %s\n", acct, val.String())
 		}
 
-		ops = append(ops, &RosettaTypes.Operation{
-			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: ops[len(ops)-1].OperationIdentifier.Index + 1,
-			},
+		b.Add(&RosettaTypes.Operation{
 			Type:   sdkTypes.DestructOpType,
 			Status: RosettaTypes.String(sdkTypes.SuccessStatus),
 			Account: &RosettaTypes.AccountIdentifier{
@@ -360,7 +478,7 @@ func TraceOps(
 		})
 	}
 
-	return ops
+	return b.release()
 }
 
 // Erc20Ops returns a list of erc20 operations parsed from the log from a transaction receipt
@@ -369,7 +487,7 @@ func Erc20Ops(
 	currency *evmClient.ContractCurrency,
 	opsLen int64,
 ) []*RosettaTypes.Operation {
-	ops := []*RosettaTypes.Operation{}
+	b := NewOperationBuilder(opsLen)
 	contractAddress := transferLog.Address
 	event := transferLog.Topics[0]
 
@@ -377,31 +495,25 @@ func Erc20Ops(
 		address := transferLog.Topics[1]
 
 		if event.Hex() == evmClient.Erc20LogTopicMap[evmClient.Erc20DepositLogTopic] {
-			mintOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen,
-				},
+			mintOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Mint,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, false),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&address)),
 			}
-			ops = append(ops, &mintOp)
-			return ops
+			b.Add(mintOp)
+			return b.Operations()
 		}
 
 		if event.Hex() == evmClient.Erc20LogTopicMap[evmClient.Erc20WithdrawalLogTopic] {
-			burnOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen,
-				},
+			burnOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Burn,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, true),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&address)),
 			}
-			ops = append(ops, &burnOp)
-			return ops
+			b.Add(burnOp)
+			return b.Operations()
 		}
 	}
 
@@ -410,62 +522,45 @@ func Erc20Ops(
 		addressTo := transferLog.Topics[2]
 
 		if addressFrom.Hex() == zeroAddress {
-			mintOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen,
-				},
+			mintOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Mint,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, false),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&addressTo)),
 			}
-			ops = append(ops, &mintOp)
-			return ops
+			b.Add(mintOp)
+			return b.Operations()
 		}
 
 		if addressTo.Hex() == zeroAddress {
-			burnOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen,
-				},
+			burnOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Burn,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, true),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&addressFrom)),
 			}
-			ops = append(ops, &burnOp)
-			return ops
+			b.Add(burnOp)
+			return b.Operations()
 		}
 
 		if event.Hex() == evmClient.Erc20LogTopicMap[evmClient.Erc20TransferLogTopic] {
-			sendingOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen,
-				},
+			sendingOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Transfer,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, true),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&addressFrom)),
 			}
-			receiptOp := RosettaTypes.Operation{
-				OperationIdentifier: &RosettaTypes.OperationIdentifier{
-					Index: opsLen + 1,
-				},
+			receiptOp := &RosettaTypes.Operation{
 				Status:  RosettaTypes.String(sdkTypes.SuccessStatus),
 				Type:    sdkTypes.OpErc20Transfer,
 				Amount:  evmClient.Erc20Amount(transferLog.Data, contractAddress, currency.Symbol, currency.Decimals, false),
 				Account: evmClient.Account(evmClient.ConvertEVMTopicHashToAddress(&addressTo)),
-				RelatedOperations: []*RosettaTypes.OperationIdentifier{
-					{
-						Index: opsLen,
-					},
-				},
 			}
-			ops = append(ops, &sendingOp)
-			ops = append(ops, &receiptOp)
-			return ops
+			b.Add(sendingOp)
+			b.Add(receiptOp, sendingOp)
+			return b.Operations()
 		}
 	}
 
-	return ops
+	return b.Operations()
 }
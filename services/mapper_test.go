@@ -15,7 +15,11 @@
 package services
 
 import (
+    "encoding/json"
     evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+    "github.com/coinbase/rosetta-geth-sdk/configuration"
+    sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+    RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
     "github.com/ethereum/go-ethereum/common"
     "github.com/stretchr/testify/assert"
     "math/big"
@@ -231,3 +235,210 @@ func TestParseTransferOpsFirstIndexRelatedOps(t *testing.T) {
 	assert.Equal(t, ops[4].OperationIdentifier.Index, int64(4))
 	assert.Equal(t, ops[4].RelatedOperations[0].Index, int64(3))
 }
+
+func TestFeeOpsInvalidFeeRecipient(t *testing.T) {
+	from := common.HexToAddress("0xdd4b76b0316dcafa98862a12a92791ac9426a0e2")
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		Author:    "0xgarbage",
+		FeeAmount: big.NewInt(100),
+	}
+
+	ops, err := FeeOps(tx)
+	assert.ErrorIs(t, err, evmClient.ErrInvalidAddress)
+	assert.Nil(t, ops)
+
+	tx.Author = "0xdff384f754e854890e311e3280b767f80797291e"
+	ops, err = FeeOps(tx)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, "0xdFf384F754E854890E311e3280B767F80797291e", ops[1].Account.Address)
+}
+
+func TestParseFeeOps(t *testing.T) {
+	from := common.HexToAddress("0xdd4b76b0316dcafa98862a12a92791ac9426a0e2")
+	poster := common.HexToAddress("0xa4b00000000000000000000000000000000000f6")
+
+	// Gas prepayment burned before execution and partially refunded after it,
+	// as annotated by the call tracer of Arbitrum Nitro nodes
+	var before, after []*evmClient.EVMTransfer
+	assert.NoError(t, json.Unmarshal([]byte(`[
+		{"purpose": "feePayment", "from": "0xdd4b76b0316dcafa98862a12a92791ac9426a0e2", "to": null, "value": "0x3e8"}
+	]`), &before))
+	assert.NoError(t, json.Unmarshal([]byte(`[
+		{"purpose": "gasRefund", "from": null, "to": "0xdd4b76b0316dcafa98862a12a92791ac9426a0e2", "value": "0x64"},
+		{"purpose": "feeCollection", "from": null, "to": "0xa4b00000000000000000000000000000000000f6", "value": "0x384"},
+		{"purpose": "noop", "from": null, "to": null, "value": "0x1"},
+		{"purpose": "noop", "from": "0xdd4b76b0316dcafa98862a12a92791ac9426a0e2", "to": null, "value": "0x0"}
+	]`), &after))
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		Miner:     poster.Hex(),
+		FeeAmount: big.NewInt(1000),
+		Trace: []*evmClient.FlatCall{{
+			Type:               "CALL",
+			BeforeEVMTransfers: before,
+			AfterEVMTransfers:  after,
+		}},
+	}
+
+	ops, err := ParseFeeOps(tx, configuration.RosettaConfig{UseEVMTransferAnnotations: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                sdkTypes.FeeOpType,
+			Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: from.Hex()},
+			Amount:              evmClient.Amount(big.NewInt(-900), sdkTypes.Currency),
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+			Type:                sdkTypes.FeeOpType,
+			Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: poster.Hex()},
+			Amount:              evmClient.Amount(big.NewInt(900), sdkTypes.Currency),
+		},
+	}, ops)
+
+	// Without annotations, the fee operations are computed from the receipt
+	ops, err = ParseFeeOps(tx, configuration.RosettaConfig{})
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, "-1000", ops[0].Amount.Value)
+	assert.Equal(t, "1000", ops[1].Amount.Value)
+}
+
+func TestOPStackFeeOps(t *testing.T) {
+	from := common.HexToAddress("0xdd4b76b0316dcafa98862a12a92791ac9426a0e2")
+	cfg := configuration.RosettaConfig{SupportsOpStack: true}
+
+	// The fee is split between the fee vaults
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		Miner:     evmClient.SequencerFeeVault.Hex(),
+		FeeAmount: big.NewInt(1000),
+		FeeBurned: big.NewInt(600),
+		Receipt:   &evmClient.RosettaTxReceipt{L1Fee: big.NewInt(300)},
+	}
+	ops, err := ParseFeeOps(tx, cfg)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 4)
+	expected := []struct {
+		address string
+		value   string
+	}{
+		{from.Hex(), "-1000"},
+		{evmClient.SequencerFeeVault.Hex(), "100"},
+		{evmClient.BaseFeeVault.Hex(), "600"},
+		{evmClient.L1FeeVault.Hex(), "300"},
+	}
+	for i, op := range ops {
+		assert.Equal(t, sdkTypes.FeeOpType, op.Type)
+		assert.Equal(t, expected[i].address, op.Account.Address)
+		assert.Equal(t, expected[i].value, op.Amount.Value)
+		if i > 0 {
+			assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 0}}, op.RelatedOperations)
+		}
+	}
+
+	// Vaults without a share of the fee are omitted
+	tx.Receipt = &evmClient.RosettaTxReceipt{}
+	tx.FeeBurned = big.NewInt(1000)
+	ops, err = OPStackFeeOps(tx)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, evmClient.BaseFeeVault.Hex(), ops[1].Account.Address)
+
+	tx.FeeBurned = big.NewInt(1001)
+	_, err = OPStackFeeOps(tx)
+	assert.ErrorContains(t, err, "is less than its base fee 1001 and L1 fee 0")
+
+	// Deposits pay no fee and credit their mint to the sender
+	deposit := &evmClient.LoadedTransaction{
+		From:       &from,
+		FeeAmount:  big.NewInt(0),
+		Mint:       "0x3e8",
+		Extensions: map[string]interface{}{evmClient.SourceHashExtension: "0x01"},
+	}
+	ops, err = OPStackFeeOps(deposit)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.Operation{{
+		OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+		Type:                sdkTypes.BridgeDepositOpType,
+		Status:              RosettaTypes.String(sdkTypes.SuccessStatus),
+		Account:             &RosettaTypes.AccountIdentifier{Address: from.Hex()},
+		Amount:              evmClient.Amount(big.NewInt(1000), sdkTypes.Currency),
+	}}, ops)
+
+	deposit.Mint = ""
+	ops, err = OPStackFeeOps(deposit)
+	assert.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func TestTransactionTraceOps(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	contract := common.HexToAddress("0x2")
+	beneficiary := common.HexToAddress("0x3")
+	call := func(callType string, from common.Address, to common.Address, value int64) *evmClient.FlatCall {
+		return &evmClient.FlatCall{Type: callType, From: from, To: to, Value: big.NewInt(value)}
+	}
+
+	tests := map[string]struct {
+		calls    []*evmClient.FlatCall
+		expected map[bool][]string
+	}{
+		"selfdestruct to beneficiary": {
+			calls: []*evmClient.FlatCall{
+				call("SELFDESTRUCT", contract, beneficiary, 10),
+				call("CALL", sender, contract, 5),
+			},
+			expected: map[bool][]string{
+				false: {"SELFDESTRUCT 0x2 -10", "SELFDESTRUCT 0x3 10", "CALL 0x1 -5", "CALL 0x2 5", "DESTRUCT 0x2 -5"},
+				// The contract isn't destroyed and keeps what it receives afterwards
+				true: {"SELFDESTRUCT 0x2 -10", "SELFDESTRUCT 0x3 10", "CALL 0x1 -5", "CALL 0x2 5"},
+			},
+		},
+		"selfdestruct to self": {
+			calls: []*evmClient.FlatCall{
+				call("SELFDESTRUCT", contract, contract, 10),
+			},
+			expected: map[bool][]string{
+				false: {"SELFDESTRUCT 0x2 -10"},
+				// The balance isn't burned
+				true: {"SELFDESTRUCT 0x2 -10", "SELFDESTRUCT 0x2 10"},
+			},
+		},
+		"created in transaction": {
+			calls: []*evmClient.FlatCall{
+				call("CREATE", sender, contract, 10),
+				call("SELFDESTRUCT", contract, contract, 10),
+				call("CALL", sender, contract, 5),
+			},
+			expected: map[bool][]string{
+				false: {"CREATE 0x1 -10", "CREATE 0x2 10", "SELFDESTRUCT 0x2 -10", "CALL 0x1 -5", "CALL 0x2 5", "DESTRUCT 0x2 -5"},
+				true:  {"CREATE 0x1 -10", "CREATE 0x2 10", "SELFDESTRUCT 0x2 -10", "CALL 0x1 -5", "CALL 0x2 5", "DESTRUCT 0x2 -5"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		for _, eip6780 := range []bool{false, true} {
+			tx := &evmClient.LoadedTransaction{Trace: test.calls, EIP6780: eip6780}
+			ops := TransactionTraceOps(tx, 0)
+			assert.NoError(t, ValidateOperationIndexes(ops))
+
+			summaries := make([]string, 0, len(ops))
+			for _, op := range ops {
+				address := common.HexToAddress(op.Account.Address).Big().String()
+				summaries = append(summaries, op.Type+" 0x"+address+" "+op.Amount.Value)
+			}
+			assert.Equal(t, test.expected[eip6780], summaries, "%s, EIP-6780 %t", name, eip6780)
+		}
+	}
+
+	// TraceOps applies the rules from before Cancun
+	calls := []*evmClient.FlatCall{call("SELFDESTRUCT", contract, contract, 10)}
+	assert.Len(t, TraceOps(calls, 0), 1)
+}
@@ -16,7 +16,9 @@ package services
 
 import (
 	"context"
+	"log"
 
+	"github.com/coinbase/rosetta-geth-sdk/client"
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	"github.com/coinbase/rosetta-geth-sdk/services/construction"
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
@@ -74,6 +76,7 @@ func (s *NetworkAPIService) NetworkOptions(
 			OperationStatuses:       s.types.OperationStatuses,
 			HistoricalBalanceLookup: s.types.HistoricalBalanceSupported,
 			CallMethods:             s.types.CallMethods,
+			BalanceExemptions:       s.config.RosettaCfg.BalanceExemptions,
 		},
 	}, nil
 }
@@ -96,10 +99,25 @@ func (s *NetworkAPIService) NetworkStatus(
 		return nil, AssetTypes.ErrGethNotReady
 	}
 
+	// Nodes with pruned block history report the oldest block they serve. The
+	// oldest block is optional, so it is left out when it can't be found
+	// rather than failing the status.
+	var oldestBlock *types.BlockIdentifier
+	if finder, ok := s.client.(client.OldestBlockFinder); ok {
+		oldestBlock, err = finder.OldestBlock(ctx)
+		if err != nil {
+			log.Printf("could not find the oldest block: %v", err)
+			oldestBlock = nil
+		} else if oldestBlock != nil && oldestBlock.Index > currentBlock.Index {
+			oldestBlock = currentBlock
+		}
+	}
+
 	return &types.NetworkStatusResponse{
 		CurrentBlockIdentifier: currentBlock,
 		CurrentBlockTimestamp:  currentTime,
 		GenesisBlockIdentifier: s.config.GenesisBlockIdentifier,
+		OldestBlockIdentifier:  oldestBlock,
 		SyncStatus:             syncStatus,
 		Peers:                  peers,
 	}, nil
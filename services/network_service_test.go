@@ -16,6 +16,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
@@ -154,3 +155,79 @@ func TestNetworkEndpoints_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestNetworkOptions_BalanceExemptions(t *testing.T) {
+	exemptions := []*types.BalanceExemption{
+		{
+			Currency:      AssetTypes.Currency,
+			ExemptionType: types.BalanceDynamic,
+		},
+	}
+	cfg := &configuration.Configuration{
+		Mode:       configuration.ModeOffline,
+		Network:    networkIdentifier,
+		RosettaCfg: configuration.RosettaConfig{BalanceExemptions: exemptions},
+	}
+	servicer := NewNetworkAPIService(cfg, loadedTypes, AssetTypes.Errors, &mockedServices.Client{})
+
+	networkOptions, err := servicer.NetworkOptions(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, exemptions, networkOptions.Allow.BalanceExemptions)
+}
+
+// prunedClient is a client of a node serving blocks from oldestBlock
+type prunedClient struct {
+	*mockedServices.Client
+	oldestBlock *types.BlockIdentifier
+	err         error
+}
+
+func (c *prunedClient) OldestBlock(ctx context.Context) (*types.BlockIdentifier, error) {
+	return c.oldestBlock, c.err
+}
+
+func TestNetworkStatus_OldestBlock(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:                   configuration.ModeOnline,
+		Network:                networkIdentifier,
+		GenesisBlockIdentifier: TestnetGenesisBlockIdentifier,
+	}
+	currentBlock := &types.BlockIdentifier{Index: 10, Hash: "block 10"}
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		oldestBlock *types.BlockIdentifier
+		err         error
+		expected    *types.BlockIdentifier
+	}{
+		"pruned": {
+			oldestBlock: &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+			expected:    &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+		},
+		"after the current block": {
+			oldestBlock: &types.BlockIdentifier{Index: 11, Hash: "block 11"},
+			expected:    currentBlock,
+		},
+		// The status is still served without the oldest block
+		"lookup error": {
+			err: errors.New("missing trie node"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			mockClient.On("Status", ctx).Return(currentBlock, int64(1000000000000), &types.SyncStatus{}, nil, nil)
+			servicer := NewNetworkAPIService(
+				cfg,
+				loadedTypes,
+				AssetTypes.Errors,
+				&prunedClient{Client: mockClient, oldestBlock: test.oldestBlock, err: test.err},
+			)
+
+			networkStatus, err := servicer.NetworkStatus(ctx, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, networkStatus.OldestBlockIdentifier)
+		})
+	}
+}
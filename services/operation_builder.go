@@ -0,0 +1,162 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// maxPooledOperations is the largest operation buffer returned to operationPool
+const maxPooledOperations = 1 << 16
+
+// operationPool holds the scratch buffers of pooled operation builders
+var operationPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]*RosettaTypes.Operation, 0)
+		return &buf
+	},
+}
+
+// OperationBuilder allocates operation indexes and links related operations,
+// so op mappers can be composed without computing indexes by hand.
+type OperationBuilder struct {
+	startIndex int64
+	ops        []*RosettaTypes.Operation
+
+	// buf is the pooled buffer backing ops, if any
+	buf *[]*RosettaTypes.Operation
+}
+
+// NewOperationBuilder returns an OperationBuilder whose first operation
+// gets startIndex.
+func NewOperationBuilder(startIndex int64) *OperationBuilder {
+	return &OperationBuilder{startIndex: startIndex}
+}
+
+// newPooledOperationBuilder returns an OperationBuilder whose operations are
+// collected in a pooled buffer. The operations must be taken with release.
+func newPooledOperationBuilder(startIndex int64) *OperationBuilder {
+	buf := operationPool.Get().(*[]*RosettaTypes.Operation)
+	return &OperationBuilder{startIndex: startIndex, ops: (*buf)[:0], buf: buf}
+}
+
+// release returns a copy of the operations added to a pooled builder and
+// returns its buffer to the pool. The builder must not be used afterwards.
+func (b *OperationBuilder) release() []*RosettaTypes.Operation {
+	ops := make([]*RosettaTypes.Operation, len(b.ops))
+	copy(ops, b.ops)
+
+	if b.buf != nil && cap(b.ops) <= maxPooledOperations {
+		for i := range b.ops {
+			b.ops[i] = nil
+		}
+		*b.buf = b.ops[:0]
+		operationPool.Put(b.buf)
+	}
+	b.ops, b.buf = nil, nil
+
+	return ops
+}
+
+// NextIndex returns the index the next added operation will get.
+func (b *OperationBuilder) NextIndex() int64 {
+	return b.startIndex + int64(len(b.ops))
+}
+
+// Len returns the number of operations added to the builder.
+func (b *OperationBuilder) Len() int {
+	return len(b.ops)
+}
+
+// Operations returns all operations added to the builder.
+func (b *OperationBuilder) Operations() []*RosettaTypes.Operation {
+	return b.ops
+}
+
+// Add assigns op the next index, links it to the related operations
+// and appends it. The related operations must already be added.
+func (b *OperationBuilder) Add(
+	op *RosettaTypes.Operation,
+	related ...*RosettaTypes.Operation,
+) *RosettaTypes.Operation {
+	op.OperationIdentifier = &RosettaTypes.OperationIdentifier{
+		Index: b.NextIndex(),
+	}
+	op.RelatedOperations = nil
+	for _, r := range related {
+		op.RelatedOperations = append(op.RelatedOperations, &RosettaTypes.OperationIdentifier{
+			Index: r.OperationIdentifier.Index,
+		})
+	}
+
+	b.ops = append(b.ops, op)
+	return op
+}
+
+// Append re-indexes ops built elsewhere (e.g. by another mapper) so that they
+// follow the operations already added, keeping their relations intact.
+// ops must have consecutive indexes and only relate to operations in ops.
+func (b *OperationBuilder) Append(ops ...*RosettaTypes.Operation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := ValidateOperationIndexes(ops); err != nil {
+		return err
+	}
+
+	offset := b.NextIndex() - ops[0].OperationIdentifier.Index
+	for _, op := range ops {
+		op.OperationIdentifier.Index += offset
+		for _, r := range op.RelatedOperations {
+			r.Index += offset
+		}
+	}
+
+	b.ops = append(b.ops, ops...)
+	return nil
+}
+
+// ValidateOperationIndexes checks that ops have consecutive, increasing
+// indexes and that every operation only relates to an earlier operation in ops.
+func ValidateOperationIndexes(ops []*RosettaTypes.Operation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	for i, op := range ops {
+		if op.OperationIdentifier == nil {
+			return fmt.Errorf("operation %d has no operation identifier", i)
+		}
+	}
+
+	start := ops[0].OperationIdentifier.Index
+	for i, op := range ops {
+		index := op.OperationIdentifier.Index
+		if index != start+int64(i) {
+			return fmt.Errorf("operation %d has index %d, expected %d", i, index, start+int64(i))
+		}
+
+		for _, r := range op.RelatedOperations {
+			if r.Index < start || r.Index >= index {
+				return fmt.Errorf("operation %d is related to invalid operation %d", index, r.Index)
+			}
+		}
+	}
+
+	return nil
+}
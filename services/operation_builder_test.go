@@ -0,0 +1,180 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	evmClient "github.com/coinbase/rosetta-geth-sdk/client"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationBuilder_Add(t *testing.T) {
+	b := NewOperationBuilder(3)
+	from := b.Add(&RosettaTypes.Operation{Type: "CALL"})
+	to := b.Add(&RosettaTypes.Operation{Type: "CALL"}, from)
+
+	assert.Equal(t, 2, b.Len())
+	assert.Equal(t, int64(5), b.NextIndex())
+	assert.Equal(t, int64(3), from.OperationIdentifier.Index)
+	assert.Equal(t, int64(4), to.OperationIdentifier.Index)
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 3}}, to.RelatedOperations)
+	assert.NoError(t, ValidateOperationIndexes(b.Operations()))
+}
+
+func TestOperationBuilder_Append(t *testing.T) {
+	from := common.HexToAddress("0x1")
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		Miner:     "0x0000000000000000000000000000000000000002",
+		FeeAmount: big.NewInt(100),
+	}
+	calls := []*evmClient.FlatCall{
+		{
+			Type:  "CALL",
+			From:  common.HexToAddress("0x3"),
+			To:    common.HexToAddress("0x4"),
+			Value: big.NewInt(10),
+		},
+	}
+
+	feeOps, err := FeeOps(tx)
+	assert.NoError(t, err)
+
+	b := NewOperationBuilder(0)
+	assert.NoError(t, b.Append(feeOps...))
+	assert.NoError(t, b.Append(TraceOps(calls, 0)...))
+
+	ops := b.Operations()
+	assert.Len(t, ops, 4)
+	assert.NoError(t, ValidateOperationIndexes(ops))
+	assert.Equal(t, int64(2), ops[2].OperationIdentifier.Index)
+	assert.Equal(t, int64(3), ops[3].OperationIdentifier.Index)
+	assert.Equal(t, int64(2), ops[3].RelatedOperations[0].Index)
+}
+
+func TestValidateOperationIndexes(t *testing.T) {
+	op := func(index int64, related ...int64) *RosettaTypes.Operation {
+		o := &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: index},
+		}
+		for _, r := range related {
+			o.RelatedOperations = append(o.RelatedOperations, &RosettaTypes.OperationIdentifier{Index: r})
+		}
+		return o
+	}
+
+	tests := map[string]struct {
+		ops   []*RosettaTypes.Operation
+		valid bool
+	}{
+		"empty": {
+			valid: true,
+		},
+		"consecutive": {
+			ops:   []*RosettaTypes.Operation{op(2), op(3, 2), op(4)},
+			valid: true,
+		},
+		"gap": {
+			ops: []*RosettaTypes.Operation{op(0), op(2)},
+		},
+		"duplicate": {
+			ops: []*RosettaTypes.Operation{op(0), op(0)},
+		},
+		"related to later op": {
+			ops: []*RosettaTypes.Operation{op(0, 1), op(1)},
+		},
+		"related to op outside of the list": {
+			ops: []*RosettaTypes.Operation{op(1), op(2, 0)},
+		},
+		"missing identifier": {
+			ops: []*RosettaTypes.Operation{op(0), {}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateOperationIndexes(test.ops)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPooledOperationBuilder(t *testing.T) {
+	b := newPooledOperationBuilder(1)
+	from := b.Add(&RosettaTypes.Operation{Type: "CALL"})
+	b.Add(&RosettaTypes.Operation{Type: "CALL"}, from)
+	ops := b.release()
+	assert.Len(t, ops, 2)
+	assert.NoError(t, ValidateOperationIndexes(ops))
+
+	// A reused buffer doesn't leak into the released operations
+	next := newPooledOperationBuilder(0)
+	next.Add(&RosettaTypes.Operation{Type: "FEE"})
+	assert.Len(t, next.release(), 1)
+	assert.Equal(t, "CALL", ops[1].Type)
+}
+
+func BenchmarkTraceOps(b *testing.B) {
+	calls := make([]*evmClient.FlatCall, 500)
+	for i := range calls {
+		calls[i] = &evmClient.FlatCall{
+			Type:  "CALL",
+			From:  common.BigToAddress(big.NewInt(int64(i + 1))),
+			To:    common.BigToAddress(big.NewInt(int64(i + 2))),
+			Value: big.NewInt(int64(i + 1)),
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TraceOps(calls, 0)
+	}
+}
+
+func TestFeeOpsGasCurrency(t *testing.T) {
+	from := common.HexToAddress("0xdd4b76b0316dcafa98862a12a92791ac9426a0e2")
+	tx := &evmClient.LoadedTransaction{
+		From:      &from,
+		Miner:     "0xdff384f754e854890e311e3280b767f80797291e",
+		FeeAmount: big.NewInt(100),
+		FeeBurned: big.NewInt(40),
+	}
+
+	ops, err := FeeOps(tx)
+	assert.NoError(t, err)
+	for _, op := range ops {
+		assert.Equal(t, sdkTypes.Currency, op.Amount.Currency)
+	}
+
+	gasCurrency := &RosettaTypes.Currency{Symbol: "GAS", Decimals: 6}
+	tx.FeeCurrency = gasCurrency
+	ops, err = FeeOps(tx)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 3)
+	for _, op := range ops {
+		assert.Equal(t, gasCurrency, op.Amount.Currency)
+	}
+}
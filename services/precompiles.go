@@ -0,0 +1,90 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// precompile is a configuration.Precompile with its decoder
+type precompile struct {
+	address common.Address
+	decoder client.PrecompileDecoder
+}
+
+// loadPrecompiles returns the precompiles of the configuration with their
+// registered decoders
+func loadPrecompiles(configured []configuration.Precompile) ([]precompile, error) {
+	precompiles := make([]precompile, 0, len(configured))
+	for _, p := range configured {
+		decoder, ok := client.GetPrecompileDecoder(p.Decoder)
+		if !ok {
+			return nil, fmt.Errorf("precompile %s: decoder %q is not registered", p.Address, p.Decoder)
+		}
+		precompiles = append(precompiles, precompile{
+			address: common.HexToAddress(p.Address),
+			decoder: decoder,
+		})
+	}
+
+	return precompiles, nil
+}
+
+// appendPrecompileOps appends the operations of the precompiles called by tx or
+// emitting logs in tx to ops, in the order of the configuration
+func (s *BlockAPIService) appendPrecompileOps(
+	tx *client.LoadedTransaction,
+	logs []*EthTypes.Log,
+	ops []*RosettaTypes.Operation,
+) ([]*RosettaTypes.Operation, error) {
+	if len(s.precompiles) == 0 {
+		return ops, nil
+	}
+
+	b := NewOperationBuilder(int64(len(ops)))
+	for _, p := range s.precompiles {
+		activity := &client.PrecompileActivity{Address: p.address, Tx: tx}
+		for _, call := range tx.Trace {
+			if call.To == p.address && !call.Revert {
+				activity.Calls = append(activity.Calls, call)
+			}
+		}
+		for _, log := range logs {
+			if log.Address == p.address && !log.Removed {
+				activity.Logs = append(activity.Logs, log)
+			}
+		}
+		if len(activity.Calls) == 0 && len(activity.Logs) == 0 {
+			continue
+		}
+
+		precompileOps, err := p.decoder(activity)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode precompile %s in %s: %w", p.address, tx.TxHash, err)
+		}
+		if err := b.Append(precompileOps...); err != nil {
+			return nil, fmt.Errorf("invalid operations of precompile %s in %s: %w", p.address, tx.TxHash, err)
+		}
+	}
+
+	return append(ops, b.Operations()...), nil
+}
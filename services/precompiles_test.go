@@ -0,0 +1,88 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendPrecompileOps(t *testing.T) {
+	staking := common.HexToAddress("0x0000000000000000000000000000000000000800")
+	distribution := common.HexToAddress("0x0000000000000000000000000000000000000801")
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	txHash := common.HexToHash("0xaa")
+
+	var activities []*client.PrecompileActivity
+	decoder := func(activity *client.PrecompileActivity) ([]*RosettaTypes.Operation, error) {
+		activities = append(activities, activity)
+		return []*RosettaTypes.Operation{
+			{OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0}, Type: "STAKE"},
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+				RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 0}},
+				Type:                "STAKE",
+			},
+		}, nil
+	}
+	assert.NoError(t, client.RegisterPrecompileDecoder("test-staking", decoder))
+	assert.NoError(t, client.RegisterPrecompileDecoder("test-failing", func(*client.PrecompileActivity) ([]*RosettaTypes.Operation, error) {
+		return nil, errors.New("bad input")
+	}))
+
+	precompiles, err := loadPrecompiles([]configuration.Precompile{
+		{Address: staking.Hex(), Decoder: "test-staking"},
+		{Address: distribution.Hex(), Decoder: "test-failing"},
+	})
+	assert.NoError(t, err)
+	s := &BlockAPIService{precompiles: precompiles}
+
+	call := &client.FlatCall{Type: "CALL", From: sender, To: staking, Value: big.NewInt(0)}
+	reverted := &client.FlatCall{Type: "CALL", From: sender, To: staking, Value: big.NewInt(0), Revert: true}
+	log := &EthTypes.Log{Address: staking}
+	tx := &client.LoadedTransaction{
+		TxHash: &txHash,
+		Trace:  []*client.FlatCall{{Type: "CALL", From: sender, To: sender, Value: big.NewInt(0)}, call, reverted},
+	}
+	ops := []*RosettaTypes.Operation{{OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0}, Type: "FEE"}}
+
+	// The operations of the called precompiles follow the transaction operations
+	ops, err = s.appendPrecompileOps(tx, []*EthTypes.Log{log, {Address: sender}}, ops)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 3)
+	assert.NoError(t, ValidateOperationIndexes(ops))
+	assert.Equal(t, int64(1), ops[2].RelatedOperations[0].Index)
+	assert.Len(t, activities, 1)
+	assert.Equal(t, staking, activities[0].Address)
+	assert.Equal(t, []*client.FlatCall{call}, activities[0].Calls)
+	assert.Equal(t, []*EthTypes.Log{log}, activities[0].Logs)
+
+	// Decoder failures fail the transaction
+	tx.Trace = append(tx.Trace, &client.FlatCall{Type: "CALL", From: sender, To: distribution, Value: big.NewInt(0)})
+	_, err = s.appendPrecompileOps(tx, nil, nil)
+	assert.EqualError(t, err, "could not decode precompile "+distribution.Hex()+" in "+txHash.Hex()+": bad input")
+
+	_, err = loadPrecompiles([]configuration.Precompile{{Address: staking.Hex(), Decoder: "test-missing"}})
+	assert.EqualError(t, err, "precompile "+staking.Hex()+`: decoder "test-missing" is not registered`)
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// BalanceFetcher fetches the balances of an account at a block. It is
+// implemented by construction.Client.
+type BalanceFetcher interface {
+	Balance(
+		ctx context.Context,
+		account *RosettaTypes.AccountIdentifier,
+		blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+		currencies []*RosettaTypes.Currency,
+	) (*RosettaTypes.AccountBalanceResponse, error)
+}
+
+// BalanceMismatch is a balance computed from the operations of a block range
+// that does not match the balance reported by the node.
+type BalanceMismatch struct {
+	Account  *RosettaTypes.AccountIdentifier `json:"account_identifier"`
+	Currency *RosettaTypes.Currency          `json:"currency"`
+
+	// StartBalance is the node balance at the parent of the first block
+	StartBalance string `json:"start_balance"`
+
+	// ComputedBalance is StartBalance plus the operations of the block range
+	ComputedBalance string `json:"computed_balance"`
+
+	// NodeBalance is the node balance at the last block
+	NodeBalance string `json:"node_balance"`
+}
+
+// ReconciliationReport is the result of ReconcileBalances
+type ReconciliationReport struct {
+	StartBlock *RosettaTypes.BlockIdentifier `json:"start_block_identifier"`
+	EndBlock   *RosettaTypes.BlockIdentifier `json:"end_block_identifier"`
+
+	// Reconciled is the number of account balances that were checked
+	Reconciled int `json:"reconciled"`
+
+	Mismatches []*BalanceMismatch `json:"mismatches"`
+}
+
+// accountBalances are the balances of an account, keyed by currency hash
+type accountBalances struct {
+	account    *RosettaTypes.AccountIdentifier
+	currencies []*RosettaTypes.Currency
+	amounts    map[string]*big.Int
+}
+
+// ReconcileBalances checks that the operations of a range of consecutive
+// /block responses explain the balance changes reported by the node. For
+// every account and currency the operations touch, the successful operation
+// amounts are added to the node balance at the parent of the first block and
+// compared with the node balance at the last block. When accounts is not
+// empty, only those accounts are reconciled.
+//
+// This requires a node that serves historical balances.
+func ReconcileBalances(
+	ctx context.Context,
+	fetcher BalanceFetcher,
+	blocks []*RosettaTypes.Block,
+	accounts []*RosettaTypes.AccountIdentifier,
+) (*ReconciliationReport, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("no blocks to reconcile")
+	}
+	for i := 1; i < len(blocks); i++ {
+		if RosettaTypes.Hash(blocks[i].ParentBlockIdentifier) != RosettaTypes.Hash(blocks[i-1].BlockIdentifier) {
+			return nil, fmt.Errorf(
+				"block %d is not the child of block %d",
+				blocks[i].BlockIdentifier.Index,
+				blocks[i-1].BlockIdentifier.Index,
+			)
+		}
+	}
+
+	include := map[string]bool{}
+	for _, account := range accounts {
+		include[RosettaTypes.Hash(account)] = true
+	}
+
+	var keys []string
+	changes := map[string]*accountBalances{}
+	for _, block := range blocks {
+		for _, tx := range block.Transactions {
+			for _, op := range tx.Operations {
+				if op.Account == nil || op.Amount == nil {
+					continue
+				}
+				if op.Status != nil && *op.Status != AssetTypes.SuccessStatus {
+					continue
+				}
+				key := RosettaTypes.Hash(op.Account)
+				if len(include) > 0 && !include[key] {
+					continue
+				}
+				value, ok := new(big.Int).SetString(op.Amount.Value, 10) // nolint:gomnd
+				if !ok {
+					return nil, fmt.Errorf(
+						"invalid amount %s in transaction %s",
+						op.Amount.Value,
+						tx.TransactionIdentifier.Hash,
+					)
+				}
+
+				change, ok := changes[key]
+				if !ok {
+					change = &accountBalances{account: op.Account, amounts: map[string]*big.Int{}}
+					changes[key] = change
+					keys = append(keys, key)
+				}
+				currencyKey := RosettaTypes.Hash(op.Amount.Currency)
+				if _, ok := change.amounts[currencyKey]; !ok {
+					change.amounts[currencyKey] = new(big.Int)
+					change.currencies = append(change.currencies, op.Amount.Currency)
+				}
+				change.amounts[currencyKey].Add(change.amounts[currencyKey], value)
+			}
+		}
+	}
+
+	report := &ReconciliationReport{
+		StartBlock: blocks[0].BlockIdentifier,
+		EndBlock:   blocks[len(blocks)-1].BlockIdentifier,
+		Mismatches: []*BalanceMismatch{},
+	}
+	for _, key := range keys {
+		change := changes[key]
+		start, err := fetchBalances(ctx, fetcher, change, blocks[0].ParentBlockIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		end, err := fetchBalances(ctx, fetcher, change, report.EndBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, currency := range change.currencies {
+			currencyKey := RosettaTypes.Hash(currency)
+			computed := new(big.Int).Add(start[currencyKey], change.amounts[currencyKey])
+			report.Reconciled++
+			if computed.Cmp(end[currencyKey]) == 0 {
+				continue
+			}
+
+			report.Mismatches = append(report.Mismatches, &BalanceMismatch{
+				Account:         change.account,
+				Currency:        currency,
+				StartBalance:    start[currencyKey].String(),
+				ComputedBalance: computed.String(),
+				NodeBalance:     end[currencyKey].String(),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// fetchBalances returns the node balances of an account at a block, keyed by currency hash
+func fetchBalances(
+	ctx context.Context,
+	fetcher BalanceFetcher,
+	change *accountBalances,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+) (map[string]*big.Int, error) {
+	resp, err := fetcher.Balance(
+		ctx,
+		change.account,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: &blockIdentifier.Index,
+			Hash:  &blockIdentifier.Hash,
+		},
+		change.currencies,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not get balance of %s at block %d: %w",
+			change.account.Address,
+			blockIdentifier.Index,
+			err,
+		)
+	}
+
+	balances := map[string]*big.Int{}
+	for _, amount := range resp.Balances {
+		value, ok := new(big.Int).SetString(amount.Value, 10) // nolint:gomnd
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %s of %s", amount.Value, change.account.Address)
+		}
+		balances[RosettaTypes.Hash(amount.Currency)] = value
+	}
+	for _, currency := range change.currencies {
+		if _, ok := balances[RosettaTypes.Hash(currency)]; !ok {
+			return nil, fmt.Errorf(
+				"missing %s balance of %s at block %d",
+				currency.Symbol,
+				change.account.Address,
+				blockIdentifier.Index,
+			)
+		}
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// staticBalanceFetcher returns balances keyed by block index and address
+type staticBalanceFetcher map[int64]map[string]string
+
+func (f staticBalanceFetcher) Balance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	return &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: *blockIdentifier.Index, Hash: *blockIdentifier.Hash},
+		Balances: []*RosettaTypes.Amount{
+			{Value: f[*blockIdentifier.Index][account.Address], Currency: currencies[0]},
+		},
+	}, nil
+}
+
+func TestReconcileBalances(t *testing.T) {
+	currency := &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18}
+	op := func(address string, value string, status string) *RosettaTypes.Operation {
+		return &RosettaTypes.Operation{
+			Type:    AssetTypes.CallOpType,
+			Status:  RosettaTypes.String(status),
+			Account: &RosettaTypes.AccountIdentifier{Address: address},
+			Amount:  &RosettaTypes.Amount{Value: value, Currency: currency},
+		}
+	}
+	block := func(index int64, ops ...*RosettaTypes.Operation) *RosettaTypes.Block {
+		return &RosettaTypes.Block{
+			BlockIdentifier:       &RosettaTypes.BlockIdentifier{Index: index, Hash: string(rune('a' + index))},
+			ParentBlockIdentifier: &RosettaTypes.BlockIdentifier{Index: index - 1, Hash: string(rune('a' + index - 1))},
+			Transactions: []*RosettaTypes.Transaction{
+				{TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: "0x1"}, Operations: ops},
+			},
+		}
+	}
+
+	blocks := []*RosettaTypes.Block{
+		block(1, op("alice", "-10", AssetTypes.SuccessStatus), op("bob", "10", AssetTypes.SuccessStatus)),
+		block(2, op("bob", "-5", AssetTypes.SuccessStatus), op("alice", "5", AssetTypes.FailureStatus)),
+	}
+	fetcher := staticBalanceFetcher{
+		0: {"alice": "100", "bob": "0"},
+		// bob is missing a 1 wei fee
+		2: {"alice": "90", "bob": "4"},
+	}
+
+	report, err := ReconcileBalances(context.Background(), fetcher, blocks, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Reconciled)
+	assert.Equal(t, blocks[0].BlockIdentifier, report.StartBlock)
+	assert.Equal(t, blocks[1].BlockIdentifier, report.EndBlock)
+	assert.Equal(t, []*BalanceMismatch{
+		{
+			Account:         &RosettaTypes.AccountIdentifier{Address: "bob"},
+			Currency:        currency,
+			StartBalance:    "0",
+			ComputedBalance: "5",
+			NodeBalance:     "4",
+		},
+	}, report.Mismatches)
+
+	report, err = ReconcileBalances(
+		context.Background(),
+		fetcher,
+		blocks,
+		[]*RosettaTypes.AccountIdentifier{{Address: "alice"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Reconciled)
+	assert.Empty(t, report.Mismatches)
+
+	_, err = ReconcileBalances(context.Background(), fetcher, []*RosettaTypes.Block{blocks[1], blocks[0]}, nil)
+	assert.EqualError(t, err, "block 1 is not the child of block 2")
+}
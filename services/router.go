@@ -15,19 +15,27 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 	"github.com/coinbase/rosetta-sdk-go/types"
 
 	construction "github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
 )
 
 // NewBlockchainRouter creates a Mux http.Handler from a collection
-// of server controllers.
+// of server controllers. It exits if the concurrency limits of the
+// configuration are invalid, see NewBlockchainRouterWithSigner. The handler
+// is a *BlockchainRouter.
 func NewBlockchainRouter(
 	config *configuration.Configuration,
 	types *AssetTypes.Types,
@@ -35,6 +43,40 @@ func NewBlockchainRouter(
 	client construction.Client,
 	asserter *asserter.Asserter,
 ) http.Handler {
+	router, err := NewBlockchainRouterWithSigner(config, types, errors, client, asserter, nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return router
+}
+
+// BlockchainRouter is the http.Handler of the Rosetta endpoints
+type BlockchainRouter struct {
+	http.Handler
+
+	blockAPIService *BlockAPIService
+}
+
+// Close stops the background work of the services of the router and closes
+// their stores
+func (r *BlockchainRouter) Close() error {
+	return r.blockAPIService.Close()
+}
+
+// NewBlockchainRouterWithSigner creates the router of NewBlockchainRouter, with
+// serverSigner serving the sign_and_submit /call method. serverSigner is nil
+// without server side signing. It errors if the concurrency limits of the
+// configuration are invalid. The router must be closed when it is no longer
+// served.
+func NewBlockchainRouterWithSigner(
+	config *configuration.Configuration,
+	types *AssetTypes.Types,
+	errors []*types.Error,
+	client construction.Client,
+	asserter *asserter.Asserter,
+	serverSigner signer.Signer,
+) (*BlockchainRouter, error) {
 	networkAPIService := NewNetworkAPIService(config, types, errors, client)
 	networkAPIController := server.NewNetworkAPIController(
 		networkAPIService,
@@ -65,18 +107,131 @@ func NewBlockchainRouter(
 	// 	asserter,
 	// )
 
-	// callAPIService := NewCallAPIService(config, client)
-	// callAPIController := server.NewCallAPIController(
-	// 	callAPIService,
-	// 	asserter,
-	// )
+	callAPIService := NewCallAPIService(config, client)
+	if serverSigner != nil {
+		callAPIService = NewCallAPIServiceWithSigner(config, client, serverSigner, constructionAPIService)
+	}
+	callAPIController := server.NewCallAPIController(
+		callAPIService,
+		asserter,
+	)
 
-	return server.NewRouter(
+	router := server.NewRouter(
 		networkAPIController,
 		accountAPIController,
 		blockAPIController,
 		constructionAPIController,
 		// mempoolAPIController,
-		// callAPIController,
+		callAPIController,
 	)
+
+	// Queued requests wait within the time budget of the request
+	limitedRouter, err := ConcurrencyLimitMiddleware(config.RosettaCfg.ConcurrencyLimits, router)
+	if err != nil {
+		_ = blockAPIService.Close()
+		return nil, err
+	}
+
+	timedRouter := RequestTimeoutMiddleware(config.RosettaCfg.RequestTimeout, limitedRouter)
+
+	return &BlockchainRouter{
+		Handler:         CompressionMiddleware(config.RosettaCfg.CompressResponses, timedRouter),
+		blockAPIService: blockAPIService,
+	}, nil
+}
+
+// RequestTimeoutMiddleware cancels the context of every request after timeout,
+// so the node calls of requests that run out of budget are aborted. The
+// request context is also cancelled when the caller goes away. A zero timeout
+// returns next unchanged.
+func RequestTimeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ConcurrencyLimitMiddleware limits the concurrent requests to the endpoints of
+// limits, keyed by request path. Requests beyond the limit of an endpoint wait
+// in its queue until a request finishes or their context is cancelled. Requests
+// that don't fit in the queue, or are cancelled while queued, fail with the
+// retriable ErrServerBusy. No limits returns next unchanged. It errors if a
+// limit doesn't admit any request or has a negative queue depth.
+func ConcurrencyLimitMiddleware(
+	limits map[string]configuration.ConcurrencyLimit,
+	next http.Handler,
+) (http.Handler, error) {
+	if len(limits) == 0 {
+		return next, nil
+	}
+
+	limiters := make(map[string]*concurrencyLimiter, len(limits))
+	for path, limit := range limits {
+		if limit.MaxConcurrent <= 0 {
+			return nil, fmt.Errorf("max concurrent requests %d of %s is not positive", limit.MaxConcurrent, path)
+		}
+		if limit.QueueDepth < 0 {
+			return nil, fmt.Errorf("queue depth %d of %s is negative", limit.QueueDepth, path)
+		}
+		limiters[path] = &concurrencyLimiter{
+			admitted: make(chan struct{}, limit.MaxConcurrent+limit.QueueDepth),
+			running:  make(chan struct{}, limit.MaxConcurrent),
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter, ok := limiters[r.URL.Path]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := limiter.acquire(r.Context()); err != nil {
+			server.EncodeJSONResponse(
+				AssetTypes.WrapErr(AssetTypes.ErrServerBusy, err),
+				http.StatusInternalServerError,
+				w,
+			)
+			return
+		}
+		defer limiter.release()
+
+		next.ServeHTTP(w, r)
+	}), nil
+}
+
+// concurrencyLimiter admits the running and queued requests of an endpoint
+type concurrencyLimiter struct {
+	admitted chan struct{}
+	running  chan struct{}
+}
+
+// acquire waits for a running slot, or fails if the queue is full or ctx is
+// cancelled first
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.admitted <- struct{}{}:
+	default:
+		return errors.New("too many concurrent requests")
+	}
+
+	select {
+	case l.running <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		<-l.admitted
+		return fmt.Errorf("request cancelled while queued: %w", ctx.Err())
+	}
+}
+
+// release frees the running slot of a request
+func (l *concurrencyLimiter) release() {
+	<-l.running
+	<-l.admitted
 }
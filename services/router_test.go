@@ -0,0 +1,220 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	handler, err := ConcurrencyLimitMiddleware(
+		map[string]configuration.ConcurrencyLimit{
+			"/block": {MaxConcurrent: 1},
+		},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/block" {
+				started <- struct{}{}
+				<-unblock
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	assert.NoError(t, err)
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		return recorder
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- serve("/block").Code
+	}()
+	<-started
+
+	// The endpoint is saturated
+	recorder := serve("/block")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var rosettaErr types.Error
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rosettaErr))
+	assert.Equal(t, AssetTypes.ErrServerBusy.Code, rosettaErr.Code)
+	assert.True(t, rosettaErr.Retriable)
+
+	// Other endpoints are not limited
+	assert.Equal(t, http.StatusOK, serve("/network/status").Code)
+
+	unblock <- struct{}{}
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestConcurrencyLimitMiddleware_InvalidLimits(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := map[string]struct {
+		limit         configuration.ConcurrencyLimit
+		expectedError string
+	}{
+		"no concurrent requests": {
+			limit:         configuration.ConcurrencyLimit{MaxConcurrent: 0, QueueDepth: 1},
+			expectedError: "max concurrent requests 0 of /block is not positive",
+		},
+		"negative concurrent requests": {
+			limit:         configuration.ConcurrencyLimit{MaxConcurrent: -1},
+			expectedError: "max concurrent requests -1 of /block is not positive",
+		},
+		"negative queue depth": {
+			limit:         configuration.ConcurrencyLimit{MaxConcurrent: 1, QueueDepth: -1},
+			expectedError: "queue depth -1 of /block is negative",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ConcurrencyLimitMiddleware(map[string]configuration.ConcurrencyLimit{"/block": test.limit}, next)
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := &concurrencyLimiter{
+		admitted: make(chan struct{}, 2),
+		running:  make(chan struct{}, 1),
+	}
+	ctx := context.Background()
+
+	// The first request runs
+	assert.NoError(t, limiter.acquire(ctx))
+
+	// A queued request whose context is cancelled fails and leaves the queue
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, limiter.acquire(cancelled), context.Canceled)
+
+	// The second request is queued until the first one finishes
+	acquired := make(chan error)
+	go func() {
+		acquired <- limiter.acquire(ctx)
+	}()
+	assert.Eventually(t, func() bool { return len(limiter.admitted) == 2 }, time.Second, time.Millisecond)
+
+	// The queue is full
+	assert.EqualError(t, limiter.acquire(ctx), "too many concurrent requests")
+
+	limiter.release()
+	assert.NoError(t, <-acquired)
+	limiter.release()
+	assert.Empty(t, limiter.admitted)
+	assert.Empty(t, limiter.running)
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"block":{"transactions":[]}}`, 100)
+	handler := CompressionMiddleware(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write([]byte(body))
+	}))
+
+	tests := map[string]struct {
+		path           string
+		acceptEncoding string
+
+		expectedEncoding string
+	}{
+		"gzip": {
+			path:             "/block",
+			acceptEncoding:   "gzip, deflate",
+			expectedEncoding: "gzip",
+		},
+		"any encoding": {
+			path:             "/block",
+			acceptEncoding:   "br;q=1.0, *;q=0.5",
+			expectedEncoding: "gzip",
+		},
+		"gzip refused": {
+			path:           "/block",
+			acceptEncoding: "gzip;q=0, identity",
+		},
+		"no encoding": {
+			path: "/block",
+		},
+		"no body": {
+			path:           "/empty",
+			acceptEncoding: "gzip",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, test.path, nil)
+			if len(test.acceptEncoding) > 0 {
+				request.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			assert.Equal(t, test.expectedEncoding, recorder.Header().Get("Content-Encoding"))
+			if test.path == "/empty" {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+				assert.Empty(t, recorder.Body.Bytes())
+				return
+			}
+
+			received := recorder.Body.Bytes()
+			if len(test.expectedEncoding) > 0 {
+				assert.Empty(t, recorder.Header().Get("Content-Length"))
+				assert.Less(t, len(received), len(body))
+				reader, err := gzip.NewReader(bytes.NewReader(received))
+				assert.NoError(t, err)
+				received, err = io.ReadAll(reader)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, body, string(received))
+		})
+	}
+
+	// Responses aren't compressed when compression is disabled
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/block", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	CompressionMiddleware(false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})).ServeHTTP(recorder, request)
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, recorder.Body.String())
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// OperationDivergence is a difference between the operations two providers
+// produce for a transaction of the same block. Primary or Secondary is nil
+// when only one of them produces the transaction or the operation.
+type OperationDivergence struct {
+	TransactionHash string `json:"transaction_hash"`
+
+	// OperationIndex is the index of the divergent operation, unset when the
+	// transaction is missing from a block
+	OperationIndex *int64 `json:"operation_index,omitempty"`
+
+	Reason    string                  `json:"reason"`
+	Primary   *RosettaTypes.Operation `json:"primary,omitempty"`
+	Secondary *RosettaTypes.Operation `json:"secondary,omitempty"`
+}
+
+// TraceDiffReport is the result of DiffBlockOperations
+type TraceDiffReport struct {
+	Block *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+
+	// Transactions is the number of transactions of the primary block
+	Transactions int `json:"transactions"`
+
+	Divergences []*OperationDivergence `json:"divergences"`
+}
+
+// DiffBlockOperations compares the operations of the /block responses of the
+// same block built from two providers, e.g. geth and erigon, whose tracers can
+// differ subtly. Transactions are matched by hash and their operations by
+// index. Divergences are reported in the order of the primary block, followed
+// by the transactions only the secondary block has.
+func DiffBlockOperations(primary, secondary *RosettaTypes.Block) (*TraceDiffReport, error) {
+	if primary == nil || secondary == nil {
+		return nil, errors.New("both blocks are required")
+	}
+	if RosettaTypes.Hash(primary.BlockIdentifier) != RosettaTypes.Hash(secondary.BlockIdentifier) {
+		return nil, fmt.Errorf(
+			"block %d %s does not match block %d %s",
+			primary.BlockIdentifier.Index,
+			primary.BlockIdentifier.Hash,
+			secondary.BlockIdentifier.Index,
+			secondary.BlockIdentifier.Hash,
+		)
+	}
+
+	secondaryTxs := make(map[string]*RosettaTypes.Transaction, len(secondary.Transactions))
+	for _, tx := range secondary.Transactions {
+		secondaryTxs[tx.TransactionIdentifier.Hash] = tx
+	}
+
+	report := &TraceDiffReport{
+		Block:        primary.BlockIdentifier,
+		Transactions: len(primary.Transactions),
+		Divergences:  []*OperationDivergence{},
+	}
+	for _, tx := range primary.Transactions {
+		hash := tx.TransactionIdentifier.Hash
+		other, ok := secondaryTxs[hash]
+		if !ok {
+			report.Divergences = append(report.Divergences, &OperationDivergence{
+				TransactionHash: hash,
+				Reason:          "transaction missing from the secondary block",
+			})
+			continue
+		}
+		delete(secondaryTxs, hash)
+
+		report.Divergences = append(report.Divergences, diffOperations(hash, tx.Operations, other.Operations)...)
+	}
+	for _, tx := range secondary.Transactions {
+		if _, ok := secondaryTxs[tx.TransactionIdentifier.Hash]; ok {
+			report.Divergences = append(report.Divergences, &OperationDivergence{
+				TransactionHash: tx.TransactionIdentifier.Hash,
+				Reason:          "transaction missing from the primary block",
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// diffOperations returns the divergences between the operations of a
+// transaction, compared by index
+func diffOperations(hash string, primary, secondary []*RosettaTypes.Operation) []*OperationDivergence {
+	divergences := []*OperationDivergence{}
+	for i := 0; i < len(primary) || i < len(secondary); i++ {
+		index := int64(i)
+		divergence := &OperationDivergence{TransactionHash: hash, OperationIndex: &index}
+		switch {
+		case i >= len(secondary):
+			divergence.Reason = "operation missing from the secondary transaction"
+			divergence.Primary = primary[i]
+		case i >= len(primary):
+			divergence.Reason = "operation missing from the primary transaction"
+			divergence.Secondary = secondary[i]
+		default:
+			reason := operationDifference(primary[i], secondary[i])
+			if len(reason) == 0 {
+				continue
+			}
+			divergence.Reason = reason
+			divergence.Primary = primary[i]
+			divergence.Secondary = secondary[i]
+		}
+		divergences = append(divergences, divergence)
+	}
+
+	return divergences
+}
+
+// operationDifference returns which part of two operations differs, or an
+// empty string when they are the same
+func operationDifference(a, b *RosettaTypes.Operation) string {
+	switch {
+	case a.Type != b.Type:
+		return "type differs"
+	case RosettaTypes.Hash(a.Status) != RosettaTypes.Hash(b.Status):
+		return "status differs"
+	case RosettaTypes.Hash(a.Account) != RosettaTypes.Hash(b.Account):
+		return "account differs"
+	case RosettaTypes.Hash(a.Amount) != RosettaTypes.Hash(b.Amount):
+		return "amount differs"
+	case RosettaTypes.Hash(a.RelatedOperations) != RosettaTypes.Hash(b.RelatedOperations):
+		return "related operations differ"
+	case RosettaTypes.Hash(a) != RosettaTypes.Hash(b):
+		return "operation differs"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBlockOperations(t *testing.T) {
+	identifier := &RosettaTypes.BlockIdentifier{Index: 10, Hash: "0x0a"}
+	operation := func(index int64, opType string, address string, value string) *RosettaTypes.Operation {
+		return &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: index},
+			Type:                opType,
+			Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: address},
+			Amount:              &RosettaTypes.Amount{Value: value, Currency: AssetTypes.Currency},
+		}
+	}
+	transaction := func(hash string, ops ...*RosettaTypes.Operation) *RosettaTypes.Transaction {
+		return &RosettaTypes.Transaction{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: hash},
+			Operations:            ops,
+		}
+	}
+	index := func(i int64) *int64 { return &i }
+
+	tests := map[string]struct {
+		primary   []*RosettaTypes.Transaction
+		secondary []*RosettaTypes.Transaction
+		expected  []*OperationDivergence
+	}{
+		"same operations": {
+			primary:   []*RosettaTypes.Transaction{transaction("0x1", operation(0, "CALL", "0xa", "-1"))},
+			secondary: []*RosettaTypes.Transaction{transaction("0x1", operation(0, "CALL", "0xa", "-1"))},
+			expected:  []*OperationDivergence{},
+		},
+		"amount differs": {
+			primary:   []*RosettaTypes.Transaction{transaction("0x1", operation(0, "CALL", "0xa", "-1"))},
+			secondary: []*RosettaTypes.Transaction{transaction("0x1", operation(0, "CALL", "0xa", "-2"))},
+			expected: []*OperationDivergence{
+				{
+					TransactionHash: "0x1",
+					OperationIndex:  index(0),
+					Reason:          "amount differs",
+					Primary:         operation(0, "CALL", "0xa", "-1"),
+					Secondary:       operation(0, "CALL", "0xa", "-2"),
+				},
+			},
+		},
+		"type differs": {
+			primary:   []*RosettaTypes.Transaction{transaction("0x1", operation(0, "CALL", "0xa", "-1"))},
+			secondary: []*RosettaTypes.Transaction{transaction("0x1", operation(0, "DELEGATECALL", "0xa", "-1"))},
+			expected: []*OperationDivergence{
+				{
+					TransactionHash: "0x1",
+					OperationIndex:  index(0),
+					Reason:          "type differs",
+					Primary:         operation(0, "CALL", "0xa", "-1"),
+					Secondary:       operation(0, "DELEGATECALL", "0xa", "-1"),
+				},
+			},
+		},
+		"missing operations": {
+			primary: []*RosettaTypes.Transaction{
+				transaction("0x1", operation(0, "CALL", "0xa", "-1"), operation(1, "CALL", "0xb", "1")),
+				transaction("0x2"),
+			},
+			secondary: []*RosettaTypes.Transaction{
+				transaction("0x1", operation(0, "CALL", "0xa", "-1")),
+				transaction("0x2", operation(0, "FEE", "0xa", "-5")),
+			},
+			expected: []*OperationDivergence{
+				{
+					TransactionHash: "0x1",
+					OperationIndex:  index(1),
+					Reason:          "operation missing from the secondary transaction",
+					Primary:         operation(1, "CALL", "0xb", "1"),
+				},
+				{
+					TransactionHash: "0x2",
+					OperationIndex:  index(0),
+					Reason:          "operation missing from the primary transaction",
+					Secondary:       operation(0, "FEE", "0xa", "-5"),
+				},
+			},
+		},
+		"missing transactions": {
+			primary:   []*RosettaTypes.Transaction{transaction("0x1"), transaction("0x2")},
+			secondary: []*RosettaTypes.Transaction{transaction("0x2"), transaction("0x3")},
+			expected: []*OperationDivergence{
+				{TransactionHash: "0x1", Reason: "transaction missing from the secondary block"},
+				{TransactionHash: "0x3", Reason: "transaction missing from the primary block"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report, err := DiffBlockOperations(
+				&RosettaTypes.Block{BlockIdentifier: identifier, Transactions: test.primary},
+				&RosettaTypes.Block{BlockIdentifier: identifier, Transactions: test.secondary},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, identifier, report.Block)
+			assert.Equal(t, len(test.primary), report.Transactions)
+			assert.Equal(t, test.expected, report.Divergences)
+		})
+	}
+
+	t.Run("different blocks", func(t *testing.T) {
+		_, err := DiffBlockOperations(
+			&RosettaTypes.Block{BlockIdentifier: identifier},
+			&RosettaTypes.Block{BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: 10, Hash: "0x0b"}},
+		)
+		assert.EqualError(t, err, "block 10 0x0a does not match block 10 0x0b")
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Operation metadata keys of the AssetTypes.UserOperationOpType operations
+const (
+	UserOperationHashMetadataKey        = "user_op_hash"
+	UserOperationEntryPointMetadataKey  = "entry_point"
+	UserOperationNonceMetadataKey       = "nonce"
+	UserOperationPaymasterMetadataKey   = "paymaster"
+	UserOperationGasCostMetadataKey     = "actual_gas_cost"
+	UserOperationGasUsedMetadataKey     = "actual_gas_used"
+	UserOperationCallDataMetadataKey    = "call_data"
+	UserOperationInitCodeMetadataKey    = "init_code"
+	UserOperationBeneficiaryMetadataKey = "beneficiary"
+	UserOperationLogIndexMetadataKey    = "log_index"
+)
+
+// appendUserOperationOps appends an AssetTypes.UserOperationOpType operation
+// for every ERC-4337 user operation of tx to ops, when user operations are
+// decoded. The operations have no amount: the transfers of the user
+// operations, and the gas refund of the beneficiary, are already operations
+// of the traces of tx.
+func (s *BlockAPIService) appendUserOperationOps(
+	tx *client.LoadedTransaction,
+	logs []*EthTypes.Log,
+	ops []*RosettaTypes.Operation,
+) ([]*RosettaTypes.Operation, error) {
+	if !s.config.RosettaCfg.DecodeUserOperations || tx.Transaction == nil {
+		return ops, nil
+	}
+
+	userOps, err := client.ParseUserOperations(s.config.RosettaCfg.EntryPoints(), tx.Transaction, logs)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the user operations of %s: %w", tx.TxHash, err)
+	}
+
+	b := NewOperationBuilder(int64(len(ops)))
+	for _, userOp := range userOps {
+		b.Add(userOperationOp(userOp))
+	}
+
+	return append(ops, b.Operations()...), nil
+}
+
+// userOperationOp returns the operation of a user operation, failed when its
+// execution reverted
+func userOperationOp(userOp *client.UserOperation) *RosettaTypes.Operation {
+	status := AssetTypes.SuccessStatus
+	if !userOp.Success {
+		status = AssetTypes.FailureStatus
+	}
+
+	metadata := map[string]interface{}{
+		UserOperationHashMetadataKey:       userOp.Hash.Hex(),
+		UserOperationEntryPointMetadataKey: userOp.EntryPoint.Hex(),
+		UserOperationNonceMetadataKey:      userOp.Nonce.String(),
+		UserOperationGasCostMetadataKey:    userOp.ActualGasCost.String(),
+		UserOperationGasUsedMetadataKey:    userOp.ActualGasUsed.String(),
+		UserOperationLogIndexMetadataKey:   userOp.LogIndex,
+	}
+	if userOp.Paymaster != (common.Address{}) {
+		metadata[UserOperationPaymasterMetadataKey] = client.FormatAddress(userOp.Paymaster)
+	}
+	if userOp.Beneficiary != nil {
+		metadata[UserOperationCallDataMetadataKey] = hexutil.Encode(userOp.CallData)
+		metadata[UserOperationBeneficiaryMetadataKey] = client.FormatAddress(*userOp.Beneficiary)
+		if len(userOp.InitCode) > 0 {
+			metadata[UserOperationInitCodeMetadataKey] = hexutil.Encode(userOp.InitCode)
+		}
+	}
+
+	return &RosettaTypes.Operation{
+		Type:     AssetTypes.UserOperationOpType,
+		Status:   RosettaTypes.String(status),
+		Account:  client.Account(&userOp.Sender),
+		Metadata: metadata,
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// userOperationLog returns a UserOperationEvent log of an entry point
+func userOperationLog(
+	entryPoint common.Address,
+	index uint,
+	sender common.Address,
+	paymaster common.Address,
+	success bool,
+) *EthTypes.Log {
+	topic := crypto.Keccak256Hash([]byte("UserOperationEvent(bytes32,address,address,uint256,bool,uint256,uint256)"))
+	successWord := big.NewInt(0)
+	if success {
+		successWord = big.NewInt(1)
+	}
+
+	var data []byte
+	for _, word := range []*big.Int{big.NewInt(3), successWord, big.NewInt(1000), big.NewInt(100)} {
+		data = append(data, common.LeftPadBytes(word.Bytes(), 32)...)
+	}
+
+	return &EthTypes.Log{
+		Address: entryPoint,
+		Index:   index,
+		Topics: []common.Hash{
+			topic,
+			common.HexToHash("0xaa"),
+			common.BytesToHash(sender.Bytes()),
+			common.BytesToHash(paymaster.Bytes()),
+		},
+		Data: data,
+	}
+}
+
+func TestAppendUserOperationOps(t *testing.T) {
+	entryPoint := common.HexToAddress(configuration.DefaultEntryPointAddresses[0])
+	bundler := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	sender := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	paymaster := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	txHash := common.HexToHash("0xbb")
+	tx := &client.LoadedTransaction{
+		TxHash:      &txHash,
+		Transaction: EthTypes.NewTx(&EthTypes.LegacyTx{To: &bundler}),
+	}
+	logs := []*EthTypes.Log{
+		userOperationLog(entryPoint, 0, sender, common.Address{}, true),
+		{Address: sender},
+		userOperationLog(entryPoint, 2, sender, paymaster, false),
+	}
+	ops := []*RosettaTypes.Operation{{OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0}, Type: "FEE"}}
+
+	t.Run("disabled", func(t *testing.T) {
+		s := &BlockAPIService{config: &configuration.Configuration{}}
+		result, err := s.appendUserOperationOps(tx, logs, ops)
+		assert.NoError(t, err)
+		assert.Equal(t, ops, result)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		s := &BlockAPIService{config: &configuration.Configuration{
+			RosettaCfg: configuration.RosettaConfig{DecodeUserOperations: true},
+		}}
+		result, err := s.appendUserOperationOps(tx, logs, ops)
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+		assert.NoError(t, ValidateOperationIndexes(result))
+
+		assert.Equal(t, &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+			Type:                AssetTypes.UserOperationOpType,
+			Status:              RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account:             client.Account(&sender),
+			Metadata: map[string]interface{}{
+				UserOperationHashMetadataKey:       common.HexToHash("0xaa").Hex(),
+				UserOperationEntryPointMetadataKey: entryPoint.Hex(),
+				UserOperationNonceMetadataKey:      "3",
+				UserOperationGasCostMetadataKey:    "1000",
+				UserOperationGasUsedMetadataKey:    "100",
+				UserOperationLogIndexMetadataKey:   uint(0),
+			},
+		}, result[1])
+
+		// A reverted user operation fails, its bundle doesn't
+		assert.Equal(t, AssetTypes.FailureStatus, *result[2].Status)
+		assert.Equal(t, client.FormatAddress(paymaster), result[2].Metadata[UserOperationPaymasterMetadataKey])
+		assert.NotContains(t, result[2].Metadata, UserOperationCallDataMetadataKey)
+	})
+}
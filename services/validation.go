@@ -0,0 +1,42 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+)
+
+// applyValidationPolicy returns the trustless validation error err of subject if
+// the validation policy is FailValidationPolicy, and logs it otherwise. Errors
+// of cancelled requests are always returned.
+func applyValidationPolicy(
+	ctx context.Context,
+	cfg *configuration.Configuration,
+	subject string,
+	err error,
+) error {
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if cfg.RosettaCfg.TrustlessValidationPolicy != configuration.FailValidationPolicy {
+		log.Printf("trustless validation of %s failed: %v", subject, err)
+		return nil
+	}
+
+	return err
+}
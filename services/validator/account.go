@@ -0,0 +1,286 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	// ErrInvalidAccountProof is returned when the account proof of the node does
+	// not prove the account against the state root of the block
+	ErrInvalidAccountProof = errors.New("invalid account proof")
+
+	// ErrBalanceMismatch is returned when a balance does not match the proven balance
+	ErrBalanceMismatch = errors.New("balance mismatch")
+
+	// ErrProofWindowExceeded is returned when the node can't prove state that old
+	ErrProofWindowExceeded = errors.New("block is outside the proof window")
+
+	// stats counts the account proofs that were skipped (proofs_skipped), those
+	// of them done at the nearest provable block instead (proofs_fallback), and
+	// the total distance of those fallbacks in blocks (proofs_fallback_blocks)
+	stats = expvar.NewMap("trustless_validation")
+)
+
+// proofWindowMessage is part of the eth_getProof error for blocks whose state
+// is too old to prove
+const proofWindowMessage = "exceeds maximum proof window"
+
+// AccountValidation is the result of validating an account
+type AccountValidation struct {
+	// BlockNumber is the block the account was proven at
+	BlockNumber uint64 `json:"block_number"`
+
+	// Delta is the distance in blocks between the validated block and BlockNumber
+	Delta uint64 `json:"delta"`
+
+	// Skipped is set when the account could not be proven at the validated
+	// block, even if it was proven at the nearest provable block
+	Skipped bool `json:"skipped"`
+
+	// ProvenBalance is the balance proven at the nearest provable block, set
+	// when the validated block could not be proven. It is not compared with
+	// the validated balance, which is of another block.
+	ProvenBalance *big.Int `json:"proven_balance,omitempty"`
+}
+
+// accountResult is the result of eth_getProof
+type accountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+}
+
+// stateAccount is the consensus encoding of an account in the state trie
+type stateAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// ValidateAccount checks that balance is the native balance of address at the
+// block with blockHash. The account is proven with eth_getProof against the
+// state root of the block header.
+func (v *TrustlessValidator) ValidateAccount(
+	ctx context.Context,
+	address common.Address,
+	blockHash common.Hash,
+	balance *big.Int,
+) error {
+	_, err := v.ValidateAccountWithResult(ctx, address, blockHash, balance)
+	return err
+}
+
+// ValidateAccountWithResult is ValidateAccount, also returning the block the
+// account was proven at. When the node can't prove the block because it is
+// outside its proof window, validation is skipped. With
+// WithNearestProvableBlock, the account is still proven at the nearest
+// provable block, whose proven balance and distance are reported with the
+// skipped result.
+func (v *TrustlessValidator) ValidateAccountWithResult(
+	ctx context.Context,
+	address common.Address,
+	blockHash common.Hash,
+	balance *big.Int,
+) (*AccountValidation, error) {
+	var raw json.RawMessage
+	if err := v.client.CallContext(ctx, &raw, "eth_getBlockByHash", blockHash, false); err != nil {
+		return nil, fmt.Errorf("could not get header of block %s: %w", blockHash, err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+	header, extraFields, err := decodeHeader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode header of block %s: %w", blockHash, err)
+	}
+	if err := v.validateHeaderHash(header, extraFields, blockHash); err != nil {
+		return nil, err
+	}
+
+	result := &AccountValidation{BlockNumber: header.Number.Uint64()}
+	account, err := v.proveAccount(ctx, address, header)
+	if errors.Is(err, ErrProofWindowExceeded) {
+		stats.Add("proofs_skipped", 1)
+		result.Skipped = true
+		if !v.nearestProvableBlock {
+			return result, nil
+		}
+
+		header, err = v.nearestProvableHeader(ctx, address, header.Number)
+		if err != nil {
+			return nil, err
+		}
+		account, err = v.proveAccount(ctx, address, header)
+		if err != nil {
+			return nil, err
+		}
+		result.Delta = header.Number.Uint64() - result.BlockNumber
+		result.BlockNumber = header.Number.Uint64()
+		result.ProvenBalance = account.Balance
+		stats.Add("proofs_fallback", 1)
+		stats.Add("proofs_fallback_blocks", int64(result.Delta))
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Balance.Cmp(balance) != 0 {
+		return nil, fmt.Errorf(
+			"%w: %s has balance %s at block %d, proven %s",
+			ErrBalanceMismatch,
+			address,
+			balance,
+			result.BlockNumber,
+			account.Balance,
+		)
+	}
+
+	return result, nil
+}
+
+// nearestProvableHeader returns the header of the first block after number the
+// node can prove address at, searching up to the latest block
+func (v *TrustlessValidator) nearestProvableHeader(
+	ctx context.Context,
+	address common.Address,
+	number *big.Int,
+) (*EthTypes.Header, error) {
+	latest, err := v.headerByNumber(ctx, "latest")
+	if err != nil {
+		return nil, err
+	}
+
+	// The proof window ends at the latest block, so search for its start
+	lo, hi := new(big.Int).Add(number, common.Big1), new(big.Int).Set(latest.Number)
+	for lo.Cmp(hi) < 0 {
+		mid := new(big.Int).Rsh(new(big.Int).Add(lo, hi), 1)
+		var result accountResult
+		err := v.client.CallContext(ctx, &result, "eth_getProof", address, []string{}, hexutil.EncodeBig(mid))
+		switch {
+		case isProofWindowError(err):
+			lo = mid.Add(mid, common.Big1)
+		case err != nil:
+			return nil, fmt.Errorf("could not get proof of %s at block %d: %w", address, mid, err)
+		default:
+			hi = mid
+		}
+	}
+	if lo.Cmp(latest.Number) == 0 {
+		return latest, nil
+	}
+
+	return v.headerByNumber(ctx, hexutil.EncodeBig(lo))
+}
+
+// headerByNumber returns the header of the block number, a number or a tag,
+// checking that it hashes to the hash the node returns for the block
+func (v *TrustlessValidator) headerByNumber(ctx context.Context, number string) (*EthTypes.Header, error) {
+	var raw json.RawMessage
+	if err := v.client.CallContext(ctx, &raw, "eth_getBlockByNumber", number, false); err != nil {
+		return nil, fmt.Errorf("could not get header of block %s: %w", number, err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("block %s not found", number)
+	}
+	var block struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, fmt.Errorf("could not decode hash of block %s: %w", number, err)
+	}
+	header, extraFields, err := decodeHeader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode header of block %s: %w", number, err)
+	}
+	if err := v.validateHeaderHash(header, extraFields, block.Hash); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// isProofWindowError returns true if err is the eth_getProof error for blocks
+// outside the proof window of the node
+func isProofWindowError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), proofWindowMessage)
+}
+
+// proveAccount returns the state of address at header, proven with eth_getProof
+// against the state root of header. Accounts that don't exist have no nonce and
+// no balance.
+func (v *TrustlessValidator) proveAccount(
+	ctx context.Context,
+	address common.Address,
+	header *EthTypes.Header,
+) (*stateAccount, error) {
+	var result accountResult
+	err := v.client.CallContext(
+		ctx,
+		&result,
+		"eth_getProof",
+		address,
+		[]string{},
+		hexutil.EncodeBig(header.Number),
+	)
+	if isProofWindowError(err) {
+		return nil, fmt.Errorf("%w: %s at block %d: %v", ErrProofWindowExceeded, address, header.Number, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get proof of %s at block %d: %w", address, header.Number, err)
+	}
+
+	proofDB := memorydb.New()
+	for _, node := range result.AccountProof {
+		if err := proofDB.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	value, err := trie.VerifyProof(header.Root, crypto.Keccak256(address.Bytes()), proofDB)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s at block %d: %v", ErrInvalidAccountProof, address, header.Number, err)
+	}
+
+	account := &stateAccount{Balance: new(big.Int)}
+	if len(value) > 0 {
+		if err := rlp.DecodeBytes(value, account); err != nil {
+			return nil, fmt.Errorf("%w: %s at block %d: %v", ErrInvalidAccountProof, address, header.Number, err)
+		}
+	}
+	if result.Balance == nil || result.Balance.ToInt().Cmp(account.Balance) != 0 ||
+		uint64(result.Nonce) != account.Nonce {
+		return nil, fmt.Errorf("%w: %s at block %d does not match its proof", ErrInvalidAccountProof, address, header.Number)
+	}
+
+	return account, nil
+}
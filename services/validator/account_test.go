@@ -0,0 +1,278 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"math/big"
+	"testing"
+
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stateWithAccounts returns a state trie holding the balances of the accounts
+func stateWithAccounts(t *testing.T, balances map[common.Address]int64) *trie.Trie {
+	state := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	for address, balance := range balances {
+		encoded, err := rlp.EncodeToBytes(&stateAccount{
+			Nonce:    1,
+			Balance:  big.NewInt(balance),
+			Root:     EthTypes.EmptyRootHash,
+			CodeHash: crypto.Keccak256(nil),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, state.Update(crypto.Keccak256(address.Bytes()), encoded))
+	}
+	return state
+}
+
+// rawHeader returns the JSON RPC encoding of header
+func rawHeader(t *testing.T, header *EthTypes.Header) json.RawMessage {
+	raw, err := json.Marshal(header)
+	assert.NoError(t, err)
+	return raw
+}
+
+// mockProof mocks the header and the eth_getProof result of address at header
+func mockProof(
+	t *testing.T,
+	mockClient *mockedServices.Client,
+	state *trie.Trie,
+	header *EthTypes.Header,
+	address common.Address,
+	balance int64,
+	nonce uint64,
+) {
+	var proof proofNodes
+	assert.NoError(t, state.Prove(crypto.Keccak256(address.Bytes()), &proof))
+
+	mockClient.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByHash", header.Hash(), false).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = rawHeader(t, header)
+		},
+	).Once()
+	mockClient.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_getProof",
+		address,
+		[]string{},
+		hexutil.EncodeBig(header.Number),
+	).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*accountResult)) = accountResult{
+				Address:      address,
+				AccountProof: proof,
+				Balance:      (*hexutil.Big)(big.NewInt(balance)),
+				Nonce:        hexutil.Uint64(nonce),
+			}
+		},
+	).Once()
+}
+
+func TestValidateAccount(t *testing.T) {
+	ctx := context.Background()
+	alice := common.HexToAddress("0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0")
+	bob := common.HexToAddress("0x0d2b2Fb39b10cd50caB7aa8E834879069AB1A8d4")
+	missing := common.HexToAddress("0x1E77ad77925Ac0075CF61Fb76bA35D884985019d")
+	state := stateWithAccounts(t, map[common.Address]int64{alice: 100, bob: 7})
+	header := &EthTypes.Header{Number: big.NewInt(10), Difficulty: new(big.Int), Root: state.Hash()}
+
+	tests := map[string]struct {
+		address     common.Address
+		balance     int64
+		provenNonce uint64
+		nodeBalance int64
+		expectedErr error
+	}{
+		"valid": {
+			address:     alice,
+			balance:     100,
+			provenNonce: 1,
+			nodeBalance: 100,
+		},
+		"balance mismatch": {
+			address:     bob,
+			balance:     8,
+			provenNonce: 1,
+			nodeBalance: 7,
+			expectedErr: ErrBalanceMismatch,
+		},
+		"missing account": {
+			address: missing,
+		},
+		"proof does not match result": {
+			address:     alice,
+			balance:     101,
+			provenNonce: 1,
+			nodeBalance: 101,
+			expectedErr: ErrInvalidAccountProof,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			mockProof(t, mockClient, state, header, test.address, test.nodeBalance, test.provenNonce)
+
+			err := New(mockClient).ValidateAccount(ctx, test.address, header.Hash(), big.NewInt(test.balance))
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateAccount_InvalidProof(t *testing.T) {
+	alice := common.HexToAddress("0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0")
+	state := stateWithAccounts(t, map[common.Address]int64{alice: 100})
+	// The proof is for another state root
+	header := &EthTypes.Header{Number: big.NewInt(10), Difficulty: new(big.Int), Root: common.HexToHash("0x1")}
+
+	mockClient := &mockedServices.Client{}
+	mockProof(t, mockClient, state, header, alice, 100, 1)
+
+	err := New(mockClient).ValidateAccount(context.Background(), alice, header.Hash(), big.NewInt(100))
+	assert.ErrorIs(t, err, ErrInvalidAccountProof)
+}
+
+// statValue returns the value of the trustless_validation counter key
+func statValue(key string) int64 {
+	if v, ok := stats.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestValidateAccount_ProofWindow(t *testing.T) {
+	ctx := context.Background()
+	alice := common.HexToAddress("0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0")
+	state := stateWithAccounts(t, map[common.Address]int64{alice: 100})
+	var proof proofNodes
+	assert.NoError(t, state.Prove(crypto.Keccak256(alice.Bytes()), &proof))
+
+	headers := map[int64]*EthTypes.Header{}
+	for _, number := range []int64{10, 15, 20} {
+		headers[number] = &EthTypes.Header{Number: big.NewInt(number), Difficulty: new(big.Int), Root: state.Hash()}
+	}
+	// The node can prove blocks 15 to 20
+	const windowStart = 15
+
+	mockNode := func(mockClient *mockedServices.Client) {
+		mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByHash", headers[10].Hash(), false).Return(nil).Run(
+			func(args mock.Arguments) {
+				*(args.Get(1).(*json.RawMessage)) = rawHeader(t, headers[10])
+			},
+		).Once()
+		mockClient.On("CallContext", ctx, mock.Anything, "eth_getProof", alice, []string{}, mock.Anything).Return(
+			func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+				number, err := hexutil.DecodeBig(args[2].(string))
+				assert.NoError(t, err)
+				if number.Int64() < windowStart {
+					return errors.New("distance to target block exceeds maximum proof window")
+				}
+				*(result.(*accountResult)) = accountResult{
+					Address:      alice,
+					AccountProof: proof,
+					Balance:      (*hexutil.Big)(big.NewInt(100)),
+					Nonce:        1,
+				}
+				return nil
+			},
+		)
+	}
+
+	t.Run("skipped", func(t *testing.T) {
+		skipped := statValue("proofs_skipped")
+		mockClient := &mockedServices.Client{}
+		mockNode(mockClient)
+
+		result, err := New(mockClient).ValidateAccountWithResult(ctx, alice, headers[10].Hash(), big.NewInt(1))
+		assert.NoError(t, err)
+		assert.Equal(t, &AccountValidation{BlockNumber: 10, Skipped: true}, result)
+		assert.Equal(t, skipped+1, statValue("proofs_skipped"))
+	})
+
+	mockHeaderByNumber := func(mockClient *mockedServices.Client, number string, raw json.RawMessage) {
+		mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByNumber", number, false).Return(nil).Run(
+			func(args mock.Arguments) {
+				*(args.Get(1).(*json.RawMessage)) = raw
+			},
+		).Once()
+	}
+
+	t.Run("nearest provable block", func(t *testing.T) {
+		skipped := statValue("proofs_skipped")
+		fallbacks := statValue("proofs_fallback")
+		mockClient := &mockedServices.Client{}
+		mockNode(mockClient)
+		mockHeaderByNumber(mockClient, "latest", rawHeader(t, headers[20]))
+		mockHeaderByNumber(mockClient, "0xf", rawHeader(t, headers[15]))
+
+		// The balance at block 10 can't be compared with the proof at block 15,
+		// so the validation is skipped with the proven balance
+		result, err := New(mockClient, WithNearestProvableBlock()).ValidateAccountWithResult(
+			ctx,
+			alice,
+			headers[10].Hash(),
+			big.NewInt(1),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, &AccountValidation{BlockNumber: 15, Delta: 5, Skipped: true, ProvenBalance: big.NewInt(100)}, result)
+		assert.Equal(t, skipped+1, statValue("proofs_skipped"))
+		assert.Equal(t, fallbacks+1, statValue("proofs_fallback"))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("nearest provable block with invalid header", func(t *testing.T) {
+		mockClient := &mockedServices.Client{}
+		mockNode(mockClient)
+		mockHeaderByNumber(mockClient, "latest", rawHeader(t, headers[20]))
+
+		// The header of block 15 claims the hash of block 20
+		var fields map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rawHeader(t, headers[15]), &fields))
+		fields["hash"] = headers[20].Hash()
+		tampered, err := json.Marshal(fields)
+		assert.NoError(t, err)
+		mockHeaderByNumber(mockClient, "0xf", tampered)
+
+		_, err = New(mockClient, WithNearestProvableBlock()).ValidateAccountWithResult(
+			ctx,
+			alice,
+			headers[10].Hash(),
+			big.NewInt(1),
+		)
+		assert.ErrorIs(t, err, ErrBlockHashMismatch)
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// HeaderHasher computes the block hash of a header, for chains whose block hash
+// is not the keccak256 hash of the RLP encoded header like geth computes it, e.g.
+// Sonic, or that hash header fields geth doesn't decode. extraFields are the
+// fields of the JSON RPC block that are not part of a standard block. /block
+// only has them with RosettaConfig.TolerantHeaderDecoding.
+//
+// NewTrustlessValidator uses the client as the HeaderHasher when it implements
+// the interface.
+type HeaderHasher interface {
+	HashHeader(header *EthTypes.Header, extraFields map[string]json.RawMessage) (common.Hash, error)
+}
+
+// HashFunc computes the block hash of a header
+type HashFunc func(header *EthTypes.Header) common.Hash
+
+// HashHeader implements HeaderHasher, ignoring the extra header fields
+func (f HashFunc) HashHeader(header *EthTypes.Header, _ map[string]json.RawMessage) (common.Hash, error) {
+	return f(header), nil
+}
+
+// Option configures a TrustlessValidator created with New
+type Option func(*TrustlessValidator)
+
+// WithChainConfig sets the chain config that determines the receipt encoding
+// and the transaction signer of a block
+func WithChainConfig(chainConfig *params.ChainConfig) Option {
+	return func(v *TrustlessValidator) {
+		v.chainConfig = chainConfig
+	}
+}
+
+// WithSigner sets the signer transaction senders are recovered with, instead of
+// the signer of the chain config at the block
+func WithSigner(signer EthTypes.Signer) Option {
+	return func(v *TrustlessValidator) {
+		v.signer = signer
+	}
+}
+
+// WithHashFunc sets the function computing block hashes, for chains whose block
+// hash is not the keccak256 hash of the RLP encoded header
+func WithHashFunc(hashFunc HashFunc) Option {
+	return WithHeaderHasher(hashFunc)
+}
+
+// WithHeaderHasher sets the HeaderHasher computing block hashes
+func WithHeaderHasher(hasher HeaderHasher) Option {
+	return func(v *TrustlessValidator) {
+		v.headerHasher = hasher
+	}
+}
+
+// WithSkipSenderCheck disables the validation of transaction senders
+func WithSkipSenderCheck() Option {
+	return func(v *TrustlessValidator) {
+		v.skipSenderCheck = true
+	}
+}
+
+// WithSignerRoutines sets the number of goroutines recovering transaction senders
+func WithSignerRoutines(routines int) Option {
+	return func(v *TrustlessValidator) {
+		if routines > 0 {
+			v.signerRoutines = routines
+		}
+	}
+}
+
+// WithFailFast makes sender validation stop at the first mismatch instead of
+// reporting the mismatches of all transactions
+func WithFailFast() Option {
+	return func(v *TrustlessValidator) {
+		v.failFast = true
+	}
+}
+
+// WithNearestProvableBlock makes account validation prove the account at the
+// nearest block the node can prove when the validated block is outside its
+// proof window. The validation is still reported as skipped, see
+// AccountValidation.ProvenBalance.
+func WithNearestProvableBlock() Option {
+	return func(v *TrustlessValidator) {
+		v.nearestProvableBlock = true
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ErrInvalidProof is returned when a Merkle proof does not prove its value
+var ErrInvalidProof = errors.New("invalid proof")
+
+// ReceiptProof is the Merkle Patricia proof of a receipt in the receipts trie of
+// a block. The receipt is in its consensus encoding.
+type ReceiptProof struct {
+	BlockHash    common.Hash     `json:"block_hash"`
+	ReceiptsRoot common.Hash     `json:"receipts_root"`
+	Index        uint64          `json:"index"`
+	Receipt      hexutil.Bytes   `json:"receipt"`
+	Proof        []hexutil.Bytes `json:"proof"`
+}
+
+// TransactionProof is the Merkle Patricia proof of a transaction in the
+// transactions trie of a block. The transaction is in its consensus encoding.
+type TransactionProof struct {
+	BlockHash        common.Hash     `json:"block_hash"`
+	TransactionsRoot common.Hash     `json:"transactions_root"`
+	Index            uint64          `json:"index"`
+	Transaction      hexutil.Bytes   `json:"transaction"`
+	Proof            []hexutil.Bytes `json:"proof"`
+}
+
+// proofNodes collects the trie nodes of a proof
+type proofNodes []hexutil.Bytes
+
+func (p *proofNodes) Put(key []byte, value []byte) error {
+	*p = append(*p, common.CopyBytes(value))
+	return nil
+}
+
+func (p *proofNodes) Delete(key []byte) error {
+	return nil
+}
+
+// GetTransactionReceiptProof returns the proof of the receipt of transaction
+// txHash of block against the receipts root of the block header. The receipts
+// trie is built from the raw receipts of the block, which must match the root.
+func (v *TrustlessValidator) GetTransactionReceiptProof(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+	txHash common.Hash,
+) (*ReceiptProof, error) {
+	index, err := transactionIndex(block, blockHash, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := v.GetRawReceipts(ctx, block, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.normalizeReceipts(block.Number(), receipts); err != nil {
+		return nil, fmt.Errorf("block %s: %w", blockHash, err)
+	}
+
+	root, value, proof, err := proveListItem(receipts, index)
+	if err != nil {
+		return nil, err
+	}
+	if root != block.ReceiptHash() {
+		return nil, fmt.Errorf(
+			"%w: block %s has receipts root %s, computed %s",
+			ErrReceiptsRootMismatch,
+			blockHash,
+			block.ReceiptHash(),
+			root,
+		)
+	}
+
+	return &ReceiptProof{
+		BlockHash:    blockHash,
+		ReceiptsRoot: root,
+		Index:        uint64(index),
+		Receipt:      value,
+		Proof:        proof,
+	}, nil
+}
+
+// VerifyReceiptProof checks that proof proves its receipt against its receipts
+// root and returns the decoded receipt
+func VerifyReceiptProof(proof *ReceiptProof) (*EthTypes.Receipt, error) {
+	if err := verifyListItem(proof.ReceiptsRoot, proof.Index, proof.Receipt, proof.Proof); err != nil {
+		return nil, err
+	}
+
+	var receipt EthTypes.Receipt
+	if err := receipt.UnmarshalBinary(proof.Receipt); err != nil {
+		return nil, fmt.Errorf("could not decode receipt: %w", err)
+	}
+	return &receipt, nil
+}
+
+// GetTransactionProof fetches the body of the block with blockHash and returns
+// the proof of transaction txHash against its transactions root, see
+// ProveTransaction
+func (v *TrustlessValidator) GetTransactionProof(
+	ctx context.Context,
+	blockHash common.Hash,
+	txHash common.Hash,
+) (*TransactionProof, error) {
+	var raw json.RawMessage
+	if err := v.client.CallContext(ctx, &raw, "eth_getBlockByHash", blockHash, true); err != nil {
+		return nil, fmt.Errorf("could not get block %s: %w", blockHash, err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+
+	header, extraFields, err := decodeHeader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode header of block %s: %w", blockHash, err)
+	}
+	var body struct {
+		Transactions []*EthTypes.Transaction `json:"transactions"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("could not decode transactions of block %s: %w", blockHash, err)
+	}
+
+	return v.proveTransaction(
+		EthTypes.NewBlockWithHeader(header).WithBody(body.Transactions, nil),
+		extraFields,
+		blockHash,
+		txHash,
+	)
+}
+
+// ProveTransaction returns the proof of transaction txHash of block against the
+// transactions root of the block header, after checking that the header hashes
+// to blockHash and the transactions of block match the root
+func (v *TrustlessValidator) ProveTransaction(
+	block *EthTypes.Block,
+	blockHash common.Hash,
+	txHash common.Hash,
+) (*TransactionProof, error) {
+	return v.proveTransaction(block, nil, blockHash, txHash)
+}
+
+// proveTransaction is ProveTransaction for a block with the extra header fields
+// of the HeaderHasher
+func (v *TrustlessValidator) proveTransaction(
+	block *EthTypes.Block,
+	extraFields map[string]json.RawMessage,
+	blockHash common.Hash,
+	txHash common.Hash,
+) (*TransactionProof, error) {
+	header := block.Header()
+	if err := v.validateHeaderHash(header, extraFields, blockHash); err != nil {
+		return nil, err
+	}
+
+	index, err := transactionIndex(block, blockHash, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	root, value, proof, err := proveListItem(block.Transactions(), index)
+	if err != nil {
+		return nil, err
+	}
+	if root != header.TxHash {
+		return nil, fmt.Errorf(
+			"%w: block %s has transactions root %s, computed %s",
+			ErrTransactionsRootMismatch,
+			blockHash,
+			header.TxHash,
+			root,
+		)
+	}
+
+	return &TransactionProof{
+		BlockHash:        blockHash,
+		TransactionsRoot: root,
+		Index:            uint64(index),
+		Transaction:      value,
+		Proof:            proof,
+	}, nil
+}
+
+// VerifyTransactionProof checks that proof proves its transaction against its
+// transactions root and returns the decoded transaction
+func VerifyTransactionProof(proof *TransactionProof) (*EthTypes.Transaction, error) {
+	if err := verifyListItem(proof.TransactionsRoot, proof.Index, proof.Transaction, proof.Proof); err != nil {
+		return nil, err
+	}
+
+	var tx EthTypes.Transaction
+	if err := tx.UnmarshalBinary(proof.Transaction); err != nil {
+		return nil, fmt.Errorf("could not decode transaction: %w", err)
+	}
+	return &tx, nil
+}
+
+// transactionIndex returns the index of transaction txHash in block
+func transactionIndex(block *EthTypes.Block, blockHash common.Hash, txHash common.Hash) (int, error) {
+	for i, tx := range block.Transactions() {
+		if tx.Hash() == txHash {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("transaction %s is not in block %s", txHash, blockHash)
+}
+
+// proveListItem builds the trie of list, like EthTypes.DeriveSha, and returns its
+// root with the encoding and the proof of the item at index
+func proveListItem(list EthTypes.DerivableList, index int) (common.Hash, []byte, []hexutil.Bytes, error) {
+	listTrie := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	var value []byte
+	for i := 0; i < list.Len(); i++ {
+		var buf bytes.Buffer
+		list.EncodeIndex(i, &buf)
+		if i == index {
+			value = common.CopyBytes(buf.Bytes())
+		}
+		if err := listTrie.Update(rlp.AppendUint64(nil, uint64(i)), buf.Bytes()); err != nil {
+			return common.Hash{}, nil, nil, err
+		}
+	}
+
+	var proof proofNodes
+	if err := listTrie.Prove(rlp.AppendUint64(nil, uint64(index)), &proof); err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+
+	return listTrie.Hash(), value, proof, nil
+}
+
+// verifyListItem checks that proof proves value at index of the list trie with root
+func verifyListItem(root common.Hash, index uint64, value []byte, proof []hexutil.Bytes) error {
+	proofDB := memorydb.New()
+	for _, node := range proof {
+		if err := proofDB.Put(crypto.Keccak256(node), node); err != nil {
+			return err
+		}
+	}
+
+	proven, err := trie.VerifyProof(root, rlp.AppendUint64(nil, index), proofDB)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if !bytes.Equal(proven, value) {
+		return fmt.Errorf("%w: item %d does not match the proven value", ErrInvalidProof, index)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTransactionReceiptProof(t *testing.T) {
+	ctx := context.Background()
+	block, receipts := testBlock()
+	txHash := block.Transactions()[1].Hash()
+
+	tests := map[string]struct {
+		txHash   common.Hash
+		receipts EthTypes.Receipts
+		fetch    bool
+
+		expectedErr    error
+		expectedErrMsg string
+	}{
+		"valid": {
+			txHash:   txHash,
+			receipts: receipts,
+			fetch:    true,
+		},
+		"missing receipt": {
+			txHash:      txHash,
+			receipts:    EthTypes.Receipts{receipts[0], receipts[0]},
+			fetch:       true,
+			expectedErr: ErrReceiptsRootMismatch,
+		},
+		"unknown transaction": {
+			txHash:         common.HexToHash("0x1"),
+			expectedErrMsg: "is not in block",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			if test.fetch {
+				raw, err := json.Marshal(test.receipts)
+				assert.NoError(t, err)
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockReceipts", block.Hash()).
+					Return(nil).Run(
+					func(args mock.Arguments) {
+						*(args.Get(1).(*json.RawMessage)) = raw
+					},
+				).Once()
+			}
+
+			validator := NewTrustlessValidator(&configuration.Configuration{}, mockClient)
+			proof, err := validator.GetTransactionReceiptProof(ctx, block, block.Hash(), test.txHash)
+			switch {
+			case test.expectedErr != nil:
+				assert.ErrorIs(t, err, test.expectedErr)
+			case test.expectedErrMsg != "":
+				assert.ErrorContains(t, err, test.expectedErrMsg)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, block.ReceiptHash(), proof.ReceiptsRoot)
+				assert.Equal(t, uint64(1), proof.Index)
+
+				receipt, err := VerifyReceiptProof(proof)
+				assert.NoError(t, err)
+				assert.Equal(t, receipts[1].Status, receipt.Status)
+				assert.Equal(t, receipts[1].CumulativeGasUsed, receipt.CumulativeGasUsed)
+				assert.Len(t, receipt.Logs, 1)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyReceiptProof_Invalid(t *testing.T) {
+	block, receipts := testBlock()
+	root, value, nodes, err := proveListItem(receipts, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, block.ReceiptHash(), root)
+
+	proof := &ReceiptProof{ReceiptsRoot: root, Index: 1, Receipt: value, Proof: nodes}
+	_, err = VerifyReceiptProof(proof)
+	assert.NoError(t, err)
+
+	tampered := *proof
+	tampered.Index = 0
+	_, err = VerifyReceiptProof(&tampered)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	tampered = *proof
+	tampered.ReceiptsRoot = common.HexToHash("0x1")
+	_, err = VerifyReceiptProof(&tampered)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestProveTransaction(t *testing.T) {
+	block, _ := testBlock()
+	validator := NewTrustlessValidator(&configuration.Configuration{}, &mockedServices.Client{})
+	txHash := block.Transactions()[1].Hash()
+
+	proof, err := validator.ProveTransaction(block, block.Hash(), txHash)
+	assert.NoError(t, err)
+	tx, err := VerifyTransactionProof(proof)
+	assert.NoError(t, err)
+	assert.Equal(t, txHash, tx.Hash())
+
+	_, err = validator.ProveTransaction(block, common.HexToHash("0x1"), txHash)
+	assert.ErrorIs(t, err, ErrBlockHashMismatch)
+
+	tampered := block.WithBody(EthTypes.Transactions{block.Transactions()[1]}, nil)
+	_, err = validator.ProveTransaction(tampered, block.Hash(), txHash)
+	assert.ErrorIs(t, err, ErrTransactionsRootMismatch)
+}
@@ -0,0 +1,371 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validator verifies the data returned by a node against the
+// commitments of the block header, so Rosetta responses don't have to trust
+// the node blindly.
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// ErrBlockHashMismatch is returned when the header does not hash to the block hash
+	ErrBlockHashMismatch = errors.New("block hash mismatch")
+
+	// ErrTransactionsRootMismatch is returned when the transactions of a block don't
+	// match the transactions root of its header
+	ErrTransactionsRootMismatch = errors.New("transactions root mismatch")
+
+	// ErrReceiptsRootMismatch is returned when the receipts of a block don't match
+	// the receipts root of its header
+	ErrReceiptsRootMismatch = errors.New("receipts root mismatch")
+
+	// ErrSenderMismatch is returned when the sender recovered from the signature
+	// of a transaction is not the sender returned by the node
+	ErrSenderMismatch = errors.New("sender mismatch")
+
+	// ErrMissingPostState is returned when the node omits the post transaction
+	// state root of a receipt from before Byzantium
+	ErrMissingPostState = errors.New("missing receipt post state")
+)
+
+// Client is the node API used by the validator. It is implemented by
+// construction.Client.
+type Client interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// TrustlessValidator validates blocks fetched from a node against their header.
+// It can be used outside the Rosetta services, e.g. by indexers.
+type TrustlessValidator struct {
+	client       Client
+	chainConfig  *params.ChainConfig
+	signer       EthTypes.Signer
+	headerHasher HeaderHasher
+
+	skipSenderCheck      bool
+	signerRoutines       int
+	failFast             bool
+	nearestProvableBlock bool
+}
+
+// New returns a TrustlessValidator that fetches the data it needs from client.
+// Without options, receipts are encoded as returned by the node, senders are
+// recovered with the latest signer, and blocks are hashed like geth does.
+func New(client Client, opts ...Option) *TrustlessValidator {
+	v := &TrustlessValidator{
+		client:         client,
+		headerHasher:   HashFunc(func(header *EthTypes.Header) common.Hash { return header.Hash() }),
+		signerRoutines: configuration.DefaultSignerValidationRoutines,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// NewTrustlessValidator returns a TrustlessValidator configured by the chain
+// config and the signer validation settings of cfg.
+func NewTrustlessValidator(cfg *configuration.Configuration, client Client) *TrustlessValidator {
+	opts := []Option{
+		WithChainConfig(cfg.ChainConfig),
+		WithSignerRoutines(cfg.RosettaCfg.SignerValidationRoutines),
+	}
+	if cfg.RosettaCfg.SignerValidationFailFast {
+		opts = append(opts, WithFailFast())
+	}
+	if cfg.RosettaCfg.ValidateNearestProvableBlock {
+		opts = append(opts, WithNearestProvableBlock())
+	}
+	if hasher, ok := client.(HeaderHasher); ok {
+		opts = append(opts, WithHeaderHasher(hasher))
+	}
+
+	return New(client, opts...)
+}
+
+// ValidateBlock checks that the header of block hashes to blockHash and that the
+// transactions of block and the receipts returned by the node match the header.
+func (v *TrustlessValidator) ValidateBlock(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+) error {
+	return v.ValidateBlockWithExtraFields(ctx, block, blockHash, nil)
+}
+
+// ValidateBlockWithExtraFields is ValidateBlock for blocks with header fields
+// geth doesn't decode, which are passed to the HeaderHasher
+func (v *TrustlessValidator) ValidateBlockWithExtraFields(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+	extraFields map[string]json.RawMessage,
+) error {
+	header := block.Header()
+	if err := v.validateHeaderHash(header, extraFields, blockHash); err != nil {
+		return err
+	}
+
+	txRoot := EthTypes.DeriveSha(block.Transactions(), trie.NewStackTrie(nil))
+	if txRoot != header.TxHash {
+		return fmt.Errorf(
+			"%w: block %s has transactions root %s, computed %s",
+			ErrTransactionsRootMismatch,
+			blockHash,
+			header.TxHash,
+			txRoot,
+		)
+	}
+
+	return v.ValidateReceipts(ctx, block, blockHash)
+}
+
+// ValidateReceipts checks that the receipts of the block returned by the node
+// match the receipts root of its header. The receipts are decoded from
+// eth_getBlockReceipts as is, instead of from the Rosetta receipts, so all the
+// consensus fields are kept.
+func (v *TrustlessValidator) ValidateReceipts(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+) error {
+	header := block.Header()
+	receipts, err := v.GetRawReceipts(ctx, block, blockHash)
+	if err != nil {
+		return err
+	}
+	if err := v.normalizeReceipts(header.Number, receipts); err != nil {
+		return fmt.Errorf("block %s: %w", blockHash, err)
+	}
+
+	receiptsRoot := EthTypes.DeriveSha(receipts, trie.NewStackTrie(nil))
+	if receiptsRoot != header.ReceiptHash {
+		return fmt.Errorf(
+			"%w: block %s has receipts root %s, computed %s",
+			ErrReceiptsRootMismatch,
+			blockHash,
+			header.ReceiptHash,
+			receiptsRoot,
+		)
+	}
+
+	return nil
+}
+
+// ValidateTransactions checks that the sender recovered from the signature of
+// every transaction of block is the sender returned by the node, in senders.
+// Senders are recovered concurrently. With fail fast enabled, the first failure
+// cancels the remaining work; otherwise all failures are returned.
+func (v *TrustlessValidator) ValidateTransactions(
+	ctx context.Context,
+	block *EthTypes.Block,
+	senders []common.Address,
+) error {
+	if v.skipSenderCheck {
+		return nil
+	}
+
+	txs := block.Transactions()
+	if len(senders) != len(txs) {
+		return fmt.Errorf("got %d senders for %d transactions", len(senders), len(txs))
+	}
+
+	signer := v.signer
+	if signer == nil && v.chainConfig != nil {
+		signer = EthTypes.MakeSigner(v.chainConfig, block.Number(), block.Time())
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(v.signerRoutines)
+	failures := make([]error, len(txs))
+	for i := range txs {
+		i := i
+		if gctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+
+			err := validateSender(signer, txs[i], senders[i])
+			if err != nil && v.failFast {
+				return err
+			}
+			failures[i] = err
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return errors.Join(failures...)
+}
+
+// decodeHeader decodes the header of a JSON RPC block and returns it with the
+// fields of the block that are not part of a standard block
+func decodeHeader(raw json.RawMessage) (*EthTypes.Header, map[string]json.RawMessage, error) {
+	var header EthTypes.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, nil, err
+	}
+	extraFields, err := client.HeaderExtraFields(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &header, extraFields, nil
+}
+
+// validateHeaderHash checks that header hashes to blockHash
+func (v *TrustlessValidator) validateHeaderHash(
+	header *EthTypes.Header,
+	extraFields map[string]json.RawMessage,
+	blockHash common.Hash,
+) error {
+	hash, err := v.headerHasher.HashHeader(header, extraFields)
+	if err != nil {
+		return fmt.Errorf("could not hash header of block %d: %w", header.Number, err)
+	}
+	if hash != blockHash {
+		return fmt.Errorf("%w: header of block %d hashes to %s, expected %s", ErrBlockHashMismatch, header.Number, hash, blockHash)
+	}
+
+	return nil
+}
+
+// validateSender checks that sender signed tx
+func validateSender(signer EthTypes.Signer, tx *EthTypes.Transaction, sender common.Address) error {
+	if signer == nil {
+		signer = EthTypes.LatestSignerForChainID(tx.ChainId())
+	}
+
+	recovered, err := EthTypes.Sender(signer, tx)
+	if err != nil {
+		return fmt.Errorf("could not recover sender of %s: %w", tx.Hash(), err)
+	}
+	if recovered != sender {
+		return fmt.Errorf("%w: %s is signed by %s, not %s", ErrSenderMismatch, tx.Hash(), recovered, sender)
+	}
+
+	return nil
+}
+
+// GetRawReceipts returns the receipts of a block from eth_getBlockReceipts, or
+// from eth_getTransactionReceipt for nodes that don't support it
+func (v *TrustlessValidator) GetRawReceipts(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+) (EthTypes.Receipts, error) {
+	var raw json.RawMessage
+	err := v.client.CallContext(ctx, &raw, "eth_getBlockReceipts", blockHash)
+	if client.IsMethodNotFound(err) {
+		return v.getTransactionReceipts(ctx, block, blockHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get receipts of block %s: %w", blockHash, err)
+	}
+
+	var receipts EthTypes.Receipts
+	if err := json.Unmarshal(raw, &receipts); err != nil {
+		return nil, fmt.Errorf("could not decode receipts of block %s: %w", blockHash, err)
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			return nil, fmt.Errorf("got null receipt %d of block %s", i, blockHash)
+		}
+	}
+
+	return receipts, nil
+}
+
+// getTransactionReceipts returns the receipts of the transactions of block
+func (v *TrustlessValidator) getTransactionReceipts(
+	ctx context.Context,
+	block *EthTypes.Block,
+	blockHash common.Hash,
+) (EthTypes.Receipts, error) {
+	txs := block.Transactions()
+	receipts := make(EthTypes.Receipts, len(txs))
+	reqs := make([]rpc.BatchElem, len(txs))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{txs[i].Hash()},
+			Result: &receipts[i],
+		}
+	}
+	if len(reqs) > 0 {
+		if err := v.client.BatchCallContext(ctx, reqs); err != nil {
+			return nil, fmt.Errorf("could not get receipts of block %s: %w", blockHash, err)
+		}
+	}
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, fmt.Errorf("could not get receipt of %s: %w", txs[i].Hash(), reqs[i].Error)
+		}
+		if receipts[i] == nil {
+			return nil, fmt.Errorf("got null receipt for %s", txs[i].Hash())
+		}
+	}
+
+	return receipts, nil
+}
+
+// normalizeReceipts makes the status fields of receipts match their consensus
+// encoding at block number. Receipts before Byzantium commit to the post state
+// root, while later receipts commit to the status, even if the node returns a
+// root for them.
+func (v *TrustlessValidator) normalizeReceipts(number *big.Int, receipts EthTypes.Receipts) error {
+	if v.chainConfig == nil {
+		return nil
+	}
+
+	byzantium := v.chainConfig.IsByzantium(number)
+	for i, receipt := range receipts {
+		if byzantium {
+			receipt.PostState = nil
+			continue
+		}
+		if len(receipt.PostState) != common.HashLength {
+			return fmt.Errorf("%w: receipt %d", ErrMissingPostState, i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
+
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testBlock returns a block with two transactions and their receipts
+func testBlock() (*EthTypes.Block, EthTypes.Receipts) {
+	return testBlockAt(10, false)
+}
+
+// testBlockAt returns a block at number with two transactions and their receipts,
+// which commit to post state roots when postState is set
+func testBlockAt(number int64, postState bool) (*EthTypes.Block, EthTypes.Receipts) {
+	token := common.HexToAddress("0x1E77ad77925Ac0075CF61Fb76bA35D884985019d")
+	txs := EthTypes.Transactions{
+		EthTypes.NewTransaction(0, token, big.NewInt(1), 21000, big.NewInt(1), nil),
+		EthTypes.NewTx(&EthTypes.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     1,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       60000,
+			To:        &token,
+			Data:      common.FromHex("0xa9059cbb"),
+		}),
+	}
+	receipts := EthTypes.Receipts{
+		{
+			Type:              EthTypes.LegacyTxType,
+			Status:            EthTypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			GasUsed:           21000,
+			TxHash:            txs[0].Hash(),
+			Logs:              []*EthTypes.Log{},
+		},
+		{
+			Type:              EthTypes.DynamicFeeTxType,
+			Status:            EthTypes.ReceiptStatusFailed,
+			CumulativeGasUsed: 72000,
+			GasUsed:           51000,
+			TxHash:            txs[1].Hash(),
+			Logs: []*EthTypes.Log{
+				{
+					Address: token,
+					Topics:  []common.Hash{common.HexToHash("0xddf252ad")},
+					Data:    common.FromHex("0x01"),
+					TxHash:  txs[1].Hash(),
+				},
+			},
+		},
+	}
+	for i, receipt := range receipts {
+		receipt.Bloom = EthTypes.CreateBloom(EthTypes.Receipts{receipt})
+		if postState {
+			receipt.Type = EthTypes.LegacyTxType
+			receipt.PostState = common.BigToHash(big.NewInt(int64(i + 1))).Bytes()
+		}
+	}
+	if postState {
+		txs = txs[:1]
+		receipts = receipts[:1]
+	}
+
+	header := &EthTypes.Header{Number: big.NewInt(number), Difficulty: new(big.Int)}
+	block := EthTypes.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	return block, receipts
+}
+
+func TestValidateBlock(t *testing.T) {
+	ctx := context.Background()
+	block, receipts := testBlock()
+
+	tests := map[string]struct {
+		blockHash common.Hash
+		receipts  EthTypes.Receipts
+		fetch     bool
+
+		expectedErr error
+	}{
+		"valid": {
+			blockHash: block.Hash(),
+			receipts:  receipts,
+			fetch:     true,
+		},
+		"wrong block hash": {
+			blockHash:   common.HexToHash("0x1"),
+			expectedErr: ErrBlockHashMismatch,
+		},
+		"missing receipt": {
+			blockHash:   block.Hash(),
+			receipts:    receipts[:1],
+			fetch:       true,
+			expectedErr: ErrReceiptsRootMismatch,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			if test.fetch {
+				raw, err := json.Marshal(test.receipts)
+				assert.NoError(t, err)
+				mockClient.On(
+					"CallContext",
+					ctx,
+					mock.Anything,
+					"eth_getBlockReceipts",
+					test.blockHash,
+				).Return(
+					nil,
+				).Run(
+					func(args mock.Arguments) {
+						*(args.Get(1).(*json.RawMessage)) = raw
+					},
+				).Once()
+			}
+
+			err := NewTrustlessValidator(&configuration.Configuration{}, mockClient).ValidateBlock(ctx, block, test.blockHash)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateBlock_Transactions(t *testing.T) {
+	block, _ := testBlock()
+	tampered := block.WithBody(block.Transactions()[:1], nil)
+
+	err := NewTrustlessValidator(&configuration.Configuration{}, &mockedServices.Client{}).ValidateBlock(
+		context.Background(),
+		tampered,
+		block.Hash(),
+	)
+	assert.ErrorIs(t, err, ErrTransactionsRootMismatch)
+}
+
+type methodNotFoundError struct{}
+
+func (methodNotFoundError) Error() string  { return "the method eth_getBlockReceipts does not exist" }
+func (methodNotFoundError) ErrorCode() int { return testutil.MethodNotFoundCode }
+
+func TestValidateReceipts_PostState(t *testing.T) {
+	ctx := context.Background()
+	byzantium := params.MainnetChainConfig.ByzantiumBlock.Int64()
+
+	preByzantium, legacyReceipts := testBlockAt(byzantium-1, true)
+	postByzantium, receipts := testBlockAt(byzantium, false)
+
+	// Receipts as returned by nodes that also set a root after Byzantium
+	rootedReceipts := make(EthTypes.Receipts, len(receipts))
+	for i, receipt := range receipts {
+		rooted := *receipt
+		rooted.PostState = common.Hash{}.Bytes()
+		rootedReceipts[i] = &rooted
+	}
+	// Receipts as returned by nodes without the post state
+	strippedReceipts := EthTypes.Receipts{{
+		Type:              legacyReceipts[0].Type,
+		Status:            EthTypes.ReceiptStatusSuccessful,
+		CumulativeGasUsed: legacyReceipts[0].CumulativeGasUsed,
+		GasUsed:           legacyReceipts[0].GasUsed,
+		Bloom:             legacyReceipts[0].Bloom,
+		Logs:              legacyReceipts[0].Logs,
+		TxHash:            legacyReceipts[0].TxHash,
+	}}
+
+	tests := map[string]struct {
+		block          *EthTypes.Block
+		receipts       EthTypes.Receipts
+		methodNotFound bool
+
+		expectedErr error
+	}{
+		"pre byzantium": {
+			block:    preByzantium,
+			receipts: legacyReceipts,
+		},
+		"pre byzantium without eth_getBlockReceipts": {
+			block:          preByzantium,
+			receipts:       legacyReceipts,
+			methodNotFound: true,
+		},
+		"pre byzantium without post state": {
+			block:       preByzantium,
+			receipts:    strippedReceipts,
+			expectedErr: ErrMissingPostState,
+		},
+		"post byzantium with root": {
+			block:    postByzantium,
+			receipts: rootedReceipts,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mockedServices.Client{}
+			blockHash := test.block.Hash()
+			if test.methodNotFound {
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockReceipts", blockHash).
+					Return(methodNotFoundError{}).Once()
+				mockClient.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(
+					func(args mock.Arguments) {
+						reqs := args.Get(1).([]rpc.BatchElem)
+						for i := range reqs {
+							assert.Equal(t, "eth_getTransactionReceipt", reqs[i].Method)
+							*(reqs[i].Result.(**EthTypes.Receipt)) = test.receipts[i]
+						}
+					},
+				).Once()
+			} else {
+				raw, err := json.Marshal(test.receipts)
+				assert.NoError(t, err)
+				mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockReceipts", blockHash).
+					Return(nil).Run(
+					func(args mock.Arguments) {
+						*(args.Get(1).(*json.RawMessage)) = raw
+					},
+				).Once()
+			}
+
+			validator := NewTrustlessValidator(&configuration.Configuration{ChainConfig: params.MainnetChainConfig}, mockClient)
+			err := validator.ValidateBlock(ctx, test.block, blockHash)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateTransactions(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := EthTypes.LatestSignerForChainID(big.NewInt(1))
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	other := common.HexToAddress("0x4dC8f417d4eB731D179A0F08b1feaF25216cEfd0")
+
+	txs := make(EthTypes.Transactions, 5)
+	for i := range txs {
+		tx, err := EthTypes.SignNewTx(key, signer, &EthTypes.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &other,
+			Value:    big.NewInt(1),
+			Gas:      21000,
+			GasPrice: big.NewInt(1),
+		})
+		assert.NoError(t, err)
+		txs[i] = tx
+	}
+	block := EthTypes.NewBlock(
+		&EthTypes.Header{Number: big.NewInt(10), Difficulty: new(big.Int)},
+		txs,
+		nil,
+		nil,
+		trie.NewStackTrie(nil),
+	)
+	senders := func(mismatches ...int) []common.Address {
+		addrs := []common.Address{sender, sender, sender, sender, sender}
+		for _, i := range mismatches {
+			addrs[i] = other
+		}
+		return addrs
+	}
+
+	tests := map[string]struct {
+		failFast bool
+		senders  []common.Address
+		cancel   bool
+
+		expectedErr      error
+		expectedFailures int
+	}{
+		"valid": {
+			senders: senders(),
+		},
+		"all mismatches": {
+			senders:          senders(1, 3),
+			expectedErr:      ErrSenderMismatch,
+			expectedFailures: 2,
+		},
+		"fail fast": {
+			failFast:         true,
+			senders:          senders(1, 3),
+			expectedErr:      ErrSenderMismatch,
+			expectedFailures: 1,
+		},
+		"cancelled": {
+			senders:     senders(),
+			cancel:      true,
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+
+			validator := NewTrustlessValidator(&configuration.Configuration{
+				RosettaCfg: configuration.RosettaConfig{
+					SignerValidationRoutines: 2,
+					SignerValidationFailFast: test.failFast,
+				},
+			}, &mockedServices.Client{})
+			err := validator.ValidateTransactions(ctx, block, test.senders)
+			if test.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.expectedErr)
+			if test.expectedFailures > 0 {
+				failures := 1
+				if joined, ok := err.(interface{ Unwrap() []error }); ok {
+					failures = len(joined.Unwrap())
+				}
+				assert.Equal(t, test.expectedFailures, failures)
+			}
+		})
+	}
+
+	validator := NewTrustlessValidator(&configuration.Configuration{}, &mockedServices.Client{})
+	assert.EqualError(
+		t,
+		validator.ValidateTransactions(context.Background(), block, senders()[:1]),
+		"got 1 senders for 5 transactions",
+	)
+}
+
+func TestNew_Options(t *testing.T) {
+	ctx := context.Background()
+	block, receipts := testBlock()
+	customHash := common.HexToHash("0xc0ffee")
+
+	raw, err := json.Marshal(receipts)
+	assert.NoError(t, err)
+	mockClient := &mockedServices.Client{}
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockReceipts", customHash).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = raw
+		},
+	).Once()
+
+	validator := New(
+		mockClient,
+		WithHashFunc(func(header *EthTypes.Header) common.Hash { return customHash }),
+	)
+	assert.NoError(t, validator.ValidateBlock(ctx, block, customHash))
+	assert.ErrorIs(t, validator.ValidateBlock(ctx, block, block.Hash()), ErrBlockHashMismatch)
+
+	// The transactions of testBlock are unsigned
+	assert.Error(t, New(mockClient).ValidateTransactions(ctx, block, make([]common.Address, 2)))
+	assert.NoError(t, New(mockClient, WithSkipSenderCheck()).ValidateTransactions(ctx, block, nil))
+
+	// Transactions signed for a chain id can't be recovered by a homestead signer
+	key, _ := crypto.GenerateKey()
+	tx, err := EthTypes.SignNewTx(key, EthTypes.LatestSignerForChainID(big.NewInt(1)), &EthTypes.LegacyTx{
+		Gas:      21000,
+		GasPrice: big.NewInt(1),
+	})
+	assert.NoError(t, err)
+	signed := block.WithBody(EthTypes.Transactions{tx}, nil)
+	senders := []common.Address{crypto.PubkeyToAddress(key.PublicKey)}
+	assert.NoError(t, New(mockClient).ValidateTransactions(ctx, signed, senders))
+	assert.Error(t, New(mockClient, WithSigner(EthTypes.HomesteadSigner{})).ValidateTransactions(ctx, signed, senders))
+
+	mockClient.AssertExpectations(t)
+}
+
+// hashingClient is the client of a chain whose block hash is in the sonicHash
+// header field
+type hashingClient struct {
+	*mockedServices.Client
+}
+
+func (c *hashingClient) HashHeader(
+	header *EthTypes.Header,
+	extraFields map[string]json.RawMessage,
+) (common.Hash, error) {
+	var hash common.Hash
+	if err := json.Unmarshal(extraFields["sonicHash"], &hash); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+func TestHeaderHasher(t *testing.T) {
+	ctx := context.Background()
+	block, _ := testBlock()
+	customHash := common.HexToHash("0xc0ffee")
+	txHash := block.Transactions()[1].Hash()
+	extraFields := map[string]json.RawMessage{"sonicHash": json.RawMessage(`"` + customHash.Hex() + `"`)}
+
+	mockClient := &mockedServices.Client{}
+	validator := NewTrustlessValidator(&configuration.Configuration{}, &hashingClient{Client: mockClient})
+
+	// The header can't be hashed without its extra fields
+	_, err := validator.ProveTransaction(block, customHash, txHash)
+	assert.Error(t, err)
+	assert.ErrorIs(
+		t,
+		validator.ValidateBlockWithExtraFields(ctx, block, block.Hash(), extraFields),
+		ErrBlockHashMismatch,
+	)
+
+	encoded, err := json.Marshal(block.Header())
+	assert.NoError(t, err)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	fields["transactions"] = block.Transactions()
+	fields["sonicHash"] = customHash
+	raw, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	mockClient.On("CallContext", ctx, mock.Anything, "eth_getBlockByHash", customHash, true).Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = raw
+		},
+	).Once()
+
+	proof, err := validator.GetTransactionProof(ctx, customHash, txHash)
+	assert.NoError(t, err)
+	tx, err := VerifyTransactionProof(proof)
+	assert.NoError(t, err)
+	assert.Equal(t, txHash, tx.Hash())
+
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Operation metadata keys of the AssetTypes.WithdrawalOpType operations
+const (
+	WithdrawalIndexMetadataKey = "withdrawal_index"
+	ValidatorIndexMetadataKey  = "validator_index"
+)
+
+// withdrawalTransaction returns the transaction of the withdrawals of a
+// block, with a WITHDRAWAL operation crediting each withdrawal. Withdrawals
+// have no transaction hash, the transaction is identified by the block hash
+// suffixed with -withdrawals so it doesn't collide with the reward transaction.
+// Their amounts are converted to WithdrawalOpCurrency with the withdrawal
+// multiplier of cfg.
+func withdrawalTransaction(
+	cfg configuration.RosettaConfig,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	withdrawals []*EthTypes.Withdrawal,
+) *RosettaTypes.Transaction {
+	currency := cfg.WithdrawalOpCurrency()
+	multiplier := cfg.WithdrawalAmountMultiplier()
+
+	b := NewOperationBuilder(0)
+	for _, withdrawal := range withdrawals {
+		address := withdrawal.Address
+		amount := new(big.Int).Mul(new(big.Int).SetUint64(withdrawal.Amount), multiplier)
+		b.Add(&RosettaTypes.Operation{
+			Type:    AssetTypes.WithdrawalOpType,
+			Status:  RosettaTypes.String(AssetTypes.SuccessStatus),
+			Account: client.Account(&address),
+			Amount: &RosettaTypes.Amount{
+				Value:    amount.String(),
+				Currency: currency,
+			},
+			Metadata: map[string]interface{}{
+				WithdrawalIndexMetadataKey: withdrawal.Index,
+				ValidatorIndexMetadataKey:  withdrawal.Validator,
+			},
+		})
+	}
+
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: fmt.Sprintf("%s-withdrawals", blockIdentifier.Hash),
+		},
+		Operations: b.Operations(),
+	}
+}
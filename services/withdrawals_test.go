@@ -0,0 +1,82 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawalTransaction(t *testing.T) {
+	eth := &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18}
+	gno := &RosettaTypes.Currency{Symbol: "GNO", Decimals: 18}
+	blockIdentifier := &RosettaTypes.BlockIdentifier{Index: 10, Hash: "0xaa"}
+	withdrawals := []*EthTypes.Withdrawal{
+		{Index: 5, Validator: 7, Address: common.HexToAddress("0x1111111111111111111111111111111111111111"), Amount: 32},
+		{Index: 6, Validator: 8, Address: common.HexToAddress("0x2222222222222222222222222222222222222222"), Amount: 1},
+	}
+
+	tests := map[string]struct {
+		cfg              configuration.RosettaConfig
+		expectedCurrency *RosettaTypes.Currency
+		expectedAmounts  []string
+	}{
+		"gwei of the native currency": {
+			cfg:              configuration.RosettaConfig{Currency: eth},
+			expectedCurrency: eth,
+			expectedAmounts:  []string{"32000000000", "1000000000"},
+		},
+		"staking token": {
+			// Gnosis withdrawals are denominated in mGNO, 1/32 GNO
+			cfg: configuration.RosettaConfig{
+				Currency:             &RosettaTypes.Currency{Symbol: "XDAI", Decimals: 18},
+				WithdrawalCurrency:   gno,
+				WithdrawalMultiplier: big.NewInt(31250000),
+			},
+			expectedCurrency: gno,
+			expectedAmounts:  []string{"1000000000", "31250000"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := withdrawalTransaction(test.cfg, blockIdentifier, withdrawals)
+			assert.Equal(t, "0xaa-withdrawals", tx.TransactionIdentifier.Hash)
+			assert.NoError(t, ValidateOperationIndexes(tx.Operations))
+			assert.Len(t, tx.Operations, len(withdrawals))
+			for i, op := range tx.Operations {
+				assert.Equal(t, AssetTypes.WithdrawalOpType, op.Type)
+				assert.Equal(t, AssetTypes.SuccessStatus, *op.Status)
+				assert.Equal(t, withdrawals[i].Address.Hex(), op.Account.Address)
+				assert.Equal(t, &RosettaTypes.Amount{
+					Value:    test.expectedAmounts[i],
+					Currency: test.expectedCurrency,
+				}, op.Amount)
+				assert.Equal(t, map[string]interface{}{
+					WithdrawalIndexMetadataKey: withdrawals[i].Index,
+					ValidatorIndexMetadataKey:  withdrawals[i].Validator,
+				}, op.Metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// secp256k1OID is the object identifier of the secp256k1 curve
+	secp256k1OID = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+	// secp256k1N and secp256k1HalfN are the order of the secp256k1 curve and
+	// its half, above which signatures are malleable
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// KMSClient is a secp256k1 key of a key management service. Keys of AWS KMS
+// (ECC_SECG_P256K1 keys, signing digests with ECDSA_SHA_256) and of Google
+// Cloud KMS (EC_SIGN_SECP256K1_SHA256 keys) are adapted with a few calls of
+// their SDK, so the SDK doesn't depend on them.
+type KMSClient interface {
+	// PublicKey returns the public key, a DER encoded SubjectPublicKeyInfo
+	// as returned by AWS KMS, or its PEM encoding as returned by Google
+	// Cloud KMS
+	PublicKey(ctx context.Context) ([]byte, error)
+
+	// SignDigest returns the DER encoded ECDSA signature of a 32 bytes digest
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// KMSSigner is a Signer whose key never leaves a key management service
+type KMSSigner struct {
+	client    KMSClient
+	publicKey *ecdsa.PublicKey
+}
+
+// NewKMSSigner returns a KMSSigner signing with the key of client
+func NewKMSSigner(ctx context.Context, client KMSClient) (*KMSSigner, error) {
+	encoded, err := client.PublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the kms public key: %w", err)
+	}
+
+	publicKey, err := parsePublicKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kms public key: %w", err)
+	}
+
+	return &KMSSigner{client: client, publicKey: publicKey}, nil
+}
+
+// PublicKey implements Signer
+func (s *KMSSigner) PublicKey() *RosettaTypes.PublicKey {
+	return &RosettaTypes.PublicKey{
+		Bytes:     crypto.CompressPubkey(s.publicKey),
+		CurveType: RosettaTypes.Secp256k1,
+	}
+}
+
+// Sign implements Signer with recoverable ECDSA signatures. The signatures of
+// the service are normalized to their low S form, and their recovery id is
+// found with the public key.
+func (s *KMSSigner) Sign(ctx context.Context, payload *RosettaTypes.SigningPayload) (*RosettaTypes.Signature, error) {
+	if err := checkPayload(payload); err != nil {
+		return nil, err
+	}
+
+	der, err := s.client.SignDigest(ctx, payload.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("kms could not sign: %w", err)
+	}
+
+	signature, err := recoverableSignature(payload.Bytes, der, s.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RosettaTypes.Signature{
+		SigningPayload: payload,
+		PublicKey:      s.PublicKey(),
+		SignatureType:  RosettaTypes.EcdsaRecovery,
+		Bytes:          signature,
+	}, nil
+}
+
+// parsePublicKey parses a secp256k1 SubjectPublicKeyInfo, DER or PEM encoded.
+// The crypto/x509 package doesn't support the secp256k1 curve.
+func parsePublicKey(encoded []byte) (*ecdsa.PublicKey, error) {
+	if block, _ := pem.Decode(encoded); block != nil {
+		encoded = block.Bytes
+	}
+
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if rest, err := asn1.Unmarshal(encoded, &info); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after the public key")
+	}
+
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return nil, fmt.Errorf("invalid curve: %w", err)
+	}
+	if !curve.Equal(secp256k1OID) {
+		return nil, fmt.Errorf("curve %s is not secp256k1", curve)
+	}
+
+	return crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+}
+
+// recoverableSignature converts the DER encoded signature of digest by
+// publicKey to a 65 bytes [R || S || V] signature, with V the recovery id
+func recoverableSignature(digest []byte, der []byte, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	var parsed struct {
+		R *big.Int
+		S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid kms signature: %w", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("invalid kms signature: trailing data")
+	}
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 ||
+		parsed.R.Cmp(secp256k1N) >= 0 || parsed.S.Cmp(secp256k1N) >= 0 {
+		return nil, errors.New("invalid kms signature: values out of range")
+	}
+
+	s := parsed.S
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	parsed.R.FillBytes(signature[:32])
+	s.FillBytes(signature[32:64])
+
+	expected := crypto.FromECDSAPub(publicKey)
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		recovered, err := crypto.Ecrecover(digest, signature)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return signature, nil
+		}
+	}
+
+	return nil, errors.New("kms signature was not made by its public key")
+}
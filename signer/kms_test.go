@@ -0,0 +1,155 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS is a KMSClient signing with a key in memory
+type fakeKMS struct {
+	key       *ecdsa.PrivateKey
+	curve     asn1.ObjectIdentifier
+	pem       bool
+	highS     bool
+	signError error
+}
+
+func (f *fakeKMS) PublicKey(context.Context) ([]byte, error) {
+	curve, err := asn1.Marshal(f.curve)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := crypto.FromECDSAPub(&f.key.PublicKey)
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{Bytes: publicKey, BitLength: 8 * len(publicKey)},
+	})
+	if err != nil || !f.pem {
+		return der, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func (f *fakeKMS) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	if f.signError != nil {
+		return nil, f.signError
+	}
+	signature, err := crypto.Sign(digest, f.key)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if f.highS {
+		s.Sub(secp256k1N, s)
+	}
+	return asn1.Marshal(struct {
+		R *big.Int
+		S *big.Int
+	}{r, s})
+}
+
+func TestKMSSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	digest := crypto.Keccak256([]byte("payload"))
+
+	tests := map[string]struct {
+		client *fakeKMS
+
+		expectedKeyErr  bool
+		expectedSignErr bool
+	}{
+		"der public key": {
+			client: &fakeKMS{key: key, curve: secp256k1OID},
+		},
+		"pem public key": {
+			client: &fakeKMS{key: key, curve: secp256k1OID, pem: true},
+		},
+		"high s signature": {
+			client: &fakeKMS{key: key, curve: secp256k1OID, highS: true},
+		},
+		"p256 public key": {
+			client:         &fakeKMS{key: key, curve: asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}},
+			expectedKeyErr: true,
+		},
+		"sign error": {
+			client:          &fakeKMS{key: key, curve: secp256k1OID, signError: errors.New("denied")},
+			expectedSignErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewKMSSigner(context.Background(), test.client)
+			if test.expectedKeyErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			address, err := Address(signer)
+			require.NoError(t, err)
+			assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), address)
+
+			signature, err := signer.Sign(context.Background(), &RosettaTypes.SigningPayload{Bytes: digest})
+			if test.expectedSignErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.True(t, new(big.Int).SetBytes(signature.Bytes[32:64]).Cmp(secp256k1HalfN) <= 0)
+			recovered, err := crypto.SigToPub(digest, signature.Bytes)
+			require.NoError(t, err)
+			assert.Equal(t, address, crypto.PubkeyToAddress(*recovered))
+		})
+	}
+}
+
+func TestRecoverableSignature_OtherKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	digest := crypto.Keccak256([]byte("payload"))
+
+	der, err := (&fakeKMS{key: other}).SignDigest(context.Background(), digest)
+	require.NoError(t, err)
+	_, err = recoverableSignature(digest, der, &key.PublicKey)
+	assert.Error(t, err)
+
+	_, err = recoverableSignature(digest, []byte{0x30, 0x00}, &key.PublicKey)
+	assert.Error(t, err)
+}
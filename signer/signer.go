@@ -0,0 +1,128 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signer signs the payloads of the construction API with keys held in
+// memory, in encrypted keystore files or in a key management service. Signers
+// are used by the clients of a deployed service, see rosettaclient, and by
+// the server itself when server side signing is enabled, see
+// configuration.RosettaConfig.ServerSideSigning.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs the payloads of transactions
+type Signer interface {
+	// PublicKey returns the public key the sender account is derived from
+	PublicKey() *RosettaTypes.PublicKey
+
+	// Sign returns the signature of payload
+	Sign(ctx context.Context, payload *RosettaTypes.SigningPayload) (*RosettaTypes.Signature, error)
+}
+
+// Provider is implemented by clients providing the server side signer, e.g.
+// a KMSSigner with the key management service of the operator
+type Provider interface {
+	ServerSigner(ctx context.Context) (Signer, error)
+}
+
+// Address returns the address of the account of signer
+func Address(signer Signer) (common.Address, error) {
+	publicKey := signer.PublicKey()
+	if publicKey == nil || publicKey.CurveType != RosettaTypes.Secp256k1 {
+		return common.Address{}, errors.New("signer has no secp256k1 public key")
+	}
+
+	key, err := crypto.DecompressPubkey(publicKey.Bytes)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signer public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+// KeySigner is a Signer holding a secp256k1 private key in memory
+type KeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner returns a KeySigner signing with key
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+// NewKeystoreSigner returns a KeySigner signing with the key of the encrypted
+// JSON keystore file at path, as written by geth account new
+func NewKeystoreSigner(path string, passphrase string) (*KeySigner, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore %s: %w", path, err)
+	}
+
+	key, err := keystore.DecryptKey(encrypted, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt keystore %s: %w", path, err)
+	}
+
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+// PublicKey implements Signer
+func (s *KeySigner) PublicKey() *RosettaTypes.PublicKey {
+	return &RosettaTypes.PublicKey{
+		Bytes:     crypto.CompressPubkey(&s.key.PublicKey),
+		CurveType: RosettaTypes.Secp256k1,
+	}
+}
+
+// Sign implements Signer with recoverable ECDSA signatures
+func (s *KeySigner) Sign(_ context.Context, payload *RosettaTypes.SigningPayload) (*RosettaTypes.Signature, error) {
+	if err := checkPayload(payload); err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.Sign(payload.Bytes, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RosettaTypes.Signature{
+		SigningPayload: payload,
+		PublicKey:      s.PublicKey(),
+		SignatureType:  RosettaTypes.EcdsaRecovery,
+		Bytes:          signature,
+	}, nil
+}
+
+// checkPayload checks that payload is a digest to sign with a recoverable
+// ECDSA signature
+func checkPayload(payload *RosettaTypes.SigningPayload) error {
+	if len(payload.SignatureType) > 0 && payload.SignatureType != RosettaTypes.EcdsaRecovery {
+		return fmt.Errorf("unsupported signature type %s", payload.SignatureType)
+	}
+	if len(payload.Bytes) != common.HashLength {
+		return fmt.Errorf("payload of %d bytes is not a %d bytes digest", len(payload.Bytes), common.HashLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewKeySigner(key)
+
+	address, err := Address(signer)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), address)
+
+	digest := crypto.Keccak256([]byte("payload"))
+	signature, err := signer.Sign(context.Background(), &RosettaTypes.SigningPayload{Bytes: digest})
+	require.NoError(t, err)
+	assert.Equal(t, RosettaTypes.EcdsaRecovery, signature.SignatureType)
+
+	recovered, err := crypto.SigToPub(digest, signature.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, address, crypto.PubkeyToAddress(*recovered))
+}
+
+func TestCheckPayload(t *testing.T) {
+	digest := crypto.Keccak256([]byte("payload"))
+
+	tests := map[string]struct {
+		payload *RosettaTypes.SigningPayload
+
+		expectedErr bool
+	}{
+		"digest": {
+			payload: &RosettaTypes.SigningPayload{Bytes: digest, SignatureType: RosettaTypes.EcdsaRecovery},
+		},
+		"untyped digest": {
+			payload: &RosettaTypes.SigningPayload{Bytes: digest},
+		},
+		"not a digest": {
+			payload:     &RosettaTypes.SigningPayload{Bytes: []byte("payload")},
+			expectedErr: true,
+		},
+		"unsupported signature type": {
+			payload:     &RosettaTypes.SigningPayload{Bytes: digest, SignatureType: RosettaTypes.Ed25519},
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkPayload(test.payload)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewKeystoreSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	encrypted, err := keystore.EncryptKey(&keystore.Key{
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	require.NoError(t, os.WriteFile(path, encrypted, 0o600))
+
+	signer, err := NewKeystoreSigner(path, "passphrase")
+	require.NoError(t, err)
+	address, err := Address(signer)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), address)
+
+	_, err = NewKeystoreSigner(path, "wrong")
+	assert.Error(t, err)
+	_, err = NewKeystoreSigner(filepath.Join(t.TempDir(), "missing.json"), "passphrase")
+	assert.Error(t, err)
+}
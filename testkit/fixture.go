@@ -0,0 +1,137 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testkit records the JSON RPC responses of a live node to fixture files
+// and replays them, so integration tests run deterministically in CI. It also
+// simulates reorgs of a synthetic chain, see ReorgChain.
+package testkit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// fixtureKeyLen is the number of hex characters of the params hash in fixture
+// file names
+const fixtureKeyLen = 16
+
+// unsafeFileChars are the characters of a method name replaced in fixture file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Fixture is a recorded JSON RPC call
+type Fixture struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *RecordedError    `json:"error,omitempty"`
+}
+
+// RecordedError is the JSON RPC error of a recorded call. It implements
+// rpc.Error so callers can check its code when replayed.
+type RecordedError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RecordedError) Error() string {
+	return e.Message
+}
+
+// ErrorCode returns the JSON RPC error code
+func (e *RecordedError) ErrorCode() int {
+	return e.Code
+}
+
+// Key identifies the call of the fixture by its method and params
+func (f *Fixture) Key() string {
+	return fixtureKey(f.Method, f.Params)
+}
+
+// FileName is the name of the fixture file, the method and a hash of the params
+func (f *Fixture) FileName() string {
+	return fmt.Sprintf("%s_%s.json", unsafeFileChars.ReplaceAllString(f.Method, "_"), f.Key()[:fixtureKeyLen])
+}
+
+// fixtureKey returns the hex encoded hash of method and its JSON encoded params
+func fixtureKey(method string, params []json.RawMessage) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	for _, param := range params {
+		h.Write([]byte{0})
+		h.Write(compactJSON(param))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// compactJSON returns raw without insignificant whitespace, so params match
+// regardless of how fixture files are formatted
+func compactJSON(raw json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+
+	return buf.Bytes()
+}
+
+// SaveFixtures writes every fixture to its own file in dir
+func SaveFixtures(dir string, fixtures []*Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil { // nolint:gomnd
+		return err
+	}
+
+	for _, fixture := range fixtures {
+		raw, err := json.MarshalIndent(fixture, "", "  ")
+		if err != nil {
+			return fmt.Errorf("could not encode fixture of %s: %w", fixture.Method, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fixture.FileName()), append(raw, '\n'), 0o644); err != nil { // nolint:gomnd
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFixtures reads the fixture files of dir, sorted by file name
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return nil, fmt.Errorf("could not decode fixture %s: %w", path, err)
+		}
+		fixtures = append(fixtures, &fixture)
+	}
+
+	return fixtures, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Recorder is a client.JSONRPC that captures the responses of the node it wraps
+// as fixtures. Calls failing without a JSON RPC error, e.g. on timeouts, are not
+// recorded. It is safe for concurrent use.
+type Recorder struct {
+	client.JSONRPC
+
+	mu       sync.Mutex
+	fixtures map[string]*Fixture
+	order    []string
+}
+
+// NewRecorder returns a Recorder of the node behind jsonrpc
+func NewRecorder(jsonrpc client.JSONRPC) *Recorder {
+	return &Recorder{JSONRPC: jsonrpc, fixtures: map[string]*Fixture{}}
+}
+
+// CallContext calls the node and records its response
+func (r *Recorder) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var raw json.RawMessage
+	err := r.JSONRPC.CallContext(ctx, &raw, method, args...)
+	if err := r.record(method, args, raw, err); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+// BatchCallContext calls the node and records the response of every element
+func (r *Recorder) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	raws := make([]json.RawMessage, len(b))
+	batch := make([]rpc.BatchElem, len(b))
+	for i := range b {
+		batch[i] = rpc.BatchElem{Method: b[i].Method, Args: b[i].Args, Result: &raws[i]}
+	}
+	if err := r.JSONRPC.BatchCallContext(ctx, batch); err != nil {
+		return err
+	}
+
+	for i := range b {
+		b[i].Error = batch[i].Error
+		if err := r.record(b[i].Method, b[i].Args, raws[i], batch[i].Error); err != nil {
+			return err
+		}
+		if b[i].Error == nil && b[i].Result != nil {
+			b[i].Error = json.Unmarshal(raws[i], b[i].Result)
+		}
+	}
+
+	return nil
+}
+
+// Fixtures returns the recorded fixtures in the order of their first call.
+// Repeated calls are recorded once, with the latest response.
+func (r *Recorder) Fixtures() []*Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fixtures := make([]*Fixture, len(r.order))
+	for i, key := range r.order {
+		fixtures[i] = r.fixtures[key]
+	}
+
+	return fixtures
+}
+
+// Save writes the recorded fixtures to dir, see SaveFixtures
+func (r *Recorder) Save(dir string) error {
+	return SaveFixtures(dir, r.Fixtures())
+}
+
+// record adds the response of a call as a fixture
+func (r *Recorder) record(method string, args []interface{}, result json.RawMessage, callErr error) error {
+	fixture := &Fixture{Method: method, Params: make([]json.RawMessage, len(args))}
+	for i, arg := range args {
+		encoded, err := json.Marshal(arg)
+		if err != nil {
+			return fmt.Errorf("could not encode param %d of %s: %w", i, method, err)
+		}
+		fixture.Params[i] = encoded
+	}
+
+	var rpcErr rpc.Error
+	switch {
+	case callErr == nil:
+		fixture.Result = result
+		if len(fixture.Result) == 0 {
+			fixture.Result = json.RawMessage("null")
+		}
+	case errors.As(callErr, &rpcErr):
+		fixture.Error = &RecordedError{Code: rpcErr.ErrorCode(), Message: callErr.Error()}
+	default:
+		return nil
+	}
+
+	key := fixture.Key()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.fixtures[key]; !ok {
+		r.order = append(r.order, key)
+	}
+	r.fixtures[key] = fixture
+
+	return nil
+}
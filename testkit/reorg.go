@@ -0,0 +1,554 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testkit
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// reorgTransferGas is the gas of the transfers of the blocks of a ReorgChain
+	reorgTransferGas = 21000
+
+	// reorgBlockGasLimit is the gas limit of the blocks of a ReorgChain
+	reorgBlockGasLimit = 30000000
+
+	// reorgBlockTime is the number of seconds between the blocks of a ReorgChain
+	reorgBlockTime = 12
+)
+
+var (
+	// reorgSenderKey is the key signing the transfers of a ReorgChain
+	reorgSenderKey = mustHexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+
+	// ReorgRecipient is the recipient of the transfers of a ReorgChain
+	ReorgRecipient = common.HexToAddress("0x1111111111111111111111111111111111111111")
+)
+
+func mustHexToECDSA(raw string) *ecdsa.PrivateKey {
+	key, err := crypto.HexToECDSA(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+// ReorgSender is the sender of the transfers of a ReorgChain
+func ReorgSender() common.Address {
+	return crypto.PubkeyToAddress(reorgSenderKey.PublicKey)
+}
+
+// reorgBlock is a block of a ReorgChain
+type reorgBlock struct {
+	header *EthTypes.Header
+	txs    []*EthTypes.Transaction
+}
+
+func (b *reorgBlock) identifier() *RosettaTypes.BlockIdentifier {
+	return &RosettaTypes.BlockIdentifier{Index: b.header.Number.Int64(), Hash: b.header.Hash().Hex()}
+}
+
+// ReorgChain is a chain of synthetic blocks of transfers, served through the
+// handlers of a testutil.FakeJSONRPC, whose head can be reorged to test how
+// the SDK and chain modules handle orphaned blocks without waiting for a real
+// reorg. Like geth, the node keeps serving orphaned blocks by hash, while
+// blocks by number, transactions and receipts follow the canonical chain. It
+// is safe for concurrent use.
+type ReorgChain struct {
+	mu sync.Mutex
+
+	signer    EthTypes.Signer
+	canonical []*reorgBlock
+	blocks    map[common.Hash]*reorgBlock
+	nonce     uint64
+	forks     int
+	orphaned  []*RosettaTypes.BlockIdentifier
+}
+
+// NewReorgChain returns a chain of chainID holding a genesis block, serving
+// eth_blockNumber, eth_getBlockByNumber, eth_getBlockByHash,
+// eth_getTransactionByHash, eth_getTransactionReceipt and eth_getBlockReceipts
+// through fake
+func NewReorgChain(fake *testutil.FakeJSONRPC, chainID *big.Int) *ReorgChain {
+	c := &ReorgChain{
+		signer: EthTypes.NewEIP155Signer(chainID),
+		blocks: map[common.Hash]*reorgBlock{},
+	}
+	c.appendBlock(nil)
+
+	fake.Handle("eth_blockNumber", c.handleBlockNumber)
+	fake.Handle("eth_getBlockByNumber", c.handleBlockByNumber)
+	fake.Handle("eth_getBlockByHash", c.handleBlockByHash)
+	fake.Handle("eth_getTransactionByHash", c.handleTransactionByHash)
+	fake.Handle("eth_getTransactionReceipt", c.handleTransactionReceipt)
+	fake.Handle("eth_getBlockReceipts", c.handleBlockReceipts)
+
+	return c
+}
+
+// Mine appends a block of txs transfers from ReorgSender to the head and
+// returns it
+func (c *ReorgChain) Mine(txs int) *RosettaTypes.BlockIdentifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	transfers := make([]*EthTypes.Transaction, txs)
+	for i := range transfers {
+		transfers[i] = c.transfer()
+	}
+
+	return c.appendBlock(transfers).identifier()
+}
+
+// Reorg replaces the depth blocks at the head with a competing branch of
+// length blocks, and returns the orphaned blocks, lowest first. The
+// transactions of the orphaned blocks are included again in the first block
+// of the branch, like a node mining them again from its pool, so they move to
+// another block. A reorg of depth 1 and length 1 is a competing head block.
+func (c *ReorgChain) Reorg(depth int, length int) ([]*RosettaTypes.BlockIdentifier, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if depth < 1 || depth >= len(c.canonical) {
+		return nil, fmt.Errorf("reorg depth %d is not between 1 and the head %d", depth, len(c.canonical)-1)
+	}
+	if length < 1 {
+		return nil, fmt.Errorf("reorg length %d is not positive", length)
+	}
+
+	fork := len(c.canonical) - depth
+	orphaned := make([]*RosettaTypes.BlockIdentifier, 0, depth)
+	var txs []*EthTypes.Transaction
+	for _, block := range c.canonical[fork:] {
+		orphaned = append(orphaned, block.identifier())
+		txs = append(txs, block.txs...)
+	}
+	c.canonical = c.canonical[:fork]
+	c.forks++
+	c.orphaned = append(c.orphaned, orphaned...)
+
+	c.appendBlock(txs)
+	for i := 1; i < length; i++ {
+		c.appendBlock(nil)
+	}
+
+	return orphaned, nil
+}
+
+// Head returns the head block
+func (c *ReorgChain) Head() *RosettaTypes.BlockIdentifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.canonical[len(c.canonical)-1].identifier()
+}
+
+// Canonical returns the blocks of the canonical chain, genesis first
+func (c *ReorgChain) Canonical() []*RosettaTypes.BlockIdentifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	identifiers := make([]*RosettaTypes.BlockIdentifier, len(c.canonical))
+	for i, block := range c.canonical {
+		identifiers[i] = block.identifier()
+	}
+
+	return identifiers
+}
+
+// Orphaned returns the blocks orphaned by the reorgs so far, in reorg order
+func (c *ReorgChain) Orphaned() []*RosettaTypes.BlockIdentifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*RosettaTypes.BlockIdentifier(nil), c.orphaned...)
+}
+
+// Parent returns the parent of the block with hash, canonical or orphaned, or
+// nil for the genesis block and unknown blocks
+func (c *ReorgChain) Parent(hash string) *RosettaTypes.BlockIdentifier {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, ok := c.blocks[common.HexToHash(hash)]
+	if !ok || block.header.Number.Sign() == 0 {
+		return nil
+	}
+
+	return c.blocks[block.header.ParentHash].identifier()
+}
+
+// Transactions returns the hashes of the transactions of the block with hash,
+// canonical or orphaned
+func (c *ReorgChain) Transactions(hash string) []common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, ok := c.blocks[common.HexToHash(hash)]
+	if !ok {
+		return nil
+	}
+	hashes := make([]common.Hash, len(block.txs))
+	for i, tx := range block.txs {
+		hashes[i] = tx.Hash()
+	}
+
+	return hashes
+}
+
+// transfer returns the next signed transfer of ReorgSender
+func (c *ReorgChain) transfer() *EthTypes.Transaction {
+	tx, err := EthTypes.SignTx(
+		EthTypes.NewTransaction(c.nonce, ReorgRecipient, big.NewInt(1), reorgTransferGas, big.NewInt(1), nil),
+		c.signer,
+		reorgSenderKey,
+	)
+	if err != nil {
+		panic(err)
+	}
+	c.nonce++
+
+	return tx
+}
+
+// appendBlock mines a block of txs on the head. The extra data holds the
+// number of reorgs so far, so blocks of competing branches have other hashes.
+func (c *ReorgChain) appendBlock(txs []*EthTypes.Transaction) *reorgBlock {
+	header := &EthTypes.Header{
+		UncleHash:  EthTypes.EmptyUncleHash,
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(int64(len(c.canonical))),
+		GasLimit:   reorgBlockGasLimit,
+		GasUsed:    uint64(len(txs)) * reorgTransferGas,
+		Time:       uint64(len(c.canonical)) * reorgBlockTime,
+		Extra:      []byte(fmt.Sprintf("fork %d", c.forks)),
+		TxHash:     EthTypes.DeriveSha(EthTypes.Transactions(txs), trie.NewStackTrie(nil)),
+	}
+	if len(c.canonical) > 0 {
+		header.ParentHash = c.canonical[len(c.canonical)-1].header.Hash()
+	}
+	block := &reorgBlock{header: header, txs: txs}
+	header.ReceiptHash = EthTypes.DeriveSha(EthTypes.Receipts(c.receipts(block)), trie.NewStackTrie(nil))
+
+	c.canonical = append(c.canonical, block)
+	c.blocks[header.Hash()] = block
+
+	return block
+}
+
+// receipts returns the receipts of the transactions of block
+func (c *ReorgChain) receipts(block *reorgBlock) []*EthTypes.Receipt {
+	receipts := make([]*EthTypes.Receipt, len(block.txs))
+	for i, tx := range block.txs {
+		receipts[i] = &EthTypes.Receipt{
+			Type:              tx.Type(),
+			Status:            EthTypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i+1) * reorgTransferGas,
+			Logs:              []*EthTypes.Log{},
+			TxHash:            tx.Hash(),
+			GasUsed:           reorgTransferGas,
+			EffectiveGasPrice: tx.GasPrice(),
+			BlockHash:         block.header.Hash(),
+			BlockNumber:       block.header.Number,
+			TransactionIndex:  uint(i),
+		}
+	}
+
+	return receipts
+}
+
+// canonicalTransaction returns the canonical block including the transaction
+// with hash and its index
+func (c *ReorgChain) canonicalTransaction(hash common.Hash) (*reorgBlock, int) {
+	for _, block := range c.canonical {
+		for i, tx := range block.txs {
+			if tx.Hash() == hash {
+				return block, i
+			}
+		}
+	}
+
+	return nil, 0
+}
+
+// blockJSON returns the JSON RPC block of block, with its full transactions
+// when fullTx is set and their hashes otherwise
+func (c *ReorgChain) blockJSON(block *reorgBlock, fullTx bool) (interface{}, error) {
+	raw, err := json.Marshal(block.header)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	txs := make([]interface{}, len(block.txs))
+	for i, tx := range block.txs {
+		if fullTx {
+			if txs[i], err = c.transactionJSON(block, i); err != nil {
+				return nil, err
+			}
+		} else {
+			txs[i] = tx.Hash()
+		}
+	}
+	fields["transactions"] = txs
+	fields["uncles"] = []common.Hash{}
+
+	return fields, nil
+}
+
+// transactionJSON returns the JSON RPC transaction at index of block
+func (c *ReorgChain) transactionJSON(block *reorgBlock, index int) (map[string]interface{}, error) {
+	tx := block.txs[index]
+	raw, err := tx.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	from, err := EthTypes.Sender(c.signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = from
+	fields["blockHash"] = block.header.Hash()
+	fields["blockNumber"] = (*hexutil.Big)(block.header.Number)
+	fields["transactionIndex"] = hexutil.Uint64(index)
+
+	return fields, nil
+}
+
+func (c *ReorgChain) handleBlockNumber([]json.RawMessage) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return hexutil.Uint64(len(c.canonical) - 1), nil
+}
+
+func (c *ReorgChain) handleBlockByNumber(params []json.RawMessage) (interface{}, error) {
+	var number string
+	var fullTx bool
+	if err := decodeParams(params, &number, &fullTx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := uint64(len(c.canonical) - 1)
+	switch number {
+	case "latest", "pending", "safe", "finalized":
+	case "earliest":
+		index = 0
+	default:
+		parsed, err := hexutil.DecodeUint64(number)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number %s: %w", number, err)
+		}
+		if parsed >= uint64(len(c.canonical)) {
+			return nil, nil
+		}
+		index = parsed
+	}
+
+	return c.blockJSON(c.canonical[index], fullTx)
+}
+
+func (c *ReorgChain) handleBlockByHash(params []json.RawMessage) (interface{}, error) {
+	var hash common.Hash
+	var fullTx bool
+	if err := decodeParams(params, &hash, &fullTx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, ok := c.blocks[hash]
+	if !ok {
+		return nil, nil
+	}
+
+	return c.blockJSON(block, fullTx)
+}
+
+func (c *ReorgChain) handleTransactionByHash(params []json.RawMessage) (interface{}, error) {
+	var hash common.Hash
+	if err := decodeParams(params, &hash); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, index := c.canonicalTransaction(hash)
+	if block == nil {
+		return nil, nil
+	}
+
+	return c.transactionJSON(block, index)
+}
+
+func (c *ReorgChain) handleTransactionReceipt(params []json.RawMessage) (interface{}, error) {
+	var hash common.Hash
+	if err := decodeParams(params, &hash); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, index := c.canonicalTransaction(hash)
+	if block == nil {
+		return nil, nil
+	}
+
+	return c.receipts(block)[index], nil
+}
+
+// handleBlockReceipts returns the receipts of a block by number or hash. Like
+// geth, the receipts of orphaned blocks are still served by hash.
+func (c *ReorgChain) handleBlockReceipts(params []json.RawMessage) (interface{}, error) {
+	var blockNumberOrHash string
+	if err := decodeParams(params, &blockNumberOrHash); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(blockNumberOrHash) == 2+2*common.HashLength && strings.HasPrefix(blockNumberOrHash, "0x") { // nolint:gomnd
+		block, ok := c.blocks[common.HexToHash(blockNumberOrHash)]
+		if !ok {
+			return nil, nil
+		}
+		return c.receipts(block), nil
+	}
+
+	index, err := hexutil.DecodeUint64(blockNumberOrHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number %s: %w", blockNumberOrHash, err)
+	}
+	if index >= uint64(len(c.canonical)) {
+		return nil, nil
+	}
+
+	return c.receipts(c.canonical[index]), nil
+}
+
+// decodeParams decodes the leading params into values
+func decodeParams(params []json.RawMessage, values ...interface{}) error {
+	if len(params) < len(values) {
+		return fmt.Errorf("expected %d params, got %d", len(values), len(params))
+	}
+	for i, value := range values {
+		if err := json.Unmarshal(params[i], value); err != nil {
+			return fmt.Errorf("invalid param %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ReorgScenario is a synthetic reorg run by RunReorgScenarios
+type ReorgScenario struct {
+	Name string
+
+	// Blocks is the number of blocks mined on top of the genesis block before
+	// the reorg, each with TxsPerBlock transfers
+	Blocks      int
+	TxsPerBlock int
+
+	// Depth is the number of blocks rolled back and Length the number of
+	// blocks of the competing branch replacing them
+	Depth  int
+	Length int
+}
+
+// ReorgScenarios are the competing head blocks, the depth-N rollbacks and the
+// shorter and longer branches integrators should handle
+func ReorgScenarios() []ReorgScenario {
+	return []ReorgScenario{
+		{Name: "competing head block", Blocks: 5, TxsPerBlock: 1, Depth: 1, Length: 1},
+		{Name: "depth 2 rollback", Blocks: 5, TxsPerBlock: 2, Depth: 2, Length: 2},
+		{Name: "depth 4 rollback to a longer branch", Blocks: 8, TxsPerBlock: 1, Depth: 4, Length: 6},
+		{Name: "depth 3 rollback to a shorter branch", Blocks: 6, TxsPerBlock: 1, Depth: 3, Length: 1},
+		{Name: "rollback of empty blocks", Blocks: 4, Depth: 2, Length: 3},
+	}
+}
+
+// ReorgTest checks how code handles a ReorgScenario. Before is called once the
+// blocks before the reorg are mined, e.g. to sync them, and After once the
+// reorg happened with the orphaned blocks.
+type ReorgTest struct {
+	Before func(t *testing.T, chain *ReorgChain)
+	After  func(t *testing.T, chain *ReorgChain, orphaned []*RosettaTypes.BlockIdentifier)
+}
+
+// RunReorgScenarios runs test as a subtest for each scenario, with a chain of
+// chainID served by a new testutil.FakeJSONRPC. setup can register the other
+// methods of the tested code on the fake, or replace the handlers of the chain.
+func RunReorgScenarios(
+	t *testing.T,
+	scenarios []ReorgScenario,
+	chainID *big.Int,
+	setup func(t *testing.T, fake *testutil.FakeJSONRPC, chain *ReorgChain),
+	test ReorgTest,
+) {
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			fake := testutil.NewFakeJSONRPC()
+			chain := NewReorgChain(fake, chainID)
+			if setup != nil {
+				setup(t, fake, chain)
+			}
+			for i := 0; i < scenario.Blocks; i++ {
+				chain.Mine(scenario.TxsPerBlock)
+			}
+			if test.Before != nil {
+				test.Before(t, chain)
+			}
+
+			orphaned, err := chain.Reorg(scenario.Depth, scenario.Length)
+			if err != nil {
+				t.Fatalf("could not reorg: %v", err)
+			}
+			if test.After != nil {
+				test.After(t, chain, orphaned)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
+)
+
+// RecordURLEnv is the environment variable with the URL of the node recorded by
+// Client. When it is not set, Client replays the fixtures.
+const RecordURLEnv = "TESTKIT_RECORD_URL"
+
+// NewReplayClient returns a fake client.JSONRPC answering calls with the
+// responses of fixtures. Calls without a fixture fail.
+func NewReplayClient(fixtures []*Fixture) *testutil.FakeJSONRPC {
+	byMethod := map[string]map[string]*Fixture{}
+	for _, fixture := range fixtures {
+		if _, ok := byMethod[fixture.Method]; !ok {
+			byMethod[fixture.Method] = map[string]*Fixture{}
+		}
+		byMethod[fixture.Method][fixture.Key()] = fixture
+	}
+
+	fake := testutil.NewFakeJSONRPC()
+	for method, recorded := range byMethod {
+		method, recorded := method, recorded
+		fake.Handle(method, func(params []json.RawMessage) (interface{}, error) {
+			fixture, ok := recorded[fixtureKey(method, params)]
+			if !ok {
+				encoded, _ := json.Marshal(params)
+				return nil, fmt.Errorf("no fixture of %s with params %s", method, encoded)
+			}
+			if fixture.Error != nil {
+				return nil, fixture.Error
+			}
+			return fixture.Result, nil
+		})
+	}
+
+	return fake
+}
+
+// LoadReplayClient returns a replay client of the fixtures of dir
+func LoadReplayClient(dir string) (*testutil.FakeJSONRPC, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayClient(fixtures), nil
+}
+
+// Client returns a client.JSONRPC for an integration test. When RecordURLEnv is
+// set, calls go to that node and their responses are saved to dir when the test
+// ends. Otherwise the fixtures of dir are replayed.
+func Client(t testing.TB, dir string) client.JSONRPC {
+	t.Helper()
+
+	url := os.Getenv(RecordURLEnv)
+	if len(url) == 0 {
+		replay, err := LoadReplayClient(dir)
+		if err != nil {
+			t.Fatalf("could not load fixtures of %s: %v", dir, err)
+		}
+		return replay
+	}
+
+	node, err := client.NewRPCClient(url, nil)
+	if err != nil {
+		t.Fatalf("could not connect to %s: %v", url, err)
+	}
+	recorder := NewRecorder(node)
+	t.Cleanup(func() {
+		if err := recorder.Save(dir); err != nil {
+			t.Errorf("could not save fixtures to %s: %v", dir, err)
+		}
+	})
+
+	return recorder
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	"github.com/coinbase/rosetta-geth-sdk/testutil"
+	sdkTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	node := testutil.NewFakeJSONRPC()
+	node.Handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var address common.Address
+		if err := json.Unmarshal(params[0], &address); err != nil {
+			return nil, err
+		}
+		return (*hexutil.Big)(new(big.Int).SetBytes(address.Bytes())), nil
+	})
+	node.Respond("debug_traceBlockByHash", json.RawMessage(`[{"result":{"type":"CALL"}}]`))
+
+	recorder := NewRecorder(node)
+	var balance hexutil.Big
+	assert.NoError(t, recorder.CallContext(ctx, &balance, "eth_getBalance", common.HexToAddress("0x1"), "0xa"))
+	assert.Equal(t, int64(1), balance.ToInt().Int64())
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{common.HexToAddress("0x2"), "0xa"}, Result: new(hexutil.Big)},
+		{Method: "debug_traceBlockByHash", Args: []interface{}{common.HexToHash("0x3")}, Result: new(json.RawMessage)},
+		{Method: "eth_getBlockReceipts", Args: []interface{}{common.HexToHash("0x3")}, Result: new(json.RawMessage)},
+	}
+	assert.NoError(t, recorder.BatchCallContext(ctx, batch))
+	assert.Equal(t, int64(2), batch[0].Result.(*hexutil.Big).ToInt().Int64())
+	assert.Error(t, batch[2].Error)
+
+	// Repeated calls are recorded once
+	assert.NoError(t, recorder.CallContext(ctx, &balance, "eth_getBalance", common.HexToAddress("0x1"), "0xa"))
+	assert.Len(t, recorder.Fixtures(), 4)
+
+	dir := t.TempDir()
+	assert.NoError(t, recorder.Save(dir))
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	replay, err := LoadReplayClient(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, replay.CallContext(ctx, &balance, "eth_getBalance", common.HexToAddress("0x2"), "0xa"))
+	assert.Equal(t, int64(2), balance.ToInt().Int64())
+
+	var trace json.RawMessage
+	assert.NoError(t, replay.CallContext(ctx, &trace, "debug_traceBlockByHash", common.HexToHash("0x3")))
+	assert.JSONEq(t, `[{"result":{"type":"CALL"}}]`, string(trace))
+
+	var rpcErr rpc.Error
+	err = replay.CallContext(ctx, &trace, "eth_getBlockReceipts", common.HexToHash("0x3"))
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, testutil.MethodNotFoundCode, rpcErr.ErrorCode())
+
+	err = replay.CallContext(ctx, &balance, "eth_getBalance", common.HexToAddress("0x4"), "0xa")
+	assert.ErrorContains(t, err, "no fixture of eth_getBalance")
+}
+
+func TestClient_Replay(t *testing.T) {
+	if len(os.Getenv(RecordURLEnv)) != 0 {
+		t.Skipf("%s is set", RecordURLEnv)
+	}
+
+	dir := t.TempDir()
+	fixture := &Fixture{
+		Method: "eth_blockNumber",
+		Params: []json.RawMessage{},
+		Result: json.RawMessage(`"0x10"`),
+	}
+	assert.NoError(t, SaveFixtures(dir, []*Fixture{fixture}))
+	assert.FileExists(t, filepath.Join(dir, fixture.FileName()))
+
+	var number hexutil.Uint64
+	assert.NoError(t, Client(t, dir).CallContext(context.Background(), &number, "eth_blockNumber"))
+	assert.Equal(t, hexutil.Uint64(16), number)
+}
+
+func TestReorgChain(t *testing.T) {
+	ctx := context.Background()
+	fake := testutil.NewFakeJSONRPC()
+	chain := NewReorgChain(fake, big.NewInt(1))
+	chain.Mine(1)
+	mined := chain.Mine(2)
+	txs := chain.Transactions(mined.Hash)
+	assert.Len(t, txs, 2)
+
+	_, err := chain.Reorg(3, 1)
+	assert.EqualError(t, err, "reorg depth 3 is not between 1 and the head 2")
+	_, err = chain.Reorg(1, 0)
+	assert.EqualError(t, err, "reorg length 0 is not positive")
+
+	orphaned, err := chain.Reorg(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.BlockIdentifier{mined}, orphaned)
+	assert.Equal(t, orphaned, chain.Orphaned())
+
+	canonical := chain.Canonical()
+	assert.Len(t, canonical, 4)
+	assert.Equal(t, canonical[3], chain.Head())
+	assert.NotEqual(t, mined.Hash, canonical[2].Hash)
+	assert.Equal(t, canonical[1], chain.Parent(mined.Hash))
+	assert.Equal(t, canonical[1], chain.Parent(canonical[2].Hash))
+	assert.Nil(t, chain.Parent(canonical[0].Hash))
+
+	// The orphaned transactions moved to the first block of the branch
+	assert.Equal(t, txs, chain.Transactions(canonical[2].Hash))
+	var receipt struct {
+		BlockHash common.Hash `json:"blockHash"`
+	}
+	assert.NoError(t, fake.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txs[0]))
+	assert.Equal(t, canonical[2].Hash, receipt.BlockHash.Hex())
+
+	var number hexutil.Uint64
+	assert.NoError(t, fake.CallContext(ctx, &number, "eth_blockNumber"))
+	assert.Equal(t, hexutil.Uint64(3), number)
+
+	var block map[string]interface{}
+	assert.NoError(t, fake.CallContext(ctx, &block, "eth_getBlockByNumber", "0x4", false))
+	assert.Nil(t, block)
+}
+
+func TestRunReorgScenarios(t *testing.T) {
+	ctx := context.Background()
+	var sdkClient *client.SDKClient
+	synced := map[int64]string{}
+
+	RunReorgScenarios(
+		t,
+		ReorgScenarios(),
+		big.NewInt(1),
+		func(t *testing.T, fake *testutil.FakeJSONRPC, chain *ReorgChain) {
+			sdkClient = testutil.NewFakeClient(t, &configuration.Configuration{}, fake)
+			synced = map[int64]string{}
+		},
+		ReorgTest{
+			Before: func(t *testing.T, chain *ReorgChain) {
+				for _, identifier := range chain.Canonical() {
+					block, err := sdkClient.BlockByNumber(ctx, big.NewInt(identifier.Index))
+					assert.NoError(t, err)
+					assert.Equal(t, identifier.Hash, block.Hash().Hex())
+					synced[identifier.Index] = block.Hash().Hex()
+				}
+			},
+			After: func(t *testing.T, chain *ReorgChain, orphaned []*RosettaTypes.BlockIdentifier) {
+				head := chain.Head()
+				for _, identifier := range orphaned {
+					assert.Equal(t, synced[identifier.Index], identifier.Hash)
+
+					// Orphaned blocks are still served by hash
+					block, err := sdkClient.BlockByHash(ctx, common.HexToHash(identifier.Hash))
+					assert.NoError(t, err)
+					assert.Equal(t, identifier.Hash, block.Hash().Hex())
+
+					if identifier.Index <= head.Index {
+						header, err := sdkClient.HeaderByNumber(ctx, big.NewInt(identifier.Index))
+						assert.NoError(t, err)
+						assert.NotEqual(t, identifier.Hash, header.Hash().Hex())
+					}
+
+					// The receipts of the transactions of an orphaned block are
+					// of another block
+					hashes := chain.Transactions(identifier.Hash)
+					txs := make([]client.RPCTransaction, len(hashes))
+					for i := range hashes {
+						txs[i].TxHash = &hashes[i]
+					}
+					_, err = sdkClient.OPStackBlockReceipts(ctx, common.HexToHash(identifier.Hash), txs)
+					if len(txs) > 0 {
+						assert.ErrorIs(t, err, sdkTypes.ErrClientBlockOrphaned)
+					} else {
+						assert.NoError(t, err)
+					}
+				}
+
+				// The chain continues from the parent of the lowest orphaned block
+				assert.Equal(t, chain.Parent(orphaned[0].Hash), chain.Parent(chain.Canonical()[orphaned[0].Index].Hash))
+			},
+		},
+	)
+}
+
+func TestReorgChain_LoadedTransaction(t *testing.T) {
+	ctx := context.Background()
+	fake := testutil.NewFakeJSONRPC()
+	chain := NewReorgChain(fake, params.MainnetChainConfig.ChainID)
+	mined := chain.Mine(1)
+	hash := chain.Transactions(mined.Hash)[0]
+	sdkClient := testutil.NewFakeClient(t, &configuration.Configuration{ChainConfig: params.MainnetChainConfig}, fake)
+
+	tx, err := sdkClient.GetLoadedTransaction(ctx, &RosettaTypes.BlockTransactionRequest{
+		BlockIdentifier:       mined,
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: hash.Hex()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hash, *tx.TxHash)
+	assert.Equal(t, mined.Hash, tx.BlockHash.Hex())
+	assert.Equal(t, ReorgSender(), *tx.From)
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// ReadFixture returns the JSON fixture at path, failing the test if it can't
+// be read or is not valid JSON
+func ReadFixture(t testing.TB, path string) json.RawMessage {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read fixture %s: %v", path, err)
+	}
+	if !json.Valid(raw) {
+		t.Fatalf("fixture %s is not valid JSON", path)
+	}
+
+	return raw
+}
+
+// DecodeFixture decodes the JSON fixture at path into v, failing the test if
+// it can't be decoded
+func DecodeFixture(t testing.TB, path string, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(ReadFixture(t, path), v); err != nil {
+		t.Fatalf("could not decode fixture %s: %v", path, err)
+	}
+}
+
+// RespondWithFixture registers a handler always returning the JSON fixture at
+// path for method
+func (f *FakeJSONRPC) RespondWithFixture(t testing.TB, method string, path string) {
+	t.Helper()
+
+	f.Respond(method, ReadFixture(t, path))
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testutil provides test doubles of the node APIs used by the SDK, so
+// chain modules can be unit tested without running a node.
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// MethodNotFoundCode is the JSON RPC error code of unsupported methods
+const MethodNotFoundCode = -32601
+
+// Handler returns the result of a JSON RPC call given its JSON encoded params
+type Handler func(params []json.RawMessage) (interface{}, error)
+
+// Call is a JSON RPC call received by FakeJSONRPC
+type Call struct {
+	Method string
+	Params []json.RawMessage
+}
+
+// MethodNotFoundError is returned for calls of methods without a handler. It
+// implements rpc.Error like the errors of geth nodes.
+type MethodNotFoundError struct {
+	Method string
+}
+
+func (e *MethodNotFoundError) Error() string {
+	return fmt.Sprintf("the method %s does not exist/is not available", e.Method)
+}
+
+// ErrorCode returns MethodNotFoundCode
+func (e *MethodNotFoundError) ErrorCode() int {
+	return MethodNotFoundCode
+}
+
+// FakeJSONRPC is a client.JSONRPC answering calls with the handlers registered
+// for their method. Params and results are passed through their JSON encoding,
+// like with a node, so handlers and callers see the same values they would see
+// over the wire. It is safe for concurrent use.
+type FakeJSONRPC struct {
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    []Call
+}
+
+// NewFakeJSONRPC returns a FakeJSONRPC without handlers
+func NewFakeJSONRPC() *FakeJSONRPC {
+	return &FakeJSONRPC{handlers: map[string]Handler{}}
+}
+
+// Handle registers handler for method, replacing the previous handler
+func (f *FakeJSONRPC) Handle(method string, handler Handler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers[method] = handler
+}
+
+// Respond registers a handler always returning result for method. result can
+// be a json.RawMessage, e.g. loaded with ReadFixture.
+func (f *FakeJSONRPC) Respond(method string, result interface{}) {
+	f.Handle(method, func([]json.RawMessage) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// Fail registers a handler always returning err for method
+func (f *FakeJSONRPC) Fail(method string, err error) {
+	f.Handle(method, func([]json.RawMessage) (interface{}, error) {
+		return nil, err
+	})
+}
+
+// Calls returns the calls received so far, in order
+func (f *FakeJSONRPC) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// CallCount returns the number of calls of method received so far
+func (f *FakeJSONRPC) CallCount(method string) int {
+	count := 0
+	for _, call := range f.Calls() {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CallContext calls the handler of method and decodes its result into result
+func (f *FakeJSONRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	params := make([]json.RawMessage, len(args))
+	for i, arg := range args {
+		encoded, err := json.Marshal(arg)
+		if err != nil {
+			return fmt.Errorf("could not encode param %d of %s: %w", i, method, err)
+		}
+		params[i] = encoded
+	}
+
+	raw, err := f.dispatch(method, params)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+// BatchCallContext calls the handlers of the batch elements, setting the error
+// of each element like rpc.Client
+func (f *FakeJSONRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i := range b {
+		b[i].Error = f.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...)
+	}
+
+	return nil
+}
+
+// Close does nothing
+func (f *FakeJSONRPC) Close() {}
+
+// dispatch records the call of method and returns the JSON encoded result of
+// its handler
+func (f *FakeJSONRPC) dispatch(method string, params []json.RawMessage) (json.RawMessage, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Method: method, Params: params})
+	handler, ok := f.handlers[method]
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, &MethodNotFoundError{Method: method}
+	}
+
+	result, err := handler(params)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := result.(json.RawMessage); ok {
+		return raw, nil
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultErrorCode is the JSON RPC error code of handler errors that don't
+// implement rpc.Error
+const defaultErrorCode = -32000
+
+type jsonrpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// NewFakeNode returns an HTTP JSON RPC server answering calls and batches with
+// the handlers of fake. The server is closed when the test ends.
+func NewFakeNode(t testing.TB, fake *FakeJSONRPC) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var resp interface{}
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var reqs []jsonrpcRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			resps := make([]jsonrpcResponse, len(reqs))
+			for i, req := range reqs {
+				resps[i] = fake.serve(req)
+			}
+			resp = resps
+		} else {
+			var req jsonrpcRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			resp = fake.serve(req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// NewFakeClient returns a client.SDKClient, which implements
+// construction.Client, connected to a fake node answering with the handlers of
+// fake. The chain config defaults to params.AllEthashProtocolChanges.
+func NewFakeClient(t testing.TB, cfg *configuration.Configuration, fake *FakeJSONRPC) *client.SDKClient {
+	server := NewFakeNode(t, fake)
+
+	nodeCfg := *cfg
+	nodeCfg.GethURL = server.URL
+	if nodeCfg.ChainConfig == nil {
+		nodeCfg.ChainConfig = params.AllEthashProtocolChanges
+	}
+
+	sdkClient, err := client.NewClient(&nodeCfg, nil, nil)
+	if err != nil {
+		t.Fatalf("could not create client of fake node: %v", err)
+	}
+
+	return sdkClient
+}
+
+// serve returns the response of a JSON RPC request
+func (f *FakeJSONRPC) serve(req jsonrpcRequest) jsonrpcResponse {
+	resp := jsonrpcResponse{Version: "2.0", ID: req.ID}
+
+	result, err := f.dispatch(req.Method, req.Params)
+	if err != nil {
+		resp.Error = &jsonrpcError{Code: defaultErrorCode, Message: err.Error()}
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			resp.Error.Code = rpcErr.ErrorCode()
+		}
+		return resp
+	}
+
+	resp.Result = result
+	return resp
+}
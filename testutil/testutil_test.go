@@ -0,0 +1,114 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/client"
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	mockedClient "github.com/coinbase/rosetta-geth-sdk/mocks/client"
+	mockedServices "github.com/coinbase/rosetta-geth-sdk/mocks/services"
+	"github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/services/validator"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// The generated mocks and the fakes must implement the interfaces they stand in
+// for, so drift is caught at build time. Run `make mocks` to regenerate them.
+var (
+	_ client.JSONRPC      = (*mockedClient.JSONRPC)(nil)
+	_ client.GraphQL      = (*mockedClient.GraphQL)(nil)
+	_ construction.Client = (*mockedServices.Client)(nil)
+
+	_ client.JSONRPC      = (*FakeJSONRPC)(nil)
+	_ validator.Client    = (*FakeJSONRPC)(nil)
+	_ construction.Client = (*client.SDKClient)(nil)
+)
+
+func TestFakeJSONRPC(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeJSONRPC()
+	fake.Respond("eth_blockNumber", hexutil.Uint64(10))
+	fake.Handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var address common.Address
+		if err := json.Unmarshal(params[0], &address); err != nil {
+			return nil, err
+		}
+		return (*hexutil.Big)(new(big.Int).SetBytes(address.Bytes()[19:])), nil
+	})
+	fake.Fail("eth_call", errors.New("execution reverted"))
+
+	var number hexutil.Uint64
+	assert.NoError(t, fake.CallContext(ctx, &number, "eth_blockNumber"))
+	assert.Equal(t, hexutil.Uint64(10), number)
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{common.HexToAddress("0x7"), "latest"}, Result: new(hexutil.Big)},
+		{Method: "eth_call", Args: []interface{}{}, Result: new(hexutil.Bytes)},
+		{Method: "eth_getBlockReceipts", Args: []interface{}{}, Result: new(json.RawMessage)},
+	}
+	assert.NoError(t, fake.BatchCallContext(ctx, batch))
+	assert.NoError(t, batch[0].Error)
+	assert.Equal(t, int64(7), batch[0].Result.(*hexutil.Big).ToInt().Int64())
+	assert.EqualError(t, batch[1].Error, "execution reverted")
+
+	var rpcErr rpc.Error
+	assert.ErrorAs(t, batch[2].Error, &rpcErr)
+	assert.Equal(t, MethodNotFoundCode, rpcErr.ErrorCode())
+
+	assert.Len(t, fake.Calls(), 4)
+	assert.Equal(t, 1, fake.CallCount("eth_getBalance"))
+	assert.JSONEq(t, `"latest"`, string(fake.Calls()[1].Params[1]))
+}
+
+func TestNewFakeClient(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeJSONRPC()
+	fake.RespondWithFixture(t, "eth_getBlockByNumber", "../client/testdata/block_10992.json")
+	fake.Respond("eth_getBalance", "0x2a")
+
+	sdkClient := NewFakeClient(t, &configuration.Configuration{}, fake)
+
+	// Calls through the ethclient
+	header, err := sdkClient.HeaderByNumber(ctx, big.NewInt(10992))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10992), header.Number.Uint64())
+
+	// Calls and batches through the JSON RPC client
+	var balance hexutil.Big
+	assert.NoError(t, sdkClient.CallContext(ctx, &balance, "eth_getBalance", common.Address{}, "latest"))
+	assert.Equal(t, int64(42), balance.ToInt().Int64())
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{common.Address{}, "latest"}, Result: new(hexutil.Big)},
+		{Method: "eth_getBlockReceipts", Args: []interface{}{}, Result: new(json.RawMessage)},
+	}
+	assert.NoError(t, sdkClient.BatchCallContext(ctx, batch))
+	assert.NoError(t, batch[0].Error)
+	var rpcErr rpc.Error
+	assert.ErrorAs(t, batch[1].Error, &rpcErr)
+	assert.Equal(t, MethodNotFoundCode, rpcErr.ErrorCode())
+
+	assert.Equal(t, 1, fake.CallCount("eth_getBlockByNumber"))
+}
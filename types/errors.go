@@ -22,7 +22,8 @@ import (
 
 var (
 	// Errors contains all errors that could be returned
-	// by this Rosetta implementation.
+	// by this Rosetta implementation. Their codes are at most
+	// MaxSDKErrorCode, integrators define their errors with RegisterError.
 	Errors = []*types.Error{
 		ErrUnimplemented,
 		ErrUnavailableOffline,
@@ -47,6 +48,17 @@ var (
 		ErrGasTipCapError,
 		ErrGasFeeCapError,
 		ErrL1DataFeeError,
+		ErrSignatureMalleable,
+		ErrSignatureChainIDMismatch,
+		ErrSignerMismatch,
+		ErrValidationFailed,
+		ErrSimulationFailed,
+		ErrChainIDMismatch,
+		ErrServerBusy,
+		ErrNonceTooLow,
+		ErrUnderpriced,
+		ErrAlreadyKnown,
+		ErrBlockPruned,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -218,6 +230,85 @@ var (
 		Message: "error getting l1 data fee",
 	}
 
+	// ErrSignatureMalleable is returned when the s value
+	// of a signature is not in the lower half order
+	ErrSignatureMalleable = &types.Error{
+		Code:    23, //nolint
+		Message: "signature is malleable",
+	}
+
+	// ErrSignatureChainIDMismatch is returned when a signature
+	// or transaction is for a different chain id
+	ErrSignatureChainIDMismatch = &types.Error{
+		Code:    24, //nolint
+		Message: "signature chain id mismatch",
+	}
+
+	// ErrSignerMismatch is returned when the address recovered
+	// from a signature is not the transaction sender
+	ErrSignerMismatch = &types.Error{
+		Code:    25, //nolint
+		Message: "signer does not match transaction sender",
+	}
+
+	// ErrValidationFailed is returned when the data returned
+	// by the node does not match the block header
+	ErrValidationFailed = &types.Error{
+		Code:    26, //nolint
+		Message: "trustless validation failed",
+	}
+
+	// ErrSimulationFailed is returned when the simulation
+	// of a transaction in /construction/metadata reverts
+	ErrSimulationFailed = &types.Error{
+		Code:    27, //nolint
+		Message: "transaction simulation failed",
+	}
+
+	// ErrChainIDMismatch is returned when a construction request
+	// is for a chain id the network doesn't construct transactions for
+	ErrChainIDMismatch = &types.Error{
+		Code:    28, //nolint
+		Message: "chain id mismatch",
+	}
+
+	// ErrServerBusy is returned when an endpoint is at its
+	// concurrency limit and its queue is full
+	ErrServerBusy = &types.Error{
+		Code:      29, //nolint
+		Message:   "server is busy",
+		Retriable: true,
+	}
+
+	// ErrNonceTooLow is returned when a submitted transaction
+	// has a nonce the sender has already used
+	ErrNonceTooLow = &types.Error{
+		Code:    30, //nolint
+		Message: "nonce too low",
+	}
+
+	// ErrUnderpriced is returned when a submitted transaction is
+	// underpriced, or underpriced to replace a pending transaction
+	ErrUnderpriced = &types.Error{
+		Code:    31, //nolint
+		Message: "transaction underpriced",
+	}
+
+	// ErrAlreadyKnown classifies node errors for a submitted
+	// transaction that is already in the transaction pool.
+	// /construction/submit returns its hash instead.
+	ErrAlreadyKnown = &types.Error{
+		Code:    32, //nolint
+		Message: "transaction already known",
+	}
+
+	// ErrBlockPruned is returned when a requested block
+	// is older than the oldest block the node serves
+	ErrBlockPruned = &types.Error{
+		Code:    33, //nolint
+		Message: "block pruned",
+	}
+
 	ErrClientBlockOrphaned         = errors.New("block orphaned")
 	ErrClientCallParametersInvalid = errors.New("call parameters invalid")
 	ErrClientCallOutputMarshal     = errors.New("call output marshal")
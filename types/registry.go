@@ -0,0 +1,210 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// MaxSDKErrorCode is the largest error code reserved for the errors of the
+	// SDK, see Errors
+	MaxSDKErrorCode = 999
+
+	// MinIntegratorErrorCode is the smallest code of the errors defined by
+	// integrators with RegisterError
+	MinIntegratorErrorCode = 1000
+)
+
+var (
+	registryMu sync.Mutex
+
+	// registeredErrors are the errors added by RegisterError
+	registeredErrors []*RosettaTypes.Error
+
+	// registeredOpTypes are the operation types added by RegisterOpType
+	registeredOpTypes []string
+
+	// registeredStatuses are the operation statuses added by RegisterStatus
+	registeredStatuses []*RosettaTypes.OperationStatus
+)
+
+// RegisterOpType adds an operation type to the supported operation types of
+// LoadTypes, for chains whose operations are not covered by OperationTypes,
+// e.g. BridgeDepositOpType. The registered types are returned by
+// /network/options and accepted in the operations of construction requests.
+// Registering a supported type again is a no-op.
+func RegisterOpType(opType string) error {
+	if len(opType) == 0 {
+		return errors.New("operation type is empty")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if containsOpType(OperationTypes, opType) || containsOpType(registeredOpTypes, opType) {
+		return nil
+	}
+	registeredOpTypes = append(registeredOpTypes, opType)
+
+	return nil
+}
+
+// RegisterStatus adds an operation status to the supported operation statuses
+// of LoadTypes. Registering a supported status again is a no-op, unless it
+// changes whether the status is successful.
+func RegisterStatus(status string, successful bool) error {
+	if len(status) == 0 {
+		return errors.New("operation status is empty")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing := findStatus(OperationStatuses, status); existing != nil {
+		return checkStatus(existing, successful)
+	}
+	if existing := findStatus(registeredStatuses, status); existing != nil {
+		return checkStatus(existing, successful)
+	}
+	registeredStatuses = append(registeredStatuses, &RosettaTypes.OperationStatus{
+		Status:     status,
+		Successful: successful,
+	})
+
+	return nil
+}
+
+// IncludeRegistered adds the registered operation types and statuses that t
+// doesn't have to t, for types loaded before the extensions were registered
+func IncludeRegistered(t *Types) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, opType := range registeredOpTypes {
+		if !containsOpType(t.OperationTypes, opType) {
+			t.OperationTypes = append(t.OperationTypes, opType)
+		}
+	}
+	for _, status := range registeredStatuses {
+		if findStatus(t.OperationStatuses, status.Status) == nil {
+			t.OperationStatuses = append(t.OperationStatuses, status)
+		}
+	}
+}
+
+func containsOpType(opTypes []string, opType string) bool {
+	for _, t := range opTypes {
+		if t == opType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findStatus(statuses []*RosettaTypes.OperationStatus, status string) *RosettaTypes.OperationStatus {
+	for _, s := range statuses {
+		if s.Status == status {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func checkStatus(existing *RosettaTypes.OperationStatus, successful bool) error {
+	if existing.Successful != successful {
+		return fmt.Errorf(
+			"operation status %s is already registered with successful %t",
+			existing.Status,
+			existing.Successful,
+		)
+	}
+
+	return nil
+}
+
+// RegisterError defines a chain specific error of an integrator. Its code must
+// be at least MinIntegratorErrorCode, so it doesn't collide with the errors of
+// the SDK, and must not be the code of another registered error. Registered
+// errors are included in /network/options by utils.BootStrap.
+func RegisterError(code int32, message string, retriable bool) (*RosettaTypes.Error, error) {
+	if code < MinIntegratorErrorCode {
+		return nil, fmt.Errorf(
+			"error code %d is reserved for the SDK, integrator codes start at %d",
+			code,
+			MinIntegratorErrorCode,
+		)
+	}
+	if len(message) == 0 {
+		return nil, fmt.Errorf("error %d has no message", code)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, registered := range registeredErrors {
+		if registered.Code == code {
+			return nil, fmt.Errorf("error code %d is already registered for %q", code, registered.Message)
+		}
+	}
+	rErr := &RosettaTypes.Error{
+		Code:      code,
+		Message:   message,
+		Retriable: retriable,
+	}
+	registeredErrors = append(registeredErrors, rErr)
+
+	return rErr, nil
+}
+
+// MustRegisterError is RegisterError for package level error definitions. It
+// panics if the error can't be registered.
+func MustRegisterError(code int32, message string, retriable bool) *RosettaTypes.Error {
+	rErr, err := RegisterError(code, message, retriable)
+	if err != nil {
+		panic(err)
+	}
+
+	return rErr
+}
+
+// MustRegisterRetriableError defines a retriable error with MustRegisterError,
+// for failures that may succeed when retried, like those of an unsynced node
+func MustRegisterRetriableError(code int32, message string) *RosettaTypes.Error {
+	return MustRegisterError(code, message, true)
+}
+
+// IncludeRegisteredErrors returns rErrors with the registered errors whose code
+// is not in rErrors appended
+func IncludeRegisteredErrors(rErrors []*RosettaTypes.Error) []*RosettaTypes.Error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	codes := make(map[int32]bool, len(rErrors))
+	for _, rErr := range rErrors {
+		codes[rErr.Code] = true
+	}
+	included := append([]*RosettaTypes.Error{}, rErrors...)
+	for _, rErr := range registeredErrors {
+		if !codes[rErr.Code] {
+			included = append(included, rErr)
+		}
+	}
+
+	return included
+}
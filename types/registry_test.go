@@ -0,0 +1,90 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Cleanup(func() {
+		registeredOpTypes = nil
+		registeredStatuses = nil
+	})
+
+	loaded := LoadTypes()
+
+	assert.NoError(t, RegisterOpType("WRAP"))
+	assert.NoError(t, RegisterOpType("WRAP"))
+	assert.NoError(t, RegisterOpType(CallOpType))
+	assert.EqualError(t, RegisterOpType(""), "operation type is empty")
+
+	assert.NoError(t, RegisterStatus("REVERTED", false))
+	assert.NoError(t, RegisterStatus(SuccessStatus, true))
+	assert.EqualError(
+		t,
+		RegisterStatus(FailureStatus, true),
+		"operation status FAILURE is already registered with successful false",
+	)
+	assert.EqualError(
+		t,
+		RegisterStatus("REVERTED", true),
+		"operation status REVERTED is already registered with successful false",
+	)
+
+	types := LoadTypes()
+	assert.Equal(t, append(append([]string{}, OperationTypes...), "WRAP"), types.OperationTypes)
+	assert.Equal(t, append(
+		append([]*RosettaTypes.OperationStatus{}, OperationStatuses...),
+		&RosettaTypes.OperationStatus{Status: "REVERTED", Successful: false},
+	), types.OperationStatuses)
+
+	// The package types are not changed by registrations
+	assert.NotContains(t, OperationTypes, "WRAP")
+
+	// Types loaded before the registrations include them once
+	IncludeRegistered(loaded)
+	IncludeRegistered(loaded)
+	assert.Equal(t, types, loaded)
+}
+
+func TestRegisterError(t *testing.T) {
+	t.Cleanup(func() { registeredErrors = nil })
+
+	for _, rErr := range Errors {
+		assert.LessOrEqual(t, rErr.Code, int32(MaxSDKErrorCode))
+	}
+
+	errBridgePaused := MustRegisterRetriableError(1000, "bridge paused")
+	assert.Equal(t, &RosettaTypes.Error{Code: 1000, Message: "bridge paused", Retriable: true}, errBridgePaused)
+	errUnknownAlias, err := RegisterError(1001, "unknown alias", false)
+	assert.NoError(t, err)
+
+	_, err = RegisterError(34, "collides with the SDK", false)
+	assert.EqualError(t, err, "error code 34 is reserved for the SDK, integrator codes start at 1000")
+	_, err = RegisterError(1000, "bridge halted", true)
+	assert.EqualError(t, err, `error code 1000 is already registered for "bridge paused"`)
+	_, err = RegisterError(1002, "", true)
+	assert.EqualError(t, err, "error 1002 has no message")
+	assert.Panics(t, func() { MustRegisterError(1001, "unknown alias", false) })
+
+	included := IncludeRegisteredErrors(Errors)
+	assert.Equal(t, append(append([]*RosettaTypes.Error{}, Errors...), errBridgePaused, errUnknownAlias), included)
+	assert.Equal(t, included, IncludeRegisteredErrors(included))
+	assert.Len(t, Errors, len(included)-2)
+}
@@ -65,17 +65,52 @@ const (
 	// StaticCallOpType is used to represent STATICCALL trace operations.
 	StaticCallOpType = "STATICCALL"
 
+	// DeployOpType is used to represent contract deployments
+	// in the construction API.
+	DeployOpType = "DEPLOY"
+
 	// DestructOpType is a synthetic operation used to represent the
 	// deletion of suicided accounts that still have funds at the end
 	// of a transaction.
 	DestructOpType = "DESTRUCT"
 
+	// AtomicImportOpType is used to represent the AVAX credited by an
+	// Avalanche C-chain atomic import transaction, see
+	// client.AvalancheAtomicTransactions.
+	AtomicImportOpType = "IMPORT"
+
+	// AtomicExportOpType is used to represent the AVAX debited by an
+	// Avalanche C-chain atomic export transaction.
+	AtomicExportOpType = "EXPORT"
+
+	// BridgeDepositOpType is used to represent the funds credited by a
+	// bridge event, see client.BridgeEventParser.
+	BridgeDepositOpType = "BRIDGE_DEPOSIT"
+
+	// BridgeWithdrawalOpType is used to represent the funds debited by a
+	// bridge event.
+	BridgeWithdrawalOpType = "BRIDGE_WITHDRAWAL"
+
+	// WithdrawalOpType is used to represent the funds credited by an
+	// EIP-4895 withdrawal of the consensus layer.
+	WithdrawalOpType = "WITHDRAWAL"
+
+	// UserOperationOpType is used to represent an ERC-4337 user operation
+	// of a bundle, see client.ParseUserOperations. It has no amount, the
+	// transfers of the user operation are operations of the bundle.
+	UserOperationOpType = "USER_OPERATION"
+
 	OpErc20Transfer = "ERC20_TRANSFER"
 
 	OpErc20Mint = "ERC20_MINT"
 
 	OpErc20Burn = "ERC20_BURN"
 
+	// OpErc20BalanceAdjustment is a synthetic operation used to represent
+	// the balance changes of non standard tokens that are not reflected
+	// by their Transfer events, e.g. transfer fees and rebases.
+	OpErc20BalanceAdjustment = "ERC20_BALANCE_ADJUSTMENT"
+
 	// SuccessStatus is the status of any
 	// Ethereum operation considered successful.
 	SuccessStatus = "SUCCESS"
@@ -107,6 +142,28 @@ const (
 	// MainnetGethArguments are the arguments to start a mainnet geth instance.
 	MainnetGethArguments = `--config=/app/ethereum/geth.toml --gcmode=archive --graphql`
 
+	// GetRawBlockMethod is the /call method returning the RLP encoding
+	// of a block.
+	GetRawBlockMethod = "get_raw_block"
+
+	// GetPendingTransactionsMethod is the /call method returning the
+	// transactions of a sender in the transaction pool.
+	GetPendingTransactionsMethod = "get_pending_transactions"
+
+	// SignAndSubmitMethod is the /call method signing, combining and submitting
+	// an unsigned transaction with the server signer. It is only supported
+	// with server side signing.
+	SignAndSubmitMethod = "sign_and_submit"
+
+	// GetSlowCallsMethod is the /call method returning the slowest JSON RPC
+	// calls to the node. It is only supported with slow call logging.
+	GetSlowCallsMethod = "get_slow_calls"
+
+	// AccountNonceMethod is the /call method returning the latest and
+	// pending nonces of an account, with the nonce gaps of its transactions
+	// in the transaction pool.
+	AccountNonceMethod = "account_nonce"
+
 	// IncludeMempoolCoins does not apply to rosetta-ethereum as it is not UTXO-based.
 	IncludeMempoolCoins = false
 
@@ -132,6 +189,8 @@ var (
 		DestructOpType,
 		OpErc20Mint,
 		OpErc20Burn,
+		OpErc20BalanceAdjustment,
+		DeployOpType,
 	}
 
 	// OperationStatuses are all supported operation statuses.
@@ -152,6 +211,9 @@ var (
 		"eth_getTransactionReceipt",
 		"eth_call",
 		"eth_estimateGas",
+		GetRawBlockMethod,
+		GetPendingTransactionsMethod,
+		AccountNonceMethod,
 	}
 
 	Currency = &RosettaTypes.Currency{
@@ -215,12 +277,13 @@ func CreateType(t string) bool {
 func LoadTypes() *Types {
 	types := &Types{}
 
-	types.OperationStatuses = OperationStatuses
-	types.OperationTypes = OperationTypes
+	types.OperationStatuses = append([]*RosettaTypes.OperationStatus{}, OperationStatuses...)
+	types.OperationTypes = append([]string{}, OperationTypes...)
 	types.CallMethods = CallMethods
 	types.Currency = Currency
 	types.HistoricalBalanceSupported = HistoricalBalanceSupported
 	types.NodeVersion = NodeVersion
+	IncludeRegistered(types)
 
 	return types
 }
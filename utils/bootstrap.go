@@ -25,6 +25,7 @@ import (
 	"github.com/coinbase/rosetta-geth-sdk/configuration"
 	"github.com/coinbase/rosetta-geth-sdk/services"
 	"github.com/coinbase/rosetta-geth-sdk/services/construction"
+	"github.com/coinbase/rosetta-geth-sdk/signer"
 
 	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
 
@@ -47,6 +48,19 @@ func BootStrap(
 	errors []*RosettaTypes.Error,
 	client construction.Client,
 ) error {
+	// Include the operation types, statuses and errors registered by extensions
+	AssetTypes.IncludeRegistered(types)
+	errors = AssetTypes.IncludeRegisteredErrors(errors)
+
+	// The server side signing method is only served when it is enabled
+	if cfg.RosettaCfg.ServerSideSigning {
+		types.CallMethods = append(append([]string{}, types.CallMethods...), AssetTypes.SignAndSubmitMethod)
+	}
+	// The slow call report is only served when slow calls are logged
+	if cfg.RosettaCfg.SlowCallLoggingEnabled() {
+		types.CallMethods = append(append([]string{}, types.CallMethods...), AssetTypes.GetSlowCallsMethod)
+	}
+
 	// The asserter automatically rejects incorrectly formatted requests.
 	asserter, err := asserter.NewServer(
 		types.OperationTypes,
@@ -60,6 +74,35 @@ func BootStrap(
 		return fmt.Errorf("could not initialize server asserter: %w", err)
 	}
 
+	if err := cfg.RosettaCfg.DefaultBlock().Validate(); err != nil {
+		return fmt.Errorf("invalid default block: %w", err)
+	}
+
+	if detector, ok := client.(gethSdkClient.FeatureDetector); ok && !cfg.IsOfflineMode() {
+		if err := detector.DetectFeatures(context.Background()); err != nil {
+			return fmt.Errorf("could not detect node features: %w", err)
+		}
+		rosettaCfg := client.GetRosettaConfig()
+		cfg.RosettaCfg.HasUncles = rosettaCfg.HasUncles
+		cfg.RosettaCfg.HasAdminRPC = rosettaCfg.HasAdminRPC
+	}
+
+	if verifier, ok := client.(gethSdkClient.TokenDecimalsVerifier); ok && !cfg.IsOfflineMode() {
+		if err := verifier.VerifyTokenDecimals(context.Background()); err != nil {
+			return fmt.Errorf("could not verify token decimals: %w", err)
+		}
+		cfg.RosettaCfg.TokenWhiteList = client.GetRosettaConfig().TokenWhiteList
+	}
+
+	checksumStrategy, err := gethSdkClient.ChecksumStrategyFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("could not initialize address checksum: %w", err)
+	}
+	gethSdkClient.SetChecksumStrategy(checksumStrategy)
+	if normalizer, ok := client.(gethSdkClient.AddressNormalizer); ok {
+		gethSdkClient.SetAddressNormalizer(normalizer)
+	}
+
 	// If header forwarding is turned on, initialize a new client
 	var headerForwarder *headerforwarder.HeaderForwarder
 	if cfg.RosettaCfg.SupportHeaderForwarding {
@@ -89,8 +132,23 @@ func BootStrap(
 		client = convertedClient
 	}
 
-	router := services.NewBlockchainRouter(cfg, types, errors, client, asserter)
+	// sign_and_submit has no authentication, so servers with server side
+	// signing must only be reachable by trusted callers
+	var serverSigner signer.Signer
+	if cfg.RosettaCfg.ServerSideSigning {
+		serverSigner, err = services.LoadServerSigner(context.Background(), cfg, client)
+		if err != nil {
+			return fmt.Errorf("could not load server signer: %w", err)
+		}
+	}
+
+	blockchainRouter, err := services.NewBlockchainRouterWithSigner(cfg, types, errors, client, asserter, serverSigner)
+	if err != nil {
+		return fmt.Errorf("could not initialize router: %w", err)
+	}
+	defer blockchainRouter.Close()
 
+	var router http.Handler = blockchainRouter
 	if cfg.RosettaCfg.SupportHeaderForwarding {
 		router = headerForwarder.HeaderForwarderHandler(router)
 	}
@@ -0,0 +1,423 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+	AssetTypes "github.com/coinbase/rosetta-geth-sdk/types"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// RosettaCLIConfigFile is the name of the generated rosetta-cli config file
+	RosettaCLIConfigFile = "config.json"
+
+	// RosettaCLIBootstrapBalancesFile is the name of the generated bootstrap balances file
+	RosettaCLIBootstrapBalancesFile = "bootstrap_balances.json"
+
+	defaultCLIDataDirectory = "cli-data"
+	defaultCLIHTTPTimeout   = 300
+	defaultCLIMaxRetries    = 15
+	defaultCLIConnections   = 50
+	defaultCLISyncers       = 16
+	defaultCLITipDelay      = 120
+	defaultCLIStaleDepth    = 3
+	defaultCLIBroadcasts    = 5
+	defaultCLIReconcilers   = 32
+	defaultCLICoverage      = 0.95
+
+	// defaultCLIMaxFee is the fee reserved by construction transfers, 21000 gas at 100 gwei
+	defaultCLIMaxFee = "2100000000000000"
+)
+
+// RosettaCLIConfig is the subset of the rosetta-cli configuration file used to
+// run check:data and check:construction
+type RosettaCLIConfig struct {
+	Network              *RosettaTypes.NetworkIdentifier `json:"network"`
+	OnlineURL            string                          `json:"online_url"`
+	DataDirectory        string                          `json:"data_directory"`
+	HTTPTimeout          uint64                          `json:"http_timeout"`
+	MaxRetries           uint64                          `json:"max_retries"`
+	MaxOnlineConnections int                             `json:"max_online_connections"`
+	MaxSyncConcurrency   int64                           `json:"max_sync_concurrency"`
+	TipDelay             int64                           `json:"tip_delay"`
+	CompressionDisabled  bool                            `json:"compression_disabled"`
+	MemoryLimitDisabled  bool                            `json:"memory_limit_disabled"`
+
+	Construction *RosettaCLIConstructionConfig `json:"construction"`
+	Data         *RosettaCLIDataConfig         `json:"data"`
+}
+
+// RosettaCLIConstructionConfig is the check:construction section of a rosetta-cli config
+type RosettaCLIConstructionConfig struct {
+	OfflineURL         string                        `json:"offline_url"`
+	StaleDepth         int64                         `json:"stale_depth"`
+	BroadcastLimit     int                           `json:"broadcast_limit"`
+	ConstructorDSLFile string                        `json:"constructor_dsl_file"`
+	PrefundedAccounts  []*RosettaCLIPrefundedAccount `json:"prefunded_accounts,omitempty"`
+	EndConditions      map[string]int                `json:"end_conditions"`
+}
+
+// RosettaCLIPrefundedAccount is an account funded before check:construction
+// starts, e.g. in the genesis of a devnet
+type RosettaCLIPrefundedAccount struct {
+	PrivateKeyHex     string                          `json:"privkey"`
+	AccountIdentifier *RosettaTypes.AccountIdentifier `json:"account_identifier"`
+	CurveType         RosettaTypes.CurveType          `json:"curve_type"`
+	Currency          *RosettaTypes.Currency          `json:"currency"`
+}
+
+// RosettaCLIDataConfig is the check:data section of a rosetta-cli config
+type RosettaCLIDataConfig struct {
+	InitialBalanceFetchDisabled     bool                         `json:"initial_balance_fetch_disabled"`
+	ActiveReconciliationConcurrency uint64                       `json:"active_reconciliation_concurrency"`
+	BootstrapBalances               string                       `json:"bootstrap_balances,omitempty"`
+	EndConditions                   *RosettaCLIDataEndConditions `json:"end_conditions"`
+}
+
+// RosettaCLIDataEndConditions are the end conditions of check:data
+type RosettaCLIDataEndConditions struct {
+	ReconciliationCoverage *RosettaCLIReconciliationCoverage `json:"reconciliation_coverage"`
+}
+
+// RosettaCLIReconciliationCoverage is the reconciliation coverage end condition of check:data
+type RosettaCLIReconciliationCoverage struct {
+	Coverage float64 `json:"coverage"`
+	FromTip  bool    `json:"from_tip"`
+}
+
+// RosettaCLIOptions are the settings of the generated rosetta-cli config that
+// are not part of configuration.Configuration
+type RosettaCLIOptions struct {
+	// OnlineURL is the URL of the Rosetta server. Defaults to localhost at the
+	// configured port.
+	OnlineURL string
+
+	// OfflineURL is the URL of the offline Rosetta server. Defaults to OnlineURL.
+	OfflineURL string
+
+	// DataDirectory is the rosetta-cli data directory. Defaults to cli-data.
+	DataDirectory string
+
+	// PrefundedKeys are the keys of funded accounts used by check:construction,
+	// e.g. the dev accounts of a devnet
+	PrefundedKeys []*ecdsa.PrivateKey
+
+	// GenesisBalances are the native balances of the genesis block, written to the
+	// bootstrap balances of check:data. When set, the initial balance fetch of
+	// check:data is disabled.
+	GenesisBalances map[common.Address]*big.Int
+
+	// MaxFee is the fee reserved by the transfers of check:construction, in the
+	// smallest unit of the native currency. Defaults to 21000 gas at 100 gwei.
+	MaxFee *big.Int
+}
+
+// rosettaCLIAccountBalance is an entry of the bootstrap balances file
+type rosettaCLIAccountBalance struct {
+	Account  *RosettaTypes.AccountIdentifier `json:"account_identifier"`
+	Currency *RosettaTypes.Currency          `json:"currency"`
+	Value    string                          `json:"value"`
+}
+
+// NewRosettaCLIConfig returns the rosetta-cli config checking the Rosetta server
+// of cfg, with the construction DSL file named by RosettaCLIDSLFile
+func NewRosettaCLIConfig(cfg *configuration.Configuration, opts *RosettaCLIOptions) (*RosettaCLIConfig, error) {
+	if cfg.Network == nil {
+		return nil, errors.New("network identifier is not set")
+	}
+	if cfg.RosettaCfg.Currency == nil {
+		return nil, errors.New("native currency is not set")
+	}
+	if opts == nil {
+		opts = &RosettaCLIOptions{}
+	}
+
+	onlineURL := opts.OnlineURL
+	if len(onlineURL) == 0 {
+		onlineURL = fmt.Sprintf("http://localhost:%d", cfg.Port)
+	}
+	offlineURL := opts.OfflineURL
+	if len(offlineURL) == 0 {
+		offlineURL = onlineURL
+	}
+	dataDirectory := opts.DataDirectory
+	if len(dataDirectory) == 0 {
+		dataDirectory = defaultCLIDataDirectory
+	}
+
+	prefunded := make([]*RosettaCLIPrefundedAccount, len(opts.PrefundedKeys))
+	for i, key := range opts.PrefundedKeys {
+		prefunded[i] = &RosettaCLIPrefundedAccount{
+			PrivateKeyHex: hex.EncodeToString(crypto.FromECDSA(key)),
+			AccountIdentifier: &RosettaTypes.AccountIdentifier{
+				Address: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			},
+			CurveType: RosettaTypes.Secp256k1,
+			Currency:  cfg.RosettaCfg.Currency,
+		}
+	}
+
+	data := &RosettaCLIDataConfig{
+		ActiveReconciliationConcurrency: defaultCLIReconcilers,
+		EndConditions: &RosettaCLIDataEndConditions{
+			ReconciliationCoverage: &RosettaCLIReconciliationCoverage{
+				Coverage: defaultCLICoverage,
+				FromTip:  true,
+			},
+		},
+	}
+	if len(opts.GenesisBalances) > 0 {
+		data.InitialBalanceFetchDisabled = true
+		data.BootstrapBalances = RosettaCLIBootstrapBalancesFile
+	}
+
+	return &RosettaCLIConfig{
+		Network:              cfg.Network,
+		OnlineURL:            onlineURL,
+		DataDirectory:        dataDirectory,
+		HTTPTimeout:          defaultCLIHTTPTimeout,
+		MaxRetries:           defaultCLIMaxRetries,
+		MaxOnlineConnections: defaultCLIConnections,
+		MaxSyncConcurrency:   defaultCLISyncers,
+		TipDelay:             defaultCLITipDelay,
+		CompressionDisabled:  true,
+		MemoryLimitDisabled:  true,
+		Construction: &RosettaCLIConstructionConfig{
+			OfflineURL:         offlineURL,
+			StaleDepth:         defaultCLIStaleDepth,
+			BroadcastLimit:     defaultCLIBroadcasts,
+			ConstructorDSLFile: RosettaCLIDSLFile(cfg),
+			PrefundedAccounts:  prefunded,
+			EndConditions: map[string]int{
+				"create_account": 1,
+				"transfer":       1,
+			},
+		},
+		Data: data,
+	}, nil
+}
+
+// RosettaCLIDSLFile is the name of the generated construction DSL file, the
+// lowercase blockchain name
+func RosettaCLIDSLFile(cfg *configuration.Configuration) string {
+	return strings.ToLower(strings.ReplaceAll(cfg.Network.Blockchain, " ", "_")) + ".ros"
+}
+
+// RosettaCLIFiles returns the contents of the rosetta-cli config, construction
+// DSL and bootstrap balances files checking the Rosetta server of cfg, keyed by
+// file name
+func RosettaCLIFiles(cfg *configuration.Configuration, opts *RosettaCLIOptions) (map[string][]byte, error) {
+	cliConfig, err := NewRosettaCLIConfig(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &RosettaCLIOptions{}
+	}
+
+	files := map[string][]byte{}
+	if files[RosettaCLIConfigFile], err = json.MarshalIndent(cliConfig, "", "  "); err != nil {
+		return nil, err
+	}
+
+	maxFee := opts.MaxFee
+	if maxFee == nil {
+		maxFee, _ = new(big.Int).SetString(defaultCLIMaxFee, 10) // nolint:gomnd
+	}
+	if files[RosettaCLIDSLFile(cfg)], err = constructionDSL(cfg, maxFee); err != nil {
+		return nil, err
+	}
+
+	if len(opts.GenesisBalances) > 0 {
+		addresses := make([]common.Address, 0, len(opts.GenesisBalances))
+		for address := range opts.GenesisBalances {
+			addresses = append(addresses, address)
+		}
+		sort.Slice(addresses, func(i, j int) bool {
+			return bytes.Compare(addresses[i].Bytes(), addresses[j].Bytes()) < 0
+		})
+
+		balances := make([]*rosettaCLIAccountBalance, len(addresses))
+		for i, address := range addresses {
+			balances[i] = &rosettaCLIAccountBalance{
+				Account:  &RosettaTypes.AccountIdentifier{Address: address.Hex()},
+				Currency: cfg.RosettaCfg.Currency,
+				Value:    opts.GenesisBalances[address].String(),
+			}
+		}
+		if files[RosettaCLIBootstrapBalancesFile], err = json.MarshalIndent(balances, "", "  "); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// WriteRosettaCLIConfig writes the rosetta-cli files of RosettaCLIFiles to dir,
+// ready to run check:data and check:construction with
+// `rosetta-cli --configuration-file <dir>/config.json`
+func WriteRosettaCLIConfig(dir string, cfg *configuration.Configuration, opts *RosettaCLIOptions) error {
+	files, err := RosettaCLIFiles(cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil { // nolint:gomnd
+		return err
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0o644); err != nil { // nolint:gomnd
+			return err
+		}
+	}
+
+	return nil
+}
+
+// constructionDSL returns the construction DSL creating accounts and transferring
+// the native currency between them. The template uses [[ ]] delimiters since
+// the DSL uses {{ }} for variables.
+func constructionDSL(cfg *configuration.Configuration, maxFee *big.Int) ([]byte, error) {
+	network, err := json.Marshal(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := json.Marshal(cfg.RosettaCfg.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = constructionDSLTemplate.Execute(&buf, map[string]string{
+		"Network":  string(network),
+		"Currency": string(currency),
+		"MaxFee":   maxFee.String(),
+		"OpType":   AssetTypes.CallOpType,
+	})
+	return buf.Bytes(), err
+}
+
+var constructionDSLTemplate = template.Must(template.New("dsl").Delims("[[", "]]").Parse(`request_funds(1){
+  find_account{
+    currency = [[.Currency]];
+    random_account = find_balance({
+      "minimum_balance":{
+        "value": "0",
+        "currency": {{currency}}
+      },
+      "create_limit":1
+    });
+  },
+
+  // Create a separate scenario to request funds so that
+  // the address we are using to request funds does not
+  // get rolled back if funds do not yet exist.
+  request{
+    loaded_account = find_balance({
+      "account_identifier": {{random_account.account_identifier}},
+      "minimum_balance":{
+        "value": "[[.MaxFee]]",
+        "currency": {{currency}}
+      }
+    });
+  }
+}
+
+create_account(1){
+  create{
+    network = [[.Network]];
+    key = generate_key({"curve_type": "secp256k1"});
+    account = derive({
+      "network_identifier": {{network}},
+      "public_key": {{key.public_key}}
+    });
+
+    // If the account is not saved, the key will be lost!
+    save_account({
+      "account_identifier": {{account.account_identifier}},
+      "keypair": {{key}}
+    });
+  }
+}
+
+transfer(1){
+  transfer{
+    transfer.network = [[.Network]];
+    currency = [[.Currency]];
+    max_fee = "[[.MaxFee]]";
+    sender = find_balance({
+      "minimum_balance":{
+        "value": {{max_fee}},
+        "currency": {{currency}}
+      }
+    });
+
+    // Set the recipient_amount as some value <= sender.balance-max_fee
+    available_amount = {{sender.balance.value}} - {{max_fee}};
+    recipient_amount = random_number({"minimum": "1", "maximum": {{available_amount}}});
+    print_message({"recipient_amount":{{recipient_amount}}});
+
+    // Find recipient and construct operations
+    sender_amount = 0 - {{recipient_amount}};
+    recipient = find_balance({
+      "not_account_identifier":[{{sender.account_identifier}}],
+      "minimum_balance":{
+        "value": "0",
+        "currency": {{currency}}
+      },
+      "create_limit": 100,
+      "create_probability": 50
+    });
+    transfer.confirmation_depth = "1";
+    transfer.operations = [
+      {
+        "operation_identifier":{"index":0},
+        "type":"[[.OpType]]",
+        "account":{{sender.account_identifier}},
+        "amount":{
+          "value":{{sender_amount}},
+          "currency":{{currency}}
+        }
+      },
+      {
+        "operation_identifier":{"index":1},
+        "type":"[[.OpType]]",
+        "account":{{recipient.account_identifier}},
+        "amount":{
+          "value":{{recipient_amount}},
+          "currency":{{currency}}
+        }
+      }
+    ];
+  }
+}
+`))
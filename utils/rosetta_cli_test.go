@@ -0,0 +1,108 @@
+// Copyright 2024 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coinbase/rosetta-geth-sdk/configuration"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRosettaCLIFiles(t *testing.T) {
+	currency := &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18}
+	cfg := &configuration.Configuration{
+		Network:    &RosettaTypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Devnet"},
+		Port:       8080,
+		RosettaCfg: configuration.RosettaConfig{Currency: currency},
+	}
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	funded := crypto.PubkeyToAddress(key.PublicKey)
+
+	files, err := RosettaCLIFiles(cfg, &RosettaCLIOptions{
+		PrefundedKeys:   []*ecdsa.PrivateKey{key},
+		GenesisBalances: map[common.Address]*big.Int{funded: big.NewInt(1000)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	var cliConfig RosettaCLIConfig
+	assert.NoError(t, json.Unmarshal(files[RosettaCLIConfigFile], &cliConfig))
+	assert.Equal(t, cfg.Network, cliConfig.Network)
+	assert.Equal(t, "http://localhost:8080", cliConfig.OnlineURL)
+	assert.Equal(t, "http://localhost:8080", cliConfig.Construction.OfflineURL)
+	assert.Equal(t, "ethereum.ros", cliConfig.Construction.ConstructorDSLFile)
+	assert.Equal(t, []*RosettaCLIPrefundedAccount{{
+		PrivateKeyHex:     hex.EncodeToString(crypto.FromECDSA(key)),
+		AccountIdentifier: &RosettaTypes.AccountIdentifier{Address: funded.Hex()},
+		CurveType:         RosettaTypes.Secp256k1,
+		Currency:          currency,
+	}}, cliConfig.Construction.PrefundedAccounts)
+	assert.True(t, cliConfig.Data.InitialBalanceFetchDisabled)
+	assert.Equal(t, RosettaCLIBootstrapBalancesFile, cliConfig.Data.BootstrapBalances)
+
+	var balances []*rosettaCLIAccountBalance
+	assert.NoError(t, json.Unmarshal(files[RosettaCLIBootstrapBalancesFile], &balances))
+	assert.Equal(t, []*rosettaCLIAccountBalance{{
+		Account:  &RosettaTypes.AccountIdentifier{Address: funded.Hex()},
+		Currency: currency,
+		Value:    "1000",
+	}}, balances)
+
+	dsl := string(files["ethereum.ros"])
+	assert.Contains(t, dsl, `transfer.network = {"blockchain":"Ethereum","network":"Devnet"};`)
+	assert.Contains(t, dsl, `currency = {"symbol":"ETH","decimals":18};`)
+	assert.Contains(t, dsl, `max_fee = "2100000000000000";`)
+	assert.Contains(t, dsl, `"account":{{sender.account_identifier}},`)
+	assert.NotContains(t, dsl, "[[")
+
+	_, err = RosettaCLIFiles(&configuration.Configuration{}, nil)
+	assert.EqualError(t, err, "network identifier is not set")
+}
+
+func TestWriteRosettaCLIConfig(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Network: &RosettaTypes.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"},
+		RosettaCfg: configuration.RosettaConfig{
+			Currency: &RosettaTypes.Currency{Symbol: "ETH", Decimals: 18},
+		},
+	}
+	dir := filepath.Join(t.TempDir(), "rosetta-cli-conf")
+
+	assert.NoError(t, WriteRosettaCLIConfig(dir, cfg, &RosettaCLIOptions{OnlineURL: "http://rosetta:8080"}))
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	assert.Equal(t, []string{RosettaCLIConfigFile, "ethereum.ros"}, names)
+
+	raw, err := os.ReadFile(filepath.Join(dir, RosettaCLIConfigFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), `"online_url": "http://rosetta:8080"`)
+	assert.NotContains(t, string(raw), "bootstrap_balances")
+}